@@ -5,11 +5,29 @@
  * their own credentials.json.
  *
  * Flow:
- *   1. CLI opens browser → GET /start?port=<port>
- *   2. Worker redirects  → Tienda Nube authorize page
- *   3. User authorizes   → Tienda Nube redirects to GET /callback?code=<code>&state=<port>
+ *   1. CLI opens browser → GET /start?port=<port>&state=<state>
+ *   2. Worker redirects  → Tienda Nube authorize page, state=<port>.<state>
+ *   3. User authorizes   → Tienda Nube redirects to GET /callback?code=<code>&state=<port>.<state>
  *   4. Worker exchanges code for token (server-to-server)
- *   5. Worker redirects  → http://127.0.0.1:<port>/callback?token=<token>&user_id=<user_id>
+ *   5. Worker redirects  → http://127.0.0.1:<port>/callback?token=<token>&user_id=<user_id>&state=<state>
+ *
+ * The CLI checks that its random state comes back with the token, so the
+ * worker stays stateless. Older CLIs send no state; then only the port is
+ * passed through.
+ *
+ * QR logins (`nube login --qr`) are approved on another device, which
+ * can't reach the CLI's port:
+ *   1. CLI keeps a random <verifier> and shows a QR code of
+ *      GET /start?poll=1&state=<state>, where <state> is the unpadded
+ *      base64url SHA-256 of <verifier> (as for PKCE)
+ *   2. Worker redirects  → Tienda Nube authorize page, state=poll.<state>
+ *   3. Worker exchanges the code and keeps the token in the TOKENS KV
+ *      namespace under <state> for 10 minutes
+ *   4. CLI polls POST /poll with the form value verifier=<verifier>: 204
+ *      until the token is there, then 200 {"access_token", "user_id"}, once
+ * Anyone who sees the QR code knows <state>, but only the CLI knows the
+ * verifier that collects the token. Without a TOKENS binding, /poll
+ * answers 501 and the CLI says so.
  *
  * Secrets (set via `wrangler secret put`):
  *   - CLIENT_ID
@@ -19,9 +37,13 @@
 const AUTH_BASE = "https://www.tiendanube.com/apps";
 const TOKEN_URL = "https://www.tiendanube.com/apps/authorize/token";
 
+const PORT_PATTERN = /^\d{4,5}$/;
+const STATE_PATTERN = /^[A-Za-z0-9_-]{16,128}$/;
+const POLL_TTL_SECONDS = 600;
+
 /**
  * @param {Request} request
- * @param {{ CLIENT_ID: string, CLIENT_SECRET: string }} env
+ * @param {{ CLIENT_ID: string, CLIENT_SECRET: string, TOKENS?: KVNamespace }} env
  * @returns {Promise<Response>}
  */
 export default {
@@ -33,6 +55,8 @@ export default {
         return handleStart(url, env);
       case "/callback":
         return handleCallback(url, env);
+      case "/poll":
+        return handlePoll(request, env);
       case "/robots.txt":
         return new Response("User-agent: *\nDisallow: /\n", {
           headers: { "Content-Type": "text/plain" },
@@ -44,40 +68,68 @@ export default {
 };
 
 /**
- * GET /start?port=<port>
+ * GET /start?port=<port>&state=<state>
  *
- * Validates the port and redirects to the Tienda Nube authorization page.
- * The port is passed as `state` so the callback can redirect back to the CLI.
+ * Validates the port and state and redirects to the Tienda Nube
+ * authorization page. Both are passed as `state` so the callback can
+ * redirect back to the CLI with the CLI's state.
  */
 function handleStart(url, env) {
   const port = url.searchParams.get("port");
+  const state = url.searchParams.get("state");
 
-  if (!port || !/^\d{4,5}$/.test(port)) {
+  if (url.searchParams.get("poll")) {
+    if (!state || !STATE_PATTERN.test(state)) {
+      return new Response("Bad Request: invalid or missing state parameter", {
+        status: 400,
+      });
+    }
+
+    return Response.redirect(
+      `${AUTH_BASE}/${env.CLIENT_ID}/authorize?state=poll.${state}`,
+      302,
+    );
+  }
+
+  if (!port || !PORT_PATTERN.test(port)) {
     return new Response("Bad Request: invalid or missing port parameter", {
       status: 400,
     });
   }
 
-  const authorizeURL = `${AUTH_BASE}/${env.CLIENT_ID}/authorize?state=${port}`;
+  if (state && !STATE_PATTERN.test(state)) {
+    return new Response("Bad Request: invalid state parameter", {
+      status: 400,
+    });
+  }
+
+  const upstreamState = state ? `${port}.${state}` : port;
+  const authorizeURL = `${AUTH_BASE}/${env.CLIENT_ID}/authorize?state=${upstreamState}`;
 
   return Response.redirect(authorizeURL, 302);
 }
 
 /**
- * GET /callback?code=<code>&state=<port>
+ * GET /callback?code=<code>&state=<port>.<state>
  *
  * Exchanges the authorization code for an access token, then redirects
  * the browser back to the CLI's local callback server.
  */
 async function handleCallback(url, env) {
   const code = url.searchParams.get("code");
-  const port = url.searchParams.get("state");
+  const [port, state, ...rest] = (url.searchParams.get("state") || "").split(".");
 
   if (!code) {
     return new Response("Bad Request: missing code parameter", { status: 400 });
   }
 
-  if (!port || !/^\d{4,5}$/.test(port)) {
+  const poll = port === "poll";
+  const valid = poll
+    ? state !== undefined && STATE_PATTERN.test(state)
+    : PORT_PATTERN.test(port) &&
+      (state === undefined || STATE_PATTERN.test(state));
+
+  if (!valid || rest.length > 0) {
     return new Response("Bad Request: invalid or missing state parameter", {
       status: 400,
     });
@@ -123,6 +175,25 @@ async function handleCallback(url, env) {
     });
   }
 
+  if (poll) {
+    if (!env.TOKENS) {
+      return new Response("Not Implemented: QR logins need a TOKENS KV namespace", {
+        status: 501,
+      });
+    }
+
+    await env.TOKENS.put(
+      state,
+      JSON.stringify({ access_token: data.access_token, user_id: data.user_id }),
+      { expirationTtl: POLL_TTL_SECONDS },
+    );
+
+    return new Response(
+      "Authorization successful. Go back to the terminal: nube finishes logging in there. You can close this page.",
+      { headers: { "Content-Type": "text/plain; charset=utf-8" } },
+    );
+  }
+
   const callbackURL = new URL(`http://127.0.0.1:${port}/callback`);
   callbackURL.searchParams.set("token", data.access_token);
 
@@ -130,5 +201,71 @@ async function handleCallback(url, env) {
     callbackURL.searchParams.set("user_id", String(data.user_id));
   }
 
+  if (state !== undefined) {
+    callbackURL.searchParams.set("state", state);
+  }
+
   return Response.redirect(callbackURL.toString(), 302);
 }
+
+/**
+ * POST /poll (form: verifier=<verifier>)
+ *
+ * Hands a QR login's token to the CLI that started it, once: 200 with the
+ * token, or 204 while the user hasn't authorized yet. The token is kept
+ * under the hash of the verifier (see pollChallenge).
+ */
+async function handlePoll(request, env) {
+  if (request.method !== "POST") {
+    return new Response("Method Not Allowed", {
+      status: 405,
+      headers: { Allow: "POST" },
+    });
+  }
+
+  const form = await request.formData().catch(() => null);
+  const verifier = form && form.get("verifier");
+
+  if (typeof verifier !== "string" || !STATE_PATTERN.test(verifier)) {
+    return new Response("Bad Request: invalid or missing verifier parameter", {
+      status: 400,
+    });
+  }
+
+  const state = await pollChallenge(verifier);
+
+  if (!env.TOKENS) {
+    return new Response("Not Implemented: QR logins need a TOKENS KV namespace", {
+      status: 501,
+    });
+  }
+
+  const token = await env.TOKENS.get(state);
+
+  if (token === null) {
+    return new Response(null, {
+      status: 204,
+      headers: { "Cache-Control": "no-store" },
+    });
+  }
+
+  await env.TOKENS.delete(state);
+
+  return new Response(token, {
+    headers: { "Content-Type": "application/json", "Cache-Control": "no-store" },
+  });
+}
+
+/**
+ * Returns the state of a QR login with the given verifier: the unpadded
+ * base64url SHA-256 of it.
+ *
+ * @param {string} verifier
+ * @returns {Promise<string>}
+ */
+async function pollChallenge(verifier) {
+  const digest = await crypto.subtle.digest("SHA-256", new TextEncoder().encode(verifier));
+  const base64 = btoa(String.fromCharCode(...new Uint8Array(digest)));
+
+  return base64.replace(/\+/g, "-").replace(/\//g, "_").replace(/=+$/, "");
+}
@@ -1,15 +1,23 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
+
+	"github.com/gberlati/nube-cli/internal/history"
+	"github.com/gberlati/nube-cli/internal/journal"
+	"github.com/gberlati/nube-cli/internal/openapi"
 )
 
 const (
@@ -21,6 +29,11 @@ const (
 	defaultHTTPTimeout = 30 * time.Second
 )
 
+// DefaultMaxIdleConnsPerHost keeps enough idle connections to the API for
+// the bulk pool's workers and long-running proxy and daemon modes; the
+// standard library keeps only two.
+const DefaultMaxIdleConnsPerHost = 16
+
 // Client is the main HTTP client for the Tienda Nube API.
 type Client struct {
 	httpClient  *http.Client
@@ -29,6 +42,23 @@ type Client struct {
 	accessToken string
 	userAgent   string
 	timeout     time.Duration
+	transport   TransportOptions
+	mockDir     string
+	recordDir   string
+}
+
+// TransportOptions tune the connections of the client's default transport.
+// Zero values keep the defaults.
+type TransportOptions struct {
+	// MaxIdleConnsPerHost is how many idle connections to the API are kept
+	// for reuse (default DefaultMaxIdleConnsPerHost).
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout closes connections idle for longer (default 90s).
+	IdleConnTimeout time.Duration
+	// ForceHTTP2 speaks only HTTP/2, failing against servers without it.
+	ForceHTTP2 bool
+	// DisableKeepAlives uses a new connection for every request.
+	DisableKeepAlives bool
 }
 
 // Option configures a Client.
@@ -54,6 +84,12 @@ func WithTimeout(d time.Duration) Option {
 	return func(c *Client) { c.timeout = d }
 }
 
+// WithTransportOptions tunes the default transport. It has no effect
+// together with WithHTTPClient.
+func WithTransportOptions(o TransportOptions) Option {
+	return func(c *Client) { c.transport = o }
+}
+
 // New creates a new API client for the given store.
 // The storeID is the Tienda Nube user_id (store ID).
 func New(storeID, accessToken string, opts ...Option) *Client {
@@ -71,16 +107,43 @@ func New(storeID, accessToken string, opts ...Option) *Client {
 
 	if c.httpClient == nil {
 		c.httpClient = &http.Client{
-			Transport: NewRetryTransport(newBaseTransport()),
+			Transport: NewRetryTransport(newBaseTransport(c.transport)),
 			Timeout:   c.timeout,
 		}
 	}
 
+	c.withFixtures()
+
 	return c
 }
 
-// newBaseTransport creates an http.Transport with TLS 1.2+ enforcement.
-func newBaseTransport() *http.Transport {
+// newBaseTransport creates an http.Transport with TLS 1.2+ enforcement,
+// tuned by o.
+func newBaseTransport(o TransportOptions) *http.Transport {
+	transport := cloneDefaultTransport()
+
+	transport.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	if o.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+	}
+
+	if o.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = o.IdleConnTimeout
+	}
+
+	if o.ForceHTTP2 {
+		var protocols http.Protocols
+
+		protocols.SetHTTP2(true)
+		transport.Protocols = &protocols
+	}
+
+	transport.DisableKeepAlives = o.DisableKeepAlives
+
+	return transport
+}
+
+func cloneDefaultTransport() *http.Transport {
 	defaultTransport, ok := http.DefaultTransport.(*http.Transport)
 	if !ok || defaultTransport == nil {
 		return &http.Transport{
@@ -109,7 +172,30 @@ func (c *Client) url(path string) string {
 	return fmt.Sprintf("%s/%s/%s", strings.TrimRight(c.baseURL, "/"), c.storeID, strings.TrimLeft(path, "/"))
 }
 
+// relativePath turns an absolute URL path from a Link header (e.g.
+// /v1/123/products) back into a path relative to the store, so it can be
+// passed to Get again. Paths outside the store are returned unchanged.
+func (c *Client) relativePath(p string) string {
+	prefix := "/" + c.storeID + "/"
+
+	if base, err := url.Parse(c.baseURL); err == nil {
+		prefix = strings.TrimRight(base.Path, "/") + prefix
+	}
+
+	if rest, ok := strings.CutPrefix(p, prefix); ok {
+		return rest
+	}
+
+	return p
+}
+
 func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	if guard := RequestGuardFromContext(ctx); guard != nil {
+		if err := guard(method, path); err != nil {
+			return nil, err
+		}
+	}
+
 	req, err := http.NewRequestWithContext(ctx, method, c.url(path), body)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
@@ -124,16 +210,57 @@ func (c *Client) newRequest(ctx context.Context, method, path string, body io.Re
 }
 
 func (c *Client) do(req *http.Request) (*http.Response, error) {
+	done := hooksFromContext(req.Context()).request(req)
+
 	resp, err := c.httpClient.Do(req) //nolint:gosec // URL is constructed from configured base URL
 	if err != nil {
+		done(0, err)
+
 		return nil, fmt.Errorf("http request: %w", err)
 	}
 
+	RecorderFromContext(req.Context()).record(req, resp)
+	logResponse(req, resp)
+
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		done(resp.StatusCode, nil)
+
 		return resp, nil
 	}
 
-	return nil, parseErrorResponse(resp)
+	err = parseErrorResponse(resp)
+	done(resp.StatusCode, err)
+
+	return nil, err
+}
+
+// logResponse logs the response status and metadata headers at debug level
+// (shown with --verbose).
+func logResponse(req *http.Request, resp *http.Response) {
+	meta := ParseResponseMeta(resp.Header)
+	attrs := []any{"method", req.Method, "path", req.URL.Path, "status", resp.StatusCode}
+
+	if meta.RequestID != "" {
+		attrs = append(attrs, "request_id", meta.RequestID)
+	}
+
+	if meta.RateLimitRemaining >= 0 {
+		attrs = append(attrs, "rate_limit_remaining", meta.RateLimitRemaining)
+	}
+
+	if meta.RateLimitLimit >= 0 {
+		attrs = append(attrs, "rate_limit_limit", meta.RateLimitLimit)
+	}
+
+	if meta.RateLimitResetMS >= 0 {
+		attrs = append(attrs, "rate_limit_reset_ms", meta.RateLimitResetMS)
+	}
+
+	if meta.TotalCount >= 0 {
+		attrs = append(attrs, "total_count", meta.TotalCount)
+	}
+
+	slog.DebugContext(req.Context(), "api response", attrs...) //nolint:gosec // structured log, not injection
 }
 
 // Get performs a GET request to the given path.
@@ -152,32 +279,240 @@ func (c *Client) Get(ctx context.Context, path string, query url.Values) (*http.
 
 // Post performs a POST request with JSON body.
 func (c *Client) Post(ctx context.Context, path string, body io.Reader) (*http.Response, error) {
-	req, err := c.newRequest(ctx, http.MethodPost, path, body)
+	return c.doWrite(ctx, http.MethodPost, path, body)
+}
+
+// Put performs a PUT request with JSON body.
+func (c *Client) Put(ctx context.Context, path string, body io.Reader) (*http.Response, error) {
+	return c.doWrite(ctx, http.MethodPut, path, body)
+}
+
+// Patch performs a PATCH request with JSON body. The API's PATCH operations
+// update many variants at once, so they aren't snapshotted.
+func (c *Client) Patch(ctx context.Context, path string, body io.Reader) (*http.Response, error) {
+	return c.doWrite(ctx, http.MethodPatch, path, body)
+}
+
+// Delete performs a DELETE request.
+func (c *Client) Delete(ctx context.Context, path string) (*http.Response, error) {
+	return c.doWrite(ctx, http.MethodDelete, path, nil)
+}
+
+// doWrite sends a mutating request. When ctx carries a journal, the request
+// gets an Idempotency-Key and is recorded before sending and after the outcome
+// is known. Journal failures are logged, never fatal to the request.
+func (c *Client) doWrite(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	return c.write(ctx, method, path, body, nil, c.do)
+}
+
+// write is doWrite with the request finished by prepare, if set, and sent
+// with send, which may return an error status as a response (see Forward).
+func (c *Client) write(ctx context.Context, method, path string, body io.Reader, prepare func(*http.Request), send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if method == http.MethodPut || method == http.MethodDelete {
+		c.snapshot(ctx, method, path)
+	}
+
+	j := journal.FromContext(ctx)
+	if j == nil {
+		req, err := c.newRequest(ctx, method, path, body)
+		if err != nil {
+			return nil, err
+		}
+
+		if prepare != nil {
+			prepare(req)
+		}
+
+		return send(req)
+	}
+
+	var payload []byte
+
+	if body != nil {
+		b, err := io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("read request body: %w", err)
+		}
+
+		payload = b
+		body = bytes.NewReader(b)
+	}
+
+	req, err := c.newRequest(ctx, method, path, body)
 	if err != nil {
 		return nil, err
 	}
 
-	return c.do(req)
+	key := journal.KeyFromContext(ctx)
+	if key == "" {
+		if key, err = journal.NewID(); err != nil {
+			return nil, err
+		}
+	}
+
+	if prepare != nil {
+		prepare(req)
+	}
+
+	req.Header.Set("Idempotency-Key", key)
+
+	if jErr := j.Begin(key, c.storeID, method, path, payload); jErr != nil {
+		slog.WarnContext(ctx, "journal write failed", "error", jErr)
+	}
+
+	resp, err := send(req)
+
+	status, httpStatus, msg := journal.StatusOK, 0, ""
+	if resp != nil {
+		httpStatus = resp.StatusCode
+		if httpStatus >= http.StatusBadRequest {
+			status, msg = journal.StatusFailed, http.StatusText(httpStatus)
+		}
+	}
+
+	if err != nil {
+		status, httpStatus, msg = journalOutcome(err)
+	}
+
+	if jErr := j.Finish(key, status, httpStatus, msg); jErr != nil {
+		slog.WarnContext(ctx, "journal write failed", "error", jErr)
+	}
+
+	return resp, err
 }
 
-// Put performs a PUT request with JSON body.
-func (c *Client) Put(ctx context.Context, path string, body io.Reader) (*http.Response, error) {
-	req, err := c.newRequest(ctx, http.MethodPut, path, body)
+// snapshot saves the current state of the resource at path when ctx carries
+// a history store and the API can read it back. A resource that can't be
+// read is logged at debug level; the write proceeds regardless.
+func (c *Client) snapshot(ctx context.Context, method, path string) {
+	h := history.FromContext(ctx)
+	if h == nil || !readable(path) {
+		return
+	}
+
+	resp, err := c.Get(ctx, path, nil)
 	if err != nil {
-		return nil, err
+		slog.DebugContext(ctx, "no snapshot before write", "path", path, "error", err)
+		return
 	}
 
-	return c.do(req)
+	defer func() { _ = resp.Body.Close() }()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil || !json.Valid(b) {
+		slog.DebugContext(ctx, "no snapshot before write", "path", path, "error", err)
+		return
+	}
+
+	if _, err := h.Save(c.storeID, method, path, b); err != nil {
+		slog.WarnContext(ctx, "snapshot before write failed", "path", path, "error", err)
+	}
 }
 
-// Delete performs a DELETE request.
-func (c *Client) Delete(ctx context.Context, path string) (*http.Response, error) {
-	req, err := c.newRequest(ctx, http.MethodDelete, path, nil)
+// readable reports whether the API can GET path: the embedded API
+// description lists a GET for it, or doesn't know the path.
+func readable(path string) bool {
+	spec, err := openapi.Default()
+	if err != nil {
+		return true
+	}
+
+	methods := spec.Methods(path)
+
+	return methods == nil || slices.Contains(methods, http.MethodGet)
+}
+
+// journalOutcome classifies a write error: API rejections are definitive
+// failures, anything without a response leaves the outcome unknown.
+func journalOutcome(err error) (journal.Status, int, string) {
+	var (
+		apiErr  *APIError
+		valErr  *ValidationError
+		authErr *AuthError
+		payErr  *PaymentRequiredError
+		permErr *PermissionDeniedError
+		nfErr   *NotFoundError
+		cbErr   *CircuitBreakerError
+	)
+
+	switch {
+	case errors.As(err, &cbErr):
+		// Rejected locally; nothing was sent.
+		return journal.StatusFailed, 0, err.Error()
+	case errors.As(err, &apiErr):
+		return journal.StatusFailed, apiErr.StatusCode, err.Error()
+	case errors.As(err, &valErr):
+		return journal.StatusFailed, valErr.StatusCode, err.Error()
+	case errors.As(err, &authErr):
+		return journal.StatusFailed, http.StatusUnauthorized, err.Error()
+	case errors.As(err, &payErr):
+		return journal.StatusFailed, http.StatusPaymentRequired, err.Error()
+	case errors.As(err, &permErr):
+		return journal.StatusFailed, http.StatusForbidden, err.Error()
+	case errors.As(err, &nfErr):
+		return journal.StatusFailed, http.StatusNotFound, err.Error()
+	default:
+		return journal.StatusUnknown, 0, err.Error()
+	}
+}
+
+// StoreID returns the store (user) ID the client is scoped to.
+func (c *Client) StoreID() string {
+	return c.storeID
+}
+
+// BaseURL returns the store-scoped API root (base URL + store ID).
+func (c *Client) BaseURL() string {
+	return strings.TrimSuffix(c.url(""), "/")
+}
+
+// Forward sends a request to path and returns the raw response, including
+// non-2xx statuses, for callers that relay responses verbatim (e.g. the proxy).
+// Auth and User-Agent headers always come from the client; contentType is
+// passed through when set. Writes are journaled and snapshotted like those
+// of Post, Put and Delete.
+func (c *Client) Forward(ctx context.Context, method, path string, query url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	prepare := func(req *http.Request) {
+		if len(query) > 0 {
+			req.URL.RawQuery = query.Encode()
+		}
+
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+	}
+
+	if method != http.MethodGet && method != http.MethodHead && method != http.MethodOptions {
+		return c.write(ctx, method, path, body, prepare, c.send)
+	}
+
+	req, err := c.newRequest(ctx, method, path, body)
 	if err != nil {
 		return nil, err
 	}
 
-	return c.do(req)
+	prepare(req)
+
+	return c.send(req)
+}
+
+// send sends req and returns the raw response, including non-2xx statuses.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	done := hooksFromContext(ctx).request(req)
+
+	resp, err := c.httpClient.Do(req) //nolint:gosec // URL is constructed from configured base URL
+	if err != nil {
+		done(0, err)
+
+		return nil, fmt.Errorf("http request: %w", err)
+	}
+
+	RecorderFromContext(ctx).record(req, resp)
+	logResponse(req, resp)
+	done(resp.StatusCode, nil)
+
+	return resp, nil
 }
 
 // DecodeResponse reads and decodes a JSON response body into the given type.
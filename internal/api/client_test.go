@@ -7,10 +7,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/history"
+	"github.com/gberlati/nube-cli/internal/journal"
 )
 
 func newTestClient(t *testing.T, handler http.Handler) *api.Client {
@@ -470,3 +473,181 @@ func TestClient_ErrorResponseBodyParsing(t *testing.T) {
 		t.Errorf("error = %q, want containing 'name is required'", err.Error())
 	}
 }
+
+func TestClient_ForwardRelaysErrors(t *testing.T) {
+	t.Parallel()
+
+	var gotAuth, gotType string
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authentication")
+		gotType = r.Header.Get("Content-Type")
+
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code":404}`))
+	}))
+
+	resp, err := c.Forward(context.Background(), http.MethodPost, "products", nil, strings.NewReader(`{}`), "application/merge-patch+json")
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", resp.StatusCode)
+	}
+
+	if gotAuth != "bearer test-token" {
+		t.Errorf("Authentication = %q", gotAuth)
+	}
+
+	if gotType != "application/merge-patch+json" {
+		t.Errorf("Content-Type = %q", gotType)
+	}
+
+	if !strings.HasSuffix(c.BaseURL(), "/12345") {
+		t.Errorf("BaseURL() = %q", c.BaseURL())
+	}
+}
+
+func TestClient_WritesAreJournaled(t *testing.T) {
+	t.Parallel()
+
+	var gotKeys []string
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKeys = append(gotKeys, r.Header.Get("Idempotency-Key"))
+
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"id":["cannot delete"]}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+
+	j := journal.New(filepath.Join(t.TempDir(), "journal.jsonl"))
+	ctx := journal.WithJournal(context.Background(), j)
+
+	resp, err := c.Post(ctx, "products", strings.NewReader(`{"name":"x"}`))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+
+	_ = resp.Body.Close()
+
+	if _, err := c.Delete(ctx, "products/1"); err == nil {
+		t.Fatal("Delete() expected error")
+	}
+
+	entries, err := j.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("entries = %d, want 2", len(entries))
+	}
+
+	if entries[0].ID != gotKeys[0] || entries[0].Status != journal.StatusOK || string(entries[0].Body) != `{"name":"x"}` {
+		t.Errorf("post entry = %+v (key %q)", entries[0], gotKeys[0])
+	}
+
+	if entries[1].Status != journal.StatusFailed || entries[1].HTTPStatus != http.StatusUnprocessableEntity {
+		t.Errorf("delete entry = %+v", entries[1])
+	}
+}
+
+func TestClient_WriteReusesContextKey(t *testing.T) {
+	t.Parallel()
+
+	var gotKey string
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("Idempotency-Key")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+
+	j := journal.New(filepath.Join(t.TempDir(), "journal.jsonl"))
+	ctx := journal.WithKey(journal.WithJournal(context.Background(), j), "abc")
+
+	resp, err := c.Put(ctx, "products/1", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	_ = resp.Body.Close()
+
+	if gotKey != "abc" {
+		t.Errorf("Idempotency-Key = %q, want abc", gotKey)
+	}
+}
+
+func TestClient_SnapshotsBeforeUpdateAndDelete(t *testing.T) {
+	t.Parallel()
+
+	var methods []string
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		_, _ = w.Write([]byte(`{"id":1,"name":"before"}`))
+	}))
+
+	h := history.New(filepath.Join(t.TempDir(), "history.jsonl"))
+	ctx := history.WithHistory(context.Background(), h)
+
+	for _, send := range []func() (*http.Response, error){
+		func() (*http.Response, error) { return c.Put(ctx, "products/1", strings.NewReader(`{}`)) },
+		func() (*http.Response, error) { return c.Delete(ctx, "products/1") },
+		func() (*http.Response, error) { return c.Post(ctx, "products", strings.NewReader(`{}`)) },
+	} {
+		resp, err := send()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_ = resp.Body.Close()
+	}
+
+	want := []string{"GET", "PUT", "GET", "DELETE", "POST"}
+	if strings.Join(methods, ",") != strings.Join(want, ",") {
+		t.Errorf("methods = %v, want %v", methods, want)
+	}
+
+	entries, err := h.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 2 || entries[0].Method != "PUT" || string(entries[1].Snapshot) != `{"id":1,"name":"before"}` {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestClient_NoSnapshotWithoutGet(t *testing.T) {
+	t.Parallel()
+
+	var methods []string
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+
+	h := history.New(filepath.Join(t.TempDir(), "history.jsonl"))
+
+	// The API has no GET for a single address.
+	resp, err := c.Put(history.WithHistory(context.Background(), h), "customers/1/addresses/2", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = resp.Body.Close()
+
+	if strings.Join(methods, ",") != "PUT" {
+		t.Errorf("methods = %v, want only PUT", methods)
+	}
+}
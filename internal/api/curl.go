@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// TokenPlaceholder stands in for the access token in Curl output, so the
+// command can be shared and still run where NUBE_ACCESS_TOKEN is set.
+const TokenPlaceholder = "$NUBE_ACCESS_TOKEN"
+
+// Curl returns the curl command line equivalent to sending the request with
+// c, headers included. The access token is replaced by TokenPlaceholder
+// unless revealToken is set.
+func (c *Client) Curl(method, path string, query url.Values, body []byte, revealToken bool) (string, error) {
+	req, err := c.newRequest(context.Background(), method, path, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if len(query) > 0 {
+		req.URL.RawQuery = query.Encode()
+	}
+
+	args := []string{"curl", "-X", method, shellQuote(req.URL.String())}
+
+	for _, name := range slices.Sorted(maps.Keys(req.Header)) {
+		header := name + ": " + req.Header.Get(name)
+
+		if name == "Authentication" && !revealToken {
+			// Double quotes so the shell expands the placeholder.
+			args = append(args, "-H", `"`+name+": bearer "+TokenPlaceholder+`"`)
+
+			continue
+		}
+
+		args = append(args, "-H", shellQuote(header))
+	}
+
+	if len(body) > 0 {
+		args = append(args, "--data", shellQuote(string(body)))
+	}
+
+	return strings.Join(args, " "), nil
+}
+
+// shellQuote wraps s in single quotes for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,31 @@
+package api_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/api"
+)
+
+func TestClient_Curl(t *testing.T) {
+	t.Parallel()
+
+	c := api.New("12345", "test-token", api.WithBaseURL("https://api.example.com/v1"), api.WithUserAgent("nube-test"))
+
+	got, err := c.Curl("POST", "products", url.Values{"lang": {"es"}}, []byte(`{"name":"it's"}`), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `curl -X POST 'https://api.example.com/v1/12345/products?lang=es' -H "Authentication: bearer $NUBE_ACCESS_TOKEN"` +
+		` -H 'Content-Type: application/json' -H 'User-Agent: nube-test' --data '{"name":"it'\''s"}'`
+	if got != want {
+		t.Errorf("Curl =\n%s\nwant\n%s", got, want)
+	}
+
+	got, _ = c.Curl("GET", "products/1", nil, nil, true)
+	if want := `curl -X GET 'https://api.example.com/v1/12345/products/1' -H 'Authentication: bearer test-token'` +
+		` -H 'Content-Type: application/json' -H 'User-Agent: nube-test'`; got != want {
+		t.Errorf("revealed Curl =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,108 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/api"
+)
+
+func TestFixtures_RecordAndReplay(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Total-Count", "1")
+		w.Header().Set("Set-Cookie", "session=secret")
+
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		_, _ = w.Write([]byte(`[{"id":1}]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	rec := api.New("111", "tok", api.WithBaseURL(srv.URL+"/v1"), api.WithHTTPClient(srv.Client()), api.WithRecordDir(dir))
+
+	resp, err := rec.Get(context.Background(), "products", url.Values{"page": {"2"}})
+	if err != nil {
+		t.Fatalf("record Get() error = %v", err)
+	}
+
+	if _, err := api.DecodeResponse[[]map[string]any](resp); err != nil {
+		t.Fatalf("recorded body not readable: %v", err)
+	}
+
+	resp, err = rec.Delete(context.Background(), "products/1")
+	if err != nil {
+		t.Fatalf("record Delete() error = %v", err)
+	}
+
+	resp.Body.Close()
+
+	file := filepath.Join(dir, "GET", "products@page=2.json")
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatalf("fixture not written: %v", err)
+	}
+
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("fixture mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	b, _ := os.ReadFile(file)
+	if string(b) == "" || strings.Contains(string(b), "secret") {
+		t.Errorf("fixture = %s", b)
+	}
+
+	// Replay under a different store, with no server at all.
+	mock := api.New("222", "", api.WithMockDir(dir))
+
+	resp, err = mock.Get(context.Background(), "products", url.Values{"page": {"2"}})
+	if err != nil {
+		t.Fatalf("replay Get() error = %v", err)
+	}
+
+	if resp.Header.Get("X-Total-Count") != "1" {
+		t.Errorf("replayed headers = %v", resp.Header)
+	}
+
+	items, err := api.DecodeResponse[[]map[string]any](resp)
+	if err != nil || len(items) != 1 {
+		t.Errorf("replayed items = %v, err = %v", items, err)
+	}
+
+	resp, err = mock.Delete(context.Background(), "products/1")
+	if err != nil || resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("replay Delete() = %v, %v", resp, err)
+	}
+
+	resp.Body.Close()
+
+	_, err = mock.Get(context.Background(), "orders", nil)
+	if !errors.Is(err, api.ErrNoFixture) {
+		t.Errorf("missing fixture err = %v, want ErrNoFixture", err)
+	}
+}
+
+func TestFixturePath(t *testing.T) {
+	t.Parallel()
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://x/v1/1/products?per_page=30&page=2", nil)
+
+	got := api.FixturePath("fx", req, "products")
+	if want := filepath.Join("fx", "GET", "products@page=2&per_page=30.json"); got != want {
+		t.Errorf("FixturePath() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// graphQLPath is the store-relative GraphQL endpoint.
+const graphQLPath = "graphql"
+
+// GraphQLRequest is a GraphQL operation.
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	OperationName string         `json:"operationName,omitempty"`
+}
+
+// GraphQLError is one entry of a GraphQL response's "errors" array.
+type GraphQLError struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// code returns extensions.code, the conventional machine-readable error kind.
+func (e GraphQLError) code() string {
+	s, _ := e.Extensions["code"].(string)
+
+	return s
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []GraphQLError  `json:"errors"`
+}
+
+// GraphQL sends an operation to the store's GraphQL endpoint and returns the
+// response's data. Transport-level failures map like REST ones; errors
+// reported in the response body map by extensions.code onto the same typed
+// errors (UNAUTHENTICATED → AuthError, FORBIDDEN → PermissionDeniedError,
+// NOT_FOUND → NotFoundError, anything else → APIError). When the response has
+// both data and errors, the partial data is returned along with the error.
+//
+// Operations are sent directly, not journaled: a GraphQL request may or may
+// not mutate, and replaying queries would only add noise.
+func (c *Client) GraphQL(ctx context.Context, op GraphQLRequest) (json.RawMessage, error) {
+	body, err := json.Marshal(op)
+	if err != nil {
+		return nil, fmt.Errorf("encode graphql request: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, graphQLPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read graphql response: %w", err)
+	}
+
+	var out graphQLResponse
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("decode graphql response: %w", err)
+	}
+
+	data := out.Data
+	if string(data) == "null" {
+		data = nil
+	}
+
+	if len(out.Errors) > 0 {
+		return data, graphQLErrorFor(resp.StatusCode, out.Errors, string(raw))
+	}
+
+	return data, nil
+}
+
+// graphQLErrorFor maps the first error's code onto the REST error types; the
+// message lists every error.
+func graphQLErrorFor(status int, errs []GraphQLError, body string) error {
+	msgs := make([]string, 0, len(errs))
+
+	for _, e := range errs {
+		msg := e.Message
+		if len(e.Path) > 0 {
+			msg = fmt.Sprintf("%s (at %s)", msg, graphQLPathString(e.Path))
+		}
+
+		msgs = append(msgs, msg)
+	}
+
+	message := strings.Join(msgs, "; ")
+	code := errs[0].code()
+
+	switch code {
+	case "UNAUTHENTICATED":
+		return &AuthError{Message: message}
+	case "FORBIDDEN":
+		return &PermissionDeniedError{Message: message}
+	case "NOT_FOUND":
+		return &NotFoundError{Resource: "resource"}
+	default:
+		return &APIError{StatusCode: status, Code: code, Message: message, Body: body}
+	}
+}
+
+func graphQLPathString(path []any) string {
+	parts := make([]string, 0, len(path))
+
+	for _, p := range path {
+		parts = append(parts, fmt.Sprint(p))
+	}
+
+	return strings.Join(parts, ".")
+}
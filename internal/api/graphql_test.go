@@ -0,0 +1,99 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/api"
+)
+
+func TestClient_GraphQL(t *testing.T) {
+	t.Parallel()
+
+	var gotPath string
+
+	var gotReq api.GraphQLRequest
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		b, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(b, &gotReq)
+
+		_, _ = w.Write([]byte(`{"data":{"product":{"id":"1"}}}`))
+	}))
+
+	data, err := c.GraphQL(context.Background(), api.GraphQLRequest{
+		Query:     "query($id: ID!) { product(id: $id) { id } }",
+		Variables: map[string]any{"id": "1"},
+	})
+	if err != nil {
+		t.Fatalf("GraphQL() error = %v", err)
+	}
+
+	if gotPath != "/12345/graphql" || gotReq.Variables["id"] != "1" {
+		t.Errorf("request = %s %+v", gotPath, gotReq)
+	}
+
+	if string(data) != `{"product":{"id":"1"}}` {
+		t.Errorf("data = %s", data)
+	}
+}
+
+func TestClient_GraphQLErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		body     string
+		check    func(error) bool
+		wantData bool
+	}{
+		{
+			name:  "unauthenticated",
+			body:  `{"errors":[{"message":"bad token","extensions":{"code":"UNAUTHENTICATED"}}]}`,
+			check: api.IsAuthError,
+		},
+		{
+			name:  "forbidden",
+			body:  `{"errors":[{"message":"scope","extensions":{"code":"FORBIDDEN"}}]}`,
+			check: func(err error) bool { var e *api.PermissionDeniedError; return errors.As(err, &e) },
+		},
+		{
+			name:  "not found",
+			body:  `{"data":null,"errors":[{"message":"no product","extensions":{"code":"NOT_FOUND"}}]}`,
+			check: api.IsNotFoundError,
+		},
+		{
+			name: "partial data",
+			body: `{"data":{"a":1,"b":null},"errors":[{"message":"boom","path":["b",0]}]}`,
+			check: func(err error) bool {
+				var e *api.APIError
+				return errors.As(err, &e) && e.Message == "boom (at b.0)"
+			},
+			wantData: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(tt.body))
+			}))
+
+			data, err := c.GraphQL(context.Background(), api.GraphQLRequest{Query: "{ a b }"})
+			if !tt.check(err) {
+				t.Errorf("err = %#v", err)
+			}
+
+			if (data != nil) != tt.wantData {
+				t.Errorf("data = %s, want data: %v", data, tt.wantData)
+			}
+		})
+	}
+}
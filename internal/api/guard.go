@@ -0,0 +1,22 @@
+package api
+
+import "context"
+
+// RequestGuard vets a request before it is sent; a non-nil error aborts it.
+// path is relative to the store, e.g. "products/123".
+type RequestGuard func(method, path string) error
+
+type guardCtxKey struct{}
+
+// WithRequestGuard returns a context whose requests are checked by g first,
+// so callers can enforce restrictions on every endpoint a command touches.
+func WithRequestGuard(ctx context.Context, g RequestGuard) context.Context {
+	return context.WithValue(ctx, guardCtxKey{}, g)
+}
+
+// RequestGuardFromContext returns the guard set by WithRequestGuard, or nil.
+func RequestGuardFromContext(ctx context.Context) RequestGuard {
+	g, _ := ctx.Value(guardCtxKey{}).(RequestGuard)
+
+	return g
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Hooks observe the requests a client sends, for metrics and tracing. Either
+// field may be nil.
+type Hooks struct {
+	// Request is called before an API request is sent, retries included, and
+	// the func it returns once the outcome is known: the final HTTP status
+	// (0 when no response arrived) and the request's error, if any. It may
+	// add headers to req.
+	Request func(req *http.Request) func(status int, err error)
+	// Attempt is called after each HTTP attempt with its status (0 on a
+	// network error) and whether RetryTransport is about to repeat it.
+	Attempt func(req *http.Request, status int, retry bool)
+}
+
+type hooksCtxKey struct{}
+
+// WithHooks returns a context whose requests are reported to h, after any
+// hooks already in ctx.
+func WithHooks(ctx context.Context, h *Hooks) context.Context {
+	if prev := hooksFromContext(ctx); prev != nil {
+		h = chainHooks(prev, h)
+	}
+
+	return context.WithValue(ctx, hooksCtxKey{}, h)
+}
+
+func hooksFromContext(ctx context.Context) *Hooks {
+	h, _ := ctx.Value(hooksCtxKey{}).(*Hooks)
+
+	return h
+}
+
+func chainHooks(first, second *Hooks) *Hooks {
+	return &Hooks{
+		Request: func(req *http.Request) func(int, error) {
+			a, b := first.request(req), second.request(req)
+
+			return func(status int, err error) {
+				a(status, err)
+				b(status, err)
+			}
+		},
+		Attempt: func(req *http.Request, status int, retry bool) {
+			first.attempt(req, status, retry)
+			second.attempt(req, status, retry)
+		},
+	}
+}
+
+// request runs the Request hook; it is safe on nil hooks.
+func (h *Hooks) request(req *http.Request) func(int, error) {
+	if h == nil || h.Request == nil {
+		return func(int, error) {}
+	}
+
+	return h.Request(req)
+}
+
+func (h *Hooks) attempt(req *http.Request, status int, retry bool) {
+	if h != nil && h.Attempt != nil {
+		h.Attempt(req, status, retry)
+	}
+}
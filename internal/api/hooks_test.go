@@ -0,0 +1,116 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+)
+
+type attempt struct {
+	status int
+	retry  bool
+}
+
+func TestHooks_RequestAndAttempts(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Test-Hook") != "1" {
+			t.Errorf("hook header missing on attempt %d", calls.Load()+1)
+		}
+
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	rt := api.NewRetryTransport(srv.Client().Transport)
+	rt.BaseDelay = time.Millisecond
+	c := api.New("1", "tok", api.WithBaseURL(srv.URL), api.WithHTTPClient(&http.Client{Transport: rt}))
+
+	var (
+		attempts []attempt
+		outcomes []int
+		second   int
+	)
+
+	ctx := api.WithHooks(context.Background(), &api.Hooks{
+		Request: func(req *http.Request) func(int, error) {
+			req.Header.Set("X-Test-Hook", "1")
+
+			return func(status int, err error) {
+				if err != nil {
+					t.Errorf("outcome error = %v", err)
+				}
+
+				outcomes = append(outcomes, status)
+			}
+		},
+		Attempt: func(_ *http.Request, status int, retry bool) {
+			attempts = append(attempts, attempt{status, retry})
+		},
+	})
+	// Hooks added later run too.
+	ctx = api.WithHooks(ctx, &api.Hooks{Request: func(*http.Request) func(int, error) {
+		return func(int, error) { second++ }
+	}})
+
+	resp, err := c.Get(ctx, "store", nil)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	_ = resp.Body.Close()
+
+	want := []attempt{{http.StatusTooManyRequests, true}, {http.StatusOK, false}}
+	if len(attempts) != len(want) || attempts[0] != want[0] || attempts[1] != want[1] {
+		t.Errorf("attempts = %v, want %v", attempts, want)
+	}
+
+	if len(outcomes) != 1 || outcomes[0] != http.StatusOK || second != 1 {
+		t.Errorf("outcomes = %v, second hook calls = %d", outcomes, second)
+	}
+}
+
+func TestHooks_ErrorOutcome(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	var (
+		gotStatus int
+		gotErr    error
+	)
+
+	ctx := api.WithHooks(context.Background(), &api.Hooks{
+		Request: func(*http.Request) func(int, error) {
+			return func(status int, err error) { gotStatus, gotErr = status, err }
+		},
+	})
+
+	_, err := c.Get(ctx, "products/1", nil)
+
+	var notFound *api.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Get error = %v, want *api.NotFoundError", err)
+	}
+
+	if gotStatus != http.StatusNotFound || !errors.Is(gotErr, err) {
+		t.Errorf("outcome = %d, %v", gotStatus, gotErr)
+	}
+}
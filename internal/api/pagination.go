@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/http"
 	"net/url"
 	"strings"
@@ -58,6 +59,66 @@ func ParseLinkHeader(header string) PageInfo {
 	return info
 }
 
+// Page is one page of a paginated list.
+type Page[T any] struct {
+	// Number is the 1-based position of the page in the iteration.
+	Number int
+	Items  []T
+	Info   PageInfo
+}
+
+// Pages returns an iterator over the pages of a list endpoint, fetched lazily
+// by following Link headers. Items are decoded as a JSON array of T. A fetch
+// or decode error is yielded once and ends the iteration; stopping the range
+// early stops fetching.
+func Pages[T any](ctx context.Context, client *Client, path string, query url.Values) iter.Seq2[Page[T], error] {
+	return pages(ctx, client, path, query, DecodeResponse[[]T])
+}
+
+func pages[T any](
+	ctx context.Context,
+	client *Client,
+	path string,
+	query url.Values,
+	decode func(*http.Response) ([]T, error),
+) iter.Seq2[Page[T], error] {
+	return func(yield func(Page[T], error) bool) {
+		currentPath := path
+		currentQuery := query
+
+		for n := 1; ; n++ {
+			resp, err := client.Get(ctx, currentPath, currentQuery) //nolint:bodyclose // decode callback closes body
+			if err != nil {
+				yield(Page[T]{}, fmt.Errorf("fetch page: %w", err))
+				return
+			}
+
+			// Read Link header before decode closes the body.
+			info := ParseLinkHeader(resp.Header.Get("Link"))
+
+			items, err := decode(resp)
+			if err != nil {
+				yield(Page[T]{}, fmt.Errorf("decode page: %w", err))
+				return
+			}
+
+			if !yield(Page[T]{Number: n, Items: items, Info: info}, nil) || !info.HasNext() {
+				return
+			}
+
+			// Parse the next URL to extract path and query.
+			nextURL, err := url.Parse(info.Next)
+			if err != nil {
+				yield(Page[T]{}, fmt.Errorf("parse next page URL: %w", err))
+				return
+			}
+
+			currentPath = client.relativePath(nextURL.Path)
+			currentQuery = nextURL.Query()
+		}
+	}
+}
+
 // CollectAllPages follows pagination links to collect all items.
 // The decode function is called for each page response to extract items.
 func CollectAllPages[T any](
@@ -69,42 +130,12 @@ func CollectAllPages[T any](
 ) ([]T, error) {
 	var all []T
 
-	currentPath := path
-	currentQuery := query
-
-	for {
-		resp, err := client.Get(ctx, currentPath, currentQuery) //nolint:bodyclose // decode callback closes body
+	for page, err := range pages(ctx, client, path, query, decode) {
 		if err != nil {
-			return nil, fmt.Errorf("fetch page: %w", err)
-		}
-
-		// Read Link header before decode closes the body.
-		linkHeader := resp.Header.Get("Link")
-
-		items, decodeErr := decode(resp)
-		if decodeErr != nil {
-			return nil, fmt.Errorf("decode page: %w", decodeErr)
-		}
-
-		all = append(all, items...)
-
-		if linkHeader == "" {
-			break
-		}
-
-		pageInfo := ParseLinkHeader(linkHeader)
-		if !pageInfo.HasNext() {
-			break
-		}
-
-		// Parse the next URL to extract path and query.
-		nextURL, parseErr := url.Parse(pageInfo.Next)
-		if parseErr != nil {
-			return nil, fmt.Errorf("parse next page URL: %w", parseErr)
+			return nil, err
 		}
 
-		currentPath = nextURL.Path
-		currentQuery = nextURL.Query()
+		all = append(all, page.Items...)
 	}
 
 	return all, nil
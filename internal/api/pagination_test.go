@@ -124,8 +124,12 @@ func TestCollectAllPages(t *testing.T) {
 
 		page := 0
 
+		var paths []string
+
 		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			page++
+			paths = append(paths, r.URL.Path)
+
 			switch page {
 			case 1:
 				w.Header().Set("Link", fmt.Sprintf(`<%s/12345/products?page=2>; rel="next"`, "http://"+r.Host))
@@ -156,6 +160,12 @@ func TestCollectAllPages(t *testing.T) {
 		if len(items) != 4 {
 			t.Errorf("got %d items, want 4", len(items))
 		}
+
+		for _, p := range paths {
+			if p != "/12345/products" {
+				t.Errorf("requested %s, want /12345/products", p)
+			}
+		}
 	})
 
 	t.Run("single page", func(t *testing.T) {
@@ -219,3 +229,90 @@ func TestCollectAllPages(t *testing.T) {
 		}
 	})
 }
+
+func TestPages(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		ID int `json:"id"`
+	}
+
+	newServer := func(t *testing.T, requests *int) *api.Client {
+		t.Helper()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*requests++
+
+			if r.URL.Query().Get("page") == "" {
+				w.Header().Set("Link", fmt.Sprintf(`<http://%s/v1/1/products?page=2>; rel="next", <http://%s/v1/1/products?page=2>; rel="last"`, r.Host, r.Host))
+				_, _ = w.Write([]byte(`[{"id":1},{"id":2}]`))
+
+				return
+			}
+
+			_, _ = w.Write([]byte(`[{"id":3}]`))
+		}))
+		t.Cleanup(srv.Close)
+
+		return api.New("1", "tok", api.WithBaseURL(srv.URL+"/v1"), api.WithHTTPClient(srv.Client()))
+	}
+
+	t.Run("yields every page", func(t *testing.T) {
+		t.Parallel()
+
+		requests := 0
+		c := newServer(t, &requests)
+
+		var got []int
+
+		for page, err := range api.Pages[item](context.Background(), c, "products", nil) {
+			if err != nil {
+				t.Fatalf("page %d: %v", page.Number, err)
+			}
+
+			if page.Number == 1 && !page.Info.HasNext() {
+				t.Error("page 1 should have a next link")
+			}
+
+			for _, it := range page.Items {
+				got = append(got, it.ID)
+			}
+		}
+
+		if fmt.Sprint(got) != "[1 2 3]" || requests != 2 {
+			t.Errorf("items = %v after %d requests", got, requests)
+		}
+	})
+
+	t.Run("stops fetching on break", func(t *testing.T) {
+		t.Parallel()
+
+		requests := 0
+		c := newServer(t, &requests)
+
+		for range api.Pages[item](context.Background(), c, "products", nil) {
+			break
+		}
+
+		if requests != 1 {
+			t.Errorf("requests = %d, want 1", requests)
+		}
+	})
+
+	t.Run("yields errors", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		t.Cleanup(srv.Close)
+
+		c := api.New("1", "tok", api.WithBaseURL(srv.URL), api.WithHTTPClient(srv.Client()))
+
+		for _, err := range api.Pages[item](context.Background(), c, "products", nil) {
+			if !api.IsNotFoundError(err) {
+				t.Errorf("err = %v, want not found", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,11 @@
+package api
+
+// PartnerBaseURL is the Tienda Nube partners API base URL.
+const PartnerBaseURL = "https://partners.tiendanube.com/v1"
+
+// NewPartner creates a client for the partners API, scoped to partnerID
+// the way New scopes a client to a store: paths are relative to
+// PartnerBaseURL/partnerID.
+func NewPartner(partnerID, accessToken string, opts ...Option) *Client {
+	return New(partnerID, accessToken, append([]Option{WithBaseURL(PartnerBaseURL)}, opts...)...)
+}
@@ -0,0 +1,299 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultPoolWorkers is the default number of concurrent pool jobs.
+	DefaultPoolWorkers = 4
+	// DefaultRateLimitBurst is the Tienda Nube bucket size (requests).
+	DefaultRateLimitBurst = 40
+	// DefaultRateLimitRefill is the Tienda Nube bucket refill rate (requests per second).
+	DefaultRateLimitRefill = 2
+)
+
+// Job is one unit of bulk work. Requests it makes with ctx are throttled by
+// the pool's shared rate budget and counted in its stats.
+type Job func(ctx context.Context) error
+
+// Pool runs bulk jobs with bounded concurrency. All requests made through a
+// pool share one token bucket, kept in sync with the API's rate-limit headers,
+// so parallel workers back off together instead of each tripping 429s.
+// Retries of 429 and 5xx responses are handled by RetryTransport and also
+// draw from the shared budget.
+type Pool struct {
+	workers int
+	bucket  *tokenBucket
+
+	requests atomic.Int64
+	retries  atomic.Int64
+	failed   atomic.Int64
+	elapsed  atomic.Int64
+}
+
+// PoolStats summarizes the work done by a pool.
+type PoolStats struct {
+	// Requests counts every HTTP attempt, retries included.
+	Requests int
+	// Retries counts attempts repeated after a 429 or 5xx response.
+	Retries int
+	// Failed counts jobs that returned an error.
+	Failed int
+	// Duration is the wall time spent in Run.
+	Duration time.Duration
+}
+
+// MarshalJSON emits snake_case keys with the duration in milliseconds.
+func (s PoolStats) MarshalJSON() ([]byte, error) {
+	return fmt.Appendf(nil, `{"requests":%d,"retries":%d,"failed":%d,"duration_ms":%d}`,
+		s.Requests, s.Retries, s.Failed, s.Duration.Milliseconds()), nil
+}
+
+// String renders the stats as a one-line report.
+func (s PoolStats) String() string {
+	return fmt.Sprintf("%d requests, %d retries, %d failed in %s",
+		s.Requests, s.Retries, s.Failed, s.Duration.Round(time.Millisecond))
+}
+
+// PoolOption configures a Pool.
+type PoolOption func(*Pool)
+
+// WithWorkers sets the number of jobs run concurrently.
+func WithWorkers(n int) PoolOption {
+	return func(p *Pool) {
+		if n > 0 {
+			p.workers = n
+		}
+	}
+}
+
+// WithRateLimit sets the initial bucket size and refill rate (requests per
+// second). Rate-limit headers seen on responses override the burst.
+func WithRateLimit(burst int, perSecond float64) PoolOption {
+	return func(p *Pool) {
+		if burst > 0 && perSecond > 0 {
+			p.bucket = newTokenBucket(float64(burst), perSecond)
+		}
+	}
+}
+
+// NewPool creates a Pool with DefaultPoolWorkers workers and the Tienda Nube
+// default rate budget.
+func NewPool(opts ...PoolOption) *Pool {
+	p := &Pool{
+		workers: DefaultPoolWorkers,
+		bucket:  newTokenBucket(DefaultRateLimitBurst, DefaultRateLimitRefill),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Run executes jobs and returns their errors, indexed like jobs (nil for
+// jobs that succeeded). Jobs not started before ctx is done get ctx's error.
+func (p *Pool) Run(ctx context.Context, jobs []Job) []error {
+	start := time.Now()
+	defer func() { p.elapsed.Add(int64(time.Since(start))) }()
+
+	errs := make([]error, len(jobs))
+	jobCtx := withPool(ctx, p)
+
+	var wg sync.WaitGroup
+
+	sem := make(chan struct{}, p.workers)
+
+	for i, job := range jobs {
+		if err := acquireSlot(ctx, sem); err != nil {
+			errs[i] = err
+			p.failed.Add(1)
+
+			continue
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := job(jobCtx); err != nil {
+				errs[i] = err
+				p.failed.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return errs
+}
+
+// acquireSlot takes a worker slot, failing once ctx is done even if a slot
+// is free.
+func acquireSlot(ctx context.Context, sem chan struct{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	select {
+	case sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns the pool's counters, accumulated across Run calls.
+func (p *Pool) Stats() PoolStats {
+	return PoolStats{
+		Requests: int(p.requests.Load()),
+		Retries:  int(p.retries.Load()),
+		Failed:   int(p.failed.Load()),
+		Duration: time.Duration(p.elapsed.Load()),
+	}
+}
+
+type poolCtxKey struct{}
+
+func withPool(ctx context.Context, p *Pool) context.Context {
+	return context.WithValue(ctx, poolCtxKey{}, p)
+}
+
+func poolFromContext(ctx context.Context) *Pool {
+	p, _ := ctx.Value(poolCtxKey{}).(*Pool)
+
+	return p
+}
+
+// acquire waits for a request token. A nil pool never blocks.
+func (p *Pool) acquire(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+
+	p.requests.Add(1)
+
+	return p.bucket.take(ctx)
+}
+
+// observe feeds a response's rate-limit headers into the shared bucket.
+func (p *Pool) observe(resp *http.Response) {
+	if p == nil || resp == nil {
+		return
+	}
+
+	p.bucket.observe(resp)
+}
+
+func (p *Pool) retried() {
+	if p != nil {
+		p.retries.Add(1)
+	}
+}
+
+// tokenBucket is a mutex-guarded token bucket. Tokens refill continuously at
+// rate per second up to capacity; a 429 empties it until the server's reset.
+type tokenBucket struct {
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	rate     float64
+	last     time.Time
+	paused   time.Time
+}
+
+func newTokenBucket(capacity, rate float64) *tokenBucket {
+	return &tokenBucket{capacity: capacity, tokens: capacity, rate: rate, last: time.Now()}
+}
+
+// take blocks until a token is available or ctx is done.
+func (b *tokenBucket) take(ctx context.Context) error {
+	for {
+		wait := b.reserve(time.Now())
+		if wait <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+
+			return fmt.Errorf("wait for rate limit: %w", ctx.Err())
+		}
+	}
+}
+
+// reserve takes a token if one is available and returns zero, or returns how
+// long to wait before trying again.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.Before(b.paused) {
+		return b.paused.Sub(now)
+	}
+
+	b.refill(now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+
+		return 0
+	}
+
+	return time.Duration(math.Ceil((1 - b.tokens) / b.rate * float64(time.Second)))
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.capacity, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// observe trusts the server's view of the bucket when it is tighter than
+// ours: X-Rate-Limit-Limit sets the capacity, X-Rate-Limit-Remaining caps the
+// available tokens, and a 429 pauses every caller until X-Rate-Limit-Reset.
+func (b *tokenBucket) observe(resp *http.Response) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.refill(now)
+
+	if n, err := strconv.Atoi(resp.Header.Get(headerRateLimitLimit)); err == nil && n > 0 {
+		b.capacity = float64(n)
+	}
+
+	if n, err := strconv.Atoi(resp.Header.Get(headerRateLimitRemaining)); err == nil && n >= 0 {
+		b.tokens = min(b.tokens, float64(n))
+	}
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return
+	}
+
+	b.tokens = 0
+
+	if ms, err := strconv.Atoi(resp.Header.Get(headerRateLimitReset)); err == nil && ms > 0 {
+		if until := now.Add(time.Duration(ms) * time.Millisecond); until.After(b.paused) {
+			b.paused = until
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newPoolTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	rt := NewRetryTransport(srv.Client().Transport)
+	rt.BaseDelay = time.Millisecond
+
+	return New("123", "tok", WithBaseURL(srv.URL), WithHTTPClient(&http.Client{Transport: rt}))
+}
+
+func TestPool_BoundsConcurrency(t *testing.T) {
+	t.Parallel()
+
+	var inFlight, peak atomic.Int32
+
+	c := newPoolTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	p := NewPool(WithWorkers(2), WithRateLimit(100, 1000))
+
+	jobs := make([]Job, 6)
+	for i := range jobs {
+		jobs[i] = func(ctx context.Context) error {
+			resp, err := c.Get(ctx, "products", nil)
+			if err != nil {
+				return err
+			}
+
+			return resp.Body.Close()
+		}
+	}
+
+	for i, err := range p.Run(context.Background(), jobs) {
+		if err != nil {
+			t.Errorf("job %d: %v", i, err)
+		}
+	}
+
+	if got := peak.Load(); got != 2 {
+		t.Errorf("peak concurrency = %d, want 2", got)
+	}
+
+	if s := p.Stats(); s.Requests != 6 || s.Retries != 0 || s.Failed != 0 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestPool_CountsRetriesAndFailures(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	c := newPoolTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/123/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if calls.Add(1) == 1 {
+			w.Header().Set(headerRateLimitReset, "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	get := func(path string) Job {
+		return func(ctx context.Context) error {
+			resp, err := c.Get(ctx, path, nil)
+			if err != nil {
+				return err
+			}
+
+			return resp.Body.Close()
+		}
+	}
+
+	p := NewPool(WithWorkers(1), WithRateLimit(100, 1000))
+	errs := p.Run(context.Background(), []Job{get("products"), get("missing")})
+
+	if errs[0] != nil {
+		t.Errorf("job 0: %v", errs[0])
+	}
+
+	if !IsNotFoundError(errs[1]) {
+		t.Errorf("job 1 = %v, want not found", errs[1])
+	}
+
+	s := p.Stats()
+	if s.Requests != 3 || s.Retries != 1 || s.Failed != 1 {
+		t.Errorf("stats = %+v, want 3 requests, 1 retry, 1 failed", s)
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil || m["retries"] != float64(1) {
+		t.Errorf("json = %s", b)
+	}
+}
+
+func TestPool_CancelledContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var ran atomic.Int32
+
+	job := func(context.Context) error {
+		ran.Add(1)
+		return nil
+	}
+
+	p := NewPool()
+
+	for i, err := range p.Run(ctx, []Job{job, job}) {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("job %d = %v, want context.Canceled", i, err)
+		}
+	}
+
+	if ran.Load() != 0 {
+		t.Errorf("%d jobs ran after cancellation", ran.Load())
+	}
+
+	if s := p.Stats(); s.Failed != 2 {
+		t.Errorf("failed = %d, want 2", s.Failed)
+	}
+}
+
+func TestTokenBucket_Reserve(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now()
+	b := &tokenBucket{capacity: 2, tokens: 2, rate: 10, last: start}
+
+	if d := b.reserve(start); d != 0 {
+		t.Fatalf("first reserve wait = %v", d)
+	}
+
+	if d := b.reserve(start); d != 0 {
+		t.Fatalf("second reserve wait = %v", d)
+	}
+
+	if d := b.reserve(start); d != 100*time.Millisecond {
+		t.Errorf("empty bucket wait = %v, want 100ms", d)
+	}
+
+	if d := b.reserve(start.Add(100 * time.Millisecond)); d != 0 {
+		t.Errorf("after refill wait = %v, want 0", d)
+	}
+}
+
+func TestTokenBucket_Observe(t *testing.T) {
+	t.Parallel()
+
+	b := newTokenBucket(40, 2)
+
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	resp.Header.Set(headerRateLimitLimit, "80")
+	resp.Header.Set(headerRateLimitRemaining, "3")
+	b.observe(resp)
+
+	if b.capacity != 80 || b.tokens > 3.5 {
+		t.Errorf("capacity = %v, tokens = %v; want 80 and ~3", b.capacity, b.tokens)
+	}
+
+	resp = &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set(headerRateLimitReset, "500")
+	b.observe(resp)
+
+	if d := b.reserve(time.Now()); d < 400*time.Millisecond {
+		t.Errorf("wait after 429 = %v, want ~500ms", d)
+	}
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// Recorder accumulates response metadata for every request made with a
+// context carrying it. Commands attach one to report request accounting
+// (e.g. the --envelope meta block) without threading state through callers.
+type Recorder struct {
+	mu          sync.Mutex
+	requests    int
+	pages       int
+	retries     int
+	rateLimited int
+	meta        ResponseMeta
+	hasMeta     bool
+	links       PageInfo
+
+	bytes atomic.Int64
+}
+
+// RecorderSnapshot is a point-in-time copy of a Recorder's counters.
+type RecorderSnapshot struct {
+	// Requests counts every completed API response.
+	Requests int
+	// Pages counts successful GET responses (one per page for list endpoints).
+	Pages int
+	// Retries counts attempts RetryTransport repeated after a 429 or 5xx.
+	Retries int
+	// RateLimited counts 429 responses, retried or not.
+	RateLimited int
+	// BytesReceived counts response body bytes read so far.
+	BytesReceived int64
+	// ResponseMeta holds the latest value seen for each metadata header.
+	ResponseMeta
+	// Links is the Link header of the latest list page, so a listing can
+	// be resumed where it stopped.
+	Links PageInfo
+}
+
+// ResponseMeta is the request accounting Tienda Nube sends in response
+// headers. Numeric fields are -1 when the header was absent.
+type ResponseMeta struct {
+	// RequestID is X-Request-Id, the reference support asks for.
+	RequestID string
+	// RateLimitLimit is X-Rate-Limit-Limit, the bucket size.
+	RateLimitLimit int
+	// RateLimitRemaining is X-Rate-Limit-Remaining.
+	RateLimitRemaining int
+	// RateLimitResetMS is X-Rate-Limit-Reset, milliseconds until the bucket empties.
+	RateLimitResetMS int
+	// TotalCount is X-Total-Count, the size of a list across all pages.
+	TotalCount int
+}
+
+// ParseResponseMeta reads the metadata headers of a response.
+func ParseResponseMeta(h http.Header) ResponseMeta {
+	return ResponseMeta{
+		RequestID:          h.Get("X-Request-Id"),
+		RateLimitLimit:     headerInt(h, headerRateLimitLimit),
+		RateLimitRemaining: headerInt(h, headerRateLimitRemaining),
+		RateLimitResetMS:   headerInt(h, headerRateLimitReset),
+		TotalCount:         headerInt(h, "X-Total-Count"),
+	}
+}
+
+func emptyResponseMeta() ResponseMeta {
+	return ResponseMeta{RateLimitLimit: -1, RateLimitRemaining: -1, RateLimitResetMS: -1, TotalCount: -1}
+}
+
+func headerInt(h http.Header, key string) int {
+	n, err := strconv.Atoi(h.Get(key))
+	if err != nil || n < 0 {
+		return -1
+	}
+
+	return n
+}
+
+// merge overwrites m with the fields present in o.
+func (m *ResponseMeta) merge(o ResponseMeta) {
+	if o.RequestID != "" {
+		m.RequestID = o.RequestID
+	}
+
+	m.RateLimitLimit = latest(m.RateLimitLimit, o.RateLimitLimit)
+	m.RateLimitRemaining = latest(m.RateLimitRemaining, o.RateLimitRemaining)
+	m.RateLimitResetMS = latest(m.RateLimitResetMS, o.RateLimitResetMS)
+	m.TotalCount = latest(m.TotalCount, o.TotalCount)
+}
+
+func latest(cur, next int) int {
+	if next >= 0 {
+		return next
+	}
+
+	return cur
+}
+
+type recorderCtxKey struct{}
+
+// WithRecorder attaches a Recorder to the context.
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderCtxKey{}, r)
+}
+
+// RecorderFromContext returns the Recorder attached to ctx, or nil.
+func RecorderFromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(recorderCtxKey{}).(*Recorder)
+
+	return r
+}
+
+func (r *Recorder) record(req *http.Request, resp *http.Response) {
+	if r == nil || resp == nil {
+		return
+	}
+
+	resp.Body = &countingBody{ReadCloser: resp.Body, n: &r.bytes}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests++
+
+	if req.Method == http.MethodGet && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		r.pages++
+
+		// List endpoints send X-Total-Count, and Link when there is more
+		// than one page; the last page of a list replaces earlier links.
+		if resp.Header.Get("Link") != "" || resp.Header.Get("X-Total-Count") != "" {
+			r.links = ParseLinkHeader(resp.Header.Get("Link"))
+		}
+	}
+
+	if !r.hasMeta {
+		r.meta = emptyResponseMeta()
+		r.hasMeta = true
+	}
+
+	r.meta.merge(ParseResponseMeta(resp.Header))
+}
+
+// attempt counts an HTTP attempt that RetryTransport saw fail with status.
+func (r *Recorder) attempt(status int, retry bool) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if status == http.StatusTooManyRequests {
+		r.rateLimited++
+	}
+
+	if retry {
+		r.retries++
+	}
+}
+
+// countingBody adds the bytes read from a response body to n.
+type countingBody struct {
+	io.ReadCloser
+	n *atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+
+	return n, err //nolint:wrapcheck // io.Reader contract: return io.EOF as is
+}
+
+// Snapshot returns the current counters.
+func (r *Recorder) Snapshot() RecorderSnapshot {
+	if r == nil {
+		return RecorderSnapshot{ResponseMeta: emptyResponseMeta()}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snap := RecorderSnapshot{
+		Requests:      r.requests,
+		Pages:         r.pages,
+		Retries:       r.retries,
+		RateLimited:   r.rateLimited,
+		BytesReceived: r.bytes.Load(),
+		ResponseMeta:  emptyResponseMeta(),
+		Links:         r.links,
+	}
+
+	if r.hasMeta {
+		snap.ResponseMeta = r.meta
+	}
+
+	return snap
+}
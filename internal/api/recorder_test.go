@@ -0,0 +1,177 @@
+package api_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/api"
+)
+
+func TestRecorder_RecordsResponses(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Rate-Limit-Remaining", "12")
+
+		if r.Method == http.MethodDelete {
+			w.Header().Set("X-Request-Id", "abc123")
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.Header().Set("X-Total-Count", "250")
+
+		_, _ = w.Write([]byte(`{}`))
+	}))
+
+	rec := &api.Recorder{}
+	ctx := api.WithRecorder(context.Background(), rec)
+
+	resp, err := c.Get(ctx, "products", nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	resp.Body.Close()
+
+	if _, err := c.Delete(ctx, "products/1"); err == nil {
+		t.Fatal("expected Delete() error")
+	}
+
+	snap := rec.Snapshot()
+	if snap.Requests != 2 {
+		t.Errorf("Requests = %d, want 2", snap.Requests)
+	}
+
+	if snap.Pages != 1 {
+		t.Errorf("Pages = %d, want 1", snap.Pages)
+	}
+
+	if snap.RateLimitRemaining != 12 {
+		t.Errorf("RateLimitRemaining = %d, want 12", snap.RateLimitRemaining)
+	}
+
+	if snap.RequestID != "abc123" || snap.TotalCount != 250 || snap.RateLimitLimit != -1 {
+		t.Errorf("meta = %+v, want request abc123, total 250, no limit", snap.ResponseMeta)
+	}
+}
+
+func TestParseResponseMeta(t *testing.T) {
+	t.Parallel()
+
+	h := http.Header{}
+	h.Set("X-Request-Id", "r1")
+	h.Set("X-Rate-Limit-Limit", "40")
+	h.Set("X-Rate-Limit-Remaining", "0")
+	h.Set("X-Rate-Limit-Reset", "1500")
+	h.Set("X-Total-Count", "bogus")
+
+	want := api.ResponseMeta{RequestID: "r1", RateLimitLimit: 40, RateLimitRemaining: 0, RateLimitResetMS: 1500, TotalCount: -1}
+	if got := api.ParseResponseMeta(h); got != want {
+		t.Errorf("ParseResponseMeta() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRecorder_Links(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "1":
+			w.Header().Set("Link", `<https://api.example/v1/123/products?page=2>; rel="next"`)
+			w.Header().Set("X-Total-Count", "60")
+		case "2":
+			w.Header().Set("X-Total-Count", "60")
+		}
+
+		_, _ = w.Write([]byte(`{}`))
+	}))
+
+	rec := &api.Recorder{}
+	ctx := api.WithRecorder(context.Background(), rec)
+
+	get := func(page string) {
+		t.Helper()
+
+		q := url.Values{}
+		if page != "" {
+			q.Set("page", page)
+		}
+
+		resp, err := c.Get(ctx, "products", q)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+
+		resp.Body.Close()
+	}
+
+	get("1")
+	get("") // not a list: keeps the links
+
+	if got := rec.Snapshot().Links.Next; got != "https://api.example/v1/123/products?page=2" {
+		t.Errorf("Links.Next = %q after page 1", got)
+	}
+
+	get("2")
+
+	if got := rec.Snapshot().Links; got.HasNext() {
+		t.Errorf("Links = %+v after the last page, want no next", got)
+	}
+}
+
+func TestRecorder_NilSnapshot(t *testing.T) {
+	t.Parallel()
+
+	var rec *api.Recorder
+	if got := rec.Snapshot().RateLimitRemaining; got != -1 {
+		t.Errorf("RateLimitRemaining = %d, want -1", got)
+	}
+
+	if api.RecorderFromContext(context.Background()) != nil {
+		t.Error("expected nil recorder")
+	}
+}
+
+func TestRecorder_CountsRetriesAndBytes(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.Header().Set("X-Rate-Limit-Reset", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			_, _ = w.Write([]byte(`{"id":1}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	rt := api.NewRetryTransport(srv.Client().Transport)
+	c := api.New("1", "tok", api.WithBaseURL(srv.URL), api.WithHTTPClient(&http.Client{Transport: rt}))
+
+	rec := &api.Recorder{}
+
+	resp, err := c.Get(api.WithRecorder(context.Background(), rec), "products/1", nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	snap := rec.Snapshot()
+	if snap.Requests != 1 || snap.Retries != 2 || snap.RateLimited != 1 || snap.BytesReceived != int64(len(`{"id":1}`)) {
+		t.Errorf("snapshot = %+v, want 1 request, 2 retries, 1 rate-limited, 8 bytes", snap)
+	}
+}
@@ -69,6 +69,10 @@ func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	retries429 := 0
 	retries5xx := 0
 
+	pool := poolFromContext(req.Context())
+	hooks := hooksFromContext(req.Context())
+	recorder := RecorderFromContext(req.Context())
+
 	for {
 		// Reset body for retry.
 		if req.GetBody != nil {
@@ -84,15 +88,23 @@ func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 			req.Body = body
 		}
 
+		if waitErr := pool.acquire(req.Context()); waitErr != nil {
+			return nil, waitErr
+		}
+
 		resp, err = t.Base.RoundTrip(req)
 		if err != nil {
+			hooks.attempt(req, 0, false)
 			t.recordFailure()
 
 			return nil, fmt.Errorf("round trip: %w", err)
 		}
 
+		pool.observe(resp)
+
 		// Success or non-retryable client error.
 		if resp.StatusCode < 400 {
+			hooks.attempt(req, resp.StatusCode, false)
 			t.recordSuccess()
 
 			return resp, nil
@@ -100,6 +112,9 @@ func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 
 		// Rate limit (429).
 		if resp.StatusCode == http.StatusTooManyRequests {
+			hooks.attempt(req, resp.StatusCode, retries429 < t.MaxRetries429)
+			recorder.attempt(resp.StatusCode, retries429 < t.MaxRetries429)
+
 			if retries429 >= t.MaxRetries429 {
 				t.recordFailure()
 
@@ -107,7 +122,7 @@ func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 			}
 
 			delay := t.calculateBackoff(retries429, resp)
-			slog.Debug("rate limited, retrying", //nolint:gosec // structured log, not injection
+			slog.DebugContext(req.Context(), "rate limited, retrying", //nolint:gosec // structured log, not injection
 				"delay", delay,
 				"attempt", retries429+1,
 				"max_retries", t.MaxRetries429,
@@ -121,18 +136,23 @@ func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 
 			retries429++
 
+			pool.retried()
+
 			continue
 		}
 
 		// Server error (5xx).
 		if resp.StatusCode >= 500 {
+			hooks.attempt(req, resp.StatusCode, retries5xx < t.MaxRetries5xx)
+			recorder.attempt(resp.StatusCode, retries5xx < t.MaxRetries5xx)
+
 			if retries5xx >= t.MaxRetries5xx {
 				t.recordFailure()
 
 				return resp, nil
 			}
 
-			slog.Debug("server error, retrying", //nolint:gosec // structured log, not injection
+			slog.DebugContext(req.Context(), "server error, retrying", //nolint:gosec // structured log, not injection
 				"status", resp.StatusCode,
 				"attempt", retries5xx+1)
 
@@ -144,10 +164,14 @@ func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 
 			retries5xx++
 
+			pool.retried()
+
 			continue
 		}
 
 		// Other errors (4xx except 429): don't retry.
+		hooks.attempt(req, resp.StatusCode, false)
+
 		return resp, nil
 	}
 }
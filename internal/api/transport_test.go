@@ -3,6 +3,7 @@ package api
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -402,3 +403,33 @@ func TestRoundTrip_NilBodyGET(t *testing.T) {
 		t.Errorf("status = %d, want 200", resp.StatusCode)
 	}
 }
+
+func TestNewBaseTransport(t *testing.T) {
+	t.Parallel()
+
+	def := newBaseTransport(TransportOptions{})
+	if def.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost || def.DisableKeepAlives || def.Protocols != nil {
+		t.Errorf("default transport = idle %d, keep-alives off %v, protocols %v",
+			def.MaxIdleConnsPerHost, def.DisableKeepAlives, def.Protocols)
+	}
+
+	if def.TLSClientConfig == nil || def.TLSClientConfig.MinVersion < tls.VersionTLS12 {
+		t.Error("default transport should require TLS 1.2+")
+	}
+
+	tuned := newBaseTransport(TransportOptions{
+		MaxIdleConnsPerHost: 4,
+		IdleConnTimeout:     time.Minute,
+		ForceHTTP2:          true,
+		DisableKeepAlives:   true,
+	})
+
+	if tuned.MaxIdleConnsPerHost != 4 || tuned.IdleConnTimeout != time.Minute || !tuned.DisableKeepAlives {
+		t.Errorf("tuned transport = idle %d, timeout %v, keep-alives off %v",
+			tuned.MaxIdleConnsPerHost, tuned.IdleConnTimeout, tuned.DisableKeepAlives)
+	}
+
+	if tuned.Protocols == nil || !tuned.Protocols.HTTP2() || tuned.Protocols.HTTP1() {
+		t.Errorf("ForceHTTP2 protocols = %v, want HTTP/2 only", tuned.Protocols)
+	}
+}
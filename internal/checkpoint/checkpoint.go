@@ -0,0 +1,229 @@
+// Package checkpoint records the items a bulk command has finished, so an
+// interrupted run can be resumed without redoing them.
+//
+// A checkpoint is a JSON-lines file: a header naming the command, then one
+// line per finished item, appended and synced as each item completes. A
+// torn final line (crash mid-write) is ignored when the file is read back.
+package checkpoint
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/config"
+)
+
+// ErrWrongCommand is returned by Resume for a checkpoint another command
+// wrote.
+var ErrWrongCommand = errors.New("checkpoint belongs to another command")
+
+// header is the first line of a checkpoint file.
+type header struct {
+	Command string    `json:"command"`
+	Started time.Time `json:"started_at"`
+}
+
+// record is one finished item.
+type record struct {
+	Done string `json:"done"`
+}
+
+// File is an open checkpoint. A nil *File records nothing and has nothing
+// done, so commands can use one unconditionally.
+type File struct {
+	path string
+	mu   sync.Mutex
+	f    *os.File
+	done map[string]bool
+}
+
+// DefaultPath returns a new checkpoint location for command inside the data
+// directory, e.g. checkpoints/product-price-adjust-20240601T120000.jsonl.
+func DefaultPath(command string, now time.Time) (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.ReplaceAll(command, " ", "-") + "-" + now.UTC().Format("20060102T150405") + ".jsonl"
+
+	return filepath.Join(dir, "checkpoints", name), nil
+}
+
+// Create starts a checkpoint at path for command; the file must not exist.
+func Create(path, command string) (*File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("ensure checkpoint dir: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600) //nolint:gosec // path from the data dir or the user
+	if err != nil {
+		return nil, fmt.Errorf("create checkpoint: %w", err)
+	}
+
+	cp := &File{path: path, f: f, done: map[string]bool{}}
+	if err := cp.append(header{Command: command, Started: time.Now().UTC()}); err != nil {
+		_ = f.Close()
+
+		return nil, err
+	}
+
+	return cp, nil
+}
+
+// Resume opens the checkpoint at path, written by command, to skip its
+// finished items and record more.
+func Resume(path, command string) (*File, error) {
+	b, err := os.ReadFile(path) //nolint:gosec // user-supplied checkpoint path
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	var h header
+	if !sc.Scan() || json.Unmarshal(sc.Bytes(), &h) != nil || h.Command == "" {
+		return nil, fmt.Errorf("%s: not a checkpoint file", path)
+	}
+
+	if h.Command != command {
+		return nil, fmt.Errorf("%w: %s is from %q", ErrWrongCommand, path, h.Command)
+	}
+
+	done := map[string]bool{}
+
+	for sc.Scan() {
+		var rec record
+		if json.Unmarshal(sc.Bytes(), &rec) == nil && rec.Done != "" {
+			done[rec.Done] = true
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // user-supplied checkpoint path
+	if err != nil {
+		return nil, fmt.Errorf("open checkpoint: %w", err)
+	}
+
+	cp := &File{path: path, f: f, done: done}
+
+	// Start on a fresh line if the last write was torn.
+	if len(b) > 0 && b[len(b)-1] != '\n' {
+		if _, err := f.WriteString("\n"); err != nil {
+			_ = f.Close()
+
+			return nil, fmt.Errorf("write checkpoint: %w", err)
+		}
+	}
+
+	return cp, nil
+}
+
+// Path returns the checkpoint file path, or "" for a nil File.
+func (c *File) Path() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.path
+}
+
+// Done reports whether key was finished by this or an earlier run.
+func (c *File) Done(key string) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.done[key]
+}
+
+// Len returns the number of finished items.
+func (c *File) Len() int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.done)
+}
+
+// Mark records key as finished. It is safe for concurrent use and the line
+// is synced before it returns.
+func (c *File) Mark(key string) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.append(record{Done: key}); err != nil {
+		return err
+	}
+
+	c.done[key] = true
+
+	return nil
+}
+
+// Close closes the file, keeping it for a later Resume.
+func (c *File) Close() error {
+	if c == nil {
+		return nil
+	}
+
+	if err := c.f.Close(); err != nil {
+		return fmt.Errorf("close checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// Remove closes and deletes the file once the run has nothing left to do.
+func (c *File) Remove() error {
+	if c == nil {
+		return nil
+	}
+
+	_ = c.f.Close()
+
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+func (c *File) append(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode checkpoint: %w", err)
+	}
+
+	if _, err := c.f.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+
+	if err := c.f.Sync(); err != nil {
+		return fmt.Errorf("sync checkpoint: %w", err)
+	}
+
+	return nil
+}
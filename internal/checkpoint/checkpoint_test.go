@@ -0,0 +1,157 @@
+package checkpoint
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateResume(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "checkpoints", "run.jsonl")
+
+	cp, err := Create(path, "product price adjust")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	for _, key := range []string{"1/11", "1/12"} {
+		if err := cp.Mark(key); err != nil {
+			t.Fatalf("Mark(%s): %v", key, err)
+		}
+	}
+
+	if err := cp.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if _, err := Create(path, "product price adjust"); err == nil {
+		t.Error("Create over an existing checkpoint: want error")
+	}
+
+	cp, err = Resume(path, "product price adjust")
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+
+	if !cp.Done("1/11") || !cp.Done("1/12") || cp.Done("2/21") || cp.Len() != 2 {
+		t.Errorf("resumed done set wrong: len %d", cp.Len())
+	}
+
+	if err := cp.Mark("2/21"); err != nil {
+		t.Fatalf("Mark after resume: %v", err)
+	}
+
+	if err := cp.Remove(); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("checkpoint still exists after Remove: %v", err)
+	}
+}
+
+func TestResume_TornLine(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "run.jsonl")
+	body := `{"command":"product categorize","started_at":"2024-06-01T12:00:00Z"}` + "\n" +
+		`{"done":"1"}` + "\n" + `{"done":"2`
+
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cp, err := Resume(path, "product categorize")
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+
+	if !cp.Done("1") || cp.Done("2") {
+		t.Errorf("Done(1)=%v Done(2)=%v, want true false", cp.Done("1"), cp.Done("2"))
+	}
+
+	if err := cp.Mark("2"); err != nil {
+		t.Fatalf("Mark: %v", err)
+	}
+
+	_ = cp.Close()
+
+	cp, err = Resume(path, "product categorize")
+	if err != nil {
+		t.Fatalf("second Resume: %v", err)
+	}
+
+	if !cp.Done("2") {
+		t.Error("item marked after a torn line was lost")
+	}
+
+	_ = cp.Close()
+}
+
+func TestResume_Invalid(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	cp, err := Create(filepath.Join(dir, "price.jsonl"), "product price adjust")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	_ = cp.Close()
+
+	if err := os.WriteFile(filepath.Join(dir, "junk.jsonl"), []byte("hello\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		command string
+		wantErr error
+	}{
+		{name: "wrong command", file: "price.jsonl", command: "product categorize", wantErr: ErrWrongCommand},
+		{name: "missing", file: "nope.jsonl", command: "product categorize", wantErr: os.ErrNotExist},
+		{name: "not a checkpoint", file: "junk.jsonl", command: "product categorize"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Resume(filepath.Join(dir, tt.file), tt.command)
+			if err == nil {
+				t.Fatal("want error")
+			}
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNilFile(t *testing.T) {
+	t.Parallel()
+
+	var cp *File
+
+	if cp.Done("x") || cp.Len() != 0 || cp.Path() != "" {
+		t.Error("nil File should have nothing done")
+	}
+
+	if err := cp.Mark("x"); err != nil {
+		t.Errorf("Mark: %v", err)
+	}
+
+	if err := cp.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+
+	if err := cp.Remove(); err != nil {
+		t.Errorf("Remove: %v", err)
+	}
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"github.com/gberlati/nube-cli/internal/outfmt"
 	"github.com/gberlati/nube-cli/internal/ui"
@@ -30,7 +29,7 @@ func (c *AgentExitCodesCmd) Run(ctx context.Context) error {
 			entries[i] = entry{Code: e.Code, Name: e.Name, Desc: e.Desc}
 		}
 
-		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"exit_codes": entries})
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"exit_codes": entries})
 	}
 
 	u := ui.FromContext(ctx)
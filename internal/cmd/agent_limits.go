@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gberlati/nube-cli/internal/config"
+)
+
+// agentLimits are the config defaults that keep --envelope output within
+// what an LLM agent can read: a cap on list items and a default --select.
+type agentLimits struct {
+	maxItems      int
+	defaultSelect string
+}
+
+// readAgentLimits reads agent_max_items and agent_default_select; an
+// unreadable config means no limits.
+func readAgentLimits() agentLimits {
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return agentLimits{}
+	}
+
+	return agentLimits{
+		maxItems:      max(cfg.AgentMaxItems, 0),
+		defaultSelect: strings.TrimSpace(cfg.AgentDefaultSelect),
+	}
+}
+
+// truncateItems cuts a list to its first maxItems items, describing the cut.
+// Anything else, and lists within the cap, come back unchanged.
+func truncateItems(data any, maxItems int) (any, *truncation) {
+	v := reflect.ValueOf(data)
+	if maxItems <= 0 || v.Kind() != reflect.Slice || v.Len() <= maxItems {
+		return data, nil
+	}
+
+	return v.Slice(0, maxItems).Interface(), &truncation{
+		Returned: maxItems,
+		Total:    v.Len(),
+		Hint: fmt.Sprintf("only the first %d of %d items are shown (config agent_max_items); "+
+			"narrow the query or fetch pages of at most %d with --page and --per-page",
+			maxItems, v.Len(), maxItems),
+	}
+}
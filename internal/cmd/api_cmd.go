@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gberlati/nube-cli/internal/openapi"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// APICmd sends a raw request to the store API. Requests are checked against
+// the embedded API description first, so mistakes fail before anything is
+// sent.
+type APICmd struct {
+	Path        string   `arg:"" help:"Path relative to the store, e.g. products/123 or 'orders?status=open'"`
+	Method      string   `help:"HTTP method (default: GET, or POST with a body)" name:"method" short:"X" enum:",GET,POST,PUT,PATCH,DELETE" default:""`
+	Query       []string `help:"Query parameter as key=value (repeatable)" name:"query" short:"F" sep:"none"`
+	Data        string   `help:"JSON or YAML request body" name:"data" short:"d"`
+	Input       string   `help:"File with the JSON or YAML request body ('-' for stdin)" name:"input"`
+	NoValidate  bool     `help:"Send the request even if it doesn't match the API description" name:"no-validate"`
+	AsCurl      bool     `help:"Print the equivalent curl command instead of sending the request" name:"as-curl"`
+	RevealToken bool     `help:"Put the access token in the --as-curl output instead of $NUBE_ACCESS_TOKEN" name:"reveal-token"`
+}
+
+func (c *APICmd) Run(ctx context.Context, flags *RootFlags) error {
+	if c.Data != "" && c.Input != "" {
+		return usagef("--data and --input can't be combined")
+	}
+
+	body := []byte(c.Data)
+
+	if c.Input != "" {
+		b, err := readInputFile(c.Input)
+		if err != nil {
+			return err
+		}
+
+		body = b
+	}
+
+	body, err := jsonFromYAML(body)
+	if err != nil {
+		return usagef("request body: %v", err)
+	}
+
+	method := c.Method
+	if method == "" {
+		method = http.MethodGet
+		if len(body) > 0 {
+			method = http.MethodPost
+		}
+	}
+
+	if method == http.MethodGet && len(body) > 0 {
+		return usagef("GET requests can't have a body")
+	}
+
+	path, q, err := splitAPIPath(c.Path, c.Query)
+	if err != nil {
+		return err
+	}
+
+	if !c.NoValidate {
+		if err := validateRequest(method, path, body); err != nil {
+			return err
+		}
+	}
+
+	if c.RevealToken && !c.AsCurl {
+		return usagef("--reveal-token only applies to --as-curl")
+	}
+
+	if c.AsCurl {
+		return c.printCurl(ctx, flags, method, path, q, body)
+	}
+
+	if flags.DryRun && method != http.MethodGet {
+		return writeResult(ctx, ui.FromContext(ctx),
+			kv("dry_run", true),
+			kv("method", method),
+			kv("path", path),
+			kv("query", q.Encode()),
+			kv("body", string(body)),
+		)
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	// Writes take the query as part of the path.
+	writePath := path
+	if len(q) > 0 {
+		writePath += "?" + q.Encode()
+	}
+
+	var resp *http.Response
+
+	switch method {
+	case http.MethodGet:
+		resp, err = client.Get(ctx, path, q) //nolint:bodyclose // decodeOptionalJSON closes body
+	case http.MethodPost:
+		resp, err = client.Post(ctx, writePath, bytes.NewReader(body)) //nolint:bodyclose // decodeOptionalJSON closes body
+	case http.MethodPut:
+		resp, err = client.Put(ctx, writePath, bytes.NewReader(body)) //nolint:bodyclose // decodeOptionalJSON closes body
+	case http.MethodPatch:
+		resp, err = client.Patch(ctx, writePath, bytes.NewReader(body)) //nolint:bodyclose // decodeOptionalJSON closes body
+	case http.MethodDelete:
+		resp, err = client.Delete(ctx, writePath) //nolint:bodyclose // decodeOptionalJSON closes body
+	}
+
+	if err != nil {
+		return err
+	}
+
+	result, err := decodeOptionalJSON(resp)
+	if err != nil || result == nil {
+		return err
+	}
+
+	return outfmt.WriteJSON(ctx, stdoutFrom(ctx), result)
+}
+
+// printCurl prints the request as a curl command, e.g. to attach a
+// reproduction to a support ticket.
+func (c *APICmd) printCurl(ctx context.Context, flags *RootFlags, method, path string, q url.Values, body []byte) error {
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	line, err := client.Curl(method, path, q, body, c.RevealToken)
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"curl": line})
+	}
+
+	_, err = fmt.Fprintln(stdoutFrom(ctx), line)
+
+	return err
+}
+
+// splitAPIPath separates a query string written into the path and merges it
+// with --query pairs.
+func splitAPIPath(raw string, pairs []string) (string, url.Values, error) {
+	path, rawQuery, _ := strings.Cut(strings.TrimLeft(raw, "/"), "?")
+
+	q, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", nil, usagef("%s: bad query string: %v", raw, err)
+	}
+
+	for _, p := range pairs {
+		k, v, ok := strings.Cut(p, "=")
+		if !ok || k == "" {
+			return "", nil, usagef("--query %q: want key=value", p)
+		}
+
+		q.Add(k, v)
+	}
+
+	return path, q, nil
+}
+
+// validateRequest checks a request against the embedded API description and
+// turns a mismatch into a usage error.
+func validateRequest(method, path string, body []byte) error {
+	spec, err := openapi.Default()
+	if err != nil {
+		return err
+	}
+
+	err = spec.Validate(method, path, body)
+
+	var vErr *openapi.Error
+	if errors.As(err, &vErr) {
+		return newUsageError(fmt.Errorf("%w (pass --no-validate to send it anyway)", err))
+	}
+
+	return err
+}
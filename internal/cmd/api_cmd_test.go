@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestAPI_Get(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var gotQuery string
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/123/orders" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+
+		gotQuery = r.URL.RawQuery
+		_, _ = w.Write([]byte(`[{"id":1}]`))
+	}))
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"api", "orders?status=open", "-F", "fields=id"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if gotQuery != "fields=id&status=open" {
+		t.Errorf("query = %q", gotQuery)
+	}
+
+	var got []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil || len(got) != 1 {
+		t.Errorf("output = %q (err %v)", buf.String(), err)
+	}
+}
+
+func TestAPI_ValidatesBeforeSending(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var writes []string
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			b, _ := io.ReadAll(r.Body)
+			writes = append(writes, r.Method+" "+r.URL.Path+" "+string(b))
+		}
+
+		_, _ = w.Write([]byte(`{"id":7}`))
+	}))
+
+	_ = captureStdout(t)
+	_ = captureStderr(t)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"api", "prodcts"}, "unknown API path"},
+		{[]string{"api", "orders/1", "-X", "DELETE"}, "method not allowed"},
+		{[]string{"api", "webhooks", "-d", `{"url":"https://example.com"}`}, `missing required field "event"`},
+		{[]string{"api", "products", "-X", "GET", "-d", `{}`}, "can't have a body"},
+	}
+
+	for _, tt := range tests {
+		if err := Execute(tt.args); ExitCode(err) != ExitUsage || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: err = %v (exit %d), want usage error with %q", tt.args, err, ExitCode(err), tt.want)
+		}
+	}
+
+	if len(writes) != 0 {
+		t.Errorf("writes = %v, want none", writes)
+	}
+
+	if err := Execute([]string{"api", "webhooks", "-d", `{"url":"https://example.com"}`, "--no-validate", "--no-history"}); err != nil {
+		t.Fatalf("--no-validate: %v", err)
+	}
+
+	if want := `POST /v1/123/webhooks {"url":"https://example.com"}`; len(writes) != 1 || writes[0] != want {
+		t.Errorf("writes = %v, want [%s]", writes, want)
+	}
+}
+
+func TestAPI_YAML(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var writes []string
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			b, _ := io.ReadAll(r.Body)
+			writes = append(writes, string(b))
+		}
+
+		_, _ = w.Write([]byte(`{"id":7,"event":"order/created","tags":["a","true"]}`))
+	}))
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"api", "webhooks", "-d", "event: order/created\nurl: https://example.com", "--yaml", "--no-history"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if want := `{"event":"order/created","url":"https://example.com"}`; len(writes) != 1 || writes[0] != want {
+		t.Errorf("writes = %v, want [%s]", writes, want)
+	}
+
+	if want := "event: order/created\nid: 7\ntags:\n  - a\n  - \"true\"\n"; buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+
+	errBuf := captureStderr(t)
+	if err := Execute([]string{"api", "webhooks", "--yaml", "--envelope"}); ExitCode(err) != ExitUsage {
+		t.Errorf("--yaml --envelope: ExitCode = %d, want %d", ExitCode(err), ExitUsage)
+	}
+
+	if !strings.Contains(errBuf.String(), "can't be combined") {
+		t.Errorf("stderr = %q, want the conflict explained", errBuf.String())
+	}
+}
+
+func TestAPI_Patch(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var requests []string
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(b))
+
+		_, _ = w.Write([]byte(`[]`))
+	}))
+
+	_ = captureStdout(t)
+	if err := Execute([]string{"api", "products/stock-price", "-X", "PATCH", "-d", `[{"id":1,"variants":[{"id":11,"stock":5}]}]`}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	// Journaled like any other write, and sent as is.
+	want := `PATCH /v1/123/products/stock-price [{"id":1,"variants":[{"id":11,"stock":5}]}]`
+	if len(requests) != 1 || requests[0] != want {
+		t.Errorf("requests = %v, want [%s]", requests, want)
+	}
+
+	j, _ := newJournal()
+	if entries, _ := j.List(); len(entries) != 1 || entries[0].Method != http.MethodPatch {
+		t.Errorf("journal = %+v, want the PATCH", entries)
+	}
+}
+
+func TestSplitAPIPath(t *testing.T) {
+	t.Parallel()
+
+	path, q, err := splitAPIPath("/products/1?fields=id", []string{"lang=es"})
+	if err != nil || path != "products/1" || q.Encode() != "fields=id&lang=es" {
+		t.Errorf("splitAPIPath = %q, %q, %v", path, q.Encode(), err)
+	}
+
+	if _, _, err := splitAPIPath("products", []string{"novalue"}); ExitCode(err) != ExitUsage {
+		t.Errorf("bad --query: err = %v", err)
+	}
+}
+
+func TestAPI_AsCurl(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "test-token"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("request sent: %s %s", r.Method, r.URL.Path)
+	}))
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"api", "categories/12", "-X", "PUT", "-d", `{"name":{"es":"D'Alba"}}`, "--as-curl"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		"curl -X PUT 'http://",
+		"/v1/123/categories/12' ",
+		`-H "Authentication: bearer $NUBE_ACCESS_TOKEN"`,
+		`--data '{"name":{"es":"D'\''Alba"}}'`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output = %s\nmissing %s", got, want)
+		}
+	}
+
+	buf = captureStdout(t)
+	if err := Execute([]string{"api", "products", "-F", "fields=id", "--as-curl", "--reveal-token"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if got := buf.String(); !strings.Contains(got, "'Authentication: bearer test-token'") || !strings.Contains(got, "products?fields=id'") {
+		t.Errorf("revealed output = %s", got)
+	}
+
+	_ = captureStderr(t)
+
+	if err := Execute([]string{"api", "products", "--reveal-token"}); ExitCode(err) != ExitUsage {
+		t.Errorf("--reveal-token alone: err = %v", err)
+	}
+}
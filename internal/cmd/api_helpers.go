@@ -1,13 +1,25 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/url"
 	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
 
 	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/config"
 	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/i18n"
 )
 
 // newAPIClient composes store resolution + api.New.
@@ -16,28 +28,178 @@ var newAPIClient = defaultNewAPIClient
 
 func defaultNewAPIClient(flags *RootFlags) (*api.Client, error) {
 	// Fast path: env-var token bypasses credential file entirely.
-	if tok := os.Getenv("NUBE_ACCESS_TOKEN"); tok != "" {
-		userID := os.Getenv("NUBE_USER_ID")
+	if tok := flags.getenv("NUBE_ACCESS_TOKEN"); tok != "" {
+		userID := flags.getenv("NUBE_USER_ID")
 		if userID == "" {
 			slog.Warn("NUBE_USER_ID not set; API calls that require a store ID will fail")
 		}
 
-		return api.New(userID, tok), nil
+		opts, err := clientOptions(flags, "")
+		if err != nil {
+			return nil, err
+		}
+
+		return api.New(userID, tok, opts...), nil
 	}
 
 	// Standard path: resolve store profile.
-	_, profile, err := credstore.ResolveStore(flags.Store)
+	name, profile, err := flags.resolveStore(flags.Store)
 	if err != nil {
+		// Fixtures don't depend on the store, so mock mode works offline
+		// without any profile.
+		if flags.MockDir != "" {
+			opts, optsErr := clientOptions(flags, "")
+			if optsErr != nil {
+				return nil, optsErr
+			}
+
+			return api.New(mockStoreID, "", opts...), nil
+		}
+
 		return nil, &ExitErr{Code: ExitConfig, Err: err}
 	}
 
-	return api.New(profile.StoreID, profile.AccessToken), nil
+	return profileClient(flags, name, profile)
+}
+
+// profileClient returns an API client for the named store profile.
+func profileClient(flags *RootFlags, name string, profile credstore.StoreProfile) (*api.Client, error) {
+	// A bad profile URL only matters when --api-base-url doesn't replace it.
+	if err := validateBaseURL(profile.APIBaseURL); err != nil && flags.APIBaseURL == "" {
+		return nil, &ExitErr{Code: ExitConfig, Err: fmt.Errorf("store profile %q: api_base_url: %w", name, err)}
+	}
+
+	opts, err := clientOptions(flags, profile.APIBaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fixtures never see the token, so mock runs don't count as use.
+	if flags.MockDir == "" {
+		touchStore(name)
+	}
+
+	return api.New(profile.StoreID, profile.AccessToken, opts...), nil
+}
+
+// touchStore records that the profile was used, so `auth list` can point
+// out stale credentials. Failing to record it never fails the command.
+func touchStore(name string) {
+	if _, err := credstore.TouchStore(name, time.Now()); err != nil {
+		slog.Debug("record profile use", "store", name, "err", err)
+	}
+}
+
+// validateBaseURL accepts an empty URL (the default API) or an absolute
+// http(s) URL.
+func validateBaseURL(s string) error {
+	if s == "" {
+		return nil
+	}
+
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an http(s) URL", s)
+	}
+
+	return nil
+}
+
+// activeStoreName returns the name of the store profile commands act on, or
+// the store ID when it isn't a stored profile (e.g. NUBE_ACCESS_TOKEN).
+func activeStoreName(flags *RootFlags, client *api.Client) string {
+	if flags.getenv("NUBE_ACCESS_TOKEN") == "" {
+		if name, _, err := flags.resolveStore(flags.Store); err == nil {
+			return name
+		}
+	}
+
+	return client.StoreID()
+}
+
+// mockStoreID stands in for the store ID in mock mode without a profile.
+const mockStoreID = "mock"
+
+// clientOptions translates root flags and config into API client options.
+// profileBaseURL is the store profile's API base URL, used unless
+// --api-base-url is set.
+func clientOptions(flags *RootFlags, profileBaseURL string) ([]api.Option, error) {
+	var opts []api.Option
+
+	baseURL := profileBaseURL
+	if flags.APIBaseURL != "" {
+		if err := validateBaseURL(flags.APIBaseURL); err != nil {
+			return nil, usagef("--api-base-url: %v", err)
+		}
+
+		baseURL = flags.APIBaseURL
+	}
+
+	if baseURL != "" {
+		opts = append(opts, api.WithBaseURL(baseURL))
+	}
+
+	transport, err := configTransportOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	if transport != (api.TransportOptions{}) {
+		opts = append(opts, api.WithTransportOptions(transport))
+	}
+
+	if flags.Timeout > 0 {
+		opts = append(opts, api.WithTimeout(flags.Timeout))
+	}
+
+	if flags.MockDir != "" {
+		opts = append(opts, api.WithMockDir(flags.MockDir))
+	}
+
+	if flags.Record != "" {
+		opts = append(opts, api.WithRecordDir(flags.Record))
+	}
+
+	return opts, nil
+}
+
+// configTransportOptions reads the "http" connection settings of
+// config.json. As with the theme, an unreadable config is left to the
+// commands that need it.
+func configTransportOptions() (api.TransportOptions, error) {
+	cfg, err := config.ReadConfig()
+	if err != nil || cfg.HTTP == nil {
+		return api.TransportOptions{}, nil //nolint:nilerr // see above
+	}
+
+	o := api.TransportOptions{
+		MaxIdleConnsPerHost: cfg.HTTP.MaxIdleConnsPerHost,
+		ForceHTTP2:          cfg.HTTP.ForceHTTP2,
+		DisableKeepAlives:   cfg.HTTP.DisableKeepAlives,
+	}
+
+	if cfg.HTTP.MaxIdleConnsPerHost < 0 {
+		return o, &ExitErr{Code: ExitConfig, Err: errors.New("config.json: http.max_idle_conns_per_host must not be negative")}
+	}
+
+	if s := cfg.HTTP.IdleConnTimeout; s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return o, &ExitErr{Code: ExitConfig, Err: fmt.Errorf("config.json: http.idle_conn_timeout %q: want a duration like 90s", s)}
+		}
+
+		o.IdleConnTimeout = d
+	}
+
+	return o, nil
 }
 
-// PaginationFlags embeds --page, --per-page for paginated list commands.
+// PaginationFlags embeds --page, --per-page and --page-token for paginated
+// list commands.
 type PaginationFlags struct {
-	Page    int `help:"Page number (omit to fetch all pages)" default:"0"`
-	PerPage int `help:"Results per page" default:"30" aliases:"max"`
+	Page      int    `help:"Page number (omit to fetch all pages)" default:"0"`
+	PerPage   int    `help:"Results per page" default:"30" aliases:"max"`
+	PageToken string `help:"Resume a listing from the meta.next_page of an earlier --envelope run (its filters are kept)" name:"page-token"`
 }
 
 // Apply sets pagination query params. If Page is 0, the caller should use CollectAllPages.
@@ -51,9 +213,49 @@ func (p PaginationFlags) Apply(q url.Values) {
 	}
 }
 
-// WantsAllPages returns true when no explicit --page was given.
+// WantsAllPages returns true when no explicit --page or --page-token was given.
 func (p PaginationFlags) WantsAllPages() bool {
-	return p.Page <= 0
+	return p.Page <= 0 && p.PageToken == ""
+}
+
+// resume returns the query saved in --page-token, which replaces q, or q
+// itself without a token. The token must come from a listing of path.
+func (p PaginationFlags) resume(path string, q url.Values) (url.Values, error) {
+	if p.PageToken == "" {
+		return q, nil
+	}
+
+	next, err := parsePageToken(p.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasSuffix(next.Path, "/"+path) {
+		return nil, usagef("--page-token is for %s, not %s", next.Path, path)
+	}
+
+	return next.Query(), nil
+}
+
+// pageToken turns the next-page URL of a Link header into an opaque token
+// for --page-token. It keeps every query parameter, so the filters of the
+// original listing carry over.
+func pageToken(next string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(next))
+}
+
+func parsePageToken(token string) (*url.URL, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(token))
+	if err != nil {
+		return nil, usagef("invalid --page-token: %v", err)
+	}
+
+	u, err := url.Parse(string(b))
+	if err != nil || u.Path == "" {
+		return nil, usagef("invalid --page-token: not a page link")
+	}
+
+	return u, nil
 }
 
 // addQueryParam sets key=value in q if value is non-empty.
@@ -63,6 +265,59 @@ func addQueryParam(q url.Values, key, value string) {
 	}
 }
 
+// defaultLangPriority is the order extractI18n tries translations in unless
+// --lang-priority or config lang_priority says otherwise.
+var defaultLangPriority = []string{i18n.Spanish, i18n.Portuguese, i18n.English}
+
+// langPriority is the process-wide translation order; nil means the default.
+var langPriority atomic.Pointer[[]string]
+
+func setLangPriority(order []string) {
+	if len(order) == 0 {
+		langPriority.Store(nil)
+
+		return
+	}
+
+	langPriority.Store(&order)
+}
+
+func translationOrder() []string {
+	if p := langPriority.Load(); p != nil {
+		return *p
+	}
+
+	return defaultLangPriority
+}
+
+// resolveLangPriority validates the --lang-priority list, falling back to
+// config lang_priority when the flag isn't given.
+func resolveLangPriority(flag []string) ([]string, error) {
+	order, source := flag, "--lang-priority"
+
+	if len(order) == 0 {
+		cfg, err := config.ReadConfig()
+		if err != nil {
+			return nil, nil //nolint:nilerr // a broken config is reported by the commands that need it
+		}
+
+		order, source = cfg.LangPriority, "config lang_priority"
+	}
+
+	out := make([]string, 0, len(order))
+
+	for _, l := range order {
+		lang := i18n.Normalize(l)
+		if lang == "" {
+			return nil, usagef("%s: unsupported language %q (want en, es, or pt)", source, l)
+		}
+
+		out = append(out, lang)
+	}
+
+	return out, nil
+}
+
 // extractI18n returns the best available translation from an i18n map.
 // Tienda Nube returns multilingual fields as {"es":"...","pt":"...","en":"..."}.
 func extractI18n(obj map[string]any, key string) string {
@@ -81,8 +336,8 @@ func extractI18n(obj map[string]any, key string) string {
 		return ""
 	}
 
-	// Prefer: es > pt > en > first available.
-	for _, lang := range []string{"es", "pt", "en"} {
+	// Prefer the configured order, then whatever is available.
+	for _, lang := range translationOrder() {
 		if v, ok := m[lang]; ok {
 			if s, ok := v.(string); ok && s != "" {
 				return s
@@ -99,6 +354,59 @@ func extractI18n(obj map[string]any, key string) string {
 	return ""
 }
 
+// jsonBody encodes v as a JSON request body.
+func jsonBody(v any) (io.Reader, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode request body: %w", err)
+	}
+
+	return bytes.NewReader(b), nil
+}
+
+// jsonFromYAML returns b as JSON. JSON passes through untouched and
+// anything else is read as YAML, so bodies and files can be written in
+// either.
+func jsonFromYAML(b []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(b)) == 0 || json.Valid(b) {
+		return b, nil
+	}
+
+	var doc any
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("not valid JSON or YAML: %w", err)
+	}
+
+	j, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("not valid JSON or YAML: %w", err)
+	}
+
+	return j, nil
+}
+
+// readInputFile reads path, or stdin for "-".
+func readInputFile(path string) ([]byte, error) {
+	var (
+		b   []byte
+		err error
+	)
+
+	if path == "-" {
+		done := waitingForInput()
+		b, err = io.ReadAll(os.Stdin)
+		done()
+	} else {
+		b, err = os.ReadFile(path) //nolint:gosec // user-provided path
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	return b, nil
+}
+
 func itoa(i int) string {
 	return fmt.Sprintf("%d", i)
 }
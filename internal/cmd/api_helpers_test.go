@@ -99,6 +99,10 @@ func TestDefaultNewAPIClient_StandardPathUsesCredStore(t *testing.T) {
 	if client == nil {
 		t.Fatal("expected non-nil client")
 	}
+
+	if p, _ := credstore.GetStore("test"); p.LastUsedAt == "" {
+		t.Error("last_used_at not recorded")
+	}
 }
 
 func TestExtractI18n(t *testing.T) {
@@ -0,0 +1,515 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/jsondiff"
+	"github.com/gberlati/nube-cli/internal/openapi"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// Apply actions.
+const (
+	applyCreate    = "create"
+	applyUpdate    = "update"
+	applyUnchanged = "unchanged"
+	applyDelete    = "delete"
+)
+
+// applyKind describes how a manifest kind maps onto the API: its collection
+// path and the spec fields that identify an existing resource when the spec
+// has no id.
+type applyKind struct {
+	Path string
+	Keys []string
+}
+
+var applyKinds = map[string]applyKind{
+	"product":  {Path: "products", Keys: []string{"handle"}},
+	"category": {Path: "categories", Keys: []string{"handle"}},
+	"webhook":  {Path: "webhooks", Keys: []string{"event", "url"}},
+	"coupon":   {Path: "coupons", Keys: []string{"code"}},
+}
+
+// ApplyCmd converges the store to a manifest of declarative resources.
+type ApplyCmd struct {
+	File  string `help:"Manifest file (YAML or JSON; '-' for stdin)" name:"file" short:"f" required:""`
+	Prune bool   `help:"Delete resources of the manifest's kinds that the manifest doesn't list" name:"prune"`
+}
+
+// manifestResource is one entry of a manifest: {kind: product, spec: {...}}.
+type manifestResource struct {
+	Kind string         `json:"kind"`
+	Spec map[string]any `json:"spec"`
+}
+
+// applyAction is one planned (or executed) change.
+type applyAction struct {
+	Kind    string        `json:"kind"`
+	Key     string        `json:"key"`
+	Action  string        `json:"action"`
+	ID      string        `json:"id,omitempty"`
+	Changes []jsondiff.Op `json:"changes,omitempty"`
+
+	spec map[string]any
+}
+
+func (c *ApplyCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	b, err := readInputFile(c.File)
+	if err != nil {
+		return err
+	}
+
+	resources, err := parseManifest(b)
+	if err != nil {
+		return newUsageError(err)
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	remote := map[string][]map[string]any{}
+
+	for _, r := range resources {
+		if _, ok := remote[r.Kind]; ok {
+			continue
+		}
+
+		items, listErr := api.CollectAllPages(ctx, client, applyKinds[r.Kind].Path, nil, decodeList)
+		if listErr != nil {
+			return listErr
+		}
+
+		remote[r.Kind] = items
+	}
+
+	plan, err := planApply(resources, remote, c.Prune)
+	if err != nil {
+		return newUsageError(err)
+	}
+
+	if err := validateApplyPlan(plan); err != nil {
+		return err
+	}
+
+	if !flags.DryRun {
+		if deletes := actionIDs(plan, applyDelete); len(deletes) > 0 {
+			action := fmt.Sprintf("delete %d resources not in the manifest", len(deletes))
+			if err := confirmBulk(flags, action, deletes, activeStoreName(flags, client)); err != nil {
+				return err
+			}
+		}
+
+		if err := executeApply(ctx, client, plan); err != nil {
+			return err
+		}
+	}
+
+	return writeApplyPlan(ctx, u, plan, flags.DryRun)
+}
+
+// parseManifest reads one or more YAML documents (JSON is valid YAML), each
+// holding a resource or a list of resources.
+func parseManifest(b []byte) ([]manifestResource, error) {
+	var resources []manifestResource
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+
+	for {
+		var doc any
+
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("parse manifest: %w", err)
+		}
+
+		if doc == nil {
+			continue
+		}
+
+		// Round-trip through JSON so values compare like API responses
+		// (float64 numbers, map[string]any objects).
+		j, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("parse manifest: %w", err)
+		}
+
+		var items []manifestResource
+		if bytes.HasPrefix(j, []byte("[")) {
+			err = json.Unmarshal(j, &items)
+		} else {
+			var r manifestResource
+
+			err = json.Unmarshal(j, &r)
+			items = []manifestResource{r}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("parse manifest: %w", err)
+		}
+
+		resources = append(resources, items...)
+	}
+
+	if len(resources) == 0 {
+		return nil, errors.New("manifest has no resources")
+	}
+
+	for i, r := range resources {
+		kind, ok := applyKinds[r.Kind]
+		if !ok {
+			return nil, fmt.Errorf("resource %d: unknown kind %q (want product, category, webhook, or coupon)", i+1, r.Kind)
+		}
+
+		if len(r.Spec) == 0 {
+			return nil, fmt.Errorf("resource %d: spec is required", i+1)
+		}
+
+		if _, hasID := r.Spec["id"]; hasID {
+			continue
+		}
+
+		for _, k := range kind.Keys {
+			if _, ok := r.Spec[k]; !ok {
+				return nil, fmt.Errorf("resource %d (%s): spec needs id or %s to match existing resources", i+1, r.Kind, strings.Join(kind.Keys, " and "))
+			}
+		}
+	}
+
+	return resources, nil
+}
+
+// planApply matches manifest resources against remote ones and decides what
+// to create, update, or (with prune) delete.
+func planApply(resources []manifestResource, remote map[string][]map[string]any, prune bool) ([]applyAction, error) {
+	var plan []applyAction
+
+	matched := map[string]map[string]bool{}
+
+	for _, r := range resources {
+		if matched[r.Kind] == nil {
+			matched[r.Kind] = map[string]bool{}
+		}
+
+		key := manifestKey(r)
+		action := applyAction{Kind: r.Kind, Key: key, spec: r.Spec}
+
+		existing := findRemote(r, remote[r.Kind])
+		if existing == nil {
+			if _, hasID := r.Spec["id"]; hasID {
+				return nil, fmt.Errorf("%s %s: id not found in store", r.Kind, key)
+			}
+
+			action.Action = applyCreate
+			plan = append(plan, action)
+
+			continue
+		}
+
+		id := jsonStr(existing, "id")
+		if matched[r.Kind][id] {
+			return nil, fmt.Errorf("%s %s: more than one manifest entry matches resource %s", r.Kind, key, id)
+		}
+
+		matched[r.Kind][id] = true
+		action.ID = id
+		action.Changes = diffResource(existing, stripReadOnly(r.Spec), false)
+
+		if len(action.Changes) == 0 {
+			action.Action = applyUnchanged
+		} else {
+			action.Action = applyUpdate
+		}
+
+		plan = append(plan, action)
+	}
+
+	if !prune {
+		return plan, nil
+	}
+
+	kinds := make([]string, 0, len(matched))
+	for k := range matched {
+		kinds = append(kinds, k)
+	}
+
+	slices.Sort(kinds)
+
+	for _, kind := range kinds {
+		for _, item := range remote[kind] {
+			id := jsonStr(item, "id")
+			if matched[kind][id] {
+				continue
+			}
+
+			plan = append(plan, applyAction{Kind: kind, Key: remoteKey(kind, item), Action: applyDelete, ID: id})
+		}
+	}
+
+	return plan, nil
+}
+
+// findRemote returns the remote resource a manifest entry refers to, by id
+// when the spec has one, otherwise by the kind's key fields.
+func findRemote(r manifestResource, items []map[string]any) map[string]any {
+	if id, ok := r.Spec["id"]; ok {
+		want := fmt.Sprint(id)
+		if f, isNum := id.(float64); isNum {
+			want = fmt.Sprintf("%.0f", f)
+		}
+
+		for _, item := range items {
+			if jsonStr(item, "id") == want {
+				return item
+			}
+		}
+
+		return nil
+	}
+
+	for _, item := range items {
+		if matchesKeys(r.Spec, item, applyKinds[r.Kind].Keys) {
+			return item
+		}
+	}
+
+	return nil
+}
+
+func matchesKeys(spec, item map[string]any, keys []string) bool {
+	for _, k := range keys {
+		if !fieldMatches(spec[k], item[k]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fieldMatches compares a key field, treating i18n maps as matching when any
+// language in the spec agrees with the remote value.
+func fieldMatches(spec, remote any) bool {
+	specMap, specIsMap := spec.(map[string]any)
+	remoteMap, remoteIsMap := remote.(map[string]any)
+
+	switch {
+	case specIsMap && remoteIsMap:
+		for lang, v := range specMap {
+			if s, ok := v.(string); ok && s != "" && remoteMap[lang] == s {
+				return true
+			}
+		}
+
+		return false
+	case remoteIsMap:
+		for _, v := range remoteMap {
+			if v == spec {
+				return true
+			}
+		}
+
+		return false
+	default:
+		return fmt.Sprint(spec) == fmt.Sprint(remote)
+	}
+}
+
+func manifestKey(r manifestResource) string {
+	if id, ok := r.Spec["id"]; ok {
+		return diffValue(id)
+	}
+
+	return keyString(r.Spec, applyKinds[r.Kind].Keys)
+}
+
+func remoteKey(kind string, item map[string]any) string {
+	return keyString(item, applyKinds[kind].Keys)
+}
+
+func keyString(m map[string]any, keys []string) string {
+	parts := make([]string, 0, len(keys))
+
+	for _, k := range keys {
+		if v := extractI18n(m, k); v != "" {
+			parts = append(parts, v)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// stripReadOnly returns spec without server-assigned fields.
+func stripReadOnly(spec map[string]any) map[string]any {
+	out := make(map[string]any, len(spec))
+
+	for k, v := range spec {
+		if !slices.Contains(readOnlyFields, k) {
+			out[k] = v
+		}
+	}
+
+	return out
+}
+
+func countActions(plan []applyAction, action string) int {
+	n := 0
+
+	for _, a := range plan {
+		if a.Action == action {
+			n++
+		}
+	}
+
+	return n
+}
+
+// actionIDs returns "kind/id" for each planned action of the given type.
+func actionIDs(plan []applyAction, action string) []string {
+	var ids []string
+
+	for _, a := range plan {
+		if a.Action == action {
+			ids = append(ids, a.Kind+"/"+a.ID)
+		}
+	}
+
+	return ids
+}
+
+// validateApplyPlan checks every create and update body against the API
+// description, so a bad manifest fails before the first write instead of
+// halfway through.
+func validateApplyPlan(plan []applyAction) error {
+	spec, err := openapi.Default()
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+
+	for _, a := range plan {
+		path := applyKinds[a.Kind].Path
+		method := http.MethodPost
+
+		switch a.Action {
+		case applyCreate:
+		case applyUpdate:
+			path += "/" + a.ID
+			method = http.MethodPut
+		default:
+			continue
+		}
+
+		body, err := json.Marshal(stripReadOnly(a.spec))
+		if err != nil {
+			return fmt.Errorf("encode %s %s: %w", a.Kind, a.Key, err)
+		}
+
+		var vErr *openapi.Error
+		if err := spec.Validate(method, path, body); errors.As(err, &vErr) {
+			for _, p := range vErr.Problems {
+				problems = append(problems, fmt.Sprintf("%s %s: %s", a.Kind, a.Key, p))
+			}
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if len(problems) > 0 {
+		return newUsageError(fmt.Errorf("manifest doesn't match the API:\n  %s", strings.Join(problems, "\n  ")))
+	}
+
+	return nil
+}
+
+// executeApply performs the planned writes in order, stopping at the first failure.
+func executeApply(ctx context.Context, client *api.Client, plan []applyAction) error {
+	for i := range plan {
+		a := &plan[i]
+		path := applyKinds[a.Kind].Path
+
+		var (
+			resp *http.Response
+			err  error
+		)
+
+		switch a.Action {
+		case applyCreate, applyUpdate:
+			body, bodyErr := jsonBody(stripReadOnly(a.spec))
+			if bodyErr != nil {
+				return bodyErr
+			}
+
+			if a.Action == applyCreate {
+				resp, err = client.Post(ctx, path, body) //nolint:bodyclose // decodeOptionalJSON closes body
+			} else {
+				resp, err = client.Put(ctx, path+"/"+a.ID, body) //nolint:bodyclose // decodeOptionalJSON closes body
+			}
+		case applyDelete:
+			resp, err = client.Delete(ctx, path+"/"+a.ID) //nolint:bodyclose // decodeOptionalJSON closes body
+		default:
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s %s %s: %w", a.Action, a.Kind, a.Key, err)
+		}
+
+		result, err := decodeOptionalJSON(resp)
+		if err != nil {
+			return err
+		}
+
+		if m, ok := result.(map[string]any); ok && a.Action == applyCreate {
+			a.ID = jsonStr(m, "id")
+		}
+	}
+
+	return nil
+}
+
+func writeApplyPlan(ctx context.Context, u *ui.UI, plan []applyAction, dryRun bool) error {
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{
+			"dry_run": dryRun,
+			"actions": plan,
+		})
+	}
+
+	w, done := tableWriter(ctx)
+
+	_, _ = fmt.Fprintln(w, "KIND\tKEY\tACTION\tID\tCHANGES")
+
+	for _, a := range plan {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", a.Kind, a.Key, a.Action, a.ID, len(a.Changes))
+	}
+
+	done()
+
+	if dryRun && u != nil {
+		u.Err().Printf("dry run: %d to create, %d to update, %d to delete",
+			countActions(plan, applyCreate), countActions(plan, applyUpdate), countActions(plan, applyDelete))
+	}
+
+	return nil
+}
@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+const applyManifest = `kind: product
+spec:
+  handle: {es: remera}
+  name: {es: Remera roja}
+---
+kind: product
+spec:
+  handle: {es: gorra}
+  name: {es: Gorra}
+---
+- kind: webhook
+  spec:
+    event: order/created
+    url: https://example.com/hook
+`
+
+type applyRequest struct {
+	Method, Path string
+	Body         map[string]any
+}
+
+func setupApplyAPI(t *testing.T) *[]applyRequest {
+	t.Helper()
+
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var (
+		mu   sync.Mutex
+		reqs []applyRequest
+	)
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		path := strings.TrimPrefix(r.URL.Path, "/v1/123/")
+
+		if r.Method == http.MethodGet {
+			switch path {
+			case "products":
+				_ = json.NewEncoder(w).Encode([]map[string]any{
+					{"id": 1, "handle": map[string]any{"es": "remera"}, "name": map[string]any{"es": "Remera"}},
+					{"id": 2, "handle": map[string]any{"es": "vieja"}, "name": map[string]any{"es": "Vieja"}},
+				})
+			case "webhooks":
+				_ = json.NewEncoder(w).Encode([]map[string]any{
+					{"id": 5, "event": "order/created", "url": "https://example.com/hook"},
+				})
+			default:
+				_ = json.NewEncoder(w).Encode(map[string]any{"id": 1})
+			}
+
+			return
+		}
+
+		var body map[string]any
+
+		b, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(b, &body)
+
+		mu.Lock()
+		reqs = append(reqs, applyRequest{r.Method, path, body})
+		mu.Unlock()
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 9})
+	}))
+
+	return &reqs
+}
+
+func writeManifest(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "resources.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestApply_Converges(t *testing.T) {
+	reqs := setupApplyAPI(t)
+	file := writeManifest(t, applyManifest)
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"apply", "-f", file, "--json", "--no-history"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	got := make([]string, 0, len(*reqs))
+	for _, r := range *reqs {
+		got = append(got, r.Method+" "+r.Path)
+	}
+
+	want := "PUT products/1,POST products"
+	if strings.Join(got, ",") != want {
+		t.Errorf("writes = %v, want %s", got, want)
+	}
+
+	var out struct {
+		Actions []applyAction `json:"actions"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("unmarshal: %v (output: %q)", err, buf.String())
+	}
+
+	actions := make([]string, 0, len(out.Actions))
+	for _, a := range out.Actions {
+		actions = append(actions, a.Kind+":"+a.Action+":"+a.ID)
+	}
+
+	if want := "product:update:1,product:create:9,webhook:unchanged:5"; strings.Join(actions, ",") != want {
+		t.Errorf("actions = %v, want %s", actions, want)
+	}
+}
+
+func TestApply_DryRunPrune(t *testing.T) {
+	reqs := setupApplyAPI(t)
+	file := writeManifest(t, applyManifest)
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"apply", "-f", file, "--prune", "--dry-run", "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if len(*reqs) != 0 {
+		t.Errorf("dry run sent writes: %v", *reqs)
+	}
+
+	if !strings.Contains(buf.String(), `"action": "delete"`) || !strings.Contains(buf.String(), `"key": "vieja"`) {
+		t.Errorf("output = %q, want delete of vieja", buf.String())
+	}
+}
+
+func TestApply_PruneNeedsConfirmation(t *testing.T) {
+	reqs := setupApplyAPI(t)
+	file := writeManifest(t, applyManifest)
+
+	_ = captureStdout(t)
+	_ = captureStderr(t)
+
+	err := Execute([]string{"apply", "-f", file, "--prune", "--no-input"})
+	if ExitCode(err) != ExitUsage {
+		t.Fatalf("exit code = %d, want %d", ExitCode(err), ExitUsage)
+	}
+
+	if len(*reqs) != 0 {
+		t.Errorf("writes = %v, want none", *reqs)
+	}
+}
+
+func TestApply_InvalidSpecFailsBeforeWrites(t *testing.T) {
+	reqs := setupApplyAPI(t)
+	file := writeManifest(t, applyManifest+`- kind: webhook
+  spec:
+    event: order/create
+    url: https://example.com/hook
+`)
+
+	stderr := captureStderr(t)
+
+	err := Execute([]string{"apply", "-f", file, "--no-history"})
+	if ExitCode(err) != ExitUsage {
+		t.Fatalf("exit code = %d, want %d (err %v)", ExitCode(err), ExitUsage, err)
+	}
+
+	if len(*reqs) != 0 {
+		t.Errorf("writes = %v, want none", *reqs)
+	}
+
+	if !strings.Contains(stderr.String(), "webhook order/create https://example.com/hook: body.event: order/create is not one of") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
+
+func TestParseManifest_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"empty":        "",
+		"unknown kind": "kind: gadget\nspec: {id: 1}\n",
+		"no spec":      "kind: product\n",
+		"no key":       "kind: coupon\nspec: {value: 10}\n",
+		"bad yaml":     "kind: [\n",
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := parseManifest([]byte(input)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestParseManifest_JSON(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseManifest([]byte(`[{"kind":"coupon","spec":{"code":"OFF10","value":10}}]`))
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if len(got) != 1 || got[0].Spec["value"] != float64(10) {
+		t.Errorf("resources = %+v", got)
+	}
+}
+
+func TestFieldMatches(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		spec, remote any
+		want         bool
+	}{
+		{"string", "a", "a", true},
+		{"string vs i18n", "remera", map[string]any{"es": "remera"}, true},
+		{"i18n vs i18n", map[string]any{"pt": "camisa"}, map[string]any{"es": "remera", "pt": "camisa"}, true},
+		{"i18n mismatch", map[string]any{"es": "x"}, map[string]any{"es": "y"}, false},
+		{"different", "a", "b", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := fieldMatches(tt.spec, tt.remote); got != tt.want {
+				t.Errorf("fieldMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
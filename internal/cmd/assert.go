@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/itchyny/gojq"
+
+	"github.com/gberlati/nube-cli/internal/outfmt"
+)
+
+// AssertCmd runs a command and checks its JSON output with a jq expression,
+// for shell conditionals and CI health checks: it exits 0 when the check
+// holds and 1 when it doesn't, printing nothing unless --json is set.
+type AssertCmd struct {
+	Command string `help:"Command line to run, e.g. \"order get 1\"" name:"command" required:""`
+	JQ      string `help:"jq expression that must hold for the command's JSON output, e.g. '.payment_status==\"paid\"' (without it the command only has to succeed)" name:"jq"`
+}
+
+func (c *AssertCmd) Run(ctx context.Context, flags *RootFlags) error {
+	args, err := splitCommandLine(c.Command)
+	if err != nil {
+		return newUsageError(err)
+	}
+
+	if len(args) == 0 {
+		return usagef("--command is empty")
+	}
+
+	var code *gojq.Code
+
+	if c.JQ != "" {
+		query, err := gojq.Parse(c.JQ)
+		if err != nil {
+			return usagef("--jq: %v", err)
+		}
+
+		code, err = gojq.Compile(query)
+		if err != nil {
+			return usagef("--jq: %v", err)
+		}
+	}
+
+	var stdout bytes.Buffer
+
+	// The command reports its own failure on stderr; pass its exit code on.
+	if err := execute(withNested(ctx), subcommandArgs(flags, append([]string{"--json"}, args...)), &stdout, stderrFrom(ctx)); err != nil {
+		return &ExitErr{Code: ExitCode(err)}
+	}
+
+	values := []any{}
+	passed := true
+
+	if code != nil {
+		values, err = runJQ(ctx, code, stdout.Bytes())
+		if err != nil {
+			return err
+		}
+
+		// Like jq -e: the last value decides, and no value at all fails.
+		passed = len(values) > 0 && truthy(values[len(values)-1])
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"passed": passed, "values": values}); err != nil {
+			return err
+		}
+	}
+
+	if !passed {
+		return &ExitErr{Code: ExitError}
+	}
+
+	return nil
+}
+
+// runJQ evaluates code against a JSON document and collects its values.
+func runJQ(ctx context.Context, code *gojq.Code, doc []byte) ([]any, error) {
+	var input any
+	if err := json.Unmarshal(doc, &input); err != nil {
+		return nil, fmt.Errorf("command output is not JSON: %w", err)
+	}
+
+	values := []any{}
+	iter := code.RunWithContext(ctx, input)
+
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			return values, nil
+		}
+
+		if err, isErr := v.(error); isErr {
+			var halt *gojq.HaltError
+			if errors.As(err, &halt) && halt.Value() == nil {
+				return values, nil
+			}
+
+			return nil, fmt.Errorf("--jq: %w", err)
+		}
+
+		values = append(values, v)
+	}
+}
+
+// truthy follows jq: everything but false and null is true.
+func truthy(v any) bool {
+	return v != nil && v != false
+}
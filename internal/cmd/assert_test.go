@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestAssert(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantOut  string
+	}{
+		{"holds", []string{"assert", "--command", "order get 1", "--jq", `.payment_status=="paid"`}, ExitOK, ""},
+		{"fails", []string{"assert", "--command", "order get 1", "--jq", `.payment_status=="pending"`}, ExitError, ""},
+		{"no output fails", []string{"assert", "--command", "order get 1", "--jq", "empty"}, ExitError, ""},
+		{"last value decides", []string{"assert", "--command", "order get 1", "--jq", ".products[].quantity > 1"}, ExitOK, ""},
+		{"no jq", []string{"assert", "--command", "order get 1"}, ExitOK, ""},
+		{"command fails", []string{"assert", "--command", "order get 2", "--jq", "true"}, ExitNotFound, ""},
+		{"json", []string{"assert", "--command", "order get 1", "--jq", ".number", "--json"}, ExitOK, `"passed": true`},
+		{"bad jq", []string{"assert", "--command", "order get 1", "--jq", ".payment_status=="}, ExitUsage, ""},
+		{"empty command", []string{"assert", "--command", " ", "--jq", "true"}, ExitUsage, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+			setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/123/orders/1" {
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`{"code":404,"message":"Not Found"}`))
+
+					return
+				}
+
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"id": 1, "number": 1001, "payment_status": "paid",
+					"products": []any{map[string]any{"quantity": 1}, map[string]any{"quantity": 3}},
+				})
+			}))
+
+			buf := captureStdout(t)
+			_ = captureStderr(t)
+
+			err := Execute(tt.args)
+			if ExitCode(err) != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d (err %v)", ExitCode(err), tt.wantCode, err)
+			}
+
+			if tt.wantOut == "" && buf.String() != "" {
+				t.Errorf("stdout = %q, want nothing", buf.String())
+			} else if !strings.Contains(buf.String(), tt.wantOut) {
+				t.Errorf("stdout = %q, want %q", buf.String(), tt.wantOut)
+			}
+		})
+	}
+}
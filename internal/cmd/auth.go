@@ -10,6 +10,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/config"
 	"github.com/gberlati/nube-cli/internal/credstore"
 	"github.com/gberlati/nube-cli/internal/oauth"
 	"github.com/gberlati/nube-cli/internal/outfmt"
@@ -25,66 +27,112 @@ type AuthCmd struct {
 	Status      AuthStatusCmd      `cmd:"" name:"status" help:"Show auth configuration"`
 	Token       AuthTokenCmd       `cmd:"" name:"token" help:"Print access token for a store profile"`
 	Default     AuthDefaultCmd     `cmd:"" name:"default" help:"Set default store profile"`
+	BaseURL     AuthBaseURLCmd     `cmd:"" name:"base-url" help:"Point a store profile at another API base URL"`
+	Defaults    AuthDefaultsCmd    `cmd:"" name:"defaults" help:"Show or set flag defaults for a store profile"`
+	Prune       AuthPruneCmd       `cmd:"" name:"prune" help:"Remove store profiles whose tokens were revoked"`
 }
 
 // --- Login (top-level) ---
 
 type LoginCmd struct {
-	Name      string        `arg:"" optional:"" name:"name" help:"Profile name (auto-generated if omitted)"`
-	Timeout   time.Duration `name:"timeout" help:"Authorization timeout" default:"5m"`
-	BrokerURL string        `name:"broker-url" help:"OAuth broker URL (overrides default)" env:"NUBE_AUTH_BROKER"`
+	Name             string        `arg:"" optional:"" name:"name" help:"Profile name (auto-generated if omitted)"`
+	Timeout          time.Duration `name:"auth-timeout" help:"How long to wait for browser authorization" default:"5m"`
+	BrokerURL        string        `name:"broker-url" help:"OAuth broker URL (overrides default)" env:"NUBE_AUTH_BROKER"`
+	CallbackTemplate string        `name:"callback-template" help:"HTML template for the page the browser shows after authorizing" type:"path"`
+	QR               bool          `name:"qr" help:"Show the authorization link as a QR code to approve from your phone; the token comes back through the broker"`
 }
 
 func (c *LoginCmd) Run(ctx context.Context, flags *RootFlags) error {
 	u := ui.FromContext(ctx)
 
-	tok, err := authorizeOAuth(ctx, oauth.AuthorizeOptions{
-		Timeout:   c.Timeout,
-		OAuthApp:  "default",
-		BrokerURL: c.BrokerURL,
-	})
+	opts, err := authorizeOptions(c.Timeout, c.BrokerURL)
 	if err != nil {
 		return err
 	}
 
-	userID := tok.UserID.String()
+	opts.CallbackTemplate = c.CallbackTemplate
+	opts.QR = c.QR
 
-	var scopes []string
-	if tok.Scope != "" {
-		scopes = strings.Split(tok.Scope, " ")
+	tok, err := authorizeOAuth(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := validateBaseURL(flags.APIBaseURL); err != nil {
+		return usagef("--api-base-url: %v", err)
 	}
 
 	name := strings.TrimSpace(c.Name)
 	if name == "" {
-		name = "store-" + userID
+		name = defaultProfileName(tok)
 	}
 
-	profile := credstore.StoreProfile{
-		StoreID:     userID,
-		AccessToken: tok.AccessToken,
-		Scopes:      scopes,
-		CreatedAt:   time.Now().UTC().Format(time.RFC3339),
-	}
+	profile := loginProfile(tok, flags.APIBaseURL)
 
 	if err := credstore.SetStore(name, profile); err != nil {
 		return err
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{
 			"stored":   true,
 			"name":     name,
-			"store_id": userID,
-			"scopes":   scopes,
+			"store_id": profile.StoreID,
+			"scopes":   profile.Scopes,
 		})
 	}
 
 	u.Out().Printf("name\t%s", name)
-	u.Out().Printf("store_id\t%s", userID)
+	u.Out().Printf("store_id\t%s", profile.StoreID)
 
 	return nil
 }
 
+// authorizeOptions are the options of a login through brokerURL, or through
+// the default broker or the saved OAuth client when it is empty. Brokers
+// are held to the config broker_allowlist.
+func authorizeOptions(timeout time.Duration, brokerURL string) (oauth.AuthorizeOptions, error) {
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return oauth.AuthorizeOptions{}, err
+	}
+
+	if brokerURL != "" {
+		if _, err := oauth.CheckBrokerURL(brokerURL, cfg.BrokerAllowlist); err != nil {
+			return oauth.AuthorizeOptions{}, usagef("--broker-url: %v", err)
+		}
+	}
+
+	return oauth.AuthorizeOptions{
+		Timeout:        timeout,
+		OAuthApp:       "default",
+		BrokerURL:      brokerURL,
+		AllowedBrokers: cfg.BrokerAllowlist,
+	}, nil
+}
+
+// defaultProfileName names the profile of a login when the user gives no
+// name.
+func defaultProfileName(tok oauth.TokenResponse) string {
+	return "store-" + tok.UserID.String()
+}
+
+// loginProfile is the store profile saved for an authorized token.
+func loginProfile(tok oauth.TokenResponse, apiBaseURL string) credstore.StoreProfile {
+	var scopes []string
+	if tok.Scope != "" {
+		scopes = strings.Split(tok.Scope, " ")
+	}
+
+	return credstore.StoreProfile{
+		StoreID:     tok.UserID.String(),
+		AccessToken: tok.AccessToken,
+		Scopes:      scopes,
+		CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+		APIBaseURL:  apiBaseURL,
+	}
+}
+
 // --- Logout (top-level) ---
 
 type LogoutCmd struct {
@@ -135,7 +183,9 @@ func (c *AuthCredentialsSetCmd) Run(ctx context.Context, _ *RootFlags) error {
 	inPath := c.Path
 
 	if inPath == "-" {
+		done := waitingForInput()
 		b, err = io.ReadAll(os.Stdin)
+		done()
 	} else {
 		inPath, err = expandPath(inPath)
 		if err != nil {
@@ -149,30 +199,14 @@ func (c *AuthCredentialsSetCmd) Run(ctx context.Context, _ *RootFlags) error {
 		return err
 	}
 
-	var creds struct {
-		ClientID     string `json:"client_id"`
-		ClientSecret string `json:"client_secret"` //nolint:gosec // field name
-	}
-
-	if parseErr := json.Unmarshal(b, &creds); parseErr != nil {
-		return fmt.Errorf("parse credentials: %w", parseErr)
-	}
-
-	if creds.ClientID == "" || creds.ClientSecret == "" {
-		return fmt.Errorf("credentials.json must contain client_id and client_secret")
-	}
-
-	if err := credstore.SetOAuthClient("default", credstore.OAuthClient{
-		ClientID:     creds.ClientID,
-		ClientSecret: creds.ClientSecret,
-	}); err != nil {
+	if err := saveOAuthClient(b); err != nil {
 		return err
 	}
 
 	credPath, _ := credstore.Path()
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{
 			"saved": true,
 			"path":  credPath,
 		})
@@ -183,6 +217,28 @@ func (c *AuthCredentialsSetCmd) Run(ctx context.Context, _ *RootFlags) error {
 	return nil
 }
 
+// saveOAuthClient stores the client_id and client_secret of an app's
+// credentials.json as the default OAuth client.
+func saveOAuthClient(b []byte) error {
+	var creds struct {
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"` //nolint:gosec // field name
+	}
+
+	if err := json.Unmarshal(b, &creds); err != nil {
+		return fmt.Errorf("parse credentials: %w", err)
+	}
+
+	if creds.ClientID == "" || creds.ClientSecret == "" {
+		return fmt.Errorf("credentials.json must contain client_id and client_secret")
+	}
+
+	return credstore.SetOAuthClient("default", credstore.OAuthClient{
+		ClientID:     creds.ClientID,
+		ClientSecret: creds.ClientSecret,
+	})
+}
+
 // expandPath expands ~ at the beginning of a path to the user's home directory.
 func expandPath(path string) (string, error) {
 	if path == "" {
@@ -217,7 +273,7 @@ func (c *AuthCredentialsListCmd) Run(ctx context.Context, _ *RootFlags) error {
 
 	if len(f.OAuthClients) == 0 {
 		if outfmt.IsJSON(ctx) {
-			return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"clients": []any{}})
+			return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"clients": []any{}})
 		}
 
 		u.Err().Println("No OAuth client credentials stored")
@@ -237,7 +293,7 @@ func (c *AuthCredentialsListCmd) Run(ctx context.Context, _ *RootFlags) error {
 	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"clients": entries})
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"clients": entries})
 	}
 
 	w, done := tableWriter(ctx)
@@ -265,30 +321,34 @@ func (c *AuthListCmd) Run(ctx context.Context, _ *RootFlags) error {
 	}
 
 	type item struct {
-		Name      string   `json:"name"`
-		StoreID   string   `json:"store_id"`
-		Email     string   `json:"email,omitempty"`
-		Scopes    []string `json:"scopes,omitempty"`
-		CreatedAt string   `json:"created_at,omitempty"`
-		Default   bool     `json:"default"`
+		Name       string   `json:"name"`
+		StoreID    string   `json:"store_id"`
+		Email      string   `json:"email,omitempty"`
+		Scopes     []string `json:"scopes,omitempty"`
+		CreatedAt  string   `json:"created_at,omitempty"`
+		LastUsedAt string   `json:"last_used_at,omitempty"`
+		APIBaseURL string   `json:"api_base_url,omitempty"`
+		Default    bool     `json:"default"`
 	}
 
 	items := make([]item, 0, len(f.Stores))
 	for name, p := range f.Stores {
 		items = append(items, item{
-			Name:      name,
-			StoreID:   p.StoreID,
-			Email:     p.Email,
-			Scopes:    p.Scopes,
-			CreatedAt: p.CreatedAt,
-			Default:   name == f.DefaultStore,
+			Name:       name,
+			StoreID:    p.StoreID,
+			Email:      p.Email,
+			Scopes:     p.Scopes,
+			CreatedAt:  p.CreatedAt,
+			LastUsedAt: p.LastUsedAt,
+			APIBaseURL: p.APIBaseURL,
+			Default:    name == f.DefaultStore,
 		})
 	}
 
 	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"stores": items})
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"stores": items})
 	}
 
 	if len(items) == 0 {
@@ -299,7 +359,7 @@ func (c *AuthListCmd) Run(ctx context.Context, _ *RootFlags) error {
 	w, done := tableWriter(ctx)
 	defer done()
 
-	_, _ = fmt.Fprintln(w, "NAME\tSTORE ID\tDEFAULT\tCREATED")
+	_, _ = fmt.Fprintln(w, "NAME\tSTORE ID\tDEFAULT\tCREATED\tLAST USED")
 
 	for _, it := range items {
 		def := ""
@@ -307,12 +367,53 @@ func (c *AuthListCmd) Run(ctx context.Context, _ *RootFlags) error {
 			def = "*"
 		}
 
-		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", it.Name, it.StoreID, def, it.CreatedAt)
+		lastUsed := it.LastUsedAt
+		if lastUsed == "" {
+			lastUsed = "never"
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", it.Name, it.StoreID, def, it.CreatedAt, lastUsed)
 	}
 
 	return nil
 }
 
+// --- Auth Base URL ---
+
+type AuthBaseURLCmd struct {
+	Name string `arg:"" name:"name" help:"Profile name"`
+	URL  string `arg:"" optional:"" name:"url" help:"API base URL, e.g. http://localhost:8080/v1 (omit to use the Tienda Nube API)"`
+}
+
+func (c *AuthBaseURLCmd) Run(ctx context.Context, _ *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	if err := validateBaseURL(c.URL); err != nil {
+		return newUsageError(err)
+	}
+
+	profile, err := credstore.GetStore(c.Name)
+	if err != nil {
+		return &ExitErr{Code: ExitConfig, Err: err}
+	}
+
+	profile.APIBaseURL = c.URL
+
+	if err := credstore.SetStore(c.Name, profile); err != nil {
+		return err
+	}
+
+	baseURL := c.URL
+	if baseURL == "" {
+		baseURL = api.DefaultBaseURL
+	}
+
+	return writeResult(ctx, u,
+		kv("name", c.Name),
+		kv("api_base_url", baseURL),
+	)
+}
+
 // --- Auth Status ---
 
 type AuthStatusCmd struct{}
@@ -334,14 +435,14 @@ func (c *AuthStatusCmd) Run(ctx context.Context, flags *RootFlags) error {
 	storeID := ""
 
 	if flags != nil {
-		if name, profile, resolveErr := credstore.ResolveStore(flags.Store); resolveErr == nil {
+		if name, profile, resolveErr := flags.resolveStore(flags.Store); resolveErr == nil {
 			storeName = name
 			storeID = profile.StoreID
 		}
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{
 			"credentials": map[string]any{
 				"path":   credPath,
 				"exists": credExists,
@@ -383,13 +484,13 @@ func (c *AuthTokenCmd) Run(ctx context.Context, flags *RootFlags) error {
 		flagStore = name
 	}
 
-	resolvedName, profile, err := credstore.ResolveStore(flagStore)
+	resolvedName, profile, err := flags.resolveStore(flagStore)
 	if err != nil {
 		return &ExitErr{Code: ExitConfig, Err: err}
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{
 			"access_token": profile.AccessToken,
 			"store_id":     profile.StoreID,
 			"name":         resolvedName,
@@ -397,7 +498,7 @@ func (c *AuthTokenCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	// Plain: just the token, suitable for $(nube auth token ...)
-	fmt.Fprintln(os.Stdout, profile.AccessToken)
+	fmt.Fprintln(stdoutFrom(ctx), profile.AccessToken)
 
 	return nil
 }
@@ -424,3 +525,101 @@ func (c *AuthDefaultCmd) Run(ctx context.Context, _ *RootFlags) error {
 		kv("default", name),
 	)
 }
+
+// --- Auth Prune ---
+
+type AuthPruneCmd struct{}
+
+func (c *AuthPruneCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	f, err := credstore.Read()
+	if err != nil {
+		return err
+	}
+
+	type item struct {
+		Name    string `json:"name"`
+		StoreID string `json:"store_id"`
+		Status  string `json:"status"`
+		Error   string `json:"error,omitempty"`
+		Removed bool   `json:"removed"`
+	}
+
+	names := make([]string, 0, len(f.Stores))
+	for name := range f.Stores {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	items := make([]item, 0, len(names))
+
+	var revoked []string
+
+	// Only a 401 proves a token is gone; profiles that can't be checked
+	// (network errors, a bad base URL) are kept.
+	for _, name := range names {
+		p := f.Stores[name]
+		it := item{Name: name, StoreID: p.StoreID}
+
+		client, err := profileClient(flags, name, p)
+		if err == nil {
+			it.Status, err = checkInstallation(ctx, client)
+		}
+
+		if err != nil {
+			it.Status, it.Error = "unknown", err.Error()
+			u.Err().Printf("%s: can't check: %v", name, err)
+		}
+
+		if it.Status == appRevoked {
+			revoked = append(revoked, name)
+		}
+
+		items = append(items, it)
+	}
+
+	if len(revoked) > 0 && !flags.DryRun {
+		if err := confirmDestructive(flags, fmt.Sprintf("remove revoked store profiles %s", strings.Join(revoked, ", "))); err != nil {
+			return err
+		}
+
+		for i := range items {
+			if items[i].Status != appRevoked {
+				continue
+			}
+
+			if err := credstore.RemoveStore(items[i].Name); err != nil {
+				return err
+			}
+
+			items[i].Removed = true
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"profiles": items, "dry_run": flags.DryRun})
+	}
+
+	if len(items) == 0 {
+		u.Err().Println("No store profiles configured")
+		return nil
+	}
+
+	w, done := tableWriter(ctx)
+	defer done()
+
+	_, _ = fmt.Fprintln(w, "NAME\tSTORE ID\tSTATUS\tREMOVED")
+
+	for _, it := range items {
+		removed := ""
+		if it.Removed {
+			removed = "yes"
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", it.Name, it.StoreID, it.Status, removed)
+	}
+
+	return nil
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/gberlati/nube-cli/internal/config"
 	"github.com/gberlati/nube-cli/internal/credstore"
 	"github.com/gberlati/nube-cli/internal/oauth"
 )
@@ -120,19 +124,47 @@ func TestLogin_BrokerURL(t *testing.T) {
 	t.Cleanup(func() { authorizeOAuth = orig })
 
 	_ = captureStdout(t)
-	err := Execute([]string{"login", "test", "--broker-url", "http://broker.test"})
+	_ = captureStderr(t)
+
+	err := Execute([]string{"login", "test", "--broker-url", "https://broker.test"})
 	if err != nil {
 		t.Fatalf("error = %v", err)
 	}
 
-	if capturedOpts.BrokerURL != "http://broker.test" {
-		t.Errorf("BrokerURL = %q, want %q", capturedOpts.BrokerURL, "http://broker.test")
+	if capturedOpts.BrokerURL != "https://broker.test" {
+		t.Errorf("BrokerURL = %q, want %q", capturedOpts.BrokerURL, "https://broker.test")
+	}
+
+	capturedOpts = oauth.AuthorizeOptions{}
+
+	if err := Execute([]string{"login", "test", "--broker-url", "http://broker.test"}); ExitCode(err) != ExitUsage {
+		t.Errorf("http broker: exit code = %d, want %d (err %v)", ExitCode(err), ExitUsage, err)
+	}
+
+	if err := config.WriteConfig(config.File{BrokerAllowlist: []string{"broker.corp"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Execute([]string{"login", "test", "--broker-url", "https://broker.test"}); ExitCode(err) != ExitUsage {
+		t.Errorf("broker not in allowlist: exit code = %d, want %d (err %v)", ExitCode(err), ExitUsage, err)
+	}
+
+	if capturedOpts.BrokerURL != "" {
+		t.Errorf("authorized through a rejected broker: %+v", capturedOpts)
+	}
+
+	if err := Execute([]string{"login", "test"}); err != nil || len(capturedOpts.AllowedBrokers) != 1 {
+		t.Errorf("err = %v, AllowedBrokers = %v, want the allowlist passed on", err, capturedOpts.AllowedBrokers)
+	}
+	if err := Execute([]string{"login", "test", "--qr"}); err != nil || !capturedOpts.QR {
+		t.Errorf("err = %v, QR = %v, want --qr passed on", err, capturedOpts.QR)
 	}
 }
 
 func TestAuthList(t *testing.T) {
 	stores := map[string]credstore.StoreProfile{
-		"my-shop": {StoreID: "123", AccessToken: "tok"},
+		"my-shop": {StoreID: "123", AccessToken: "tok", LastUsedAt: "2026-03-01T10:00:00Z"},
+		"old":     {StoreID: "456", AccessToken: "tok2"},
 	}
 	setupCredStore(t, stores, "my-shop")
 
@@ -145,6 +177,10 @@ func TestAuthList(t *testing.T) {
 	if !strings.Contains(buf.String(), "my-shop") {
 		t.Errorf("output = %q, want containing store name", buf.String())
 	}
+
+	if !strings.Contains(buf.String(), "2026-03-01T10:00:00Z") || !strings.Contains(buf.String(), "never") {
+		t.Errorf("output = %q, want last used times", buf.String())
+	}
 }
 
 func TestLogout(t *testing.T) {
@@ -263,3 +299,151 @@ func TestAuthDefault(t *testing.T) {
 		t.Errorf("DefaultStore = %q, want %q", f.DefaultStore, "b")
 	}
 }
+
+func TestAuthBaseURL_ProfileAndFlag(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"staging": {StoreID: "123", AccessToken: "tok"}}, "staging")
+	t.Setenv("NUBE_ACCESS_TOKEN", "")
+
+	var hits []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits = append(hits, r.URL.Path)
+		_, _ = w.Write([]byte(`{"id":123}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	_ = captureStdout(t)
+
+	if err := Execute([]string{"auth", "base-url", "staging", srv.URL + "/v1"}); err != nil {
+		t.Fatalf("auth base-url error = %v", err)
+	}
+
+	if p, _ := credstore.GetStore("staging"); p.APIBaseURL != srv.URL+"/v1" {
+		t.Fatalf("APIBaseURL = %q", p.APIBaseURL)
+	}
+
+	if err := Execute([]string{"store", "get", "--json"}); err != nil {
+		t.Fatalf("store get error = %v", err)
+	}
+
+	// The flag wins over the profile.
+	if err := Execute([]string{"store", "get", "--json", "--api-base-url", srv.URL + "/other"}); err != nil {
+		t.Fatalf("store get --api-base-url error = %v", err)
+	}
+
+	want := []string{"/v1/123/store", "/other/123/store"}
+	if strings.Join(hits, " ") != strings.Join(want, " ") {
+		t.Errorf("requests = %v, want %v", hits, want)
+	}
+
+	if err := Execute([]string{"auth", "base-url", "staging"}); err != nil {
+		t.Fatalf("clearing base-url error = %v", err)
+	}
+
+	if p, _ := credstore.GetStore("staging"); p.APIBaseURL != "" {
+		t.Errorf("APIBaseURL = %q after clearing", p.APIBaseURL)
+	}
+}
+
+func TestAuthBaseURL_Invalid(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"bad": {StoreID: "1", AccessToken: "tok", APIBaseURL: "localhost:8080"}}, "bad")
+	t.Setenv("NUBE_ACCESS_TOKEN", "")
+	_ = captureStderr(t)
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"set", []string{"auth", "base-url", "bad", "ftp://example.com"}, ExitUsage},
+		{"flag", []string{"store", "get", "--api-base-url", "not a url"}, ExitUsage},
+		{"profile", []string{"store", "get"}, ExitConfig},
+		{"unknown profile", []string{"auth", "base-url", "nope", "http://localhost"}, ExitConfig},
+	}
+
+	for _, tt := range tests {
+		if code := ExitCode(Execute(tt.args)); code != tt.code {
+			t.Errorf("%s: exit code = %d, want %d", tt.name, code, tt.code)
+		}
+	}
+}
+
+func TestLogin_APIBaseURL(t *testing.T) {
+	setupConfigDir(t)
+	mockAuthorizeOAuth(t, oauth.TokenResponse{AccessToken: "tok", UserID: "7"}, nil)
+	_ = captureStdout(t)
+
+	if err := Execute([]string{"login", "dev", "--api-base-url", "http://localhost:8080/v1"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if p, _ := credstore.GetStore("dev"); p.APIBaseURL != "http://localhost:8080/v1" {
+		t.Errorf("APIBaseURL = %q", p.APIBaseURL)
+	}
+}
+
+func TestAuthPrune(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authentication") == "bearer dead" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code":401,"message":"Unauthorized"}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	setupCredStore(t, map[string]credstore.StoreProfile{
+		"live":    {StoreID: "1", AccessToken: "ok", APIBaseURL: srv.URL},
+		"gone":    {StoreID: "2", AccessToken: "dead", APIBaseURL: srv.URL},
+		"offline": {StoreID: "3", AccessToken: "dead", APIBaseURL: "http://127.0.0.1:1"},
+	}, "live")
+	_ = captureStderr(t)
+
+	_ = captureStdout(t)
+
+	if err := Execute([]string{"auth", "prune", "--dry-run", "--json"}); err != nil {
+		t.Fatalf("dry run error = %v", err)
+	}
+
+	if names, _ := credstore.ListStores(); len(names) != 3 {
+		t.Fatalf("dry run removed profiles: %v", names)
+	}
+
+	stdout := captureStdout(t)
+
+	if err := Execute([]string{"auth", "prune", "--force", "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	// Unreachable profiles are kept: only a 401 proves a token is revoked.
+	names, _ := credstore.ListStores()
+	if strings.Join(names, ",") != "live,offline" {
+		t.Errorf("profiles after prune = %v", names)
+	}
+
+	var out struct {
+		Profiles []struct {
+			Name    string `json:"name"`
+			Status  string `json:"status"`
+			Removed bool   `json:"removed"`
+		} `json:"profiles"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		t.Fatalf("parse: %v\n%s", err, stdout.String())
+	}
+
+	got := map[string]string{}
+	for _, p := range out.Profiles {
+		got[p.Name] = fmt.Sprintf("%s/%v", p.Status, p.Removed)
+	}
+
+	want := map[string]string{"gone": "revoked/true", "live": "installed/false", "offline": "unknown/false"}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("%s = %q, want %q", name, got[name], w)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/outfmt"
+)
+
+var errEmptyBatch = errors.New("batch file has no steps")
+
+type batchCtxKey struct{}
+
+// BatchCmd groups batch execution commands.
+type BatchCmd struct {
+	Run BatchRunCmd `cmd:"" default:"withargs" help:"Run commands from a script file"`
+}
+
+// BatchRunCmd executes a list of CLI invocations and reports per-step results.
+type BatchRunCmd struct {
+	File            string `arg:"" name:"file" help:"Script file (JSON lines, a JSON array or a YAML list), or '-' for stdin"`
+	Parallel        int    `help:"Number of steps to run concurrently" name:"parallel" default:"1"`
+	ContinueOnError bool   `help:"Keep running after a step fails (default: stop)" name:"continue-on-error"`
+}
+
+// batchStep is one entry of a batch script. Either Args or Command is required.
+type batchStep struct {
+	Name    string   `json:"name,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	Command string   `json:"command,omitempty"`
+}
+
+// batchStepResult is the per-step report entry.
+type batchStepResult struct {
+	Index      int      `json:"index"`
+	Name       string   `json:"name,omitempty"`
+	Args       []string `json:"args"`
+	OK         bool     `json:"ok"`
+	Skipped    bool     `json:"skipped,omitempty"`
+	ExitCode   int      `json:"exit_code"`
+	Error      string   `json:"error,omitempty"`
+	DurationMS int64    `json:"duration_ms"`
+	Output     any      `json:"output,omitempty"`
+}
+
+func (c *BatchRunCmd) Run(ctx context.Context, flags *RootFlags) error {
+	// A batch file that runs itself would never finish.
+	if ctx.Value(batchCtxKey{}) != nil {
+		return usagef("batch run cannot run inside a batch")
+	}
+
+	steps, err := readBatchFile(c.File)
+	if err != nil {
+		return err
+	}
+
+	if c.Parallel < 1 {
+		return usagef("--parallel must be at least 1")
+	}
+
+	results := runBatch(ctx, flags, steps, c.Parallel, !c.ContinueOnError)
+
+	failed := 0
+	firstCode := ExitOK
+
+	for _, r := range results {
+		if !r.OK && !r.Skipped {
+			failed++
+
+			if firstCode == ExitOK {
+				firstCode = r.ExitCode
+			}
+		}
+	}
+
+	if err := writeBatchReport(ctx, results, failed); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		// The report already describes the failures; only propagate the exit code.
+		return &ExitErr{Code: firstCode}
+	}
+
+	return nil
+}
+
+func readBatchFile(path string) ([]batchStep, error) {
+	b, err := readInputFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := parseBatchSteps(b)
+	if err != nil {
+		return nil, newUsageError(err)
+	}
+
+	if len(steps) == 0 {
+		return nil, newUsageError(errEmptyBatch)
+	}
+
+	return steps, nil
+}
+
+// parseBatchSteps accepts a JSON array of steps, one JSON step per line, or
+// a YAML list of steps.
+func parseBatchSteps(b []byte) ([]batchStep, error) {
+	var steps []batchStep
+
+	trimmed := bytes.TrimSpace(b)
+
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		if err := json.Unmarshal(trimmed, &steps); err != nil {
+			return nil, fmt.Errorf("parse batch file: %w", err)
+		}
+	case !isJSONLines(trimmed):
+		j, err := jsonFromYAML(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("parse batch file: %w", err)
+		}
+
+		if err := json.Unmarshal(j, &steps); err != nil {
+			return nil, fmt.Errorf("parse batch file: want a list of steps: %w", err)
+		}
+	default:
+		sc := bufio.NewScanner(bytes.NewReader(b))
+		sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+		for line := 1; sc.Scan(); line++ {
+			text := strings.TrimSpace(sc.Text())
+			if text == "" || strings.HasPrefix(text, "#") {
+				continue
+			}
+
+			var s batchStep
+			if err := json.Unmarshal([]byte(text), &s); err != nil {
+				return nil, fmt.Errorf("parse batch file line %d: %w", line, err)
+			}
+
+			steps = append(steps, s)
+		}
+
+		if err := sc.Err(); err != nil {
+			return nil, fmt.Errorf("read batch file: %w", err)
+		}
+	}
+
+	for i := range steps {
+		if len(steps[i].Args) > 0 {
+			continue
+		}
+
+		args, err := splitCommandLine(steps[i].Command)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %w", i+1, err)
+		}
+
+		if len(args) == 0 {
+			return nil, fmt.Errorf("step %d: args or command is required", i+1)
+		}
+
+		steps[i].Args = args
+	}
+
+	return steps, nil
+}
+
+// isJSONLines reports whether the first line that isn't blank or a comment
+// holds a JSON object; anything else that isn't a JSON array is YAML.
+func isJSONLines(b []byte) bool {
+	for line := range strings.Lines(string(b)) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		return strings.HasPrefix(line, "{")
+	}
+
+	return true
+}
+
+// runBatch executes steps with up to parallel workers. With stopOnError, steps
+// not yet started when a failure occurs are reported as skipped.
+func runBatch(ctx context.Context, flags *RootFlags, steps []batchStep, parallel int, stopOnError bool) []batchStepResult {
+	results := make([]batchStepResult, len(steps))
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		stopped bool
+	)
+
+	sem := make(chan struct{}, parallel)
+
+	for i, step := range steps {
+		sem <- struct{}{}
+
+		mu.Lock()
+		skip := stopped
+		mu.Unlock()
+
+		if skip {
+			<-sem
+
+			results[i] = batchStepResult{Index: i + 1, Name: step.Name, Args: step.Args, Skipped: true}
+
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(i int, step batchStep) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			res := runBatchStep(ctx, flags, step)
+			res.Index = i + 1
+			results[i] = res
+
+			if !res.OK && stopOnError {
+				mu.Lock()
+				stopped = true
+				mu.Unlock()
+			}
+		}(i, step)
+
+		// Sequential runs must observe a failure before starting the next step.
+		if parallel == 1 {
+			wg.Wait()
+		}
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+func runBatchStep(ctx context.Context, flags *RootFlags, step batchStep) batchStepResult {
+	var stdout, stderr bytes.Buffer
+
+	start := time.Now()
+	err := execute(withNested(context.WithValue(ctx, batchCtxKey{}, true)), subcommandArgs(flags, step.Args), &stdout, &stderr)
+
+	res := batchStepResult{
+		Name:       step.Name,
+		Args:       step.Args,
+		OK:         err == nil,
+		ExitCode:   ExitCode(err),
+		DurationMS: time.Since(start).Milliseconds(),
+		Output:     stepOutput(stdout.Bytes()),
+	}
+
+	if err != nil {
+		res.Error = strings.TrimSpace(stderr.String())
+		if res.Error == "" {
+			res.Error = err.Error()
+		}
+	}
+
+	return res
+}
+
+// stepOutput embeds JSON output as-is and anything else as a string.
+func stepOutput(b []byte) any {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return nil
+	}
+
+	if json.Valid(b) {
+		return json.RawMessage(b)
+	}
+
+	return string(b)
+}
+
+func writeBatchReport(ctx context.Context, results []batchStepResult, failed int) error {
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{
+			"ok":     failed == 0,
+			"failed": failed,
+			"steps":  results,
+		})
+	}
+
+	w, done := tableWriter(ctx)
+	defer done()
+
+	_, _ = fmt.Fprintln(w, "STEP\tNAME\tCOMMAND\tSTATUS\tEXIT\tDURATION")
+
+	for _, r := range results {
+		status := "ok"
+
+		switch {
+		case r.Skipped:
+			status = "skipped"
+		case !r.OK:
+			status = "failed"
+		}
+
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%dms\n", r.Index, r.Name, strings.Join(r.Args, " "), status, r.ExitCode, r.DurationMS)
+	}
+
+	for _, r := range results {
+		if r.Error != "" {
+			_, _ = fmt.Fprintf(stderrFrom(ctx), "step %d: %s\n", r.Index, firstLine(r.Error))
+		}
+	}
+
+	return nil
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+
+	return line
+}
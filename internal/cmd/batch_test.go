@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestParseBatchSteps(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    [][]string
+		wantErr bool
+	}{
+		{
+			name:  "json lines",
+			input: "{\"args\":[\"product\",\"get\",\"1\"]}\n\n# comment\n{\"command\":\"order get 'A 1'\"}\n",
+			want:  [][]string{{"product", "get", "1"}, {"order", "get", "A 1"}},
+		},
+		{
+			name:  "json array",
+			input: `[{"name":"a","command":"store get"},{"args":["version"]}]`,
+			want:  [][]string{{"store", "get"}, {"version"}},
+		},
+		{
+			name:  "yaml list",
+			input: "# nightly\n- command: store get\n- name: one\n  args: [product, get, \"1\"]\n",
+			want:  [][]string{{"store", "get"}, {"product", "get", "1"}},
+		},
+		{name: "yaml not a list", input: "command: store get\n", wantErr: true},
+		{name: "empty step", input: `{"name":"x"}`, wantErr: true},
+		{name: "bad json", input: `{"args":`, wantErr: true},
+		{name: "unterminated quote", input: `{"command":"product get 'x"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			steps, err := parseBatchSteps([]byte(tt.input))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", steps)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+
+			var got [][]string
+			for _, s := range steps {
+				got = append(got, s.Args)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("args = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func writeBatchFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "steps.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func batchHandler(calls *atomic.Int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+
+		if strings.HasSuffix(r.URL.Path, "products/404") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 1, "name": map[string]any{"es": "Remera"}})
+	})
+}
+
+func runBatchJSON(t *testing.T, args []string) (map[string]any, error) {
+	t.Helper()
+
+	buf := captureStdout(t)
+	_ = captureStderr(t)
+
+	err := Execute(args)
+
+	var got map[string]any
+	if jsonErr := json.Unmarshal(buf.Bytes(), &got); jsonErr != nil {
+		t.Fatalf("unmarshal: %v (output: %q)", jsonErr, buf.String())
+	}
+
+	return got, err
+}
+
+func TestBatchRun_AllSucceed(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var calls atomic.Int32
+
+	setupMockAPIClient(t, batchHandler(&calls))
+
+	path := writeBatchFile(t, `{"name":"first","command":"product get 1 --json"}
+{"args":["store","get","--json"]}
+`)
+
+	got, err := runBatchJSON(t, []string{"batch", "run", path, "--json"})
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if got["ok"] != true {
+		t.Errorf("ok = %v", got["ok"])
+	}
+
+	steps, _ := got["steps"].([]any)
+	if len(steps) != 2 {
+		t.Fatalf("steps = %d, want 2", len(steps))
+	}
+
+	first, _ := steps[0].(map[string]any)
+	if first["name"] != "first" {
+		t.Errorf("name = %v", first["name"])
+	}
+
+	output, _ := first["output"].(map[string]any)
+	if jsonStr(output, "id") != "1" {
+		t.Errorf("output = %v, want embedded JSON", first["output"])
+	}
+}
+
+func TestBatchRun_StopsOnError(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var calls atomic.Int32
+
+	setupMockAPIClient(t, batchHandler(&calls))
+
+	path := writeBatchFile(t, `{"command":"product get 404"}
+{"command":"product get 1"}
+`)
+
+	got, err := runBatchJSON(t, []string{"batch", "run", path, "--json"})
+	if ExitCode(err) != ExitNotFound {
+		t.Fatalf("exit code = %d, want %d", ExitCode(err), ExitNotFound)
+	}
+
+	if calls.Load() != 1 {
+		t.Errorf("API calls = %d, want 1", calls.Load())
+	}
+
+	steps, _ := got["steps"].([]any)
+	second, _ := steps[1].(map[string]any)
+
+	if second["skipped"] != true {
+		t.Errorf("second step = %v, want skipped", second)
+	}
+}
+
+func TestBatchRun_ContinueOnErrorParallel(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var calls atomic.Int32
+
+	setupMockAPIClient(t, batchHandler(&calls))
+
+	path := writeBatchFile(t, `{"command":"product get 404"}
+{"command":"product get 1"}
+{"command":"product get 2"}
+`)
+
+	got, err := runBatchJSON(t, []string{"batch", "run", path, "--json", "--continue-on-error", "--parallel", "2"})
+	if ExitCode(err) != ExitNotFound {
+		t.Fatalf("exit code = %d, want %d", ExitCode(err), ExitNotFound)
+	}
+
+	if calls.Load() != 3 {
+		t.Errorf("API calls = %d, want 3", calls.Load())
+	}
+
+	if got["failed"] != float64(1) {
+		t.Errorf("failed = %v, want 1", got["failed"])
+	}
+}
+
+func TestBatchRun_InheritsScopeFlags(t *testing.T) {
+	setupConfigDir(t)
+	_ = captureStdout(t)
+	_ = captureStderr(t)
+
+	path := writeBatchFile(t, `{"command":"product list"}`)
+
+	err := Execute([]string{"batch", "run", path, "--enable-commands", "batch,store"})
+	if ExitCode(err) != ExitUsage {
+		t.Fatalf("exit code = %d, want %d", ExitCode(err), ExitUsage)
+	}
+}
+
+func TestBatchRun_InheritsExpectStore(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{
+		"test":  {StoreID: "123", AccessToken: "tok"},
+		"other": {StoreID: "456", AccessToken: "tok2"},
+	}, "test")
+
+	var calls atomic.Int32
+
+	setupMockAPIClient(t, batchHandler(&calls))
+
+	// The step picks another store; the parent's --expect-store still holds.
+	path := writeBatchFile(t, `{"args":["--store","other","product","get","1"]}`)
+
+	_, err := runBatchJSON(t, []string{"--expect-store", "test", "batch", "run", path, "--json"})
+	if ExitCode(err) != ExitMismatch {
+		t.Fatalf("exit code = %d, want %d (err %v)", ExitCode(err), ExitMismatch, err)
+	}
+
+	if calls.Load() != 0 {
+		t.Errorf("API calls = %d, want none after a mismatch", calls.Load())
+	}
+}
+
+func TestBatchRun_InheritsAPIBaseURL(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var paths []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":123}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	path := writeBatchFile(t, `{"command":"store get --json"}`)
+
+	if _, err := runBatchJSON(t, []string{"--api-base-url", srv.URL + "/v1", "batch", "run", path, "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if len(paths) != 1 || paths[0] != "/v1/123/store" {
+		t.Errorf("requests = %v, want the step's sent to --api-base-url", paths)
+	}
+}
+
+func TestBatchRun_RefusesLongRunningSteps(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var calls atomic.Int32
+
+	setupMockAPIClient(t, batchHandler(&calls))
+
+	// Leading flags must not hide the command, and a batch can't run itself.
+	path := writeBatchFile(t, "")
+	steps := `{"args":["--json","proxy","--listen","127.0.0.1:0"]}
+{"args":["--store","test","serve","--socket","` + filepath.Join(t.TempDir(), "nube.sock") + `"]}
+{"args":["--json","sync","stock","--source","file","--from","stock.csv","--watch"]}
+{"args":["--no-input","batch","run","` + path + `"]}
+`
+
+	if err := os.WriteFile(path, []byte(steps), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := runBatchJSON(t, []string{"batch", "run", path, "--json", "--continue-on-error"})
+	if ExitCode(err) != ExitUsage {
+		t.Fatalf("exit code = %d, want %d", ExitCode(err), ExitUsage)
+	}
+
+	results, _ := got["steps"].([]any)
+	for i, r := range results {
+		if step, _ := r.(map[string]any); step["exit_code"] != float64(ExitUsage) {
+			t.Errorf("step %d = %v, want a usage error", i+1, step)
+		}
+	}
+
+	if len(results) != 4 || calls.Load() != 0 {
+		t.Errorf("steps = %d, API calls = %d", len(results), calls.Load())
+	}
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// BenchCmd runs commands several times and compares their wall time and
+// API traffic, e.g. the same export with different --per-page values.
+type BenchCmd struct {
+	Commands []string `help:"Command line to measure, e.g. \"product list --all\" (repeat to compare several)" name:"command" required:"" sep:"none"`
+	Runs     int      `help:"Times to run each command" name:"runs" default:"5"`
+}
+
+// benchRun is one run of a benchmarked command.
+type benchRun struct {
+	DurationMS  int64 `json:"duration_ms"`
+	Requests    int   `json:"requests"`
+	Pages       int   `json:"pages"`
+	Retries     int   `json:"retries"`
+	RateLimited int   `json:"rate_limited"`
+	Bytes       int64 `json:"bytes_received"`
+	ExitCode    int   `json:"exit_code"`
+}
+
+// benchResult sums up the runs of one command.
+type benchResult struct {
+	Command  string     `json:"command"`
+	Runs     []benchRun `json:"runs"`
+	MinMS    int64      `json:"min_ms"`
+	MedianMS int64      `json:"median_ms"`
+	MaxMS    int64      `json:"max_ms"`
+	Requests float64    `json:"avg_requests"`
+	Retries  float64    `json:"avg_retries"`
+	Failed   int        `json:"failed"`
+}
+
+func (c *BenchCmd) Run(ctx context.Context, flags *RootFlags) error {
+	if c.Runs < 1 {
+		return usagef("--runs must be at least 1")
+	}
+
+	commands := make([][]string, len(c.Commands))
+
+	for i, line := range c.Commands {
+		args, err := splitCommandLine(line)
+		if err != nil {
+			return newUsageError(err)
+		}
+
+		if len(args) == 0 {
+			return usagef("--command is empty")
+		}
+
+		commands[i] = args
+	}
+
+	u := ui.FromContext(ctx)
+	results := make([]benchResult, len(commands))
+	failed := 0
+
+	for i, args := range commands {
+		results[i].Command = c.Commands[i]
+
+		for run := range c.Runs {
+			u.Err().Printf("%s: run %d/%d", c.Commands[i], run+1, c.Runs)
+
+			r, err := benchOnce(ctx, flags, args)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			if err != nil {
+				results[i].Failed++
+				failed++
+			}
+
+			results[i].Runs = append(results[i].Runs, r)
+		}
+
+		results[i].summarize()
+	}
+
+	if err := writeBench(ctx, results); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d runs failed; run the command alone to see why", failed, len(commands)*c.Runs)
+	}
+
+	return nil
+}
+
+// benchOnce runs args nested, with a recorder counting its API traffic.
+// Output is discarded: only the cost of producing it is measured.
+func benchOnce(ctx context.Context, flags *RootFlags, args []string) (benchRun, error) {
+	rec := &api.Recorder{}
+	start := time.Now()
+
+	err := execute(api.WithRecorder(withNested(ctx), rec), subcommandArgs(flags, args), io.Discard, io.Discard)
+	snap := rec.Snapshot()
+
+	return benchRun{
+		DurationMS:  time.Since(start).Milliseconds(),
+		Requests:    snap.Requests,
+		Pages:       snap.Pages,
+		Retries:     snap.Retries,
+		RateLimited: snap.RateLimited,
+		Bytes:       snap.BytesReceived,
+		ExitCode:    ExitCode(err),
+	}, err
+}
+
+func (r *benchResult) summarize() {
+	durations := make([]int64, len(r.Runs))
+
+	for i, run := range r.Runs {
+		durations[i] = run.DurationMS
+		r.Requests += float64(run.Requests)
+		r.Retries += float64(run.Retries)
+	}
+
+	slices.Sort(durations)
+
+	n := len(durations)
+	r.MinMS, r.MaxMS = durations[0], durations[n-1]
+	r.MedianMS = durations[n/2]
+
+	if n%2 == 0 {
+		r.MedianMS = (durations[n/2-1] + durations[n/2]) / 2
+	}
+
+	r.Requests /= float64(n)
+	r.Retries /= float64(n)
+}
+
+func writeBench(ctx context.Context, results []benchResult) error {
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), results)
+	}
+
+	w, done := tableWriter(ctx)
+	defer done()
+
+	_, _ = fmt.Fprintln(w, "COMMAND\tRUNS\tMIN\tMEDIAN\tMAX\tREQUESTS\tRETRIES\tFAILED")
+
+	for _, r := range results {
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%.1f\t%.1f\t%d\n", r.Command, len(r.Runs),
+			benchDuration(r.MinMS), benchDuration(r.MedianMS), benchDuration(r.MaxMS), r.Requests, r.Retries, r.Failed)
+	}
+
+	return nil
+}
+
+func benchDuration(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
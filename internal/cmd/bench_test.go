@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestBench(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var calls atomic.Int32
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/123/products/404" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":404,"message":"Not Found"}`))
+
+			return
+		}
+
+		calls.Add(1)
+		_, _ = w.Write([]byte(`[{"id":1}]`))
+	}))
+
+	_ = captureStderr(t)
+	out := captureStdout(t)
+
+	if err := Execute([]string{"--json", "bench", "--command", "product list --page 1", "--runs", "3"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var got []benchResult
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, out.String())
+	}
+
+	if len(got) != 1 || len(got[0].Runs) != 3 || got[0].Failed != 0 || got[0].Requests*3 != float64(calls.Load()) {
+		t.Errorf("results = %+v, calls = %d", got, calls.Load())
+	}
+
+	out = captureStdout(t)
+
+	err := Execute([]string{"bench", "--command", "product get 404", "--command", "product list --page 1", "--runs", "1"})
+	if ExitCode(err) != ExitError {
+		t.Errorf("failing run: err = %v, want exit 1", err)
+	}
+
+	if out.String() == "" {
+		t.Error("table not printed when a run failed")
+	}
+}
+
+func TestBenchResult_Summarize(t *testing.T) {
+	t.Parallel()
+
+	r := benchResult{Runs: []benchRun{{DurationMS: 40, Requests: 2}, {DurationMS: 10, Requests: 4, Retries: 1}, {DurationMS: 20}, {DurationMS: 30}}}
+	r.summarize()
+
+	if r.MinMS != 10 || r.MaxMS != 40 || r.MedianMS != 25 || r.Requests != 1.5 || r.Retries != 0.25 {
+		t.Errorf("summary = %+v", r)
+	}
+}
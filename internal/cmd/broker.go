@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/oauth"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// BrokerCmd groups commands for running your own OAuth broker.
+type BrokerCmd struct {
+	Serve BrokerServeCmd `cmd:"" help:"Run an OAuth broker for nube login --broker-url with your app's credentials"`
+}
+
+// BrokerServeCmd serves the protocol of nube's hosted broker, so a team can
+// keep its app's client secret on its own server and log in through it.
+type BrokerServeCmd struct {
+	ClientID     string `help:"App client ID (default: the saved OAuth client)" name:"client-id" env:"NUBE_BROKER_CLIENT_ID"`
+	ClientSecret string `help:"App client secret (default: the saved OAuth client)" name:"client-secret" env:"NUBE_BROKER_CLIENT_SECRET"`
+	Listen       string `help:"Address to listen on" name:"listen" default:":8787"`
+	TLSCert      string `help:"TLS certificate file, to serve HTTPS" name:"tls-cert" type:"path"`
+	TLSKey       string `help:"TLS key file for --tls-cert" name:"tls-key" type:"path"`
+}
+
+func (c *BrokerServeCmd) Run(ctx context.Context) error {
+	u := ui.FromContext(ctx)
+
+	if err := refuseNested(ctx, "broker serve"); err != nil {
+		return err
+	}
+
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return usagef("--tls-cert and --tls-key go together")
+	}
+
+	clientID, clientSecret, err := c.credentials()
+	if err != nil {
+		return err
+	}
+
+	ln, err := (&net.ListenConfig{}).Listen(ctx, "tcp", c.Listen)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", c.Listen, err)
+	}
+
+	srv := &http.Server{
+		Handler:           oauth.NewBroker(clientID, clientSecret),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+
+	scheme := "http"
+	if c.TLSCert != "" {
+		scheme = "https"
+	}
+
+	addr := scheme + "://" + ln.Addr().String()
+	if outfmt.IsJSON(ctx) {
+		_ = outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"listening": addr, "client_id": clientID})
+	} else if u != nil {
+		u.Err().Printf("broker listening on %s; the app's redirect URL must be this server's public URL + /callback", addr)
+	}
+
+	if c.TLSCert != "" {
+		err = srv.ServeTLS(ln, c.TLSCert, c.TLSKey)
+	} else {
+		err = srv.Serve(ln)
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve broker: %w", err)
+	}
+
+	return nil
+}
+
+// credentials returns the app's client ID and secret from the flags, or
+// the saved OAuth client when neither flag is given.
+func (c *BrokerServeCmd) credentials() (string, string, error) {
+	switch {
+	case c.ClientID != "" && c.ClientSecret != "":
+		return c.ClientID, c.ClientSecret, nil
+	case c.ClientID != "" || c.ClientSecret != "":
+		return "", "", usagef("--client-id and --client-secret go together")
+	}
+
+	client, err := credstore.GetOAuthClient("default")
+	if err != nil {
+		return "", "", err
+	}
+
+	return client.ClientID, client.ClientSecret, nil
+}
@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// cacheSearchFields are the fields cache query --q looks in, as dotted paths
+// through objects and lists. Resources missing here are searched in every
+// string field.
+var cacheSearchFields = map[string][]string{
+	"products":   {"name", "handle", "tags", "variants.sku", "variants.barcode"},
+	"categories": {"name", "handle"},
+	"coupons":    {"code"},
+	"webhooks":   {"event", "url"},
+}
+
+// cacheColumns are the default table columns of cache query per resource.
+var cacheColumns = map[string][]string{
+	"products":   {"id", "name", "handle", "sku", "price", "stock"},
+	"categories": {"id", "name", "handle", "parent"},
+	"coupons":    {"id", "code", "type", "value", "valid"},
+	"scripts":    {"id", "name", "event", "location"},
+	"webhooks":   {"id", "event", "url"},
+}
+
+// CacheCmd groups commands over the local copy of store resources kept for
+// lookups while the API is down or rate-limited.
+type CacheCmd struct {
+	Refresh CacheRefreshCmd `cmd:"" help:"Download resources into the local cache"`
+	Query   CacheQueryCmd   `cmd:"" help:"Search the local cache or a snapshot file without calling the API"`
+}
+
+type CacheRefreshCmd struct {
+	Resources string `help:"Comma-separated resources to cache (categories,coupons,products,scripts,webhooks)" default:"products,categories"`
+}
+
+func (c *CacheRefreshCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	names, err := parseSnapshotResources(c.Resources)
+	if err != nil {
+		return err
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	snap, err := captureSnapshot(ctx, client, names)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[string]int, len(names))
+
+	// One file per resource, so refreshing some keeps the others.
+	for _, n := range names {
+		path, err := cachePath(client.StoreID(), n)
+		if err != nil {
+			return err
+		}
+
+		entry := storeSnapshot{
+			Store:     snap.Store,
+			CreatedAt: snap.CreatedAt,
+			Resources: map[string][]map[string]any{n: snap.Resources[n]},
+		}
+		if err := writeCacheFile(path, entry); err != nil {
+			return err
+		}
+
+		counts[n] = len(snap.Resources[n])
+	}
+
+	return writeResult(ctx, u, kv("resources", counts), kv("fetched_at", snap.CreatedAt))
+}
+
+type CacheQueryCmd struct {
+	ColumnsFlags `embed:""`
+
+	Resource string `arg:"" enum:"categories,coupons,products,scripts,webhooks" help:"Resource to search: categories, coupons, products, scripts or webhooks"`
+	Query    string `help:"Text to look for, case-insensitive (e.g. a name, handle or SKU for products)" short:"q" name:"q"`
+	File     string `help:"Search this snapshot file (from 'nube snapshot create') instead of the cache" name:"file" type:"path"`
+	Limit    int    `help:"Show at most this many matches (0 for all)" name:"limit" default:"50"`
+}
+
+func (c *CacheQueryCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	path := c.File
+	if path == "" {
+		client, err := newAPIClient(flags)
+		if err != nil {
+			return err
+		}
+
+		if path, err = cachePath(client.StoreID(), c.Resource); err != nil {
+			return err
+		}
+	}
+
+	snap, err := readCacheFile(path)
+	if errors.Is(err, os.ErrNotExist) && c.File == "" {
+		return usagef("no cached %s for this store; run 'nube cache refresh --resources %s' while the API is reachable", c.Resource, c.Resource)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	items, ok := snap.Resources[c.Resource]
+	if !ok {
+		return usagef("%s has no %s", path, c.Resource)
+	}
+
+	matches := make([]map[string]any, 0, min(len(items), max(c.Limit, 0)))
+	total := 0
+
+	for _, item := range items {
+		if !cacheMatch(item, c.Resource, c.Query) {
+			continue
+		}
+
+		total++
+
+		if c.Limit <= 0 || len(matches) < c.Limit {
+			matches = append(matches, item)
+		}
+	}
+
+	out := map[string]any{
+		"offline":    true,
+		"source":     path,
+		"store":      snap.Store,
+		"fetched_at": snap.CreatedAt,
+		"total":      total,
+		"items":      matches,
+	}
+
+	// Always say where the answer came from: it may no longer be true.
+	if fetched, err := time.Parse(time.RFC3339, snap.CreatedAt); err == nil {
+		age := time.Since(fetched)
+		out["age_seconds"] = int(age.Seconds())
+
+		u.Err().Printf("offline: %s as of %s (%s ago), may be stale", c.Resource, fetched.Local().Format(time.DateTime), age.Round(time.Minute))
+	} else {
+		u.Err().Printf("offline: %s from %s, of unknown age, may be stale", c.Resource, path)
+	}
+
+	if total > len(matches) {
+		u.Err().Printf("showing %d of %d matches (--limit)", len(matches), total)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), out)
+	}
+
+	cols, err := c.pick([]column{
+		textColumn("id", "id"),
+		i18nColumn("name", "name"),
+		i18nColumn("handle", "handle"),
+		{name: "sku", value: func(p map[string]any) string { return firstVariantField(p, "sku") }},
+		{name: "price", value: firstVariantPrice},
+		{name: "stock", value: totalStock},
+	}, cacheColumns[c.Resource]...)
+	if err != nil {
+		return err
+	}
+
+	writeItemsTable(ctx, cols, matches)
+
+	return nil
+}
+
+// cacheMatch reports whether item contains q in one of the resource's search
+// fields, ignoring case. An empty q matches everything.
+func cacheMatch(item map[string]any, resource, q string) bool {
+	if q == "" {
+		return true
+	}
+
+	q = strings.ToLower(q)
+
+	fields, ok := cacheSearchFields[resource]
+	if !ok {
+		return containsText(item, q)
+	}
+
+	for _, f := range fields {
+		if containsText(lookupPath(item, strings.Split(f, ".")), q) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lookupPath collects the values at a dotted path, fanning out over lists.
+func lookupPath(v any, segs []string) []any {
+	if len(segs) == 0 {
+		return []any{v}
+	}
+
+	switch x := v.(type) {
+	case map[string]any:
+		return lookupPath(x[segs[0]], segs[1:])
+	case []any:
+		var out []any
+		for _, el := range x {
+			out = append(out, lookupPath(el, segs)...)
+		}
+
+		return out
+	default:
+		return nil
+	}
+}
+
+// containsText reports whether any string in v contains lower-cased q.
+func containsText(v any, q string) bool {
+	switch x := v.(type) {
+	case string:
+		return strings.Contains(strings.ToLower(x), q)
+	case map[string]any:
+		for _, el := range x {
+			if containsText(el, q) {
+				return true
+			}
+		}
+	case []any:
+		for _, el := range x {
+			if containsText(el, q) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func cachePath(storeID, resource string) (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "cache", storeID, resource+".json"), nil
+}
+
+func readCacheFile(path string) (storeSnapshot, error) {
+	b, err := os.ReadFile(path) //nolint:gosec // data dir or user-supplied snapshot
+	if err != nil {
+		return storeSnapshot{}, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	var snap storeSnapshot
+	if err := json.Unmarshal(b, &snap); err != nil || snap.Resources == nil {
+		return storeSnapshot{}, usagef("%s is not a snapshot or cache file", path)
+	}
+
+	return snap, nil
+}
+
+// writeCacheFile replaces path through a temporary file, so a query never
+// reads a half-written cache.
+func writeCacheFile(path string, snap storeSnapshot) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+
+	b, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("encode cache: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+
+	return nil
+}
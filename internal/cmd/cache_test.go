@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func setupCacheAPI(t *testing.T) *int {
+	t.Helper()
+
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	calls := 0
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		switch strings.TrimPrefix(r.URL.Path, "/v1/123/") {
+		case "products":
+			_, _ = w.Write([]byte(`[
+				{"id":2,"name":{"es":"Zapato de cuero"},"handle":{"es":"zapato-cuero"},"variants":[{"sku":"ZAP-01","price":"100.00","stock":3}]},
+				{"id":1,"name":{"es":"Remera"},"handle":{"es":"remera"},"variants":[{"sku":"REM-01","price":"50.00","stock":1}]}
+			]`))
+		case "categories":
+			_, _ = w.Write([]byte(`[{"id":10,"name":{"es":"Calzado"}}]`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+
+	return &calls
+}
+
+func TestCache_RefreshQuery(t *testing.T) {
+	calls := setupCacheAPI(t)
+
+	_ = captureStdout(t)
+	if err := Execute([]string{"cache", "refresh"}); err != nil {
+		t.Fatalf("refresh error = %v", err)
+	}
+
+	path, err := cachePath("123", "products")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("products cache not written: %v", err)
+	}
+
+	*calls = 0
+
+	tests := []struct {
+		name    string
+		q       string
+		wantIDs []string
+	}{
+		{name: "name", q: "ZAPATO", wantIDs: []string{"2"}},
+		{name: "variant sku", q: "rem-", wantIDs: []string{"1"}},
+		{name: "everything", q: "", wantIDs: []string{"1", "2"}},
+		{name: "no match", q: "campera", wantIDs: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := captureStdout(t)
+			errOut := captureStderr(t)
+
+			if err := Execute([]string{"--json", "cache", "query", "products", "-q", tt.q}); err != nil {
+				t.Fatalf("query error = %v", err)
+			}
+
+			var res struct {
+				Offline bool             `json:"offline"`
+				Items   []map[string]any `json:"items"`
+			}
+			if err := json.Unmarshal(out.Bytes(), &res); err != nil {
+				t.Fatalf("unmarshal: %v\n%s", err, out.String())
+			}
+
+			ids := []string{}
+			for _, item := range res.Items {
+				ids = append(ids, jsonStr(item, "id"))
+			}
+
+			if !res.Offline || strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") {
+				t.Errorf("offline=%v ids=%v, want true %v", res.Offline, ids, tt.wantIDs)
+			}
+
+			if !strings.Contains(errOut.String(), "may be stale") {
+				t.Errorf("stderr = %q, want a staleness note", errOut.String())
+			}
+		})
+	}
+
+	if *calls != 0 {
+		t.Errorf("query made %d API calls, want none", *calls)
+	}
+}
+
+func TestCache_QueryMissing(t *testing.T) {
+	setupCacheAPI(t)
+
+	_ = captureStdout(t)
+	_ = captureStderr(t)
+
+	if err := Execute([]string{"cache", "query", "coupons"}); ExitCode(err) != ExitUsage {
+		t.Errorf("exit code = %d, want %d", ExitCode(err), ExitUsage)
+	}
+}
+
+func TestCache_QuerySnapshotFile(t *testing.T) {
+	setupConfigDir(t)
+
+	file := filepath.Join(t.TempDir(), "baseline.json")
+	snap := storeSnapshot{
+		Store:     "123",
+		CreatedAt: "2024-06-01T12:00:00Z",
+		Resources: map[string][]map[string]any{
+			"webhooks": {{"id": 1, "event": "order/paid", "url": "https://a"}, {"id": 2, "event": "product/updated", "url": "https://b"}},
+		},
+	}
+
+	if err := writeJSONFile(file, snap); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t)
+	_ = captureStderr(t)
+
+	if err := Execute([]string{"--plain", "cache", "query", "webhooks", "--file", file, "-q", "order/"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if got := out.String(); !strings.Contains(got, "order/paid") || strings.Contains(got, "product/updated") {
+		t.Errorf("output = %q", got)
+	}
+
+	if err := Execute([]string{"cache", "query", "products", "--file", file}); ExitCode(err) != ExitUsage {
+		t.Errorf("snapshot without products: exit code = %d, want %d", ExitCode(err), ExitUsage)
+	}
+}
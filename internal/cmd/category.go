@@ -2,10 +2,9 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 	"net/url"
-	"os"
+	"strconv"
 
 	"github.com/gberlati/nube-cli/internal/api"
 	"github.com/gberlati/nube-cli/internal/outfmt"
@@ -14,23 +13,25 @@ import (
 
 // CategoryCmd groups category-related commands.
 type CategoryCmd struct {
-	List CategoryListCmd `cmd:"" help:"List categories"`
-	Get  CategoryGetCmd  `cmd:"" help:"Get a category by ID"`
+	List  CategoryListCmd  `cmd:"" help:"List categories"`
+	Get   CategoryGetCmd   `cmd:"" help:"Get a category by ID"`
+	Diff  CategoryDiffCmd  `cmd:"" help:"Compare a local JSON file against a category"`
+	Edit  CategoryEditCmd  `cmd:"" help:"Edit a category in $EDITOR and save the changes"`
+	Move  CategoryMoveCmd  `cmd:"" help:"Move a category under another parent"`
+	Merge CategoryMergeCmd `cmd:"" help:"Move a category's products and subcategories into another and delete it"`
 }
 
 // CategoryListCmd lists categories with pagination and filters.
 type CategoryListCmd struct {
 	PaginationFlags `embed:""`
-
-	SinceID    string `help:"Return categories after this ID" name:"since-id"`
-	Language   string `help:"Filter by language code" name:"language"`
-	Handle     string `help:"Filter by URL handle" name:"handle"`
-	ParentID   string `help:"Filter by parent category ID" name:"parent-id"`
-	CreatedMin string `help:"Created after (ISO 8601)" name:"created-at-min"`
-	CreatedMax string `help:"Created before (ISO 8601)" name:"created-at-max"`
-	UpdatedMin string `help:"Updated after (ISO 8601)" name:"updated-at-min"`
-	UpdatedMax string `help:"Updated before (ISO 8601)" name:"updated-at-max"`
-	Fields     string `help:"Comma-separated fields to return from API" name:"fields"`
+	DateFilterFlags `embed:""`
+	ColumnsFlags    `embed:""`
+
+	SinceID  string `help:"Return categories after this ID" name:"since-id"`
+	Language string `help:"Filter by language code" name:"language"`
+	Handle   string `help:"Filter by URL handle" name:"handle"`
+	ParentID string `help:"Filter by parent category ID" name:"parent-id"`
+	Fields   string `help:"Comma-separated fields to return from API" name:"fields"`
 }
 
 func (c *CategoryListCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -47,12 +48,17 @@ func (c *CategoryListCmd) Run(ctx context.Context, flags *RootFlags) error {
 	addQueryParam(q, "language", c.Language)
 	addQueryParam(q, "handle", c.Handle)
 	addQueryParam(q, "parent_id", c.ParentID)
-	addQueryParam(q, "created_at_min", c.CreatedMin)
-	addQueryParam(q, "created_at_max", c.CreatedMax)
-	addQueryParam(q, "updated_at_min", c.UpdatedMin)
-	addQueryParam(q, "updated_at_max", c.UpdatedMax)
 	addQueryParam(q, "fields", c.Fields)
 
+	zone := newZoneResolver(flags, client)
+	if err := c.resolve(ctx, q, zone); err != nil {
+		return err
+	}
+
+	if q, err = c.resume("categories", q); err != nil {
+		return err
+	}
+
 	var items []map[string]any
 
 	if c.WantsAllPages() {
@@ -70,24 +76,22 @@ func (c *CategoryListCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, items)
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), items)
 	}
 
-	w, done := tableWriter(ctx)
-	defer done()
-
-	_, _ = fmt.Fprintln(w, "ID\tNAME\tHANDLE\tPARENT\tSUBCATEGORIES")
-
-	for _, cat := range items {
-		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", //nolint:gosec // tabwriter, not HTML
-			jsonStr(cat, "id"),
-			extractI18n(cat, "name"),
-			extractI18n(cat, "handle"),
-			jsonStr(cat, "parent"),
-			countSubcategories(cat),
-		)
+	cols, err := c.pick([]column{
+		textColumn("id", "id"),
+		i18nColumn("name", "name"),
+		i18nColumn("handle", "handle"),
+		textColumn("parent", "parent"),
+		{name: "subcategories", value: func(cat map[string]any) string { return strconv.Itoa(countSubcategories(cat)) }},
+	}, "id", "name", "handle", "parent", "subcategories")
+	if err != nil {
+		return err
 	}
 
+	writeItemsTable(ctx, cols, items)
+
 	_ = u
 
 	return nil
@@ -121,7 +125,12 @@ func (c *CategoryGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, data)
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), data)
+	}
+
+	times, err := newTimeFormatter(ctx, flags, newZoneResolver(flags, client))
+	if err != nil {
+		return err
 	}
 
 	return writeResult(ctx, u,
@@ -130,8 +139,8 @@ func (c *CategoryGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 		kv("handle", extractI18n(data, "handle")),
 		kv("parent", jsonStr(data, "parent")),
 		kv("subcategories", countSubcategories(data)),
-		kv("created_at", jsonStr(data, "created_at")),
-		kv("updated_at", jsonStr(data, "updated_at")),
+		kv("created_at", times.format(jsonStr(data, "created_at"))),
+		kv("updated_at", times.format(jsonStr(data, "updated_at"))),
 	)
 }
 
@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// --- Move ---
+
+// CategoryMoveCmd changes a category's parent, taking its subcategories
+// along.
+type CategoryMoveCmd struct {
+	CategoryID string `arg:"" name:"category-id" help:"Category ID"`
+	Parent     string `help:"New parent category ID, or 0 for the top level" name:"parent" required:""`
+}
+
+func (c *CategoryMoveCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	if c.Parent == c.CategoryID {
+		return usagef("a category cannot be its own parent")
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	parents, err := categoryParents(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	from, ok := parents[c.CategoryID]
+	if !ok {
+		return &ExitErr{Code: ExitNotFound, Err: fmt.Errorf("category %s not found", c.CategoryID)}
+	}
+
+	var parent any
+
+	if c.Parent != "0" {
+		if _, ok := parents[c.Parent]; !ok {
+			return &ExitErr{Code: ExitNotFound, Err: fmt.Errorf("parent category %s not found", c.Parent)}
+		}
+
+		if isDescendant(parents, c.Parent, c.CategoryID) {
+			return usagef("category %s is inside %s; moving %s under it would make a loop", c.Parent, c.CategoryID, c.CategoryID)
+		}
+
+		id, err := strconv.ParseInt(c.Parent, 10, 64)
+		if err != nil {
+			return usagef("--parent %q: want a category ID", c.Parent)
+		}
+
+		parent = id
+	}
+
+	if flags.DryRun {
+		return writeResult(ctx, u,
+			kv("dry_run", true),
+			kv("category_id", c.CategoryID),
+			kv("from_parent", orTopLevel(from)),
+			kv("to_parent", orTopLevel(c.Parent)),
+		)
+	}
+
+	if err := updateCategory(ctx, client, c.CategoryID, map[string]any{"parent": parent}); err != nil {
+		return err
+	}
+
+	return writeResult(ctx, u,
+		kv("moved", true),
+		kv("category_id", c.CategoryID),
+		kv("from_parent", orTopLevel(from)),
+		kv("to_parent", orTopLevel(c.Parent)),
+	)
+}
+
+// --- Merge ---
+
+// CategoryMergeCmd moves everything out of one category into another and
+// deletes the emptied one.
+type CategoryMergeCmd struct {
+	From     string `arg:"" name:"from" help:"Category to merge and delete"`
+	Into     string `arg:"" name:"into" help:"Category that receives its products and subcategories"`
+	Parallel int    `help:"Products updated in parallel" name:"parallel" default:"4"`
+}
+
+func (c *CategoryMergeCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	if c.From == c.Into {
+		return usagef("cannot merge a category into itself")
+	}
+
+	if c.Parallel < 1 {
+		return usagef("--parallel must be at least 1")
+	}
+
+	into, err := strconv.ParseInt(c.Into, 10, 64)
+	if err != nil {
+		return usagef("%q: want a category ID", c.Into)
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	parents, err := categoryParents(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range []string{c.From, c.Into} {
+		if _, ok := parents[id]; !ok {
+			return &ExitErr{Code: ExitNotFound, Err: fmt.Errorf("category %s not found", id)}
+		}
+	}
+
+	if isDescendant(parents, c.Into, c.From) {
+		return usagef("category %s is inside %s; merge it the other way round or move it out first", c.Into, c.From)
+	}
+
+	var children []string
+
+	for id, parent := range parents {
+		if parent == c.From {
+			children = append(children, id)
+		}
+	}
+
+	slices.Sort(children)
+
+	listed, err := api.CollectAllPages(ctx, client, "products", url.Values{"category_id": {c.From}}, decodeList)
+	if err != nil {
+		return err
+	}
+
+	// The filter may also match products of subcategories, which stay
+	// where they are.
+	products := slices.DeleteFunc(listed, func(p map[string]any) bool {
+		return !slices.Contains(productCategoryIDs(p), c.From)
+	})
+
+	productIDs := make([]string, len(products))
+	for i, p := range products {
+		productIDs[i] = jsonStr(p, "id")
+	}
+
+	if flags.DryRun {
+		return writeResult(ctx, u,
+			kv("dry_run", true),
+			kv("from", c.From),
+			kv("into", c.Into),
+			kv("products", productIDs),
+			kv("subcategories", children),
+		)
+	}
+
+	action := fmt.Sprintf("move %d products and %d subcategories from category %s to %s, then delete %s",
+		len(products), len(children), c.From, c.Into, c.From)
+	if err := confirmBulk(flags, action, productIDs, activeStoreName(flags, client)); err != nil {
+		return err
+	}
+
+	pool := api.NewPool(api.WithWorkers(c.Parallel))
+	jobs := make([]api.Job, len(products))
+
+	for i, p := range products {
+		ids := replaceCategory(productCategoryIDs(p), c.From, c.Into)
+
+		jobs[i] = func(ctx context.Context) error {
+			return setProductCategories(ctx, client, jsonStr(p, "id"), ids)
+		}
+	}
+
+	if err := errors.Join(pool.Run(ctx, jobs)...); err != nil {
+		return fmt.Errorf("category %s kept, products not all moved: %w", c.From, err)
+	}
+
+	for _, child := range children {
+		if err := updateCategory(ctx, client, child, map[string]any{"parent": into}); err != nil {
+			return fmt.Errorf("category %s kept, subcategory %s not moved: %w", c.From, child, err)
+		}
+	}
+
+	resp, err := client.Delete(ctx, "categories/"+c.From) //nolint:bodyclose // decodeOptionalJSON closes body
+	if err != nil {
+		return err
+	}
+
+	if _, err := decodeOptionalJSON(resp); err != nil {
+		return err
+	}
+
+	return writeResult(ctx, u,
+		kv("merged", true),
+		kv("from", c.From),
+		kv("into", c.Into),
+		kv("products", len(products)),
+		kv("subcategories", len(children)),
+	)
+}
+
+// categoryParents maps every category ID to its parent ID ("" at the top
+// level).
+func categoryParents(ctx context.Context, client *api.Client) (map[string]string, error) {
+	cats, err := api.CollectAllPages(ctx, client, "categories", url.Values{"fields": {"id,parent"}}, decodeList)
+	if err != nil {
+		return nil, err
+	}
+
+	parents := make(map[string]string, len(cats))
+
+	for _, cat := range cats {
+		parent := jsonStr(cat, "parent")
+		if parent == "0" {
+			parent = ""
+		}
+
+		parents[jsonStr(cat, "id")] = parent
+	}
+
+	return parents, nil
+}
+
+// isDescendant reports whether id sits anywhere below ancestor.
+func isDescendant(parents map[string]string, id, ancestor string) bool {
+	seen := map[string]bool{}
+
+	for p := parents[id]; p != "" && !seen[p]; p = parents[p] {
+		if p == ancestor {
+			return true
+		}
+
+		seen[p] = true
+	}
+
+	return false
+}
+
+func orTopLevel(id string) string {
+	if id == "" || id == "0" {
+		return "(top level)"
+	}
+
+	return id
+}
+
+func updateCategory(ctx context.Context, client *api.Client, id string, update map[string]any) error {
+	body, err := jsonBody(update)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Put(ctx, "categories/"+id, body) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return err
+	}
+
+	_, err = api.DecodeResponse[map[string]any](resp)
+
+	return err
+}
+
+// replaceCategory swaps from for into in ids, without duplicating into.
+func replaceCategory(ids []string, from, into string) []string {
+	out := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		if id != from && id != into {
+			out = append(out, id)
+		}
+	}
+
+	return append(out, into)
+}
+
+// setProductCategories replaces a product's categories with ids.
+func setProductCategories(ctx context.Context, client *api.Client, productID string, ids []string) error {
+	nums := make([]int64, 0, len(ids))
+
+	for _, id := range ids {
+		n, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return usagef("category %q: want a numeric ID", id)
+		}
+
+		nums = append(nums, n)
+	}
+
+	body, err := jsonBody(map[string]any{"categories": nums})
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Put(ctx, "products/"+productID, body) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return fmt.Errorf("product %s: %w", productID, err)
+	}
+
+	if _, err := api.DecodeResponse[map[string]any](resp); err != nil {
+		return fmt.Errorf("product %s: %w", productID, err)
+	}
+
+	return nil
+}
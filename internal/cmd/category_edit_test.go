@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// categoryTreeServer serves categories 1 > 2 > 3 and 4, and products 100
+// (in 2) and 101 (in 3, listed under 2 as well), recording writes.
+func categoryTreeServer(t *testing.T, writes *[]string) http.Handler {
+	t.Helper()
+
+	var mu sync.Mutex
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			body, _ := io.ReadAll(r.Body)
+
+			mu.Lock()
+			*writes = append(*writes, r.Method+" "+r.URL.Path+" "+string(body))
+			mu.Unlock()
+
+			_, _ = w.Write([]byte(`{}`))
+
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/123/categories":
+			_, _ = w.Write([]byte(`[{"id":1,"parent":null},{"id":2,"parent":1},{"id":3,"parent":2},{"id":4,"parent":null}]`))
+		case "/v1/123/products":
+			_, _ = w.Write([]byte(`[{"id":100,"categories":[{"id":2},{"id":4}]},{"id":101,"categories":[{"id":3}]}]`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	})
+}
+
+func TestCategoryMove(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		code  int
+		write string
+	}{
+		{"to top level", []string{"2", "--parent", "0"}, ExitOK, `PUT /v1/123/categories/2 {"parent":null}`},
+		{"under other", []string{"2", "--parent", "4"}, ExitOK, `PUT /v1/123/categories/2 {"parent":4}`},
+		{"into own child", []string{"1", "--parent", "3"}, ExitUsage, ""},
+		{"missing parent", []string{"2", "--parent", "9"}, ExitNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var writes []string
+
+			setupMockAPIClient(t, categoryTreeServer(t, &writes))
+			_ = captureStdout(t)
+			_ = captureStderr(t)
+
+			err := Execute(append([]string{"category", "move"}, tt.args...))
+			if ExitCode(err) != tt.code {
+				t.Fatalf("ExitCode = %d, want %d (err %v)", ExitCode(err), tt.code, err)
+			}
+
+			var want []string
+			if tt.write != "" {
+				want = []string{tt.write}
+			}
+
+			if !slices.Equal(writes, want) {
+				t.Errorf("writes = %q, want %q", writes, want)
+			}
+		})
+	}
+}
+
+func TestCategoryMerge(t *testing.T) {
+	var writes []string
+
+	setupMockAPIClient(t, categoryTreeServer(t, &writes))
+	_ = captureStdout(t)
+
+	if err := Execute([]string{"category", "merge", "2", "4", "--force"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	want := []string{
+		`PUT /v1/123/products/100 {"categories":[4]}`,
+		`PUT /v1/123/categories/3 {"parent":4}`,
+		`DELETE /v1/123/categories/2 `,
+	}
+	if !slices.Equal(writes, want) {
+		t.Errorf("writes = %q, want %q", writes, want)
+	}
+}
+
+func TestCategoryMerge_DryRun(t *testing.T) {
+	var writes []string
+
+	setupMockAPIClient(t, categoryTreeServer(t, &writes))
+	out := captureStdout(t)
+
+	if err := Execute([]string{"--json", "--dry-run", "category", "merge", "2", "4"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if len(writes) != 0 || len(got["products"].([]any)) != 1 || len(got["subcategories"].([]any)) != 1 {
+		t.Errorf("writes = %q, output = %v", writes, got)
+	}
+}
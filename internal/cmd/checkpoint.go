@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/checkpoint"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// CheckpointFlags adds --resume to bulk commands that record finished items
+// in a checkpoint file as they go.
+type CheckpointFlags struct {
+	Resume string `help:"Continue an interrupted run from its checkpoint file, skipping what it finished (pass the same filters)" name:"resume" type:"path"`
+}
+
+// resumed opens the --resume checkpoint of command, or returns nil without
+// --resume.
+func (f CheckpointFlags) resumed(command string) (*checkpoint.File, error) {
+	if f.Resume == "" {
+		return nil, nil
+	}
+
+	cp, err := checkpoint.Resume(f.Resume, command)
+	if errors.Is(err, checkpoint.ErrWrongCommand) || errors.Is(err, os.ErrNotExist) {
+		return nil, newUsageError(err)
+	}
+
+	return cp, err
+}
+
+// startCheckpoint creates a checkpoint for a new run of command and says
+// where it is, so the path is known if the run dies.
+func startCheckpoint(u *ui.UI, command string) (*checkpoint.File, error) {
+	path, err := checkpoint.DefaultPath(command, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	cp, err := checkpoint.Create(path, command)
+	if err != nil {
+		return nil, err
+	}
+
+	u.Err().Printf("progress is saved in %s", path)
+
+	return cp, nil
+}
+
+// markDone records key in cp. The item's change already happened, so a
+// failed write only costs redoing it on resume.
+func markDone(ctx context.Context, cp *checkpoint.File, key string) {
+	if err := cp.Mark(key); err != nil {
+		slog.WarnContext(ctx, "checkpoint not updated", "item", key, "err", err)
+	}
+}
+
+// finishCheckpoint deletes cp after a complete run and otherwise keeps it,
+// saying how to resume.
+func finishCheckpoint(ctx context.Context, u *ui.UI, cp *checkpoint.File, complete bool) {
+	if cp == nil {
+		return
+	}
+
+	if complete {
+		if err := cp.Remove(); err != nil {
+			slog.WarnContext(ctx, "checkpoint not removed", "err", err)
+		}
+
+		return
+	}
+
+	_ = cp.Close()
+
+	u.Err().Printf("%d items done; rerun with --resume %s to continue", cp.Len(), cp.Path())
+}
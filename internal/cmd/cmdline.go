@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var errUnterminatedQuote = errors.New("unterminated quote")
+
+// splitCommandLine splits a command string into arguments the way a POSIX
+// shell would for simple cases: whitespace separates words, single and double
+// quotes group, and backslash escapes the next character outside single quotes.
+// It does not expand variables or globs.
+func splitCommandLine(s string) ([]string, error) {
+	var (
+		args    []string
+		cur     strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				args = append(args, cur.String())
+				cur.Reset()
+
+				inWord = false
+			}
+		default:
+			cur.WriteRune(r)
+
+			inWord = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, errUnterminatedQuote
+	}
+
+	if inWord {
+		args = append(args, cur.String())
+	}
+
+	return args, nil
+}
+
+// subcommandArgs prefixes args with the parent invocation's scoping and
+// safety flags, so nested runs (batch steps, scheduled jobs) can't widen
+// what the parent was allowed to do.
+func subcommandArgs(flags *RootFlags, args []string) []string {
+	var out []string
+
+	if flags != nil {
+		if flags.Store != "" {
+			out = append(out, "--store", flags.Store)
+		}
+
+		if flags.EnableCommands != "" {
+			out = append(out, "--enable-commands", flags.EnableCommands)
+		}
+
+		if flags.ExpectStore != "" {
+			out = append(out, "--expect-store", flags.ExpectStore)
+		}
+
+		if flags.APIBaseURL != "" {
+			out = append(out, "--api-base-url", flags.APIBaseURL)
+		}
+
+		// --total-deadline needs no flag: the parent's deadline is on ctx.
+		if flags.Timeout > 0 {
+			out = append(out, "--timeout", flags.Timeout.String())
+		}
+
+		if flags.DryRun {
+			out = append(out, "--dry-run")
+		}
+
+		if flags.NoInput {
+			out = append(out, "--no-input")
+		}
+
+		if flags.NoJournal {
+			out = append(out, "--no-journal")
+		}
+
+		if flags.NoHistory {
+			out = append(out, "--no-history")
+		}
+
+		if flags.MockDir != "" {
+			out = append(out, "--mock-dir", flags.MockDir)
+		}
+
+		if flags.Record != "" {
+			out = append(out, "--record", flags.Record)
+		}
+	}
+
+	return append(out, args...)
+}
+
+type nestedCtxKey struct{}
+
+// withNested marks ctx as belonging to an invocation started by another
+// command rather than by the process itself.
+func withNested(ctx context.Context) context.Context {
+	return context.WithValue(ctx, nestedCtxKey{}, true)
+}
+
+func isNested(ctx context.Context) bool {
+	v, _ := ctx.Value(nestedCtxKey{}).(bool)
+
+	return v
+}
+
+// refuseNested keeps commands that run until interrupted out of batch steps
+// and scheduled jobs, which would otherwise never finish.
+func refuseNested(ctx context.Context, name string) error {
+	if isNested(ctx) {
+		return usagef("%s runs until interrupted and cannot be started by batch, schedule or run-scheduled", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{"simple", "product get 1", []string{"product", "get", "1"}, false},
+		{"extra spaces", "  order   list  ", []string{"order", "list"}, false},
+		{"double quotes", `order note set 1 "hello world"`, []string{"order", "note", "set", "1", "hello world"}, false},
+		{"single quotes", `x '$HOME "q"'`, []string{"x", `$HOME "q"`}, false},
+		{"escaped space", `a\ b c`, []string{"a b", "c"}, false},
+		{"empty quoted arg", `a ""`, []string{"a", ""}, false},
+		{"empty", "", nil, false},
+		{"unterminated", `a "b`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := splitCommandLine(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitCommandLine(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubcommandArgs(t *testing.T) {
+	t.Parallel()
+
+	flags := &RootFlags{Store: "shop", EnableCommands: "product", ExpectStore: "shop", APIBaseURL: "http://127.0.0.1:8765", Timeout: 90 * time.Second, DryRun: true, Force: true}
+	got := subcommandArgs(flags, []string{"product", "list"})
+	want := []string{"--store", "shop", "--enable-commands", "product", "--expect-store", "shop", "--api-base-url", "http://127.0.0.1:8765", "--timeout", "1m30s", "--dry-run", "product", "list"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("subcommandArgs() = %q, want %q", got, want)
+	}
+
+	if got := subcommandArgs(nil, []string{"version"}); !reflect.DeepEqual(got, []string{"version"}) {
+		t.Errorf("subcommandArgs(nil) = %q", got)
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// column is one column of a list table.
+type column struct {
+	name  string
+	value func(item map[string]any) string
+}
+
+// ColumnsFlags embeds --columns in list commands.
+type ColumnsFlags struct {
+	Columns []string `help:"Table columns to show, in order (e.g. id,name,price); any field of the items works" name:"columns" sep:","`
+}
+
+// pick returns the columns to print: the --columns names, or defaults. Names
+// are matched against the command's known columns first; anything else is
+// read from the items by dotted path.
+func (f ColumnsFlags) pick(known []column, defaults ...string) ([]column, error) {
+	names := defaults
+	if len(f.Columns) > 0 {
+		names = f.Columns
+	}
+
+	cols := make([]column, 0, len(names))
+
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			return nil, usagef("--columns: empty column name")
+		}
+
+		cols = append(cols, lookupColumn(known, name))
+	}
+
+	return cols, nil
+}
+
+func lookupColumn(known []column, name string) column {
+	for _, c := range known {
+		if c.name == name {
+			return c
+		}
+	}
+
+	return column{name: name, value: func(item map[string]any) string { return fieldValue(item, name) }}
+}
+
+// fieldValue renders the item field at a dotted path for a table cell.
+// Multilingual fields pick a translation unless the path names one
+// ("name" vs "name.pt"); nested objects and lists print as compact JSON.
+func fieldValue(item map[string]any, path string) string {
+	segs := strings.Split(path, ".")
+
+	cur := item
+	for _, seg := range segs[:len(segs)-1] {
+		next, ok := cur[seg].(map[string]any)
+		if !ok {
+			return ""
+		}
+
+		cur = next
+	}
+
+	key := segs[len(segs)-1]
+
+	switch v := cur[key].(type) {
+	case map[string]any:
+		if s := extractI18n(cur, key); s != "" {
+			return s
+		}
+
+		return compactJSON(v)
+	case []any:
+		return compactJSON(v)
+	default:
+		return jsonStr(cur, key)
+	}
+}
+
+func compactJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(b)
+}
+
+// writeItemsTable prints items as a table of cols, headed by the column
+// names in upper case.
+func writeItemsTable(ctx context.Context, cols []column, items []map[string]any) {
+	w, done := tableWriter(ctx)
+	defer done()
+
+	cells := make([]string, len(cols))
+
+	for i, c := range cols {
+		cells[i] = strings.ToUpper(c.name)
+	}
+
+	_, _ = fmt.Fprintln(w, strings.Join(cells, "\t"))
+
+	for _, item := range items {
+		for i, c := range cols {
+			cells[i] = c.value(item)
+		}
+
+		_, _ = fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+}
+
+// textColumn is a known column holding the item's key as text.
+func textColumn(name, key string) column {
+	return column{name: name, value: func(item map[string]any) string { return jsonStr(item, key) }}
+}
+
+// i18nColumn is a known column holding a translation of the item's key.
+func i18nColumn(name, key string) column {
+	return column{name: name, value: func(item map[string]any) string { return extractI18n(item, key) }}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestFieldValue(t *testing.T) {
+	t.Parallel()
+
+	item := map[string]any{
+		"id":       float64(7),
+		"name":     map[string]any{"es": "Remera", "pt": "Camiseta"},
+		"customer": map[string]any{"name": "Ana", "address": map[string]any{"city": "Rosario"}},
+		"tags":     []any{"a", "b"},
+		"attrs":    map[string]any{"size": 1},
+	}
+
+	tests := map[string]string{
+		"id":                    "7",
+		"name":                  "Remera",
+		"name.pt":               "Camiseta",
+		"customer.name":         "Ana",
+		"customer.address.city": "Rosario",
+		"tags":                  `["a","b"]`,
+		"attrs":                 `{"size":1}`,
+		"missing":               "",
+		"id.nested":             "",
+	}
+
+	for path, want := range tests {
+		if got := fieldValue(item, path); got != want {
+			t.Errorf("fieldValue(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestProductList_Columns(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/123/products" {
+			t.Errorf("unexpected request %s (no amount column, so no store lookup)", r.URL.Path)
+		}
+
+		_ = json.NewEncoder(w).Encode([]map[string]any{{
+			"id":   1,
+			"name": map[string]any{"es": "Remera", "pt": "Camiseta"},
+			"tags": "verano",
+			"variants": []any{
+				map[string]any{"sku": "REM-1", "stock": 3},
+				map[string]any{"sku": "REM-2", "stock": 4},
+			},
+		}})
+	}))
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"product", "list", "--page", "1", "--plain", "--columns", "sku,name.pt,stock,tags,id"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	want := "SKU\tNAME.PT\tSTOCK\tTAGS\tID\nREM-1\tCamiseta\t7\tverano\t1\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	if err := Execute([]string{"product", "list", "--page", "1", "--columns", "id,,name"}); ExitCode(err) != ExitUsage {
+		t.Errorf("empty column: ExitCode = %d, want %d", ExitCode(err), ExitUsage)
+	}
+}
+
+func TestTotalStock(t *testing.T) {
+	t.Parallel()
+
+	unlimited := map[string]any{"variants": []any{map[string]any{"stock": float64(2)}, map[string]any{"stock": nil}}}
+	if got := totalStock(unlimited); got != "unlimited" {
+		t.Errorf("totalStock = %q, want unlimited", got)
+	}
+
+	if got := totalStock(map[string]any{}); got != "0" {
+		t.Errorf("totalStock(no variants) = %q, want 0", got)
+	}
+}
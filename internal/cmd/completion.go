@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/alecthomas/kong"
+)
+
+// completionShells are the shells nube completion writes scripts for.
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// The scripts ask `nube __complete` for candidates, so they never go stale
+// as commands and flags change.
+const (
+	bashCompletion = `# bash completion for nube
+_nube() {
+  local IFS=$'\n'
+  COMPREPLY=($(nube __complete -- "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _nube nube
+`
+	zshCompletion = `#compdef nube
+_nube() {
+  local -a candidates
+  candidates=(${(f)"$(nube __complete -- "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+  compadd -a candidates
+}
+if [ "$funcstack[1]" = "_nube" ]; then
+  _nube "$@"
+else
+  compdef _nube nube
+fi
+`
+	fishCompletion = `# fish completion for nube
+complete -c nube -f -a '(nube __complete -- (commandline -opc)[2..-1] (commandline -ct) 2>/dev/null)'
+`
+)
+
+// CompletionCmd prints a shell completion script.
+type CompletionCmd struct {
+	Shell string `arg:"" name:"shell" enum:"bash,zsh,fish" help:"Shell to complete in: bash, zsh or fish"`
+}
+
+func (c *CompletionCmd) Run(ctx context.Context) error {
+	_, err := fmt.Fprint(stdoutFrom(ctx), completionScript(c.Shell))
+
+	return err
+}
+
+func completionScript(shell string) string {
+	switch shell {
+	case "zsh":
+		return zshCompletion
+	case "fish":
+		return fishCompletion
+	default:
+		return bashCompletion
+	}
+}
+
+// CompleteCmd prints the completions of the last word of a command line,
+// one per line. The completion scripts call it on every TAB.
+type CompleteCmd struct {
+	Words []string `arg:"" optional:"" passthrough:"" name:"words" help:"Command line after nube; the last word is being completed"`
+}
+
+func (c *CompleteCmd) Run(ctx context.Context, parser *kong.Kong) error {
+	words := c.Words
+	if len(words) > 0 && words[0] == "--" {
+		words = words[1:]
+	}
+
+	for _, s := range completions(parser.Model.Node, words) {
+		if _, err := fmt.Fprintln(stdoutFrom(ctx), s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// completions returns the subcommands or flags of the command named by
+// words that start with the last word.
+func completions(root *kong.Node, words []string) []string {
+	current := ""
+	if len(words) > 0 {
+		current, words = words[len(words)-1], words[:len(words)-1]
+	}
+
+	node := root
+	flags := slices.Clone(root.Flags)
+
+	for _, w := range words {
+		if strings.HasPrefix(w, "-") {
+			continue
+		}
+
+		if child := childCommand(node, w); child != nil {
+			node = child
+			flags = append(flags, child.Flags...)
+		}
+	}
+
+	var out []string
+
+	if strings.HasPrefix(current, "-") {
+		for _, f := range flags {
+			if !f.Hidden && strings.HasPrefix("--"+f.Name, current) {
+				out = append(out, "--"+f.Name)
+			}
+		}
+	} else {
+		for _, child := range node.Children {
+			if !child.Hidden && child.Type == kong.CommandNode && strings.HasPrefix(child.Name, current) {
+				out = append(out, child.Name)
+			}
+		}
+	}
+
+	slices.Sort(out)
+
+	return slices.Compact(out)
+}
+
+func childCommand(node *kong.Node, name string) *kong.Node {
+	for _, child := range node.Children {
+		if child.Type == kong.CommandNode && (child.Name == name || slices.Contains(child.Aliases, name)) {
+			return child
+		}
+	}
+
+	return nil
+}
+
+// completionPath is where shell looks for a user's completion script for
+// nube: bash-completion's and fish's per-user directories, and for zsh a
+// directory that must be on $fpath.
+func completionPath(shell string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home dir: %w", err)
+	}
+
+	data := os.Getenv("XDG_DATA_HOME")
+	if data == "" {
+		data = filepath.Join(home, ".local", "share")
+	}
+
+	switch shell {
+	case "bash":
+		return filepath.Join(data, "bash-completion", "completions", "nube"), nil
+	case "zsh":
+		return filepath.Join(data, "zsh", "site-functions", "_nube"), nil
+	case "fish":
+		config := os.Getenv("XDG_CONFIG_HOME")
+		if config == "" {
+			config = filepath.Join(home, ".config")
+		}
+
+		return filepath.Join(config, "fish", "completions", "nube.fish"), nil
+	default:
+		return "", fmt.Errorf("no completion for shell %q (want %s)", shell, strings.Join(completionShells, ", "))
+	}
+}
+
+// installCompletion writes the completion script of shell where the shell
+// loads it from and returns its path.
+func installCompletion(shell string) (string, error) {
+	path, err := completionPath(shell)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { //nolint:gosec // shell directory, not secret
+		return "", fmt.Errorf("create completion dir: %w", err)
+	}
+
+	if err := os.WriteFile(path, []byte(completionScript(shell)), 0o644); err != nil { //nolint:gosec // shell script, not secret
+		return "", fmt.Errorf("write completion: %w", err)
+	}
+
+	return path, nil
+}
+
+// loginShell is the name of the user's shell from $SHELL; empty when nube
+// can't complete in it.
+func loginShell() string {
+	shell := filepath.Base(os.Getenv("SHELL"))
+	if !slices.Contains(completionShells, shell) {
+		return ""
+	}
+
+	return shell
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestCompletions(t *testing.T) {
+	parser, _, err := newParser("", nil, os.Stdout, os.Stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := parser.Model.Node
+
+	for _, tc := range []struct {
+		words []string
+		want  string
+		not   string
+	}{
+		{[]string{"pro"}, "product", "order"},
+		{[]string{""}, "config", "__complete"},
+		{[]string{"prod", "li"}, "list", "get"},
+		{[]string{"product", "list", "--per"}, "--per-page", "--json-errors"},
+		{[]string{"--store", "x", "config", ""}, "validate", "product"},
+		{[]string{"--js"}, "--json", "--per-page"},
+	} {
+		got := completions(root, tc.words)
+		if !slices.Contains(got, tc.want) || slices.Contains(got, tc.not) {
+			t.Errorf("completions(%q) = %v, want %q and not %q", tc.words, got, tc.want, tc.not)
+		}
+	}
+}
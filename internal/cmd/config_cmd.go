@@ -3,7 +3,6 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"github.com/gberlati/nube-cli/internal/config"
 	"github.com/gberlati/nube-cli/internal/credstore"
@@ -11,8 +10,12 @@ import (
 )
 
 type ConfigCmd struct {
-	List ConfigListCmd `cmd:"" aliases:"ls,all" default:"withargs" help:"List all config values"`
-	Path ConfigPathCmd `cmd:"" aliases:"where" help:"Print config file path"`
+	List     ConfigListCmd     `cmd:"" aliases:"ls,all" default:"withargs" help:"List all config values"`
+	Path     ConfigPathCmd     `cmd:"" aliases:"where" help:"Print config file path"`
+	Theme    ConfigThemeCmd    `cmd:"" help:"Color theme"`
+	Edit     ConfigEditCmd     `cmd:"" help:"Edit config.json in $EDITOR; it is saved only if it validates"`
+	Validate ConfigValidateCmd `cmd:"" help:"Check config and credentials for unknown keys, invalid values and loose permissions"`
+	Migrate  ConfigMigrateCmd  `cmd:"" help:"Upgrade config and credentials files to this version's format"`
 }
 
 type ConfigListCmd struct{}
@@ -22,14 +25,14 @@ func (c *ConfigListCmd) Run(ctx context.Context) error {
 	credPath, _ := credstore.Path()
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{
 			"config_path":      path,
 			"credentials_path": credPath,
 		})
 	}
 
-	fmt.Fprintf(os.Stdout, "Config file: %s\n", path)
-	fmt.Fprintf(os.Stdout, "Credentials: %s\n", credPath)
+	fmt.Fprintf(stdoutFrom(ctx), "Config file: %s\n", path)
+	fmt.Fprintf(stdoutFrom(ctx), "Credentials: %s\n", credPath)
 
 	return nil
 }
@@ -43,10 +46,10 @@ func (c *ConfigPathCmd) Run(ctx context.Context) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, outfmt.PathPayload(path))
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), outfmt.PathPayload(path))
 	}
 
-	fmt.Fprintln(os.Stdout, path)
+	fmt.Fprintln(stdoutFrom(ctx), path)
 
 	return nil
 }
@@ -1,9 +1,15 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/credstore"
 )
 
 func TestConfigPath(t *testing.T) {
@@ -52,3 +58,211 @@ func TestConfigList(t *testing.T) {
 		t.Errorf("output = %q, want containing 'Config file'", buf.String())
 	}
 }
+
+func TestConfigThemePreview(t *testing.T) {
+	setupConfigDir(t)
+
+	if err := config.WriteConfig(config.File{Theme: &config.Theme{Accent: "#ff00ff", Header: "underline"}}); err != nil {
+		t.Fatalf("WriteConfig: %v", err)
+	}
+
+	buf := captureStdout(t)
+
+	if err := Execute([]string{"config", "theme", "preview", "--json"}); err != nil {
+		t.Fatalf("Execute error = %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v (output: %q)", err, buf.String())
+	}
+
+	if got["accent"] != "#ff00ff" || got["header"] != "underline" || got["success"] == "" {
+		t.Errorf("theme = %v", got)
+	}
+}
+
+func TestConfigThemePreview_Table(t *testing.T) {
+	setupConfigDir(t)
+	buf := captureStdout(t)
+	_ = captureStderr(t)
+
+	if err := Execute([]string{"config", "theme"}); err != nil {
+		t.Fatalf("Execute error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"ROLE", "success", "#22c55e", "header"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output = %q, want containing %q", out, want)
+		}
+	}
+}
+
+func TestConfigTheme_Invalid(t *testing.T) {
+	setupConfigDir(t)
+
+	if err := config.WriteConfig(config.File{Theme: &config.Theme{Success: "green"}}); err != nil {
+		t.Fatalf("WriteConfig: %v", err)
+	}
+
+	_ = captureStderr(t)
+
+	err := Execute([]string{"config", "path"})
+	if code := ExitCode(err); code != ExitConfig {
+		t.Fatalf("exit code = %d (err %v), want %d", code, err, ExitConfig)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{
+		"ok":  {StoreID: "1", AccessToken: "t"},
+		"bad": {StoreID: "2", LastUsedAt: "yesterday", Defaults: map[string]string{"force": "true"}},
+	}, "gone")
+
+	configPath, _ := config.ConfigPath()
+	if err := os.WriteFile(configPath, []byte(`{"version": 1, "lang": "fr", "colour": "red", "smtp": {"host": "mail"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	credPath, _ := credstore.Path()
+	_ = os.Chmod(credPath, 0o644)
+
+	out := captureStdout(t)
+	_ = captureStderr(t)
+
+	err := Execute([]string{"config", "validate", "--json"})
+	if ExitCode(err) != ExitError {
+		t.Errorf("err = %v, want exit 1", err)
+	}
+
+	var got struct {
+		Problems []configProblem `json:"problems"`
+	}
+	if err := json.NewDecoder(bytes.NewReader(out.Bytes())).Decode(&got); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, out.String())
+	}
+
+	keys := map[string]bool{}
+	for _, p := range got.Problems {
+		keys[filepath.Base(p.File)+":"+p.Key] = true
+	}
+
+	for _, want := range []string{
+		"config.json:lang", "config.json:colour", "config.json:smtp.from",
+		"credentials.json:default_store", "credentials.json:stores.bad.access_token",
+		"credentials.json:stores.bad.last_used_at", "credentials.json:stores.bad.defaults.force",
+		"credentials.json:",
+	} {
+		if !keys[want] {
+			t.Errorf("missing problem %s in %+v", want, got.Problems)
+		}
+	}
+
+	if keys["config.json:version"] || keys["credentials.json:stores.ok.store_id"] {
+		t.Errorf("unexpected problems: %+v", got.Problems)
+	}
+}
+
+func TestConfigValidate_Clean(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"ok": {StoreID: "1", AccessToken: "t"}}, "ok")
+
+	_ = captureStdout(t)
+	_ = captureStderr(t)
+
+	if err := Execute([]string{"config", "validate"}); err != nil {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestConfigMigrate(t *testing.T) {
+	setupConfigDir(t)
+
+	dir, _ := config.EnsureDir()
+	credPath := filepath.Join(dir, "credentials.json")
+
+	if err := os.WriteFile(credPath, []byte(`{"default_store": "a", "stores": {"a": {"store_id": "1", "access_token": "t"}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t)
+
+	if err := Execute([]string{"config", "migrate", "--json"}); err != nil {
+		t.Fatalf("Execute error = %v", err)
+	}
+
+	var got struct {
+		Files []config.MigrateResult `json:"files"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, out.String())
+	}
+
+	// config.json doesn't exist and is left alone.
+	if len(got.Files) != 2 || got.Files[0].Backup != "" || got.Files[1].From != 0 || got.Files[1].Backup != credPath+".v0.bak" {
+		t.Errorf("files = %+v", got.Files)
+	}
+
+	if st, err := os.Stat(got.Files[1].Backup); err != nil || st.Mode().Perm() != 0o600 {
+		t.Errorf("backup: %v, %v", st, err)
+	}
+
+	f, err := credstore.Read()
+	if err != nil || f.Version != credstore.CurrentVersion || f.Stores["a"].AccessToken != "t" {
+		t.Errorf("credentials = %+v, %v", f, err)
+	}
+}
+
+func TestConfigEdit(t *testing.T) {
+	setupConfigDir(t)
+
+	seen := setupEditor(t, func(string) string { return "{\n  // mine\n  \"lang\": \"es\",\n}\n" })
+
+	_ = captureStdout(t)
+	_ = captureStderr(t)
+
+	if err := Execute([]string{"config", "edit"}); err != nil {
+		t.Fatalf("Execute error = %v", err)
+	}
+
+	if !strings.Contains(*seen, `"version": 1`) {
+		t.Errorf("new config template = %q", *seen)
+	}
+
+	path, _ := config.ConfigPath()
+
+	if cfg, err := config.ReadConfig(); err != nil || cfg.Lang != "es" {
+		t.Fatalf("config = %+v, %v", cfg, err)
+	}
+
+	// An invalid edit is rejected; the saved config and the edits survive.
+	setupEditor(t, func(s string) string { return strings.Replace(s, `"es"`, `"fr"`, 1) })
+
+	err := Execute([]string{"config", "edit"})
+	if ExitCode(err) != ExitConfig || !strings.Contains(err.Error(), "lang: unsupported language") {
+		t.Fatalf("err = %v, want a config error naming lang", err)
+	}
+
+	kept := err.Error()[strings.LastIndex(err.Error(), " ")+1:]
+	if b, readErr := os.ReadFile(kept); readErr != nil || !strings.Contains(string(b), `"fr"`) {
+		t.Errorf("edits not kept in %s: %v", kept, readErr)
+	}
+
+	if cfg, _ := config.ReadConfig(); cfg.Lang != "es" {
+		t.Errorf("config changed to %+v", cfg)
+	}
+
+	setupEditor(t, func(s string) string { return strings.Replace(s, `"es"`, `"pt"`, 1) })
+
+	if err := Execute([]string{"config", "edit"}); err != nil {
+		t.Fatalf("Execute error = %v", err)
+	}
+
+	if b, _ := os.ReadFile(path + ".bak"); !strings.Contains(string(b), `"es"`) {
+		t.Errorf("backup = %q", b)
+	}
+
+	if b, _ := os.ReadFile(path); !strings.Contains(string(b), "// mine") {
+		t.Errorf("comments lost: %q", b)
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// ConfigEditCmd opens config.json in the user's editor and saves it only
+// if it still validates, keeping the previous version as config.json.bak.
+type ConfigEditCmd struct{}
+
+// newConfigTemplate is what the editor shows when there is no config yet.
+const newConfigTemplate = "{\n  // nube configuration (JSON5: comments and trailing commas are fine)\n  \"version\": 1,\n}\n"
+
+func (c *ConfigEditCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	dir, err := config.EnsureDir()
+	if err != nil {
+		return err
+	}
+
+	path, err := config.ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	original, err := os.ReadFile(path) //nolint:gosec // config file path
+	exists := err == nil
+
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		original = []byte(newConfigTemplate)
+	case err != nil:
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	// The copy lives next to the config, so it gets the same protection.
+	f, err := os.CreateTemp(dir, "config-edit-*.json")
+	if err != nil {
+		return fmt.Errorf("create edit file: %w", err)
+	}
+
+	tmp := f.Name()
+
+	_, err = f.Write(original)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp)
+
+		return fmt.Errorf("write edit file: %w", err)
+	}
+
+	// The file is kept when the edit is rejected, so the edits aren't lost.
+	keep := false
+
+	defer func() {
+		if !keep {
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	if err := runEditor(ctx, tmp); err != nil {
+		return err
+	}
+
+	edited, err := os.ReadFile(tmp) //nolint:gosec // our own temp file
+	if err != nil {
+		return fmt.Errorf("read edit file: %w", err)
+	}
+
+	if bytes.Equal(bytes.TrimSpace(edited), bytes.TrimSpace(original)) {
+		u.Err().Println("edit cancelled, no changes made")
+
+		return nil
+	}
+
+	if problems := validateConfig(&problemList{file: tmp, oldVersionOK: true}); len(problems) > 0 {
+		keep = true
+
+		lines := make([]string, len(problems))
+		for i, p := range problems {
+			lines[i] = p.Problem
+			if p.Key != "" {
+				lines[i] = p.Key + ": " + p.Problem
+			}
+		}
+
+		return &ExitErr{Code: ExitConfig, Err: fmt.Errorf("%s not saved:\n  %s\nyour edits are in %s",
+			filepath.Base(path), strings.Join(lines, "\n  "), tmp)}
+	}
+
+	if flags.DryRun {
+		return writeResult(ctx, u, kv("dry_run", true), kv("path", path), kv("valid", true))
+	}
+
+	backup := ""
+
+	if exists {
+		backup = path + ".bak"
+		if err := os.WriteFile(backup, original, 0o600); err != nil {
+			keep = true
+
+			return fmt.Errorf("back up config: %w (your edits are in %s)", err, tmp)
+		}
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		keep = true
+
+		return fmt.Errorf("save config: %w (your edits are in %s)", err, tmp)
+	}
+
+	return writeResult(ctx, u, kv("path", path), kv("backup", backup))
+}
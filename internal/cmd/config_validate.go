@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/yosuke-furukawa/json5/encoding/json5"
+
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/i18n"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// ConfigValidateCmd checks config.json and credentials.json and exits 1
+// when it finds problems.
+type ConfigValidateCmd struct{}
+
+// ConfigMigrateCmd upgrades config.json and credentials.json to the format
+// of this build.
+type ConfigMigrateCmd struct{}
+
+// configProblem is one issue found in a config file.
+type configProblem struct {
+	File    string `json:"file"`
+	Key     string `json:"key,omitempty"`
+	Problem string `json:"problem"`
+}
+
+// problemList collects the problems of one file.
+type problemList struct {
+	file     string
+	problems []configProblem
+	// oldVersionOK accepts a file in an older format, which reads fine.
+	oldVersionOK bool
+}
+
+func (l *problemList) add(key, format string, args ...any) {
+	l.problems = append(l.problems, configProblem{File: l.file, Key: key, Problem: fmt.Sprintf(format, args...)})
+}
+
+func (c *ConfigValidateCmd) Run(ctx context.Context, parser *kong.Kong) error {
+	u := ui.FromContext(ctx)
+
+	configPath, err := config.ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	credPath, err := credstore.Path()
+	if err != nil {
+		return err
+	}
+
+	problems := append([]configProblem{}, validateConfigFile(configPath)...)
+	problems = append(problems, validateCredentialsFile(credPath, flagNames(parser.Model.Node))...)
+	problems = append(problems, checkConfigPermissions(filepath.Dir(configPath), credPath)...)
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"problems": problems}); err != nil {
+			return err
+		}
+	} else if len(problems) > 0 {
+		w, done := tableWriter(ctx)
+
+		_, _ = fmt.Fprintln(w, "FILE\tKEY\tPROBLEM")
+
+		for _, p := range problems {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", filepath.Base(p.File), p.Key, p.Problem)
+		}
+
+		done()
+	}
+
+	if len(problems) == 0 {
+		u.Err().Println("Config and credentials are valid")
+
+		return nil
+	}
+
+	u.Err().Printf("%d problems found", len(problems))
+
+	return &ExitErr{Code: ExitError}
+}
+
+// readVersioned reads a JSON5 file into a generic map and into v, reporting
+// syntax errors, type mismatches, unknown keys and version problems. It
+// returns false when the file is missing or can't be decoded.
+func readVersioned(l *problemList, current int, v any) bool {
+	b, err := os.ReadFile(l.file)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			l.add("", "%v", err)
+		}
+
+		return false
+	}
+
+	raw := map[string]any{}
+	if err := json5.Unmarshal(b, &raw); err != nil {
+		l.add("", "not valid JSON5: %v", err)
+
+		return false
+	}
+
+	switch version, err := config.Version(raw); {
+	case err != nil:
+		l.add("version", "%v", err)
+	case version > current:
+		l.add("version", "version %d is %v", version, config.ErrNewerVersion)
+
+		return false
+	case version < current && !l.oldVersionOK:
+		l.add("version", "version %d is out of date (current %d); run `nube config migrate`", version, current)
+	}
+
+	for _, key := range config.UnknownKeys(raw, v) {
+		l.add(key, "unknown key")
+	}
+
+	b, _ = json.Marshal(raw)
+
+	var typeErr *json.UnmarshalTypeError
+	if err := json.Unmarshal(b, v); errors.As(err, &typeErr) {
+		l.add(typeErr.Field, "want a %s, not a JSON %s", typeErr.Type, typeErr.Value)
+
+		return false
+	} else if err != nil {
+		l.add("", "%v", err)
+
+		return false
+	}
+
+	return true
+}
+
+func validateConfigFile(path string) []configProblem {
+	return validateConfig(&problemList{file: path})
+}
+
+// validateConfig checks the config.json at l.file.
+func validateConfig(l *problemList) []configProblem {
+	var cfg config.File
+	if !readVersioned(l, config.CurrentVersion, &cfg) {
+		return l.problems
+	}
+
+	for _, n := range []struct {
+		key   string
+		value int
+	}{
+		{"confirm_threshold", cfg.ConfirmThreshold},
+		{"confirm_preview", cfg.ConfirmPreview},
+		{"agent_max_items", cfg.AgentMaxItems},
+	} {
+		if n.value < 0 {
+			l.add(n.key, "must not be negative")
+		}
+	}
+
+	if cfg.Lang != "" && i18n.Normalize(cfg.Lang) == "" {
+		l.add("lang", "unsupported language %q (want en, es, or pt)", cfg.Lang)
+	}
+
+	for _, lang := range cfg.LangPriority {
+		if i18n.Normalize(lang) == "" {
+			l.add("lang_priority", "unsupported language %q (want en, es, or pt)", lang)
+		}
+	}
+
+	if t := cfg.Theme; t != nil {
+		theme := ui.Theme{Success: t.Success, Error: t.Error, Accent: t.Accent, Muted: t.Muted, Header: t.Header, Background: t.Background}
+		if err := theme.Validate(); err != nil {
+			l.add("theme", "%v", err)
+		}
+	}
+
+	if h := cfg.HTTP; h != nil {
+		if h.MaxIdleConnsPerHost < 0 {
+			l.add("http.max_idle_conns_per_host", "must not be negative")
+		}
+
+		if d, err := time.ParseDuration(h.IdleConnTimeout); h.IdleConnTimeout != "" && (err != nil || d < 0) {
+			l.add("http.idle_conn_timeout", "%q: want a duration like 90s", h.IdleConnTimeout)
+		}
+	}
+
+	if s := cfg.SMTP; s != nil {
+		if s.Host != "" && s.From == "" {
+			l.add("smtp.from", "required with smtp.host; it is the sender address")
+		}
+
+		if s.Port < 0 || s.Port > 65535 {
+			l.add("smtp.port", "%d is not a port", s.Port)
+		}
+
+		if !slices.Contains([]string{"", "starttls", "tls", "none"}, s.TLS) {
+			l.add("smtp.tls", "%q: want starttls, tls or none", s.TLS)
+		}
+	}
+
+	return l.problems
+}
+
+func validateCredentialsFile(path string, flags map[string]bool) []configProblem {
+	l := &problemList{file: path}
+
+	var f credstore.File
+	if !readVersioned(l, credstore.CurrentVersion, &f) {
+		return l.problems
+	}
+
+	if _, ok := f.Stores[f.DefaultStore]; f.DefaultStore != "" && !ok {
+		l.add("default_store", "no store profile named %q", f.DefaultStore)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(f.Stores)) {
+		p, key := f.Stores[name], "stores."+name
+
+		if p.StoreID == "" {
+			l.add(key+".store_id", "missing")
+		}
+
+		if p.AccessToken == "" {
+			l.add(key+".access_token", "missing")
+		}
+
+		if err := validateBaseURL(p.APIBaseURL); err != nil {
+			l.add(key+".api_base_url", "%v", err)
+		}
+
+		for _, ts := range [][2]string{{"created_at", p.CreatedAt}, {"last_used_at", p.LastUsedAt}} {
+			if _, err := time.Parse(time.RFC3339, ts[1]); ts[1] != "" && err != nil {
+				l.add(key+"."+ts[0], "%q is not an RFC 3339 time", ts[1])
+			}
+		}
+
+		for _, flag := range slices.Sorted(maps.Keys(p.Defaults)) {
+			if !flags[flag] || slices.Contains(profileDefaultsDenied, flag) {
+				l.add(key+".defaults."+flag, "not a flag a profile can default")
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(f.OAuthClients)) {
+		if c := f.OAuthClients[name]; c.ClientID == "" || c.ClientSecret == "" {
+			l.add("oauth_clients."+name, "client_id and client_secret are required")
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(f.Partners)) {
+		p, key := f.Partners[name], "partners."+name
+
+		if p.PartnerID == "" || p.AccessToken == "" {
+			l.add(key, "partner_id and access_token are required")
+		}
+
+		if err := validateBaseURL(p.APIBaseURL); err != nil {
+			l.add(key+".api_base_url", "%v", err)
+		}
+	}
+
+	return l.problems
+}
+
+// checkConfigPermissions reports a config directory or credential file
+// other users can read or write. Windows permissions aren't mode bits, so
+// they aren't checked there.
+func checkConfigPermissions(dir, credPath string) []configProblem {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	var problems []configProblem
+
+	if st, err := os.Stat(dir); err == nil && st.Mode().Perm()&0o022 != 0 {
+		problems = append(problems, configProblem{File: dir, Problem: fmt.Sprintf("writable by other users (%o); run chmod 700", st.Mode().Perm())})
+	}
+
+	if st, err := os.Stat(credPath); err == nil && st.Mode().Perm()&0o077 != 0 {
+		problems = append(problems, configProblem{File: credPath, Problem: fmt.Sprintf("accessible by other users (%o); run chmod 600", st.Mode().Perm())})
+	}
+
+	return problems
+}
+
+func (c *ConfigMigrateCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	cfg, err := config.MigrateConfig(flags.DryRun)
+	if err != nil {
+		return &ExitErr{Code: ExitConfig, Err: err}
+	}
+
+	creds, err := credstore.Migrate(flags.DryRun)
+	if err != nil {
+		return &ExitErr{Code: ExitConfig, Err: err}
+	}
+
+	results := []config.MigrateResult{cfg, creds}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"dry_run": flags.DryRun, "files": results})
+	}
+
+	for _, r := range results {
+		switch {
+		case r.From == r.To:
+			u.Out().Printf("%s\tup to date (version %d)", r.Path, r.To)
+		case flags.DryRun:
+			u.Out().Printf("%s\twould upgrade from version %d to %d", r.Path, r.From, r.To)
+		default:
+			u.Out().Printf("%s\tupgraded from version %d to %d (backup: %s)", r.Path, r.From, r.To, r.Backup)
+		}
+	}
+
+	return nil
+}
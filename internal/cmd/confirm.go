@@ -9,6 +9,15 @@ import (
 	"strings"
 
 	"golang.org/x/term"
+
+	"github.com/gberlati/nube-cli/internal/config"
+)
+
+// Defaults for bulk confirmations; config keys confirm_threshold and
+// confirm_preview override them.
+const (
+	defaultConfirmThreshold = 25
+	defaultConfirmPreview   = 5
 )
 
 func confirmDestructive(flags *RootFlags, action string) error {
@@ -16,22 +25,137 @@ func confirmDestructive(flags *RootFlags, action string) error {
 		return nil
 	}
 
-	// Never prompt in non-interactive contexts.
-	if flags.NoInput || !term.IsTerminal(int(os.Stdin.Fd())) { //nolint:gosec // fd conversion is safe
+	if !interactive(flags) {
+		return &ExitErr{Code: ExitUsage, Err: fmt.Errorf("refusing to %s without --force (non-interactive)", action)}
+	}
+
+	return promptYesNo(action)
+}
+
+// confirmBulk guards writes to many resources. It previews how many are
+// affected and the first IDs; above the configured threshold the user must
+// type store (the store's name) instead of answering y/N. --force skips it.
+func confirmBulk(flags *RootFlags, action string, ids []string, store string) error {
+	if flags == nil || flags.Force {
+		return nil
+	}
+
+	if !interactive(flags) {
 		return &ExitErr{Code: ExitUsage, Err: fmt.Errorf("refusing to %s without --force (non-interactive)", action)}
 	}
 
+	threshold, preview := bulkConfirmSettings()
+
+	fmt.Fprintln(os.Stderr, bulkPreview(action, ids, preview))
+
+	if len(ids) > threshold && store != "" {
+		return promptTyped(action, store)
+	}
+
+	return promptYesNo(action)
+}
+
+// bulkPreview summarizes a bulk write: the count, then up to n sample IDs.
+func bulkPreview(action string, ids []string, n int) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "About to %s (%d affected).", action, len(ids))
+
+	if n > 0 && len(ids) > 0 {
+		sample := ids[:min(n, len(ids))]
+		fmt.Fprintf(&b, "\n  IDs: %s", strings.Join(sample, ", "))
+
+		if rest := len(ids) - len(sample); rest > 0 {
+			fmt.Fprintf(&b, " and %d more", rest)
+		}
+	}
+
+	return b.String()
+}
+
+// bulkConfirmSettings reads the confirmation threshold and preview size from
+// config, falling back to the defaults.
+func bulkConfirmSettings() (threshold, preview int) {
+	threshold, preview = defaultConfirmThreshold, defaultConfirmPreview
+
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return threshold, preview
+	}
+
+	if cfg.ConfirmThreshold > 0 {
+		threshold = cfg.ConfirmThreshold
+	}
+
+	if cfg.ConfirmPreview > 0 {
+		preview = cfg.ConfirmPreview
+	}
+
+	return threshold, preview
+}
+
+// confirmTyped guards operations too destructive for a y/N prompt: the user
+// must type want (e.g. the store ID), or pass it with flag (given) for
+// non-interactive use. --force does not bypass it.
+func confirmTyped(flags *RootFlags, action, want, flag, given string) error {
+	if given != "" {
+		if given != want {
+			return &ExitErr{Code: ExitUsage, Err: fmt.Errorf("refusing to %s: %q does not match %q", action, given, want)}
+		}
+
+		return nil
+	}
+
+	if flags == nil || !interactive(flags) {
+		return &ExitErr{Code: ExitUsage, Err: fmt.Errorf("refusing to %s without %s %s (non-interactive)", action, flag, want)}
+	}
+
+	return promptTyped(action, want)
+}
+
+// interactive reports whether prompting is allowed.
+func interactive(flags *RootFlags) bool {
+	return !flags.NoInput && term.IsTerminal(int(os.Stdin.Fd())) //nolint:gosec // fd conversion is safe
+}
+
+func promptYesNo(action string) error {
 	fmt.Fprintf(os.Stderr, "Proceed to %s? [y/N]: ", action)
-	line, readErr := bufio.NewReader(os.Stdin).ReadString('\n')
 
-	if readErr != nil && !errors.Is(readErr, io.EOF) {
-		return fmt.Errorf("read confirmation: %w", readErr)
+	line, err := readConfirmation()
+	if err != nil {
+		return err
 	}
 
-	ans := strings.TrimSpace(strings.ToLower(line))
+	ans := strings.ToLower(line)
 	if ans == "y" || ans == "yes" {
 		return nil
 	}
 
 	return &ExitErr{Code: ExitCancelled, Err: errors.New("cancelled")}
 }
+
+func promptTyped(action, want string) error {
+	fmt.Fprintf(os.Stderr, "This will %s.\nType %q to confirm: ", action, want)
+
+	line, err := readConfirmation()
+	if err != nil {
+		return err
+	}
+
+	if line != want {
+		return &ExitErr{Code: ExitCancelled, Err: errors.New("cancelled")}
+	}
+
+	return nil
+}
+
+func readConfirmation() (string, error) {
+	defer waitingForInput()()
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read confirmation: %w", err)
+	}
+
+	return strings.TrimSpace(line), nil
+}
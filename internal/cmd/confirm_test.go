@@ -1,6 +1,10 @@
 package cmd
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/config"
+)
 
 func TestConfirmDestructive_ForceSkips(t *testing.T) {
 	flags := &RootFlags{Force: true}
@@ -22,3 +26,78 @@ func TestConfirmDestructive_NilFlags(t *testing.T) {
 		t.Errorf("nil flags should skip, got error: %v", err)
 	}
 }
+
+func TestConfirmTyped(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		flags    *RootFlags
+		given    string
+		wantCode int
+	}{
+		{name: "matching value", flags: &RootFlags{NoInput: true}, given: "123", wantCode: ExitOK},
+		{name: "mismatched value", flags: &RootFlags{NoInput: true}, given: "456", wantCode: ExitUsage},
+		{name: "force is not enough", flags: &RootFlags{Force: true, NoInput: true}, wantCode: ExitUsage},
+		{name: "non-interactive", flags: &RootFlags{NoInput: true}, wantCode: ExitUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := confirmTyped(tt.flags, "wipe", "123", "--confirm-store", tt.given)
+			if got := ExitCode(err); got != tt.wantCode {
+				t.Errorf("ExitCode = %d, want %d (err %v)", got, tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestBulkPreview(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		ids  []string
+		n    int
+		want string
+	}{
+		{[]string{"1", "2"}, 5, "About to delete (2 affected).\n  IDs: 1, 2"},
+		{[]string{"1", "2", "3", "4"}, 2, "About to delete (4 affected).\n  IDs: 1, 2 and 2 more"},
+		{nil, 5, "About to delete (0 affected)."},
+	}
+
+	for _, tt := range tests {
+		if got := bulkPreview("delete", tt.ids, tt.n); got != tt.want {
+			t.Errorf("bulkPreview(%v, %d) = %q, want %q", tt.ids, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestBulkConfirmSettings(t *testing.T) {
+	setupConfigDir(t)
+
+	if threshold, preview := bulkConfirmSettings(); threshold != defaultConfirmThreshold || preview != defaultConfirmPreview {
+		t.Errorf("defaults = %d, %d", threshold, preview)
+	}
+
+	if err := config.WriteConfig(config.File{ConfirmThreshold: 3, ConfirmPreview: 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	if threshold, preview := bulkConfirmSettings(); threshold != 3 || preview != 10 {
+		t.Errorf("configured = %d, %d, want 3, 10", threshold, preview)
+	}
+}
+
+func TestConfirmBulk_NonInteractive(t *testing.T) {
+	t.Parallel()
+
+	if err := confirmBulk(&RootFlags{Force: true}, "delete", []string{"1"}, "shop"); err != nil {
+		t.Errorf("Force=true should skip, got %v", err)
+	}
+
+	if err := confirmBulk(&RootFlags{NoInput: true}, "delete", []string{"1"}, "shop"); ExitCode(err) != ExitUsage {
+		t.Errorf("NoInput: ExitCode = %d, want %d", ExitCode(err), ExitUsage)
+	}
+}
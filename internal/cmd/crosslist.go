@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/crosslist"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// CrosslistCmd groups helpers for listing the catalog on marketplaces.
+type CrosslistCmd struct {
+	Export CrosslistExportCmd `cmd:"" help:"Map products to a marketplace's item schema, flagging what can't be mapped"`
+}
+
+type CrosslistExportCmd struct {
+	Target      string `help:"Marketplace: meli (MercadoLibre)" name:"target" enum:"meli" required:""`
+	Out         string `help:"File to write the listings to (default stdout)" short:"o" name:"out" type:"path"`
+	Category    string `help:"MercadoLibre category ID for products without a category_id custom field, e.g. MLA1055" name:"category"`
+	ListingType string `help:"MercadoLibre listing type" name:"listing-type" default:"gold_special"`
+	Condition   string `help:"Item condition" name:"condition" enum:"new,used,not_specified" default:"new"`
+	Currency    string `help:"Currency ID (default: the store's main currency)" name:"currency"`
+	Namespace   string `help:"Namespace of the product custom fields sent as MercadoLibre attributes" name:"namespace" default:"meli"`
+}
+
+func (c *CrosslistExportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	currency := c.Currency
+	if currency == "" {
+		info, err := loadStoreInfo(ctx, client)
+		if err != nil {
+			return err
+		}
+
+		currency = info.MainCurrency
+	}
+
+	fields, err := productCustomFields(ctx, client, c.Namespace)
+	if err != nil {
+		return err
+	}
+
+	opts := crosslist.MeLiOptions{
+		CategoryID:  c.Category,
+		ListingType: c.ListingType,
+		Condition:   c.Condition,
+		Currency:    currency,
+		Translate:   extractI18n,
+	}
+
+	listings := []crosslist.Listing{}
+	flagged := 0
+
+	for page, err := range api.Pages[map[string]any](ctx, client, "products", url.Values{"per_page": {"200"}}) {
+		if err != nil {
+			return err
+		}
+
+		for _, p := range page.Items {
+			l := crosslist.MeLi(p, fields[jsonStr(p, "id")], opts)
+			if !l.OK {
+				flagged++
+
+				u.Err().Printf("product %s: %s", l.ProductID, strings.Join(l.Errors, "; "))
+			}
+
+			listings = append(listings, l)
+		}
+	}
+
+	u.Err().Printf("%d ready, %d need attention", len(listings)-flagged, flagged)
+
+	if c.Out == "" || c.Out == "-" {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), listings)
+	}
+
+	if err := writeJSONFile(c.Out, listings); err != nil {
+		return err
+	}
+
+	return writeResult(ctx, u, kv("path", c.Out), kv("ready", len(listings)-flagged), kv("flagged", flagged))
+}
+
+// productCustomFields returns the product metafields in namespace, by
+// product ID and key.
+func productCustomFields(ctx context.Context, client *api.Client, namespace string) (map[string]map[string]string, error) {
+	fields := map[string]map[string]string{}
+	q := url.Values{"owner_resource": {"Product"}, "namespace": {namespace}, "per_page": {"200"}}
+
+	for page, err := range api.Pages[map[string]any](ctx, client, "metafields", q) {
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range page.Items {
+			// Filtered here too, in case the API ignores the parameter.
+			if jsonStr(m, "namespace") != namespace {
+				continue
+			}
+
+			id := jsonStr(m, "owner_id")
+			if fields[id] == nil {
+				fields[id] = map[string]string{}
+			}
+
+			fields[id][jsonStr(m, "key")] = jsonStr(m, "value")
+		}
+	}
+
+	return fields, nil
+}
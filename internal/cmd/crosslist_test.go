@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestCrosslistExport_MeLi(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimPrefix(r.URL.Path, "/v1/123/") {
+		case "products":
+			_, _ = w.Write([]byte(`[
+				{"id":1,"name":{"es":"Remera"},"images":[{"src":"https://cdn.test/1.jpg"}],"variants":[{"price":"100","stock":2,"sku":"REM"}]},
+				{"id":2,"name":{"es":"Taza"},"variants":[{"price":"50","stock":1}]}
+			]`))
+		case "metafields":
+			if r.URL.Query().Get("owner_resource") != "Product" {
+				t.Errorf("metafields query = %s", r.URL.RawQuery)
+			}
+
+			_, _ = w.Write([]byte(`[
+				{"namespace":"meli","key":"category_id","value":"MLA999","owner_id":1},
+				{"namespace":"meli","key":"model","value":"Básica","owner_id":1},
+				{"namespace":"other","key":"model","value":"ignored","owner_id":2}
+			]`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+
+	out := filepath.Join(t.TempDir(), "items.json")
+
+	_ = captureStdout(t)
+	errOut := captureStderr(t)
+
+	if err := Execute([]string{"crosslist", "export", "--target", "meli", "--currency", "ARS", "--out", out}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var listings []struct {
+		ProductID string         `json:"product_id"`
+		OK        bool           `json:"ok"`
+		Errors    []string       `json:"errors"`
+		Item      map[string]any `json:"item"`
+	}
+	if err := json.Unmarshal(b, &listings); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, b)
+	}
+
+	if len(listings) != 2 || !listings[0].OK || listings[1].OK {
+		t.Fatalf("listings = %+v", listings)
+	}
+
+	if listings[0].Item["category_id"] != "MLA999" || listings[0].Item["currency_id"] != "ARS" {
+		t.Errorf("item = %v", listings[0].Item)
+	}
+
+	if !strings.Contains(strings.Join(listings[1].Errors, ";"), "no MercadoLibre category") {
+		t.Errorf("errors = %q", listings[1].Errors)
+	}
+
+	if !strings.Contains(errOut.String(), "1 ready, 1 need attention") || !strings.Contains(errOut.String(), "product 2:") {
+		t.Errorf("stderr = %q", errOut.String())
+	}
+}
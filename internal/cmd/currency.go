@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// exchangeRatesURL serves current rates as {"rates": {"ARS": 350, ...}} per
+// unit of the currency appended to it. It is a package-level var so tests
+// can swap it.
+var exchangeRatesURL = "https://open.er-api.com/v6/latest/"
+
+// ConvertFlags add currency conversion to commands that output amounts.
+type ConvertFlags struct {
+	ConvertTo  string `help:"Convert amounts to this currency (ISO code, e.g. USD)" name:"convert-to"`
+	RateSource string `help:"Rates for --convert-to: api (current rates) or fixed:RATE / fixed:ARS=350,BRL=5.2 (units of each currency per unit of --convert-to)" name:"rate-source" default:"api"`
+}
+
+// currencyConverter converts amounts into one currency. Rates are units of
+// the source currency per unit of the target, as exchange rates are usually
+// quoted ("the dollar at 350").
+type currencyConverter struct {
+	to    string
+	fixed map[string]float64 // "" is the rate for any currency
+	api   func() (map[string]float64, error)
+}
+
+// converter returns nil without --convert-to.
+func (f ConvertFlags) converter(ctx context.Context, timeout time.Duration) (*currencyConverter, error) {
+	if f.ConvertTo == "" {
+		return nil, nil
+	}
+
+	to := strings.ToUpper(strings.TrimSpace(f.ConvertTo))
+	if len(to) != 3 {
+		return nil, usagef("--convert-to %q: want a three-letter currency code", f.ConvertTo)
+	}
+
+	c := &currencyConverter{to: to}
+
+	switch spec, ok := strings.CutPrefix(f.RateSource, "fixed:"); {
+	case f.RateSource == "api":
+		c.api = sync.OnceValues(func() (map[string]float64, error) { return fetchExchangeRates(ctx, to, timeout) })
+	case ok:
+		rates, err := parseFixedRates(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		c.fixed = rates
+	default:
+		return nil, usagef("--rate-source %q: want api or fixed:RATE", f.RateSource)
+	}
+
+	return c, nil
+}
+
+// parseFixedRates reads "350" (any currency) or "ARS=350,BRL=5.2".
+func parseFixedRates(spec string) (map[string]float64, error) {
+	rates := map[string]float64{}
+
+	for _, part := range strings.Split(spec, ",") {
+		code, value, ok := strings.Cut(part, "=")
+		if !ok {
+			code, value = "", code
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate <= 0 || math.IsInf(rate, 0) {
+			return nil, usagef("--rate-source fixed:%s: %q is not a positive rate", spec, value)
+		}
+
+		rates[strings.ToUpper(strings.TrimSpace(code))] = rate
+	}
+
+	return rates, nil
+}
+
+// rate returns the units of from per unit of the target currency.
+func (c *currencyConverter) rate(from string) (float64, error) {
+	from = strings.ToUpper(from)
+	if from == c.to {
+		return 1, nil
+	}
+
+	if c.api == nil {
+		if r, ok := c.fixed[from]; ok {
+			return r, nil
+		}
+
+		if r, ok := c.fixed[""]; ok && from != "" {
+			return r, nil
+		}
+
+		return 0, usagef("--rate-source has no rate for %q amounts; add %s=RATE", from, from)
+	}
+
+	rates, err := c.api()
+	if err != nil {
+		return 0, err
+	}
+
+	r, ok := rates[from]
+	if !ok || r <= 0 {
+		return 0, fmt.Errorf("exchange rates: no %s rate for %s; pass --rate-source fixed:%s=RATE", from, c.to, from)
+	}
+
+	return r, nil
+}
+
+// convert returns amount, in from, in the target currency, rounded to the
+// target's minor unit.
+func (c *currencyConverter) convert(amount float64, from string) (float64, error) {
+	r, err := c.rate(from)
+	if err != nil {
+		return 0, err
+	}
+
+	return roundCurrency(amount/r, c.to), nil
+}
+
+func roundCurrency(amount float64, currency string) float64 {
+	if zeroDecimalCurrencies[currency] {
+		return math.Round(amount)
+	}
+
+	return math.Round(amount*100) / 100
+}
+
+func fetchExchangeRates(ctx context.Context, base string, timeout time.Duration) (map[string]float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, exchangeRatesURL+base, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch exchange rates: %w", unwrapURLError(err))
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("fetch exchange rates: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("fetch exchange rates: HTTP %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Rates map[string]float64 `json:"rates"`
+	}
+
+	if err := json.Unmarshal(b, &body); err != nil || len(body.Rates) == 0 {
+		return nil, fmt.Errorf("fetch exchange rates: unexpected response for %s", base)
+	}
+
+	return body.Rates, nil
+}
+
+// moneyFields are each resource's amount fields by dotted path; a path
+// through a list ("variants.price") covers every element.
+var moneyFields = map[string][]string{
+	"orders": {
+		"subtotal", "discount", "discount_coupon", "discount_gateway", "total",
+		"shipping_cost_owner", "shipping_cost_customer", "products.price", "products.compare_at_price",
+	},
+	"products":  {"variants.price", "variants.promotional_price", "variants.compare_at_price", "variants.cost"},
+	"customers": {"total_spent"},
+}
+
+// currencyFields name the field with the currency of a resource's amounts,
+// which conversion updates. Products are priced in the store's currency.
+var currencyFields = map[string]string{"orders": "currency", "customers": "total_spent_currency"}
+
+// convertItem converts the amounts of an API object in place. storeCurrency
+// is used when the item doesn't name its currency.
+func (c *currencyConverter) convertItem(resource string, item map[string]any, storeCurrency func() (string, error)) error {
+	key := currencyFields[resource]
+
+	from := jsonStr(item, key)
+	if from == "" {
+		var err error
+		if from, err = storeCurrency(); err != nil {
+			return err
+		}
+	}
+
+	r, err := c.rate(from)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range moneyFields[resource] {
+		convertPath(item, strings.Split(p, "."), func(v float64) float64 { return roundCurrency(v/r, c.to) })
+	}
+
+	if key != "" {
+		item[key] = c.to
+	}
+
+	return nil
+}
+
+// convertPath applies fn to the amount at path, keeping its JSON type (the
+// API sends most amounts as strings). Missing and empty values are skipped.
+func convertPath(m map[string]any, path []string, fn func(float64) float64) {
+	v, ok := m[path[0]]
+	if !ok {
+		return
+	}
+
+	if len(path) > 1 {
+		switch next := v.(type) {
+		case map[string]any:
+			convertPath(next, path[1:], fn)
+		case []any:
+			for _, el := range next {
+				if em, ok := el.(map[string]any); ok {
+					convertPath(em, path[1:], fn)
+				}
+			}
+		}
+
+		return
+	}
+
+	switch amount := v.(type) {
+	case float64:
+		m[path[0]] = fn(amount)
+	case string:
+		if f, err := strconv.ParseFloat(amount, 64); err == nil {
+			m[path[0]] = strconv.FormatFloat(fn(f), 'f', 2, 64)
+		}
+	}
+}
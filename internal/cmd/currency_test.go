@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportSales_ConvertTo(t *testing.T) {
+	setupReportAPI(t)
+
+	out := captureStdout(t)
+
+	if err := Execute([]string{"--json", "--tz", "UTC", "report", "sales", "--by", "month", "--convert-to", "usd", "--rate-source", "fixed:ARS=10"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var got []salesRow
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, out.String())
+	}
+
+	// 160.50 ARS at 10 per dollar, plus the 20 USD order.
+	want := []salesRow{{Period: "2024-06", Currency: "USD", Orders: 4, Revenue: 36.05, Average: 9.01}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rows = %+v, want %+v", got, want)
+	}
+}
+
+func TestExport_ConvertToAPIRates(t *testing.T) {
+	setupExportAPI(t)
+
+	var calls int
+
+	rates := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		if r.URL.Path != "/EUR" {
+			t.Errorf("rates path = %s", r.URL.Path)
+		}
+
+		_, _ = w.Write([]byte(`{"result":"success","base_code":"EUR","rates":{"EUR":1,"ARS":1000}}`))
+	}))
+	t.Cleanup(rates.Close)
+
+	orig := exchangeRatesURL
+	exchangeRatesURL = rates.URL + "/"
+
+	t.Cleanup(func() { exchangeRatesURL = orig })
+
+	// The fixture's orders carry no currency, so the store's is used.
+	path, err := storeInfoPath("123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeStoreInfo(path, storeInfo{ID: "123", MainCurrency: "ARS", FetchedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = captureStderr(t)
+	out := captureStdout(t)
+
+	if err := Execute([]string{"export", "orders", "--convert-to", "EUR"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 2 ||
+		!strings.Contains(lines[0], `"total":"0.15"`) || !strings.Contains(lines[1], `"total":"0.02"`) {
+		t.Errorf("jsonl = %q", out.String())
+	}
+
+	if calls != 1 {
+		t.Errorf("rates fetched %d times, want once", calls)
+	}
+}
+
+func TestConvertFlags_Usage(t *testing.T) {
+	t.Parallel()
+
+	for _, f := range []ConvertFlags{
+		{ConvertTo: "dollars", RateSource: "api"},
+		{ConvertTo: "USD", RateSource: "bcra"},
+		{ConvertTo: "USD", RateSource: "fixed:"},
+		{ConvertTo: "USD", RateSource: "fixed:ARS=0"},
+		{ConvertTo: "USD", RateSource: "fixed:ARS=350,BRL"},
+	} {
+		if _, err := f.converter(context.Background(), time.Second); ExitCode(err) != ExitUsage {
+			t.Errorf("%+v: err = %v, want usage error", f, err)
+		}
+	}
+
+	c, err := ConvertFlags{ConvertTo: "USD", RateSource: "fixed:BRL=5"}.converter(context.Background(), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.convert(100, "ARS"); ExitCode(err) != ExitUsage {
+		t.Errorf("missing rate err = %v, want usage error", err)
+	}
+}
+
+func TestConvertItem(t *testing.T) {
+	t.Parallel()
+
+	c := &currencyConverter{to: "CLP", fixed: map[string]float64{"": 0.001}}
+	store := func() (string, error) { return "ARS", nil }
+
+	product := map[string]any{"variants": []any{
+		map[string]any{"price": "12.50", "promotional_price": nil, "cost": ""},
+		map[string]any{"price": 3.0},
+	}}
+
+	if err := c.convertItem("products", product, store); err != nil {
+		t.Fatal(err)
+	}
+
+	variants := product["variants"].([]any)
+	if v := variants[0].(map[string]any); v["price"] != "12500.00" || v["promotional_price"] != nil || v["cost"] != "" {
+		t.Errorf("variant 0 = %v", v)
+	}
+
+	if v := variants[1].(map[string]any); v["price"] != 3000.0 {
+		t.Errorf("variant 1 = %v", v)
+	}
+
+	customer := map[string]any{"total_spent": "10", "total_spent_currency": "CLP"}
+	if err := c.convertItem("customers", customer, store); err != nil || customer["total_spent"] != "10.00" {
+		t.Errorf("customer = %v, %v", customer, err)
+	}
+}
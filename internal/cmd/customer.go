@@ -2,10 +2,8 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 	"net/url"
-	"os"
 
 	"github.com/gberlati/nube-cli/internal/api"
 	"github.com/gberlati/nube-cli/internal/outfmt"
@@ -14,22 +12,25 @@ import (
 
 // CustomerCmd groups customer-related commands.
 type CustomerCmd struct {
-	List CustomerListCmd `cmd:"" help:"List customers"`
-	Get  CustomerGetCmd  `cmd:"" help:"Get a customer by ID"`
+	List       CustomerListCmd       `cmd:"" help:"List customers"`
+	Get        CustomerGetCmd        `cmd:"" help:"Get a customer by ID"`
+	Edit       CustomerEditCmd       `cmd:"" help:"Edit a customer in $EDITOR and save the changes"`
+	DataExport CustomerDataExportCmd `cmd:"" name:"data-export" help:"Export all data held for a customer (profile, orders, addresses)"`
+	Anonymize  CustomerAnonymizeCmd  `cmd:"" name:"anonymize" help:"Anonymize a customer's personal data"`
+	Diff       CustomerDiffCmd       `cmd:"" help:"Compare a local JSON file against a customer"`
+	Address    CustomerAddressCmd    `cmd:"" help:"Manage a customer's addresses"`
 }
 
 // CustomerListCmd lists customers with pagination and filters.
 type CustomerListCmd struct {
 	PaginationFlags `embed:""`
+	DateFilterFlags `embed:""`
+	ColumnsFlags    `embed:""`
 
-	SinceID    string `help:"Return customers after this ID" name:"since-id"`
-	Query      string `help:"Search query" short:"q" name:"q"`
-	Email      string `help:"Filter by email" name:"email"`
-	CreatedMin string `help:"Created after (ISO 8601)" name:"created-at-min"`
-	CreatedMax string `help:"Created before (ISO 8601)" name:"created-at-max"`
-	UpdatedMin string `help:"Updated after (ISO 8601)" name:"updated-at-min"`
-	UpdatedMax string `help:"Updated before (ISO 8601)" name:"updated-at-max"`
-	Fields     string `help:"Comma-separated fields to return from API" name:"fields"`
+	SinceID string `help:"Return customers after this ID" name:"since-id"`
+	Query   string `help:"Search query" short:"q" name:"q"`
+	Email   string `help:"Filter by email" name:"email"`
+	Fields  string `help:"Comma-separated fields to return from API" name:"fields"`
 }
 
 func (c *CustomerListCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -45,12 +46,17 @@ func (c *CustomerListCmd) Run(ctx context.Context, flags *RootFlags) error {
 	addQueryParam(q, "since_id", c.SinceID)
 	addQueryParam(q, "q", c.Query)
 	addQueryParam(q, "email", c.Email)
-	addQueryParam(q, "created_at_min", c.CreatedMin)
-	addQueryParam(q, "created_at_max", c.CreatedMax)
-	addQueryParam(q, "updated_at_min", c.UpdatedMin)
-	addQueryParam(q, "updated_at_max", c.UpdatedMax)
 	addQueryParam(q, "fields", c.Fields)
 
+	zone := newZoneResolver(flags, client)
+	if err := c.resolve(ctx, q, zone); err != nil {
+		return err
+	}
+
+	if q, err = c.resume("customers", q); err != nil {
+		return err
+	}
+
 	var items []map[string]any
 
 	if c.WantsAllPages() {
@@ -68,24 +74,33 @@ func (c *CustomerListCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, items)
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), items)
 	}
 
-	w, done := tableWriter(ctx)
-	defer done()
+	money := lazyMoneyFormatter(ctx, flags, client)
 
-	_, _ = fmt.Fprintln(w, "ID\tNAME\tEMAIL\tPHONE\tCREATED")
+	times, err := newTimeFormatter(ctx, flags, zone)
+	if err != nil {
+		return err
+	}
 
-	for _, cust := range items {
-		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", //nolint:gosec // tabwriter, not HTML
-			jsonStr(cust, "id"),
-			jsonStr(cust, "name"),
-			jsonStr(cust, "email"),
-			jsonStr(cust, "phone"),
-			jsonStr(cust, "created_at"),
-		)
+	cols, err := c.pick([]column{
+		textColumn("id", "id"),
+		textColumn("name", "name"),
+		textColumn("email", "email"),
+		textColumn("phone", "phone"),
+		{name: "spent", value: func(cust map[string]any) string {
+			return money().format(jsonStr(cust, "total_spent"), jsonStr(cust, "total_spent_currency"))
+		}},
+		{name: "created", value: func(cust map[string]any) string { return times.format(jsonStr(cust, "created_at")) }},
+		{name: "updated", value: func(cust map[string]any) string { return times.format(jsonStr(cust, "updated_at")) }},
+	}, "id", "name", "email", "phone", "created")
+	if err != nil {
+		return err
 	}
 
+	writeItemsTable(ctx, cols, items)
+
 	_ = u
 
 	return nil
@@ -110,7 +125,7 @@ func (c *CustomerGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 
 	resp, err := client.Get(ctx, "customers/"+c.CustomerID, q) //nolint:bodyclose // DecodeResponse closes body
 	if err != nil {
-		return err
+		return withDidYouMean(ctx, client, customerDidYouMean, c.CustomerID, err)
 	}
 
 	data, err := api.DecodeResponse[map[string]any](resp)
@@ -119,7 +134,12 @@ func (c *CustomerGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, data)
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), data)
+	}
+
+	times, err := newTimeFormatter(ctx, flags, newZoneResolver(flags, client))
+	if err != nil {
+		return err
 	}
 
 	return writeResult(ctx, u,
@@ -127,7 +147,7 @@ func (c *CustomerGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 		kv("name", jsonStr(data, "name")),
 		kv("email", jsonStr(data, "email")),
 		kv("phone", jsonStr(data, "phone")),
-		kv("created_at", jsonStr(data, "created_at")),
-		kv("updated_at", jsonStr(data, "updated_at")),
+		kv("created_at", times.format(jsonStr(data, "created_at"))),
+		kv("updated_at", times.format(jsonStr(data, "updated_at"))),
 	)
 }
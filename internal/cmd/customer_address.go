@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// CustomerAddressCmd manages a customer's saved addresses.
+type CustomerAddressCmd struct {
+	List   CustomerAddressListCmd   `cmd:"" help:"List a customer's addresses"`
+	Add    CustomerAddressAddCmd    `cmd:"" help:"Add an address to a customer"`
+	Update CustomerAddressUpdateCmd `cmd:"" help:"Change fields of a customer's address"`
+	Delete CustomerAddressDeleteCmd `cmd:"" help:"Delete a customer's address"`
+}
+
+// AddressFlags embeds the address fields of add and update.
+type AddressFlags struct {
+	FirstName string `help:"First name" name:"first-name"`
+	LastName  string `help:"Last name" name:"last-name"`
+	Address   string `help:"Street" name:"address"`
+	Number    string `help:"Street number" name:"number"`
+	Floor     string `help:"Floor or apartment" name:"floor"`
+	Locality  string `help:"Locality or neighborhood" name:"locality"`
+	City      string `help:"City" name:"city"`
+	Province  string `help:"Province or state" name:"province"`
+	Zipcode   string `help:"Postal code" name:"zipcode"`
+	Country   string `help:"Country code (e.g. AR)" name:"country"`
+	Phone     string `help:"Phone" name:"phone"`
+}
+
+// fields returns the flags that were set, keyed by API field name.
+func (a AddressFlags) fields() map[string]any {
+	m := map[string]any{}
+
+	for key, value := range map[string]string{
+		"first_name": a.FirstName,
+		"last_name":  a.LastName,
+		"address":    a.Address,
+		"number":     a.Number,
+		"floor":      a.Floor,
+		"locality":   a.Locality,
+		"city":       a.City,
+		"province":   a.Province,
+		"zipcode":    a.Zipcode,
+		"country":    a.Country,
+		"phone":      a.Phone,
+	} {
+		if value != "" {
+			m[key] = value
+		}
+	}
+
+	return m
+}
+
+func addressesPath(customerID string) string {
+	return "customers/" + customerID + "/addresses"
+}
+
+// --- List ---
+
+type CustomerAddressListCmd struct {
+	ColumnsFlags `embed:""`
+
+	CustomerID string `arg:"" name:"customer-id" help:"Customer ID"`
+}
+
+func (c *CustomerAddressListCmd) Run(ctx context.Context, flags *RootFlags) error {
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Get(ctx, addressesPath(c.CustomerID), nil) //nolint:bodyclose // decodeList closes body
+	if err != nil {
+		return err
+	}
+
+	items, err := decodeList(resp)
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), items)
+	}
+
+	cols, err := c.pick([]column{
+		textColumn("id", "id"),
+		{name: "street", value: func(a map[string]any) string {
+			return joinNonEmpty(" ", jsonStr(a, "address"), jsonStr(a, "number"), jsonStr(a, "floor"))
+		}},
+		textColumn("city", "city"),
+		textColumn("province", "province"),
+		textColumn("zipcode", "zipcode"),
+		textColumn("country", "country"),
+		textColumn("default", "default"),
+	}, "id", "street", "city", "province", "zipcode", "country")
+	if err != nil {
+		return err
+	}
+
+	writeItemsTable(ctx, cols, items)
+
+	return nil
+}
+
+// --- Add ---
+
+type CustomerAddressAddCmd struct {
+	AddressFlags `embed:""`
+
+	CustomerID string `arg:"" name:"customer-id" help:"Customer ID"`
+}
+
+func (c *CustomerAddressAddCmd) Run(ctx context.Context, flags *RootFlags) error {
+	fields := c.fields()
+	if fields["address"] == nil || fields["city"] == nil || fields["zipcode"] == nil {
+		return usagef("an address needs at least --address, --city and --zipcode")
+	}
+
+	return writeAddress(ctx, flags, "add", addressesPath(c.CustomerID), fields)
+}
+
+// --- Update ---
+
+type CustomerAddressUpdateCmd struct {
+	AddressFlags `embed:""`
+
+	CustomerID string `arg:"" name:"customer-id" help:"Customer ID"`
+	AddressID  string `arg:"" name:"address-id" help:"Address ID"`
+}
+
+func (c *CustomerAddressUpdateCmd) Run(ctx context.Context, flags *RootFlags) error {
+	fields := c.fields()
+	if len(fields) == 0 {
+		return usagef("nothing to update: pass at least one address field, e.g. --zipcode")
+	}
+
+	return writeAddress(ctx, flags, "update", addressesPath(c.CustomerID)+"/"+c.AddressID, fields)
+}
+
+// writeAddress creates (op "add") or updates an address and prints it.
+func writeAddress(ctx context.Context, flags *RootFlags, op, path string, fields map[string]any) error {
+	u := ui.FromContext(ctx)
+
+	if flags.DryRun {
+		return writeResult(ctx, u,
+			kv("dry_run", true),
+			kv("path", path),
+			kv(op, fields),
+		)
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	body, err := jsonBody(fields)
+	if err != nil {
+		return err
+	}
+
+	write := client.Put
+	if op == "add" {
+		write = client.Post
+	}
+
+	resp, err := write(ctx, path, body) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return err
+	}
+
+	address, err := api.DecodeResponse[map[string]any](resp)
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), address)
+	}
+
+	return writeResult(ctx, u,
+		kv("id", jsonStr(address, "id")),
+		kv("address", joinNonEmpty(" ", jsonStr(address, "address"), jsonStr(address, "number"), jsonStr(address, "floor"))),
+		kv("city", jsonStr(address, "city")),
+		kv("zipcode", jsonStr(address, "zipcode")),
+	)
+}
+
+// --- Delete ---
+
+type CustomerAddressDeleteCmd struct {
+	CustomerID string `arg:"" name:"customer-id" help:"Customer ID"`
+	AddressID  string `arg:"" name:"address-id" help:"Address ID"`
+}
+
+func (c *CustomerAddressDeleteCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	path := addressesPath(c.CustomerID) + "/" + c.AddressID
+
+	if flags.DryRun {
+		return writeResult(ctx, u,
+			kv("dry_run", true),
+			kv("delete", path),
+		)
+	}
+
+	if err := confirmDestructive(flags, fmt.Sprintf("delete address %s of customer %s", c.AddressID, c.CustomerID)); err != nil {
+		return err
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Delete(ctx, path) //nolint:bodyclose // decodeOptionalJSON closes body
+	if err != nil {
+		return err
+	}
+
+	if _, err := decodeOptionalJSON(resp); err != nil {
+		return err
+	}
+
+	return writeResult(ctx, u,
+		kv("deleted", true),
+		kv("customer_id", c.CustomerID),
+		kv("address_id", c.AddressID),
+	)
+}
+
+// joinNonEmpty joins the non-empty parts with sep.
+func joinNonEmpty(sep string, parts ...string) string {
+	kept := make([]string, 0, len(parts))
+
+	for _, p := range parts {
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+
+	return strings.Join(kept, sep)
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestCustomerAddress(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantMethod string
+		wantPath   string
+		wantBody   map[string]any
+	}{
+		{
+			"add",
+			[]string{"customer", "address", "add", "7", "--address", "Av. Corrientes", "--number", "1234", "--city", "CABA", "--zipcode", "1043"},
+			http.MethodPost, "/v1/123/customers/7/addresses",
+			map[string]any{"address": "Av. Corrientes", "number": "1234", "city": "CABA", "zipcode": "1043"},
+		},
+		{
+			"update",
+			[]string{"customer", "address", "update", "7", "55", "--zipcode", "1044"},
+			http.MethodPut, "/v1/123/customers/7/addresses/55",
+			map[string]any{"zipcode": "1044"},
+		},
+		{
+			"delete",
+			[]string{"customer", "address", "delete", "7", "55", "--force"},
+			http.MethodDelete, "/v1/123/customers/7/addresses/55",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				method, path string
+				body         map[string]any
+			)
+
+			setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// Addresses have no GET, so nothing reads them before a write.
+				if r.Method != http.MethodGet {
+					method, path = r.Method, r.URL.Path
+					_ = json.NewDecoder(r.Body).Decode(&body)
+				}
+
+				_, _ = w.Write([]byte(`{"id":55}`))
+			}))
+
+			_ = captureStdout(t)
+
+			if err := Execute(append(tt.args, "--json")); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+
+			if method != tt.wantMethod || path != tt.wantPath {
+				t.Errorf("request = %s %s, want %s %s", method, path, tt.wantMethod, tt.wantPath)
+			}
+
+			if len(body) != len(tt.wantBody) {
+				t.Fatalf("body = %v, want %v", body, tt.wantBody)
+			}
+
+			for k, v := range tt.wantBody {
+				if body[k] != v {
+					t.Errorf("body[%s] = %v, want %v", k, body[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestCustomerAddress_Usage(t *testing.T) {
+	setupMockAPIClient(t, http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+
+	_ = captureStderr(t)
+
+	for _, args := range [][]string{
+		{"customer", "address", "add", "7", "--city", "CABA"},
+		{"customer", "address", "update", "7", "55"},
+	} {
+		if code := ExitCode(Execute(args)); code != ExitUsage {
+			t.Errorf("%v: exit code = %d, want %d", args, code, ExitUsage)
+		}
+	}
+}
+
+func TestCustomerAddressList(t *testing.T) {
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":55,"address":"Av. Corrientes","number":"1234","city":"CABA","province":"CABA","zipcode":"1043","country":"AR"}]`))
+	}))
+
+	stdout := captureStdout(t)
+
+	if err := Execute([]string{"customer", "address", "list", "7", "--plain"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	want := "ID\tSTREET\tCITY\tPROVINCE\tZIPCODE\tCOUNTRY\n55\tAv. Corrientes 1234\tCABA\tCABA\t1043\tAR\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
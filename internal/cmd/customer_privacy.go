@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// anonymizedEmailDomain is a reserved TLD (RFC 2606) so anonymized emails never deliver.
+const anonymizedEmailDomain = "anonymized.invalid"
+
+// CustomerDataExportCmd collects everything the store holds about a customer
+// into a single JSON bundle, for answering data-subject access requests.
+type CustomerDataExportCmd struct {
+	CustomerID string `arg:"" name:"customer-id" help:"Customer ID"`
+	Out        string `help:"Write the bundle to a file instead of stdout" name:"out" short:"o"`
+}
+
+func (c *CustomerDataExportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	bundle, err := buildCustomerBundle(ctx, client, c.CustomerID)
+	if err != nil {
+		return err
+	}
+
+	if c.Out == "" {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), bundle)
+	}
+
+	if err := writeJSONFile(c.Out, bundle); err != nil {
+		return err
+	}
+
+	return writeResult(ctx, u,
+		kv("customer_id", c.CustomerID),
+		kv("orders", len(bundle.Orders)),
+		kv("addresses", len(bundle.Addresses)),
+		kv("path", c.Out),
+	)
+}
+
+// CustomerAnonymizeCmd overwrites a customer's personal data with
+// placeholders: the profile, its billing details and every saved address.
+type CustomerAnonymizeCmd struct {
+	CustomerID string `arg:"" name:"customer-id" help:"Customer ID"`
+	Bundle     string `help:"Save a data-export bundle to this file before anonymizing" name:"bundle"`
+}
+
+func (c *CustomerAnonymizeCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	payload := anonymizedCustomer(c.CustomerID)
+
+	if flags.DryRun {
+		return writeResult(ctx, u,
+			kv("dry_run", true),
+			kv("customer_id", c.CustomerID),
+			kv("update", payload),
+			kv("address_update", anonymizedAddress()),
+		)
+	}
+
+	if c.Bundle != "" {
+		bundle, bundleErr := buildCustomerBundle(ctx, client, c.CustomerID)
+		if bundleErr != nil {
+			return bundleErr
+		}
+
+		if err := writeJSONFile(c.Bundle, bundle); err != nil {
+			return err
+		}
+	}
+
+	if err := confirmDestructive(flags, fmt.Sprintf("anonymize customer %s", c.CustomerID)); err != nil {
+		return err
+	}
+
+	body, err := jsonBody(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Put(ctx, "customers/"+c.CustomerID, body) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return err
+	}
+
+	if _, err := api.DecodeResponse[map[string]any](resp); err != nil {
+		return err
+	}
+
+	addresses, err := anonymizeAddresses(ctx, client, c.CustomerID)
+	if err != nil {
+		return err
+	}
+
+	return writeResult(ctx, u,
+		kv("anonymized", true),
+		kv("customer_id", c.CustomerID),
+		kv("addresses", addresses),
+		kv("bundle", c.Bundle),
+	)
+}
+
+// anonymizeAddresses overwrites each of the customer's saved addresses
+// (the default one included) and returns how many there were.
+func anonymizeAddresses(ctx context.Context, client *api.Client, customerID string) (int, error) {
+	resp, err := client.Get(ctx, addressesPath(customerID), nil) //nolint:bodyclose // decodeList closes body
+	if err != nil {
+		return 0, err
+	}
+
+	addresses, err := decodeList(resp)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, a := range addresses {
+		id := jsonStr(a, "id")
+		if id == "" {
+			continue
+		}
+
+		body, err := jsonBody(anonymizedAddress())
+		if err != nil {
+			return 0, err
+		}
+
+		resp, err := client.Put(ctx, addressesPath(customerID)+"/"+id, body) //nolint:bodyclose // DecodeResponse closes body
+		if err != nil {
+			return 0, fmt.Errorf("anonymize address %s: %w", id, err)
+		}
+
+		if _, err := api.DecodeResponse[map[string]any](resp); err != nil {
+			return 0, fmt.Errorf("anonymize address %s: %w", id, err)
+		}
+	}
+
+	return len(addresses), nil
+}
+
+// customerBundle is the data-export document for a single customer.
+type customerBundle struct {
+	ExportedAt string           `json:"exported_at"`
+	Customer   map[string]any   `json:"customer"`
+	Addresses  []any            `json:"addresses"`
+	Orders     []map[string]any `json:"orders"`
+}
+
+func buildCustomerBundle(ctx context.Context, client *api.Client, customerID string) (customerBundle, error) {
+	resp, err := client.Get(ctx, "customers/"+customerID, nil) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return customerBundle{}, err
+	}
+
+	customer, err := api.DecodeResponse[map[string]any](resp)
+	if err != nil {
+		return customerBundle{}, err
+	}
+
+	q := url.Values{}
+	q.Set("customer_ids", customerID)
+
+	orders, err := api.CollectAllPages(ctx, client, "orders", q, decodeList)
+	if err != nil {
+		return customerBundle{}, err
+	}
+
+	addresses, _ := customer["addresses"].([]any)
+	if addresses == nil {
+		addresses = []any{}
+	}
+
+	if orders == nil {
+		orders = []map[string]any{}
+	}
+
+	return customerBundle{
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Customer:   customer,
+		Addresses:  addresses,
+		Orders:     orders,
+	}, nil
+}
+
+// anonymizedCustomer returns the update payload that replaces personal fields,
+// billing details included. billing_country stays, as it identifies no one.
+func anonymizedCustomer(customerID string) map[string]any {
+	return map[string]any{
+		"name":             "Anonymized",
+		"email":            fmt.Sprintf("customer-%s@%s", customerID, anonymizedEmailDomain),
+		"phone":            "",
+		"identification":   "",
+		"note":             "",
+		"billing_name":     "",
+		"billing_phone":    "",
+		"billing_address":  "",
+		"billing_number":   "",
+		"billing_floor":    "",
+		"billing_locality": "",
+		"billing_zipcode":  "",
+		"billing_city":     "",
+		"billing_province": "",
+	}
+}
+
+// anonymizedAddress returns the update payload for a saved address. The
+// API wants a street on every address, so it gets a placeholder; country,
+// province and city stay, being shared by too many people to identify one.
+func anonymizedAddress() map[string]any {
+	return map[string]any{
+		"first_name": "Anonymized",
+		"last_name":  "",
+		"address":    "Anonymized",
+		"number":     "",
+		"floor":      "",
+		"locality":   "",
+		"zipcode":    "",
+		"phone":      "",
+	}
+}
+
+// writeJSONFile writes v as indented JSON to path with 0600 permissions.
+func writeJSONFile(path string, v any) error {
+	path, err := expandPath(path)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600) //nolint:gosec // user-provided path
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+
+	if err := outfmt.EncodeJSON(f, v); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func customerPrivacyHandler(t *testing.T, gotPut *map[string]any) http.Handler {
+	return customerPrivacyHandlerWithAddresses(t, gotPut, map[string]map[string]any{})
+}
+
+// customerPrivacyHandlerWithAddresses also serves customer 200's addresses
+// 7 and 8, recording the updates to them in addressPuts.
+func customerPrivacyHandlerWithAddresses(t *testing.T, gotPut *map[string]any, addressPuts map[string]map[string]any) http.Handler {
+	t.Helper()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodPut && strings.Contains(r.URL.Path, "customers/200/addresses/"):
+			var body map[string]any
+
+			b, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(b, &body)
+			addressPuts[r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]] = body
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": 7})
+		case strings.Contains(r.URL.Path, "customers/200/addresses/"):
+			// No GET for a single address.
+			w.WriteHeader(http.StatusNotFound)
+		case strings.HasSuffix(r.URL.Path, "customers/200/addresses"):
+			_ = json.NewEncoder(w).Encode([]map[string]any{
+				{"id": 7, "address": "Av. Siempre Viva", "number": "742", "default": true},
+				{"id": 8, "address": "Calle Falsa", "number": "123"},
+			})
+		case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "customers/200"):
+			b, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(b, gotPut)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": 200})
+		case strings.HasSuffix(r.URL.Path, "customers/200"):
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id":        200,
+				"name":      "Juan Perez",
+				"email":     "juan@example.com",
+				"addresses": []any{map[string]any{"address": "Av. Siempre Viva 742"}},
+			})
+		case strings.HasSuffix(r.URL.Path, "orders"):
+			if got := r.URL.Query().Get("customer_ids"); got != "200" {
+				t.Errorf("customer_ids = %q, want 200", got)
+			}
+
+			_ = json.NewEncoder(w).Encode([]map[string]any{{"id": 1}, {"id": 2}})
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+}
+
+func TestCustomerDataExport_JSON(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+	setupMockAPIClient(t, customerPrivacyHandler(t, nil))
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"customer", "data-export", "200"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	var got customerBundle
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v (output: %q)", err, buf.String())
+	}
+
+	if len(got.Orders) != 2 {
+		t.Errorf("orders = %d, want 2", len(got.Orders))
+	}
+
+	if len(got.Addresses) != 1 {
+		t.Errorf("addresses = %d, want 1", len(got.Addresses))
+	}
+
+	if jsonStr(got.Customer, "email") != "juan@example.com" {
+		t.Errorf("customer = %v", got.Customer)
+	}
+}
+
+func TestCustomerDataExport_OutFile(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+	setupMockAPIClient(t, customerPrivacyHandler(t, nil))
+
+	out := filepath.Join(t.TempDir(), "bundle.json")
+
+	_ = captureStdout(t)
+	if err := Execute([]string{"customer", "data-export", "200", "--out", out}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read bundle: %v", err)
+	}
+
+	if !strings.Contains(string(b), "Av. Siempre Viva 742") {
+		t.Errorf("bundle = %s", b)
+	}
+}
+
+func TestCustomerAnonymize_DryRun(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var put map[string]any
+	setupMockAPIClient(t, customerPrivacyHandler(t, &put))
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"customer", "anonymize", "200", "--dry-run", "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if put != nil {
+		t.Errorf("dry run should not send PUT, got %v", put)
+	}
+
+	if !strings.Contains(buf.String(), anonymizedEmailDomain) {
+		t.Errorf("output = %q, want planned update", buf.String())
+	}
+}
+
+func TestCustomerAnonymize_Force(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var put map[string]any
+
+	addressPuts := map[string]map[string]any{}
+	setupMockAPIClient(t, customerPrivacyHandlerWithAddresses(t, &put, addressPuts))
+
+	bundle := filepath.Join(t.TempDir(), "bundle.json")
+
+	_ = captureStdout(t)
+	if err := Execute([]string{"customer", "anonymize", "200", "--force", "--bundle", bundle}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if put["name"] != "Anonymized" || put["billing_address"] != "" || put["billing_zipcode"] != "" {
+		t.Errorf("PUT body = %v", put)
+	}
+
+	if len(addressPuts) != 2 || addressPuts["7"]["address"] != "Anonymized" || addressPuts["8"]["number"] != "" {
+		t.Errorf("address updates = %v, want both addresses overwritten", addressPuts)
+	}
+
+	if _, err := os.Stat(bundle); err != nil {
+		t.Errorf("bundle not written: %v", err)
+	}
+}
+
+func TestCustomerAnonymize_NoInputRefuses(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var put map[string]any
+	setupMockAPIClient(t, customerPrivacyHandler(t, &put))
+
+	_ = captureStdout(t)
+	err := Execute([]string{"customer", "anonymize", "200", "--no-input"})
+	if ExitCode(err) != ExitUsage {
+		t.Fatalf("exit code = %d, want %d (err=%v)", ExitCode(err), ExitUsage, err)
+	}
+
+	if put != nil {
+		t.Errorf("should not send PUT without confirmation, got %v", put)
+	}
+}
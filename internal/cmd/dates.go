@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+)
+
+// DateFilterFlags embeds the created/updated range filters of list commands.
+// Besides ISO 8601 they accept dates and relative forms, resolved in the
+// --tz zone (the store's by default) before they are sent.
+type DateFilterFlags struct {
+	CreatedMin string `help:"Created after (ISO 8601, 2024-06-01, 7d, yesterday, 2024-Q4)" name:"created-at-min"`
+	CreatedMax string `help:"Created before (ISO 8601, 2024-06-01, 7d, yesterday, 2024-Q4)" name:"created-at-max"`
+	UpdatedMin string `help:"Updated after (ISO 8601, 2024-06-01, 7d, yesterday, 2024-Q4)" name:"updated-at-min"`
+	UpdatedMax string `help:"Updated before (ISO 8601, 2024-06-01, 7d, yesterday, 2024-Q4)" name:"updated-at-max"`
+}
+
+// resolve sets the filters given as query params, in the API's format.
+func (d DateFilterFlags) resolve(ctx context.Context, q url.Values, zone *zoneResolver) error {
+	for _, f := range []struct {
+		flag, param, value string
+		end                bool
+	}{
+		{"--created-at-min", "created_at_min", d.CreatedMin, false},
+		{"--created-at-max", "created_at_max", d.CreatedMax, true},
+		{"--updated-at-min", "updated_at_min", d.UpdatedMin, false},
+		{"--updated-at-max", "updated_at_max", d.UpdatedMax, true},
+	} {
+		if f.value == "" {
+			continue
+		}
+
+		// Timestamps with an offset are sent as given, without looking up
+		// the store's zone.
+		if _, err := time.Parse(time.RFC3339, f.value); err == nil {
+			q.Set(f.param, f.value)
+
+			continue
+		}
+
+		loc, err := zone.location(ctx)
+		if err != nil {
+			return err
+		}
+
+		t, err := parseDateFilter(f.value, time.Now().In(loc), f.end)
+		if err != nil {
+			return usagef("%s %q: want ISO 8601, a date (2024-06-01), a time ago (12h, 7d, 2w), today, yesterday or a quarter (2024-Q4)", f.flag, f.value)
+		}
+
+		q.Set(f.param, t.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+var (
+	agoPattern     = regexp.MustCompile(`^(\d+)([hdw])$`)
+	quarterPattern = regexp.MustCompile(`^(\d{4})-[Qq]([1-4])$`)
+)
+
+// parseDateFilter resolves s relative to now, in now's location. Periods
+// (a day, month or quarter) resolve to their first second, or their last
+// when end is set, so a max filter includes the whole period.
+func parseDateFilter(s string, now time.Time, end bool) (time.Time, error) {
+	loc := now.Location()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	period := func(start, next time.Time) time.Time {
+		if end {
+			return next.Add(-time.Second)
+		}
+
+		return start
+	}
+
+	switch strings.ToLower(s) {
+	case "now":
+		return now, nil
+	case "today":
+		return period(day, day.AddDate(0, 0, 1)), nil
+	case "yesterday":
+		return period(day.AddDate(0, 0, -1), day), nil
+	}
+
+	if m := agoPattern.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+
+		switch m[2] {
+		case "h":
+			return now.Add(-time.Duration(n) * time.Hour), nil
+		case "d":
+			return now.AddDate(0, 0, -n), nil
+		default:
+			return now.AddDate(0, 0, -7*n), nil
+		}
+	}
+
+	if m := quarterPattern.FindStringSubmatch(s); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		q, _ := strconv.Atoi(m[2])
+		start := time.Date(year, time.Month(3*q-2), 1, 0, 0, 0, 0, loc)
+
+		return period(start, start.AddDate(0, 3, 0)), nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02T15:04:05", s, loc); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation(time.DateOnly, s, loc); err == nil {
+		return period(t, t.AddDate(0, 0, 1)), nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01", s, loc); err == nil {
+		return period(t, t.AddDate(0, 1, 0)), nil
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized date %q", s)
+}
+
+// storeTimezones maps store countries to their main time zone.
+var storeTimezones = map[string]string{
+	"AR": "America/Argentina/Buenos_Aires",
+	"BR": "America/Sao_Paulo",
+	"CL": "America/Santiago",
+	"CO": "America/Bogota",
+	"MX": "America/Mexico_City",
+	"PE": "America/Lima",
+	"UY": "America/Montevideo",
+}
+
+// zoneResolver resolves --tz once per command: "local", "store" (the
+// default) or an IANA zone name.
+type zoneResolver struct {
+	flags  *RootFlags
+	client *api.Client
+	loc    *time.Location
+}
+
+func newZoneResolver(flags *RootFlags, client *api.Client) *zoneResolver {
+	return &zoneResolver{flags: flags, client: client}
+}
+
+func (z *zoneResolver) location(ctx context.Context) (*time.Location, error) {
+	if z.loc != nil {
+		return z.loc, nil
+	}
+
+	switch tz := z.flags.TZ; tz {
+	case "local":
+		z.loc = time.Local
+	case "", "store":
+		z.loc = z.storeLocation(ctx)
+	default:
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, usagef("--tz %q: want local, store or an IANA zone such as America/Sao_Paulo", tz)
+		}
+
+		z.loc = loc
+	}
+
+	return z.loc, nil
+}
+
+// storeLocation is the zone of the store's country, or UTC when it can't be
+// told.
+func (z *zoneResolver) storeLocation(ctx context.Context) *time.Location {
+	info, err := loadStoreInfo(ctx, z.client)
+	if err != nil {
+		slog.DebugContext(ctx, "store settings unavailable; using UTC", "err", err)
+
+		return time.UTC
+	}
+
+	name, ok := storeTimezones[info.Country]
+	if !ok {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// timeFormatter shows API timestamps in the --tz zone. Without --tz, and
+// always in JSON, timestamps are left as the API returns them.
+type timeFormatter struct {
+	loc *time.Location
+}
+
+func newTimeFormatter(ctx context.Context, flags *RootFlags, zone *zoneResolver) (*timeFormatter, error) {
+	if flags.TZ == "" || outfmt.IsJSON(ctx) {
+		return &timeFormatter{}, nil
+	}
+
+	loc, err := zone.location(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &timeFormatter{loc: loc}, nil
+}
+
+// apiTimeLayouts are the timestamp formats the API returns.
+var apiTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05-0700"}
+
+func (f *timeFormatter) format(s string) string {
+	if f.loc == nil {
+		return s
+	}
+
+	for _, layout := range apiTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.In(f.loc).Format("2006-01-02 15:04 MST")
+		}
+	}
+
+	return s
+}
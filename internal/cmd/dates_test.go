@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestParseDateFilter(t *testing.T) {
+	t.Parallel()
+
+	loc := time.FixedZone("-03", -3*3600)
+	now := time.Date(2024, 11, 20, 15, 30, 0, 0, loc)
+
+	tests := []struct {
+		in   string
+		end  bool
+		want string
+	}{
+		{"now", false, "2024-11-20T15:30:00-03:00"},
+		{"today", false, "2024-11-20T00:00:00-03:00"},
+		{"yesterday", false, "2024-11-19T00:00:00-03:00"},
+		{"yesterday", true, "2024-11-19T23:59:59-03:00"},
+		{"7d", false, "2024-11-13T15:30:00-03:00"},
+		{"12h", false, "2024-11-20T03:30:00-03:00"},
+		{"2w", true, "2024-11-06T15:30:00-03:00"},
+		{"2024-Q4", false, "2024-10-01T00:00:00-03:00"},
+		{"2024-q1", true, "2024-03-31T23:59:59-03:00"},
+		{"2024-06-01", false, "2024-06-01T00:00:00-03:00"},
+		{"2024-06-01", true, "2024-06-01T23:59:59-03:00"},
+		{"2024-02", true, "2024-02-29T23:59:59-03:00"},
+		{"2024-06-01T08:00:00", true, "2024-06-01T08:00:00-03:00"},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDateFilter(tt.in, now, tt.end)
+		if err != nil {
+			t.Errorf("parseDateFilter(%q) error = %v", tt.in, err)
+
+			continue
+		}
+
+		if s := got.Format(time.RFC3339); s != tt.want {
+			t.Errorf("parseDateFilter(%q, end=%v) = %s, want %s", tt.in, tt.end, s, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "last week", "7y", "2024-Q5", "06/01/2024"} {
+		if _, err := parseDateFilter(bad, now, false); err == nil {
+			t.Errorf("parseDateFilter(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestTimeFormatter(t *testing.T) {
+	t.Parallel()
+
+	f := &timeFormatter{loc: time.FixedZone("-03", -3*3600)}
+
+	for in, want := range map[string]string{
+		"2025-01-01T12:00:00+0000": "2025-01-01 09:00 -03",
+		"2025-01-01T12:00:00Z":     "2025-01-01 09:00 -03",
+		"":                         "",
+		"soon":                     "soon",
+	} {
+		if got := f.format(in); got != want {
+			t.Errorf("format(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	if got := (&timeFormatter{}).format("2025-01-01T12:00:00+0000"); got != "2025-01-01T12:00:00+0000" {
+		t.Errorf("without --tz: %q", got)
+	}
+}
+
+// dateStore serves an Argentine store and records the order list query.
+func dateStore(t *testing.T, storeGets *atomic.Int32, query *string) http.Handler {
+	t.Helper()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/123/store":
+			storeGets.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": 123, "country": "AR", "main_currency": "ARS"})
+		case "/v1/123/orders":
+			*query = r.URL.RawQuery
+			_ = json.NewEncoder(w).Encode([]map[string]any{
+				{"id": 101, "number": "1001", "total": "10.00", "created_at": "2024-10-01T12:00:00+0000"},
+			})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	})
+}
+
+func TestOrderList_RelativeDates(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var (
+		storeGets atomic.Int32
+		query     string
+	)
+
+	setupMockAPIClient(t, dateStore(t, &storeGets, &query))
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"order", "list", "--page", "1", "--created-at-min", "2024-Q4", "--tz", "store"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if !strings.Contains(query, "created_at_min=2024-10-01T00%3A00%3A00-03%3A00") {
+		t.Errorf("query = %q, want Q4 start in the store's zone", query)
+	}
+
+	if out := buf.String(); !strings.Contains(out, "2024-10-01 09:00 -03") {
+		t.Errorf("output = %q, want created_at in the store's zone", out)
+	}
+
+	// Timestamps with an offset go out unchanged.
+	buf = captureStdout(t)
+	if err := Execute([]string{"order", "list", "--page", "1", "--created-at-min", "2024-06-01T00:00:00Z", "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	_ = buf.String()
+
+	if !strings.Contains(query, "created_at_min=2024-06-01T00%3A00%3A00Z") {
+		t.Errorf("query = %q", query)
+	}
+}
+
+func TestOrderList_BadDates(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var (
+		storeGets atomic.Int32
+		query     string
+	)
+
+	setupMockAPIClient(t, dateStore(t, &storeGets, &query))
+
+	for _, args := range [][]string{
+		{"order", "list", "--created-at-min", "last week"},
+		{"order", "list", "--created-at-min", "7d", "--tz", "Mars/Olympus"},
+	} {
+		if err := Execute(args); ExitCode(err) != ExitUsage {
+			t.Errorf("%v: ExitCode = %d, want %d (err %v)", args, ExitCode(err), ExitUsage, err)
+		}
+	}
+
+	if query != "" {
+		t.Errorf("orders requested with query %q, want no request", query)
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/redact"
+)
+
+// DevCmd groups helpers for working on nube itself.
+type DevCmd struct {
+	Record DevRecordCmd `cmd:"" help:"Run a command and save its API traffic as scrubbed fixtures for tests"`
+}
+
+// DevRecordCmd runs a command in record mode with every request and
+// response scrubbed of credentials and personal data, so the fixtures can
+// be committed and replayed with --mock-dir.
+type DevRecordCmd struct {
+	Command string `help:"Command line to record, e.g. \"product list --page 1\"" name:"command" required:""`
+	Out     string `help:"Fixture directory" name:"out" default:"testdata" type:"path"`
+}
+
+func (c *DevRecordCmd) Run(ctx context.Context, flags *RootFlags) error {
+	args, err := splitCommandLine(c.Command)
+	if err != nil {
+		return newUsageError(err)
+	}
+
+	if len(args) == 0 {
+		return usagef("--command is empty")
+	}
+
+	if flags.MockDir != "" || flags.Record != "" {
+		return usagef("dev record talks to the store and writes its own fixtures; drop --mock-dir and --record")
+	}
+
+	secrets := newRedactor("dev record")
+
+	var (
+		mu    sync.Mutex
+		saved = map[string]bool{}
+	)
+
+	recordCtx := api.WithRecordOptions(withNested(ctx), api.RecordOptions{
+		Scrub:    func(b []byte) []byte { return []byte(secrets.String(string(redact.ScrubPII(b)))) },
+		Requests: true,
+		Saved: func(path string) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			saved[path] = true
+		},
+	})
+
+	nested := *flags
+	nested.Record = c.Out
+
+	// The command's own output isn't scrubbed, so it isn't shown; its
+	// errors are.
+	runErr := execute(recordCtx, subcommandArgs(&nested, args), io.Discard, stderrFrom(ctx))
+
+	files := make([]string, 0, len(saved))
+
+	for _, p := range slices.Sorted(maps.Keys(saved)) {
+		if rel, err := filepath.Rel(c.Out, p); err == nil {
+			p = rel
+		}
+
+		files = append(files, filepath.ToSlash(p))
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"dir": c.Out, "fixtures": files}); err != nil {
+			return err
+		}
+	} else {
+		for _, f := range files {
+			_, _ = fmt.Fprintln(stdoutFrom(ctx), f)
+		}
+	}
+
+	// Failed requests are recorded too, which is handy for error tests;
+	// the command's exit code is passed on.
+	if runErr != nil {
+		return &ExitErr{Code: ExitCode(runErr)}
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestDevRecord(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok-0123456789abcdef"}}, "test")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":9,"name":"Ana Paz","email":"ana@mail.com","note":"token tok-0123456789abcdef"}]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	// Like the real client, honor --record and --mock-dir.
+	orig := newAPIClient
+	newAPIClient = func(flags *RootFlags) (*api.Client, error) {
+		return api.New("123", "test-token", api.WithBaseURL(srv.URL+"/v1"), api.WithHTTPClient(srv.Client()), api.WithRecordDir(flags.Record), api.WithMockDir(flags.MockDir)), nil
+	}
+
+	t.Cleanup(func() { newAPIClient = orig })
+
+	dir := t.TempDir()
+	out := captureStdout(t)
+
+	if err := Execute([]string{"--json", "dev", "record", "--command", "customer list --page 1", "--out", dir}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var res struct {
+		Fixtures []string `json:"fixtures"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &res); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, out.String())
+	}
+
+	if len(res.Fixtures) != 1 || !strings.HasPrefix(res.Fixtures[0], "GET/customers@") {
+		t.Fatalf("fixtures = %v", res.Fixtures)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, res.Fixtures[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fix api.Fixture
+	if err := json.Unmarshal(b, &fix); err != nil {
+		t.Fatal(err)
+	}
+
+	if fix.Request == nil || fix.Request.Method != http.MethodGet || fix.Request.Path != "customers" {
+		t.Errorf("request = %+v", fix.Request)
+	}
+
+	for _, leak := range []string{"Ana Paz", "ana@mail.com", "tok-0123456789abcdef"} {
+		if strings.Contains(string(fix.Body), leak) {
+			t.Errorf("fixture body leaks %q: %s", leak, fix.Body)
+		}
+	}
+
+	// The fixtures replay like any other.
+	out = captureStdout(t)
+
+	if err := Execute([]string{"--json", "--mock-dir", dir, "customer", "list", "--page", "1"}); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "@example.com") {
+		t.Errorf("replayed = %s", out.String())
+	}
+}
+
+func TestDevRecord_RefusesMockDir(t *testing.T) {
+	setupConfigDir(t)
+
+	_ = captureStderr(t)
+
+	err := Execute([]string{"--mock-dir", t.TempDir(), "dev", "record", "--command", "shop"})
+	if ExitCode(err) != ExitUsage {
+		t.Errorf("err = %v, want usage error", err)
+	}
+}
@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/errfmt"
+	"github.com/gberlati/nube-cli/internal/i18n"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+)
+
+// A did-you-mean search compares this many results and names at most
+// didYouMeanShown of them.
+const (
+	didYouMeanPerPage = 20
+	didYouMeanShown   = 3
+)
+
+// didYouMean says how a get command looks for what the user may have meant
+// when its ID or SKU is not found.
+type didYouMean struct {
+	// resource is the searchResources entry searched with the term.
+	resource string
+	// message formats the error from the term and the matches.
+	message string
+	// command is the get command a match is fetched with.
+	command    string
+	candidates func(item map[string]any) []didYouMeanCandidate
+}
+
+// didYouMeanCandidate is something a search result can be fetched by.
+type didYouMeanCandidate struct {
+	arg   string
+	label string
+	// keys are compared with the term; the closest decides the rank.
+	keys []string
+}
+
+var (
+	productDidYouMean = didYouMean{
+		resource: "products",
+		message:  "product %s not found; did you mean %s?",
+		command:  "nube product get",
+		candidates: func(p map[string]any) []didYouMeanCandidate {
+			keys := []string{jsonStr(p, "id"), extractI18n(p, "name"), extractI18n(p, "handle")}
+			return []didYouMeanCandidate{{arg: jsonStr(p, "id"), label: extractI18n(p, "name"), keys: append(keys, variantSKUs(p)...)}}
+		},
+	}
+	skuDidYouMean = didYouMean{
+		resource: "products",
+		message:  "no product has SKU %s; did you mean %s?",
+		command:  "nube product get-by-sku",
+		candidates: func(p map[string]any) []didYouMeanCandidate {
+			var out []didYouMeanCandidate
+			for _, sku := range variantSKUs(p) {
+				out = append(out, didYouMeanCandidate{arg: sku, label: extractI18n(p, "name"), keys: []string{sku}})
+			}
+
+			return out
+		},
+	}
+	orderDidYouMean = didYouMean{
+		resource: "orders",
+		message:  "order %s not found; did you mean %s?",
+		command:  "nube order get",
+		candidates: func(o map[string]any) []didYouMeanCandidate {
+			return []didYouMeanCandidate{{arg: jsonStr(o, "id"), label: "#" + jsonStr(o, "number"), keys: []string{jsonStr(o, "id"), jsonStr(o, "number")}}}
+		},
+	}
+	customerDidYouMean = didYouMean{
+		resource: "customers",
+		message:  "customer %s not found; did you mean %s?",
+		command:  "nube customer get",
+		candidates: func(c map[string]any) []didYouMeanCandidate {
+			return []didYouMeanCandidate{{arg: jsonStr(c, "id"), label: jsonStr(c, "name"), keys: []string{jsonStr(c, "id"), jsonStr(c, "name"), jsonStr(c, "email")}}}
+		},
+	}
+)
+
+// withDidYouMean turns the not-found err of a get for term into one naming
+// close matches, found with one search request, and suggests fetching the
+// closest. Other errors, a failed search and one with nothing close return
+// err unchanged.
+func withDidYouMean(ctx context.Context, client *api.Client, d didYouMean, term string, err error) error {
+	if !api.IsNotFoundError(err) || !didYouMeanEnabled(ctx) {
+		return err
+	}
+
+	matches := d.closest(ctx, client, term)
+	if len(matches) == 0 {
+		return err
+	}
+
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = m.arg
+		if m.label != "" && m.label != m.arg {
+			names[i] += fmt.Sprintf(" '%s'", m.label)
+		}
+	}
+
+	tr := i18n.FromContext(ctx)
+	msg := tr.Sprintf(d.message, term, strings.Join(names, ", "))
+
+	return errfmt.WithSuggestion(errfmt.NewUserFacingError(msg, err), errfmt.Suggestion{
+		Command: d.command + " " + matches[0].arg,
+		Reason:  tr.T("closest match"),
+	})
+}
+
+// didYouMeanEnabled reads config did_you_mean; unset, only table output
+// of commands run by hand (not by batch, bench or a schedule) searches,
+// since scripts would rather fail fast.
+func didYouMeanEnabled(ctx context.Context) bool {
+	if cfg, err := config.ReadConfig(); err == nil && cfg.DidYouMean != nil {
+		return *cfg.DidYouMean
+	}
+
+	return !outfmt.IsJSON(ctx) && !outfmt.IsPlain(ctx) && !isNested(ctx)
+}
+
+// closest searches for term and returns the candidates within a few edits
+// of it, closest first.
+func (d didYouMean) closest(ctx context.Context, client *api.Client, term string) []didYouMeanCandidate {
+	q := url.Values{"q": {term}, "per_page": {strconv.Itoa(didYouMeanPerPage)}}
+
+	resp, err := client.Get(ctx, searchResources[d.resource].path, q) //nolint:bodyclose // decodeList closes body
+	if err != nil {
+		return nil
+	}
+
+	items, err := decodeList(resp)
+	if err != nil {
+		return nil
+	}
+
+	type ranked struct {
+		didYouMeanCandidate
+		distance int
+	}
+
+	maxDistance := max(1, len([]rune(term))/3)
+
+	var found []ranked
+
+	for _, item := range items {
+		for _, c := range d.candidates(item) {
+			if c.arg == "" || slices.ContainsFunc(found, func(r ranked) bool { return r.arg == c.arg }) {
+				continue
+			}
+
+			best := maxDistance + 1
+			for _, k := range c.keys {
+				if k != "" {
+					best = min(best, matchDistance(term, k))
+				}
+			}
+
+			if best <= maxDistance {
+				found = append(found, ranked{c, best})
+			}
+		}
+	}
+
+	slices.SortStableFunc(found, func(a, b ranked) int { return a.distance - b.distance })
+
+	out := make([]didYouMeanCandidate, 0, didYouMeanShown)
+	for _, r := range found[:min(len(found), didYouMeanShown)] {
+		out = append(out, r.didYouMeanCandidate)
+	}
+
+	return out
+}
+
+// matchDistance is how far key is from what the user typed: the edit
+// distance, ignoring case, or 1 when key contains a term of 3 or more
+// characters.
+func matchDistance(term, key string) int {
+	term, key = strings.ToLower(term), strings.ToLower(key)
+
+	if len([]rune(term)) >= 3 && strings.Contains(key, term) {
+		return min(1, editDistance(term, key))
+	}
+
+	return editDistance(term, key)
+}
+
+// editDistance is the Levenshtein distance between a and b, in runes.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// variantSKUs returns the SKUs of a product's variants that have one.
+func variantSKUs(p map[string]any) []string {
+	arr, _ := p["variants"].([]any)
+
+	var skus []string
+
+	for _, v := range arr {
+		variant, _ := v.(map[string]any)
+		if sku := jsonStr(variant, "sku"); sku != "" {
+			skus = append(skus, sku)
+		}
+	}
+
+	return skus
+}
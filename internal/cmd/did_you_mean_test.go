@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/errfmt"
+)
+
+func TestDidYouMean(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var searches []string
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/123/products" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		searches = append(searches, r.URL.Query().Get("q"))
+
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"id": 99, "name": map[string]any{"es": "Remera"}, "variants": []any{map[string]any{"sku": "REM-1"}}},
+			{"id": 12340, "name": map[string]any{"es": "Zapato Azul"}, "variants": []any{map[string]any{"sku": "ZAP-AZ"}}},
+		})
+	}))
+
+	_ = captureStdout(t)
+
+	err := Execute([]string{"product", "get", "1234"})
+	if ExitCode(err) != ExitNotFound {
+		t.Fatalf("exit code = %d, want %d (err %v)", ExitCode(err), ExitNotFound, err)
+	}
+
+	if msg := errfmt.Format(err); msg != "product 1234 not found; did you mean 12340 'Zapato Azul'?" {
+		t.Errorf("message = %q", msg)
+	}
+
+	if s := errfmt.SuggestionFor(err); s == nil || s.Command != "nube product get 12340" {
+		t.Errorf("suggestion = %+v", s)
+	}
+
+	err = Execute([]string{"product", "get-by-sku", "ZAP-A"})
+	if msg := errfmt.Format(err); !strings.Contains(msg, "did you mean ZAP-AZ 'Zapato Azul'?") {
+		t.Errorf("get-by-sku message = %q", msg)
+	}
+
+	searches = nil
+
+	if err := Execute([]string{"product", "get", "1234", "--json"}); ExitCode(err) != ExitNotFound || len(searches) != 0 {
+		t.Errorf("--json: exit code = %d, searches = %v, want no search", ExitCode(err), searches)
+	}
+
+	off := false
+	if err := config.WriteConfig(config.File{DidYouMean: &off}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Execute([]string{"product", "get", "1234"}); errfmt.Format(err) != "resource not found" || len(searches) != 0 {
+		t.Errorf("did_you_mean false: message = %q, searches = %v", errfmt.Format(err), searches)
+	}
+}
+
+func TestMatchDistance(t *testing.T) {
+	tests := []struct {
+		term, key string
+		want      int
+	}{
+		{"1234", "12340", 1},
+		{"1234", "1243", 2},
+		{"zapato", "Zapato Azul", 1},
+		{"ZAP-A", "zap-az", 1},
+		{"ab", "xaby", 2},
+	}
+
+	for _, tt := range tests {
+		if got := matchDistance(tt.term, tt.key); got != tt.want {
+			t.Errorf("matchDistance(%q, %q) = %d, want %d", tt.term, tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/jsondiff"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// DiffFlags are shared by the per-resource diff commands.
+type DiffFlags struct {
+	File string `help:"Local JSON or YAML file to compare ('-' for stdin)" name:"file" short:"f" required:""`
+	Full bool   `help:"Also report remote fields missing from the file (default: only compare fields the file sets)" name:"full"`
+}
+
+type ProductDiffCmd struct {
+	ProductID string `arg:"" name:"product-id" help:"Product ID"`
+	DiffFlags `embed:""`
+}
+
+func (c *ProductDiffCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return runResourceDiff(ctx, flags, "products/"+c.ProductID, c.DiffFlags)
+}
+
+type CategoryDiffCmd struct {
+	CategoryID string `arg:"" name:"category-id" help:"Category ID"`
+	DiffFlags  `embed:""`
+}
+
+func (c *CategoryDiffCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return runResourceDiff(ctx, flags, "categories/"+c.CategoryID, c.DiffFlags)
+}
+
+type CustomerDiffCmd struct {
+	CustomerID string `arg:"" name:"customer-id" help:"Customer ID"`
+	DiffFlags  `embed:""`
+}
+
+func (c *CustomerDiffCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return runResourceDiff(ctx, flags, "customers/"+c.CustomerID, c.DiffFlags)
+}
+
+// runResourceDiff prints the changes that would turn the remote resource at
+// path into the local file: colorized lines, or a JSON Patch with --json.
+func runResourceDiff(ctx context.Context, flags *RootFlags, path string, df DiffFlags) error {
+	u := ui.FromContext(ctx)
+
+	local, err := readJSONObjectFile(df.File)
+	if err != nil {
+		return err
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Get(ctx, path, nil) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return err
+	}
+
+	remote, err := api.DecodeResponse[map[string]any](resp)
+	if err != nil {
+		return err
+	}
+
+	ops := diffResource(remote, local, df.Full)
+
+	if outfmt.IsJSON(ctx) {
+		if ops == nil {
+			ops = []jsondiff.Op{}
+		}
+
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), ops)
+	}
+
+	if len(ops) == 0 {
+		if u != nil {
+			u.Err().Printf("no differences")
+		}
+
+		return nil
+	}
+
+	printDiff(ctx, u, ops)
+
+	return nil
+}
+
+// diffResource compares remote to local. Unless full is set, only top-level
+// fields present in local are compared, so partial files don't report every
+// other remote field as removed.
+func diffResource(remote, local map[string]any, full bool) []jsondiff.Op {
+	from := remote
+
+	if !full {
+		from = make(map[string]any, len(local))
+
+		for k := range local {
+			if v, ok := remote[k]; ok {
+				from[k] = v
+			}
+		}
+	}
+
+	return jsondiff.Diff(from, local)
+}
+
+func printDiff(ctx context.Context, u *ui.UI, ops []jsondiff.Op) {
+	if u == nil || outfmt.IsPlain(ctx) {
+		w := stdoutFrom(ctx)
+
+		for _, op := range ops {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", op.Op, op.Path, diffValue(op.Old), diffValue(op.Value))
+		}
+
+		return
+	}
+
+	printOps(u, "", ops)
+}
+
+// printOps prints ops as colorized -/+ lines, each prefixed with indent.
+func printOps(u *ui.UI, indent string, ops []jsondiff.Op) {
+	for _, op := range ops {
+		switch op.Op {
+		case jsondiff.OpAdd:
+			u.Out().Addedf("%s+ %s: %s", indent, op.Path, diffValue(op.Value))
+		case jsondiff.OpRemove:
+			u.Out().Removedf("%s- %s: %s", indent, op.Path, diffValue(op.Old))
+		default:
+			u.Out().Removedf("%s- %s: %s", indent, op.Path, diffValue(op.Old))
+			u.Out().Addedf("%s+ %s: %s", indent, op.Path, diffValue(op.Value))
+		}
+	}
+}
+
+// diffValue renders a JSON value compactly for diff output.
+func diffValue(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(b)
+}
+
+// readJSONObjectFile reads a JSON or YAML object from path ('-' for stdin).
+func readJSONObjectFile(path string) (map[string]any, error) {
+	b, err := readInputFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err = jsonFromYAML(b)
+	if err != nil {
+		return nil, usagef("%s: %v", path, err)
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, usagef("%s: expected an object: %v", path, err)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func setupDiffRemote(t *testing.T, wantPath string) {
+	t.Helper()
+
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != wantPath {
+			t.Errorf("path = %q, want %q", r.URL.Path, wantPath)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":        1,
+			"name":      map[string]any{"es": "Remera", "pt": "Camisa"},
+			"published": true,
+			"tags":      "verano",
+		})
+	}))
+}
+
+func writeLocalJSON(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "local.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestProductDiff_JSONPatch(t *testing.T) {
+	setupDiffRemote(t, "/v1/123/products/1")
+
+	file := writeLocalJSON(t, `{"name":{"es":"Remera roja","pt":"Camisa"},"published":true,"handle":"remera"}`)
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"product", "diff", "1", "--file", file, "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	var ops []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &ops); err != nil {
+		t.Fatalf("unmarshal: %v (output: %q)", err, buf.String())
+	}
+
+	want := []string{"add /handle", "replace /name/es"}
+	if len(ops) != len(want) {
+		t.Fatalf("ops = %v, want %v", ops, want)
+	}
+
+	for i, w := range want {
+		if got := ops[i]["op"].(string) + " " + ops[i]["path"].(string); got != w {
+			t.Errorf("op[%d] = %q, want %q", i, got, w)
+		}
+	}
+}
+
+func TestProductDiff_YAMLFile(t *testing.T) {
+	setupDiffRemote(t, "/v1/123/products/1")
+
+	file := writeLocalJSON(t, "name:\n  es: Remera\n  pt: Camisa\npublished: false\n")
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"product", "diff", "1", "--file", file, "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	var ops []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &ops); err != nil {
+		t.Fatalf("unmarshal: %v (output: %q)", err, buf.String())
+	}
+
+	if len(ops) != 1 || ops[0]["path"] != "/published" {
+		t.Errorf("ops = %v, want only /published", ops)
+	}
+}
+
+func TestCategoryDiff_Full(t *testing.T) {
+	setupDiffRemote(t, "/v1/123/categories/1")
+
+	file := writeLocalJSON(t, `{"id":1,"name":{"es":"Remera","pt":"Camisa"},"published":true}`)
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"category", "diff", "1", "--file", file, "--full", "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `"path": "/tags"`) || !strings.Contains(buf.String(), `"op": "remove"`) {
+		t.Errorf("output = %q, want removal of /tags", buf.String())
+	}
+}
+
+func TestCustomerDiff_Text(t *testing.T) {
+	setupDiffRemote(t, "/v1/123/customers/1")
+
+	file := writeLocalJSON(t, `{"tags":"invierno"}`)
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"customer", "diff", "1", "--file", file}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, `- /tags: "verano"`) || !strings.Contains(out, `+ /tags: "invierno"`) {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestProductDiff_NoDifferences(t *testing.T) {
+	setupDiffRemote(t, "/v1/123/products/1")
+
+	file := writeLocalJSON(t, `{"published":true}`)
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"product", "diff", "1", "-f", file, "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("output = %q, want []", buf.String())
+	}
+}
+
+func TestProductDiff_InvalidFile(t *testing.T) {
+	setupConfigDir(t)
+	_ = captureStderr(t)
+
+	file := writeLocalJSON(t, `[1,2]`)
+
+	err := Execute([]string{"product", "diff", "1", "--file", file})
+	if ExitCode(err) != ExitUsage {
+		t.Fatalf("exit code = %d, want %d", ExitCode(err), ExitUsage)
+	}
+}
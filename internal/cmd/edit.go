@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/jsondiff"
+	"github.com/gberlati/nube-cli/internal/openapi"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+type ProductEditCmd struct {
+	ProductID string `arg:"" name:"product-id" help:"Product ID"`
+}
+
+func (c *ProductEditCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return runResourceEdit(ctx, flags, "products/"+c.ProductID)
+}
+
+type OrderEditCmd struct {
+	OrderID string `arg:"" name:"order-id" help:"Order ID"`
+}
+
+func (c *OrderEditCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return runResourceEdit(ctx, flags, "orders/"+c.OrderID)
+}
+
+type CustomerEditCmd struct {
+	CustomerID string `arg:"" name:"customer-id" help:"Customer ID"`
+}
+
+func (c *CustomerEditCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return runResourceEdit(ctx, flags, "customers/"+c.CustomerID)
+}
+
+type CategoryEditCmd struct {
+	CategoryID string `arg:"" name:"category-id" help:"Category ID"`
+}
+
+func (c *CategoryEditCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return runResourceEdit(ctx, flags, "categories/"+c.CategoryID)
+}
+
+// runEditor opens path in the user's editor and waits for it to exit.
+// Tests replace it.
+var runEditor = func(ctx context.Context, path string) error {
+	args := strings.Fields(editorCommand())
+
+	cmd := exec.CommandContext(ctx, args[0], append(args[1:], path)...) //nolint:gosec // the user's own editor
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s: %w", args[0], err)
+	}
+
+	return nil
+}
+
+// editorCommand is $VISUAL, then $EDITOR, then the platform's basic editor.
+func editorCommand() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if v := strings.TrimSpace(os.Getenv(env)); v != "" {
+			return v
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+
+	return "vi"
+}
+
+// runResourceEdit fetches the resource at path, opens it in the editor and
+// PUTs back the top-level fields that changed; --yaml edits it as YAML.
+// Removing a field in the editor leaves it as it is.
+func runResourceEdit(ctx context.Context, flags *RootFlags, path string) error {
+	u := ui.FromContext(ctx)
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Get(ctx, path, nil) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return err
+	}
+
+	remote, err := api.DecodeResponse[map[string]any](resp)
+	if err != nil {
+		return err
+	}
+
+	original, ext, err := encodeForEdit(remote, flags.YAML)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp("", "nube-edit-*"+ext)
+	if err != nil {
+		return fmt.Errorf("create edit file: %w", err)
+	}
+
+	tmp := f.Name()
+
+	_, err = f.Write(original)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp)
+
+		return fmt.Errorf("write edit file: %w", err)
+	}
+
+	// The file is kept when something goes wrong after the editor, so the
+	// edits aren't lost.
+	keep := false
+
+	defer func() {
+		if !keep {
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	if err := runEditor(ctx, tmp); err != nil {
+		return err
+	}
+
+	edited, err := os.ReadFile(tmp) //nolint:gosec // our own temp file
+	if err != nil {
+		return fmt.Errorf("read edit file: %w", err)
+	}
+
+	if bytes.Equal(bytes.TrimSpace(edited), bytes.TrimSpace(original)) {
+		u.Err().Println("edit cancelled, no changes made")
+
+		return nil
+	}
+
+	local, err := decodeEdited(edited)
+	if err != nil {
+		keep = true
+
+		return newUsageError(fmt.Errorf("%w (your edits are in %s)", err, tmp))
+	}
+
+	changes := diffResource(remote, stripReadOnly(local), false)
+	if len(changes) == 0 {
+		u.Err().Println("no changes to save")
+
+		return nil
+	}
+
+	update := changedFields(local, changes)
+
+	body, err := json.Marshal(update)
+	if err != nil {
+		return fmt.Errorf("encode update: %w", err)
+	}
+
+	if err := validateEdit(path, body); err != nil {
+		keep = true
+
+		return newUsageError(fmt.Errorf("%w\nyour edits are in %s", err, tmp))
+	}
+
+	if !flags.DryRun {
+		resp, err := client.Put(ctx, path, bytes.NewReader(body)) //nolint:bodyclose // decodeOptionalJSON closes body
+		if err != nil {
+			keep = true
+
+			return fmt.Errorf("%w (your edits are in %s)", err, tmp)
+		}
+
+		if _, err := decodeOptionalJSON(resp); err != nil {
+			keep = true
+
+			return fmt.Errorf("%w (your edits are in %s)", err, tmp)
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"dry_run": flags.DryRun, "path": path, "changes": changes})
+	}
+
+	printDiff(ctx, u, changes)
+
+	if flags.DryRun {
+		u.Err().Printf("dry run: %s not updated", path)
+	} else {
+		u.Err().Printf("%s updated", path)
+	}
+
+	return nil
+}
+
+// encodeForEdit renders a resource for the editor, with the file extension
+// that gets it highlighted.
+func encodeForEdit(v map[string]any, asYAML bool) ([]byte, string, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, "", fmt.Errorf("encode json: %w", err)
+	}
+
+	if !asYAML {
+		return append(b, '\n'), ".json", nil
+	}
+
+	var buf bytes.Buffer
+	if err := outfmt.EncodeYAML(&buf, v); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), ".yaml", nil
+}
+
+// decodeEdited parses the saved file as JSON or YAML.
+func decodeEdited(b []byte) (map[string]any, error) {
+	j, err := jsonFromYAML(b)
+	if err != nil {
+		return nil, fmt.Errorf("edited file: %w", err)
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal(j, &m); err != nil || m == nil {
+		return nil, errors.New("edited file: want an object")
+	}
+
+	return m, nil
+}
+
+// changedFields returns the top-level fields of local touched by changes.
+func changedFields(local map[string]any, changes []jsondiff.Op) map[string]any {
+	out := map[string]any{}
+
+	for _, op := range changes {
+		key, _, _ := strings.Cut(strings.TrimPrefix(op.Path, "/"), "/")
+		key = strings.NewReplacer("~1", "/", "~0", "~").Replace(key)
+
+		if v, ok := local[key]; ok {
+			out[key] = v
+		}
+	}
+
+	return out
+}
+
+// validateEdit checks an update body against the API description.
+func validateEdit(path string, body []byte) error {
+	spec, err := openapi.Default()
+	if err != nil {
+		return err
+	}
+
+	var vErr *openapi.Error
+	if err := spec.Validate(http.MethodPut, path, body); errors.As(err, &vErr) {
+		return fmt.Errorf("update doesn't match the API:\n  %s", strings.Join(vErr.Problems, "\n  "))
+	} else if err != nil {
+		return err
+	}
+
+	return nil
+}
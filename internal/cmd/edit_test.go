@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+// setupEditor replaces the editor with edit, applied to the file's text.
+func setupEditor(t *testing.T, edit func(string) string) *string {
+	t.Helper()
+
+	var seen string
+
+	orig := runEditor
+	runEditor = func(_ context.Context, path string) error {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		seen = string(b)
+
+		return os.WriteFile(path, []byte(edit(seen)), 0o600)
+	}
+
+	t.Cleanup(func() { runEditor = orig })
+
+	return &seen
+}
+
+// setupEditRemote serves one product and records PUT bodies.
+func setupEditRemote(t *testing.T, puts *[]map[string]any) {
+	t.Helper()
+
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/123/products/1234567890" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+
+		if r.Method == http.MethodPut {
+			b, _ := io.ReadAll(r.Body)
+
+			var body map[string]any
+			_ = json.Unmarshal(b, &body)
+			*puts = append(*puts, body)
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":        1234567890,
+			"name":      map[string]any{"es": "Remera", "pt": "Camisa"},
+			"published": true,
+			"tags":      "verano",
+		})
+	}))
+}
+
+func TestProductEdit_JSON(t *testing.T) {
+	var puts []map[string]any
+
+	setupEditRemote(t, &puts)
+	setupEditor(t, func(s string) string { return strings.Replace(s, `"Remera"`, `"Remera roja"`, 1) })
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"product", "edit", "1234567890", "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if len(puts) != 1 {
+		t.Fatalf("PUTs = %d, want 1", len(puts))
+	}
+
+	name, _ := puts[0]["name"].(map[string]any)
+	if len(puts[0]) != 1 || name["es"] != "Remera roja" || name["pt"] != "Camisa" {
+		t.Errorf("PUT body = %v, want only the edited name", puts[0])
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if changes, _ := got["changes"].([]any); len(changes) != 1 {
+		t.Errorf("changes = %v, want 1", got["changes"])
+	}
+}
+
+func TestProductEdit_YAML(t *testing.T) {
+	var puts []map[string]any
+
+	setupEditRemote(t, &puts)
+
+	seen := setupEditor(t, func(s string) string {
+		return regexp.MustCompile(`(?m)^published: true$`).ReplaceAllString(s, "published: false")
+	})
+
+	_ = captureStdout(t)
+	_ = captureStderr(t)
+
+	if err := Execute([]string{"product", "edit", "1234567890", "--yaml"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	for _, want := range []string{"id: 1234567890\n", "  es: Remera\n", "tags: verano\n"} {
+		if !strings.Contains(*seen, want) {
+			t.Errorf("editor file = %q, want %q", *seen, want)
+		}
+	}
+
+	if len(puts) != 1 || puts[0]["published"] != false || len(puts[0]) != 1 {
+		t.Errorf("PUTs = %v, want published=false only", puts)
+	}
+}
+
+func TestProductEdit_NoChanges(t *testing.T) {
+	var puts []map[string]any
+
+	setupEditRemote(t, &puts)
+	setupEditor(t, func(s string) string { return s })
+
+	errBuf := captureStderr(t)
+
+	if err := Execute([]string{"product", "edit", "1234567890"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if len(puts) != 0 {
+		t.Errorf("PUTs = %v, want none", puts)
+	}
+
+	if !strings.Contains(errBuf.String(), "no changes") {
+		t.Errorf("stderr = %q", errBuf.String())
+	}
+}
+
+func TestProductEdit_InvalidKeepsFile(t *testing.T) {
+	var puts []map[string]any
+
+	setupEditRemote(t, &puts)
+	setupEditor(t, func(string) string { return "[1, 2" })
+
+	err := Execute([]string{"product", "edit", "1234567890"})
+	if ExitCode(err) != ExitUsage {
+		t.Fatalf("exit code = %d, want %d (err %v)", ExitCode(err), ExitUsage, err)
+	}
+
+	path := regexp.MustCompile(`\S*nube-edit-\S*\.json`).FindString(err.Error())
+	if path == "" {
+		t.Fatalf("error = %v, want the kept file", err)
+	}
+
+	t.Cleanup(func() { _ = os.Remove(path) })
+
+	if _, statErr := os.Stat(path); statErr != nil {
+		t.Errorf("edit file not kept: %v", statErr)
+	}
+
+	if len(puts) != 0 {
+		t.Errorf("PUTs = %v, want none", puts)
+	}
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"html/template"
+	"net/mail"
+	"os"
+
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/mailer"
+)
+
+// EmailFlags deliver a report by email, through the SMTP server in the
+// config or else the local sendmail.
+type EmailFlags struct {
+	EmailTo      string `help:"Email the report (HTML table and CSV) to these comma-separated addresses" name:"email-to"`
+	EmailSubject string `help:"Subject of the emailed report" name:"email-subject"`
+}
+
+// tableReport is a rendered report: a title, a header and rows of text.
+type tableReport struct {
+	Title  string
+	Header []string
+	Rows   [][]string
+	// File names the CSV attachment.
+	File string
+}
+
+var reportHTML = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html><body style="font-family:sans-serif">
+<h2>{{.Title}}</h2>
+<table cellpadding="6" style="border-collapse:collapse">
+<tr>{{range .Header}}<th style="border-bottom:2px solid #333;text-align:left">{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td style="border-bottom:1px solid #ddd">{{.}}</td>{{end}}</tr>
+{{else}}<tr><td colspan="{{len .Header}}">No data</td></tr>
+{{end}}</table>
+</body></html>
+`))
+
+// recipients parses --email-to; nil when it is empty.
+func (f EmailFlags) recipients() ([]string, error) {
+	if f.EmailTo == "" {
+		return nil, nil
+	}
+
+	list, err := mail.ParseAddressList(f.EmailTo)
+	if err != nil {
+		return nil, usagef("--email-to %q: %v", f.EmailTo, err)
+	}
+
+	to := make([]string, len(list))
+	for i, a := range list {
+		to[i] = a.Address
+	}
+
+	return to, nil
+}
+
+// send emails r to the recipients as an HTML table with the CSV attached.
+func (f EmailFlags) send(ctx context.Context, to []string, r tableReport) error {
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return err
+	}
+
+	var (
+		server *mailer.SMTP
+		from   string
+	)
+
+	if s := cfg.SMTP; s != nil {
+		from = s.From
+
+		if s.Host != "" {
+			if from == "" {
+				return usagef("smtp.from is not set in the config; it is the sender address")
+			}
+
+			server = &mailer.SMTP{Host: s.Host, Port: s.Port, Username: s.Username, Password: s.Password, TLS: s.TLS}
+			if p := os.Getenv("NUBE_SMTP_PASSWORD"); p != "" {
+				server.Password = p
+			}
+		}
+	}
+
+	var html bytes.Buffer
+	if err := reportHTML.Execute(&html, r); err != nil {
+		return err
+	}
+
+	var data bytes.Buffer
+
+	w := csv.NewWriter(&data)
+	_ = w.Write(r.Header)
+	_ = w.WriteAll(r.Rows)
+
+	subject := f.EmailSubject
+	if subject == "" {
+		subject = r.Title
+	}
+
+	err = mailer.Send(ctx, server, &mailer.Message{
+		From:    from,
+		To:      to,
+		Subject: subject,
+		HTML:    html.String(),
+		Attachments: []mailer.Attachment{
+			{Name: r.File, ContentType: "text/csv; charset=utf-8", Data: data.Bytes()},
+		},
+	})
+	if errors.Is(err, mailer.ErrNoTransport) {
+		return usagef("can't send email: set smtp (host, from, ...) in the config file, or install sendmail")
+	}
+
+	return err
+}
@@ -1,6 +1,9 @@
 package cmd
 
-import "testing"
+import (
+	"io"
+	"testing"
+)
 
 func TestEnforceEnabledCommands(t *testing.T) {
 	tests := []struct {
@@ -19,7 +22,7 @@ func TestEnforceEnabledCommands(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			parser, _, err := newParser("test")
+			parser, _, err := newParser("test", nil, io.Discard, io.Discard)
 			if err != nil {
 				t.Fatalf("newParser: %v", err)
 			}
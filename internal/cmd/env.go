@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/gberlati/nube-cli/internal/outfmt"
+)
+
+// envVar is an environment variable nube reads.
+type envVar struct {
+	Name string
+	// Flag is the flag the variable sets, if any.
+	Flag string
+	Help string
+	// Secret values are masked by nube env and in all output (see newRedactor).
+	Secret bool
+}
+
+// envVars lists every NUBE_* variable nube honors. A test scans the source
+// for NUBE_ names, so a new variable fails it until it is listed here.
+var envVars = []envVar{
+	{Name: "NUBE_ACCESS_TOKEN", Help: "Access token; bypasses the credential file (for CI)", Secret: true},
+	{Name: "NUBE_USER_ID", Help: "Store ID to use with NUBE_ACCESS_TOKEN"},
+	{Name: "NUBE_STORE", Flag: "store", Help: "Store profile name"},
+	{Name: "NUBE_SESSION", Help: "Session ID of nube use (set by nube use --shell)"},
+	{Name: "NUBE_PARTNER", Flag: "partner", Help: "Partner profile name for nube partner"},
+	{Name: "NUBE_API_BASE_URL", Flag: "api-base-url", Help: "API base URL; overrides the profile's"},
+	{Name: "NUBE_AUTH_BROKER", Flag: "broker-url", Help: "OAuth broker URL for nube login"},
+	{Name: "NUBE_BROKER_CLIENT_ID", Flag: "client-id", Help: "App client ID for nube broker serve"},
+	{Name: "NUBE_BROKER_CLIENT_SECRET", Flag: "client-secret", Help: "App client secret for nube broker serve", Secret: true},
+	{Name: "NUBE_JSON", Flag: "json", Help: "Default to JSON output"},
+	{Name: "NUBE_PLAIN", Flag: "plain", Help: "Default to TSV output"},
+	{Name: "NUBE_ENVELOPE", Flag: "envelope", Help: "Wrap JSON output in an {ok,data,error,meta} envelope"},
+	{Name: "NUBE_JSON_ERRORS", Flag: "json-errors", Help: "Where --json writes error objects: stdout or stderr"},
+	{Name: "NUBE_FLATTEN", Flag: "flatten", Help: "Flatten JSON output: tsv or csv"},
+	{Name: "NUBE_YAML", Flag: "yaml", Help: "Output YAML instead of JSON"},
+	{Name: "NUBE_COLOR", Flag: "color", Help: "Color mode: auto, always or never"},
+	{Name: "NUBE_ENABLE_COMMANDS", Flag: "enable-commands", Help: "Comma-separated allowlist of top-level commands"},
+	{Name: "NUBE_POLICY", Help: "Policy file restricting commands and API resources"},
+	{Name: "NUBE_DAEMON", Flag: "daemon", Help: "Socket of a nube serve daemon to forward invocations to"},
+	{Name: "NUBE_NO_JOURNAL", Flag: "no-journal", Help: "Don't record write requests in the local journal"},
+	{Name: "NUBE_NO_HISTORY", Flag: "no-history", Help: "Don't snapshot resources before updates and deletes"},
+	{Name: "NUBE_TIMEOUT", Flag: "timeout", Help: "Per-request HTTP timeout, including retries"},
+	{Name: "NUBE_TOTAL_DEADLINE", Flag: "total-deadline", Help: "Abort the whole command after this long"},
+	{Name: "NUBE_MOCK_DIR", Flag: "mock-dir", Help: "Fixture directory to serve API responses from"},
+	{Name: "NUBE_RECORD_DIR", Flag: "record", Help: "Fixture directory to record API responses into"},
+	{Name: "NUBE_EXPECT_STORE", Flag: "expect-store", Help: "Store profile name or ID every request must target"},
+	{Name: "NUBE_LANG", Flag: "lang", Help: "Language of help and messages: en, es or pt"},
+	{Name: "NUBE_LANG_PRIORITY", Flag: "lang-priority", Help: "Order translated names are picked in, e.g. pt,es,en"},
+	{Name: "NUBE_TZ", Flag: "tz", Help: "Time zone for date filters and table timestamps"},
+	{Name: "NUBE_RAW_NUMBERS", Flag: "raw-numbers", Help: "Show amounts without currency formatting"},
+	{Name: "NUBE_LOG_FORMAT", Flag: "log-format", Help: "Log format: text or json"},
+	{Name: "NUBE_LOG_FILE", Flag: "log-file", Help: "File to also write logs to"},
+	{Name: "NUBE_STATS", Flag: "stats", Help: "Print a summary of API requests when done"},
+	{Name: "NUBE_WEBHOOK_SECRET", Flag: "secret", Help: "App client secret for webhook commands", Secret: true},
+	{Name: "NUBE_NOTIFY_WEBHOOK_URL", Flag: "webhook-url", Help: "Slack or Discord webhook URL for notify orders"},
+	{Name: "NUBE_TELEGRAM_TOKEN", Flag: "telegram-token", Help: "Telegram bot token for notify orders", Secret: true},
+	{Name: "NUBE_TELEGRAM_CHAT_ID", Flag: "telegram-chat-id", Help: "Telegram chat ID for notify orders"},
+	{Name: "NUBE_SCHEDULED_NOTIFY_URL", Flag: "notify-url", Help: "Webhook URL for run-scheduled failure reports"},
+	{Name: "NUBE_FTP_HOST", Flag: "ftp-host", Help: "FTP server for theme commands"},
+	{Name: "NUBE_FTP_USER", Flag: "ftp-user", Help: "FTP user for theme commands"},
+	{Name: "NUBE_FTP_PASSWORD", Flag: "ftp-password", Help: "FTP password for theme commands", Secret: true},
+	{Name: "NUBE_SMTP_PASSWORD", Help: "SMTP password for report --email-to (else smtp.password in config.json)", Secret: true},
+}
+
+// maskedValue stands in for the value of a secret variable.
+const maskedValue = "********"
+
+// EnvCmd lists the environment variables nube reads and their values.
+type EnvCmd struct {
+	Set bool `help:"Only list variables that are set" name:"set"`
+}
+
+// envStatus is one line of nube env.
+type envStatus struct {
+	Name        string `json:"name"`
+	Flag        string `json:"flag,omitempty"`
+	Set         bool   `json:"set"`
+	Value       string `json:"value"`
+	Source      string `json:"source"`
+	Description string `json:"description"`
+}
+
+func (c *EnvCmd) Run(ctx context.Context, parser *kong.Kong) error {
+	defaults := flagDefaults(parser.Model.Node)
+	rows := make([]envStatus, 0, len(envVars))
+
+	for _, v := range envVars {
+		row := envStatus{Name: v.Name, Flag: v.Flag, Source: "unset", Description: v.Help}
+
+		if value, ok := os.LookupEnv(v.Name); ok {
+			row.Set, row.Value, row.Source = true, value, "env"
+			if v.Secret && value != "" {
+				row.Value = maskedValue
+			}
+		} else if d := defaults[v.Flag]; d != "" {
+			row.Value, row.Source = d, "default"
+		}
+
+		if c.Set && !row.Set {
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"variables": rows})
+	}
+
+	w, done := tableWriter(ctx)
+	defer done()
+
+	_, _ = fmt.Fprintln(w, "NAME\tVALUE\tSOURCE\tDESCRIPTION")
+
+	for _, r := range rows {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Name, r.Value, r.Source, r.Description)
+	}
+
+	return nil
+}
+
+// flagDefaults maps the flags of node and its subcommands to their default
+// values.
+func flagDefaults(node *kong.Node) map[string]string {
+	defaults := map[string]string{}
+
+	var walk func(*kong.Node)
+
+	walk = func(n *kong.Node) {
+		for _, f := range n.Flags {
+			if f.Default != "" {
+				defaults[f.Name] = f.Default
+			}
+		}
+
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+
+	walk(node)
+
+	return defaults
+}
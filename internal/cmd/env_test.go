@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/kong"
+)
+
+// TestEnvVars_Registered keeps envVars complete: every NUBE_ name in the
+// source must be listed, and flags bound to a variable must be its Flag.
+func TestEnvVars_Registered(t *testing.T) {
+	registered := map[string]envVar{}
+	for _, v := range envVars {
+		registered[v.Name] = v
+	}
+
+	nameRE := regexp.MustCompile(`NUBE_[A-Z0-9_]*[A-Z0-9]`)
+
+	err := filepath.WalkDir("..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range nameRE.FindAllString(string(b), -1) {
+			if _, ok := registered[name]; !ok {
+				t.Errorf("%s reads %s, which isn't in envVars (env.go)", path, name)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parser, _, err := newParser("", nil, os.Stdout, os.Stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flags := flagNames(parser.Model.Node)
+
+	for _, v := range envVars {
+		if v.Flag != "" && !flags[v.Flag] {
+			t.Errorf("%s: no flag --%s", v.Name, v.Flag)
+		}
+	}
+
+	var walk func(*kong.Node)
+
+	walk = func(n *kong.Node) {
+		for _, f := range n.Flags {
+			for _, env := range f.Envs {
+				if v, ok := registered[env]; strings.HasPrefix(env, "NUBE_") && (!ok || v.Flag != f.Name) {
+					t.Errorf("--%s reads %s, which envVars doesn't list for it", f.Name, env)
+				}
+			}
+		}
+
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+
+	walk(parser.Model.Node)
+}
+
+func TestEnv(t *testing.T) {
+	setupConfigDir(t)
+	t.Setenv("NUBE_STORE", "shop")
+	t.Setenv("NUBE_ACCESS_TOKEN", "tok-secret")
+	t.Setenv("NUBE_TIMEOUT", "")
+	os.Unsetenv("NUBE_TIMEOUT")
+
+	out := captureStdout(t)
+	if err := Execute([]string{"env", "--json"}); err != nil {
+		t.Fatalf("env: %v", err)
+	}
+
+	if strings.Contains(out.String(), "tok-secret") {
+		t.Errorf("secret printed: %s", out.String())
+	}
+
+	var got struct {
+		Variables []envStatus `json:"variables"`
+	}
+	if err := json.NewDecoder(strings.NewReader(out.String())).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	byName := map[string]envStatus{}
+	for _, v := range got.Variables {
+		byName[v.Name] = v
+	}
+
+	if len(byName) != len(envVars) {
+		t.Errorf("listed %d variables, want %d", len(byName), len(envVars))
+	}
+
+	for name, want := range map[string]envStatus{
+		"NUBE_STORE":        {Set: true, Value: "shop", Source: "env"},
+		"NUBE_ACCESS_TOKEN": {Set: true, Value: maskedValue, Source: "env"},
+		"NUBE_TIMEOUT":      {Value: "30s", Source: "default"},
+		"NUBE_POLICY":       {Source: "unset"},
+	} {
+		v := byName[name]
+		if v.Set != want.Set || v.Value != want.Value || v.Source != want.Source {
+			t.Errorf("%s = %+v, want set=%v value=%q source=%q", name, v, want.Set, want.Value, want.Source)
+		}
+	}
+
+	out = captureStdout(t)
+	if err := Execute([]string{"env", "--set", "--plain"}); err != nil {
+		t.Fatalf("env --set: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "NUBE_STORE\tshop\tenv\t") || strings.Contains(out.String(), "NUBE_POLICY") {
+		t.Errorf("env --set output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/errfmt"
+	"github.com/gberlati/nube-cli/internal/i18n"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+)
+
+// envelope is the uniform --envelope output contract:
+// {"ok":bool,"data":...,"error":{...},"meta":{...}}.
+type envelope struct {
+	OK    bool          `json:"ok"`
+	Data  any           `json:"data"`
+	Error *errorPayload `json:"error"`
+	Meta  envelopeMeta  `json:"meta"`
+}
+
+// errorPayload is the machine-readable description of a failed command.
+type errorPayload struct {
+	Code       string              `json:"code"`
+	Message    string              `json:"message"`
+	ExitCode   int                 `json:"exit_code"`
+	HTTPStatus int                 `json:"http_status,omitempty"`
+	APICode    string              `json:"api_code,omitempty"`
+	Fields     map[string][]string `json:"fields,omitempty"`
+	// Suggestion is the next command to try, for agents.
+	Suggestion *errfmt.Suggestion `json:"suggestion,omitempty"`
+}
+
+type envelopeMeta struct {
+	Store              string `json:"store,omitempty"`
+	DurationMS         int64  `json:"duration_ms"`
+	RateLimitRemaining *int   `json:"rate_limit_remaining"`
+	RateLimitLimit     *int   `json:"rate_limit_limit"`
+	RateLimitResetMS   *int   `json:"rate_limit_reset_ms"`
+	RequestID          string `json:"request_id,omitempty"`
+	TotalCount         *int   `json:"total_count"`
+	PagesFetched       int    `json:"pages_fetched"`
+	// NextPage is a --page-token for the page after the last one fetched,
+	// null when the listing is complete.
+	NextPage *string    `json:"next_page"`
+	Link     *pageLinks `json:"link,omitempty"`
+	Stats    *runStats  `json:"stats,omitempty"`
+	// DefaultSelect is the agent_default_select applied for lack of a
+	// --select.
+	DefaultSelect string      `json:"default_select,omitempty"`
+	Truncated     *truncation `json:"truncated,omitempty"`
+}
+
+// pageLinks is the Link header of the last list page fetched.
+type pageLinks struct {
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	First string `json:"first,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+// truncation tells an agent that data was cut to agent_max_items and how
+// to get the rest.
+type truncation struct {
+	Returned int    `json:"returned"`
+	Total    int    `json:"total"`
+	Hint     string `json:"hint"`
+}
+
+func newErrorPayload(tr *i18n.Translator, err error) *errorPayload {
+	code := ExitCode(err)
+
+	p := &errorPayload{
+		Code:       exitCodeName(code),
+		Message:    strings.TrimSpace(errfmt.FormatIn(tr, err)),
+		ExitCode:   code,
+		HTTPStatus: apiErrorStatus(err),
+		Suggestion: errfmt.SuggestionIn(tr, err),
+	}
+
+	var apiErr *api.APIError
+	if errors.As(err, &apiErr) {
+		p.APICode = apiErr.Code
+	}
+
+	var valErr *api.ValidationError
+	if errors.As(err, &valErr) {
+		p.Fields = valErr.Fields
+	}
+
+	return p
+}
+
+// apiErrorStatus returns the HTTP status behind an API error, or 0.
+func apiErrorStatus(err error) int {
+	var (
+		apiErr  *api.APIError
+		valErr  *api.ValidationError
+		authErr *api.AuthError
+		payErr  *api.PaymentRequiredError
+		permErr *api.PermissionDeniedError
+		nfErr   *api.NotFoundError
+		rlErr   *api.RateLimitError
+	)
+
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.StatusCode
+	case errors.As(err, &valErr):
+		return valErr.StatusCode
+	case errors.As(err, &authErr):
+		return http.StatusUnauthorized
+	case errors.As(err, &payErr):
+		return http.StatusPaymentRequired
+	case errors.As(err, &permErr):
+		return http.StatusForbidden
+	case errors.As(err, &nfErr):
+		return http.StatusNotFound
+	case errors.As(err, &rlErr):
+		return http.StatusTooManyRequests
+	default:
+		return 0
+	}
+}
+
+// writeJSONError emits {"error":{...}} for a failed command run with --json.
+func writeJSONError(w io.Writer, tr *i18n.Translator, err error) error {
+	return outfmt.EncodeJSON(w, map[string]any{"error": newErrorPayload(tr, err)})
+}
+
+// writeEnvelope wraps the captured command output (or err) with run metadata.
+// stats is set with --stats.
+func writeEnvelope(w io.Writer, tr *i18n.Translator, flags *RootFlags, capture *outfmt.Capture, rec *api.Recorder, start time.Time, stats *runStats, err error) error {
+	snap := rec.Snapshot()
+
+	env := envelope{
+		OK: err == nil,
+		Meta: envelopeMeta{
+			Store:              envelopeStore(flags),
+			DurationMS:         time.Since(start).Milliseconds(),
+			RateLimitRemaining: presentInt(snap.RateLimitRemaining),
+			RateLimitLimit:     presentInt(snap.RateLimitLimit),
+			RateLimitResetMS:   presentInt(snap.RateLimitResetMS),
+			RequestID:          snap.RequestID,
+			TotalCount:         presentInt(snap.TotalCount),
+			PagesFetched:       snap.Pages,
+			Stats:              stats,
+		},
+	}
+
+	if links := snap.Links; links != (api.PageInfo{}) {
+		env.Meta.Link = &pageLinks{Next: links.Next, Prev: links.Prev, First: links.First, Last: links.Last}
+	}
+
+	if snap.Links.HasNext() {
+		token := pageToken(snap.Links.Next)
+		env.Meta.NextPage = &token
+	}
+
+	if err != nil {
+		env.Error = newErrorPayload(tr, err)
+	}
+
+	limits := readAgentLimits()
+	if flags.Select == "" {
+		env.Meta.DefaultSelect = limits.defaultSelect
+	}
+
+	// Commands that report partial results (e.g. batch) write data and still fail.
+	if data, ok := capture.Data(); ok {
+		env.Data, env.Meta.Truncated = truncateItems(data, limits.maxItems)
+	}
+
+	return outfmt.EncodeJSON(w, env)
+}
+
+// presentInt maps the -1 "header absent" sentinel to nil.
+func presentInt(n int) *int {
+	if n < 0 {
+		return nil
+	}
+
+	return &n
+}
+
+// envelopeStore returns the active store name for metadata, or "" if unresolved.
+func envelopeStore(flags *RootFlags) string {
+	if flags.getenv("NUBE_ACCESS_TOKEN") != "" {
+		return flags.getenv("NUBE_USER_ID")
+	}
+
+	name, _, err := flags.resolveStore(flags.Store)
+	if err != nil {
+		return ""
+	}
+
+	return name
+}
@@ -0,0 +1,307 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestEnvelope_Success(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Rate-Limit-Remaining", "37")
+		w.Header().Set("X-Rate-Limit-Limit", "40")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 42, "name": map[string]any{"es": "Zapato"}})
+	}))
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"product", "get", "42", "--envelope", "--select", "id"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	var got envelope
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v (output: %q)", err, buf.String())
+	}
+
+	if !got.OK || got.Error != nil {
+		t.Fatalf("ok = %v, error = %+v", got.OK, got.Error)
+	}
+
+	data, _ := got.Data.(map[string]any)
+	if jsonStr(data, "id") != "42" || data["name"] != nil {
+		t.Errorf("data = %v, want only selected id", got.Data)
+	}
+
+	if got.Meta.Store != "test" {
+		t.Errorf("meta.store = %q", got.Meta.Store)
+	}
+
+	if got.Meta.PagesFetched != 1 {
+		t.Errorf("meta.pages_fetched = %d, want 1", got.Meta.PagesFetched)
+	}
+
+	if got.Meta.RateLimitRemaining == nil || *got.Meta.RateLimitRemaining != 37 {
+		t.Errorf("meta.rate_limit_remaining = %v, want 37", got.Meta.RateLimitRemaining)
+	}
+
+	if got.Meta.RateLimitLimit == nil || *got.Meta.RateLimitLimit != 40 {
+		t.Errorf("meta.rate_limit_limit = %v, want 40", got.Meta.RateLimitLimit)
+	}
+
+	if got.Meta.TotalCount != nil {
+		t.Errorf("meta.total_count = %v, want null", *got.Meta.TotalCount)
+	}
+}
+
+func TestEnvelope_Error(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Request-Id", "req-404")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	buf := captureStdout(t)
+	_ = captureStderr(t)
+
+	err := Execute([]string{"product", "get", "404", "--envelope"})
+	if ExitCode(err) != ExitNotFound {
+		t.Fatalf("exit code = %d, want %d", ExitCode(err), ExitNotFound)
+	}
+
+	var got envelope
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v (output: %q)", err, buf.String())
+	}
+
+	if got.OK || got.Data != nil {
+		t.Errorf("ok = %v, data = %v", got.OK, got.Data)
+	}
+
+	if got.Error == nil || got.Error.Code != "not_found" || got.Error.ExitCode != ExitNotFound {
+		t.Errorf("error = %+v", got.Error)
+	}
+
+	if got.Meta.RequestID != "req-404" {
+		t.Errorf("meta.request_id = %q, want req-404", got.Meta.RequestID)
+	}
+}
+
+func TestJSONError_ValidationToStdout(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"email":["is invalid"]}`))
+	}))
+
+	buf := captureStdout(t)
+	_ = captureStderr(t)
+
+	err := Execute([]string{"customer", "get", "1", "--json"})
+	if ExitCode(err) != ExitValidation {
+		t.Fatalf("exit code = %d, want %d", ExitCode(err), ExitValidation)
+	}
+
+	var got struct {
+		Error errorPayload `json:"error"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v (output: %q)", err, buf.String())
+	}
+
+	if got.Error.Code != "validation" || got.Error.HTTPStatus != http.StatusUnprocessableEntity {
+		t.Errorf("error = %+v", got.Error)
+	}
+
+	if msgs := got.Error.Fields["email"]; len(msgs) != 1 || msgs[0] != "is invalid" {
+		t.Errorf("fields = %v", got.Error.Fields)
+	}
+}
+
+func TestJSONError_ToStderr(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+
+	stdout := captureStdout(t)
+	stderr := captureStderr(t)
+
+	err := Execute([]string{"store", "get", "--json", "--json-errors", "stderr"})
+	if ExitCode(err) != ExitPermissionDenied {
+		t.Fatalf("exit code = %d, want %d", ExitCode(err), ExitPermissionDenied)
+	}
+
+	if out := stdout.String(); out != "" {
+		t.Errorf("stdout = %q, want empty", out)
+	}
+
+	var got map[string]errorPayload
+	if err := json.Unmarshal([]byte(stderr.String()), &got); err != nil {
+		t.Fatalf("stderr should be a single JSON object: %v", err)
+	}
+
+	if got["error"].Code != "permission_denied" {
+		t.Errorf("error = %+v", got["error"])
+	}
+}
+
+func TestJSONError_Suggestion(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"shop": {StoreID: "123", AccessToken: "tok"}}, "shop")
+	t.Setenv("NUBE_ACCESS_TOKEN", "")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+
+	stdout := captureStdout(t)
+	stderr := captureStderr(t)
+
+	if err := Execute([]string{"order", "list", "--json"}); ExitCode(err) != ExitPermissionDenied {
+		t.Fatalf("exit code = %d, want %d", ExitCode(err), ExitPermissionDenied)
+	}
+
+	var got map[string]errorPayload
+	if err := json.Unmarshal([]byte(stdout.String()), &got); err != nil {
+		t.Fatalf("unmarshal %q: %v", stdout.String(), err)
+	}
+
+	s := got["error"].Suggestion
+	if s == nil || s.Command != "nube login shop" || !strings.Contains(s.Reason, "read_orders") {
+		t.Errorf("suggestion = %+v", s)
+	}
+
+	if !strings.Contains(stderr.String(), "Next: nube login shop (") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
+
+func TestEnvelope_AgentLimits(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	if err := config.WriteConfig(config.File{AgentMaxItems: 2, AgentDefaultSelect: "id"}); err != nil {
+		t.Fatal(err)
+	}
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"id": 1, "name": map[string]any{"es": "A"}},
+			{"id": 2, "name": map[string]any{"es": "B"}},
+			{"id": 3, "name": map[string]any{"es": "C"}},
+		})
+	}))
+
+	run := func(args ...string) envelope {
+		t.Helper()
+
+		buf := captureStdout(t)
+		if err := Execute(args); err != nil {
+			t.Fatalf("%v: error = %v", args, err)
+		}
+
+		var got envelope
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("unmarshal: %v (output: %q)", err, buf.String())
+		}
+
+		return got
+	}
+
+	got := run("product", "list", "--page", "1", "--envelope")
+
+	items, _ := got.Data.([]any)
+	if len(items) != 2 {
+		t.Fatalf("data = %v, want 2 items", got.Data)
+	}
+
+	if first, _ := items[0].(map[string]any); first["name"] != nil || jsonStr(first, "id") != "1" {
+		t.Errorf("item = %v, want only the default-selected id", first)
+	}
+
+	if got.Meta.DefaultSelect != "id" {
+		t.Errorf("meta.default_select = %q, want id", got.Meta.DefaultSelect)
+	}
+
+	if tr := got.Meta.Truncated; tr == nil || tr.Returned != 2 || tr.Total != 3 || tr.Hint == "" {
+		t.Errorf("meta.truncated = %+v, want 2 of 3", tr)
+	}
+
+	got = run("product", "list", "--page", "1", "--envelope", "--select", "name")
+
+	if items, _ := got.Data.([]any); len(items) != 2 {
+		t.Errorf("data = %v, want 2 items", got.Data)
+	} else if first, _ := items[0].(map[string]any); first["name"] == nil || first["id"] != nil {
+		t.Errorf("item = %v, want the explicit --select", first)
+	}
+
+	if got.Meta.DefaultSelect != "" {
+		t.Errorf("meta.default_select = %q with --select", got.Meta.DefaultSelect)
+	}
+}
+
+func TestEnvelope_PageToken(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var queries []url.Values
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+
+		w.Header().Set("X-Total-Count", "3")
+
+		if r.URL.Query().Get("page") == "1" {
+			w.Header().Set("Link", `<http://`+r.Host+`/v1/123/products?page=2&per_page=2&q=remera>; rel="next"`)
+		}
+
+		_ = json.NewEncoder(w).Encode([]map[string]any{{"id": 1}})
+	}))
+
+	run := func(args ...string) envelope {
+		t.Helper()
+
+		buf := captureStdout(t)
+		if err := Execute(args); err != nil {
+			t.Fatalf("%v: error = %v", args, err)
+		}
+
+		var got envelope
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("unmarshal: %v (output: %q)", err, buf.String())
+		}
+
+		return got
+	}
+
+	got := run("product", "list", "--page", "1", "--per-page", "2", "-q", "remera", "--envelope")
+	if got.Meta.NextPage == nil || got.Meta.Link == nil || got.Meta.Link.Next == "" {
+		t.Fatalf("meta = %+v, want next_page and link", got.Meta)
+	}
+
+	got = run("product", "list", "--page-token", *got.Meta.NextPage, "--envelope")
+	if got.Meta.NextPage != nil {
+		t.Errorf("meta.next_page = %q on the last page, want null", *got.Meta.NextPage)
+	}
+
+	if q := queries[len(queries)-1]; q.Get("page") != "2" || q.Get("per_page") != "2" || q.Get("q") != "remera" {
+		t.Errorf("resumed query = %v, want page 2 with the original filters", q)
+	}
+
+	token := pageToken("https://api.example/v1/123/orders?page=2")
+	if err := Execute([]string{"product", "list", "--page-token", token}); ExitCode(err) != ExitUsage {
+		t.Errorf("orders token for products: exit code = %d, want %d (err %v)", ExitCode(err), ExitUsage, err)
+	}
+
+	if err := Execute([]string{"product", "list", "--page-token", "!!"}); ExitCode(err) != ExitUsage {
+		t.Errorf("bad token: exit code = %d, want %d (err %v)", ExitCode(err), ExitUsage, err)
+	}
+}
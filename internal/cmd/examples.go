@@ -0,0 +1,214 @@
+package cmd
+
+// commandExample is one concrete invocation shown in --help and the schema.
+type commandExample struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+}
+
+// commandExamples maps command paths (as in commandAPI) to examples. Every
+// example must parse; TestCommandExamples_Parse keeps them in sync with the
+// flags.
+var commandExamples = map[string][]commandExample{
+	"login": {
+		{"nube login", "Authorize a store in the browser and save it as a profile"},
+		{"nube login --callback-template login-done.html", "Show your own page in the browser after authorizing"},
+		{"nube login --qr", "Log in from a headless box by scanning the QR code with your phone"},
+	},
+	"use": {
+		{"nube use my-shop", "Target my-shop from this shell until it exits or you switch again"},
+		{"nube use my-shop --shell", "Print an export line to eval, so scripts started from this shell use my-shop too"},
+	},
+	"init": {
+		{"nube init", "Log in and set nube up by answering a few questions"},
+		{"nube init --yes --name my-shop --output json --completion none", "Set up without questions, e.g. on a server"},
+	},
+	"completion": {
+		{"nube completion bash", "Print the bash script; save it as ~/.local/share/bash-completion/completions/nube"},
+	},
+	"broker serve": {
+		{"nube broker serve --listen :8787 --tls-cert cert.pem --tls-key key.pem", "Run your own login broker with the saved OAuth client; log in with nube login --broker-url https://<host>:8787"},
+	},
+	"auth list": {
+		{"nube auth list --json", "List saved store profiles"},
+	},
+	"config edit": {
+		{"nube config edit", "Change settings in $EDITOR; a mistake is reported and nothing is saved"},
+	},
+	"config validate": {
+		{"nube config validate", "Check config and credentials for typos, bad values and loose permissions"},
+	},
+	"config migrate": {
+		{"nube config migrate --dry-run", "Show which files an upgrade would rewrite"},
+	},
+	"env": {
+		{"nube env --set", "Show which NUBE_* variables affect this shell"},
+	},
+	"auth defaults": {
+		{"nube auth defaults my-shop per-page=100 lang-priority=pt,es", "Fetch 100 per page and show Portuguese names for my-shop"},
+		{"nube auth defaults my-shop json=", "Stop defaulting to JSON output for my-shop"},
+	},
+	"store get": {
+		{"nube store get --select name,main_currency --json", "Show the store name and currency"},
+	},
+	"product list": {
+		{"nube product list --published true --json", "List published products"},
+		{"nube product list -q remera --per-page 10", "Search products, first 10 results"},
+		{"nube product list --columns id,sku,name.pt,price,stock", "Pick and order the table columns"},
+		{"nube product list --updated-at-min 2024-01-01T00:00:00Z --select id,name.es --json", "IDs and names of products changed this year"},
+		{"nube product list --json --select 'id,variants.*.sku'", "Every product's variant SKUs"},
+	},
+	"product get": {
+		{"nube product get 123 --json", "Show a product with its variants"},
+	},
+	"product get-by-sku": {
+		{"nube product get-by-sku REM-001 --json", "Find the product of a variant SKU"},
+	},
+	"product exists": {
+		{"nube product exists --sku REM-001", "Exit 0 if a variant has this SKU, 1 if none does"},
+	},
+	"product diff": {
+		{"nube product diff 123 --file product.json", "Compare a local file against the live product"},
+	},
+	"order list": {
+		{"nube order list --status open --payment-status paid --json", "Paid orders not yet closed"},
+		{"nube order list --created-at-min 2024-06-01T00:00:00Z --select id,number,total --json", "Orders since June, key fields only"},
+		{"nube order list --created-at-min yesterday --created-at-max yesterday --tz store", "Yesterday's orders, by the store's clock"},
+	},
+	"order get": {
+		{"nube order get 456 --json", "Show an order"},
+	},
+	"category list": {
+		{"nube category list --json", "List all categories"},
+	},
+	"customer list": {
+		{"nube customer list --email ana@example.com --json", "Find a customer by email"},
+	},
+	"customer data-export": {
+		{"nube customer data-export 789 --out customer-789.json", "Export everything stored about a customer"},
+	},
+	"customer anonymize": {
+		{"nube customer anonymize 789 --bundle customer-789.json", "Save the customer's data, then anonymize them"},
+	},
+	"apply": {
+		{"nube apply -f store.yaml --dry-run", "Show what a manifest would change"},
+		{"nube apply -f store.yaml --prune", "Apply a manifest and delete what it doesn't list"},
+	},
+	"snapshot create": {
+		{"nube snapshot create --resources webhooks,scripts -o baseline.json", "Save the current webhooks and scripts"},
+	},
+	"snapshot diff": {
+		{"nube snapshot diff baseline.json --exit-code", "Fail when the store drifted from a snapshot"},
+	},
+	"cache refresh": {
+		{"nube cache refresh --resources products,categories", "Download the catalog for offline lookups"},
+	},
+	"cache query": {
+		{"nube cache query products -q zapato", "Find products by name or SKU without calling the API"},
+		{"nube cache query webhooks --file baseline.json", "List the webhooks saved in a snapshot"},
+	},
+	"report sales": {
+		{"nube report sales --by week --created-at-min 2024-Q4", "Paid orders and revenue per week of the quarter"},
+		{"nube report sales --to-sheet 1AbCdEf --credentials sa.json", "Update the Sales tab of a spreadsheet with the last 30 days"},
+		{"nube report sales --created-at-min yesterday --created-at-max yesterday --email-to owner@example.com", "Email yesterday's sales, e.g. from a daily cron entry"},
+		{"nube report sales --by month --convert-to USD --rate-source fixed:ARS=350,BRL=5.2", "Monthly revenue of stores in several countries, in dollars"},
+	},
+	"payment providers list": {
+		{"nube payment providers list", "Check which payment providers the store has registered"},
+	},
+	"payment options list": {
+		{"nube payment options list --provider 42 --json", "The checkout options one provider offers"},
+	},
+	"crosslist export": {
+		{"nube crosslist export --target meli --category MLA1055 --out items.json", "Map every product to a MercadoLibre item in one category"},
+		{"nube crosslist export --target meli --json --select product_id,errors", "Only see what needs fixing before listing"},
+	},
+	"theme pull": {
+		{"nube theme pull --dir theme --ftp-host ftp.example.com --ftp-user shop@example.com", "Download the theme into ./theme (password from $NUBE_FTP_PASSWORD)"},
+	},
+	"theme push": {
+		{"nube theme push --dir theme --dry-run", "Show which edited files would be uploaded"},
+	},
+	"theme watch": {
+		{"nube theme watch --dir theme", "Upload files as you save them"},
+	},
+	"export": {
+		{"nube export orders --format parquet -o orders.parquet", "Export every order as Parquet for DuckDB or Spark"},
+		{"nube export products --format csv --updated-at-min 7d -o products.csv", "Export products changed in the last week as CSV"},
+		{"nube export orders --sink postgres://nube@localhost/shop --updated-at-min 1d", "Upsert yesterday's order changes into the nube_orders table"},
+		{"nube export orders --format csv --convert-to USD -o orders-usd.csv", "Export orders with amounts in dollars at current rates"},
+	},
+	"sync mirror": {
+		{"nube sync", "Mirror products, orders and customers, fetching only changes after the first run"},
+		{"nube sync --db shop.db --resources orders --full", "Rebuild the orders table of a mirror file"},
+	},
+	"sync stock": {
+		{"nube sync stock --source file --from stock.csv --dry-run", "Show which variants an ERP export would change"},
+		{"nube sync stock --source http --from https://erp.example.com/stock.csv --header 'Authorization: Bearer x' --watch --interval 5m --health-listen 127.0.0.1:8787", "Push ERP stock every 5 minutes with a health endpoint"},
+	},
+	"sql": {
+		{`nube sql "SELECT status, COUNT(*) AS n, SUM(total) FROM orders GROUP BY status"`, "Summarize orders by status"},
+		{`nube sql "SELECT p.id, json_extract(v.value, '$.sku') AS sku FROM products p, json_each(p.data, '$.variants') v"`, "List variant SKUs from the product JSON"},
+	},
+	"graphql query": {
+		{"nube graphql query -f q.graphql --var id=123", "Run a GraphQL query with a variable"},
+	},
+	"api": {
+		{"nube api 'products?published=true' -F fields=id,name", "GET any endpoint, with query parameters"},
+		{`nube api categories/12 -X PUT -d '{"parent":null}'`, "Send a raw update, checked against the API description first"},
+		{"nube api 'orders?status=open' --as-curl", "Print the request as a curl command to share with support"},
+	},
+	"journal list": {
+		{"nube journal list --status failed", "Show write requests that failed"},
+	},
+	"undo": {
+		{"nube undo last --dry-run", "Show how the last change would be reverted"},
+	},
+	"batch run": {
+		{"nube batch run steps.txt --parallel 4", "Run the commands in a file, four at a time"},
+	},
+	"bench": {
+		{`nube bench --command "product list --all" --command "product list --all --per-page 200" --runs 3`, "See whether bigger pages make a full listing faster"},
+		{`nube bench --command "export orders -o /dev/null" --runs 5 --json`, "Time an export, with request and retry counts per run"},
+	},
+	"assert": {
+		{"nube assert --command \"order get 1001\" --jq '.payment_status==\"paid\"'", "Fail a CI step unless order 1001 is paid"},
+	},
+	"wait": {
+		{"nube wait order 1001 --until payment_status=paid --wait-timeout 10m --interval 15s", "Block until an order is paid, exiting 13 after ten minutes"},
+		{"nube wait order 1001 --until shipping_status=shipped --via webhook --public-url https://abc.tunnel.example --interval 5m --wait-timeout 24h", "Wait a day for a shipment, woken by webhooks instead of frequent polls"},
+	},
+	"seed": {
+		{"nube seed --products 20 --orders 50 --faker-locale pt_BR", "Fill a test store with Brazilian demo data"},
+	},
+	"webhook verify": {
+		{"nube webhook verify --payload body.json --signature 3f2a... --secret-from-store", "Check the signature of a delivery"},
+	},
+	"webhook replay": {
+		{"nube webhook replay --event order/created --id 456 --to http://localhost:3000/webhooks", "Send a signed webhook for an order to a local handler"},
+	},
+	"dev record": {
+		{`nube dev record --command "product list --page 1" --out internal/cmd/testdata/products`, "Record scrubbed fixtures for a test, replayed with --mock-dir"},
+	},
+	"notify orders": {
+		{"nube notify orders --to slack --webhook-url https://hooks.slack.com/services/T/B/X", "Post new orders to a Slack channel"},
+	},
+	"run-scheduled": {
+		{`nube run-scheduled --lock-name drift --command "snapshot diff baseline.json --exit-code"`, "Run a drift check from cron without overlapping runs"},
+	},
+	"schedule add": {
+		{`nube schedule add --at 2025-12-01T00:00 --command "product price adjust --filter category-id=10 --percent -15 --force"`, "Start a sale at midnight"},
+	},
+	"product lint": {
+		{"nube product lint --checks duplicate-sku,missing-price", "Fail a CI job when SKUs repeat or variants have no price"},
+	},
+	"product edit": {
+		{"nube product edit 12345 --yaml", "Fix a product by hand in $EDITOR, as YAML"},
+	},
+	"product qr": {
+		{"nube product qr 12345 --out qr.png", "Save a product's storefront QR code for shop signage"},
+	},
+	"schema commands": {
+		{"nube schema --json", "Describe every command for agent tooling"},
+	},
+}
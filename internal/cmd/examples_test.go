@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCommandExamples_Parse(t *testing.T) {
+	t.Parallel()
+
+	for path, examples := range commandExamples {
+		for _, e := range examples {
+			args, err := splitCommandLine(e.Command)
+			if err != nil || len(args) == 0 || args[0] != "nube" {
+				t.Errorf("%s: %q doesn't start with nube (err %v)", path, e.Command, err)
+				continue
+			}
+
+			parser, _, err := newParser(baseDescription(), nil, io.Discard, io.Discard)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			kctx, err := parser.Parse(args[1:])
+			if err != nil {
+				t.Errorf("%s: %q: %v", path, e.Command, err)
+				continue
+			}
+
+			if got := schemaPath(kctx.Selected()); got != path {
+				t.Errorf("%q runs %q, but is listed under %q", e.Command, got, path)
+			}
+
+			if e.Description == "" {
+				t.Errorf("%q has no description", e.Command)
+			}
+		}
+	}
+}
+
+func TestHelp_ShowsExamples(t *testing.T) {
+	buf := captureStdout(t)
+	_ = Execute([]string{"order", "list", "--help"})
+
+	out := buf.String()
+	if !strings.Contains(out, "Examples:\n  nube order list --status open") {
+		t.Errorf("help output lacks examples:\n%s", out)
+	}
+}
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"errors"
+	"net"
 
 	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/policy"
 )
 
 // Stable exit codes — agents and scripts can rely on these values.
@@ -20,6 +23,8 @@ const (
 	ExitCancelled        = 9
 	ExitPaymentRequired  = 10
 	ExitValidation       = 11
+	ExitMismatch         = 12
+	ExitTimeout          = 13
 )
 
 // exitCodeMap documents the stable exit codes for agent tooling.
@@ -37,9 +42,22 @@ var exitCodeMap = []struct {
 	{ExitRateLimited, "rate_limited", "Rate limited (HTTP 429)"},
 	{ExitRetryable, "retryable", "Retryable server error (HTTP 5xx)"},
 	{ExitConfig, "config", "Missing config or credentials"},
-	{ExitCancelled, "cancelled", "User cancelled"},
+	{ExitCancelled, "cancelled", "Declined at a prompt, or interrupted (SIGINT/SIGTERM)"},
 	{ExitPaymentRequired, "payment_required", "Payment required (HTTP 402)"},
 	{ExitValidation, "validation", "Validation error (HTTP 422)"},
+	{ExitMismatch, "mismatch", "Verification failed (e.g. webhook signature mismatch, --expect-store)"},
+	{ExitTimeout, "timeout", "Timed out waiting for a condition (wait)"},
+}
+
+// exitCodeName returns the stable name for an exit code ("error" if unknown).
+func exitCodeName(code int) string {
+	for _, e := range exitCodeMap {
+		if e.Code == code {
+			return e.Name
+		}
+	}
+
+	return "error"
 }
 
 type ExitErr struct {
@@ -106,6 +124,11 @@ func stableExitCode(err error) int {
 		return ExitPermissionDenied
 	}
 
+	var policyErr *policy.DeniedError
+	if errors.As(err, &policyErr) {
+		return ExitPermissionDenied
+	}
+
 	var rlErr *api.RateLimitError
 	if errors.As(err, &rlErr) {
 		return ExitRateLimited
@@ -116,6 +139,12 @@ func stableExitCode(err error) int {
 		return ExitRetryable
 	}
 
+	// Timeouts (per-request --timeout or --total-deadline) are worth retrying.
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ExitRetryable
+	}
+
 	var payErr *api.PaymentRequiredError
 	if errors.As(err, &payErr) {
 		return ExitPaymentRequired
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
@@ -98,6 +99,7 @@ func TestStableExitCode(t *testing.T) {
 		{"api other", &api.APIError{StatusCode: 400, Message: "bad"}, ExitError},
 		{"generic", errors.New("boom"), ExitError},
 		{"wrapped auth", fmt.Errorf("wrap: %w", &api.AuthError{}), ExitAuthRequired},
+		{"deadline", fmt.Errorf("fetch page: %w", context.DeadlineExceeded), ExitRetryable},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func setupExportAPI(t *testing.T) *string {
+	t.Helper()
+
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var query string
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/123/orders" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		query = r.URL.RawQuery
+
+		_, _ = w.Write([]byte(`[
+			{"id":7,"number":1007,"status":"open","total":"150.50","created_at":"2024-06-01T12:00:00+0000","customer":{"id":3}},
+			{"id":8,"number":1008,"status":"closed","total":"20.00","created_at":"2024-06-02T12:00:00+0000"}
+		]`))
+	}))
+
+	return &query
+}
+
+func TestExport_Formats(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		check func(t *testing.T, b []byte)
+	}{
+		{
+			name: "jsonl",
+			args: []string{"export", "orders"},
+			check: func(t *testing.T, b []byte) {
+				t.Helper()
+
+				if lines := strings.Split(strings.TrimSpace(string(b)), "\n"); len(lines) != 2 || !strings.Contains(lines[0], `"number":1007`) {
+					t.Errorf("jsonl = %q", b)
+				}
+			},
+		},
+		{
+			name: "csv",
+			args: []string{"export", "orders", "--format", "csv"},
+			check: func(t *testing.T, b []byte) {
+				t.Helper()
+
+				lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+				if len(lines) != 3 || !strings.HasPrefix(lines[0], "id,created_at,updated_at,number,status") {
+					t.Fatalf("csv = %q", b)
+				}
+
+				if !strings.HasPrefix(lines[1], "7,2024-06-01T12:00:00Z,,1007,open,") {
+					t.Errorf("row = %q", lines[1])
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupExportAPI(t)
+
+			out := captureStdout(t)
+			errOut := captureStderr(t)
+
+			if err := Execute(tt.args); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			tt.check(t, out.Bytes())
+
+			if !strings.Contains(errOut.String(), "exported 2 orders") {
+				t.Errorf("stderr = %q", errOut.String())
+			}
+		})
+	}
+}
+
+func TestExport_ParquetFile(t *testing.T) {
+	query := setupExportAPI(t)
+
+	path := filepath.Join(t.TempDir(), "orders.parquet")
+
+	_ = captureStdout(t)
+
+	if err := Execute([]string{"export", "orders", "--format", "parquet", "-o", path, "--created-at-min", "2024-06-01T00:00:00Z"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(b, []byte("PAR1")) || !bytes.HasSuffix(b, []byte("PAR1")) {
+		t.Errorf("%s is not a parquet file", path)
+	}
+
+	if !strings.Contains(*query, "created_at_min=") {
+		t.Errorf("query = %q, want the date filter", *query)
+	}
+}
+
+func TestExport_UsageErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "parquet to stdout", args: []string{"--format", "parquet"}},
+		{name: "sink with format", args: []string{"--sink", "postgres://localhost/shop", "--format", "csv"}},
+		{name: "sink with out", args: []string{"--sink", "postgres://localhost/shop", "-o", "orders.jsonl"}},
+		{name: "table without sink", args: []string{"--table", "orders"}},
+		{name: "unsupported sink", args: []string{"--sink", "bigquery://project/dataset"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupExportAPI(t)
+
+			_ = captureStdout(t)
+			_ = captureStderr(t)
+
+			if err := Execute(append([]string{"export", "orders"}, tt.args...)); ExitCode(err) != ExitUsage {
+				t.Errorf("exit code = %d, want %d (%v)", ExitCode(err), ExitUsage, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// GraphQLCmd groups GraphQL API commands.
+type GraphQLCmd struct {
+	Query GraphQLQueryCmd `cmd:"" help:"Run a GraphQL query or mutation"`
+}
+
+type GraphQLQueryCmd struct {
+	File      string   `help:"File with the GraphQL document ('-' for stdin)" name:"file" short:"f" required:""`
+	Var       []string `help:"Variable as key=value; values that parse as JSON (numbers, booleans, objects) are sent as JSON, anything else as a string" name:"var" sep:"none"`
+	Operation string   `help:"Operation to run when the document defines several" name:"operation"`
+}
+
+func (c *GraphQLQueryCmd) Run(ctx context.Context, flags *RootFlags) error {
+	b, err := readInputFile(c.File)
+	if err != nil {
+		return err
+	}
+
+	query := strings.TrimSpace(string(b))
+	if query == "" {
+		return usagef("%s is empty", c.File)
+	}
+
+	vars, err := parseGraphQLVars(c.Var)
+	if err != nil {
+		return err
+	}
+
+	if flags.DryRun && isGraphQLMutation(query) {
+		return writeResult(ctx, ui.FromContext(ctx),
+			kv("dry_run", true),
+			kv("operation", "mutation"),
+			kv("variables", vars),
+		)
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	data, err := client.GraphQL(ctx, api.GraphQLRequest{
+		Query:         query,
+		Variables:     vars,
+		OperationName: c.Operation,
+	})
+
+	// GraphQL can fail partially; print whatever data came back, then fail.
+	if data != nil {
+		var v any
+		if decodeErr := json.Unmarshal(data, &v); decodeErr != nil {
+			return decodeErr
+		}
+
+		if writeErr := outfmt.WriteJSON(ctx, stdoutFrom(ctx), v); writeErr != nil {
+			return writeErr
+		}
+	}
+
+	return err
+}
+
+// graphQLMutation matches a mutation operation definition at the start of a
+// line, which covers documents as people write them.
+var graphQLMutation = regexp.MustCompile(`(?m)^\s*mutation\b`)
+
+func isGraphQLMutation(query string) bool {
+	return graphQLMutation.MatchString(query)
+}
+
+// parseGraphQLVars turns key=value pairs into GraphQL variables.
+func parseGraphQLVars(pairs []string) (map[string]any, error) {
+	vars := make(map[string]any, len(pairs))
+
+	for _, p := range pairs {
+		key, value, ok := strings.Cut(p, "=")
+		if !ok || key == "" {
+			return nil, usagef("--var %q: want key=value", p)
+		}
+
+		var v any
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			v = value
+		}
+
+		vars[key] = v
+	}
+
+	return vars, nil
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestParseGraphQLVars(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseGraphQLVars([]string{"id=123", "name=Remera roja", "flags={\"a\":true}", "empty="})
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	want := map[string]any{"id": float64(123), "name": "Remera roja", "flags": map[string]any{"a": true}, "empty": ""}
+	if b, wantB := mustJSON(t, got), mustJSON(t, want); b != wantB {
+		t.Errorf("vars = %s, want %s", b, wantB)
+	}
+
+	if _, err := parseGraphQLVars([]string{"novalue"}); err == nil {
+		t.Error("expected error for missing =")
+	}
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(b)
+}
+
+func TestGraphQLQuery(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var gotBody map[string]any
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/123/graphql" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+
+		b, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(b, &gotBody)
+
+		_, _ = w.Write([]byte(`{"data":{"products":[{"id":"1"}]}}`))
+	}))
+
+	file := filepath.Join(t.TempDir(), "q.graphql")
+	if err := os.WriteFile(file, []byte("query($n: Int) { products(first: $n) { id } }\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"graphql", "query", "--file", file, "--var", "n=5"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if out := buf.String(); !strings.Contains(out, `"products"`) {
+		t.Errorf("output = %q", out)
+	}
+
+	vars, _ := gotBody["variables"].(map[string]any)
+	if vars["n"] != float64(5) {
+		t.Errorf("variables = %v", gotBody["variables"])
+	}
+}
+
+func TestGraphQLQuery_MutationDryRun(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Error("dry run should not send the mutation")
+	}))
+
+	file := filepath.Join(t.TempDir(), "m.graphql")
+	if err := os.WriteFile(file, []byte("# rename\nmutation { productUpdate(id: 1) { id } }"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"graphql", "query", "--file", file, "--dry-run", "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if out := buf.String(); !strings.Contains(out, `"dry_run": true`) {
+		t.Errorf("output = %q", out)
+	}
+}
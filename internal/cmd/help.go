@@ -1,7 +1,13 @@
 package cmd
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+
 	"github.com/alecthomas/kong"
+
+	"github.com/gberlati/nube-cli/internal/i18n"
 )
 
 type HelpCmd struct{}
@@ -10,3 +16,45 @@ func (c *HelpCmd) Run(app *kong.Kong) error {
 	_, err := app.Parse([]string{"--help"})
 	return err
 }
+
+// newHelpPrinter returns a help printer that prints kong's help, translated
+// by tr, followed by the selected command's examples.
+func newHelpPrinter(tr *i18n.Translator) kong.HelpPrinter {
+	return func(options kong.HelpOptions, ctx *kong.Context) error {
+		return printHelp(tr, options, ctx)
+	}
+}
+
+func printHelp(tr *i18n.Translator, options kong.HelpOptions, ctx *kong.Context) error {
+	var buf bytes.Buffer
+
+	out := ctx.Stdout
+	ctx.Stdout = &buf
+
+	err := kong.DefaultHelpPrinter(options, ctx)
+
+	ctx.Stdout = out
+	if err != nil {
+		return err
+	}
+
+	_, _ = io.WriteString(out, translateHelpOutput(tr, buf.String(), ctx.Model.Name))
+
+	node := ctx.Selected()
+	if node == nil {
+		return nil
+	}
+
+	examples := commandExamples[schemaPath(node)]
+	if len(examples) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(out, "\n"+tr.T("Examples:"))
+
+	for _, e := range examples {
+		_, _ = fmt.Fprintf(out, "  %s\n      %s\n", e.Command, e.Description)
+	}
+
+	return nil
+}
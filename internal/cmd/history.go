@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/history"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// readOnlyFields are server-assigned and rejected or ignored on write.
+var readOnlyFields = []string{"id", "created_at", "updated_at"}
+
+// newHistory opens the snapshot store at its default location.
+var newHistory = func() (*history.History, error) {
+	path, err := history.DefaultPath()
+	if err != nil {
+		return nil, &ExitErr{Code: ExitConfig, Err: err}
+	}
+
+	return history.New(path), nil
+}
+
+// SnapshotFlags make snapshots opt-in for bulk commands, where a GET before
+// every update would double the requests.
+type SnapshotFlags struct {
+	Snapshot bool `help:"Snapshot each resource before updating it, for 'nube undo' (one more GET per update)" name:"snapshot"`
+}
+
+// historyContext returns ctx without its snapshot store unless --snapshot
+// is set.
+func (f SnapshotFlags) historyContext(ctx context.Context) context.Context {
+	if f.Snapshot {
+		return ctx
+	}
+
+	return history.WithHistory(ctx, nil)
+}
+
+// HistoryCmd groups commands over the local snapshot store.
+type HistoryCmd struct {
+	List HistoryListCmd `cmd:"" default:"withargs" help:"List snapshots, most recent last"`
+}
+
+type HistoryListCmd struct {
+	Limit int `help:"Show at most this many of the most recent entries (0 = all)" default:"20"`
+}
+
+func (c *HistoryListCmd) Run(ctx context.Context) error {
+	h, err := newHistory()
+	if err != nil {
+		return err
+	}
+
+	entries, err := h.List()
+	if err != nil {
+		return err
+	}
+
+	if c.Limit > 0 && len(entries) > c.Limit {
+		entries = entries[len(entries)-c.Limit:]
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if entries == nil {
+			entries = []history.Entry{}
+		}
+
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), entries)
+	}
+
+	w, done := tableWriter(ctx)
+	defer done()
+
+	_, _ = fmt.Fprintln(w, "ID\tTIME\tSTORE\tMETHOD\tPATH\tUNDONE")
+
+	for _, e := range entries {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t\n",
+			e.ID, e.Time.Local().Format(time.DateTime), e.Store, e.Method, e.Path, e.Undone)
+	}
+
+	return nil
+}
+
+// UndoCmd reverts a write using the snapshot taken before it: updates are
+// undone by writing the snapshot back, deletes by recreating the resource.
+type UndoCmd struct {
+	ID string `arg:"" name:"id" optional:"" default:"last" help:"History entry ID, or 'last' for the most recent change to the active store"`
+}
+
+func (c *UndoCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	h, err := newHistory()
+	if err != nil {
+		return err
+	}
+
+	e, err := h.Find(c.ID, client.StoreID())
+	if errors.Is(err, history.ErrNotFound) {
+		return &ExitErr{Code: ExitNotFound, Err: err}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if e.Undone && !flags.Force {
+		return usagef("entry %s was already undone; use --force to restore it again", e.ID)
+	}
+
+	if e.Store != "" && e.Store != client.StoreID() {
+		return usagef("entry %s was recorded for store %s, but the active store is %s", e.ID, e.Store, client.StoreID())
+	}
+
+	method, path := undoRequest(e)
+	if method == "" {
+		return usagef("cannot undo %s requests", e.Method)
+	}
+
+	if flags.DryRun {
+		return writeResult(ctx, u,
+			kv("dry_run", true),
+			kv("id", e.ID),
+			kv("method", method),
+			kv("path", path),
+		)
+	}
+
+	if err := confirmDestructive(flags, fmt.Sprintf("restore %s to its state at %s", e.Path, e.Time.Local().Format(time.DateTime))); err != nil {
+		return err
+	}
+
+	body, err := jsonBody(restorePayload(e.Snapshot))
+	if err != nil {
+		return err
+	}
+
+	// The restore itself is not snapshotted, so repeated undo doesn't ping-pong.
+	ctx = history.WithHistory(ctx, nil)
+
+	var resp *http.Response
+	if method == http.MethodPost {
+		resp, err = client.Post(ctx, path, body) //nolint:bodyclose // DecodeResponse closes body
+	} else {
+		resp, err = client.Put(ctx, path, body) //nolint:bodyclose // DecodeResponse closes body
+	}
+
+	if err != nil {
+		return err
+	}
+
+	restored, err := api.DecodeResponse[map[string]any](resp)
+	if err != nil {
+		return err
+	}
+
+	if err := h.MarkUndone(e.ID); err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{
+			"id":       e.ID,
+			"method":   method,
+			"path":     path,
+			"restored": restored,
+		})
+	}
+
+	return writeResult(ctx, u,
+		kv("id", e.ID),
+		kv("method", method),
+		kv("path", path),
+		kv("resource_id", jsonStr(restored, "id")),
+	)
+}
+
+// undoRequest returns the inverse call for a snapshotted write.
+func undoRequest(e history.Entry) (method, path string) {
+	switch e.Method {
+	case http.MethodPut:
+		return http.MethodPut, e.Path
+	case http.MethodDelete:
+		// Deleted resources come back under a new ID.
+		return http.MethodPost, history.ParentPath(e.Path)
+	default:
+		return "", ""
+	}
+}
+
+// restorePayload strips server-assigned fields from a snapshot.
+func restorePayload(snapshot json.RawMessage) any {
+	var m map[string]any
+	if json.Unmarshal(snapshot, &m) != nil {
+		return snapshot
+	}
+
+	for _, k := range readOnlyFields {
+		delete(m, k)
+	}
+
+	return m
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestUndo_RestoresUpdate(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var puts []map[string]any
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if strings.HasSuffix(r.URL.Path, "/addresses") {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+
+		if r.Method == http.MethodPut {
+			var body map[string]any
+
+			b, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(b, &body)
+			puts = append(puts, body)
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 200, "email": "juan@example.com"})
+	}))
+
+	_ = captureStdout(t)
+	if err := Execute([]string{"customer", "anonymize", "200", "--force"}); err != nil {
+		t.Fatalf("anonymize error = %v", err)
+	}
+
+	_ = captureStdout(t)
+	if err := Execute([]string{"undo", "--force"}); err != nil {
+		t.Fatalf("undo error = %v", err)
+	}
+
+	if len(puts) != 2 {
+		t.Fatalf("PUT requests = %d, want 2", len(puts))
+	}
+
+	restored := puts[1]
+	if restored["email"] != "juan@example.com" {
+		t.Errorf("restore body = %v, want original email", restored)
+	}
+
+	if _, ok := restored["id"]; ok {
+		t.Errorf("restore body should not include id: %v", restored)
+	}
+
+	h, _ := newHistory()
+
+	entries, err := h.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The restore itself must not be snapshotted.
+	if len(entries) != 1 || !entries[0].Undone {
+		t.Errorf("entries = %+v, want one undone entry", entries)
+	}
+
+	_ = captureStderr(t)
+
+	err = Execute([]string{"undo", "--force"})
+	if ExitCode(err) != ExitNotFound {
+		t.Errorf("second undo exit code = %d, want %d", ExitCode(err), ExitNotFound)
+	}
+}
+
+func TestUndo_DeleteRecreatesInCollection(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	h, _ := newHistory()
+	if _, err := h.Save("123", http.MethodDelete, "products/7", []byte(`{"id":7,"name":{"es":"Remera"}}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotMethod, gotPath string
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath = r.Method, r.URL.Path
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 8})
+	}))
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"undo", "last", "--force", "--json"}); err != nil {
+		t.Fatalf("undo error = %v", err)
+	}
+
+	if gotMethod != http.MethodPost || gotPath != "/v1/123/products" {
+		t.Errorf("request = %s %s, want POST /v1/123/products", gotMethod, gotPath)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if got["method"] != http.MethodPost {
+		t.Errorf("output = %v", got)
+	}
+}
+
+func TestUndo_DryRun(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	h, _ := newHistory()
+
+	e, err := h.Save("123", http.MethodPut, "products/7", []byte(`{"id":7}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setupMockAPIClient(t, http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+
+	_ = captureStdout(t)
+	if err := Execute([]string{"undo", e.ID, "--dry-run"}); err != nil {
+		t.Fatalf("undo error = %v", err)
+	}
+
+	if got, _ := h.Find(e.ID, ""); got.Undone {
+		t.Error("dry run marked the entry undone")
+	}
+}
+
+func TestRestorePayload(t *testing.T) {
+	t.Parallel()
+
+	got := restorePayload([]byte(`{"id":1,"created_at":"x","updated_at":"y","name":"n"}`))
+
+	m, ok := got.(map[string]any)
+	if !ok || len(m) != 1 || m["name"] != "n" {
+		t.Errorf("restorePayload() = %v", got)
+	}
+}
@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/oauth"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// InitCmd sets nube up for a first store: login, profile name, default
+// output and shell completion. Each question is skipped when its flag is
+// given, and --yes (--force) takes the defaults of the rest, for scripts.
+type InitCmd struct {
+	Login            string        `help:"How to log in: broker (in the browser, through nube's app) or credentials (your own app)" enum:",broker,credentials" default:"" name:"login"`
+	Credentials      string        `help:"Your app's credentials.json, for --login credentials" name:"credentials" type:"path"`
+	Name             string        `help:"Store profile name (default: store-<store ID>)" name:"name"`
+	Output           string        `help:"What commands print by default: table, json or plain" enum:",table,json,plain" default:"" name:"output"`
+	Completion       string        `help:"Install tab completion for bash, zsh or fish, or none (default: the shell in $SHELL)" enum:",bash,zsh,fish,none" default:"" name:"completion"`
+	Timeout          time.Duration `name:"auth-timeout" help:"How long to wait for browser authorization" default:"5m"`
+	BrokerURL        string        `name:"broker-url" help:"OAuth broker URL (overrides default)" env:"NUBE_AUTH_BROKER"`
+	CallbackTemplate string        `name:"callback-template" help:"HTML template for the page the browser shows after authorizing" type:"path"`
+	QR               bool          `name:"qr" help:"Show the authorization link as a QR code to approve from your phone; the token comes back through the broker"`
+}
+
+// initChoice is one answer to a multiple-choice question.
+type initChoice struct {
+	value string
+	label string
+}
+
+func (c *InitCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	ask := !flags.Force
+
+	if ask && !interactive(flags) {
+		return usagef("nube init asks questions on a terminal; pass --yes to take the defaults")
+	}
+
+	if err := validateBaseURL(flags.APIBaseURL); err != nil {
+		return usagef("--api-base-url: %v", err)
+	}
+
+	login := c.Login
+	if c.QR {
+		if login == "credentials" {
+			return usagef("--qr logs in through a broker; it can't be used with --login credentials")
+		}
+
+		login = "broker"
+	}
+
+	method, err := c.answer(ask, login, "How do you want to log in?", []initChoice{
+		{"broker", "In the browser, through nube's app (recommended)"},
+		{"credentials", "With the credentials.json of your own Tienda Nube app"},
+	})
+	if err != nil {
+		return err
+	}
+
+	brokerURL := c.BrokerURL
+	if brokerURL == "" {
+		brokerURL = oauth.DefaultBrokerURL
+	}
+
+	if method == "credentials" {
+		if err := c.saveCredentials(ask); err != nil {
+			return err
+		}
+
+		brokerURL = ""
+	}
+
+	opts, err := authorizeOptions(c.Timeout, brokerURL)
+	if err != nil {
+		return err
+	}
+
+	opts.CallbackTemplate = c.CallbackTemplate
+	opts.QR = c.QR
+
+	tok, err := authorizeOAuth(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSpace(c.Name)
+	if name == "" {
+		name = defaultProfileName(tok)
+
+		if ask {
+			if name, err = promptLine("Name for this store (used with --store)", name); err != nil {
+				return err
+			}
+		}
+	}
+
+	output, err := c.answer(ask, c.Output, "What should commands print by default?", []initChoice{
+		{"table", "Tables for people"},
+		{"json", "JSON, for scripts and tools"},
+		{"plain", "Tab-separated text, for spreadsheets and shell pipes"},
+	})
+	if err != nil {
+		return err
+	}
+
+	profile := loginProfile(tok, flags.APIBaseURL)
+	if output != "table" {
+		profile.Defaults = map[string]string{output: "true"}
+	}
+
+	if err := credstore.SetStore(name, profile); err != nil {
+		return err
+	}
+
+	completion, err := c.installCompletion(u, ask)
+	if err != nil {
+		return err
+	}
+
+	return writeResult(ctx, u,
+		kv("name", name),
+		kv("store_id", profile.StoreID),
+		kv("output", output),
+		kv("completion", completion),
+	)
+}
+
+// answer returns given, else the first choice with --yes, else what the
+// user picks.
+func (c *InitCmd) answer(ask bool, given, question string, choices []initChoice) (string, error) {
+	if given != "" {
+		return given, nil
+	}
+
+	if !ask {
+		return choices[0].value, nil
+	}
+
+	return promptChoice(question, choices)
+}
+
+// saveCredentials stores the OAuth client from --credentials, asking for
+// the file when it isn't given.
+func (c *InitCmd) saveCredentials(ask bool) error {
+	path := c.Credentials
+
+	if path == "" {
+		if !ask {
+			return usagef("--login credentials needs --credentials")
+		}
+
+		line, err := promptLine("Path to your app's credentials.json", "")
+		if err != nil {
+			return err
+		}
+
+		if path, err = expandPath(line); err != nil {
+			return err
+		}
+	}
+
+	b, err := os.ReadFile(path) //nolint:gosec // user-provided path
+	if err != nil {
+		return err
+	}
+
+	return saveOAuthClient(b)
+}
+
+// installCompletion installs completion for --completion, or for the shell
+// in $SHELL when the user agrees, and returns the script's path.
+func (c *InitCmd) installCompletion(u *ui.UI, ask bool) (string, error) {
+	shell := c.Completion
+
+	if shell == "" {
+		shell = loginShell()
+		if shell == "" {
+			return "", nil
+		}
+
+		if ask {
+			ok, err := promptDefaultYes(fmt.Sprintf("Install tab completion for %s?", shell))
+			if err != nil || !ok {
+				return "", err
+			}
+		}
+	}
+
+	if shell == "none" {
+		return "", nil
+	}
+
+	path, err := installCompletion(shell)
+	if err != nil {
+		return "", err
+	}
+
+	switch shell {
+	case "zsh":
+		u.Err().Printf("Tab completion takes effect in new shells once %s is on $fpath; add this before compinit in ~/.zshrc:\n  fpath=(%s $fpath)", filepath.Dir(path), filepath.Dir(path))
+	case "bash":
+		u.Err().Println("Tab completion takes effect in new shells (it needs the bash-completion package)")
+	default:
+		u.Err().Println("Tab completion takes effect in new shells")
+	}
+
+	return path, nil
+}
+
+// promptLine asks for a line of text; an empty answer is def.
+func promptLine(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(os.Stderr, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(os.Stderr, "%s: ", question)
+	}
+
+	line, err := readConfirmation()
+	if err != nil || line == "" {
+		return def, err
+	}
+
+	return line, nil
+}
+
+// promptChoice asks for one of choices, by number or value; an empty answer
+// is the first.
+func promptChoice(question string, choices []initChoice) (string, error) {
+	fmt.Fprintln(os.Stderr, question)
+
+	for i, ch := range choices {
+		fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, ch.label)
+	}
+
+	for {
+		line, err := promptLine("Choose", "1")
+		if err != nil {
+			return "", err
+		}
+
+		for i, ch := range choices {
+			if line == strconv.Itoa(i+1) || strings.EqualFold(line, ch.value) {
+				return ch.value, nil
+			}
+		}
+
+		fmt.Fprintf(os.Stderr, "Answer a number from 1 to %d\n", len(choices))
+	}
+}
+
+// promptDefaultYes asks a yes/no question whose empty answer is yes.
+func promptDefaultYes(question string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "%s [Y/n]: ", question)
+
+	line, err := readConfirmation()
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(line) {
+	case "", "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
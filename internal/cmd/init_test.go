@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/oauth"
+)
+
+func TestInit_Yes(t *testing.T) {
+	setupConfigDir(t)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SHELL", "/usr/bin/fish")
+
+	var opts oauth.AuthorizeOptions
+
+	orig := authorizeOAuth
+	authorizeOAuth = func(_ context.Context, o oauth.AuthorizeOptions) (oauth.TokenResponse, error) {
+		opts = o
+		return oauth.TokenResponse{AccessToken: "tok", UserID: "77"}, nil
+	}
+	t.Cleanup(func() { authorizeOAuth = orig })
+
+	out := captureStdout(t)
+	if err := Execute([]string{"init", "--yes", "--output", "json", "--json"}); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+
+	if opts.BrokerURL == "" {
+		t.Error("broker login didn't use a broker")
+	}
+
+	var got map[string]string
+	if err := json.NewDecoder(strings.NewReader(out.String())).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got["name"] != "store-77" || got["output"] != "json" {
+		t.Errorf("result = %v", got)
+	}
+
+	p, err := credstore.GetStore("store-77")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.AccessToken != "tok" || p.Defaults["json"] != "true" {
+		t.Errorf("profile = %+v", p)
+	}
+
+	script, err := os.ReadFile(got["completion"])
+	if err != nil || filepath.Base(got["completion"]) != "nube.fish" || !strings.Contains(string(script), "__complete") {
+		t.Errorf("completion %q: %v", got["completion"], err)
+	}
+}
+
+func TestInit_Credentials(t *testing.T) {
+	setupConfigDir(t)
+
+	var opts oauth.AuthorizeOptions
+
+	orig := authorizeOAuth
+	authorizeOAuth = func(_ context.Context, o oauth.AuthorizeOptions) (oauth.TokenResponse, error) {
+		opts = o
+		return oauth.TokenResponse{AccessToken: "tok", UserID: "5"}, nil
+	}
+	t.Cleanup(func() { authorizeOAuth = orig })
+
+	_ = captureStdout(t)
+	if err := Execute([]string{"init", "--yes", "--login", "credentials"}); ExitCode(err) != ExitUsage {
+		t.Errorf("without --credentials: %v", err)
+	}
+
+	if err := Execute([]string{"init", "--yes", "--login", "credentials", "--qr"}); ExitCode(err) != ExitUsage {
+		t.Errorf("with --qr: %v", err)
+	}
+
+	creds := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(creds, []byte(`{"client_id":"id","client_secret":"secret"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Execute([]string{"init", "--yes", "--login", "credentials", "--credentials", creds, "--name", "shop", "--completion", "none"}); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+
+	if opts.BrokerURL != "" {
+		t.Errorf("credentials login used broker %q", opts.BrokerURL)
+	}
+
+	if c, err := credstore.GetOAuthClient("default"); err != nil || c.ClientID != "id" {
+		t.Errorf("oauth client = %+v, %v", c, err)
+	}
+
+	if p, err := credstore.GetStore("shop"); err != nil || p.Defaults != nil {
+		t.Errorf("profile = %+v, %v", p, err)
+	}
+}
+
+func TestInit_NeedsTerminal(t *testing.T) {
+	setupConfigDir(t)
+
+	if err := Execute([]string{"init"}); ExitCode(err) != ExitUsage {
+		t.Errorf("err = %v, want a usage error", err)
+	}
+}
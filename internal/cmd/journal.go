@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/journal"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// JournalCmd groups commands over the local write-request journal.
+type JournalCmd struct {
+	List  JournalListCmd  `cmd:"" default:"withargs" help:"List journaled write requests"`
+	Show  JournalShowCmd  `cmd:"" help:"Show one journaled request"`
+	Retry JournalRetryCmd `cmd:"" help:"Resend a journaled request with its original idempotency key (--force for one that succeeded or a POST that may have been applied)"`
+}
+
+// newJournal opens the journal at its default location.
+var newJournal = func() (*journal.Journal, error) {
+	path, err := journal.DefaultPath()
+	if err != nil {
+		return nil, &ExitErr{Code: ExitConfig, Err: err}
+	}
+
+	return journal.New(path), nil
+}
+
+type JournalListCmd struct {
+	Status string `help:"Only show entries with this status" enum:",pending,ok,failed,unknown" default:""`
+	Limit  int    `help:"Show at most this many of the most recent entries (0 = all)" default:"20"`
+}
+
+func (c *JournalListCmd) Run(ctx context.Context) error {
+	j, err := newJournal()
+	if err != nil {
+		return err
+	}
+
+	entries, err := j.List()
+	if err != nil {
+		return err
+	}
+
+	filtered := make([]journal.Entry, 0, len(entries))
+
+	for _, e := range entries {
+		if c.Status == "" || string(e.Status) == c.Status {
+			filtered = append(filtered, e)
+		}
+	}
+
+	if c.Limit > 0 && len(filtered) > c.Limit {
+		filtered = filtered[len(filtered)-c.Limit:]
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), filtered)
+	}
+
+	w, done := tableWriter(ctx)
+	defer done()
+
+	_, _ = fmt.Fprintln(w, "ID\tTIME\tMETHOD\tPATH\tSTATUS\tHTTP\tATTEMPTS")
+
+	for _, e := range filtered {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%d\n",
+			e.ID, e.Time.Local().Format(time.DateTime), e.Method, e.Path, e.Status, e.HTTPStatus, e.Attempts)
+	}
+
+	return nil
+}
+
+type JournalShowCmd struct {
+	ID string `arg:"" name:"id" help:"Journal entry ID"`
+}
+
+func (c *JournalShowCmd) Run(ctx context.Context) error {
+	u := ui.FromContext(ctx)
+
+	e, err := getJournalEntry(c.ID)
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), e)
+	}
+
+	return writeResult(ctx, u,
+		kv("id", e.ID),
+		kv("time", e.Time.Local().Format(time.RFC3339)),
+		kv("store", e.Store),
+		kv("method", e.Method),
+		kv("path", e.Path),
+		kv("status", string(e.Status)),
+		kv("http_status", e.HTTPStatus),
+		kv("attempts", e.Attempts),
+		kv("error", e.Error),
+		kv("body", string(e.Body)),
+	)
+}
+
+type JournalRetryCmd struct {
+	ID string `arg:"" name:"id" help:"Journal entry ID"`
+}
+
+func (c *JournalRetryCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	e, err := getJournalEntry(c.ID)
+	if err != nil {
+		return err
+	}
+
+	if !flags.Force {
+		if !e.Retryable() {
+			return usagef("entry %s already succeeded; use --force to resend it", e.ID)
+		}
+
+		// The API doesn't deduplicate creates, so a POST that may have gone
+		// through would be applied twice.
+		if e.Method == http.MethodPost && e.MaybeApplied() {
+			return usagef("entry %s may have been applied already (status %s) and resending a POST would create it again; check the store, then use --force to resend it", e.ID, e.Status)
+		}
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	if e.Store != "" && e.Store != client.StoreID() {
+		return usagef("entry %s was recorded for store %s, but the active store is %s", e.ID, e.Store, client.StoreID())
+	}
+
+	if flags.DryRun {
+		return writeResult(ctx, u,
+			kv("dry_run", true),
+			kv("id", e.ID),
+			kv("method", e.Method),
+			kv("path", e.Path),
+		)
+	}
+
+	// Retry always records into the journal, under the original key, so the
+	// entry's status reflects the latest attempt even with --no-journal.
+	j, err := newJournal()
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	if len(e.Body) > 0 {
+		if body, err = j.Body(e.ID); err != nil {
+			if errors.Is(err, journal.ErrNoBody) {
+				return usagef("entry %s: its original body is no longer kept (it succeeded or is older than %d days); run the original command again", e.ID, int(journal.BodyMaxAge.Hours()/24))
+			}
+
+			return err
+		}
+	}
+
+	ctx = journal.WithKey(journal.WithJournal(ctx, j), e.ID)
+
+	resp, err := sendJournaled(ctx, client, e, body) //nolint:bodyclose // decodeOptionalJSON closes body
+	if err != nil {
+		return err
+	}
+
+	result, err := decodeOptionalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{
+			"id":       e.ID,
+			"status":   journal.StatusOK,
+			"response": result,
+		})
+	}
+
+	return writeResult(ctx, u,
+		kv("id", e.ID),
+		kv("status", string(journal.StatusOK)),
+		kv("http_status", resp.StatusCode),
+	)
+}
+
+func getJournalEntry(id string) (journal.Entry, error) {
+	j, err := newJournal()
+	if err != nil {
+		return journal.Entry{}, err
+	}
+
+	e, err := j.Get(id)
+	if errors.Is(err, journal.ErrNotFound) {
+		return journal.Entry{}, &ExitErr{Code: ExitNotFound, Err: err}
+	}
+
+	return e, err
+}
+
+// sendJournaled reissues a journaled request, with its original body,
+// through the matching client method.
+func sendJournaled(ctx context.Context, client *api.Client, e journal.Entry, b []byte) (*http.Response, error) {
+	var body io.Reader = bytes.NewReader(b)
+
+	switch e.Method {
+	case http.MethodPost:
+		return client.Post(ctx, e.Path, body)
+	case http.MethodPut:
+		return client.Put(ctx, e.Path, body)
+	case http.MethodPatch:
+		return client.Patch(ctx, e.Path, body)
+	case http.MethodDelete:
+		return client.Delete(ctx, e.Path)
+	default:
+		return nil, usagef("cannot retry %s requests", e.Method)
+	}
+}
+
+// decodeOptionalJSON decodes a JSON response body, tolerating empty bodies
+// (e.g. DELETE responses).
+func decodeOptionalJSON(resp *http.Response) (any, error) {
+	defer func() { _ = resp.Body.Close() }()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	var v any
+
+	if len(bytes.TrimSpace(b)) > 0 {
+		if err := json.Unmarshal(b, &v); err != nil {
+			return nil, fmt.Errorf("decode response: %w", err)
+		}
+	}
+
+	return v, nil
+}
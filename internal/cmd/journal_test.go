@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/journal"
+)
+
+func TestJournal_RecordsWrites(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var gotKey string
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			gotKey = r.Header.Get("Idempotency-Key")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if strings.HasSuffix(r.URL.Path, "/addresses") {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 200})
+	}))
+
+	_ = captureStdout(t)
+	if err := Execute([]string{"customer", "anonymize", "200", "--force"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"journal", "list", "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	var entries []journal.Entry
+	if err := json.Unmarshal(buf.Bytes(), &entries); err != nil {
+		t.Fatalf("unmarshal: %v (output: %q)", err, buf.String())
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(entries))
+	}
+
+	e := entries[0]
+	if e.ID != gotKey || e.Method != http.MethodPut || e.Path != "customers/200" || e.Status != journal.StatusOK {
+		t.Errorf("entry = %+v, key %q", e, gotKey)
+	}
+}
+
+func TestJournal_NoJournalFlag(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Idempotency-Key") != "" {
+			t.Error("unexpected Idempotency-Key with --no-journal")
+		}
+
+		if strings.HasSuffix(r.URL.Path, "/addresses") {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{}`))
+	}))
+
+	_ = captureStdout(t)
+	if err := Execute([]string{"customer", "anonymize", "200", "--force", "--no-journal"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	j, err := newJournal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if entries, _ := j.List(); len(entries) != 0 {
+		t.Errorf("entries = %+v, want none", entries)
+	}
+}
+
+func seedJournalEntry(t *testing.T, status journal.Status) string {
+	t.Helper()
+
+	j, err := newJournal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := j.Begin("k1", "123", http.MethodPost, "products", []byte(`{"name":"x"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := j.Finish("k1", status, 0, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	return "k1"
+}
+
+func TestJournalRetry_ReusesKey(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	id := seedJournalEntry(t, journal.StatusUnknown)
+
+	var gotKey, gotBody string
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("Idempotency-Key")
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":9}`))
+	}))
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"journal", "retry", id, "--force", "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if gotKey != id || gotBody != `{"name":"x"}` {
+		t.Errorf("key = %q, body = %q", gotKey, gotBody)
+	}
+
+	if !strings.Contains(buf.String(), `"status": "ok"`) {
+		t.Errorf("output = %q", buf.String())
+	}
+
+	j, _ := newJournal()
+
+	e, err := j.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Attempts != 2 || e.Status != journal.StatusOK {
+		t.Errorf("entry = %+v, want 2 attempts, ok", e)
+	}
+}
+
+func TestJournalRetry_RefusesSucceeded(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	id := seedJournalEntry(t, journal.StatusOK)
+
+	_ = captureStderr(t)
+
+	err := Execute([]string{"journal", "retry", id})
+	if ExitCode(err) != ExitUsage {
+		t.Fatalf("exit code = %d, want %d", ExitCode(err), ExitUsage)
+	}
+}
+
+func TestJournalRetry_POSTMayBeAppliedNeedsForce(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	id := seedJournalEntry(t, journal.StatusUnknown)
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("unexpected request without --force")
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	_ = captureStderr(t)
+
+	err := Execute([]string{"journal", "retry", id})
+	if ExitCode(err) != ExitUsage || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("err = %v (exit %d), want a usage error asking for --force", err, ExitCode(err))
+	}
+}
+
+func TestJournalShow_NotFound(t *testing.T) {
+	setupConfigDir(t)
+	_ = captureStderr(t)
+
+	err := Execute([]string{"journal", "show", "missing"})
+	if ExitCode(err) != ExitNotFound {
+		t.Fatalf("exit code = %d, want %d", ExitCode(err), ExitNotFound)
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/i18n"
+)
+
+// resolveLang picks the output language from --lang, NUBE_LANG, or the
+// config file's lang, in that order. It runs before parsing because help
+// and parse errors are printed while parsing.
+func resolveLang(args []string, getenv func(string) string) string {
+	for i, a := range args {
+		if a == "--" {
+			break
+		}
+
+		if v, ok := strings.CutPrefix(a, "--lang="); ok {
+			return v
+		}
+
+		if a == "--lang" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+
+	if v := getenv("NUBE_LANG"); v != "" {
+		return v
+	}
+
+	if cfg, err := config.ReadConfig(); err == nil {
+		return cfg.Lang
+	}
+
+	return ""
+}
+
+// translateHelp translates the help text of node, its flags and arguments,
+// and its subcommands in place.
+func translateHelp(node *kong.Node, tr *i18n.Translator) {
+	if tr.Lang() == i18n.English {
+		return
+	}
+
+	node.Help = tr.T(node.Help)
+	node.Detail = tr.T(node.Detail)
+
+	for _, f := range node.Flags {
+		f.Help = tr.T(f.Help)
+	}
+
+	for _, p := range node.Positional {
+		p.Help = tr.T(p.Help)
+	}
+
+	for _, child := range node.Children {
+		translateHelp(child, tr)
+	}
+}
+
+// helpHeadings are the fixed lines of kong's help output.
+var helpHeadings = []string{"Usage:", "Arguments:", "Flags:", "Commands:"}
+
+// translateHelpOutput translates the headings of rendered help text.
+func translateHelpOutput(tr *i18n.Translator, help, appName string) string {
+	if tr.Lang() == i18n.English {
+		return help
+	}
+
+	lines := strings.Split(help, "\n")
+
+	for i, line := range lines {
+		for _, h := range helpHeadings {
+			if rest, ok := strings.CutPrefix(line, h); ok {
+				lines[i] = tr.T(h) + rest
+			}
+		}
+	}
+
+	help = strings.Join(lines, "\n")
+
+	const more = `Run "%s <command> --help" for more information on a command.`
+
+	return strings.ReplaceAll(help,
+		strings.ReplaceAll(more, "%s", appName),
+		strings.ReplaceAll(tr.T(more), "%s", appName))
+}
+
+// translatedHeader translates the column names of the first line written to
+// it, the table header, and passes everything else through.
+type translatedHeader struct {
+	w    io.Writer
+	tr   *i18n.Translator
+	done bool
+}
+
+func (h *translatedHeader) Write(p []byte) (int, error) {
+	if h.done {
+		return h.w.Write(p)
+	}
+
+	h.done = true
+
+	line, rest, found := bytes.Cut(p, []byte("\n"))
+
+	cols := strings.Split(string(line), "\t")
+	for i, c := range cols {
+		cols[i] = h.tr.T(c)
+	}
+
+	out := strings.Join(cols, "\t")
+	if found {
+		out += "\n" + string(rest)
+	}
+
+	if _, err := io.WriteString(h.w, out); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
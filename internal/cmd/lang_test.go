@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/i18n"
+)
+
+func TestResolveLang(t *testing.T) {
+	setupConfigDir(t)
+
+	if err := config.WriteConfig(config.File{Lang: "pt"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := resolveLang([]string{"version"}, os.Getenv); got != "pt" {
+		t.Errorf("config: %q, want pt", got)
+	}
+
+	t.Setenv("NUBE_LANG", "es")
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"version"}, "es"},
+		{[]string{"--lang", "en", "version"}, "en"},
+		{[]string{"product", "list", "--lang=pt"}, "pt"},
+		{[]string{"batch", "run", "--", "--lang=pt"}, "es"},
+	}
+
+	for _, tt := range tests {
+		if got := resolveLang(tt.args, os.Getenv); got != tt.want {
+			t.Errorf("resolveLang(%v) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestLang_HelpAndTables(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"shop": {StoreID: "123", AccessToken: "tok"}}, "shop")
+	t.Cleanup(func() { i18n.SetDefault(nil) })
+
+	buf := captureStdout(t)
+	_ = Execute([]string{"order", "list", "--help", "--lang", "es"})
+
+	help := buf.String()
+	for _, want := range []string{"Uso: nube order", "Lista pedidos", "Opciones:", "Ejemplos:"} {
+		if !strings.Contains(help, want) {
+			t.Errorf("help lacks %q:\n%s", want, help)
+		}
+	}
+
+	buf = captureStdout(t)
+	if err := Execute([]string{"auth", "list", "--lang", "pt"}); err != nil {
+		t.Fatalf("auth list: %v", err)
+	}
+
+	if out := buf.String(); !strings.Contains(out, "NOME") {
+		t.Errorf("table header not translated: %q", out)
+	}
+
+	buf = captureStdout(t)
+	if err := Execute([]string{"auth", "list", "--lang", "pt", "--plain"}); err != nil {
+		t.Fatalf("auth list --plain: %v", err)
+	}
+
+	if out := buf.String(); strings.Contains(out, "NOME") {
+		t.Errorf("--plain header translated: %q", out)
+	}
+}
+
+func TestLang_Unsupported(t *testing.T) {
+	_ = captureStderr(t)
+
+	if err := Execute([]string{"version", "--lang", "fr"}); ExitCode(err) != ExitUsage {
+		t.Errorf("ExitCode = %d, want %d (err %v)", ExitCode(err), ExitUsage, err)
+	}
+}
+
+func TestLangPriority(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"shop": {StoreID: "123", AccessToken: "tok"}}, "shop")
+	t.Cleanup(func() { setLangPriority(nil) })
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"id": 1, "name": map[string]any{"es": "Remera", "pt": "Camiseta"}},
+		})
+	}))
+
+	list := func(args ...string) string {
+		t.Helper()
+
+		buf := captureStdout(t)
+		if err := Execute(append([]string{"product", "list", "--page", "1", "--raw-numbers"}, args...)); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+
+		return buf.String()
+	}
+
+	if out := list(); !strings.Contains(out, "Remera") {
+		t.Errorf("default order: %q, want Spanish name", out)
+	}
+
+	if out := list("--lang-priority", "pt,es"); !strings.Contains(out, "Camiseta") {
+		t.Errorf("--lang-priority pt: %q, want Portuguese name", out)
+	}
+
+	if err := config.WriteConfig(config.File{LangPriority: []string{"pt-BR", "en"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if out := list(); !strings.Contains(out, "Camiseta") {
+		t.Errorf("config lang_priority: %q, want Portuguese name", out)
+	}
+
+	if out := list("--lang-priority", "es"); !strings.Contains(out, "Remera") {
+		t.Errorf("flag over config: %q, want Spanish name", out)
+	}
+
+	_ = captureStderr(t)
+
+	if err := Execute([]string{"product", "list", "--lang-priority", "pt,xx"}); ExitCode(err) != ExitUsage {
+		t.Errorf("ExitCode = %d, want %d (err %v)", ExitCode(err), ExitUsage, err)
+	}
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/gberlati/nube-cli/internal/logfile"
+	"github.com/gberlati/nube-cli/internal/redact"
+)
+
+// setupLogging installs the process-wide logger (see newRunLogHandler). The
+// returned func closes the log file.
+func setupLogging(format, file string, level slog.Level, stderr io.Writer, redactor *redact.Redactor) (func(), error) {
+	handler, closeLog, err := newRunLogHandler(format, file, level, stderr, redactor)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.SetDefault(slog.New(handler))
+
+	return closeLog, nil
+}
+
+// newRunLogHandler returns a run's log handler: text or JSON records on
+// stderr and, with a log file, the same records appended to it. The returned
+// func closes the file.
+func newRunLogHandler(format, file string, level slog.Level, stderr io.Writer, redactor *redact.Redactor) (slog.Handler, func(), error) {
+	handler := newLogHandler(format, stderr, level)
+	closeLog := func() {}
+
+	if file != "" {
+		f, err := logfile.Open(file, logfile.DefaultMaxSize, logfile.DefaultBackups)
+		if err != nil {
+			return nil, nil, &ExitErr{Code: ExitConfig, Err: fmt.Errorf("--log-file: %w", err)}
+		}
+
+		handler = slog.NewMultiHandler(handler, newLogHandler(format, f, level))
+		closeLog = func() { _ = f.Close() }
+	}
+
+	return redactor.Handler(handler), closeLog, nil
+}
+
+func newLogHandler(format string, w io.Writer, level slog.Level) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	if format == "json" {
+		return slog.NewJSONHandler(w, opts)
+	}
+
+	return slog.NewTextHandler(w, opts)
+}
+
+type logHandlerCtxKey struct{}
+
+// withLogHandler gives a served run its own log handler. Runs share the
+// daemon's process-wide logger, so it reaches records logged with ctx
+// through ctxLogHandler.
+func withLogHandler(ctx context.Context, h slog.Handler) context.Context {
+	return context.WithValue(ctx, logHandlerCtxKey{}, h)
+}
+
+// ctxLogHandler is the daemon's default log handler: a record goes to the
+// handler of the run whose ctx it was logged with, and to next when it was
+// logged without one.
+type ctxLogHandler struct {
+	next slog.Handler
+	// wrap replays WithAttrs and WithGroup on a run's handler.
+	wrap []func(slog.Handler) slog.Handler
+}
+
+func (h *ctxLogHandler) handler(ctx context.Context) slog.Handler {
+	run, ok := ctx.Value(logHandlerCtxKey{}).(slog.Handler)
+	if !ok {
+		return h.next
+	}
+
+	for _, w := range h.wrap {
+		run = w(run)
+	}
+
+	return run
+}
+
+func (h *ctxLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler(ctx).Enabled(ctx, level)
+}
+
+func (h *ctxLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler(ctx).Handle(ctx, r) //nolint:wrapcheck // passes the record on
+}
+
+func (h *ctxLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(h.next.WithAttrs(attrs), func(run slog.Handler) slog.Handler { return run.WithAttrs(attrs) })
+}
+
+func (h *ctxLogHandler) WithGroup(name string) slog.Handler {
+	return h.with(h.next.WithGroup(name), func(run slog.Handler) slog.Handler { return run.WithGroup(name) })
+}
+
+func (h *ctxLogHandler) with(next slog.Handler, w func(slog.Handler) slog.Handler) *ctxLogHandler {
+	return &ctxLogHandler{next: next, wrap: append(append([]func(slog.Handler) slog.Handler(nil), h.wrap...), w)}
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestLogging_JSONAndFile(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"id":123}`))
+	}))
+
+	logPath := filepath.Join(t.TempDir(), "logs", "nube.log")
+
+	_ = captureStdout(t)
+	stderr := captureStderr(t)
+
+	if err := Execute([]string{"store", "get", "--json", "-v", "--log-format", "json", "--log-file", logPath}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	fileLog, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+
+	for name, out := range map[string]string{"stderr": stderr.String(), "log file": string(fileLog)} {
+		found := false
+
+		sc := bufio.NewScanner(strings.NewReader(out))
+		for sc.Scan() {
+			var rec map[string]any
+			if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+				t.Fatalf("%s line %q is not JSON: %v", name, sc.Text(), err)
+			}
+
+			if rec["msg"] == "api response" && rec["level"] == "DEBUG" {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Errorf("%s has no api response record:\n%s", name, out)
+		}
+	}
+}
+
+func TestLogging_BadLogFile(t *testing.T) {
+	setupConfigDir(t)
+	_ = captureStderr(t)
+
+	// A directory can't be opened as the log file.
+	err := Execute([]string{"config", "path", "--log-file", t.TempDir()})
+	if code := ExitCode(err); code != ExitConfig {
+		t.Fatalf("exit code = %d (err %v), want %d", code, err, ExitConfig)
+	}
+}
+
+func TestLogging_InvalidFormat(t *testing.T) {
+	_ = captureStderr(t)
+
+	err := Execute([]string{"config", "path", "--log-format", "xml"})
+	if code := ExitCode(err); code != ExitUsage {
+		t.Fatalf("exit code = %d (err %v), want %d", code, err, ExitUsage)
+	}
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+)
+
+// currencySymbols are the symbols shown for a store's own currency. Other
+// currencies are shown by ISO code, since "$" alone can mean any of several.
+var currencySymbols = map[string]string{
+	"ARS": "$",
+	"BRL": "R$",
+	"CLP": "$",
+	"COP": "$",
+	"MXN": "$",
+	"PEN": "S/",
+	"UYU": "$",
+	"USD": "US$",
+	"EUR": "€",
+}
+
+// zeroDecimalCurrencies are priced in whole units.
+var zeroDecimalCurrencies = map[string]bool{"CLP": true, "COP": true, "PYG": true}
+
+// decimalCommaCountries write 1.234,56 rather than 1,234.56.
+var decimalCommaCountries = map[string]bool{
+	"AR": true, "BR": true, "CL": true, "CO": true, "PY": true, "UY": true,
+}
+
+// moneyFormatter renders API amounts ("150.00") in tables as the store's
+// customers would read them ("$ 150,00").
+type moneyFormatter struct {
+	raw      bool
+	currency string
+	decimal  string
+	group    string
+}
+
+// newMoneyFormatter returns a formatter for the client's store. JSON, plain
+// output and --raw-numbers keep amounts as the API returns them; so does a
+// store whose settings can't be fetched.
+func newMoneyFormatter(ctx context.Context, flags *RootFlags, client *api.Client) *moneyFormatter {
+	if flags.RawNumbers || outfmt.IsJSON(ctx) || outfmt.IsPlain(ctx) {
+		return &moneyFormatter{raw: true}
+	}
+
+	info, err := loadStoreInfo(ctx, client)
+	if err != nil {
+		slog.DebugContext(ctx, "store settings unavailable; showing raw amounts", "err", err)
+
+		return &moneyFormatter{raw: true}
+	}
+
+	m := &moneyFormatter{currency: info.MainCurrency, decimal: ".", group: ","}
+	if decimalCommaCountries[info.Country] {
+		m.decimal, m.group = ",", "."
+	}
+
+	return m
+}
+
+// lazyMoneyFormatter defers newMoneyFormatter until an amount is shown, so
+// tables without amount columns don't look up the store.
+func lazyMoneyFormatter(ctx context.Context, flags *RootFlags, client *api.Client) func() *moneyFormatter {
+	return sync.OnceValue(func() *moneyFormatter { return newMoneyFormatter(ctx, flags, client) })
+}
+
+// format renders amount in currency, or in the store's currency when
+// currency is empty. Anything that isn't a number is returned unchanged.
+func (m *moneyFormatter) format(amount, currency string) string {
+	if currency == "" {
+		currency = m.currency
+	}
+
+	if m.raw || currency == "" {
+		return amount
+	}
+
+	v, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return amount
+	}
+
+	decimals := 2
+	if zeroDecimalCurrencies[currency] {
+		decimals = 0
+	}
+
+	symbol := currency
+	if s, ok := currencySymbols[currency]; ok && currency == m.currency {
+		symbol = s
+	}
+
+	sign := ""
+	if v < 0 {
+		sign = "-"
+	}
+
+	return sign + symbol + " " + m.number(math.Abs(v), decimals)
+}
+
+// number formats v with the formatter's decimal and grouping separators.
+func (m *moneyFormatter) number(v float64, decimals int) string {
+	digits, frac, _ := strings.Cut(strconv.FormatFloat(v, 'f', decimals, 64), ".")
+
+	var b strings.Builder
+
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(m.group)
+		}
+
+		b.WriteRune(d)
+	}
+
+	if frac != "" {
+		b.WriteString(m.decimal)
+		b.WriteString(frac)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestMoneyFormatter(t *testing.T) {
+	t.Parallel()
+
+	ar := &moneyFormatter{currency: "ARS", decimal: ",", group: "."}
+	mx := &moneyFormatter{currency: "MXN", decimal: ".", group: ","}
+
+	tests := []struct {
+		m        *moneyFormatter
+		amount   string
+		currency string
+		want     string
+	}{
+		{ar, "150.00", "", "$ 150,00"},
+		{ar, "1234567.5", "ARS", "$ 1.234.567,50"},
+		{ar, "99.9", "USD", "USD 99,90"},
+		{ar, "-20", "", "-$ 20,00"},
+		{ar, "15000", "CLP", "CLP 15.000"},
+		{mx, "1500.00", "", "$ 1,500.00"},
+		{mx, "", "", ""},
+		{mx, "n/a", "", "n/a"},
+		{&moneyFormatter{raw: true, currency: "ARS"}, "150.00", "", "150.00"},
+		{&moneyFormatter{}, "150.00", "", "150.00"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.m.format(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("format(%q, %q) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+// moneyStore serves a BRL store and one order, counting store fetches.
+func moneyStore(t *testing.T, storeGets *atomic.Int32) http.Handler {
+	t.Helper()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/123/store":
+			storeGets.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": 123, "country": "BR", "main_currency": "BRL"})
+		case "/v1/123/orders":
+			_ = json.NewEncoder(w).Encode([]map[string]any{
+				{"id": 101, "number": "1001", "total": "1500.00", "currency": "BRL"},
+			})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	})
+}
+
+func TestOrderList_FormatsTotals(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var storeGets atomic.Int32
+
+	setupMockAPIClient(t, moneyStore(t, &storeGets))
+
+	for range 2 {
+		buf := captureStdout(t)
+		if err := Execute([]string{"order", "list", "--page", "1"}); err != nil {
+			t.Fatalf("error = %v", err)
+		}
+
+		if out := buf.String(); !strings.Contains(out, "R$ 1.500,00") {
+			t.Errorf("output = %q, want the total in reais", out)
+		}
+	}
+
+	if n := storeGets.Load(); n != 1 {
+		t.Errorf("store fetched %d times, want 1 (cached)", n)
+	}
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"order", "list", "--page", "1", "--raw-numbers"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if out := buf.String(); !strings.Contains(out, "1500.00") || strings.Contains(out, "R$") {
+		t.Errorf("--raw-numbers output = %q", out)
+	}
+}
+
+func TestStoreInfo_CacheExpires(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	path, err := storeInfoPath("123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stale := storeInfo{ID: "123", Country: "AR", MainCurrency: "ARS"}
+	if err := writeStoreInfo(path, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := readStoreInfo(path); ok {
+		t.Fatal("stale cache entry was used")
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := readStoreInfo(path); ok {
+		t.Error("missing cache entry was used")
+	}
+}
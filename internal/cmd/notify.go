@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// telegramAPIBase is the Telegram Bot API root. It is a package-level var so
+// tests can swap it.
+var telegramAPIBase = "https://api.telegram.org"
+
+// NotifyCmd groups notification commands.
+type NotifyCmd struct {
+	Orders NotifyOrdersCmd `cmd:"" help:"Post a chat message for every new order"`
+}
+
+type NotifyOrdersCmd struct {
+	To             string        `help:"Chat service to notify" name:"to" enum:"slack,discord,telegram" required:""`
+	WebhookURL     string        `help:"Incoming webhook URL (Slack, Discord)" name:"webhook-url" env:"NUBE_NOTIFY_WEBHOOK_URL"`
+	TelegramToken  string        `help:"Telegram bot token" name:"telegram-token" env:"NUBE_TELEGRAM_TOKEN"`
+	TelegramChatID string        `help:"Telegram chat ID" name:"telegram-chat-id" env:"NUBE_TELEGRAM_CHAT_ID"`
+	Interval       time.Duration `help:"Time between polls" name:"interval" default:"30s"`
+	SinceID        string        `help:"Notify orders after this ID (default: orders placed from now on)" name:"since-id"`
+	Once           bool          `help:"Poll once and exit, e.g. from cron" name:"once"`
+}
+
+func (c *NotifyOrdersCmd) Run(ctx context.Context, flags *RootFlags) error {
+	if !c.Once {
+		if err := refuseNested(ctx, "notify orders without --once"); err != nil {
+			return err
+		}
+	}
+
+	send, err := c.notifier(flags.Timeout)
+	if err != nil {
+		return err
+	}
+
+	if c.Interval <= 0 {
+		return usagef("--interval must be positive")
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	poller, err := newOrderPoller(ctx, client, c.SinceID, c.Interval)
+	if err != nil {
+		return err
+	}
+
+	u := ui.FromContext(ctx)
+	failed := 0
+
+	err = poller.run(ctx, c.Once, func(o map[string]any) {
+		text := formatOrderNotification(o)
+
+		if flags.DryRun {
+			_ = writeResult(ctx, u, kv("dry_run", true), kv("order_id", jsonStr(o, "id")), kv("message", text))
+
+			return
+		}
+
+		if sendErr := send(ctx, text); sendErr != nil {
+			failed++
+
+			slog.WarnContext(ctx, "order notification failed", "order_id", jsonStr(o, "id"), "err", sendErr)
+
+			return
+		}
+
+		_ = writeResult(ctx, u, kv("notified", true), kv("order_id", jsonStr(o, "id")), kv("number", jsonStr(o, "number")))
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d notifications failed", failed)
+	}
+
+	return nil
+}
+
+// notifier returns a function posting text to the selected service.
+func (c *NotifyOrdersCmd) notifier(timeout time.Duration) (func(ctx context.Context, text string) error, error) {
+	switch c.To {
+	case "telegram":
+		if c.TelegramToken == "" || c.TelegramChatID == "" {
+			return nil, usagef("--to telegram requires --telegram-token and --telegram-chat-id")
+		}
+
+		endpoint := telegramAPIBase + "/bot" + c.TelegramToken + "/sendMessage"
+
+		return func(ctx context.Context, text string) error {
+			return postNotification(ctx, timeout, endpoint, map[string]string{"chat_id": c.TelegramChatID, "text": text})
+		}, nil
+	default:
+		if c.WebhookURL == "" {
+			return nil, usagef("--to %s requires --webhook-url", c.To)
+		}
+
+		// Slack reads "text", Discord reads "content".
+		key := "text"
+		if c.To == "discord" {
+			key = "content"
+		}
+
+		return func(ctx context.Context, text string) error {
+			return postNotification(ctx, timeout, c.WebhookURL, map[string]string{key: text})
+		}, nil
+	}
+}
+
+// formatOrderNotification renders an order as a one-line chat message.
+func formatOrderNotification(o map[string]any) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "New order #%s: %s %s", jsonStr(o, "number"), jsonStr(o, "total"), jsonStr(o, "currency"))
+
+	if cust, ok := o["customer"].(map[string]any); ok {
+		if name := jsonStr(cust, "name"); name != "" {
+			fmt.Fprintf(&b, " from %s", name)
+		}
+	}
+
+	if items, ok := o["products"].([]any); ok && len(items) > 0 {
+		fmt.Fprintf(&b, " (%d items)", len(items))
+	}
+
+	return b.String()
+}
+
+func postNotification(ctx context.Context, timeout time.Duration, endpoint string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		// The URL embeds credentials (webhook path, bot token); keep it out of logs.
+		return fmt.Errorf("post notification: %w", unwrapURLError(err))
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("post notification: HTTP %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// unwrapURLError drops the *url.Error wrapper, which repeats the URL.
+func unwrapURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+
+	return err
+}
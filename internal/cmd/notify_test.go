@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestFormatOrderNotification(t *testing.T) {
+	t.Parallel()
+
+	got := formatOrderNotification(map[string]any{
+		"number":   float64(1042),
+		"total":    "15000.00",
+		"currency": "ARS",
+		"customer": map[string]any{"name": "Lucía Gómez"},
+		"products": []any{map[string]any{}, map[string]any{}},
+	})
+
+	if want := "New order #1042: 15000.00 ARS from Lucía Gómez (2 items)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// chatServer records the JSON bodies posted to it.
+func chatServer(t *testing.T) (*httptest.Server, func() []map[string]string) {
+	t.Helper()
+
+	var (
+		mu     sync.Mutex
+		bodies []map[string]string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var b map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&b)
+		b["path"] = r.URL.Path
+
+		mu.Lock()
+		bodies = append(bodies, b)
+		mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func() []map[string]string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return slices.Clone(bodies)
+	}
+}
+
+func TestNotifyOrders_Slack(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("since_id"); got != "10" {
+			t.Errorf("since_id = %q, want 10", got)
+		}
+
+		_, _ = w.Write([]byte(`[{"id":12,"number":2,"total":"20.00","currency":"BRL"},{"id":11,"number":1,"total":"10.00","currency":"BRL"}]`))
+	}))
+
+	chat, bodies := chatServer(t)
+	_ = captureStdout(t)
+
+	err := Execute([]string{"notify", "orders", "--to", "slack", "--webhook-url", chat.URL, "--since-id", "10", "--once"})
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	got := bodies()
+	if len(got) != 2 || got[0]["text"] != "New order #1: 10.00 BRL" || got[1]["text"] != "New order #2: 20.00 BRL" {
+		t.Errorf("messages = %v", got)
+	}
+}
+
+func TestNotifyOrders_TelegramStartsAtNewestOrder(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("per_page") == "1" {
+			_, _ = w.Write([]byte(`[{"id":20}]`))
+
+			return
+		}
+
+		if got := r.URL.Query().Get("since_id"); got != "20" {
+			t.Errorf("since_id = %q, want 20", got)
+		}
+
+		_, _ = w.Write([]byte(`[{"id":21,"number":7,"total":"99.00","currency":"MXN"}]`))
+	}))
+
+	chat, bodies := chatServer(t)
+
+	orig := telegramAPIBase
+	telegramAPIBase = chat.URL
+	t.Cleanup(func() { telegramAPIBase = orig })
+
+	_ = captureStdout(t)
+
+	err := Execute([]string{"notify", "orders", "--to", "telegram", "--telegram-token", "T", "--telegram-chat-id", "42", "--once"})
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	got := bodies()
+	if len(got) != 1 || got[0]["path"] != "/botT/sendMessage" || got[0]["chat_id"] != "42" || got[0]["text"] != "New order #7: 99.00 MXN" {
+		t.Errorf("messages = %v", got)
+	}
+}
+
+func TestNotifyOrders_Usage(t *testing.T) {
+	t.Setenv("NUBE_NOTIFY_WEBHOOK_URL", "")
+	t.Setenv("NUBE_TELEGRAM_TOKEN", "")
+
+	for _, args := range [][]string{
+		{"notify", "orders", "--to", "slack"},
+		{"notify", "orders", "--to", "telegram", "--telegram-chat-id", "1"},
+		{"notify", "orders", "--to", "discord", "--webhook-url", "http://x", "--interval", "0s"},
+	} {
+		if err := Execute(args); ExitCode(err) != ExitUsage {
+			t.Errorf("%v: ExitCode = %d, want %d (err %v)", args, ExitCode(err), ExitUsage, err)
+		}
+	}
+}
@@ -2,10 +2,8 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 	"net/url"
-	"os"
 
 	"github.com/gberlati/nube-cli/internal/api"
 	"github.com/gberlati/nube-cli/internal/outfmt"
@@ -14,23 +12,29 @@ import (
 
 // OrderCmd groups order-related commands.
 type OrderCmd struct {
-	List OrderListCmd `cmd:"" help:"List orders"`
-	Get  OrderGetCmd  `cmd:"" help:"Get an order by ID"`
+	List      OrderListCmd      `cmd:"" help:"List orders"`
+	Get       OrderGetCmd       `cmd:"" help:"Get an order by ID"`
+	Edit      OrderEditCmd      `cmd:"" help:"Edit an order in $EDITOR and save the changes"`
+	Items     OrderItemsCmd     `cmd:"" help:"List an order's line items"`
+	Item      OrderItemCmd      `cmd:"" help:"Edit draft order line items"`
+	Refund    OrderRefundCmd    `cmd:"" help:"Refund all or part of an order"`
+	Label     OrderLabelCmd     `cmd:"" help:"Download an order's shipping labels"`
+	Note      OrderNoteCmd      `cmd:"" help:"Edit the customer's note on an order"`
+	OwnerNote OrderOwnerNoteCmd `cmd:"" name:"owner-note" help:"Edit the store's internal note on an order"`
+	Tag       OrderTagCmd       `cmd:"" help:"Add or remove order tags"`
 }
 
 // OrderListCmd lists orders with pagination and filters.
 type OrderListCmd struct {
 	PaginationFlags `embed:""`
+	DateFilterFlags `embed:""`
+	ColumnsFlags    `embed:""`
 
 	SinceID        string `help:"Return orders after this ID" name:"since-id"`
 	Status         string `help:"Filter by status (open/closed/cancelled)" name:"status"`
 	PaymentStatus  string `help:"Filter by payment status (pending/authorized/paid/voided/refunded)" name:"payment-status"`
 	ShippingStatus string `help:"Filter by shipping status (unpacked/shipped/unshipped/delivered)" name:"shipping-status"`
 	Channels       string `help:"Filter by sales channel" name:"channels"`
-	CreatedMin     string `help:"Created after (ISO 8601)" name:"created-at-min"`
-	CreatedMax     string `help:"Created before (ISO 8601)" name:"created-at-max"`
-	UpdatedMin     string `help:"Updated after (ISO 8601)" name:"updated-at-min"`
-	UpdatedMax     string `help:"Updated before (ISO 8601)" name:"updated-at-max"`
 	CustomerIDs    string `help:"Comma-separated customer IDs" name:"customer-ids"`
 	Query          string `help:"Search query" short:"q" name:"q"`
 	Fields         string `help:"Comma-separated fields to return from API" name:"fields"`
@@ -52,15 +56,20 @@ func (c *OrderListCmd) Run(ctx context.Context, flags *RootFlags) error {
 	addQueryParam(q, "payment_status", c.PaymentStatus)
 	addQueryParam(q, "shipping_status", c.ShippingStatus)
 	addQueryParam(q, "channels", c.Channels)
-	addQueryParam(q, "created_at_min", c.CreatedMin)
-	addQueryParam(q, "created_at_max", c.CreatedMax)
-	addQueryParam(q, "updated_at_min", c.UpdatedMin)
-	addQueryParam(q, "updated_at_max", c.UpdatedMax)
 	addQueryParam(q, "customer_ids", c.CustomerIDs)
 	addQueryParam(q, "q", c.Query)
 	addQueryParam(q, "fields", c.Fields)
 	addQueryParam(q, "aggregates", c.Aggregates)
 
+	zone := newZoneResolver(flags, client)
+	if err := c.resolve(ctx, q, zone); err != nil {
+		return err
+	}
+
+	if q, err = c.resume("orders", q); err != nil {
+		return err
+	}
+
 	var items []map[string]any
 
 	if c.WantsAllPages() {
@@ -78,26 +87,38 @@ func (c *OrderListCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, items)
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), items)
+	}
+
+	money := lazyMoneyFormatter(ctx, flags, client)
+
+	times, err := newTimeFormatter(ctx, flags, zone)
+	if err != nil {
+		return err
 	}
 
-	w, done := tableWriter(ctx)
-	defer done()
-
-	_, _ = fmt.Fprintln(w, "ID\tNUMBER\tSTATUS\tPAYMENT\tSHIPPING\tTOTAL\tCREATED")
-
-	for _, o := range items {
-		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", //nolint:gosec // tabwriter, not HTML
-			jsonStr(o, "id"),
-			jsonStr(o, "number"),
-			jsonStr(o, "status"),
-			jsonStr(o, "payment_status"),
-			jsonStr(o, "shipping_status"),
-			jsonStr(o, "total"),
-			jsonStr(o, "created_at"),
-		)
+	cols, err := c.pick([]column{
+		textColumn("id", "id"),
+		textColumn("number", "number"),
+		textColumn("status", "status"),
+		textColumn("payment", "payment_status"),
+		textColumn("shipping", "shipping_status"),
+		{name: "subtotal", value: func(o map[string]any) string {
+			return money().format(jsonStr(o, "subtotal"), jsonStr(o, "currency"))
+		}},
+		{name: "total", value: func(o map[string]any) string {
+			return money().format(jsonStr(o, "total"), jsonStr(o, "currency"))
+		}},
+		{name: "customer", value: func(o map[string]any) string { return fieldValue(o, "customer.name") }},
+		{name: "created", value: func(o map[string]any) string { return times.format(jsonStr(o, "created_at")) }},
+		{name: "updated", value: func(o map[string]any) string { return times.format(jsonStr(o, "updated_at")) }},
+	}, "id", "number", "status", "payment", "shipping", "total", "created")
+	if err != nil {
+		return err
 	}
 
+	writeItemsTable(ctx, cols, items)
+
 	_ = u
 
 	return nil
@@ -124,7 +145,7 @@ func (c *OrderGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 
 	resp, err := client.Get(ctx, "orders/"+c.OrderID, q) //nolint:bodyclose // DecodeResponse closes body
 	if err != nil {
-		return err
+		return withDidYouMean(ctx, client, orderDidYouMean, c.OrderID, err)
 	}
 
 	data, err := api.DecodeResponse[map[string]any](resp)
@@ -133,18 +154,25 @@ func (c *OrderGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, data)
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), data)
 	}
 
+	times, err := newTimeFormatter(ctx, flags, newZoneResolver(flags, client))
+	if err != nil {
+		return err
+	}
+
+	money := newMoneyFormatter(ctx, flags, client)
+
 	return writeResult(ctx, u,
 		kv("id", jsonStr(data, "id")),
 		kv("number", jsonStr(data, "number")),
 		kv("status", jsonStr(data, "status")),
 		kv("payment_status", jsonStr(data, "payment_status")),
 		kv("shipping_status", jsonStr(data, "shipping_status")),
-		kv("total", jsonStr(data, "total")),
+		kv("total", money.format(jsonStr(data, "total"), jsonStr(data, "currency"))),
 		kv("currency", jsonStr(data, "currency")),
-		kv("created_at", jsonStr(data, "created_at")),
-		kv("updated_at", jsonStr(data, "updated_at")),
+		kv("created_at", times.format(jsonStr(data, "created_at"))),
+		kv("updated_at", times.format(jsonStr(data, "updated_at"))),
 	)
 }
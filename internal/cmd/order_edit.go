@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// OrderNoteCmd edits the customer's note on an order.
+type OrderNoteCmd struct {
+	Set OrderNoteSetCmd `cmd:"" help:"Replace the customer's note on an order (\"\" clears it)"`
+}
+
+type OrderNoteSetCmd struct {
+	OrderID string `arg:"" name:"order-id" help:"Order ID"`
+	Text    string `arg:"" name:"text" help:"Note text"`
+}
+
+func (c *OrderNoteSetCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return setOrderNote(ctx, flags, c.OrderID, "note", c.Text)
+}
+
+// OrderOwnerNoteCmd edits the store's internal note on an order, which the
+// customer never sees.
+type OrderOwnerNoteCmd struct {
+	Set OrderOwnerNoteSetCmd `cmd:"" help:"Replace the store's internal note on an order (\"\" clears it)"`
+}
+
+type OrderOwnerNoteSetCmd struct {
+	OrderID string `arg:"" name:"order-id" help:"Order ID"`
+	Text    string `arg:"" name:"text" help:"Note text"`
+}
+
+func (c *OrderOwnerNoteSetCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return setOrderNote(ctx, flags, c.OrderID, "owner_note", c.Text)
+}
+
+// setOrderNote sets the note field of an order; empty text clears it.
+func setOrderNote(ctx context.Context, flags *RootFlags, orderID, field, text string) error {
+	u := ui.FromContext(ctx)
+
+	var value any = text
+	if text == "" {
+		value = nil
+	}
+
+	update := map[string]any{field: value}
+
+	if flags.DryRun {
+		return writeResult(ctx, u,
+			kv("dry_run", true),
+			kv("order_id", orderID),
+			kv("update", update),
+		)
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	if _, err := updateOrder(ctx, client, orderID, update); err != nil {
+		return err
+	}
+
+	return writeResult(ctx, u,
+		kv("updated", true),
+		kv("order_id", orderID),
+		kv(field, text),
+	)
+}
+
+// OrderTagCmd adds and removes order tags.
+type OrderTagCmd struct {
+	Add    OrderTagAddCmd    `cmd:"" help:"Add tags to an order"`
+	Remove OrderTagRemoveCmd `cmd:"" help:"Remove tags from an order"`
+}
+
+type OrderTagAddCmd struct {
+	OrderID string   `arg:"" name:"order-id" help:"Order ID"`
+	Tags    []string `arg:"" name:"tag" help:"Tags to add"`
+}
+
+func (c *OrderTagAddCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return editOrderTags(ctx, flags, c.OrderID, func(tags []string) []string {
+		for _, t := range c.Tags {
+			if t = strings.TrimSpace(t); t != "" && !slices.Contains(tags, t) {
+				tags = append(tags, t)
+			}
+		}
+
+		return tags
+	})
+}
+
+type OrderTagRemoveCmd struct {
+	OrderID string   `arg:"" name:"order-id" help:"Order ID"`
+	Tags    []string `arg:"" name:"tag" help:"Tags to remove"`
+}
+
+func (c *OrderTagRemoveCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return editOrderTags(ctx, flags, c.OrderID, func(tags []string) []string {
+		return slices.DeleteFunc(tags, func(t string) bool {
+			return slices.Contains(c.Tags, t)
+		})
+	})
+}
+
+// editOrderTags reads an order's tags, applies edit and writes them back.
+// Orders whose tags don't change aren't written.
+func editOrderTags(ctx context.Context, flags *RootFlags, orderID string, edit func([]string) []string) error {
+	u := ui.FromContext(ctx)
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Get(ctx, "orders/"+orderID, nil) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return err
+	}
+
+	order, err := api.DecodeResponse[map[string]any](resp)
+	if err != nil {
+		return err
+	}
+
+	before := orderTags(order)
+	after := edit(slices.Clone(before))
+	changed := !slices.Equal(before, after)
+
+	// The API keeps tags as one comma-separated string, as for products.
+	update := map[string]any{"tags": strings.Join(after, ",")}
+
+	switch {
+	case flags.DryRun:
+		return writeResult(ctx, u,
+			kv("dry_run", true),
+			kv("order_id", orderID),
+			kv("tags", after),
+			kv("changed", changed),
+		)
+	case changed:
+		if _, err := updateOrder(ctx, client, orderID, update); err != nil {
+			return err
+		}
+	}
+
+	return writeResult(ctx, u,
+		kv("order_id", orderID),
+		kv("tags", after),
+		kv("changed", changed),
+	)
+}
+
+// orderTags returns an order's tags, which the API may send as a
+// comma-separated string or a list.
+func orderTags(order map[string]any) []string {
+	var raw []string
+
+	switch v := order["tags"].(type) {
+	case string:
+		raw = strings.Split(v, ",")
+	case []any:
+		for _, t := range v {
+			if s, ok := t.(string); ok {
+				raw = append(raw, s)
+			}
+		}
+	}
+
+	tags := make([]string, 0, len(raw))
+
+	for _, t := range raw {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+
+	return tags
+}
+
+// updateOrder sends update to the order update endpoint and returns the
+// updated order.
+func updateOrder(ctx context.Context, client *api.Client, orderID string, update map[string]any) (map[string]any, error) {
+	body, err := jsonBody(update)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Put(ctx, "orders/"+orderID, body) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return nil, err
+	}
+
+	return api.DecodeResponse[map[string]any](resp)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOrderNoteSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		field string
+		want  any
+	}{
+		{"note", []string{"order", "note", "set", "101", "Leave at the door"}, "note", "Leave at the door"},
+		{"owner note", []string{"order", "owner-note", "set", "101", "VIP"}, "owner_note", "VIP"},
+		{"clear", []string{"order", "owner-note", "set", "101", ""}, "owner_note", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]any
+
+			setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// The write is preceded by a GET for the undo snapshot.
+				if r.Method == http.MethodPut {
+					_ = json.NewDecoder(r.Body).Decode(&body)
+				}
+
+				_, _ = w.Write([]byte(`{"id":101}`))
+			}))
+
+			_ = captureStdout(t)
+
+			if err := Execute(append(tt.args, "--json")); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+
+			if v, ok := body[tt.field]; !ok || v != tt.want || len(body) != 1 {
+				t.Errorf("body = %v, want only %s=%v", body, tt.field, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderTag(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want *string // tags sent, nil when nothing is written
+	}{
+		{"add", []string{"order", "tag", "add", "101", "gift", "rush"}, new("wholesale,gift,rush")},
+		{"add existing", []string{"order", "tag", "add", "101", "wholesale"}, nil},
+		{"remove", []string{"order", "tag", "remove", "101", "wholesale"}, new("")},
+		{"remove missing", []string{"order", "tag", "remove", "101", "gift"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var put *string
+
+			setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPut {
+					var body struct{ Tags string }
+					_ = json.NewDecoder(r.Body).Decode(&body)
+					put = &body.Tags
+				}
+
+				_, _ = w.Write([]byte(`{"id":101,"tags":"wholesale, "}`))
+			}))
+
+			_ = captureStdout(t)
+
+			if err := Execute(append(tt.args, "--json")); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+
+			switch {
+			case tt.want == nil && put != nil:
+				t.Errorf("unchanged tags were written: %q", *put)
+			case tt.want != nil && (put == nil || *put != *tt.want):
+				t.Errorf("tags written = %v, want %q", put, *tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderNoteSet_DryRun(t *testing.T) {
+	setupMockAPIClient(t, http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+
+	stdout := captureStdout(t)
+
+	if err := Execute([]string{"order", "note", "set", "101", "hi", "--dry-run", "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if !strings.Contains(stdout.String(), `"dry_run": true`) {
+		t.Errorf("output = %s", stdout.String())
+	}
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// OrderItemsCmd lists an order's line items, which order get leaves out.
+type OrderItemsCmd struct {
+	ColumnsFlags `embed:""`
+
+	OrderID string `arg:"" name:"order-id" help:"Order ID"`
+}
+
+func (c *OrderItemsCmd) Run(ctx context.Context, flags *RootFlags) error {
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	q := url.Values{"fields": {"id,currency,products"}}
+
+	resp, err := client.Get(ctx, "orders/"+c.OrderID, q) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return err
+	}
+
+	order, err := api.DecodeResponse[map[string]any](resp)
+	if err != nil {
+		return err
+	}
+
+	items := lineItems(order)
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), items)
+	}
+
+	money := lazyMoneyFormatter(ctx, flags, client)
+	currency := jsonStr(order, "currency")
+
+	cols, err := c.pick([]column{
+		textColumn("id", "id"),
+		textColumn("sku", "sku"),
+		i18nColumn("name", "name"),
+		textColumn("variant", "variant_id"),
+		textColumn("qty", "quantity"),
+		{name: "price", value: func(it map[string]any) string {
+			return money().format(jsonStr(it, "price"), currency)
+		}},
+		{name: "subtotal", value: func(it map[string]any) string {
+			return money().format(lineSubtotal(it), currency)
+		}},
+	}, "sku", "name", "qty", "price", "subtotal")
+	if err != nil {
+		return err
+	}
+
+	writeItemsTable(ctx, cols, items)
+
+	return nil
+}
+
+// lineItems returns the products of an order or draft order.
+func lineItems(order map[string]any) []map[string]any {
+	raw, _ := order["products"].([]any)
+
+	items := make([]map[string]any, 0, len(raw))
+
+	for _, p := range raw {
+		if m, ok := p.(map[string]any); ok {
+			items = append(items, m)
+		}
+	}
+
+	return items
+}
+
+// lineSubtotal is price × quantity, or "" when either isn't a number.
+func lineSubtotal(item map[string]any) string {
+	price, err := strconv.ParseFloat(jsonStr(item, "price"), 64)
+	if err != nil {
+		return ""
+	}
+
+	qty, err := strconv.ParseFloat(jsonStr(item, "quantity"), 64)
+	if err != nil {
+		return ""
+	}
+
+	return strconv.FormatFloat(price*qty, 'f', 2, 64)
+}
+
+// OrderItemCmd edits line items. Placed orders are immutable, so only
+// draft orders can be edited.
+type OrderItemCmd struct {
+	Update OrderItemUpdateCmd `cmd:"" help:"Change the quantity or price of a draft order's line item"`
+}
+
+type OrderItemUpdateCmd struct {
+	DraftOrderID string `arg:"" name:"draft-order-id" help:"Draft order ID"`
+	ItemID       string `arg:"" name:"item-id" help:"Line item ID, or its variant ID"`
+	Quantity     int    `help:"New quantity" name:"quantity"`
+	Price        string `help:"New unit price (e.g. 1500.00)" name:"price"`
+}
+
+func (c *OrderItemUpdateCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	if c.Quantity == 0 && c.Price == "" {
+		return usagef("nothing to update: pass --quantity and/or --price")
+	}
+
+	if c.Quantity < 0 {
+		return usagef("--quantity must be positive")
+	}
+
+	if c.Price != "" {
+		if v, err := strconv.ParseFloat(c.Price, 64); err != nil || v < 0 {
+			return usagef("--price %q: want a non-negative amount such as 1500.00", c.Price)
+		}
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	path := "draft_orders/" + c.DraftOrderID
+
+	resp, err := client.Get(ctx, path, nil) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return err
+	}
+
+	draft, err := api.DecodeResponse[map[string]any](resp)
+	if err != nil {
+		return err
+	}
+
+	// The draft's products are replaced as a whole, so every line is sent
+	// back with only the matching one changed.
+	items := lineItems(draft)
+	products := make([]map[string]any, 0, len(items))
+	found := false
+
+	for _, it := range items {
+		line := map[string]any{
+			"variant_id": it["variant_id"],
+			"quantity":   it["quantity"],
+			"price":      it["price"],
+		}
+
+		if jsonStr(it, "id") == c.ItemID || jsonStr(it, "variant_id") == c.ItemID {
+			found = true
+
+			if c.Quantity > 0 {
+				line["quantity"] = c.Quantity
+			}
+
+			if c.Price != "" {
+				line["price"] = c.Price
+			}
+		}
+
+		products = append(products, line)
+	}
+
+	if !found {
+		return &ExitErr{Code: ExitNotFound, Err: fmt.Errorf("draft order %s has no line item %s (items: %s)", c.DraftOrderID, c.ItemID, itemIDs(items))}
+	}
+
+	update := map[string]any{"products": products}
+
+	if flags.DryRun {
+		return writeResult(ctx, u,
+			kv("dry_run", true),
+			kv("draft_order_id", c.DraftOrderID),
+			kv("update", update),
+		)
+	}
+
+	body, err := jsonBody(update)
+	if err != nil {
+		return err
+	}
+
+	resp, err = client.Put(ctx, path, body) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return err
+	}
+
+	if _, err := api.DecodeResponse[map[string]any](resp); err != nil {
+		return err
+	}
+
+	return writeResult(ctx, u,
+		kv("updated", true),
+		kv("draft_order_id", c.DraftOrderID),
+		kv("item_id", c.ItemID),
+	)
+}
+
+func itemIDs(items []map[string]any) string {
+	ids := make([]string, 0, len(items))
+	for _, it := range items {
+		ids = append(ids, jsonStr(it, "id"))
+	}
+
+	return strings.Join(ids, ", ")
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const orderWithItems = `{"id":101,"currency":"ARS","products":[
+	{"id":1,"variant_id":11,"sku":"TEE-M","name":"Tee","quantity":2,"price":"100.00"},
+	{"id":2,"variant_id":22,"sku":"CAP","name":"Cap","quantity":1,"price":"50.50"}]}`
+
+func TestOrderItems(t *testing.T) {
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/123/orders/101" {
+			http.NotFound(w, r)
+			return
+		}
+
+		_, _ = w.Write([]byte(orderWithItems))
+	}))
+
+	stdout := captureStdout(t)
+
+	if err := Execute([]string{"order", "items", "101", "--plain"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	want := "SKU\tNAME\tQTY\tPRICE\tSUBTOTAL\nTEE-M\tTee\t2\t100.00\t200.00\nCAP\tCap\t1\t50.50\t50.50\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("output =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestOrderItemUpdate(t *testing.T) {
+	var sent map[string][]map[string]any
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/123/draft_orders/101" {
+			http.NotFound(w, r)
+			return
+		}
+
+		if r.Method == http.MethodPut {
+			_ = json.NewDecoder(r.Body).Decode(&sent)
+		}
+
+		_, _ = w.Write([]byte(orderWithItems))
+	}))
+
+	_ = captureStdout(t)
+
+	if err := Execute([]string{"order", "item", "update", "101", "22", "--quantity", "3", "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	products := sent["products"]
+	if len(products) != 2 {
+		t.Fatalf("products sent = %v", products)
+	}
+
+	if products[0]["quantity"] != float64(2) || products[1]["quantity"] != float64(3) || products[1]["price"] != "50.50" {
+		t.Errorf("products sent = %v", products)
+	}
+}
+
+func TestOrderItemUpdate_Errors(t *testing.T) {
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(orderWithItems))
+	}))
+
+	stderr := captureStderr(t)
+
+	tests := []struct {
+		args []string
+		code int
+	}{
+		{[]string{"order", "item", "update", "101", "1"}, ExitUsage},
+		{[]string{"order", "item", "update", "101", "1", "--price", "abc"}, ExitUsage},
+		{[]string{"order", "item", "update", "101", "99", "--quantity", "1"}, ExitNotFound},
+	}
+
+	for _, tt := range tests {
+		if code := ExitCode(Execute(tt.args)); code != tt.code {
+			t.Errorf("%v: exit code = %d, want %d", tt.args, code, tt.code)
+		}
+	}
+
+	if !strings.Contains(stderr.String(), "items: 1, 2") {
+		t.Errorf("stderr = %s", stderr.String())
+	}
+}
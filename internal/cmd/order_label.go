@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// OrderLabelCmd downloads the shipping labels of an order's fulfillment
+// orders.
+type OrderLabelCmd struct {
+	OrderID string `arg:"" name:"order-id" help:"Order ID"`
+	Out     string `help:"File to save the label to, or - for stdout (default: label-<order-id>.pdf); further labels get -2, -3, ... before the extension" name:"out" short:"o"`
+}
+
+// orderLabel is one label document of a fulfillment order.
+type orderLabel struct {
+	FulfillmentOrderID string `json:"fulfillment_order_id"`
+	URL                string `json:"-"`
+	Path               string `json:"path"`
+	Bytes              int64  `json:"bytes"`
+}
+
+func (c *OrderLabelCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Get(ctx, "orders/"+c.OrderID+"/fulfillment-orders", nil) //nolint:bodyclose // decodeList closes body
+	if err != nil {
+		return err
+	}
+
+	fulfillments, err := decodeList(resp)
+	if err != nil {
+		return err
+	}
+
+	var labels []orderLabel
+
+	for _, fo := range fulfillments {
+		for _, link := range labelURLs(fo) {
+			labels = append(labels, orderLabel{FulfillmentOrderID: jsonStr(fo, "id"), URL: link})
+		}
+	}
+
+	if len(labels) == 0 {
+		return &ExitErr{Code: ExitNotFound, Err: fmt.Errorf("order %s has no shipping labels (the shipping method may not issue them)", c.OrderID)}
+	}
+
+	out := c.Out
+	if out == "" {
+		out = "label-" + c.OrderID + ".pdf"
+	}
+
+	if out == "-" && len(labels) > 1 {
+		return usagef("order %s has %d labels; pass --out with a file name to save them all", c.OrderID, len(labels))
+	}
+
+	for i := range labels {
+		labels[i].Path = numberedPath(out, i)
+
+		n, err := downloadLabel(ctx, flags.Timeout, labels[i].URL, labels[i].Path)
+		if err != nil {
+			return err
+		}
+
+		labels[i].Bytes = n
+	}
+
+	if out == "-" {
+		return nil
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"order_id": c.OrderID, "labels": labels})
+	}
+
+	for _, l := range labels {
+		u.Out().Printf("%s\t%d bytes", l.Path, l.Bytes)
+	}
+
+	return nil
+}
+
+// labelURLs returns the label document URLs of a fulfillment order, which
+// may come as a "labels" list, a single "label", or a "label_url".
+func labelURLs(fo map[string]any) []string {
+	var urls []string
+
+	add := func(v any) {
+		switch l := v.(type) {
+		case string:
+			if l != "" {
+				urls = append(urls, l)
+			}
+		case map[string]any:
+			if s := jsonStr(l, "url"); s != "" {
+				urls = append(urls, s)
+			}
+		}
+	}
+
+	if list, ok := fo["labels"].([]any); ok {
+		for _, l := range list {
+			add(l)
+		}
+	}
+
+	add(fo["label"])
+	add(fo["label_url"])
+
+	return urls
+}
+
+// numberedPath returns path for the first label and path with -2, -3, ...
+// before the extension for the next ones.
+func numberedPath(path string, i int) string {
+	if i == 0 || path == "-" {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+
+	return strings.TrimSuffix(path, ext) + "-" + strconv.Itoa(i+1) + ext
+}
+
+// downloadLabel saves the document at url to path ("-" for stdout). Label
+// URLs are signed links on the carrier's or the platform's storage, so no
+// API credentials are sent with them.
+func downloadLabel(ctx context.Context, timeout time.Duration, url, path string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("label url: %w", err)
+	}
+
+	req.Header.Set("User-Agent", api.DefaultUserAgent)
+
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("download label: %w", unwrapURLError(err))
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return 0, fmt.Errorf("download label: HTTP %d", resp.StatusCode)
+	}
+
+	if path == "-" {
+		n, err := io.Copy(stdoutFrom(ctx), resp.Body)
+		if err != nil {
+			return n, fmt.Errorf("download label: %w", err)
+		}
+
+		return n, nil
+	}
+
+	path, err = expandPath(path)
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := os.Create(path) //nolint:gosec // user-provided path
+	if err != nil {
+		return 0, fmt.Errorf("create %s: %w", path, err)
+	}
+
+	n, err := io.Copy(f, resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return n, fmt.Errorf("write %s: %w", path, err)
+	}
+
+	return n, nil
+}
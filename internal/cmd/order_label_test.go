@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOrderLabel(t *testing.T) {
+	var labelAuth []string
+
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		labelAuth = append(labelAuth, r.Header.Get("Authentication"))
+		_, _ = w.Write([]byte("%PDF " + r.URL.Path))
+	}))
+	t.Cleanup(files.Close)
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id":"fo1","labels":[{"url":"` + files.URL + `/a"}]},
+			{"id":"fo2","label_url":"` + files.URL + `/b"},
+			{"id":"fo3"}]`))
+	}))
+
+	_ = captureStdout(t)
+
+	out := filepath.Join(t.TempDir(), "label.pdf")
+	if err := Execute([]string{"order", "label", "101", "--out", out}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	for path, want := range map[string]string{out: "%PDF /a", filepath.Join(filepath.Dir(out), "label-2.pdf"): "%PDF /b"} {
+		b, err := os.ReadFile(path)
+		if err != nil || string(b) != want {
+			t.Errorf("%s = %q, %v; want %q", path, b, err, want)
+		}
+	}
+
+	for _, a := range labelAuth {
+		if a != "" {
+			t.Errorf("label download sent API credentials: %q", a)
+		}
+	}
+}
+
+func TestOrderLabel_None(t *testing.T) {
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":"fo1"}]`))
+	}))
+
+	_ = captureStderr(t)
+
+	if code := ExitCode(Execute([]string{"order", "label", "101", "--out", filepath.Join(t.TempDir(), "l.pdf")})); code != ExitNotFound {
+		t.Errorf("exit code = %d, want %d", code, ExitNotFound)
+	}
+}
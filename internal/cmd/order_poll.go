@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+)
+
+// orderPoller follows new orders by polling the list endpoint with since_id,
+// so long-running commands can react to orders as they arrive.
+type orderPoller struct {
+	client   *api.Client
+	interval time.Duration
+	sinceID  int64
+}
+
+// newOrderPoller starts after sinceID, or after the store's newest order when
+// sinceID is empty, so only orders placed from now on are reported.
+func newOrderPoller(ctx context.Context, client *api.Client, sinceID string, interval time.Duration) (*orderPoller, error) {
+	p := &orderPoller{client: client, interval: interval}
+
+	if sinceID != "" {
+		id, err := strconv.ParseInt(sinceID, 10, 64)
+		if err != nil {
+			return nil, usagef("--since-id %q: want an order ID", sinceID)
+		}
+
+		p.sinceID = id
+
+		return p, nil
+	}
+
+	// The list is sorted newest first.
+	q := url.Values{}
+	q.Set("per_page", "1")
+	q.Set("fields", "id")
+
+	resp, err := client.Get(ctx, "orders", q) //nolint:bodyclose // decodeList closes body
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := decodeList(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(latest) > 0 {
+		p.sinceID = orderID(latest[0])
+	}
+
+	return p, nil
+}
+
+// poll returns the orders created since the last poll, oldest first.
+func (p *orderPoller) poll(ctx context.Context) ([]map[string]any, error) {
+	q := url.Values{}
+	q.Set("since_id", strconv.FormatInt(p.sinceID, 10))
+
+	orders, err := api.CollectAllPages(ctx, p.client, "orders", q, decodeList)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(orders, func(a, b map[string]any) int {
+		return cmp.Compare(orderID(a), orderID(b))
+	})
+
+	if len(orders) > 0 {
+		p.sinceID = max(p.sinceID, orderID(orders[len(orders)-1]))
+	}
+
+	return orders, nil
+}
+
+// run polls until ctx is done (or once), calling fn for every new order.
+// Poll errors end the run; fn decides for itself whether its errors matter.
+func (p *orderPoller) run(ctx context.Context, once bool, fn func(order map[string]any)) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		orders, err := p.poll(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, o := range orders {
+			fn(o)
+		}
+
+		if once {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func orderID(o map[string]any) int64 {
+	id, _ := strconv.ParseInt(jsonStr(o, "id"), 10, 64)
+
+	return id
+}
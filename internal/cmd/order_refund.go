@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// OrderRefundCmd refunds all or part of a paid order.
+type OrderRefundCmd struct {
+	OrderID string `arg:"" name:"order-id" help:"Order ID"`
+	Amount  string `help:"Amount to refund (default: everything not yet refunded)" name:"amount"`
+	Reason  string `help:"Reason, kept with the refund" name:"reason"`
+	Restock bool   `help:"Return the order's items to stock" name:"restock"`
+}
+
+func (c *OrderRefundCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	var requested int64
+
+	if c.Amount != "" {
+		cents, err := parseCents(c.Amount)
+		if err != nil || cents <= 0 {
+			return usagef("--amount %q: want a positive amount such as 1500.00", c.Amount)
+		}
+
+		requested = cents
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	plan, err := planRefund(ctx, client, c.OrderID, requested)
+	if err != nil {
+		return err
+	}
+
+	money := newMoneyFormatter(ctx, flags, client)
+	show := func(cents int64) string { return money.format(formatCents(cents), plan.currency) }
+
+	payload := map[string]any{
+		"type":    "refund",
+		"amount":  map[string]any{"value": formatCents(plan.amount), "currency": plan.currency},
+		"restock": c.Restock,
+	}
+	if c.Reason != "" {
+		payload["reason"] = c.Reason
+	}
+
+	if flags.DryRun {
+		return writeResult(ctx, u,
+			kv("dry_run", true),
+			kv("order_id", c.OrderID),
+			kv("amount", formatCents(plan.amount)),
+			kv("currency", plan.currency),
+			kv("already_refunded", formatCents(plan.refunded)),
+			kv("remaining_after", formatCents(plan.total-plan.refunded-plan.amount)),
+			kv("restock", c.Restock),
+		)
+	}
+
+	action := fmt.Sprintf("refund %s on order %s (total %s, already refunded %s, %s left after this)",
+		show(plan.amount), c.OrderID, show(plan.total), show(plan.refunded), show(plan.total-plan.refunded-plan.amount))
+	if c.Restock {
+		action += " and restock its items"
+	}
+
+	if err := confirmDestructive(flags, action); err != nil {
+		return err
+	}
+
+	body, err := jsonBody(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Post(ctx, "orders/"+c.OrderID+"/transactions", body) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return err
+	}
+
+	tx, err := api.DecodeResponse[map[string]any](resp)
+	if err != nil {
+		return err
+	}
+
+	return writeResult(ctx, u,
+		kv("refunded", true),
+		kv("order_id", c.OrderID),
+		kv("transaction_id", jsonStr(tx, "id")),
+		kv("amount", formatCents(plan.amount)),
+		kv("currency", plan.currency),
+		kv("restock", c.Restock),
+	)
+}
+
+// refundPlan holds a refund's amounts in cents.
+type refundPlan struct {
+	currency string
+	total    int64
+	refunded int64
+	amount   int64
+}
+
+// planRefund works out how much of the order can still be refunded and
+// checks requested (0 for all of it) against that.
+func planRefund(ctx context.Context, client *api.Client, orderID string, requested int64) (refundPlan, error) {
+	resp, err := client.Get(ctx, "orders/"+orderID, nil) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return refundPlan{}, err
+	}
+
+	order, err := api.DecodeResponse[map[string]any](resp)
+	if err != nil {
+		return refundPlan{}, err
+	}
+
+	total, err := parseCents(jsonStr(order, "total"))
+	if err != nil {
+		return refundPlan{}, fmt.Errorf("order %s: unreadable total %q", orderID, jsonStr(order, "total"))
+	}
+
+	resp, err = client.Get(ctx, "orders/"+orderID+"/transactions", nil) //nolint:bodyclose // decodeList closes body
+	if err != nil {
+		return refundPlan{}, err
+	}
+
+	txs, err := decodeList(resp)
+	if err != nil {
+		return refundPlan{}, err
+	}
+
+	plan := refundPlan{currency: jsonStr(order, "currency"), total: total}
+
+	for _, tx := range txs {
+		if jsonStr(tx, "type") != "refund" || jsonStr(tx, "status") == "failure" {
+			continue
+		}
+
+		if cents, err := parseCents(transactionAmount(tx)); err == nil {
+			plan.refunded += cents
+		}
+	}
+
+	left := plan.total - plan.refunded
+	if left <= 0 {
+		return refundPlan{}, usagef("order %s is already fully refunded", orderID)
+	}
+
+	plan.amount = left
+	if requested > 0 {
+		if requested > left {
+			return refundPlan{}, usagef("--amount %s is more than the %s left to refund", formatCents(requested), formatCents(left))
+		}
+
+		plan.amount = requested
+	}
+
+	return plan, nil
+}
+
+// transactionAmount returns a transaction's amount, which the API sends
+// either as a string or as {"value", "currency"}.
+func transactionAmount(tx map[string]any) string {
+	if m, ok := tx["amount"].(map[string]any); ok {
+		return jsonStr(m, "value")
+	}
+
+	return jsonStr(tx, "amount")
+}
+
+// parseCents parses a decimal amount ("150.5") into cents, so amounts can
+// be added up exactly.
+func parseCents(s string) (int64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	return int64(math.Round(v * 100)), nil
+}
+
+func formatCents(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
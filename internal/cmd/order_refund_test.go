@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func refundServer(t *testing.T, posted *map[string]any) http.Handler {
+	t.Helper()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/123/orders/101/transactions":
+			_ = json.NewDecoder(r.Body).Decode(posted)
+			_, _ = w.Write([]byte(`{"id":900}`))
+		case r.URL.Path == "/v1/123/orders/101/transactions":
+			_, _ = w.Write([]byte(`[
+				{"id":1,"type":"sale","status":"success","amount":{"value":"150.00"}},
+				{"id":2,"type":"refund","status":"success","amount":{"value":"30.00"}},
+				{"id":3,"type":"refund","status":"failure","amount":{"value":"99.00"}}]`))
+		case r.URL.Path == "/v1/123/orders/101":
+			_, _ = w.Write([]byte(`{"id":101,"total":"150.00","currency":"BRL"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func TestOrderRefund(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"partial", []string{"--amount", "20.5", "--reason", "damaged", "--restock"}, "20.50"},
+		{"rest", nil, "120.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var posted map[string]any
+
+			setupMockAPIClient(t, refundServer(t, &posted))
+			_ = captureStdout(t)
+
+			args := append([]string{"order", "refund", "101", "--force", "--json"}, tt.args...)
+			if err := Execute(args); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+
+			amount, _ := posted["amount"].(map[string]any)
+			if posted["type"] != "refund" || amount["value"] != tt.want || amount["currency"] != "BRL" {
+				t.Errorf("posted = %v, want amount %s", posted, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderRefund_Refused(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"too much", []string{"--amount", "120.01", "--force"}, ExitUsage},
+		{"bad amount", []string{"--amount", "-5", "--force"}, ExitUsage},
+		{"no confirmation", []string{"--no-input"}, ExitUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var posted map[string]any
+
+			setupMockAPIClient(t, refundServer(t, &posted))
+			_ = captureStderr(t)
+
+			err := Execute(append([]string{"order", "refund", "101"}, tt.args...))
+			if code := ExitCode(err); code != tt.code {
+				t.Errorf("exit code = %d, want %d (%v)", code, tt.code, err)
+			}
+
+			if posted != nil {
+				t.Errorf("refund was posted: %v", posted)
+			}
+		})
+	}
+}
+
+func TestParseCents(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"150", 15000},
+		{"0.1", 10},
+		{"19.99", 1999},
+		{"1234.565", 123457},
+	}
+
+	for _, tt := range tests {
+		got, err := parseCents(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseCents(%q) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+
+	for cents, want := range map[int64]string{0: "0.00", 5: "0.05", 15000: "150.00", -2050: "-20.50"} {
+		if got := formatCents(cents); got != want {
+			t.Errorf("formatCents(%d) = %q, want %q", cents, got, want)
+		}
+	}
+}
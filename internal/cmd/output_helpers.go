@@ -1,15 +1,49 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"os"
+	"strings"
 	"text/tabwriter"
 
+	"github.com/gberlati/nube-cli/internal/i18n"
 	"github.com/gberlati/nube-cli/internal/outfmt"
 	"github.com/gberlati/nube-cli/internal/ui"
 )
 
+type stdioCtxKey struct{}
+
+type stdio struct {
+	out io.Writer
+	err io.Writer
+}
+
+// withStdio routes a single invocation's output. Daemon and batch runs execute
+// several commands in one process, so commands must never write to os.Stdout directly.
+func withStdio(ctx context.Context, out, errOut io.Writer) context.Context {
+	return context.WithValue(ctx, stdioCtxKey{}, stdio{out: out, err: errOut})
+}
+
+// stdoutFrom returns the invocation's stdout (os.Stdout if unset).
+func stdoutFrom(ctx context.Context) io.Writer {
+	if s, ok := ctx.Value(stdioCtxKey{}).(stdio); ok && s.out != nil {
+		return s.out
+	}
+
+	return os.Stdout
+}
+
+// stderrFrom returns the invocation's stderr (os.Stderr if unset).
+func stderrFrom(ctx context.Context) io.Writer {
+	if s, ok := ctx.Value(stdioCtxKey{}).(stdio); ok && s.err != nil {
+		return s.err
+	}
+
+	return os.Stderr
+}
+
 type resultKV struct {
 	Key   string
 	Value any
@@ -21,14 +55,55 @@ func kv(key string, value any) resultKV {
 
 func tableWriter(ctx context.Context) (io.Writer, func()) {
 	if outfmt.IsPlain(ctx) {
-		return os.Stdout, func() {}
+		return stdoutFrom(ctx), func() {}
+	}
+
+	out := stdoutFrom(ctx)
+	if u := ui.FromContext(ctx); u != nil && u.Out().ColorEnabled() {
+		out = &styledHeader{w: out, style: u.Out().Header}
 	}
 
-	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	// Plain output stays in English so scripts can rely on it.
+	if tr := i18n.FromContext(ctx); tr.Lang() != i18n.English {
+		return &translatedHeader{w: tw, tr: tr}, func() { _ = tw.Flush() }
+	}
 
 	return tw, func() { _ = tw.Flush() }
 }
 
+// styledHeader styles the first line written through it, the table header
+// as laid out by tabwriter, and passes everything else through.
+type styledHeader struct {
+	w     io.Writer
+	style func(string) string
+	buf   []byte
+	done  bool
+}
+
+func (h *styledHeader) Write(p []byte) (int, error) {
+	if h.done {
+		return h.w.Write(p)
+	}
+
+	h.buf = append(h.buf, p...)
+
+	line, rest, found := bytes.Cut(h.buf, []byte("\n"))
+	if !found {
+		return len(p), nil
+	}
+
+	h.done = true
+
+	header := strings.TrimRight(string(line), " ")
+	if _, err := io.WriteString(h.w, h.style(header)+"\n"+string(rest)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
 func writeResult(ctx context.Context, u *ui.UI, kvs ...resultKV) error {
 	if outfmt.IsJSON(ctx) {
 		m := make(map[string]any, len(kvs))
@@ -36,7 +111,7 @@ func writeResult(ctx context.Context, u *ui.UI, kvs ...resultKV) error {
 			m[kv.Key] = kv.Value
 		}
 
-		return outfmt.WriteJSON(ctx, os.Stdout, m)
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), m)
 	}
 
 	if u == nil {
@@ -44,11 +119,13 @@ func writeResult(ctx context.Context, u *ui.UI, kvs ...resultKV) error {
 	}
 
 	for _, kv := range kvs {
+		key := u.Out().Accent(kv.Key)
+
 		switch v := kv.Value.(type) {
 		case bool:
-			u.Out().Printf("%s\t%t", kv.Key, v)
+			u.Out().Printf("%s\t%t", key, v)
 		default:
-			u.Out().Printf("%s\t%v", kv.Key, kv.Value)
+			u.Out().Printf("%s\t%v", key, kv.Value)
 		}
 	}
 
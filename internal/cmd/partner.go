@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// PartnerCmd groups commands for the partners API, which manages a
+// partner's apps rather than a store. It uses its own partner profiles.
+type PartnerCmd struct {
+	Login   PartnerLoginCmd   `cmd:"" help:"Save a partners API token (read from stdin)"`
+	Logout  PartnerLogoutCmd  `cmd:"" help:"Remove a partner profile"`
+	List    PartnerListCmd    `cmd:"" help:"List partner profiles"`
+	Apps    PartnerAppsCmd    `cmd:"" help:"List your apps"`
+	Stores  PartnerStoresCmd  `cmd:"" help:"List the stores that installed an app"`
+	Metrics PartnerMetricsCmd `cmd:"" help:"Show an app's metrics"`
+}
+
+// PartnerFlags embeds --partner in commands that call the partners API.
+type PartnerFlags struct {
+	Partner string `help:"Partner profile name (default: NUBE_PARTNER, else the only profile)" name:"partner"`
+}
+
+// newPartnerClient resolves the partner profile and returns a partners API
+// client for it.
+func newPartnerClient(flags *RootFlags, partner string) (*api.Client, error) {
+	name, profile, err := credstore.ResolvePartner(partner)
+	if err != nil {
+		return nil, &ExitErr{Code: ExitConfig, Err: err}
+	}
+
+	if err := validateBaseURL(profile.APIBaseURL); err != nil && flags.APIBaseURL == "" {
+		return nil, &ExitErr{Code: ExitConfig, Err: fmt.Errorf("partner profile %q: api_base_url: %w", name, err)}
+	}
+
+	opts, err := clientOptions(flags, profile.APIBaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.NewPartner(profile.PartnerID, profile.AccessToken, opts...), nil
+}
+
+// --- Login / Logout ---
+
+type PartnerLoginCmd struct {
+	Name      string `arg:"" name:"name" help:"Partner profile name"`
+	PartnerID string `help:"Partner ID, shown in the partners portal" name:"partner-id" required:""`
+}
+
+func (c *PartnerLoginCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	name := strings.TrimSpace(c.Name)
+	if name == "" {
+		return usagef("profile name required")
+	}
+
+	if err := validateBaseURL(flags.APIBaseURL); err != nil {
+		return usagef("--api-base-url: %v", err)
+	}
+
+	// Tokens are read from stdin so they stay out of shell history and
+	// process listings.
+	done := waitingForInput()
+	b, err := io.ReadAll(os.Stdin)
+	done()
+
+	if err != nil {
+		return fmt.Errorf("read token: %w", err)
+	}
+
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return usagef("no token on stdin; pipe the partners API token, e.g. `pbpaste | nube partner login %s --partner-id %s`", name, c.PartnerID)
+	}
+
+	if err := credstore.SetPartner(name, credstore.PartnerProfile{
+		PartnerID:   c.PartnerID,
+		AccessToken: token,
+		CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+		APIBaseURL:  flags.APIBaseURL,
+	}); err != nil {
+		return err
+	}
+
+	return writeResult(ctx, u,
+		kv("stored", true),
+		kv("name", name),
+		kv("partner_id", c.PartnerID),
+	)
+}
+
+type PartnerLogoutCmd struct {
+	Name string `arg:"" name:"name" help:"Partner profile to remove"`
+}
+
+func (c *PartnerLogoutCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	if err := confirmDestructive(flags, fmt.Sprintf("remove partner profile %q", c.Name)); err != nil {
+		return err
+	}
+
+	if err := credstore.RemovePartner(c.Name); err != nil {
+		return &ExitErr{Code: ExitConfig, Err: err}
+	}
+
+	return writeResult(ctx, u,
+		kv("deleted", true),
+		kv("name", c.Name),
+	)
+}
+
+// --- List ---
+
+type PartnerListCmd struct{}
+
+func (c *PartnerListCmd) Run(ctx context.Context, _ *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	f, err := credstore.Read()
+	if err != nil {
+		return err
+	}
+
+	type item struct {
+		Name       string `json:"name"`
+		PartnerID  string `json:"partner_id"`
+		CreatedAt  string `json:"created_at,omitempty"`
+		APIBaseURL string `json:"api_base_url,omitempty"`
+	}
+
+	items := make([]item, 0, len(f.Partners))
+	for name, p := range f.Partners {
+		items = append(items, item{Name: name, PartnerID: p.PartnerID, CreatedAt: p.CreatedAt, APIBaseURL: p.APIBaseURL})
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"partners": items})
+	}
+
+	if len(items) == 0 {
+		u.Err().Println("No partner profiles configured")
+		return nil
+	}
+
+	w, done := tableWriter(ctx)
+	defer done()
+
+	_, _ = fmt.Fprintln(w, "NAME\tPARTNER ID\tCREATED")
+
+	for _, it := range items {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", it.Name, it.PartnerID, it.CreatedAt)
+	}
+
+	return nil
+}
+
+// --- Apps ---
+
+type PartnerAppsCmd struct {
+	PartnerFlags `embed:""`
+	ColumnsFlags `embed:""`
+}
+
+func (c *PartnerAppsCmd) Run(ctx context.Context, flags *RootFlags) error {
+	client, err := newPartnerClient(flags, c.Partner)
+	if err != nil {
+		return err
+	}
+
+	items, err := api.CollectAllPages(ctx, client, "apps", url.Values{}, decodeList)
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), items)
+	}
+
+	cols, err := c.pick([]column{
+		textColumn("id", "id"),
+		i18nColumn("name", "name"),
+		textColumn("installs", "installs"),
+		textColumn("status", "status"),
+	}, "id", "name", "installs", "status")
+	if err != nil {
+		return err
+	}
+
+	writeItemsTable(ctx, cols, items)
+
+	return nil
+}
+
+// --- Stores ---
+
+type PartnerStoresCmd struct {
+	PartnerFlags    `embed:""`
+	PaginationFlags `embed:""`
+	ColumnsFlags    `embed:""`
+
+	AppID string `arg:"" name:"app-id" help:"App ID"`
+}
+
+func (c *PartnerStoresCmd) Run(ctx context.Context, flags *RootFlags) error {
+	client, err := newPartnerClient(flags, c.Partner)
+	if err != nil {
+		return err
+	}
+
+	q := url.Values{}
+	c.Apply(q)
+
+	path := "apps/" + url.PathEscape(c.AppID) + "/stores"
+
+	if q, err = c.resume(path, q); err != nil {
+		return err
+	}
+
+	var items []map[string]any
+
+	if c.WantsAllPages() {
+		items, err = api.CollectAllPages(ctx, client, path, q, decodeList)
+	} else {
+		var resp *http.Response
+		resp, err = client.Get(ctx, path, q) //nolint:bodyclose // decodeList closes body
+		if err == nil {
+			items, err = decodeList(resp)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), items)
+	}
+
+	cols, err := c.pick([]column{
+		textColumn("id", "id"),
+		i18nColumn("name", "name"),
+		textColumn("domain", "original_domain"),
+		textColumn("installed", "installed_at"),
+	}, "id", "name", "domain", "installed")
+	if err != nil {
+		return err
+	}
+
+	writeItemsTable(ctx, cols, items)
+
+	return nil
+}
+
+// --- Metrics ---
+
+type PartnerMetricsCmd struct {
+	PartnerFlags `embed:""`
+
+	AppID string `arg:"" name:"app-id" help:"App ID"`
+}
+
+func (c *PartnerMetricsCmd) Run(ctx context.Context, flags *RootFlags) error {
+	client, err := newPartnerClient(flags, c.Partner)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Get(ctx, "apps/"+url.PathEscape(c.AppID)+"/metrics", nil) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return err
+	}
+
+	data, err := api.DecodeResponse[map[string]any](resp)
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), data)
+	}
+
+	// Metrics nest by period; flattening keeps every figure on its own row.
+	w, done := tableWriter(ctx)
+	defer done()
+
+	_, _ = fmt.Fprintln(w, "METRIC\tVALUE")
+
+	for _, f := range outfmt.FlattenValue(data) {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", f.Key, f.Value)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestPartnerLogin(t *testing.T) {
+	setupConfigDir(t)
+
+	stdout := captureStdout(t)
+
+	var err error
+
+	withStdin(t, "ptok\n", func() {
+		err = Execute([]string{"partner", "login", "agency", "--partner-id", "42", "--json"})
+	})
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	f, _ := credstore.Read()
+	if p := f.Partners["agency"]; p.PartnerID != "42" || p.AccessToken != "ptok" {
+		t.Errorf("partner profile = %+v", p)
+	}
+
+	if len(f.Stores) != 0 {
+		t.Errorf("stores = %v, want none", f.Stores)
+	}
+
+	if !strings.Contains(stdout.String(), `"partner_id": "42"`) {
+		t.Errorf("output = %s", stdout.String())
+	}
+}
+
+func TestPartnerLogin_NoToken(t *testing.T) {
+	setupConfigDir(t)
+	_ = captureStderr(t)
+
+	var err error
+
+	withStdin(t, "", func() {
+		err = Execute([]string{"partner", "login", "agency", "--partner-id", "42"})
+	})
+
+	if code := ExitCode(err); code != ExitUsage {
+		t.Errorf("exit code = %d, want %d", code, ExitUsage)
+	}
+}
+
+func TestPartnerApps(t *testing.T) {
+	setupConfigDir(t)
+
+	var auth string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authentication")
+
+		switch r.URL.Path {
+		case "/v1/42/apps":
+			_, _ = w.Write([]byte(`[{"id":7,"name":{"es":"Mi App"},"installs":120,"status":"published"}]`))
+		case "/v1/42/apps/7/metrics":
+			_, _ = w.Write([]byte(`{"installs":{"last_30_days":12},"uninstalls":{"last_30_days":2}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	if err := credstore.SetPartner("agency", credstore.PartnerProfile{PartnerID: "42", AccessToken: "ptok", APIBaseURL: srv.URL + "/v1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := captureStdout(t)
+
+	if err := Execute([]string{"partner", "apps", "--json"}); err != nil {
+		t.Fatalf("apps error = %v", err)
+	}
+
+	var apps []map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &apps); err != nil || len(apps) != 1 {
+		t.Fatalf("apps = %s (%v)", stdout.String(), err)
+	}
+
+	if auth != "bearer ptok" {
+		t.Errorf("Authentication = %q", auth)
+	}
+
+	stdout = captureStdout(t)
+
+	if err := Execute([]string{"partner", "metrics", "7", "--plain"}); err != nil {
+		t.Fatalf("metrics error = %v", err)
+	}
+
+	for _, want := range []string{"installs.last_30_days\t12", "uninstalls.last_30_days\t2"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("metrics output missing %q:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestPartnerApps_NoProfile(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"shop": {StoreID: "1", AccessToken: "tok"}}, "shop")
+	t.Setenv("NUBE_PARTNER", "")
+	_ = captureStderr(t)
+
+	// Store profiles don't stand in for partner ones.
+	if code := ExitCode(Execute([]string{"partner", "apps"})); code != ExitConfig {
+		t.Errorf("exit code = %d, want %d", code, ExitConfig)
+	}
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+)
+
+// PaymentCmd groups payment provider commands, for payment apps checking
+// what their installation registered in a store.
+type PaymentCmd struct {
+	Providers PaymentProvidersCmd `cmd:"" help:"Payment providers registered in the store"`
+	Options   PaymentOptionsCmd   `cmd:"" help:"Checkout payment options offered by the store's providers"`
+}
+
+type PaymentProvidersCmd struct {
+	List PaymentProviderListCmd `cmd:"" help:"List payment providers"`
+}
+
+type PaymentOptionsCmd struct {
+	List PaymentOptionListCmd `cmd:"" help:"List checkout payment options"`
+}
+
+// PaymentProviderListCmd lists the store's payment providers.
+type PaymentProviderListCmd struct {
+	ColumnsFlags `embed:""`
+}
+
+func (c *PaymentProviderListCmd) Run(ctx context.Context, flags *RootFlags) error {
+	providers, err := listPaymentProviders(ctx, flags, "")
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), providers)
+	}
+
+	cols, err := c.pick([]column{
+		textColumn("id", "id"),
+		textColumn("name", "name"),
+		textColumn("public_name", "public_name"),
+		textColumn("enabled", "enabled"),
+		{name: "options", value: func(p map[string]any) string { return strconv.Itoa(len(paymentOptions(p))) }},
+	}, "id", "name", "enabled", "options")
+	if err != nil {
+		return err
+	}
+
+	writeItemsTable(ctx, cols, providers)
+
+	return nil
+}
+
+// PaymentOptionListCmd lists the checkout payment options of every provider
+// (or one). Options aren't an endpoint of their own: each provider lists
+// its options in checkout_payment_options.
+type PaymentOptionListCmd struct {
+	ColumnsFlags `embed:""`
+
+	Provider string `help:"Only options of this payment provider ID" name:"provider"`
+}
+
+func (c *PaymentOptionListCmd) Run(ctx context.Context, flags *RootFlags) error {
+	providers, err := listPaymentProviders(ctx, flags, c.Provider)
+	if err != nil {
+		return err
+	}
+
+	options := []map[string]any{}
+
+	for _, p := range providers {
+		for _, o := range paymentOptions(p) {
+			o["provider_id"] = p["id"]
+			options = append(options, o)
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), options)
+	}
+
+	cols, err := c.pick([]column{
+		textColumn("provider", "provider_id"),
+		textColumn("id", "id"),
+		textColumn("name", "name"),
+		textColumn("integration", "integration_type"),
+		{name: "methods", value: func(o map[string]any) string { return joinStrings(o["supported_payment_method_types"]) }},
+		{name: "countries", value: func(o map[string]any) string { return joinStrings(o["supported_billing_countries"]) }},
+	}, "provider", "id", "name", "integration", "methods")
+	if err != nil {
+		return err
+	}
+
+	writeItemsTable(ctx, cols, options)
+
+	return nil
+}
+
+// listPaymentProviders returns every payment provider, or just the one with
+// ID id.
+func listPaymentProviders(ctx context.Context, flags *RootFlags, id string) ([]map[string]any, error) {
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return nil, err
+	}
+
+	if id == "" {
+		resp, err := client.Get(ctx, "payment_providers", nil) //nolint:bodyclose // decodeList closes body
+		if err != nil {
+			return nil, err
+		}
+
+		return decodeList(resp)
+	}
+
+	resp, err := client.Get(ctx, "payment_providers/"+id, nil) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := api.DecodeResponse[map[string]any](resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return []map[string]any{provider}, nil
+}
+
+func paymentOptions(provider map[string]any) []map[string]any {
+	raw, _ := provider["checkout_payment_options"].([]any)
+
+	options := make([]map[string]any, 0, len(raw))
+
+	for _, o := range raw {
+		if m, ok := o.(map[string]any); ok {
+			options = append(options, m)
+		}
+	}
+
+	return options
+}
+
+// joinStrings renders a JSON list of strings as "a, b".
+func joinStrings(v any) string {
+	list, _ := v.([]any)
+
+	parts := make([]string, 0, len(list))
+
+	for _, s := range list {
+		if str, ok := s.(string); ok {
+			parts = append(parts, str)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
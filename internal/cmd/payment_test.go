@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+const paymentProvider = `{"id":"42","name":"PagoFacil","enabled":true,"checkout_payment_options":[
+	{"id":"card","name":"Tarjeta","integration_type":"transparent","supported_payment_method_types":["credit_card","debit_card"]},
+	{"id":"cash","name":"Efectivo","integration_type":"redirect","supported_payment_method_types":["ticket"]}]}`
+
+func TestPaymentProviderList_Table(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/123/payment_providers" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		_, _ = w.Write([]byte("[" + paymentProvider + "]"))
+	}))
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"payment", "providers", "list"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if out := buf.String(); !strings.Contains(out, "PagoFacil") || !strings.Contains(out, "true") {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestPaymentOptionList_Provider(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/123/payment_providers/42" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		_, _ = w.Write([]byte(paymentProvider))
+	}))
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"payment", "options", "list", "--provider", "42", "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	var got []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v (output: %q)", err, buf.String())
+	}
+
+	if len(got) != 2 || got[0]["provider_id"] != "42" || got[1]["id"] != "cash" {
+		t.Errorf("options = %v", got)
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/gberlati/nube-cli/internal/policy"
+)
+
+// loadPolicy reads the policy file named by NUBE_POLICY; without one, the
+// empty policy allows everything. Nested runs read the same variable, so
+// batch steps and scheduled jobs can't escape it, and served runs the
+// caller's.
+func loadPolicy(flags *RootFlags) (*policy.Policy, error) {
+	path := strings.TrimSpace(flags.getenv("NUBE_POLICY"))
+	if path == "" {
+		return &policy.Policy{}, nil
+	}
+
+	path, err := expandPath(path)
+	if err != nil {
+		return nil, &ExitErr{Code: ExitConfig, Err: err}
+	}
+
+	p, err := policy.Load(path)
+	if err != nil {
+		return nil, &ExitErr{Code: ExitConfig, Err: err}
+	}
+
+	return p, nil
+}
+
+// enforcePolicy checks the parsed command against the policy before it runs.
+func enforcePolicy(kctx *kong.Context, p *policy.Policy, force bool) error {
+	err := p.CheckCommand(commandPath(kctx), force)
+	if err == nil {
+		return nil
+	}
+
+	var forceErr *policy.ForceRequiredError
+	if errors.As(err, &forceErr) {
+		return &ExitErr{Code: ExitUsage, Err: err}
+	}
+
+	return &ExitErr{Code: ExitPermissionDenied, Err: err}
+}
+
+// shortcutPaths maps the top-level shortcuts to the commands they run, so
+// rules written for a command also cover its shortcut.
+var shortcutPaths = map[string]string{
+	"shop":     "store get",
+	"products": "product list",
+	"orders":   "order list",
+	"status":   "auth status",
+}
+
+// commandPath returns the command words of kctx, without argument
+// placeholders: "product get <product-id>" → "product get". Shortcuts get
+// the path of the command they run: "orders" → "order list".
+func commandPath(kctx *kong.Context) string {
+	var words []string
+
+	for _, w := range strings.Fields(kctx.Command()) {
+		if strings.HasPrefix(w, "<") || strings.HasPrefix(w, "[") {
+			break
+		}
+
+		words = append(words, w)
+	}
+
+	path := strings.Join(words, " ")
+	if canonical, ok := shortcutPaths[path]; ok {
+		return canonical
+	}
+
+	return path
+}
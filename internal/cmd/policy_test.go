@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestExecute_Policy(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var requests []string
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		_, _ = w.Write([]byte(`[]`))
+	}))
+
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(`
+commands:
+  deny: [customer]
+  require_force: [seed]
+resources:
+  products: read
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("NUBE_POLICY", path)
+
+	_ = captureStdout(t)
+	_ = captureStderr(t)
+
+	tests := []struct {
+		args     []string
+		wantCode int
+	}{
+		{[]string{"product", "list", "--json"}, ExitOK},
+		{[]string{"customer", "list"}, ExitPermissionDenied},
+		{[]string{"seed", "--products", "1"}, ExitUsage},
+		// Allowed as a command, but products are read-only.
+		{[]string{"seed", "--products", "1", "--force"}, ExitPermissionDenied},
+	}
+
+	for _, tt := range tests {
+		if err := Execute(tt.args); ExitCode(err) != tt.wantCode {
+			t.Errorf("%v: ExitCode = %d, want %d (err %v)", tt.args, ExitCode(err), tt.wantCode, err)
+		}
+	}
+
+	for _, r := range requests {
+		if r != "GET /v1/123/products" {
+			t.Errorf("unexpected request %s", r)
+		}
+	}
+}
+
+func TestExecute_PolicyInvalid(t *testing.T) {
+	setupConfigDir(t)
+
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte("resources: {products: admin}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("NUBE_POLICY", path)
+
+	_ = captureStderr(t)
+
+	if err := Execute([]string{"version"}); ExitCode(err) != ExitConfig {
+		t.Errorf("ExitCode = %d, want %d (err %v)", ExitCode(err), ExitConfig, err)
+	}
+}
+
+func TestExecute_PolicyCoversShortcuts(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var requests int
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`[]`))
+	}))
+
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte("commands:\n  deny: [order, product list, store]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("NUBE_POLICY", path)
+
+	_ = captureStdout(t)
+	_ = captureStderr(t)
+
+	for _, args := range [][]string{{"orders"}, {"products"}, {"shop"}} {
+		if err := Execute(args); ExitCode(err) != ExitPermissionDenied {
+			t.Errorf("%v: ExitCode = %d, want %d (err %v)", args, ExitCode(err), ExitPermissionDenied, err)
+		}
+	}
+
+	if requests != 0 {
+		t.Errorf("%d requests sent for denied shortcuts", requests)
+	}
+}
@@ -2,10 +2,9 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 	"net/url"
-	"os"
+	"strconv"
 
 	"github.com/gberlati/nube-cli/internal/api"
 	"github.com/gberlati/nube-cli/internal/outfmt"
@@ -14,14 +13,24 @@ import (
 
 // ProductCmd groups product-related commands.
 type ProductCmd struct {
-	List     ProductListCmd     `cmd:"" help:"List products"`
-	Get      ProductGetCmd      `cmd:"" help:"Get a product by ID"`
-	GetBySku ProductGetBySkuCmd `cmd:"" name:"get-by-sku" help:"Get a product by SKU"`
+	List         ProductListCmd         `cmd:"" help:"List products"`
+	Get          ProductGetCmd          `cmd:"" help:"Get a product by ID"`
+	GetBySku     ProductGetBySkuCmd     `cmd:"" name:"get-by-sku" help:"Get a product by SKU"`
+	Exists       ProductExistsCmd       `cmd:"" help:"Exit 0 if a product exists (by ID or --sku), 1 if not"`
+	Diff         ProductDiffCmd         `cmd:"" help:"Compare a local JSON file against a product"`
+	Edit         ProductEditCmd         `cmd:"" help:"Edit a product in $EDITOR and save the changes"`
+	StockHistory ProductStockHistoryCmd `cmd:"" name:"stock-history" help:"Show when a product's stock changed, from saved snapshots"`
+	Price        ProductPriceCmd        `cmd:"" help:"Change prices in bulk"`
+	Lint         ProductLintCmd         `cmd:"" help:"Check the catalog for duplicate SKUs, missing barcodes or prices and unknown categories"`
+	Categorize   ProductCategorizeCmd   `cmd:"" help:"Add products to categories or remove them, one product or from a CSV"`
+	QR           ProductQRCmd           `cmd:"" name:"qr" help:"Render a product's storefront link as a QR code (PNG or terminal)"`
 }
 
 // ProductListCmd lists products with pagination and filters.
 type ProductListCmd struct {
 	PaginationFlags `embed:""`
+	DateFilterFlags `embed:""`
+	ColumnsFlags    `embed:""`
 
 	IDs          string `help:"Comma-separated product IDs" name:"ids"`
 	SinceID      string `help:"Return products after this ID" name:"since-id"`
@@ -30,10 +39,6 @@ type ProductListCmd struct {
 	CategoryID   string `help:"Filter by category ID" name:"category-id"`
 	Published    string `help:"Filter by published status (true/false)" name:"published"`
 	FreeShipping string `help:"Filter by free shipping (true/false)" name:"free-shipping"`
-	CreatedMin   string `help:"Created after (ISO 8601)" name:"created-at-min"`
-	CreatedMax   string `help:"Created before (ISO 8601)" name:"created-at-max"`
-	UpdatedMin   string `help:"Updated after (ISO 8601)" name:"updated-at-min"`
-	UpdatedMax   string `help:"Updated before (ISO 8601)" name:"updated-at-max"`
 	SortBy       string `help:"Sort field (e.g. created-at-ascending)" name:"sort-by"`
 	Fields       string `help:"Comma-separated fields to return from API" name:"fields"`
 }
@@ -55,13 +60,18 @@ func (c *ProductListCmd) Run(ctx context.Context, flags *RootFlags) error {
 	addQueryParam(q, "category_id", c.CategoryID)
 	addQueryParam(q, "published", c.Published)
 	addQueryParam(q, "free_shipping", c.FreeShipping)
-	addQueryParam(q, "created_at_min", c.CreatedMin)
-	addQueryParam(q, "created_at_max", c.CreatedMax)
-	addQueryParam(q, "updated_at_min", c.UpdatedMin)
-	addQueryParam(q, "updated_at_max", c.UpdatedMax)
 	addQueryParam(q, "sort_by", c.SortBy)
 	addQueryParam(q, "fields", c.Fields)
 
+	zone := newZoneResolver(flags, client)
+	if err := c.resolve(ctx, q, zone); err != nil {
+		return err
+	}
+
+	if q, err = c.resume("products", q); err != nil {
+		return err
+	}
+
 	var items []map[string]any
 
 	if c.WantsAllPages() {
@@ -78,30 +88,35 @@ func (c *ProductListCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
-	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, items)
-	}
+	links := newStorefrontLinks(ctx, client)
 
-	w, done := tableWriter(ctx)
-	defer done()
-
-	_, _ = fmt.Fprintln(w, "ID\tNAME\tHANDLE\tPUBLISHED\tVARIANTS\tPRICE")
+	if outfmt.IsJSON(ctx) {
+		for _, p := range items {
+			links.withStorefrontURL(p)
+		}
 
-	for _, p := range items {
-		name := extractI18n(p, "name")
-		variants := countVariants(p)
-		price := firstVariantPrice(p)
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), items)
+	}
 
-		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", //nolint:gosec // tabwriter, not HTML
-			jsonStr(p, "id"),
-			name,
-			extractI18n(p, "handle"),
-			jsonStr(p, "published"),
-			variants,
-			price,
-		)
+	money := lazyMoneyFormatter(ctx, flags, client)
+
+	cols, err := c.pick([]column{
+		textColumn("id", "id"),
+		i18nColumn("name", "name"),
+		i18nColumn("handle", "handle"),
+		textColumn("published", "published"),
+		{name: "variants", value: func(p map[string]any) string { return strconv.Itoa(countVariants(p)) }},
+		{name: "price", value: func(p map[string]any) string { return money().format(firstVariantPrice(p), "") }},
+		{name: "stock", value: totalStock},
+		{name: "sku", value: func(p map[string]any) string { return firstVariantField(p, "sku") }},
+		{name: "url", value: links.product},
+	}, "id", "name", "handle", "published", "variants", "price")
+	if err != nil {
+		return err
 	}
 
+	writeItemsTable(ctx, cols, items)
+
 	_ = u
 
 	return nil
@@ -126,7 +141,7 @@ func (c *ProductGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 
 	resp, err := client.Get(ctx, "products/"+c.ProductID, q) //nolint:bodyclose // DecodeResponse closes body
 	if err != nil {
-		return err
+		return withDidYouMean(ctx, client, productDidYouMean, c.ProductID, err)
 	}
 
 	data, err := api.DecodeResponse[map[string]any](resp)
@@ -134,8 +149,15 @@ func (c *ProductGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
+	links := newStorefrontLinks(ctx, client)
+
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, data)
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), links.withStorefrontURL(data))
+	}
+
+	times, err := newTimeFormatter(ctx, flags, newZoneResolver(flags, client))
+	if err != nil {
+		return err
 	}
 
 	return writeResult(ctx, u,
@@ -144,8 +166,9 @@ func (c *ProductGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 		kv("handle", extractI18n(data, "handle")),
 		kv("published", jsonStr(data, "published")),
 		kv("variants", countVariants(data)),
-		kv("created_at", jsonStr(data, "created_at")),
-		kv("updated_at", jsonStr(data, "updated_at")),
+		kv("storefront_url", links.product(data)),
+		kv("created_at", times.format(jsonStr(data, "created_at"))),
+		kv("updated_at", times.format(jsonStr(data, "updated_at"))),
 	)
 }
 
@@ -164,7 +187,7 @@ func (c *ProductGetBySkuCmd) Run(ctx context.Context, flags *RootFlags) error {
 
 	resp, err := client.Get(ctx, "products/sku/"+c.SKU, nil) //nolint:bodyclose // DecodeResponse closes body
 	if err != nil {
-		return err
+		return withDidYouMean(ctx, client, skuDidYouMean, c.SKU, err)
 	}
 
 	data, err := api.DecodeResponse[map[string]any](resp)
@@ -172,8 +195,10 @@ func (c *ProductGetBySkuCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
+	links := newStorefrontLinks(ctx, client)
+
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, data)
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), links.withStorefrontURL(data))
 	}
 
 	return writeResult(ctx, u,
@@ -181,6 +206,7 @@ func (c *ProductGetBySkuCmd) Run(ctx context.Context, flags *RootFlags) error {
 		kv("name", extractI18n(data, "name")),
 		kv("handle", extractI18n(data, "handle")),
 		kv("sku", c.SKU),
+		kv("storefront_url", links.product(data)),
 	)
 }
 
@@ -199,20 +225,36 @@ func countVariants(p map[string]any) int {
 }
 
 func firstVariantPrice(p map[string]any) string {
-	variants, ok := p["variants"]
-	if !ok {
-		return ""
-	}
+	return firstVariantField(p, "price")
+}
 
-	arr, ok := variants.([]any)
-	if !ok || len(arr) == 0 {
+func firstVariantField(p map[string]any, key string) string {
+	arr, _ := p["variants"].([]any)
+	if len(arr) == 0 {
 		return ""
 	}
 
-	first, ok := arr[0].(map[string]any)
-	if !ok {
-		return ""
+	first, _ := arr[0].(map[string]any)
+
+	return jsonStr(first, key)
+}
+
+// totalStock sums the stock of a product's variants. Variants without stock
+// tracking (null stock) make it unlimited.
+func totalStock(p map[string]any) string {
+	arr, _ := p["variants"].([]any)
+	total := 0
+
+	for _, v := range arr {
+		m, _ := v.(map[string]any)
+
+		stock, ok := m["stock"].(float64)
+		if !ok {
+			return "unlimited"
+		}
+
+		total += int(stock)
 	}
 
-	return jsonStr(first, "price")
+	return strconv.Itoa(total)
 }
@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// ProductCategorizeCmd adds products to categories and removes them, one
+// product from flags or many from a CSV file.
+type ProductCategorizeCmd struct {
+	CheckpointFlags `embed:""`
+	SnapshotFlags   `embed:""`
+
+	ProductID string   `arg:"" optional:"" name:"product-id" help:"Product ID (omit with --file)"`
+	Add       []string `help:"Category IDs to add" name:"add" sep:","`
+	Remove    []string `help:"Category IDs to remove" name:"remove" sep:","`
+	File      string   `help:"CSV with product_id,category_id[,action] rows (action add or remove, default add); - for stdin" name:"file" short:"f"`
+	Parallel  int      `help:"Products updated in parallel" name:"parallel" default:"4"`
+}
+
+// categorizeCommand names categorize checkpoints.
+const categorizeCommand = "product categorize"
+
+// categoryEdit is the categories to add to and remove from one product.
+type categoryEdit struct {
+	ProductID string   `json:"product_id"`
+	Add       []string `json:"add,omitempty"`
+	Remove    []string `json:"remove,omitempty"`
+	Before    []string `json:"before"`
+	After     []string `json:"after"`
+	Error     string   `json:"error,omitempty"`
+}
+
+func (c *ProductCategorizeCmd) Run(ctx context.Context, flags *RootFlags) error {
+	ctx = c.historyContext(ctx)
+	u := ui.FromContext(ctx)
+
+	edits, err := c.edits()
+	if err != nil {
+		return err
+	}
+
+	if c.Parallel < 1 {
+		return usagef("--parallel must be at least 1")
+	}
+
+	cp, err := c.resumed(categorizeCommand)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = cp.Close() }()
+
+	if cp != nil {
+		planned := len(edits)
+		edits = slices.DeleteFunc(edits, func(e categoryEdit) bool { return cp.Done(e.ProductID) })
+		u.Err().Printf("resuming: skipping %d products already updated", planned-len(edits))
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	pool := api.NewPool(api.WithWorkers(c.Parallel))
+
+	// Read every product first so the preview and confirmation show what
+	// will actually change.
+	reads := make([]api.Job, len(edits))
+	for i := range edits {
+		reads[i] = func(ctx context.Context) error {
+			resp, err := client.Get(ctx, "products/"+edits[i].ProductID, nil) //nolint:bodyclose // DecodeResponse closes body
+			if err != nil {
+				return fmt.Errorf("product %s: %w", edits[i].ProductID, err)
+			}
+
+			p, err := api.DecodeResponse[map[string]any](resp)
+			if err != nil {
+				return fmt.Errorf("product %s: %w", edits[i].ProductID, err)
+			}
+
+			edits[i].Before = productCategoryIDs(p)
+			edits[i].After = editCategories(edits[i].Before, edits[i].Add, edits[i].Remove)
+
+			return nil
+		}
+	}
+
+	if err := errors.Join(pool.Run(ctx, reads)...); err != nil {
+		return err
+	}
+
+	changed := slices.DeleteFunc(edits, func(e categoryEdit) bool { return slices.Equal(e.Before, e.After) })
+
+	if flags.DryRun || len(changed) == 0 {
+		if !flags.DryRun {
+			finishCheckpoint(ctx, u, cp, true)
+		}
+
+		return writeCategoryEdits(ctx, u, changed, flags.DryRun)
+	}
+
+	if len(changed) > 1 {
+		ids := make([]string, len(changed))
+		for i, e := range changed {
+			ids[i] = e.ProductID
+		}
+
+		if err := confirmBulk(flags, fmt.Sprintf("change the categories of %d products", len(changed)), ids, activeStoreName(flags, client)); err != nil {
+			return err
+		}
+	}
+
+	// One product needs no checkpoint, but a resumed run keeps its own.
+	if cp == nil && len(changed) > 1 {
+		if cp, err = startCheckpoint(u, categorizeCommand); err != nil {
+			return err
+		}
+	}
+
+	writes := make([]api.Job, len(changed))
+	for i := range changed {
+		writes[i] = func(ctx context.Context) error {
+			if err := setProductCategories(ctx, client, changed[i].ProductID, changed[i].After); err != nil {
+				return err
+			}
+
+			markDone(ctx, cp, changed[i].ProductID)
+
+			return nil
+		}
+	}
+
+	var failed []error
+
+	for i, err := range pool.Run(ctx, writes) {
+		if err != nil {
+			changed[i].Error = err.Error()
+			failed = append(failed, err)
+		}
+	}
+
+	finishCheckpoint(ctx, u, cp, len(failed) == 0)
+
+	if err := writeCategoryEdits(ctx, u, changed, false); err != nil {
+		return err
+	}
+
+	if len(failed) > 0 {
+		return &ExitErr{Code: stableExitCode(failed[0]), Err: fmt.Errorf("%d of %d products not updated", len(failed), len(changed))}
+	}
+
+	return nil
+}
+
+// edits builds the per-product edits from the flags or the CSV file.
+func (c *ProductCategorizeCmd) edits() ([]categoryEdit, error) {
+	if c.File != "" {
+		if c.ProductID != "" || len(c.Add) > 0 || len(c.Remove) > 0 {
+			return nil, usagef("--file replaces the product ID, --add and --remove")
+		}
+
+		b, err := readInputFile(c.File)
+		if err != nil {
+			return nil, err
+		}
+
+		return parseCategoryCSV(b)
+	}
+
+	if c.ProductID == "" {
+		return nil, usagef("pass a product ID or --file")
+	}
+
+	if len(c.Add) == 0 && len(c.Remove) == 0 {
+		return nil, usagef("nothing to do: pass --add and/or --remove")
+	}
+
+	for _, id := range slices.Concat(c.Add, c.Remove) {
+		if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+			return nil, usagef("category %q: want a numeric ID", id)
+		}
+	}
+
+	return []categoryEdit{{ProductID: c.ProductID, Add: c.Add, Remove: c.Remove}}, nil
+}
+
+// parseCategoryCSV reads product_id,category_id[,action] rows, skipping a
+// header row, and groups them by product in file order.
+func parseCategoryCSV(b []byte) ([]categoryEdit, error) {
+	r := csv.NewReader(bytes.NewReader(b))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	var edits []categoryEdit
+
+	index := map[string]int{}
+
+	for line := 1; ; line++ {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, usagef("csv: %v", err)
+		}
+
+		if line == 1 && strings.EqualFold(strings.TrimSpace(rec[0]), "product_id") {
+			continue
+		}
+
+		if len(rec) < 2 || len(rec) > 3 {
+			return nil, usagef("csv line %d: want product_id,category_id[,action]", line)
+		}
+
+		product, category := strings.TrimSpace(rec[0]), strings.TrimSpace(rec[1])
+
+		action := "add"
+		if len(rec) == 3 && strings.TrimSpace(rec[2]) != "" {
+			action = strings.ToLower(strings.TrimSpace(rec[2]))
+		}
+
+		if _, err := strconv.ParseInt(category, 10, 64); err != nil || product == "" {
+			return nil, usagef("csv line %d: want a product ID and a numeric category ID", line)
+		}
+
+		i, ok := index[product]
+		if !ok {
+			i = len(edits)
+			index[product] = i
+			edits = append(edits, categoryEdit{ProductID: product})
+		}
+
+		switch action {
+		case "add":
+			edits[i].Add = append(edits[i].Add, category)
+		case "remove":
+			edits[i].Remove = append(edits[i].Remove, category)
+		default:
+			return nil, usagef("csv line %d: action %q, want add or remove", line, action)
+		}
+	}
+
+	if len(edits) == 0 {
+		return nil, usagef("csv has no rows")
+	}
+
+	return edits, nil
+}
+
+// editCategories returns ids with add appended and remove taken out,
+// keeping the existing order.
+func editCategories(ids, add, remove []string) []string {
+	out := make([]string, 0, len(ids)+len(add))
+
+	for _, id := range slices.Concat(ids, add) {
+		if !slices.Contains(remove, id) && !slices.Contains(out, id) {
+			out = append(out, id)
+		}
+	}
+
+	return out
+}
+
+func writeCategoryEdits(ctx context.Context, u *ui.UI, edits []categoryEdit, dryRun bool) error {
+	if edits == nil {
+		edits = []categoryEdit{}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"dry_run": dryRun, "products": edits})
+	}
+
+	if len(edits) == 0 {
+		u.Err().Println("no category changes")
+
+		return nil
+	}
+
+	w, done := tableWriter(ctx)
+	defer done()
+
+	_, _ = fmt.Fprintln(w, "PRODUCT\tBEFORE\tAFTER\tERROR")
+
+	for _, e := range edits {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.ProductID, strings.Join(e.Before, ","), strings.Join(e.After, ","), e.Error)
+	}
+
+	return nil
+}
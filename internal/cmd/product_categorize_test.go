@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func categorizeServer(t *testing.T, puts map[string]string) http.Handler {
+	t.Helper()
+
+	var mu sync.Mutex
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/v1/123/products/")
+
+		if r.Method == http.MethodPut {
+			body, _ := io.ReadAll(r.Body)
+
+			mu.Lock()
+			puts[id] = string(body)
+			mu.Unlock()
+		}
+
+		switch id {
+		case "1":
+			_, _ = w.Write([]byte(`{"id":1,"categories":[{"id":10},{"id":12}]}`))
+		case "2":
+			_, _ = w.Write([]byte(`{"id":2,"categories":[]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func TestProductCategorize(t *testing.T) {
+	puts := map[string]string{}
+
+	setupConfigDir(t)
+	setupMockAPIClient(t, categorizeServer(t, puts))
+	_ = captureStdout(t)
+
+	if err := Execute([]string{"product", "categorize", "1", "--add", "11,10", "--remove", "12"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if puts["1"] != `{"categories":[10,11]}` {
+		t.Errorf("puts = %v", puts)
+	}
+}
+
+func TestProductCategorize_File(t *testing.T) {
+	puts := map[string]string{}
+
+	setupConfigDir(t)
+	setupMockAPIClient(t, categorizeServer(t, puts))
+	_ = captureStdout(t)
+
+	path := filepath.Join(t.TempDir(), "map.csv")
+	csv := "product_id,category_id,action\n1,10\n2,20\n2,21,add\n1,99,remove\n"
+
+	if err := os.WriteFile(path, []byte(csv), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Execute([]string{"product", "categorize", "--file", path, "--force"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	// Product 1 already has 10 and not 99, so only product 2 is written.
+	if len(puts) != 1 || puts["2"] != `{"categories":[20,21]}` {
+		t.Errorf("puts = %v", puts)
+	}
+}
+
+func TestParseCategoryCSV_Errors(t *testing.T) {
+	t.Parallel()
+
+	for _, in := range []string{
+		"",
+		"1,abc\n",
+		"1,10,move\n",
+		"1\n",
+	} {
+		if _, err := parseCategoryCSV([]byte(in)); ExitCode(err) != ExitUsage {
+			t.Errorf("%q: err = %v, want usage error", in, err)
+		}
+	}
+}
+
+func TestEditCategories(t *testing.T) {
+	t.Parallel()
+
+	got := editCategories([]string{"1", "2"}, []string{"3", "1"}, []string{"2"})
+	if !slices.Equal(got, []string{"1", "3"}) {
+		t.Errorf("editCategories = %v", got)
+	}
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+)
+
+// ProductExistsCmd checks for a product by ID or SKU for shell conditionals:
+// it exits 0 when the product exists and 1 when it doesn't, printing
+// nothing unless --json is set.
+type ProductExistsCmd struct {
+	ProductID string `arg:"" name:"product-id" optional:"" help:"Product ID"`
+	SKU       string `help:"Look the product up by variant SKU instead" name:"sku"`
+}
+
+func (c *ProductExistsCmd) Run(ctx context.Context, flags *RootFlags) error {
+	if (c.ProductID == "") == (c.SKU == "") {
+		return usagef("give a product ID or --sku")
+	}
+
+	path := "products/" + c.ProductID
+	if c.SKU != "" {
+		path = "products/sku/" + url.PathEscape(c.SKU)
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	found := true
+
+	var id string
+
+	resp, err := client.Get(ctx, path, url.Values{"fields": {"id"}}) //nolint:bodyclose // DecodeResponse closes body
+	if err == nil {
+		var product map[string]any
+
+		product, err = api.DecodeResponse[map[string]any](resp)
+		id = jsonStr(product, "id")
+	}
+
+	switch {
+	case api.IsNotFoundError(err):
+		found = false
+	case err != nil:
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		out := map[string]any{"exists": found}
+		if found {
+			out["id"] = id
+		}
+
+		if err := outfmt.WriteJSON(ctx, stdoutFrom(ctx), out); err != nil {
+			return err
+		}
+	}
+
+	if !found {
+		// A bare exit code: the answer is the status, not an error message.
+		return &ExitErr{Code: ExitError}
+	}
+
+	return nil
+}
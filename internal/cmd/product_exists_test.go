@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestProductExists(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantOut  string
+	}{
+		{"by id", []string{"product", "exists", "42"}, ExitOK, ""},
+		{"by sku", []string{"product", "exists", "--sku", "REM-001"}, ExitOK, ""},
+		{"missing", []string{"product", "exists", "--sku", "NOPE"}, ExitError, ""},
+		{"json", []string{"product", "exists", "42", "--json"}, ExitOK, `"exists": true`},
+		{"json missing", []string{"product", "exists", "7", "--json"}, ExitError, `"exists": false`},
+		{"no id or sku", []string{"product", "exists"}, ExitUsage, ""},
+		{"id and sku", []string{"product", "exists", "42", "--sku", "REM-001"}, ExitUsage, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+			setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/v1/123/products/42", "/v1/123/products/sku/REM-001":
+					_ = json.NewEncoder(w).Encode(map[string]any{"id": 42})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`{"code":404,"message":"Not Found"}`))
+				}
+			}))
+
+			buf := captureStdout(t)
+			errBuf := captureStderr(t)
+
+			err := Execute(tt.args)
+			if ExitCode(err) != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d (err %v)", ExitCode(err), tt.wantCode, err)
+			}
+
+			if tt.wantOut == "" && buf.String() != "" {
+				t.Errorf("stdout = %q, want nothing", buf.String())
+			} else if !strings.Contains(buf.String(), tt.wantOut) {
+				t.Errorf("stdout = %q, want %q", buf.String(), tt.wantOut)
+			}
+
+			if tt.wantCode != ExitUsage && errBuf.String() != "" {
+				t.Errorf("stderr = %q, want nothing", errBuf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// Catalog checks run by product lint.
+const (
+	lintDuplicateSKU   = "duplicate-sku"
+	lintMissingBarcode = "missing-barcode"
+	lintMissingPrice   = "missing-price"
+	lintOrphanCategory = "orphan-category"
+)
+
+var lintChecks = []string{lintDuplicateSKU, lintMissingBarcode, lintMissingPrice, lintOrphanCategory}
+
+// ProductLintCmd checks the catalog for data problems and exits 1 when it
+// finds any, so it can gate a CI job.
+type ProductLintCmd struct {
+	Checks []string `help:"Checks to run (default: all): duplicate-sku, missing-barcode, missing-price, orphan-category" name:"checks" sep:","`
+}
+
+// lintIssue is one problem found in the catalog.
+type lintIssue struct {
+	Check     string `json:"check"`
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id,omitempty"`
+	Detail    string `json:"detail"`
+}
+
+func (c *ProductLintCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	checks := c.Checks
+	if len(checks) == 0 {
+		checks = lintChecks
+	}
+
+	for _, check := range checks {
+		if !slices.Contains(lintChecks, check) {
+			return usagef("unknown check %q (want %s)", check, strings.Join(lintChecks, ", "))
+		}
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	products, err := api.CollectAllPages(ctx, client, "products", nil, decodeList)
+	if err != nil {
+		return err
+	}
+
+	var categories []map[string]any
+
+	if slices.Contains(checks, lintOrphanCategory) {
+		categories, err = api.CollectAllPages(ctx, client, "categories", nil, decodeList)
+		if err != nil {
+			return err
+		}
+	}
+
+	issues := lintCatalog(products, categories, checks)
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{
+			"products": len(products),
+			"checks":   checks,
+			"issues":   issues,
+		}); err != nil {
+			return err
+		}
+	} else if len(issues) > 0 {
+		w, done := tableWriter(ctx)
+
+		_, _ = fmt.Fprintln(w, "CHECK\tPRODUCT\tVARIANT\tDETAIL")
+
+		for _, is := range issues {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", is.Check, is.ProductID, is.VariantID, is.Detail)
+		}
+
+		done()
+	}
+
+	if len(issues) == 0 {
+		u.Err().Printf("%d products checked, no issues", len(products))
+
+		return nil
+	}
+
+	u.Err().Printf("%d products checked, %d issues", len(products), len(issues))
+
+	return &ExitErr{Code: ExitError}
+}
+
+// lintCatalog runs checks over products; categories is only needed for
+// orphan-category. Issues come in product order, duplicate SKUs last.
+func lintCatalog(products, categories []map[string]any, checks []string) []lintIssue {
+	issues := []lintIssue{}
+	run := func(check string) bool { return slices.Contains(checks, check) }
+
+	known := make(map[string]bool, len(categories))
+	for _, cat := range categories {
+		known[jsonStr(cat, "id")] = true
+	}
+
+	// skus maps each SKU to the variants using it.
+	skus := map[string][]lintIssue{}
+
+	for _, p := range products {
+		pid := jsonStr(p, "id")
+		variants, _ := p["variants"].([]any)
+
+		for _, v := range variants {
+			m, _ := v.(map[string]any)
+			vid := jsonStr(m, "id")
+
+			if sku := strings.TrimSpace(jsonStr(m, "sku")); sku != "" {
+				skus[sku] = append(skus[sku], lintIssue{ProductID: pid, VariantID: vid})
+			}
+
+			if run(lintMissingBarcode) && strings.TrimSpace(jsonStr(m, "barcode")) == "" {
+				issues = append(issues, lintIssue{Check: lintMissingBarcode, ProductID: pid, VariantID: vid, Detail: "no barcode"})
+			}
+
+			if run(lintMissingPrice) {
+				if cents, err := parseCents(jsonStr(m, "price")); err != nil || cents <= 0 {
+					issues = append(issues, lintIssue{Check: lintMissingPrice, ProductID: pid, VariantID: vid, Detail: "no price"})
+				}
+			}
+		}
+
+		if run(lintOrphanCategory) {
+			for _, id := range productCategoryIDs(p) {
+				if !known[id] {
+					issues = append(issues, lintIssue{Check: lintOrphanCategory, ProductID: pid, Detail: "category " + id + " does not exist"})
+				}
+			}
+		}
+	}
+
+	if run(lintDuplicateSKU) {
+		for _, sku := range slices.Sorted(maps.Keys(skus)) {
+			uses := skus[sku]
+			if len(uses) < 2 {
+				continue
+			}
+
+			for i, use := range uses {
+				others := make([]string, 0, len(uses)-1)
+
+				for j, o := range uses {
+					if j != i {
+						others = append(others, o.ProductID+"/"+o.VariantID)
+					}
+				}
+
+				issues = append(issues, lintIssue{
+					Check:     lintDuplicateSKU,
+					ProductID: use.ProductID,
+					VariantID: use.VariantID,
+					Detail:    fmt.Sprintf("SKU %s also used by %s", sku, strings.Join(others, ", ")),
+				})
+			}
+		}
+	}
+
+	return issues
+}
+
+// productCategoryIDs returns the IDs in a product's categories, which the
+// API sends as category objects.
+func productCategoryIDs(p map[string]any) []string {
+	raw, _ := p["categories"].([]any)
+	ids := make([]string, 0, len(raw))
+
+	for _, c := range raw {
+		switch v := c.(type) {
+		case map[string]any:
+			ids = append(ids, jsonStr(v, "id"))
+		case float64:
+			ids = append(ids, fmt.Sprint(int64(v)))
+		}
+	}
+
+	return ids
+}
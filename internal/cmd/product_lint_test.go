@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestProductLint(t *testing.T) {
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/123/products":
+			_, _ = w.Write([]byte(`[
+				{"id":1,"categories":[{"id":10}],"variants":[{"id":11,"sku":"A","barcode":"779","price":"10.00"}]},
+				{"id":2,"categories":[{"id":99}],"variants":[{"id":21,"sku":"A","barcode":"780","price":null}]}]`))
+		case "/v1/123/categories":
+			_, _ = w.Write([]byte(`[{"id":10}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	tests := []struct {
+		name   string
+		checks string
+		want   map[string]int
+	}{
+		{"all", "", map[string]int{lintDuplicateSKU: 2, lintMissingPrice: 1, lintOrphanCategory: 1}},
+		{"selected", "missing-barcode,missing-price", map[string]int{lintMissingPrice: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := []string{"--json", "product", "lint"}
+			if tt.checks != "" {
+				args = append(args, "--checks", tt.checks)
+			}
+
+			out := captureStdout(t)
+			_ = captureStderr(t)
+
+			if err := Execute(args); ExitCode(err) != ExitError {
+				t.Fatalf("ExitCode = %d, want %d (err %v)", ExitCode(err), ExitError, err)
+			}
+
+			var got struct {
+				Issues []lintIssue `json:"issues"`
+			}
+			if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+
+			counts := map[string]int{}
+			for _, is := range got.Issues {
+				counts[is.Check]++
+			}
+
+			if len(counts) != len(tt.want) {
+				t.Errorf("issues = %+v", got.Issues)
+			}
+
+			for check, n := range tt.want {
+				if counts[check] != n {
+					t.Errorf("%s: %d issues, want %d (%+v)", check, counts[check], n, got.Issues)
+				}
+			}
+		})
+	}
+}
+
+func TestProductLint_Clean(t *testing.T) {
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":1,"variants":[{"id":11,"sku":"A","barcode":"779","price":"10.00"}]}]`))
+	}))
+
+	_ = captureStdout(t)
+	_ = captureStderr(t)
+
+	if err := Execute([]string{"product", "lint", "--checks", "duplicate-sku,missing-price,missing-barcode"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if err := Execute([]string{"product", "lint", "--checks", "typo"}); ExitCode(err) != ExitUsage {
+		t.Errorf("unknown check: ExitCode = %d, want %d", ExitCode(err), ExitUsage)
+	}
+}
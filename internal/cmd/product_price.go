@@ -0,0 +1,407 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// ProductPriceCmd groups bulk price operations.
+type ProductPriceCmd struct {
+	Adjust ProductPriceAdjustCmd `cmd:"" help:"Raise or lower the prices of matching variants"`
+}
+
+// ProductPriceAdjustCmd changes the price of every variant of the products
+// matching --filter by a percentage or a fixed amount, with optional
+// rounding, after showing the changes.
+type ProductPriceAdjustCmd struct {
+	CheckpointFlags `embed:""`
+	SnapshotFlags   `embed:""`
+
+	Filter      []string     `help:"Product filter key=value (repeatable): category-id, ids, q, handle, published, free-shipping" name:"filter" sep:"none"`
+	All         bool         `help:"Adjust every product in the store (instead of --filter)" name:"all"`
+	Percent     signedNumber `help:"Change prices by this percentage (e.g. -15)" name:"percent"`
+	Amount      signedNumber `help:"Change prices by this amount (e.g. -500)" name:"amount"`
+	Round       string       `help:"Round new prices: .99 or .90 sets the cents, 1 or 10 rounds to that step" name:"round"`
+	Promotional bool         `help:"Write the new price as the promotional price, keeping the regular price" name:"promotional"`
+	Parallel    int          `help:"Variants updated in parallel" name:"parallel" default:"4"`
+}
+
+// signedNumber is a flag value that may start with a minus sign, which the
+// parser would otherwise take for a short flag ("--percent -15").
+type signedNumber string
+
+func (n *signedNumber) Decode(ctx *kong.DecodeContext) error {
+	t := ctx.Scan.Pop()
+
+	s := t.String()
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return fmt.Errorf("expected a number but got %q", s)
+	}
+
+	*n = signedNumber(s)
+
+	return nil
+}
+
+// priceFilterParams maps --filter keys to product list query parameters.
+var priceFilterParams = map[string]string{
+	"category-id":   "category_id",
+	"ids":           "ids",
+	"q":             "q",
+	"handle":        "handle",
+	"published":     "published",
+	"free-shipping": "free_shipping",
+}
+
+// priceAdjustCommand names price adjust checkpoints.
+const priceAdjustCommand = "product price adjust"
+
+// priceChange is one variant's planned price change.
+type priceChange struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id"`
+	SKU       string `json:"sku,omitempty"`
+	Name      string `json:"name"`
+	Field     string `json:"field"`
+	Old       string `json:"old"`
+	New       string `json:"new"`
+	Error     string `json:"error,omitempty"`
+}
+
+// key identifies the variant in a checkpoint.
+func (ch priceChange) key() string {
+	return ch.ProductID + "/" + ch.VariantID
+}
+
+func (c *ProductPriceAdjustCmd) Run(ctx context.Context, flags *RootFlags) error {
+	ctx = c.historyContext(ctx)
+	u := ui.FromContext(ctx)
+
+	adjust, err := c.adjuster()
+	if err != nil {
+		return err
+	}
+
+	q, err := c.query()
+	if err != nil {
+		return err
+	}
+
+	if c.Parallel < 1 {
+		return usagef("--parallel must be at least 1")
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	products, err := api.CollectAllPages(ctx, client, "products", q, decodeList)
+	if err != nil {
+		return err
+	}
+
+	field := "price"
+	if c.Promotional {
+		field = "promotional_price"
+	}
+
+	changes, err := planPriceChanges(products, field, adjust)
+	if err != nil {
+		return err
+	}
+
+	// Variants a resumed run already changed are planned again from their
+	// new price, so they are dropped by ID rather than by comparing prices.
+	cp, err := c.resumed(priceAdjustCommand)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = cp.Close() }()
+
+	if cp != nil {
+		planned := len(changes)
+		changes = slices.DeleteFunc(changes, func(ch priceChange) bool { return cp.Done(ch.key()) })
+		u.Err().Printf("resuming: skipping %d variants already changed", planned-len(changes))
+	}
+
+	if len(changes) == 0 {
+		if !flags.DryRun {
+			finishCheckpoint(ctx, u, cp, true)
+		}
+
+		u.Err().Println("no prices to change")
+
+		if outfmt.IsJSON(ctx) {
+			return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"changes": changes})
+		}
+
+		return nil
+	}
+
+	if flags.DryRun {
+		if outfmt.IsJSON(ctx) {
+			return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"dry_run": true, "changes": changes})
+		}
+
+		writePriceChanges(ctx, changes)
+
+		return nil
+	}
+
+	if !outfmt.IsJSON(ctx) {
+		writePriceChanges(ctx, changes)
+	}
+
+	action := fmt.Sprintf("change the %s of %d variants in %d products", field, len(changes), countProducts(changes))
+	if err := confirmBulk(flags, action, variantIDsOf(changes), activeStoreName(flags, client)); err != nil {
+		return err
+	}
+
+	if cp == nil {
+		if cp, err = startCheckpoint(u, priceAdjustCommand); err != nil {
+			return err
+		}
+	}
+
+	pool := api.NewPool(api.WithWorkers(c.Parallel))
+	jobs := make([]api.Job, len(changes))
+
+	for i, ch := range changes {
+		jobs[i] = func(ctx context.Context) error {
+			body, err := jsonBody(map[string]any{field: ch.New})
+			if err != nil {
+				return err
+			}
+
+			resp, err := client.Put(ctx, "products/"+ch.ProductID+"/variants/"+ch.VariantID, body) //nolint:bodyclose // decodeOptionalJSON closes body
+			if err != nil {
+				return err
+			}
+
+			if _, err := decodeOptionalJSON(resp); err != nil {
+				return err
+			}
+
+			markDone(ctx, cp, ch.key())
+
+			return nil
+		}
+	}
+
+	var failed []error
+
+	for i, err := range pool.Run(ctx, jobs) {
+		if err != nil {
+			changes[i].Error = err.Error()
+			failed = append(failed, err)
+		}
+	}
+
+	finishCheckpoint(ctx, u, cp, len(failed) == 0)
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{
+			"changes": changes,
+			"updated": len(changes) - len(failed),
+			"failed":  len(failed),
+			"stats":   pool.Stats(),
+		}); err != nil {
+			return err
+		}
+	} else {
+		if err := writeResult(ctx, u,
+			kv("updated", len(changes)-len(failed)),
+			kv("failed", len(failed)),
+		); err != nil {
+			return err
+		}
+
+		for _, ch := range changes {
+			if ch.Error != "" {
+				u.Err().Printf("variant %s of product %s: %s", ch.VariantID, ch.ProductID, ch.Error)
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return &ExitErr{Code: stableExitCode(failed[0]), Err: fmt.Errorf("%d of %d price updates failed", len(failed), len(changes))}
+	}
+
+	return nil
+}
+
+// adjuster validates --percent, --amount and --round and returns the
+// function computing a new price in cents from the current one.
+func (c *ProductPriceAdjustCmd) adjuster() (func(int64) int64, error) {
+	if (c.Percent == "") == (c.Amount == "") {
+		return nil, usagef("pass exactly one of --percent and --amount")
+	}
+
+	round := func(cents int64) int64 { return cents }
+
+	if c.Round != "" {
+		r, err := parseRoundRule(c.Round)
+		if err != nil {
+			return nil, err
+		}
+
+		round = r
+	}
+
+	if c.Percent != "" {
+		pct, err := strconv.ParseFloat(string(c.Percent), 64)
+		if err != nil || math.IsNaN(pct) || math.IsInf(pct, 0) || pct <= -100 {
+			return nil, usagef("--percent %q: want a number above -100, such as -15", c.Percent)
+		}
+
+		return func(cents int64) int64 {
+			return round(int64(math.Round(float64(cents) * (100 + pct) / 100)))
+		}, nil
+	}
+
+	delta, err := parseCents(string(c.Amount))
+	if err != nil {
+		return nil, usagef("--amount %q: want an amount such as -500 or 99.90", c.Amount)
+	}
+
+	return func(cents int64) int64 { return round(cents + delta) }, nil
+}
+
+// query turns --filter into product list parameters.
+func (c *ProductPriceAdjustCmd) query() (url.Values, error) {
+	if len(c.Filter) == 0 && !c.All {
+		return nil, usagef("pass --filter (e.g. --filter category-id=10) or --all")
+	}
+
+	if len(c.Filter) > 0 && c.All {
+		return nil, usagef("--filter and --all are mutually exclusive")
+	}
+
+	q := url.Values{}
+
+	for _, f := range c.Filter {
+		key, value, ok := strings.Cut(f, "=")
+
+		param, known := priceFilterParams[key]
+		if !ok || !known || value == "" {
+			return nil, usagef("--filter %q: want key=value with key one of category-id, ids, q, handle, published, free-shipping", f)
+		}
+
+		q.Set(param, value)
+	}
+
+	return q, nil
+}
+
+// parseRoundRule parses --round. Values below 1 set the cents (".99" turns
+// 850.00 into 849.99, the nearest price with that ending); values of 1 or
+// more round to the nearest multiple ("10" turns 853 into 850).
+func parseRoundRule(s string) (func(int64) int64, error) {
+	step, err := parseCents(s)
+	if err != nil || step <= 0 {
+		return nil, usagef("--round %q: want an ending such as .99 or a step such as 10", s)
+	}
+
+	if step < 100 {
+		return func(cents int64) int64 {
+			above := cents - cents%100 + step
+			below := above - 100
+
+			if below <= 0 || above-cents < cents-below {
+				return above
+			}
+
+			return below
+		}, nil
+	}
+
+	return func(cents int64) int64 {
+		return max((cents+step/2)/step*step, step)
+	}, nil
+}
+
+// planPriceChanges computes the new price of every variant of products.
+// Variants without a price are skipped, and so are those whose price stays
+// the same. A new price of zero or less stops the plan.
+func planPriceChanges(products []map[string]any, field string, adjust func(int64) int64) ([]priceChange, error) {
+	var changes []priceChange
+
+	for _, p := range products {
+		variants, _ := p["variants"].([]any)
+
+		for _, v := range variants {
+			m, _ := v.(map[string]any)
+
+			// Promotional prices are computed from the regular price, so
+			// re-running a sale doesn't compound the discount.
+			base, err := parseCents(jsonStr(m, "price"))
+			if err != nil {
+				continue
+			}
+
+			cents := adjust(base)
+			old := jsonStr(m, field)
+
+			if cents <= 0 {
+				return nil, usagef("variant %s of product %s would cost %s; check --percent/--amount", jsonStr(m, "id"), jsonStr(p, "id"), formatCents(cents))
+			}
+
+			if cur, err := parseCents(old); err == nil && cur == cents {
+				continue
+			}
+
+			changes = append(changes, priceChange{
+				ProductID: jsonStr(p, "id"),
+				VariantID: jsonStr(m, "id"),
+				SKU:       jsonStr(m, "sku"),
+				Name:      extractI18n(p, "name"),
+				Field:     field,
+				Old:       old,
+				New:       formatCents(cents),
+			})
+		}
+	}
+
+	return changes, nil
+}
+
+func writePriceChanges(ctx context.Context, changes []priceChange) {
+	w, done := tableWriter(ctx)
+	defer done()
+
+	_, _ = fmt.Fprintln(w, "PRODUCT\tVARIANT\tSKU\tNAME\tOLD\tNEW")
+
+	for _, ch := range changes {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", ch.ProductID, ch.VariantID, ch.SKU, ch.Name, ch.Old, ch.New)
+	}
+}
+
+func variantIDsOf(changes []priceChange) []string {
+	ids := make([]string, len(changes))
+	for i, ch := range changes {
+		ids[i] = ch.VariantID
+	}
+
+	return ids
+}
+
+func countProducts(changes []priceChange) int {
+	seen := map[string]bool{}
+	for _, ch := range changes {
+		seen[ch.ProductID] = true
+	}
+
+	return len(seen)
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestProductPriceAdjust(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		query     string
+		puts      = map[string]map[string]any{}
+		snapshots int
+	)
+
+	setupConfigDir(t)
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut:
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+
+			mu.Lock()
+			puts[strings.TrimPrefix(r.URL.Path, "/v1/123/")] = body
+			mu.Unlock()
+
+			_, _ = w.Write([]byte(`{}`))
+		case r.URL.Path == "/v1/123/products":
+			query = r.URL.RawQuery
+			_, _ = w.Write([]byte(`[
+				{"id":1,"name":{"es":"Remera"},"variants":[{"id":11,"sku":"R-S","price":"1000.00"},{"id":12,"price":null}]},
+				{"id":2,"name":{"es":"Buzo"},"variants":[{"id":21,"price":"2350.00"}]}]`))
+		default:
+			mu.Lock()
+			snapshots++
+			mu.Unlock()
+
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+
+	out := captureStdout(t)
+	if err := Execute([]string{
+		"--json", "--force", "product", "price", "adjust",
+		"--filter", "category-id=10", "--percent", "-15", "--round", ".99",
+	}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if !strings.Contains(query, "category_id=10") {
+		t.Errorf("query = %q", query)
+	}
+
+	want := map[string]string{
+		"products/1/variants/11": "849.99",
+		"products/2/variants/21": "1997.99",
+	}
+	if len(puts) != len(want) {
+		t.Fatalf("puts = %v", puts)
+	}
+
+	// Bulk updates aren't snapshotted without --snapshot.
+	if snapshots != 0 {
+		t.Errorf("variant GETs = %d, want none", snapshots)
+	}
+
+	for path, price := range want {
+		if puts[path]["price"] != price {
+			t.Errorf("PUT %s = %v, want price %s", path, puts[path], price)
+		}
+	}
+
+	var got struct {
+		Updated int `json:"updated"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil || got.Updated != 2 {
+		t.Errorf("output = %s (%v)", out.String(), err)
+	}
+}
+
+func TestProductPriceAdjust_Resume(t *testing.T) {
+	setupConfigDir(t)
+
+	var (
+		mu       sync.Mutex
+		puts     []string
+		rejectID = "12"
+	)
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut:
+			mu.Lock()
+			defer mu.Unlock()
+
+			puts = append(puts, r.URL.Path)
+
+			if strings.HasSuffix(r.URL.Path, "/variants/"+rejectID) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = w.Write([]byte(`{"code":422,"message":"Unprocessable Entity","description":{"price":["is invalid"]}}`))
+
+				return
+			}
+
+			_, _ = w.Write([]byte(`{}`))
+		case r.URL.Path == "/v1/123/products":
+			_, _ = w.Write([]byte(`[{"id":1,"name":{"es":"Remera"},"variants":[{"id":11,"price":"1000.00"},{"id":12,"price":"2000.00"}]}]`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+
+	args := []string{"--force", "product", "price", "adjust", "--all", "--percent", "10", "--parallel", "1"}
+
+	_ = captureStdout(t)
+	errBuf := captureStderr(t)
+
+	if err := Execute(args); err == nil {
+		t.Fatal("expected the rejected variant to fail the run")
+	}
+
+	m := regexp.MustCompile(`--resume (\S+) to continue`).FindStringSubmatch(errBuf.String())
+	if m == nil {
+		t.Fatalf("stderr = %q, want a --resume hint", errBuf.String())
+	}
+
+	// The second run must not raise variant 11 again.
+	mu.Lock()
+	puts, rejectID = nil, ""
+	mu.Unlock()
+
+	if err := Execute(append(args, "--resume", m[1])); err != nil {
+		t.Fatalf("resume error = %v", err)
+	}
+
+	if want := []string{"/v1/123/products/1/variants/12"}; !slices.Equal(puts, want) {
+		t.Errorf("resumed PUTs = %v, want %v", puts, want)
+	}
+
+	if _, err := os.Stat(m[1]); !os.IsNotExist(err) {
+		t.Errorf("checkpoint left after a complete run: %v", err)
+	}
+
+	if err := Execute([]string{"product", "categorize", "1", "--add", "10", "--resume", m[1]}); ExitCode(err) != ExitUsage {
+		t.Errorf("missing checkpoint: exit code = %d, want %d", ExitCode(err), ExitUsage)
+	}
+}
+
+func TestProductPriceAdjust_Usage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no filter", []string{"--percent", "10"}},
+		{"unknown filter", []string{"--filter", "color=red", "--percent", "10"}},
+		{"no change", []string{"--all"}},
+		{"both changes", []string{"--all", "--percent", "10", "--amount", "5"}},
+		{"free", []string{"--all", "--percent", "-100"}},
+		{"bad round", []string{"--all", "--percent", "10", "--round", "x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupConfigDir(t)
+
+			err := Execute(append([]string{"product", "price", "adjust"}, tt.args...))
+			if ExitCode(err) != ExitUsage {
+				t.Errorf("exit = %d, err = %v", ExitCode(err), err)
+			}
+		})
+	}
+}
+
+func TestParseRoundRule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		rule  string
+		cents int64
+		want  int64
+	}{
+		{".99", 85000, 84999},
+		{".99", 85060, 85099},
+		{".90", 10, 90},
+		{"10", 85300, 85000},
+		{"10", 85500, 86000},
+		{"1", 49, 100},
+	}
+
+	for _, tt := range tests {
+		round, err := parseRoundRule(tt.rule)
+		if err != nil {
+			t.Fatalf("parseRoundRule(%q): %v", tt.rule, err)
+		}
+
+		if got := round(tt.cents); got != tt.want {
+			t.Errorf("round %q (%d) = %d, want %d", tt.rule, tt.cents, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/qr"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// ProductQRCmd renders a product's storefront link as a QR code, for shop
+// signage and social posts.
+type ProductQRCmd struct {
+	ProductID string `arg:"" name:"product-id" help:"Product ID"`
+	Out       string `help:"Save the code as a PNG to this file, or - for stdout" name:"out" short:"o"`
+	ANSI      bool   `help:"Print the code in the terminal (the default without --out)" name:"ansi"`
+	Scale     int    `help:"PNG pixels per module" name:"scale" default:"8"`
+}
+
+func (c *ProductQRCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	if c.Scale < 1 {
+		return usagef("--scale must be at least 1")
+	}
+
+	if c.Out == "-" && c.ANSI {
+		return usagef("--out - and --ansi both write to stdout; pick one")
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Get(ctx, "products/"+c.ProductID, nil) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return err
+	}
+
+	data, err := api.DecodeResponse[map[string]any](resp)
+	if err != nil {
+		return err
+	}
+
+	link := newStorefrontLinks(ctx, client).product(data)
+	if link == "" {
+		return fmt.Errorf("no storefront URL for product %s (it has no handle, or the store's domain is unknown)", c.ProductID)
+	}
+
+	code, err := qr.Encode(link)
+	if err != nil {
+		return err
+	}
+
+	if c.Out != "" {
+		if err := writeQRPNG(ctx, code, c.Out, c.Scale); err != nil {
+			return err
+		}
+
+		if c.Out == "-" {
+			return nil
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"product_id": c.ProductID, "url": link, "path": c.Out})
+	}
+
+	if c.ANSI || c.Out == "" {
+		_, _ = io.WriteString(stdoutFrom(ctx), code.ANSI())
+		u.Err().Println(link)
+
+		return nil
+	}
+
+	u.Out().Printf("%s\t%s", c.Out, link)
+
+	return nil
+}
+
+// writeQRPNG saves code as a PNG to path ("-" for stdout).
+func writeQRPNG(ctx context.Context, code *qr.Code, path string, scale int) error {
+	if path == "-" {
+		return code.PNG(stdoutFrom(ctx), scale)
+	}
+
+	path, err := expandPath(path)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path) //nolint:gosec // user-provided path
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+
+	err = code.PNG(f, scale)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+
+	return nil
+}
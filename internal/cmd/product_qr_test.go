@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func qrProductHandler(t *testing.T) http.Handler {
+	t.Helper()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/123/products/42":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id": 42, "handle": map[string]any{"es": "remera"},
+				"canonical_url": "https://tienda.example/productos/remera/",
+			})
+		case "/v1/123/products/7":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": 7})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	})
+}
+
+func TestProductQR_PNG(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+	setupMockAPIClient(t, qrProductHandler(t))
+
+	path := filepath.Join(t.TempDir(), "qr.png")
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"product", "qr", "42", "--out", path, "--scale", "2", "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if got["url"] != "https://tienda.example/productos/remera/" || got["path"] != path {
+		t.Errorf("output = %v", got)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("decode png: %v", err)
+	}
+
+	// 41 characters fit version 3: 29 modules plus the quiet zone.
+	if img.Bounds().Dx() != (29+8)*2 {
+		t.Errorf("png width = %d, want %d", img.Bounds().Dx(), (29+8)*2)
+	}
+}
+
+func TestProductQR_ANSI(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+	setupMockAPIClient(t, qrProductHandler(t))
+
+	buf := captureStdout(t)
+	errBuf := captureStderr(t)
+
+	if err := Execute([]string{"product", "qr", "42"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if out := buf.String(); !strings.Contains(out, "\x1b[30;47m") || !strings.Contains(out, "█") {
+		t.Errorf("stdout = %q, want an ANSI code", out)
+	}
+
+	if !strings.Contains(errBuf.String(), "https://tienda.example/productos/remera/") {
+		t.Errorf("stderr = %q, want the link", errBuf.String())
+	}
+}
+
+func TestProductQR_NoURL(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+	setupMockAPIClient(t, qrProductHandler(t))
+
+	err := Execute([]string{"product", "qr", "7", "--out", filepath.Join(t.TempDir(), "qr.png")})
+	if err == nil || !strings.Contains(err.Error(), "no storefront URL") {
+		t.Errorf("error = %v, want no storefront URL", err)
+	}
+}
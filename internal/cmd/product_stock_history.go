@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// ProductStockHistoryCmd rebuilds a product's stock movements from snapshot
+// files, since the API keeps no inventory history.
+type ProductStockHistoryCmd struct {
+	ProductID string   `arg:"" name:"product-id" help:"Product ID"`
+	Snapshots []string `help:"Snapshot files or directories of them, from 'nube snapshot create --resources products'" name:"snapshots" required:"" sep:","`
+	NoCurrent bool     `help:"Don't add the product's current stock from the API" name:"no-current"`
+}
+
+// stockPoint is a variant's stock at one point in time. Stock is a number,
+// "unlimited" when the variant doesn't track stock, or "deleted".
+type stockPoint struct {
+	At        string `json:"at"`
+	VariantID string `json:"variant_id"`
+	SKU       string `json:"sku,omitempty"`
+	Stock     string `json:"stock"`
+	Previous  string `json:"previous,omitempty"`
+}
+
+func (c *ProductStockHistoryCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	snaps, err := loadProductSnapshots(c.Snapshots)
+	if err != nil {
+		return err
+	}
+
+	var points []stockPoint
+
+	for _, s := range snaps {
+		points = append(points, variantStock(s.CreatedAt, findByID(s.Resources["products"], c.ProductID))...)
+	}
+
+	if !c.NoCurrent {
+		client, err := newAPIClient(flags)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Get(ctx, "products/"+c.ProductID, nil) //nolint:bodyclose // DecodeResponse closes body
+		if err != nil && !api.IsNotFoundError(err) {
+			return err
+		}
+
+		var product map[string]any
+		if err == nil {
+			if product, err = api.DecodeResponse[map[string]any](resp); err != nil {
+				return err
+			}
+		}
+
+		points = append(points, variantStock(time.Now().UTC().Format(time.RFC3339), product)...)
+	}
+
+	if len(points) == 0 {
+		return &ExitErr{Code: ExitNotFound, Err: fmt.Errorf("product %s is in none of the %d snapshots", c.ProductID, len(snaps))}
+	}
+
+	events := stockEvents(points)
+	outSince := outOfStockSince(events)
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{
+			"product_id":         c.ProductID,
+			"snapshots":          len(snaps),
+			"events":             events,
+			"out_of_stock_since": outSince,
+		})
+	}
+
+	w, done := tableWriter(ctx)
+
+	_, _ = fmt.Fprintln(w, "AT\tVARIANT\tSKU\tSTOCK\tPREVIOUS")
+
+	for _, e := range events {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.At, e.VariantID, e.SKU, e.Stock, e.Previous)
+	}
+
+	done()
+
+	if !outfmt.IsPlain(ctx) {
+		for _, id := range slices.Sorted(maps.Keys(outSince)) {
+			u.Err().Printf("variant %s out of stock since %s", id, outSince[id])
+		}
+	}
+
+	return nil
+}
+
+// loadProductSnapshots reads the snapshot files in paths, expanding
+// directories to their *.json files, and returns those holding products
+// ordered by capture time.
+func loadProductSnapshots(paths []string) ([]storeSnapshot, error) {
+	var files []string
+
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, usagef("--snapshots: %v", err)
+		}
+
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+
+		matches, _ := filepath.Glob(filepath.Join(p, "*.json"))
+		files = append(files, matches...)
+	}
+
+	var snaps []storeSnapshot
+
+	for _, f := range files {
+		b, err := os.ReadFile(f) //nolint:gosec // user-provided path
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", f, err)
+		}
+
+		var s storeSnapshot
+		if json.Unmarshal(b, &s) != nil || s.CreatedAt == "" {
+			continue
+		}
+
+		if _, ok := s.Resources["products"]; ok {
+			snaps = append(snaps, s)
+		}
+	}
+
+	if len(snaps) == 0 {
+		return nil, usagef("no product snapshots in %v; capture them periodically with 'nube snapshot create --resources products -o <dir>/$(date +%%F).json'", paths)
+	}
+
+	slices.SortStableFunc(snaps, func(a, b storeSnapshot) int { return compareTimes(a.CreatedAt, b.CreatedAt) })
+
+	return snaps, nil
+}
+
+func findByID(items []map[string]any, id string) map[string]any {
+	for _, it := range items {
+		if jsonStr(it, "id") == id {
+			return it
+		}
+	}
+
+	return nil
+}
+
+// variantStock returns the stock of each variant of product at time at.
+// A missing product yields a single "deleted" point.
+func variantStock(at string, product map[string]any) []stockPoint {
+	if product == nil {
+		return []stockPoint{{At: at, Stock: "deleted"}}
+	}
+
+	variants, _ := product["variants"].([]any)
+	points := make([]stockPoint, 0, len(variants))
+
+	for _, v := range variants {
+		m, _ := v.(map[string]any)
+
+		stock := "unlimited"
+		if n, ok := m["stock"].(float64); ok {
+			stock = strconv.Itoa(int(n))
+		}
+
+		points = append(points, stockPoint{At: at, VariantID: jsonStr(m, "id"), SKU: jsonStr(m, "sku"), Stock: stock})
+	}
+
+	return points
+}
+
+// stockEvents keeps the first point of each variant and the points where its
+// stock changed. A "deleted" point (no variant) applies to every variant.
+func stockEvents(points []stockPoint) []stockPoint {
+	last := map[string]string{}
+
+	var events []stockPoint
+
+	for _, p := range points {
+		ids := []string{p.VariantID}
+		if p.VariantID == "" {
+			ids = slices.Sorted(maps.Keys(last))
+		}
+
+		for _, id := range ids {
+			prev, seen := last[id]
+			if seen && prev == p.Stock {
+				continue
+			}
+
+			e := p
+			e.VariantID = id
+			e.Previous = prev
+			events = append(events, e)
+			last[id] = p.Stock
+		}
+	}
+
+	return events
+}
+
+// outOfStockSince returns, for variants whose latest stock is 0, when it
+// reached 0.
+func outOfStockSince(events []stockPoint) map[string]string {
+	since := map[string]string{}
+
+	for _, e := range events {
+		if e.Stock == "0" {
+			since[e.VariantID] = e.At
+		} else {
+			delete(since, e.VariantID)
+		}
+	}
+
+	return since
+}
+
+// compareTimes orders RFC 3339 timestamps, falling back to string order.
+func compareTimes(a, b string) int {
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+
+	return ta.Compare(tb)
+}
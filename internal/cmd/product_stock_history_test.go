@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func writeStockSnapshot(t *testing.T, dir, createdAt string, stock any) {
+	t.Helper()
+
+	snap := storeSnapshot{
+		Store:     "123",
+		CreatedAt: createdAt,
+		Resources: map[string][]map[string]any{"products": {{
+			"id":       1,
+			"variants": []any{map[string]any{"id": 11, "sku": "REM-S", "stock": stock}},
+		}}},
+	}
+
+	b, err := json.Marshal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, createdAt[:10]+".json"), b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProductStockHistory(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":1,"variants":[{"id":11,"sku":"REM-S","stock":0}]}`))
+	}))
+
+	dir := t.TempDir()
+	// Written out of order on purpose; snapshots are sorted by created_at.
+	writeStockSnapshot(t, dir, "2026-03-03T00:00:00Z", 0)
+	writeStockSnapshot(t, dir, "2026-03-01T00:00:00Z", 5)
+	writeStockSnapshot(t, dir, "2026-03-02T00:00:00Z", 5)
+
+	out := captureStdout(t)
+	if err := Execute([]string{"--json", "product", "stock-history", "1", "--snapshots", dir}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	var got struct {
+		Snapshots       int               `json:"snapshots"`
+		Events          []stockPoint      `json:"events"`
+		OutOfStockSince map[string]string `json:"out_of_stock_since"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if got.Snapshots != 3 || len(got.Events) != 2 {
+		t.Fatalf("history = %+v", got)
+	}
+
+	if e := got.Events[1]; e.At != "2026-03-03T00:00:00Z" || e.Stock != "0" || e.Previous != "5" || e.SKU != "REM-S" {
+		t.Errorf("second event = %+v", e)
+	}
+
+	if got.OutOfStockSince["11"] != "2026-03-03T00:00:00Z" {
+		t.Errorf("out_of_stock_since = %v", got.OutOfStockSince)
+	}
+}
+
+func TestProductStockHistory_NoSnapshots(t *testing.T) {
+	setupConfigDir(t)
+
+	err := Execute([]string{"product", "stock-history", "1", "--no-current", "--snapshots", t.TempDir()})
+	if ExitCode(err) != ExitUsage {
+		t.Fatalf("exit = %d, err = %v", ExitCode(err), err)
+	}
+}
+
+func TestStockEvents(t *testing.T) {
+	t.Parallel()
+
+	events := stockEvents([]stockPoint{
+		{At: "1", VariantID: "a", Stock: "unlimited"},
+		{At: "2", VariantID: "a", Stock: "3"},
+		{At: "3", Stock: "deleted"},
+		{At: "4", Stock: "deleted"},
+	})
+
+	want := []string{"unlimited", "3", "deleted"}
+	if len(events) != len(want) {
+		t.Fatalf("events = %+v", events)
+	}
+
+	for i, e := range events {
+		if e.Stock != want[i] || e.VariantID != "a" {
+			t.Errorf("events[%d] = %+v", i, e)
+		}
+	}
+}
@@ -95,11 +95,12 @@ func TestProductGet_JSON(t *testing.T) {
 
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(map[string]any{
-			"id":        42,
-			"name":      map[string]any{"es": "Zapato"},
-			"handle":    map[string]any{"es": "zapato"},
-			"published": true,
-			"variants":  []any{map[string]any{"sku": "ZP-001", "price": "50.00"}},
+			"id":            42,
+			"name":          map[string]any{"es": "Zapato"},
+			"handle":        map[string]any{"es": "zapato"},
+			"canonical_url": "https://tienda.example/productos/zapato/",
+			"published":     true,
+			"variants":      []any{map[string]any{"sku": "ZP-001", "price": "50.00"}},
 		})
 	}))
 
@@ -117,6 +118,10 @@ func TestProductGet_JSON(t *testing.T) {
 	if jsonStr(got, "id") != "42" {
 		t.Errorf("id = %v", got["id"])
 	}
+
+	if jsonStr(got, "storefront_url") != "https://tienda.example/productos/zapato/" {
+		t.Errorf("storefront_url = %v", got["storefront_url"])
+	}
 }
 
 func TestProductGetBySku_JSON(t *testing.T) {
@@ -132,9 +137,10 @@ func TestProductGetBySku_JSON(t *testing.T) {
 
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(map[string]any{
-			"id":     99,
-			"name":   map[string]any{"es": "Remera"},
-			"handle": map[string]any{"es": "remera"},
+			"id":            99,
+			"name":          map[string]any{"es": "Remera"},
+			"handle":        map[string]any{"es": "remera"},
+			"canonical_url": "https://tienda.example/productos/remera/",
 		})
 	}))
 
@@ -153,3 +159,31 @@ func TestProductGetBySku_JSON(t *testing.T) {
 		t.Errorf("id = %v", got["id"])
 	}
 }
+
+func TestProductList_Flatten(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"id": 1, "name": map[string]any{"es": "Remera"}, "variants": []any{map[string]any{"sku": "A"}}},
+		})
+	}))
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"product", "list", "--page", "1", "--flatten", "tsv", "--select", "id,variants.*.sku"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if got, want := buf.String(), "0.id\t1\n0.variants.*.sku.0\tA\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	errBuf := captureStderr(t)
+	if err := Execute([]string{"product", "list", "--flatten", "csv", "--envelope"}); ExitCode(err) != ExitUsage {
+		t.Errorf("--flatten --envelope: ExitCode = %d, want %d", ExitCode(err), ExitUsage)
+	}
+
+	if !strings.Contains(errBuf.String(), "can't be combined") {
+		t.Errorf("stderr = %q, want the conflict explained", errBuf.String())
+	}
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// profileDefaultsDenied are flags a store profile can't default: they pick
+// the profile, or would silently skip safety checks.
+var profileDefaultsDenied = []string{"store", "force", "expect-store", "enable-commands", "daemon"}
+
+// profileDefaults is a kong resolver that fills flags given neither on the
+// command line nor through their environment variable with the defaults
+// of the store profile the command runs against.
+func profileDefaults(flags *RootFlags) kong.Resolver {
+	var (
+		loaded   bool
+		defaults map[string]string
+	)
+
+	return kong.ResolverFunc(func(kctx *kong.Context, _ *kong.Path, flag *kong.Flag) (any, error) {
+		if !loaded {
+			loaded = true
+			defaults = activeProfileDefaults(kctx, flags)
+		}
+
+		v, ok := defaults[flag.Name]
+		if !ok || slices.Contains(profileDefaultsDenied, flag.Name) {
+			return nil, nil
+		}
+
+		for _, env := range flag.Envs {
+			if _, set := os.LookupEnv(env); set {
+				return nil, nil
+			}
+		}
+
+		return v, nil
+	})
+}
+
+// activeProfileDefaults returns the defaults of the profile --store (or
+// its fallbacks) resolves to; none when there is no such profile.
+func activeProfileDefaults(kctx *kong.Context, flags *RootFlags) map[string]string {
+	if flags.getenv("NUBE_ACCESS_TOKEN") != "" {
+		return nil
+	}
+
+	store := ""
+
+	for _, f := range kctx.Flags() {
+		if f.Name == "store" {
+			store, _ = kctx.FlagValue(f).(string)
+		}
+	}
+
+	_, profile, err := flags.resolveStore(store)
+	if err != nil {
+		return nil
+	}
+
+	return profile.Defaults
+}
+
+// AuthDefaultsCmd shows or changes the flag defaults of a store profile.
+type AuthDefaultsCmd struct {
+	Name   string   `arg:"" name:"name" help:"Store profile name"`
+	Values []string `arg:"" optional:"" name:"flag=value" help:"Flag default to set, e.g. per-page=100 or json=true (flag= removes it)"`
+}
+
+func (c *AuthDefaultsCmd) Run(ctx context.Context, parser *kong.Kong) error {
+	profile, err := credstore.GetStore(c.Name)
+	if err != nil {
+		return &ExitErr{Code: ExitConfig, Err: err}
+	}
+
+	if len(c.Values) > 0 {
+		known := flagNames(parser.Model.Node)
+
+		for _, pair := range c.Values {
+			name, value, ok := strings.Cut(strings.TrimLeft(pair, "-"), "=")
+			if !ok || name == "" {
+				return usagef("%q: want flag=value", pair)
+			}
+
+			if !known[name] {
+				return usagef("unknown flag --%s", name)
+			}
+
+			if slices.Contains(profileDefaultsDenied, name) {
+				return usagef("--%s can't have a profile default", name)
+			}
+
+			if value == "" {
+				delete(profile.Defaults, name)
+
+				continue
+			}
+
+			if profile.Defaults == nil {
+				profile.Defaults = map[string]string{}
+			}
+
+			profile.Defaults[name] = value
+		}
+
+		if err := credstore.SetStore(c.Name, profile); err != nil {
+			return err
+		}
+	}
+
+	defaults := profile.Defaults
+	if defaults == nil {
+		defaults = map[string]string{}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"name": c.Name, "defaults": defaults})
+	}
+
+	if len(defaults) == 0 {
+		ui.FromContext(ctx).Err().Printf("Store profile %s has no flag defaults", c.Name)
+
+		return nil
+	}
+
+	w, done := tableWriter(ctx)
+	defer done()
+
+	_, _ = fmt.Fprintln(w, "FLAG\tVALUE")
+
+	for _, name := range slices.Sorted(maps.Keys(defaults)) {
+		_, _ = fmt.Fprintf(w, "--%s\t%s\n", name, defaults[name])
+	}
+
+	return nil
+}
+
+// flagNames collects the names of the flags of node and its subcommands.
+func flagNames(node *kong.Node) map[string]bool {
+	names := map[string]bool{}
+
+	var walk func(*kong.Node)
+
+	walk = func(n *kong.Node) {
+		for _, f := range n.Flags {
+			names[f.Name] = true
+		}
+
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+
+	walk(node)
+
+	return names
+}
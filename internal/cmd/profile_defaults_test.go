@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestProfileDefaults(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{
+		"test": {StoreID: "123", AccessToken: "tok", Defaults: map[string]string{"per-page": "7", "json": "true"}},
+	}, "test")
+	t.Setenv("NUBE_ACCESS_TOKEN", "")
+
+	var perPage []string
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		perPage = append(perPage, r.URL.Query().Get("per_page"))
+		_, _ = w.Write([]byte(`[{"id":1,"name":{"es":"Remera"}}]`))
+	}))
+
+	out := captureStdout(t)
+	if err := Execute([]string{"product", "list", "--page", "1"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if len(perPage) != 1 || perPage[0] != "7" || !strings.HasPrefix(strings.TrimSpace(out.String()), "[") {
+		t.Errorf("per_page = %v, output = %q; want the profile's defaults", perPage, out.String())
+	}
+
+	// The command line wins.
+	perPage = nil
+	_ = captureStdout(t)
+
+	if err := Execute([]string{"product", "list", "--page", "1", "--per-page", "3"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if len(perPage) != 1 || perPage[0] != "3" {
+		t.Errorf("per_page = %v, want 3", perPage)
+	}
+}
+
+func TestAuthDefaults(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	_ = captureStdout(t)
+	if err := Execute([]string{"auth", "defaults", "test", "per-page=100", "lang-priority=pt,es"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	p, _ := credstore.GetStore("test")
+	if p.Defaults["per-page"] != "100" || p.Defaults["lang-priority"] != "pt,es" {
+		t.Errorf("defaults = %v", p.Defaults)
+	}
+
+	if err := Execute([]string{"auth", "defaults", "test", "per-page="}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if p, _ = credstore.GetStore("test"); len(p.Defaults) != 1 {
+		t.Errorf("defaults after removal = %v", p.Defaults)
+	}
+
+	_ = captureStderr(t)
+
+	for _, arg := range []string{"no-such-flag=1", "force=true", "per-page"} {
+		if err := Execute([]string{"auth", "defaults", "test", arg}); ExitCode(err) != ExitUsage {
+			t.Errorf("%s: err = %v, want usage error", arg, err)
+		}
+	}
+}
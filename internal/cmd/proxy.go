@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/policy"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// hopHeaders are connection-scoped and must not be relayed by the proxy.
+var hopHeaders = map[string]bool{
+	"Connection":        true,
+	"Keep-Alive":        true,
+	"Transfer-Encoding": true,
+	"Upgrade":           true,
+	"Content-Length":    true,
+}
+
+// ProxyCmd exposes the authenticated store API on a local address.
+type ProxyCmd struct {
+	Listen string `help:"Loopback address to listen on" name:"listen" default:"127.0.0.1:9800"`
+}
+
+func (c *ProxyCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	if err := refuseNested(ctx, "proxy"); err != nil {
+		return err
+	}
+
+	if err := requireLoopback(c.Listen); err != nil {
+		return err
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	ln, err := (&net.ListenConfig{}).Listen(ctx, "tcp", c.Listen)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", c.Listen, err)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{
+		Handler:           newProxyHandler(ctx, client, ln.Addr().String()),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+
+	addr := "http://" + ln.Addr().String()
+	if outfmt.IsJSON(ctx) {
+		_ = outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"listening": addr, "upstream": client.BaseURL()})
+	} else if u != nil {
+		u.Err().Printf("proxying %s -> %s", addr, client.BaseURL())
+	}
+
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve proxy: %w", err)
+	}
+
+	return nil
+}
+
+// requireLoopback rejects listen addresses reachable from other hosts, since
+// every request through the proxy is authenticated as the store.
+func requireLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return usagef("invalid --listen address %q: %v", addr, err)
+	}
+
+	if host == "localhost" {
+		return nil
+	}
+
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+
+	return usagef("--listen must be a loopback address (got %q)", addr)
+}
+
+// newProxyHandler relays requests to the store API, injecting auth and
+// rewriting pagination links so they point back at the proxy. Requests run
+// with the values of ctx, so the policy, --expect-store, the journal and
+// the history apply to them as to the CLI's own. Only local programs may
+// use it: a request must name the proxy at listenAddr as its Host, which a
+// web page can't do through DNS rebinding, and carry no Origin, which
+// browsers add to the requests of web pages.
+func newProxyHandler(ctx context.Context, client *api.Client, listenAddr string) http.Handler {
+	upstream := client.BaseURL()
+	_, port, _ := net.SplitHostPort(listenAddr)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Cleaned, so the policy sees the resource the API will.
+		apiPath := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+
+		if !proxyHostAllowed(r.Host, port) {
+			writeProxyError(w, http.StatusForbidden, fmt.Sprintf("host %q is not this proxy", r.Host))
+			return
+		}
+
+		if r.Header.Get("Origin") != "" {
+			writeProxyError(w, http.StatusForbidden, "requests from web pages are not allowed")
+			return
+		}
+
+		reqCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stop := context.AfterFunc(r.Context(), cancel)
+		defer stop()
+
+		resp, err := client.Forward(reqCtx, r.Method, apiPath, r.URL.Query(), r.Body, r.Header.Get("Content-Type")) //nolint:bodyclose // closed below
+		if err != nil {
+			slog.DebugContext(reqCtx, "proxy request failed", "method", r.Method, "path", apiPath, "error", err)
+
+			status := http.StatusBadGateway
+			if isGuardError(err) {
+				status = http.StatusForbidden
+			}
+
+			writeProxyError(w, status, err.Error())
+
+			return
+		}
+
+		defer func() { _ = resp.Body.Close() }()
+
+		for k, vs := range resp.Header {
+			if hopHeaders[k] {
+				continue
+			}
+
+			for _, v := range vs {
+				if k == "Link" {
+					v = strings.ReplaceAll(v, upstream, "http://"+r.Host)
+				}
+
+				w.Header().Add(k, v)
+			}
+		}
+
+		w.WriteHeader(resp.StatusCode)
+		_, _ = io.Copy(w, resp.Body)
+
+		slog.DebugContext(reqCtx, "proxied", "method", r.Method, "path", apiPath, "status", resp.StatusCode)
+	})
+}
+
+// proxyHostAllowed reports whether host, a request's Host, names the proxy
+// listening on port: a loopback address or localhost with that port.
+func proxyHostAllowed(host, port string) bool {
+	h, p, err := net.SplitHostPort(host)
+	if err != nil || p != port {
+		return false
+	}
+
+	if h == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(h)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+// isGuardError reports whether err is a request refused by the policy or
+// --expect-store.
+func isGuardError(err error) bool {
+	var denied *policy.DeniedError
+
+	return errors.As(err, &denied) || ExitCode(err) == ExitMismatch
+}
+
+func writeProxyError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = outfmt.EncodeJSON(w, map[string]any{"error": msg})
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/journal"
+	"github.com/gberlati/nube-cli/internal/policy"
+)
+
+// startTestProxy serves newProxyHandler for client with the values of ctx.
+func startTestProxy(t *testing.T, ctx context.Context, client *api.Client) *httptest.Server {
+	t.Helper()
+
+	proxy := httptest.NewUnstartedServer(nil)
+	proxy.Config.Handler = newProxyHandler(ctx, client, proxy.Listener.Addr().String())
+	proxy.Start()
+	t.Cleanup(proxy.Close)
+
+	return proxy
+}
+
+func TestProxyHandler_InjectsAuthAndRelays(t *testing.T) {
+	t.Parallel()
+
+	var gotAuth, gotPath, gotQuery string
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authentication")
+		gotPath = r.URL.Path
+		gotQuery = r.URL.RawQuery
+
+		w.Header().Set("Link", `<`+"http://"+r.Host+`/v1/123/products?page=2>; rel="next"`)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`[{"id":1}]`))
+	}))
+	t.Cleanup(upstream.Close)
+
+	client := api.New("123", "secret", api.WithBaseURL(upstream.URL+"/v1"), api.WithHTTPClient(upstream.Client()))
+
+	proxy := startTestProxy(t, context.Background(), client)
+
+	resp, err := http.Get(proxy.URL + "/products?per_page=5")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if string(body) != `[{"id":1}]` {
+		t.Errorf("body = %q", body)
+	}
+
+	if gotAuth != "bearer secret" {
+		t.Errorf("Authentication = %q", gotAuth)
+	}
+
+	if gotPath != "/v1/123/products" || gotQuery != "per_page=5" {
+		t.Errorf("upstream request = %s?%s", gotPath, gotQuery)
+	}
+
+	if link := resp.Header.Get("Link"); !strings.HasPrefix(link, "<"+proxy.URL+"/products?page=2>") {
+		t.Errorf("Link = %q, want rewritten to proxy", link)
+	}
+}
+
+func TestProxyHandler_RelaysErrorStatus(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"name":["is required"]}`))
+	}))
+	t.Cleanup(upstream.Close)
+
+	client := api.New("123", "secret", api.WithBaseURL(upstream.URL+"/v1"), api.WithHTTPClient(upstream.Client()))
+
+	proxy := startTestProxy(t, context.Background(), client)
+
+	resp, err := http.Post(proxy.URL+"/products", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422", resp.StatusCode)
+	}
+}
+
+func TestProxyHandler_RejectsWebPages(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected upstream request %s %s", r.Method, r.URL.Path)
+	}))
+	t.Cleanup(upstream.Close)
+
+	client := api.New("123", "secret", api.WithBaseURL(upstream.URL+"/v1"), api.WithHTTPClient(upstream.Client()))
+	proxy := startTestProxy(t, context.Background(), client)
+
+	for name, set := range map[string]func(*http.Request){
+		"rebound host": func(r *http.Request) { r.Host = "attacker.example:" + proxy.URL[strings.LastIndex(proxy.URL, ":")+1:] },
+		"other port":   func(r *http.Request) { r.Host = "127.0.0.1:1" },
+		"origin":       func(r *http.Request) { r.Header.Set("Origin", "https://attacker.example") },
+	} {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, proxy.URL+"/customers", nil)
+		set(req)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		_ = resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("%s: status = %d, want 403", name, resp.StatusCode)
+		}
+	}
+}
+
+func TestProxyHandler_UsesCommandContext(t *testing.T) {
+	t.Parallel()
+
+	var hits int
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits++
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+	t.Cleanup(upstream.Close)
+
+	pol, err := policy.Parse([]byte("resources:\n  customers: read\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j := journal.New(filepath.Join(t.TempDir(), "journal.jsonl"))
+
+	ctx := api.WithRequestGuard(context.Background(), pol.CheckRequest)
+	ctx = journal.WithJournal(ctx, j)
+
+	client := api.New("123", "secret", api.WithBaseURL(upstream.URL+"/v1"), api.WithHTTPClient(upstream.Client()))
+	proxy := startTestProxy(t, ctx, client)
+
+	resp, err := http.Post(proxy.URL+"/products/../customers", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusForbidden || hits != 0 {
+		t.Errorf("denied write: status = %d, upstream hits = %d", resp.StatusCode, hits)
+	}
+
+	resp, err = http.Post(proxy.URL+"/products", "application/json", strings.NewReader(`{"name":"x"}`))
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+
+	_ = resp.Body.Close()
+
+	entries, err := j.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 || entries[0].Path != "products" || entries[0].Status != journal.StatusOK {
+		t.Errorf("journal = %+v, want the proxied POST", entries)
+	}
+}
+
+func TestRequireLoopback(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		addr    string
+		wantErr bool
+	}{
+		{"127.0.0.1:9800", false},
+		{"localhost:9800", false},
+		{"[::1]:9800", false},
+		{"0.0.0.0:9800", true},
+		{"192.168.1.5:9800", true},
+		{"nonsense", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			t.Parallel()
+
+			if err := requireLoopback(tt.addr); (err != nil) != tt.wantErr {
+				t.Errorf("requireLoopback(%q) error = %v, wantErr %v", tt.addr, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/redact"
+)
+
+// redactExempt lists commands whose purpose is to print a credential.
+var redactExempt = []string{"auth token"}
+
+// newRedactor masks every credential the CLI knows about: stored store and
+// partner access tokens, client secrets, secrets passed through the
+// environment and extra, such as a served run's caller's token. It returns
+// nil (no redaction) for exempt commands.
+func newRedactor(command string, extra ...string) *redact.Redactor {
+	for _, c := range redactExempt {
+		if command == c || strings.HasPrefix(command, c+" ") {
+			return nil
+		}
+	}
+
+	secrets := extra
+
+	for _, v := range envVars {
+		if v.Secret {
+			secrets = append(secrets, os.Getenv(v.Name))
+		}
+	}
+
+	// A missing or unreadable credential file leaves nothing to mask; the
+	// command itself reports the problem if it needs credentials.
+	if f, err := credstore.Read(); err == nil {
+		for _, s := range f.Stores {
+			secrets = append(secrets, s.AccessToken)
+		}
+
+		for _, c := range f.OAuthClients {
+			secrets = append(secrets, c.ClientSecret)
+		}
+
+		for _, p := range f.Partners {
+			secrets = append(secrets, p.AccessToken)
+		}
+	}
+
+	return redact.New(secrets...)
+}
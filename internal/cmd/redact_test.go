@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestExecute_RedactsStoredTokens(t *testing.T) {
+	const token = "tok-0123456789abcdef"
+
+	setupCredStore(t, map[string]credstore.StoreProfile{"shop": {StoreID: "123", AccessToken: token}}, "shop")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"id":123,"name":{"es":"leaked ` + token + `"}}`))
+	}))
+
+	stdout := captureStdout(t)
+	stderr := captureStderr(t)
+
+	if err := Execute([]string{"store", "get", "--json", "--verbose"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if out := stdout.String() + stderr.String(); strings.Contains(out, token) {
+		t.Errorf("token leaked:\n%s", out)
+	}
+
+	if !strings.Contains(stdout.String(), "leaked [REDACTED]") {
+		t.Errorf("stdout = %s", stdout.String())
+	}
+}
+
+func TestNewRedactor_PartnerTokens(t *testing.T) {
+	const token = "partner-tok-0123456789"
+
+	setupCredStore(t, map[string]credstore.StoreProfile{"shop": {StoreID: "123", AccessToken: "tok-0123456789abcdef"}}, "shop")
+
+	if err := credstore.SetPartner("agency", credstore.PartnerProfile{PartnerID: "9", AccessToken: token}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := newRedactor("partner apps").String("token " + token); got != "token [REDACTED]" {
+		t.Errorf("redacted = %q", got)
+	}
+}
+
+func TestExecute_AuthTokenNotRedacted(t *testing.T) {
+	const token = "tok-0123456789abcdef"
+
+	setupCredStore(t, map[string]credstore.StoreProfile{"shop": {StoreID: "123", AccessToken: token}}, "shop")
+
+	stdout := captureStdout(t)
+
+	if err := Execute([]string{"auth", "token", "shop"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if !strings.Contains(stdout.String(), token) {
+		t.Errorf("auth token output = %q, want the token", stdout.String())
+	}
+}
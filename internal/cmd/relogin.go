@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/oauth"
+)
+
+// reloginTimeout is how long a re-login waits for browser authorization,
+// as nube login does by default.
+const reloginTimeout = 5 * time.Minute
+
+// reloginCommands authorize stores themselves; a 401 from them isn't
+// fixed by logging in first.
+var reloginCommands = []string{"login", "logout", "auth", "init"}
+
+// retryAfterLogin offers, on a terminal, to log the active store profile
+// in again when a command fails with 401, and then runs the command once
+// more, so the user needn't retype it.
+func retryAfterLogin(ctx context.Context, flags *RootFlags, kctx *kong.Context, err error) error {
+	var authErr *api.AuthError
+	if !errors.As(err, &authErr) || isNested(ctx) || isServing(ctx) || !interactive(flags) {
+		return err
+	}
+
+	// A token from the environment isn't renewed by logging in.
+	if flags.getenv("NUBE_ACCESS_TOKEN") != "" {
+		return err
+	}
+
+	if top, _, _ := strings.Cut(schemaPath(kctx.Selected()), " "); slices.Contains(reloginCommands, top) {
+		return err
+	}
+
+	return reloginAndRetry(ctx, flags.Store, !flags.Force, kctx.Run, err)
+}
+
+// reloginAndRetry authorizes the store of the profile store resolves to
+// again, keeping the profile's settings, and returns what run returns.
+// When the user declines, or authorizes another store, err is returned.
+func reloginAndRetry(ctx context.Context, store string, ask bool, run func(...any) error, err error) error {
+	name, profile, resolveErr := credstore.ResolveStore(store)
+	if resolveErr != nil {
+		return err
+	}
+
+	if ask {
+		ok, promptErr := promptDefaultYes(fmt.Sprintf("The access token of %q was rejected. Log in again and retry?", name))
+		if promptErr != nil || !ok {
+			return err
+		}
+	}
+
+	var tok oauth.TokenResponse
+
+	opts, loginErr := authorizeOptions(reloginTimeout, os.Getenv("NUBE_AUTH_BROKER"))
+	if loginErr == nil {
+		tok, loginErr = authorizeOAuth(ctx, opts)
+	}
+
+	if loginErr != nil {
+		fmt.Fprintf(stderrFrom(ctx), "Login failed: %v\n", loginErr)
+		return err
+	}
+
+	renewed := loginProfile(tok, profile.APIBaseURL)
+	if renewed.StoreID != profile.StoreID {
+		fmt.Fprintf(stderrFrom(ctx), "You authorized store %s, but %q is store %s; not retrying\n", renewed.StoreID, name, profile.StoreID)
+		return err
+	}
+
+	renewed.Email, renewed.Defaults = profile.Email, profile.Defaults
+
+	if setErr := credstore.SetStore(name, renewed); setErr != nil {
+		return setErr
+	}
+
+	return run()
+}
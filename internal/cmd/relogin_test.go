@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/oauth"
+)
+
+func TestReloginAndRetry(t *testing.T) {
+	authErr := &api.AuthError{}
+
+	setup := func(t *testing.T, userID string) {
+		t.Helper()
+
+		setupCredStore(t, map[string]credstore.StoreProfile{
+			"shop": {StoreID: "123", AccessToken: "old", Defaults: map[string]string{"per-page": "50"}},
+		}, "shop")
+		mockAuthorizeOAuth(t, oauth.TokenResponse{AccessToken: "new", UserID: json.Number(userID), Scope: "read_orders"}, nil)
+	}
+
+	t.Run("retries with the new token", func(t *testing.T) {
+		setup(t, "123")
+		_ = captureStderr(t)
+
+		runs := 0
+		run := func(...any) error {
+			runs++
+			return nil
+		}
+
+		if err := reloginAndRetry(t.Context(), "", false, run, authErr); err != nil || runs != 1 {
+			t.Fatalf("err = %v, runs = %d, want nil and 1", err, runs)
+		}
+
+		p, err := credstore.GetStore("shop")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if p.AccessToken != "new" || p.Defaults["per-page"] != "50" || len(p.Scopes) != 1 {
+			t.Errorf("profile = %+v, want the new token and the old defaults", p)
+		}
+	})
+
+	t.Run("another store", func(t *testing.T) {
+		setup(t, "999")
+		stderr := captureStderr(t)
+
+		run := func(...any) error {
+			t.Error("ran the command against another store")
+			return nil
+		}
+
+		if err := reloginAndRetry(t.Context(), "", false, run, authErr); !errors.Is(err, authErr) {
+			t.Errorf("err = %v, want the original 401", err)
+		}
+
+		if p, _ := credstore.GetStore("shop"); p.AccessToken != "old" {
+			t.Errorf("token = %q, want the profile unchanged", p.AccessToken)
+		}
+
+		if stderr.String() == "" {
+			t.Error("no explanation on stderr")
+		}
+	})
+
+	t.Run("declined", func(t *testing.T) {
+		setup(t, "123")
+		_ = captureStderr(t)
+
+		withStdin(t, "n\n", func() {
+			err := reloginAndRetry(t.Context(), "", true, func(...any) error { return nil }, authErr)
+			if !errors.Is(err, authErr) {
+				t.Errorf("err = %v, want the original 401", err)
+			}
+		})
+	})
+}
@@ -0,0 +1,307 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/sheets"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// sheetsAPIBase is the Google Sheets API root. It is a package-level var so
+// tests can swap it.
+var sheetsAPIBase = sheets.BaseURL
+
+// ReportCmd groups reports built from store data.
+type ReportCmd struct {
+	Sales ReportSalesCmd `cmd:"" help:"Paid orders and revenue per day, week or month"`
+}
+
+type ReportSalesCmd struct {
+	DateFilterFlags `embed:""`
+	EmailFlags      `embed:""`
+	ConvertFlags    `embed:""`
+
+	By          string `help:"Period of each row: day, week (from Monday) or month" name:"by" enum:"day,week,month" default:"day"`
+	ToSheet     string `help:"Write to this Google Sheets spreadsheet ID, updating the rows of periods already there" name:"to-sheet"`
+	Tab         string `help:"Sheet tab for --to-sheet (created if missing)" name:"tab" default:"Sales"`
+	Credentials string `help:"Service-account JSON key for --to-sheet" name:"credentials" env:"GOOGLE_APPLICATION_CREDENTIALS"`
+}
+
+// salesRow is one period and currency of the sales report.
+type salesRow struct {
+	Period   string  `json:"period"`
+	Currency string  `json:"currency"`
+	Orders   int     `json:"orders"`
+	Revenue  float64 `json:"revenue"`
+	Average  float64 `json:"average"`
+}
+
+var salesHeader = []any{"period", "currency", "orders", "revenue", "average"}
+
+func (c *ReportSalesCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	emailTo, err := c.recipients()
+	if err != nil {
+		return err
+	}
+
+	var sheet *sheets.Client
+
+	if c.ToSheet != "" {
+		var err error
+		if sheet, err = c.sheetClient(flags.Timeout); err != nil {
+			return err
+		}
+	}
+
+	conv, err := c.converter(ctx, flags.Timeout)
+	if err != nil {
+		return err
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	// Without a date range the report covers the last 30 days.
+	if c.CreatedMin == "" && c.CreatedMax == "" {
+		c.CreatedMin = "30d"
+	}
+
+	zone := newZoneResolver(flags, client)
+
+	q := url.Values{"payment_status": {"paid"}, "per_page": {"200"}}
+	if err := c.resolve(ctx, q, zone); err != nil {
+		return err
+	}
+
+	loc, err := zone.location(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows, err := c.collect(ctx, client, q, loc, conv)
+	if err != nil {
+		return err
+	}
+
+	if sheet == nil && emailTo == nil {
+		return writeSalesReport(ctx, rows)
+	}
+
+	var result []resultKV
+
+	if sheet != nil {
+		if !flags.DryRun {
+			if err := c.writeSheet(ctx, sheet, rows); err != nil {
+				return err
+			}
+		}
+
+		result = append(result, kv("spreadsheet", c.ToSheet), kv("tab", c.Tab))
+	}
+
+	if emailTo != nil {
+		if !flags.DryRun {
+			if err := c.send(ctx, emailTo, c.render(rows)); err != nil {
+				return err
+			}
+		}
+
+		result = append(result, kv("emailed", strings.Join(emailTo, ",")))
+	}
+
+	if flags.DryRun {
+		result = append([]resultKV{kv("dry_run", true)}, result...)
+	}
+
+	return writeResult(ctx, u, append(result, kv("rows", len(rows)))...)
+}
+
+// render lays the report out for email.
+func (c *ReportSalesCmd) render(rows []salesRow) tableReport {
+	r := tableReport{
+		Title:  "Sales by " + c.By,
+		Header: []string{"period", "currency", "orders", "revenue", "average"},
+		File:   "sales.csv",
+	}
+
+	if len(rows) > 0 {
+		r.Title += ", " + rows[0].Period + " to " + rows[len(rows)-1].Period
+	}
+
+	for _, row := range rows {
+		r.Rows = append(r.Rows, []string{
+			row.Period, row.Currency, strconv.Itoa(row.Orders),
+			strconv.FormatFloat(row.Revenue, 'f', 2, 64), strconv.FormatFloat(row.Average, 'f', 2, 64),
+		})
+	}
+
+	return r
+}
+
+func (c *ReportSalesCmd) sheetClient(timeout time.Duration) (*sheets.Client, error) {
+	if c.Credentials == "" {
+		return nil, usagef("--to-sheet needs a service-account key: pass --credentials or set GOOGLE_APPLICATION_CREDENTIALS")
+	}
+
+	creds, err := sheets.LoadCredentials(c.Credentials)
+	if errors.Is(err, sheets.ErrCredentials) {
+		return nil, newUsageError(err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return sheets.New(creds, sheetsAPIBase, &http.Client{Timeout: timeout}), nil
+}
+
+// collect sums paid, not cancelled, orders per period (in loc) and currency.
+// With conv, every order is converted and counted in the target currency.
+func (c *ReportSalesCmd) collect(ctx context.Context, client *api.Client, q url.Values, loc *time.Location, conv *currencyConverter) ([]salesRow, error) {
+	byKey := map[[2]string]*salesRow{}
+
+	for page, err := range api.Pages[map[string]any](ctx, client, "orders", q) {
+		if err != nil {
+			return nil, err
+		}
+
+		for _, o := range page.Items {
+			if jsonStr(o, "status") == "cancelled" {
+				continue
+			}
+
+			created, ok := parseAPITime(jsonStr(o, "created_at"))
+			if !ok {
+				continue
+			}
+
+			total, _ := strconv.ParseFloat(jsonStr(o, "total"), 64)
+			currency := jsonStr(o, "currency")
+
+			if conv != nil {
+				if total, err = conv.convert(total, currency); err != nil {
+					return nil, err
+				}
+
+				currency = conv.to
+			}
+
+			key := [2]string{salesPeriod(created.In(loc), c.By), currency}
+
+			row := byKey[key]
+			if row == nil {
+				row = &salesRow{Period: key[0], Currency: key[1]}
+				byKey[key] = row
+			}
+
+			row.Orders++
+			row.Revenue += total
+		}
+	}
+
+	rows := make([]salesRow, 0, len(byKey))
+
+	for _, r := range byKey {
+		r.Revenue = math.Round(r.Revenue*100) / 100
+		r.Average = math.Round(r.Revenue/float64(r.Orders)*100) / 100
+		rows = append(rows, *r)
+	}
+
+	slices.SortFunc(rows, func(a, b salesRow) int {
+		return cmp.Or(cmp.Compare(a.Period, b.Period), cmp.Compare(a.Currency, b.Currency))
+	})
+
+	return rows, nil
+}
+
+// writeSheet merges rows into the tab, keyed by period and currency, so
+// rerunning the report refreshes recent periods and keeps older ones.
+func (c *ReportSalesCmd) writeSheet(ctx context.Context, sheet *sheets.Client, rows []salesRow) error {
+	if err := sheet.EnsureTab(ctx, c.ToSheet, c.Tab); err != nil {
+		return sheetError(err, sheet.Email())
+	}
+
+	existing, err := sheet.Values(ctx, c.ToSheet, c.Tab)
+	if err != nil {
+		return sheetError(err, sheet.Email())
+	}
+
+	values := make([][]any, len(rows))
+	for i, r := range rows {
+		values[i] = []any{r.Period, r.Currency, r.Orders, r.Revenue, r.Average}
+	}
+
+	if err := sheet.Update(ctx, c.ToSheet, c.Tab, sheets.Merge(existing, salesHeader, values, 2)); err != nil {
+		return sheetError(err, sheet.Email())
+	}
+
+	return nil
+}
+
+// sheetError adds the usual fix to Google's "not found" and "no permission"
+// answers: the spreadsheet isn't shared with the service account.
+func sheetError(err error, email string) error {
+	var gErr *sheets.Error
+	if !errors.As(err, &gErr) || (gErr.Status != http.StatusForbidden && gErr.Status != http.StatusNotFound) {
+		return err
+	}
+
+	return fmt.Errorf("%w; check the spreadsheet ID and share it with %s as an editor", err, email)
+}
+
+func writeSalesReport(ctx context.Context, rows []salesRow) error {
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), rows)
+	}
+
+	w, done := tableWriter(ctx)
+	defer done()
+
+	_, _ = fmt.Fprintln(w, "PERIOD\tCURRENCY\tORDERS\tREVENUE\tAVERAGE")
+
+	for _, r := range rows {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%.2f\t%.2f\n", r.Period, r.Currency, r.Orders, r.Revenue, r.Average)
+	}
+
+	return nil
+}
+
+// salesPeriod names the day, week (by its Monday) or month of t.
+func salesPeriod(t time.Time, by string) string {
+	switch by {
+	case "week":
+		offset := (int(t.Weekday()) + 6) % 7
+
+		return t.AddDate(0, 0, -offset).Format(time.DateOnly)
+	case "month":
+		return t.Format("2006-01")
+	default:
+		return t.Format(time.DateOnly)
+	}
+}
+
+func parseAPITime(s string) (time.Time, bool) {
+	for _, layout := range apiTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
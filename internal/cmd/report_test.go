@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/mailer"
+)
+
+func setupReportAPI(t *testing.T) *string {
+	t.Helper()
+
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var query string
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/123/orders" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		query = r.URL.RawQuery
+
+		_, _ = w.Write([]byte(`[
+			{"id":1,"status":"open","total":"100.00","currency":"ARS","created_at":"2024-06-03T10:00:00+0000"},
+			{"id":2,"status":"closed","total":"50.50","currency":"ARS","created_at":"2024-06-03T22:00:00+0000"},
+			{"id":3,"status":"cancelled","total":"999.00","currency":"ARS","created_at":"2024-06-03T11:00:00+0000"},
+			{"id":4,"status":"open","total":"20.00","currency":"USD","created_at":"2024-06-04T09:00:00+0000"},
+			{"id":5,"status":"open","total":"10.00","currency":"ARS","created_at":"2024-06-09T09:00:00+0000"}
+		]`))
+	}))
+
+	return &query
+}
+
+func TestReportSales(t *testing.T) {
+	tests := []struct {
+		by   string
+		want []salesRow
+	}{
+		{by: "day", want: []salesRow{
+			{Period: "2024-06-03", Currency: "ARS", Orders: 2, Revenue: 150.5, Average: 75.25},
+			{Period: "2024-06-04", Currency: "USD", Orders: 1, Revenue: 20, Average: 20},
+			{Period: "2024-06-09", Currency: "ARS", Orders: 1, Revenue: 10, Average: 10},
+		}},
+		{by: "week", want: []salesRow{
+			{Period: "2024-06-03", Currency: "ARS", Orders: 3, Revenue: 160.5, Average: 53.5},
+			{Period: "2024-06-03", Currency: "USD", Orders: 1, Revenue: 20, Average: 20},
+		}},
+		{by: "month", want: []salesRow{
+			{Period: "2024-06", Currency: "ARS", Orders: 3, Revenue: 160.5, Average: 53.5},
+			{Period: "2024-06", Currency: "USD", Orders: 1, Revenue: 20, Average: 20},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.by, func(t *testing.T) {
+			query := setupReportAPI(t)
+
+			out := captureStdout(t)
+
+			if err := Execute([]string{"--json", "--tz", "UTC", "report", "sales", "--by", tt.by}); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			var got []salesRow
+			if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+				t.Fatalf("unmarshal: %v\n%s", err, out.String())
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %+v, want %+v", got, tt.want)
+			}
+
+			if !strings.Contains(*query, "payment_status=paid") || !strings.Contains(*query, "created_at_min=") {
+				t.Errorf("query = %q, want paid orders of the last 30 days", *query)
+			}
+		})
+	}
+}
+
+func TestSalesPeriod_Zone(t *testing.T) {
+	t.Parallel()
+
+	sp, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skip("no tzdata")
+	}
+
+	// 01:00 UTC on a Monday is still Sunday in São Paulo.
+	at := time.Date(2024, 6, 3, 1, 0, 0, 0, time.UTC).In(sp)
+
+	for by, want := range map[string]string{"day": "2024-06-02", "week": "2024-05-27", "month": "2024-06"} {
+		if got := salesPeriod(at, by); got != want {
+			t.Errorf("salesPeriod(%s) = %s, want %s", by, got, want)
+		}
+	}
+}
+
+// writeServiceAccountKey writes a service-account key whose token endpoint
+// is tokenURI.
+func writeServiceAccountKey(t *testing.T, tokenURI string) string {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := json.Marshal(map[string]string{
+		"client_email": "reports@shop.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    tokenURI,
+	})
+
+	path := filepath.Join(t.TempDir(), "sa.json")
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestReportSales_ToSheet(t *testing.T) {
+	setupReportAPI(t)
+
+	var written [][]any
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			_, _ = io.WriteString(w, `{"access_token":"t","expires_in":3600}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/v4/spreadsheets/sheet1":
+			_, _ = io.WriteString(w, `{"sheets":[{"properties":{"title":"Sales"}}]}`)
+		case r.Method == http.MethodGet:
+			_, _ = io.WriteString(w, `{"values":[["period","currency","orders","revenue","average"],["2024-06-02","ARS","7","700","100"],["2024-06-03","ARS","1","1","1"]]}`)
+		case r.Method == http.MethodPut:
+			var body struct {
+				Values [][]any `json:"values"`
+			}
+
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			written = body.Values
+			_, _ = io.WriteString(w, `{}`)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	orig := sheetsAPIBase
+	sheetsAPIBase = srv.URL
+
+	t.Cleanup(func() { sheetsAPIBase = orig })
+
+	key := writeServiceAccountKey(t, srv.URL+"/token")
+
+	_ = captureStdout(t)
+
+	if err := Execute([]string{"--tz", "UTC", "report", "sales", "--to-sheet", "sheet1", "--credentials", key}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var periods []string
+	for _, row := range written[1:] {
+		periods = append(periods, fmt.Sprintf("%v/%v", row[0], row[2]))
+	}
+
+	if got := strings.Join(periods, " "); got != "2024-06-02/7 2024-06-03/2 2024-06-04/1 2024-06-09/1" {
+		t.Errorf("sheet rows = %s", got)
+	}
+}
+
+func TestReportSales_ToSheetNeedsKey(t *testing.T) {
+	setupReportAPI(t)
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+
+	_ = captureStdout(t)
+	_ = captureStderr(t)
+
+	if err := Execute([]string{"report", "sales", "--to-sheet", "sheet1"}); ExitCode(err) != ExitUsage {
+		t.Errorf("exit code = %d, want %d (%v)", ExitCode(err), ExitUsage, err)
+	}
+}
+
+func TestReportSales_EmailTo(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell script")
+	}
+
+	setupReportAPI(t)
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "message")
+	script := filepath.Join(dir, "sendmail")
+
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncat > "+strconv.Quote(out)+"\n"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	orig := mailer.Sendmail
+	mailer.Sendmail = script
+
+	t.Cleanup(func() { mailer.Sendmail = orig })
+
+	_ = captureStdout(t)
+
+	if err := Execute([]string{"--tz", "UTC", "report", "sales", "--email-to", "Owner <owner@shop.test>, ops@shop.test"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	raw, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := string(raw)
+	for _, want := range []string{"To: owner@shop.test, ops@shop.test", "Subject: Sales by day, 2024-06-03 to 2024-06-09", `filename=sales.csv`} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message lacks %q:\n%s", want, msg)
+		}
+	}
+}
+
+func TestReportSales_EmailToInvalid(t *testing.T) {
+	setupReportAPI(t)
+
+	_ = captureStdout(t)
+	_ = captureStderr(t)
+
+	if err := Execute([]string{"report", "sales", "--email-to", "not an address"}); ExitCode(err) != ExitUsage {
+		t.Errorf("exit code = %d, want %d (%v)", ExitCode(err), ExitUsage, err)
+	}
+}
+
+func TestRenderSalesReport(t *testing.T) {
+	t.Parallel()
+
+	r := (&ReportSalesCmd{By: "week"}).render([]salesRow{{Period: "2024-06-03", Currency: "ARS", Orders: 2, Revenue: 150.5, Average: 75.25}})
+
+	var html bytes.Buffer
+	if err := reportHTML.Execute(&html, r); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(html.String(), "<td style=\"border-bottom:1px solid #ddd\">150.50</td>") || r.Title != "Sales by week, 2024-06-03 to 2024-06-03" {
+		t.Errorf("title %q, html:\n%s", r.Title, html.String())
+	}
+}
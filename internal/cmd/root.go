@@ -4,15 +4,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alecthomas/kong"
 
+	"github.com/gberlati/nube-cli/internal/api"
 	"github.com/gberlati/nube-cli/internal/credstore"
 	"github.com/gberlati/nube-cli/internal/errfmt"
+	"github.com/gberlati/nube-cli/internal/history"
+	"github.com/gberlati/nube-cli/internal/i18n"
+	"github.com/gberlati/nube-cli/internal/journal"
 	"github.com/gberlati/nube-cli/internal/outfmt"
 	"github.com/gberlati/nube-cli/internal/ui"
 )
@@ -23,16 +30,40 @@ const (
 )
 
 type RootFlags struct {
-	Color          string `help:"Color output: auto|always|never" default:"${color}"`
-	Store          string `help:"Store profile name" short:"s" env:"NUBE_STORE"`
-	EnableCommands string `help:"Comma-separated list of enabled top-level commands (restricts CLI)" default:"${enabled_commands}"`
-	JSON           bool   `help:"Output JSON to stdout (best for scripting)" default:"${json}" short:"j"`
-	Plain          bool   `help:"Output stable, parseable text to stdout (TSV; no colors)" default:"${plain}" short:"p"`
-	Select         string `help:"Comma-separated list of fields to select from JSON output (supports dot paths)" short:"S"`
-	Force          bool   `help:"Skip confirmations for destructive commands" aliases:"yes,assume-yes" short:"y"`
-	NoInput        bool   `help:"Never prompt; fail instead (useful for CI)" aliases:"non-interactive,noninteractive"`
-	DryRun         bool   `help:"Show what would be done without executing" short:"n"`
-	Verbose        bool   `help:"Enable verbose logging" short:"v"`
+	Color          string        `help:"Color output: auto|always|never" default:"${color}"`
+	Store          string        `help:"Store profile name" short:"s" env:"NUBE_STORE"`
+	APIBaseURL     string        `help:"API base URL, e.g. a mock or staging server (default: the profile's, else the Tienda Nube API)" env:"NUBE_API_BASE_URL" name:"api-base-url"`
+	EnableCommands string        `help:"Comma-separated list of enabled top-level commands (restricts CLI)" default:"${enabled_commands}"`
+	JSON           bool          `help:"Output JSON to stdout (best for scripting)" default:"${json}" short:"j"`
+	Envelope       bool          `help:"Wrap JSON output in an {ok,data,error,meta} envelope (implies --json)" env:"NUBE_ENVELOPE"`
+	JSONErrors     string        `help:"Where --json writes error objects: stdout|stderr" default:"stdout" enum:"stdout,stderr" env:"NUBE_JSON_ERRORS" name:"json-errors"`
+	Plain          bool          `help:"Output stable, parseable text to stdout (TSV; no colors)" default:"${plain}" short:"p"`
+	Select         string        `help:"Comma-separated list of fields to select from JSON output (dot paths, * wildcards, !path to exclude)" short:"S"`
+	Flatten        string        `help:"Print JSON output flattened: tsv (key<TAB>value rows) or csv (a row per item, dotted columns)" enum:",tsv,csv" default:"" env:"NUBE_FLATTEN" name:"flatten"`
+	YAML           bool          `help:"Output YAML instead of JSON (implies --json; error objects stay JSON)" env:"NUBE_YAML" name:"yaml"`
+	Force          bool          `help:"Skip confirmations for destructive commands" aliases:"yes,assume-yes" short:"y"`
+	NoInput        bool          `help:"Never prompt; fail instead (useful for CI)" aliases:"non-interactive,noninteractive"`
+	DryRun         bool          `help:"Show what would be done without executing" short:"n"`
+	Verbose        bool          `help:"Enable verbose logging" short:"v"`
+	LogFormat      string        `help:"Log format: text|json" enum:"text,json" default:"text" env:"NUBE_LOG_FORMAT" name:"log-format"`
+	LogFile        string        `help:"Also write logs to this file, rotated at 10 MB (3 backups kept)" env:"NUBE_LOG_FILE" name:"log-file" type:"path"`
+	Daemon         string        `help:"Forward this invocation to a 'nube serve' socket" env:"NUBE_DAEMON" name:"daemon"`
+	NoJournal      bool          `help:"Don't record write requests in the local journal" env:"NUBE_NO_JOURNAL" name:"no-journal"`
+	NoHistory      bool          `help:"Don't snapshot resources before updates and deletes" env:"NUBE_NO_HISTORY" name:"no-history"`
+	Timeout        time.Duration `help:"Per-request HTTP timeout, including retries" default:"30s" env:"NUBE_TIMEOUT"`
+	TotalDeadline  time.Duration `help:"Abort the whole command after this long (0 = no limit)" default:"0s" env:"NUBE_TOTAL_DEADLINE" name:"total-deadline"`
+	MockDir        string        `help:"Serve API requests from fixture files in this directory instead of the network" env:"NUBE_MOCK_DIR" name:"mock-dir" type:"path"`
+	Record         string        `help:"Save every API response as a fixture file in this directory" env:"NUBE_RECORD_DIR" name:"record" type:"path"`
+	Lang           string        `help:"Language of help and messages: en|es|pt" env:"NUBE_LANG" name:"lang"`
+	LangPriority   []string      `help:"Order in which translated names are picked for tables, e.g. pt,es,en" env:"NUBE_LANG_PRIORITY" name:"lang-priority" sep:","`
+	ExpectStore    string        `help:"Abort before any request unless the active store has this profile name or store ID" env:"NUBE_EXPECT_STORE" name:"expect-store"`
+	TZ             string        `help:"Time zone for date filters and table timestamps: local, store or an IANA name (default: the store's)" env:"NUBE_TZ" name:"tz"`
+	RawNumbers     bool          `help:"Show amounts in tables as the API returns them, without currency formatting" env:"NUBE_RAW_NUMBERS" name:"raw-numbers"`
+	Stats          bool          `help:"Print a summary of API requests, retries, 429s, bytes, pages and time to stderr (meta.stats with --envelope)" env:"NUBE_STATS" name:"stats"`
+
+	// callerEnv is the caller's environment in a run nube serve executes
+	// (see getenv); nil otherwise.
+	callerEnv map[string]string
 }
 
 type CLI struct {
@@ -47,30 +78,92 @@ type CLI struct {
 	Status   AuthStatusCmd  `cmd:"" name:"status" help:"Show auth status (alias for 'auth status')"`
 	Login    LoginCmd       `cmd:"" name:"login" help:"Authorize and store a profile"`
 	Logout   LogoutCmd      `cmd:"" name:"logout" help:"Remove a store profile"`
+	Use      UseCmd         `cmd:"" name:"use" help:"Switch the store profile for this shell session"`
+	Init     InitCmd        `cmd:"" name:"init" help:"Set up nube step by step: log in, name the store, pick the output and install tab completion"`
 
 	// Domain commands.
-	Auth     AuthCmd     `cmd:"" help:"Auth and credentials"`
-	Store    StoreCmd    `cmd:"" help:"Store information"`
-	Product  ProductCmd  `cmd:"" aliases:"prod" help:"Manage products"`
-	Order    OrderCmd    `cmd:"" aliases:"ord" help:"Manage orders"`
-	Category CategoryCmd `cmd:"" aliases:"cat" help:"Manage categories"`
-	Customer CustomerCmd `cmd:"" aliases:"cust" help:"Manage customers"`
-	Config   ConfigCmd   `cmd:"" help:"Manage configuration"`
-	Agent    AgentCmd    `cmd:"" help:"Agent-friendly helpers"`
-	Schema   SchemaCmd   `cmd:"" help:"Machine-readable command schema" aliases:"help-json"`
-
-	VersionCmd VersionCmd `cmd:"" name:"version" help:"Print version"`
-	Help       HelpCmd    `cmd:"" help:"Show help (same as --help)"`
+	Auth         AuthCmd         `cmd:"" help:"Auth and credentials"`
+	Broker       BrokerCmd       `cmd:"" help:"Run your own OAuth broker for nube login"`
+	Store        StoreCmd        `cmd:"" help:"Store information"`
+	Product      ProductCmd      `cmd:"" aliases:"prod" help:"Manage products"`
+	Order        OrderCmd        `cmd:"" aliases:"ord" help:"Manage orders"`
+	Category     CategoryCmd     `cmd:"" aliases:"cat" help:"Manage categories"`
+	Customer     CustomerCmd     `cmd:"" aliases:"cust" help:"Manage customers"`
+	Payment      PaymentCmd      `cmd:"" help:"Payment providers and checkout payment options"`
+	Search       SearchCmd       `cmd:"" help:"Search products, orders and customers at once"`
+	Config       ConfigCmd       `cmd:"" help:"Manage configuration"`
+	Env          EnvCmd          `cmd:"" help:"List the NUBE_* environment variables nube reads and their current values"`
+	Agent        AgentCmd        `cmd:"" help:"Agent-friendly helpers"`
+	Schema       SchemaCmd       `cmd:"" help:"Machine-readable command schema" aliases:"help-json"`
+	Serve        ServeCmd        `cmd:"" help:"Run a local JSON-RPC daemon for repeated invocations"`
+	Proxy        ProxyCmd        `cmd:"" help:"Expose the authenticated store API on localhost"`
+	Batch        BatchCmd        `cmd:"" help:"Run several commands from a script file"`
+	Assert       AssertCmd       `cmd:"" help:"Run a command and check its JSON output with jq (exit 0 or 1)"`
+	Bench        BenchCmd        `cmd:"" help:"Run commands several times and compare their time and API requests"`
+	Wait         WaitCmd         `cmd:"" help:"Poll an order, product, customer or category until fields have given values"`
+	Journal      JournalCmd      `cmd:"" help:"Inspect and retry journaled write requests"`
+	History      HistoryCmd      `cmd:"" help:"List resource snapshots taken before writes"`
+	Undo         UndoCmd         `cmd:"" help:"Restore a resource from its pre-write snapshot"`
+	Apply        ApplyCmd        `cmd:"" help:"Create or update resources to match a manifest file"`
+	Snapshot     SnapshotCmd     `cmd:"" help:"Capture store state and detect drift"`
+	Cache        CacheCmd        `cmd:"" help:"Keep a local copy of store resources and search it offline"`
+	Report       ReportCmd       `cmd:"" help:"Sales reports, optionally written to Google Sheets"`
+	Crosslist    CrosslistCmd    `cmd:"" help:"List the catalog on marketplaces such as MercadoLibre"`
+	Theme        ThemeCmd        `cmd:"" help:"Sync storefront theme files with a local directory over FTP"`
+	Export       ExportCmd       `cmd:"" help:"Export products, orders, customers or categories as JSON lines, CSV or Parquet"`
+	Sync         SyncCmd         `cmd:"" help:"Update a local SQLite mirror, or push stock levels from an ERP"`
+	SQL          SQLCmd          `cmd:"" name:"sql" help:"Query the local SQLite mirror with SQL"`
+	GraphQL      GraphQLCmd      `cmd:"" name:"graphql" help:"Query the GraphQL API"`
+	API          APICmd          `cmd:"" name:"api" help:"Send a raw request to the store API"`
+	Seed         SeedCmd         `cmd:"" help:"Populate a test store with fake products and orders"`
+	Webhook      WebhookCmd      `cmd:"" help:"Webhook development helpers"`
+	Dev          DevCmd          `cmd:"" help:"Helpers for contributors to nube"`
+	Notify       NotifyCmd       `cmd:"" help:"Send chat notifications about store activity"`
+	RunScheduled RunScheduledCmd `cmd:"" name:"run-scheduled" help:"Run a command from cron with locking and run summaries"`
+	Schedule     ScheduleCmd     `cmd:"" help:"Run commands at a set time (from cron)"`
+	Partner      PartnerCmd      `cmd:"" help:"Manage your apps through the partners API"`
+
+	Completion CompletionCmd `cmd:"" name:"completion" help:"Print a tab completion script for bash, zsh or fish"`
+	Complete   CompleteCmd   `cmd:"" name:"__complete" hidden:"" help:"Print completions for a command line (used by the completion scripts)"`
+	VersionCmd VersionCmd    `cmd:"" name:"version" help:"Print version"`
+	Help       HelpCmd       `cmd:"" help:"Show help (same as --help)"`
 }
 
 type exitPanic struct{ code int }
 
-func Execute(args []string) (err error) {
-	parser, cli, err := newParser(helpDescription())
+func Execute(args []string) error {
+	ctx, stop := withInterrupt(context.Background(), os.Stderr)
+	defer stop()
+
+	return execute(ctx, args, os.Stdout, os.Stderr)
+}
+
+// execute runs one CLI invocation with its own output streams.
+func execute(baseCtx context.Context, args []string, stdout, stderr io.Writer) (err error) {
+	start := time.Now()
+
+	// Nested runs keep the parent's language. The default is process-wide,
+	// so served runs, which run concurrently, carry theirs in ctx only.
+	tr := i18n.FromContext(baseCtx)
+	if !isNested(baseCtx) {
+		tr = i18n.New(resolveLang(args, servedGetenv(baseCtx)))
+		if !isServing(baseCtx) {
+			i18n.SetDefault(tr)
+		}
+	}
+
+	baseCtx = i18n.WithTranslator(baseCtx, tr)
+
+	parser, cli, err := newParser(helpDescription(tr), tr, stdout, stderr)
 	if err != nil {
 		return err
 	}
 
+	translateHelp(parser.Model.Node, tr)
+
+	// Set before parsing: the profile defaults resolver reads it.
+	cli.callerEnv = servedEnv(baseCtx)
+
 	defer func() {
 		if r := recover(); r != nil {
 			if ep, ok := r.(exitPanic); ok {
@@ -91,36 +184,174 @@ func Execute(args []string) (err error) {
 	kctx, err := parser.Parse(args)
 	if err != nil {
 		parsedErr := wrapParseError(err)
-		_, _ = fmt.Fprintln(os.Stderr, errfmt.Format(parsedErr))
+		_, _ = fmt.Fprintln(stderr, errfmt.FormatIn(tr, parsedErr))
 
 		return parsedErr
 	}
 
+	if cli.Lang != "" && i18n.Normalize(cli.Lang) == "" {
+		err = usagef("unsupported language %q (want en, es, or pt)", cli.Lang)
+		_, _ = fmt.Fprintln(stderr, errfmt.FormatIn(tr, err))
+
+		return err
+	}
+
+	// Like the message language, the translation order is process-wide;
+	// a served run can't change the daemon's.
+	if !isNested(baseCtx) {
+		var order []string
+
+		order, err = resolveLangPriority(cli.LangPriority)
+		if err == nil && isServing(baseCtx) && !slices.Equal(order, translationOrder()) && len(order) > 0 {
+			err = usagef("--lang-priority applies to the whole daemon: start `nube serve` with it")
+		}
+
+		if err != nil {
+			_, _ = fmt.Fprintln(stderr, errfmt.FormatIn(tr, err))
+
+			return err
+		}
+
+		if !isServing(baseCtx) {
+			setLangPriority(order)
+		}
+	}
+
 	if err = enforceEnabledCommands(kctx, cli.EnableCommands); err != nil {
-		_, _ = fmt.Fprintln(os.Stderr, errfmt.Format(err))
+		_, _ = fmt.Fprintln(stderr, errfmt.FormatIn(tr, err))
+		return err
+	}
+
+	pol, err := loadPolicy(&cli.RootFlags)
+	if err == nil {
+		err = enforcePolicy(kctx, pol, cli.Force)
+	}
+
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, errfmt.FormatIn(tr, err))
+		return err
+	}
+
+	// Checked here too, so a restricted caller can't slip past its limits
+	// through a daemon; the daemon checks again with the caller's.
+	if cli.Daemon != "" && !isServing(baseCtx) {
+		err = forwardToDaemon(baseCtx, cli.Daemon, args, stdout, stderr)
+		if msg := strings.TrimSpace(errfmt.FormatIn(tr, err)); msg != "" {
+			_, _ = fmt.Fprintln(stderr, msg)
+		}
+
 		return err
 	}
 
+	// A served run has no terminal of its own: the daemon's stdin isn't
+	// the caller's.
+	if isServing(baseCtx) {
+		cli.NoInput = true
+	}
+
+	// Mask credentials in everything the command prints or logs, so
+	// verbose output can be pasted into CI logs and bug reports.
+	redactor := newRedactor(kctx.Command(), cli.getenv("NUBE_ACCESS_TOKEN"))
+	if cli.API.RevealToken {
+		// Asked for explicitly; the token is the point of the output.
+		redactor = nil
+	}
+	stdout = redactor.Writer(stdout)
+	stderr = redactor.Writer(stderr)
+
 	logLevel := slog.LevelWarn
 	if cli.Verbose {
 		logLevel = slog.LevelDebug
 	}
 
-	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
-		Level: logLevel,
-	})))
+	// Nested runs share the parent's logger; swapping the process-wide
+	// default from concurrent steps would race, so served runs log through
+	// ctx (see ctxLogHandler).
+	var runLog slog.Handler
+
+	switch {
+	case isNested(baseCtx):
+	case isServing(baseCtx):
+		var closeLog func()
 
-	mode, err := outfmt.FromFlags(cli.JSON, cli.Plain)
+		runLog, closeLog, err = newRunLogHandler(cli.LogFormat, cli.LogFile, logLevel, stderr, redactor)
+		if err != nil {
+			_, _ = fmt.Fprintln(stderr, errfmt.FormatIn(tr, err))
+
+			return err
+		}
+
+		defer closeLog()
+	default:
+		var closeLog func()
+
+		closeLog, err = setupLogging(cli.LogFormat, cli.LogFile, logLevel, stderr, redactor)
+		if err != nil {
+			_, _ = fmt.Fprintln(stderr, errfmt.FormatIn(tr, err))
+
+			return err
+		}
+
+		defer closeLog()
+	}
+
+	if cli.Flatten != "" && cli.Envelope {
+		err = usagef("--flatten and --envelope can't be combined")
+		_, _ = fmt.Fprintln(stderr, errfmt.FormatIn(tr, err))
+
+		return err
+	}
+
+	if cli.YAML && (cli.Flatten != "" || cli.Envelope) {
+		err = usagef("--yaml can't be combined with --flatten or --envelope")
+		_, _ = fmt.Fprintln(stderr, errfmt.FormatIn(tr, err))
+
+		return err
+	}
+
+	mode, err := outfmt.FromFlags(cli.JSON || cli.Envelope || cli.Flatten != "" || cli.YAML, cli.Plain)
 	if err != nil {
 		return newUsageError(err)
 	}
 
-	ctx := context.Background()
+	if cli.MockDir != "" && cli.Record != "" {
+		err = usagef("--mock-dir and --record can't be combined")
+		_, _ = fmt.Fprintln(stderr, errfmt.FormatIn(tr, err))
+
+		return err
+	}
+
+	ctx := withStdio(baseCtx, stdout, stderr)
+	if runLog != nil {
+		ctx = withLogHandler(ctx, runLog)
+	}
+
 	ctx = outfmt.WithMode(ctx, mode)
 
-	if cli.Select != "" {
-		fields := strings.Split(cli.Select, ",")
-		ctx = outfmt.WithJSONTransform(ctx, outfmt.JSONTransform{Select: fields})
+	if !cli.NoJournal {
+		if path, pathErr := journal.DefaultPath(); pathErr == nil {
+			ctx = journal.WithJournal(ctx, journal.New(path))
+		}
+	}
+
+	if !cli.NoHistory {
+		if path, pathErr := history.DefaultPath(); pathErr == nil {
+			ctx = history.WithHistory(ctx, history.New(path))
+		}
+	}
+
+	selectExpr := cli.Select
+	if selectExpr == "" && cli.Envelope {
+		selectExpr = readAgentLimits().defaultSelect
+	}
+
+	if selectExpr != "" || cli.Flatten != "" || cli.YAML {
+		var fields []string
+		if selectExpr != "" {
+			fields = strings.Split(selectExpr, ",")
+		}
+
+		ctx = outfmt.WithJSONTransform(ctx, outfmt.JSONTransform{Select: fields, Flatten: cli.Flatten, YAML: cli.YAML})
 	}
 
 	uiColor := cli.Color
@@ -128,10 +359,17 @@ func Execute(args []string) (err error) {
 		uiColor = colorNever
 	}
 
+	theme, err := configTheme()
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, errfmt.FormatIn(tr, err))
+		return err
+	}
+
 	u, err := ui.New(ui.Options{
-		Stdout: os.Stdout,
-		Stderr: os.Stderr,
+		Stdout: stdout,
+		Stderr: stderr,
 		Color:  uiColor,
+		Theme:  theme,
 	})
 	if err != nil {
 		return err
@@ -139,37 +377,135 @@ func Execute(args []string) (err error) {
 
 	ctx = ui.WithUI(ctx, u)
 
+	guard := pol.CheckRequest
+	if sg := newStoreGuard(&cli.RootFlags, u); sg.active() {
+		guard = func(method, path string) error {
+			if err := pol.CheckRequest(method, path); err != nil {
+				return err
+			}
+
+			return sg.check(method, path)
+		}
+	}
+
+	// A nested run is held to its parent's guard as well as its own.
+	if parent := api.RequestGuardFromContext(baseCtx); parent != nil && isNested(baseCtx) {
+		own := guard
+		guard = func(method, path string) error {
+			if err := parent(method, path); err != nil {
+				return err
+			}
+
+			return own(method, path)
+		}
+	}
+
+	ctx = api.WithRequestGuard(ctx, guard)
+
+	var (
+		capture  *outfmt.Capture
+		recorder *api.Recorder
+	)
+
+	if cli.Envelope || cli.Stats {
+		recorder = &api.Recorder{}
+		ctx = api.WithRecorder(ctx, recorder)
+	}
+
+	if cli.Envelope {
+		capture = &outfmt.Capture{}
+		ctx = outfmt.WithCapture(ctx, capture)
+	}
+
+	if cli.TotalDeadline > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, cli.TotalDeadline)
+		defer cancel()
+	}
+
+	ctx, endTelemetry := startTelemetry(baseCtx, ctx, kctx.Command())
+
 	kctx.BindTo(ctx, (*context.Context)(nil))
 	kctx.Bind(&cli.RootFlags)
 	kctx.Bind(parser)
 
-	err = kctx.Run()
-	if err == nil {
-		return nil
+	err = retryAfterLogin(ctx, &cli.RootFlags, kctx, kctx.Run())
+	if ExitCode(err) == 0 {
+		err = nil
 	}
 
-	if ExitCode(err) == 0 {
+	err = interruptedErr(baseCtx, err)
+
+	if err != nil && cli.TotalDeadline > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("total deadline of %s exceeded: %w", cli.TotalDeadline, err)
+	}
+
+	if err != nil {
+		// Wrap with stable exit code if not already wrapped.
+		var ee *ExitErr
+		if !errors.As(err, &ee) {
+			err = &ExitErr{Code: stableExitCode(err), Err: err}
+		}
+
+		err = withSuggestion(err, tr, kctx, &cli.RootFlags)
+	}
+
+	endTelemetry(err)
+
+	var stats *runStats
+	if cli.Stats {
+		stats = newRunStats(recorder, start)
+	}
+
+	if cli.Envelope {
+		if envErr := writeEnvelope(stdout, tr, &cli.RootFlags, capture, recorder, start, stats, err); envErr != nil && err == nil {
+			return envErr
+		}
+	} else if stats != nil {
+		writeStats(stderr, outfmt.IsJSON(ctx), stats)
+	}
+
+	if err == nil {
 		return nil
 	}
 
-	// Wrap with stable exit code if not already wrapped.
-	var ee *ExitErr
-	if !errors.As(err, &ee) {
-		err = &ExitErr{Code: stableExitCode(err), Err: err}
+	// A bare exit code (empty message) means the command already reported
+	// the failure in its own output.
+	if outfmt.IsJSON(ctx) && !cli.Envelope && err.Error() != "" {
+		errOut := stdout
+		if cli.JSONErrors == "stderr" {
+			errOut = stderr
+		}
+
+		_ = writeJSONError(errOut, tr, err)
+
+		// Keep stderr machine-readable when it carries the JSON error.
+		if cli.JSONErrors == "stderr" {
+			return err
+		}
 	}
 
 	if u := ui.FromContext(ctx); u != nil {
-		msg := strings.TrimSpace(errfmt.Format(err))
+		msg := strings.TrimSpace(errfmt.FormatIn(tr, err))
 		if msg != "" {
 			u.Err().Error(msg)
+
+			if s := errfmt.SuggestionIn(tr, err); s != nil {
+				u.Err().Println(s.StringIn(tr))
+			}
 		}
 
 		return err
 	}
 
-	msg := strings.TrimSpace(errfmt.Format(err))
+	msg := strings.TrimSpace(errfmt.FormatIn(tr, err))
 	if msg != "" {
-		_, _ = fmt.Fprintln(os.Stderr, msg)
+		_, _ = fmt.Fprintln(stderr, msg)
+
+		if s := errfmt.SuggestionIn(tr, err); s != nil {
+			_, _ = fmt.Fprintln(stderr, s.StringIn(tr))
+		}
 	}
 
 	return err
@@ -212,10 +548,12 @@ func newUsageError(err error) error {
 	return &ExitErr{Code: ExitUsage, Err: err}
 }
 
-func newParser(description string) (*kong.Kong, *CLI, error) {
+// newParser builds the CLI parser; help is printed in the language of tr.
+func newParser(description string, tr *i18n.Translator, stdout, stderr io.Writer) (*kong.Kong, *CLI, error) {
 	envMode := outfmt.FromEnv()
 	vars := kong.Vars{
 		"color":            envOr("NUBE_COLOR", colorAuto),
+		"daemon_socket":    defaultDaemonSocket(),
 		"enabled_commands": envOr("NUBE_ENABLE_COMMANDS", ""),
 		"json":             boolString(envMode.JSON),
 		"plain":            boolString(envMode.Plain),
@@ -228,8 +566,10 @@ func newParser(description string) (*kong.Kong, *CLI, error) {
 		kong.Name("nube"),
 		kong.Description(description),
 		kong.Vars(vars),
-		kong.Writers(os.Stdout, os.Stderr),
+		kong.Writers(stdout, stderr),
+		kong.Help(newHelpPrinter(tr)),
 		kong.Exit(func(code int) { panic(exitPanic{code: code}) }),
+		kong.Resolvers(profileDefaults(&cli.RootFlags)),
 	)
 	if err != nil {
 		return nil, nil, err
@@ -242,7 +582,7 @@ func baseDescription() string {
 	return "Tienda Nube CLI for managing stores, products, orders, and more"
 }
 
-func helpDescription() string {
+func helpDescription(tr *i18n.Translator) string {
 	desc := baseDescription()
 
 	credPath, err := credstore.Path()
@@ -254,5 +594,5 @@ func helpDescription() string {
 		credLine = credPath
 	}
 
-	return fmt.Sprintf("%s\n\nCredentials: %s", desc, credLine)
+	return tr.T(desc) + "\n\n" + tr.Sprintf("Credentials: %s", credLine)
 }
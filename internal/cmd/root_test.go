@@ -1,7 +1,16 @@
 package cmd
 
 import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/credstore"
 )
 
 func TestExecute_Help(t *testing.T) {
@@ -57,3 +66,111 @@ func TestExecute_JSONPlainConflict(t *testing.T) {
 		t.Fatal("expected error for --json --plain conflict")
 	}
 }
+
+func TestExecute_TotalDeadline(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+
+	stderr := captureStderr(t)
+
+	err := Execute([]string{"store", "get", "--total-deadline", "50ms"})
+	if ExitCode(err) != ExitRetryable {
+		t.Fatalf("exit code = %d, want %d (err = %v)", ExitCode(err), ExitRetryable, err)
+	}
+
+	if !strings.Contains(stderr.String(), "total deadline of 50ms exceeded") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
+
+func TestClientOptions(t *testing.T) {
+	setupConfigDir(t)
+
+	if got, err := clientOptions(&RootFlags{}, ""); err != nil || len(got) != 0 {
+		t.Errorf("clientOptions(zero) = %d options, %v; want 0", len(got), err)
+	}
+
+	if got, err := clientOptions(&RootFlags{Timeout: time.Second}, ""); err != nil || len(got) != 1 {
+		t.Errorf("clientOptions(timeout) = %d options, %v; want 1", len(got), err)
+	}
+
+	if err := config.WriteConfig(config.File{HTTP: &config.HTTP{MaxIdleConnsPerHost: 32}}); err != nil {
+		t.Fatalf("WriteConfig: %v", err)
+	}
+
+	if got, err := clientOptions(&RootFlags{}, ""); err != nil || len(got) != 1 {
+		t.Errorf("clientOptions(http config) = %d options, %v; want 1", len(got), err)
+	}
+}
+
+func TestConfigTransportOptions(t *testing.T) {
+	setupConfigDir(t)
+
+	if err := config.WriteConfig(config.File{HTTP: &config.HTTP{
+		MaxIdleConnsPerHost: 8, IdleConnTimeout: "2m", ForceHTTP2: true, DisableKeepAlives: true,
+	}}); err != nil {
+		t.Fatalf("WriteConfig: %v", err)
+	}
+
+	got, err := configTransportOptions()
+	want := api.TransportOptions{MaxIdleConnsPerHost: 8, IdleConnTimeout: 2 * time.Minute, ForceHTTP2: true, DisableKeepAlives: true}
+
+	if err != nil || got != want {
+		t.Errorf("configTransportOptions() = %+v, %v; want %+v", got, err, want)
+	}
+
+	for _, bad := range []config.HTTP{{IdleConnTimeout: "soon"}, {MaxIdleConnsPerHost: -1}} {
+		if err := config.WriteConfig(config.File{HTTP: &bad}); err != nil {
+			t.Fatalf("WriteConfig: %v", err)
+		}
+
+		if _, err := configTransportOptions(); ExitCode(err) != ExitConfig {
+			t.Errorf("configTransportOptions(%+v) error = %v, want a config error", bad, err)
+		}
+	}
+}
+
+func TestExecute_MockDirWithoutProfile(t *testing.T) {
+	setupConfigDir(t)
+	t.Setenv("NUBE_ACCESS_TOKEN", "")
+
+	dir := t.TempDir()
+	fixture := filepath.Join(dir, "GET", "store.json")
+
+	if err := os.MkdirAll(filepath.Dir(fixture), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(fixture, []byte(`{"status":200,"body":{"id":1,"name":{"es":"Demo"}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"store", "get", "--json", "--mock-dir", dir}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if out := buf.String(); !strings.Contains(out, `"Demo"`) {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestExecute_MockDirAndRecordConflict(t *testing.T) {
+	setupConfigDir(t)
+	errBuf := captureStderr(t)
+
+	err := Execute([]string{"store", "get", "--mock-dir", t.TempDir(), "--record", t.TempDir()})
+	if ExitCode(err) != ExitUsage {
+		t.Errorf("exit code = %d, want %d", ExitCode(err), ExitUsage)
+	}
+
+	if !strings.Contains(errBuf.String(), "can't be combined") {
+		t.Errorf("stderr = %q, want the conflict explained", errBuf.String())
+	}
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/lockfile"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// lockNamePattern keeps lock names usable as file names.
+var lockNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// RunScheduledCmd wraps a command for cron: it holds a named lock so runs
+// never overlap, appends a summary of every run to a log file, and can
+// report failures to a webhook.
+type RunScheduledCmd struct {
+	LockName    string        `help:"Lock name; runs sharing a name never overlap" name:"lock-name" required:""`
+	Command     string        `help:"Command line to run, e.g. \"snapshot diff base.json --exit-code\"" name:"command" required:""`
+	SummaryFile string        `help:"JSON-lines file to append run summaries to (default: scheduled.jsonl in the data dir)" name:"summary-file" type:"path"`
+	StaleAfter  time.Duration `help:"Ignored: a crashed run's lock is released when it exits" name:"stale-after" hidden:""`
+	NotifyURL   string        `help:"Webhook URL to POST the summary to when the run fails (Slack-compatible)" name:"notify-url" env:"NUBE_SCHEDULED_NOTIFY_URL"`
+}
+
+// scheduledRun is the summary logged for each run.
+type scheduledRun struct {
+	Time       time.Time `json:"time"`
+	Lock       string    `json:"lock"`
+	Args       []string  `json:"args"`
+	Status     string    `json:"status"`
+	ExitCode   int       `json:"exit_code"`
+	ExitName   string    `json:"exit_name"`
+	DurationMS int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Scheduled run statuses.
+const (
+	scheduledOK      = "ok"
+	scheduledFailed  = "failed"
+	scheduledSkipped = "skipped"
+)
+
+func (c *RunScheduledCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	if !lockNamePattern.MatchString(c.LockName) {
+		return usagef("--lock-name %q: use letters, digits, '.', '_' and '-'", c.LockName)
+	}
+
+	args, err := splitCommandLine(c.Command)
+	if err != nil {
+		return newUsageError(err)
+	}
+
+	if len(args) == 0 {
+		return usagef("--command is empty")
+	}
+
+	if args[0] == "run-scheduled" {
+		return usagef("run-scheduled cannot run itself")
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return err
+	}
+
+	logPath := c.SummaryFile
+	if logPath == "" {
+		logPath = filepath.Join(dataDir, "scheduled.jsonl")
+	}
+
+	run := scheduledRun{Time: time.Now().UTC(), Lock: c.LockName, Args: args}
+
+	lock, err := lockfile.Acquire(filepath.Join(dataDir, "locks", c.LockName+".lock"))
+	if err != nil {
+		var locked *lockfile.LockedError
+		if !errors.As(err, &locked) {
+			return err
+		}
+
+		run.Status, run.ExitCode, run.Error = scheduledSkipped, ExitRetryable, err.Error()
+		run.ExitName = exitCodeName(run.ExitCode)
+		c.finish(ctx, u, flags, logPath, run)
+
+		return &ExitErr{Code: ExitRetryable, Err: err}
+	}
+
+	defer func() { _ = lock.Release() }()
+
+	// Tee stderr so the summary can carry the command's error message.
+	var errBuf bytes.Buffer
+
+	start := time.Now()
+	runErr := execute(withNested(ctx), subcommandArgs(flags, args), stdoutFrom(ctx), io.MultiWriter(stderrFrom(ctx), &errBuf))
+
+	run.DurationMS = time.Since(start).Milliseconds()
+	run.ExitCode = ExitCode(runErr)
+	run.ExitName = exitCodeName(run.ExitCode)
+	run.Status = scheduledOK
+
+	if runErr != nil {
+		run.Status = scheduledFailed
+		run.Error = lastLine(errBuf.String())
+
+		if run.Error == "" {
+			run.Error = runErr.Error()
+		}
+	}
+
+	c.finish(ctx, u, flags, logPath, run)
+
+	if runErr != nil {
+		// The command already reported its error.
+		return &ExitErr{Code: run.ExitCode}
+	}
+
+	return nil
+}
+
+// finish logs the run and sends the failure notification. Neither may change
+// the run's exit code, so problems are only reported on stderr.
+func (c *RunScheduledCmd) finish(ctx context.Context, u *ui.UI, flags *RootFlags, logPath string, run scheduledRun) {
+	if err := appendScheduledRun(logPath, run); err != nil && u != nil {
+		u.Err().Printf("run-scheduled: %v", err)
+	}
+
+	if run.Status == scheduledOK || c.NotifyURL == "" {
+		return
+	}
+
+	payload := map[string]any{
+		"text": fmt.Sprintf("nube %s (lock %s) %s with exit %d (%s) after %s: %s",
+			strings.Join(run.Args, " "), run.Lock, run.Status, run.ExitCode, run.ExitName,
+			time.Duration(run.DurationMS)*time.Millisecond, run.Error),
+		"run": run,
+	}
+
+	if err := postNotification(ctx, flags.Timeout, c.NotifyURL, payload); err != nil && u != nil {
+		u.Err().Printf("run-scheduled: %v", err)
+	}
+}
+
+func appendScheduledRun(path string, run scheduledRun) error {
+	b, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("encode run summary: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create log dir: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // user-provided path
+	if err != nil {
+		return fmt.Errorf("open run log: %w", err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("write run log: %w", err)
+	}
+
+	return nil
+}
+
+// lastLine returns the last non-empty line of s.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/lockfile"
+)
+
+func readScheduledRuns(t *testing.T, path string) []scheduledRun {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var runs []scheduledRun
+
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		var r scheduledRun
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("parse %q: %v", line, err)
+		}
+
+		runs = append(runs, r)
+	}
+
+	return runs
+}
+
+func TestRunScheduled(t *testing.T) {
+	setupConfigDir(t)
+	t.Setenv("NUBE_ACCESS_TOKEN", "")
+
+	var notified map[string]any
+
+	hook := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&notified)
+	}))
+	t.Cleanup(hook.Close)
+
+	logPath := filepath.Join(t.TempDir(), "runs.jsonl")
+	_ = captureStdout(t)
+	_ = captureStderr(t)
+
+	if err := Execute([]string{"run-scheduled", "--lock-name", "sync", "--summary-file", logPath, "--notify-url", hook.URL, "--command", "version"}); err != nil {
+		t.Fatalf("ok run: %v", err)
+	}
+
+	if notified != nil {
+		t.Errorf("notified on success: %v", notified)
+	}
+
+	// No store profile: the command fails with the config exit code.
+	err := Execute([]string{"run-scheduled", "--lock-name", "sync", "--summary-file", logPath, "--notify-url", hook.URL, "--command", "store get"})
+	if ExitCode(err) != ExitConfig {
+		t.Fatalf("failing run: ExitCode = %d, want %d (err %v)", ExitCode(err), ExitConfig, err)
+	}
+
+	runs := readScheduledRuns(t, logPath)
+	if len(runs) != 2 {
+		t.Fatalf("logged %d runs, want 2", len(runs))
+	}
+
+	if runs[0].Status != scheduledOK || runs[0].ExitCode != ExitOK {
+		t.Errorf("first run = %+v", runs[0])
+	}
+
+	if runs[1].Status != scheduledFailed || runs[1].ExitName != "config" || runs[1].Error == "" {
+		t.Errorf("second run = %+v", runs[1])
+	}
+
+	if text, _ := notified["text"].(string); !strings.Contains(text, "store get") || !strings.Contains(text, "failed") {
+		t.Errorf("notification = %v", notified)
+	}
+
+	dataDir, _ := config.DataDir()
+
+	lock, err := lockfile.Acquire(filepath.Join(dataDir, "locks", "sync.lock"))
+	if err != nil {
+		t.Fatalf("lock not released: %v", err)
+	}
+
+	_ = lock.Release()
+}
+
+func TestRunScheduled_LockHeld(t *testing.T) {
+	setupConfigDir(t)
+
+	dataDir, _ := config.DataDir()
+
+	lock, err := lockfile.Acquire(filepath.Join(dataDir, "locks", "sync.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = lock.Release() })
+
+	_ = captureStdout(t)
+
+	err = Execute([]string{"run-scheduled", "--lock-name", "sync", "--command", "version"})
+	if ExitCode(err) != ExitRetryable {
+		t.Fatalf("ExitCode = %d, want %d (err %v)", ExitCode(err), ExitRetryable, err)
+	}
+
+	runs := readScheduledRuns(t, filepath.Join(dataDir, "scheduled.jsonl"))
+	if len(runs) != 1 || runs[0].Status != scheduledSkipped || !strings.Contains(runs[0].Error, fmt.Sprintf("pid %d", os.Getpid())) {
+		t.Errorf("runs = %+v", runs)
+	}
+}
+
+func TestRunScheduled_Usage(t *testing.T) {
+	setupConfigDir(t)
+
+	for _, args := range [][]string{
+		{"run-scheduled", "--lock-name", "../x", "--command", "version"},
+		{"run-scheduled", "--lock-name", "x", "--command", "  "},
+		{"run-scheduled", "--lock-name", "x", "--command", "run-scheduled --lock-name y --command version"},
+	} {
+		if err := Execute(args); ExitCode(err) != ExitUsage {
+			t.Errorf("%v: ExitCode = %d, want %d (err %v)", args, ExitCode(err), ExitUsage, err)
+		}
+	}
+}
+
+func TestRunScheduled_RefusesLongRunningCommand(t *testing.T) {
+	setupConfigDir(t)
+
+	_ = captureStdout(t)
+	_ = captureStderr(t)
+
+	err := Execute([]string{"run-scheduled", "--lock-name", "proxy", "--summary-file", filepath.Join(t.TempDir(), "runs.jsonl"), "--command=--json proxy --listen 127.0.0.1:0"})
+	if ExitCode(err) != ExitUsage {
+		t.Fatalf("ExitCode = %d, want %d (err %v)", ExitCode(err), ExitUsage, err)
+	}
+}
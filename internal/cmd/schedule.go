@@ -0,0 +1,448 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/lockfile"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// ScheduleCmd keeps a local list of commands to run at a set time. Nothing
+// runs by itself: 'nube schedule run' is meant to be called from cron every
+// few minutes and runs the jobs that are due.
+type ScheduleCmd struct {
+	Add    ScheduleAddCmd    `cmd:"" help:"Schedule a command to run at a set time"`
+	List   ScheduleListCmd   `cmd:"" help:"List scheduled jobs"`
+	Remove ScheduleRemoveCmd `cmd:"" help:"Remove a scheduled job"`
+	Run    ScheduleRunCmd    `cmd:"" help:"Run the jobs that are due (call from cron)"`
+}
+
+// scheduledJob is one entry of the schedule manifest.
+type scheduledJob struct {
+	ID         string    `json:"id"`
+	At         time.Time `json:"at"`
+	Args       []string  `json:"args"`
+	Store      string    `json:"store,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	Status     string    `json:"status"`
+	StartedAt  time.Time `json:"started_at,omitzero"`
+	FinishedAt time.Time `json:"finished_at,omitzero"`
+	ExitCode   int       `json:"exit_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Job statuses. A job is marked running before it starts, so a run that
+// crashes halfway leaves it running instead of repeating it.
+const (
+	jobPending = "pending"
+	jobRunning = "running"
+	jobDone    = "done"
+	jobFailed  = "failed"
+)
+
+type scheduleManifest struct {
+	Jobs []scheduledJob `json:"jobs"`
+}
+
+func schedulePath() (string, error) {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dataDir, "schedule.json"), nil
+}
+
+func readSchedule() (scheduleManifest, error) {
+	var m scheduleManifest
+
+	path, err := schedulePath()
+	if err != nil {
+		return m, err
+	}
+
+	b, err := os.ReadFile(path) //nolint:gosec // path under the data dir
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+
+	if err != nil {
+		return m, fmt.Errorf("read schedule: %w", err)
+	}
+
+	if err := json.Unmarshal(b, &m); err != nil {
+		return m, &ExitErr{Code: ExitConfig, Err: fmt.Errorf("parse %s: %w", path, err)}
+	}
+
+	return m, nil
+}
+
+// writeSchedule replaces the manifest atomically, so a crash never leaves a
+// half-written file that would lose the record of what already ran.
+func writeSchedule(m scheduleManifest) error {
+	path, err := schedulePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create data dir: %w", err)
+	}
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode schedule: %w", err)
+	}
+
+	tmp := path + ".tmp"
+
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write schedule: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("commit schedule: %w", err)
+	}
+
+	return nil
+}
+
+// parseScheduleTime parses --at: RFC 3339, or a date with an optional
+// minute or second in local time.
+func parseScheduleTime(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range []string{"2006-01-02T15:04", "2006-01-02T15:04:05", "2006-01-02 15:04", time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, usagef("--at %q: want a time such as 2025-12-01T00:00 (local) or 2025-12-01T00:00:00-03:00", s)
+}
+
+// --- Add ---
+
+type ScheduleAddCmd struct {
+	At      string `help:"When to run: 2025-12-01T00:00 in local time, or RFC 3339 with an offset" name:"at" required:""`
+	Command string `help:"Command line to run, e.g. \"product price adjust --filter category-id=10 --percent -15 --force\"" name:"command" required:""`
+}
+
+func (c *ScheduleAddCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	at, err := parseScheduleTime(c.At, time.Local)
+	if err != nil {
+		return err
+	}
+
+	if at.Before(time.Now()) {
+		return usagef("--at %s is in the past", at.Format(time.RFC3339))
+	}
+
+	args, err := splitCommandLine(c.Command)
+	if err != nil {
+		return newUsageError(err)
+	}
+
+	if len(args) == 0 {
+		return usagef("--command is empty")
+	}
+
+	if args[0] == "schedule" || args[0] == "run-scheduled" {
+		return usagef("%s cannot be scheduled", args[0])
+	}
+
+	m, err := readSchedule()
+	if err != nil {
+		return err
+	}
+
+	job := scheduledJob{
+		ID:        nextJobID(m.Jobs),
+		At:        at.UTC(),
+		Args:      args,
+		Store:     flags.Store,
+		CreatedAt: time.Now().UTC(),
+		Status:    jobPending,
+	}
+
+	if flags.DryRun {
+		return writeResult(ctx, u,
+			kv("dry_run", true),
+			kv("at", job.At.Format(time.RFC3339)),
+			kv("command", strings.Join(args, " ")),
+		)
+	}
+
+	m.Jobs = append(m.Jobs, job)
+
+	if err := writeSchedule(m); err != nil {
+		return err
+	}
+
+	return writeResult(ctx, u,
+		kv("id", job.ID),
+		kv("at", job.At.Local().Format(time.RFC3339)),
+		kv("command", strings.Join(args, " ")),
+	)
+}
+
+func nextJobID(jobs []scheduledJob) string {
+	highest := 0
+
+	for _, j := range jobs {
+		if n, err := strconv.Atoi(j.ID); err == nil && n > highest {
+			highest = n
+		}
+	}
+
+	return strconv.Itoa(highest + 1)
+}
+
+// --- List ---
+
+type ScheduleListCmd struct {
+	All bool `help:"Include jobs that already ran" name:"all"`
+}
+
+func (c *ScheduleListCmd) Run(ctx context.Context, _ *RootFlags) error {
+	m, err := readSchedule()
+	if err != nil {
+		return err
+	}
+
+	jobs := m.Jobs
+	if !c.All {
+		jobs = slices.DeleteFunc(slices.Clone(jobs), func(j scheduledJob) bool {
+			return j.Status == jobDone || j.Status == jobFailed
+		})
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if jobs == nil {
+			jobs = []scheduledJob{}
+		}
+
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), jobs)
+	}
+
+	w, done := tableWriter(ctx)
+	defer done()
+
+	_, _ = fmt.Fprintln(w, "ID\tAT\tSTATUS\tSTORE\tCOMMAND")
+
+	for _, j := range jobs {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", j.ID, j.At.Local().Format(time.RFC3339), j.Status, j.Store, strings.Join(j.Args, " "))
+	}
+
+	return nil
+}
+
+// --- Remove ---
+
+type ScheduleRemoveCmd struct {
+	ID string `arg:"" name:"id" help:"Job ID"`
+}
+
+func (c *ScheduleRemoveCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	m, err := readSchedule()
+	if err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(m.Jobs, func(j scheduledJob) bool { return j.ID == c.ID })
+	if i < 0 {
+		return &ExitErr{Code: ExitNotFound, Err: fmt.Errorf("no scheduled job %s", c.ID)}
+	}
+
+	if flags.DryRun {
+		return writeResult(ctx, u, kv("dry_run", true), kv("remove", c.ID))
+	}
+
+	m.Jobs = slices.Delete(m.Jobs, i, i+1)
+
+	if err := writeSchedule(m); err != nil {
+		return err
+	}
+
+	return writeResult(ctx, u, kv("removed", c.ID))
+}
+
+// --- Run ---
+
+type ScheduleRunCmd struct {
+	SummaryFile string        `help:"JSON-lines file to append run summaries to (default: scheduled.jsonl in the data dir)" name:"summary-file" type:"path"`
+	StaleAfter  time.Duration `help:"Ignored: a crashed run's lock is released when it exits" name:"stale-after" hidden:""`
+}
+
+func (c *ScheduleRunCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return err
+	}
+
+	logPath := c.SummaryFile
+	if logPath == "" {
+		logPath = filepath.Join(dataDir, "scheduled.jsonl")
+	}
+
+	// The lock keeps overlapping cron runs from picking up the same job
+	// twice; a run that finds it held exits and leaves the work to the
+	// holder.
+	lock, err := lockfile.Acquire(filepath.Join(dataDir, "locks", "schedule.lock"))
+	if err != nil {
+		var locked *lockfile.LockedError
+		if errors.As(err, &locked) {
+			return &ExitErr{Code: ExitRetryable, Err: err}
+		}
+
+		return err
+	}
+
+	defer func() { _ = lock.Release() }()
+
+	m, err := readSchedule()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	var ran []scheduledJob
+
+	for _, job := range m.Jobs {
+		if job.Status != jobPending || job.At.After(now) {
+			continue
+		}
+
+		if flags.DryRun {
+			ran = append(ran, job)
+			continue
+		}
+
+		job.Status = jobRunning
+		job.StartedAt = time.Now().UTC()
+
+		if err := saveJob(job); err != nil {
+			return err
+		}
+
+		runJob(ctx, flags, &job)
+
+		if err := saveJob(job); err != nil {
+			return err
+		}
+
+		run := scheduledRun{
+			Time:       job.StartedAt,
+			Lock:       "schedule/" + job.ID,
+			Args:       job.Args,
+			Status:     scheduledOK,
+			ExitCode:   job.ExitCode,
+			ExitName:   exitCodeName(job.ExitCode),
+			DurationMS: job.FinishedAt.Sub(job.StartedAt).Milliseconds(),
+			Error:      job.Error,
+		}
+		if job.Status == jobFailed {
+			run.Status = scheduledFailed
+		}
+
+		if err := appendScheduledRun(logPath, run); err != nil {
+			u.Err().Printf("schedule: %v", err)
+		}
+
+		ran = append(ran, job)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if ran == nil {
+			ran = []scheduledJob{}
+		}
+
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"dry_run": flags.DryRun, "jobs": ran})
+	}
+
+	failed, code := 0, ExitOK
+
+	for _, j := range ran {
+		if j.Status == jobFailed {
+			if failed == 0 {
+				code = j.ExitCode
+			}
+
+			failed++
+		}
+
+		u.Err().Printf("job %s (%s): %s", j.ID, strings.Join(j.Args, " "), j.Status)
+	}
+
+	if failed > 0 {
+		return &ExitErr{Code: code, Err: fmt.Errorf("%d of %d scheduled jobs failed", failed, len(ran))}
+	}
+
+	return nil
+}
+
+// saveJob writes job's state back to the manifest, re-reading it first so
+// jobs added or removed while another job ran are kept.
+func saveJob(job scheduledJob) error {
+	m, err := readSchedule()
+	if err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(m.Jobs, func(j scheduledJob) bool { return j.ID == job.ID })
+	if i < 0 {
+		m.Jobs = append(m.Jobs, job)
+	} else {
+		m.Jobs[i] = job
+	}
+
+	return writeSchedule(m)
+}
+
+// runJob runs job in-process with the store it was scheduled for and
+// records the outcome on it.
+func runJob(ctx context.Context, flags *RootFlags, job *scheduledJob) {
+	jobFlags := *flags
+	if job.Store != "" {
+		jobFlags.Store = job.Store
+	}
+
+	var errBuf bytes.Buffer
+
+	err := execute(withNested(ctx), subcommandArgs(&jobFlags, job.Args), stdoutFrom(ctx), io.MultiWriter(stderrFrom(ctx), &errBuf))
+
+	job.FinishedAt = time.Now().UTC()
+	job.ExitCode = ExitCode(err)
+	job.Status = jobDone
+
+	if err != nil {
+		job.Status = jobFailed
+		job.Error = lastLine(errBuf.String())
+
+		if job.Error == "" {
+			job.Error = err.Error()
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/config"
+)
+
+func TestScheduleAdd(t *testing.T) {
+	setupConfigDir(t)
+
+	at := time.Now().Add(time.Hour).Format("2006-01-02T15:04")
+
+	_ = captureStdout(t)
+	if err := Execute([]string{"--store", "shop", "schedule", "add", "--at", at, "--command", `product price adjust --filter "q=remera roja" --percent -15 --force`}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	m, err := readSchedule()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(m.Jobs) != 1 {
+		t.Fatalf("jobs = %+v", m.Jobs)
+	}
+
+	job := m.Jobs[0]
+	if job.ID != "1" || job.Status != jobPending || job.Store != "shop" || len(job.Args) != 8 || job.Args[4] != "q=remera roja" {
+		t.Errorf("job = %+v", job)
+	}
+}
+
+func TestScheduleAdd_Usage(t *testing.T) {
+	setupConfigDir(t)
+
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+
+	for _, args := range [][]string{
+		{"--at", "2020-01-01T00:00", "--command", "version"},
+		{"--at", "tomorrow", "--command", "version"},
+		{"--at", future, "--command", "schedule run"},
+	} {
+		if err := Execute(append([]string{"schedule", "add"}, args...)); ExitCode(err) != ExitUsage {
+			t.Errorf("%v: ExitCode = %d, want %d (err %v)", args, ExitCode(err), ExitUsage, err)
+		}
+	}
+}
+
+func TestScheduleRun_ExactlyOnce(t *testing.T) {
+	setupConfigDir(t)
+	t.Setenv("NUBE_ACCESS_TOKEN", "")
+
+	now := time.Now().UTC()
+	if err := writeSchedule(scheduleManifest{Jobs: []scheduledJob{
+		{ID: "1", At: now.Add(-time.Minute), Args: []string{"version"}, Status: jobPending},
+		{ID: "2", At: now.Add(-time.Minute), Args: []string{"store", "get"}, Status: jobPending},
+		{ID: "3", At: now.Add(time.Hour), Args: []string{"version"}, Status: jobPending},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = captureStdout(t)
+	_ = captureStderr(t)
+
+	// Job 2 has no store profile to run against and fails.
+	if err := Execute([]string{"schedule", "run"}); ExitCode(err) != ExitConfig {
+		t.Fatalf("ExitCode = %d, want %d (err %v)", ExitCode(err), ExitConfig, err)
+	}
+
+	if err := Execute([]string{"schedule", "run"}); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+
+	m, err := readSchedule()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{jobDone, jobFailed, jobPending}
+	for i, job := range m.Jobs {
+		if job.Status != want[i] {
+			t.Errorf("job %s status = %s, want %s", job.ID, job.Status, want[i])
+		}
+	}
+
+	dataDir, _ := config.DataDir()
+	if runs := readScheduledRuns(t, filepath.Join(dataDir, "scheduled.jsonl")); len(runs) != 2 {
+		t.Errorf("logged %d runs, want 2: %+v", len(runs), runs)
+	}
+}
@@ -3,7 +3,7 @@ package cmd
 import (
 	"context"
 	"encoding/json"
-	"os"
+	"io"
 
 	"github.com/alecthomas/kong"
 
@@ -11,24 +11,31 @@ import (
 	"github.com/gberlati/nube-cli/internal/ui"
 )
 
-// SchemaCmd emits a machine-readable schema of all commands and flags.
-type SchemaCmd struct{}
+// SchemaCmd groups the machine-readable descriptions of the CLI.
+type SchemaCmd struct {
+	Commands  SchemaCommandsCmd `cmd:"" default:"1" help:"Print all commands and flags, with exit codes and required scopes"`
+	ExitCodes AgentExitCodesCmd `cmd:"" name:"exit-codes" help:"Print stable exit code map"`
+}
+
+// SchemaCommandsCmd emits a machine-readable schema of all commands and flags.
+type SchemaCommandsCmd struct{}
 
-func (c *SchemaCmd) Run(ctx context.Context) error {
-	parser, _, err := newParser(baseDescription())
+func (c *SchemaCommandsCmd) Run(ctx context.Context) error {
+	parser, _, err := newParser(baseDescription(), nil, io.Discard, io.Discard)
 	if err != nil {
 		return err
 	}
 
 	schema := buildSchema(parser.Model.Node)
+	schema["exit_codes"] = exitCodeMap
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, schema)
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), schema)
 	}
 
 	// Plain: just output compact JSON since it's machine-oriented.
 	u := ui.FromContext(ctx)
-	enc := json.NewEncoder(os.Stdout)
+	enc := json.NewEncoder(stdoutFrom(ctx))
 	enc.SetIndent("", "  ")
 	enc.SetEscapeHTML(false)
 
@@ -64,6 +71,23 @@ func buildSchema(node *kong.Node) map[string]any {
 
 	if children := schemaChildren(node); len(children) > 0 {
 		result["commands"] = children
+	} else if node.Type == kong.CommandNode {
+		path := schemaPath(node)
+		api, ok := commandAPI[path]
+
+		switch {
+		case !ok:
+		case api.Scopes == nil:
+			result["scopes_dynamic"] = true
+		default:
+			result["scopes"] = api.Scopes
+		}
+
+		result["exit_codes"] = commandExitCodes(path, ok)
+
+		if examples := commandExamples[path]; len(examples) > 0 {
+			result["examples"] = examples
+		}
 	}
 
 	return result
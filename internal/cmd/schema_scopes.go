@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/alecthomas/kong"
+)
+
+// apiUsage describes what a command needs from the API. Scopes lists the
+// OAuth scopes the app must have been granted; nil means they depend on the
+// input (the resources in a manifest, the path of a request, ...).
+type apiUsage struct {
+	Scopes []string
+}
+
+var (
+	readProducts   = apiUsage{Scopes: []string{"read_products"}}
+	readOrders     = apiUsage{Scopes: []string{"read_orders"}}
+	readCustomers  = apiUsage{Scopes: []string{"read_customers"}}
+	writeProducts  = apiUsage{Scopes: []string{"write_products"}}
+	writeOrders    = apiUsage{Scopes: []string{"write_orders"}}
+	writeCustomers = apiUsage{Scopes: []string{"write_customers"}}
+	noScopes       = apiUsage{Scopes: []string{}}
+	dynamicScopes  = apiUsage{}
+)
+
+// commandAPI maps the commands that call the store API, by command path, to
+// their API usage. Commands missing here run locally.
+var commandAPI = map[string]apiUsage{
+	"shop":                    noScopes,
+	"products":                readProducts,
+	"orders":                  readOrders,
+	"store get":               noScopes,
+	"store app-status":        noScopes,
+	"auth prune":              noScopes,
+	"product list":            readProducts,
+	"product get":             readProducts,
+	"product get-by-sku":      readProducts,
+	"product exists":          readProducts,
+	"product diff":            readProducts,
+	"product edit":            writeProducts,
+	"product stock-history":   readProducts,
+	"product price adjust":    writeProducts,
+	"product lint":            readProducts,
+	"product categorize":      writeProducts,
+	"product qr":              readProducts,
+	"order list":              readOrders,
+	"order get":               readOrders,
+	"order edit":              writeOrders,
+	"order items":             readOrders,
+	"order item update":       {Scopes: []string{"read_draft_orders", "write_draft_orders"}},
+	"order label":             {Scopes: []string{"read_fulfillment_orders"}},
+	"order refund":            {Scopes: []string{"read_orders", "write_orders"}},
+	"order note set":          writeOrders,
+	"order owner-note set":    writeOrders,
+	"order tag add":           {Scopes: []string{"read_orders", "write_orders"}},
+	"order tag remove":        {Scopes: []string{"read_orders", "write_orders"}},
+	"category list":           readProducts,
+	"category get":            readProducts,
+	"category diff":           readProducts,
+	"category edit":           writeProducts,
+	"category move":           writeProducts,
+	"category merge":          writeProducts,
+	"customer list":           readCustomers,
+	"customer get":            readCustomers,
+	"customer diff":           readCustomers,
+	"customer edit":           writeCustomers,
+	"customer address list":   readCustomers,
+	"customer address add":    writeCustomers,
+	"customer address update": writeCustomers,
+	"customer address delete": writeCustomers,
+	"customer data-export":    {Scopes: []string{"read_customers", "read_orders"}},
+	"customer anonymize":      {Scopes: []string{"read_customers", "write_customers", "read_orders"}},
+	"payment providers list":  {Scopes: []string{"read_payments"}},
+	"payment options list":    {Scopes: []string{"read_payments"}},
+	"seed":                    {Scopes: []string{"read_products", "write_products", "read_orders", "write_orders", "write_customers"}},
+	"notify orders":           readOrders,
+	"report sales":            readOrders,
+	"crosslist export":        readProducts,
+	"serve":                   dynamicScopes,
+	"proxy":                   dynamicScopes,
+	"batch run":               dynamicScopes,
+	"assert":                  dynamicScopes,
+	"bench":                   dynamicScopes,
+	"journal retry":           dynamicScopes,
+	"undo":                    dynamicScopes,
+	"apply":                   dynamicScopes,
+	"snapshot create":         dynamicScopes,
+	"snapshot diff":           dynamicScopes,
+	"cache refresh":           dynamicScopes,
+	"export":                  dynamicScopes,
+	"sync mirror":             dynamicScopes,
+	"sync stock":              {Scopes: []string{"read_products", "write_products"}},
+	"graphql query":           dynamicScopes,
+	"api":                     dynamicScopes,
+	"webhook replay":          dynamicScopes,
+	"dev record":              dynamicScopes,
+	"run-scheduled":           dynamicScopes,
+	"schedule run":            dynamicScopes,
+	"search":                  dynamicScopes,
+	"wait":                    dynamicScopes,
+	"partner apps":            noScopes,
+	"partner stores":          noScopes,
+	"partner metrics":         noScopes,
+}
+
+// baseExitCodes can come from any command: besides success, generic errors
+// and bad usage, a policy may deny it or fail to load.
+var baseExitCodes = []int{ExitOK, ExitError, ExitUsage, ExitPermissionDenied, ExitConfig}
+
+// apiExitCodes can come from any command that calls the API.
+var apiExitCodes = []int{
+	ExitAuthRequired, ExitNotFound, ExitRateLimited, ExitRetryable,
+	ExitPaymentRequired, ExitValidation, ExitMismatch,
+}
+
+// commandOwnExitCodes lists the exit codes particular to a command.
+var commandOwnExitCodes = map[string][]int{
+	"logout":                  {ExitCancelled},
+	"auth prune":              {ExitCancelled},
+	"order refund":            {ExitCancelled},
+	"category merge":          {ExitCancelled},
+	"product price adjust":    {ExitCancelled},
+	"product categorize":      {ExitCancelled},
+	"customer address delete": {ExitCancelled},
+	"customer anonymize":      {ExitCancelled},
+	"undo":                    {ExitCancelled},
+	"apply":                   {ExitCancelled},
+	"seed":                    {ExitCancelled},
+	"webhook verify":          {ExitMismatch},
+	"wait":                    {ExitTimeout},
+	"partner logout":          {ExitCancelled},
+}
+
+// commandExitCodes returns the sorted exit codes a command can return.
+func commandExitCodes(path string, usesAPI bool) []int {
+	codes := slices.Clone(baseExitCodes)
+
+	if usesAPI {
+		codes = append(codes, apiExitCodes...)
+	}
+
+	codes = append(codes, commandOwnExitCodes[path]...)
+	slices.Sort(codes)
+
+	return slices.Compact(codes)
+}
+
+// schemaPath returns the command words leading to node: "product get".
+func schemaPath(node *kong.Node) string {
+	var words []string
+
+	for n := node; n != nil; n = n.Parent {
+		if n.Type == kong.CommandNode {
+			words = append(words, n.Name)
+		}
+	}
+
+	slices.Reverse(words)
+
+	return strings.Join(words, " ")
+}
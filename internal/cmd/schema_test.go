@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+// findCommand returns the schema entry at path, e.g. ["product", "list"].
+func findCommand(t *testing.T, schema map[string]any, path ...string) map[string]any {
+	t.Helper()
+
+	node := schema
+
+	for _, name := range path {
+		var next map[string]any
+
+		children, _ := node["commands"].([]any)
+		for _, c := range children {
+			if m, _ := c.(map[string]any); m["name"] == name {
+				next = m
+			}
+		}
+
+		if next == nil {
+			t.Fatalf("command %v not in schema", path)
+		}
+
+		node = next
+	}
+
+	return node
+}
+
+func TestSchema_ExitCodesAndScopes(t *testing.T) {
+	buf := captureStdout(t)
+	if err := Execute([]string{"schema", "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &schema); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if codes, _ := schema["exit_codes"].([]any); len(codes) != len(exitCodeMap) {
+		t.Errorf("exit_codes = %v", schema["exit_codes"])
+	}
+
+	tests := []struct {
+		path       []string
+		scopes     any
+		dynamic    bool
+		wantCode   float64
+		unwantCode float64
+	}{
+		{[]string{"product", "list"}, []any{"read_products"}, false, ExitNotFound, ExitCancelled},
+		{[]string{"customer", "anonymize"}, []any{"read_customers", "write_customers", "read_orders"}, false, ExitCancelled, ExitMismatch + 1},
+		{[]string{"apply"}, nil, true, ExitValidation, ExitMismatch + 1},
+		{[]string{"webhook", "verify"}, nil, false, ExitMismatch, ExitNotFound},
+		{[]string{"config", "path"}, nil, false, ExitUsage, ExitNotFound},
+	}
+
+	for _, tt := range tests {
+		cmd := findCommand(t, schema, tt.path...)
+
+		if got, _ := json.Marshal(cmd["scopes"]); string(got) != mustJSON(t, tt.scopes) {
+			t.Errorf("%v scopes = %s, want %s", tt.path, got, mustJSON(t, tt.scopes))
+		}
+
+		if got := cmd["scopes_dynamic"] == true; got != tt.dynamic {
+			t.Errorf("%v scopes_dynamic = %v, want %v", tt.path, got, tt.dynamic)
+		}
+
+		codes, _ := cmd["exit_codes"].([]any)
+		if !slices.Contains(codes, any(tt.wantCode)) || slices.Contains(codes, any(tt.unwantCode)) {
+			t.Errorf("%v exit_codes = %v, want %v and not %v", tt.path, codes, tt.wantCode, tt.unwantCode)
+		}
+	}
+}
+
+func TestSchema_ExitCodesCommand(t *testing.T) {
+	buf := captureStdout(t)
+	if err := Execute([]string{"schema", "exit-codes", "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	var got struct {
+		ExitCodes []struct {
+			Code int    `json:"code"`
+			Name string `json:"name"`
+		} `json:"exit_codes"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if len(got.ExitCodes) != len(exitCodeMap) || got.ExitCodes[ExitMismatch].Name != "mismatch" {
+		t.Errorf("exit_codes = %+v", got.ExitCodes)
+	}
+}
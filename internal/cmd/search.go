@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// searchable describes how one resource is searched and summarized.
+type searchable struct {
+	path   string
+	name   func(map[string]any) string
+	detail func(map[string]any) string
+}
+
+var searchResources = map[string]searchable{
+	"products": {
+		path:   "products",
+		name:   func(p map[string]any) string { return extractI18n(p, "name") },
+		detail: func(p map[string]any) string { return firstVariantField(p, "sku") },
+	},
+	"orders": {
+		path: "orders",
+		name: func(o map[string]any) string { return "#" + jsonStr(o, "number") },
+		detail: func(o map[string]any) string {
+			customer, _ := o["customer"].(map[string]any)
+			return joinNonEmpty(" · ", jsonStr(customer, "name"), strings.TrimSpace(jsonStr(o, "total")+" "+jsonStr(o, "currency")))
+		},
+	},
+	"customers": {
+		path:   "customers",
+		name:   func(c map[string]any) string { return jsonStr(c, "name") },
+		detail: func(c map[string]any) string { return jsonStr(c, "email") },
+	},
+}
+
+var digitsPattern = regexp.MustCompile(`^\d+$`)
+
+// SearchCmd looks a term up in several resources at once.
+type SearchCmd struct {
+	Term      string   `arg:"" name:"term" help:"Text, email, SKU or ID to look for"`
+	Resources []string `help:"Resources to search" name:"resources" default:"products,orders,customers" sep:","`
+	Limit     int      `help:"Results per resource" name:"limit" default:"10"`
+}
+
+// searchHit is one result, in the shape shared by every resource.
+type searchHit struct {
+	Resource string         `json:"resource"`
+	ID       string         `json:"id"`
+	Name     string         `json:"name"`
+	Detail   string         `json:"detail,omitempty"`
+	Item     map[string]any `json:"item"`
+}
+
+func (c *SearchCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	term := strings.TrimSpace(c.Term)
+	if term == "" {
+		return usagef("search term is empty")
+	}
+
+	if c.Limit < 1 {
+		return usagef("--limit must be at least 1")
+	}
+
+	for _, r := range c.Resources {
+		if _, ok := searchResources[r]; !ok {
+			return usagef("cannot search %q (want products, orders or customers)", r)
+		}
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	results := make([][]searchHit, len(c.Resources))
+	jobs := make([]api.Job, len(c.Resources))
+
+	for i, r := range c.Resources {
+		jobs[i] = func(ctx context.Context) error {
+			hits, err := searchResource(ctx, client, r, term, c.Limit)
+			results[i] = hits
+
+			return err
+		}
+	}
+
+	errs := api.NewPool(api.WithWorkers(len(jobs))).Run(ctx, jobs)
+
+	grouped := make(map[string][]searchHit, len(c.Resources))
+	failures := map[string]string{}
+
+	var firstErr error
+
+	for i, r := range c.Resources {
+		if errs[i] != nil {
+			failures[r] = errs[i].Error()
+
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+
+			continue
+		}
+
+		grouped[r] = results[i]
+		if grouped[r] == nil {
+			grouped[r] = []searchHit{}
+		}
+	}
+
+	if len(failures) == len(c.Resources) {
+		return firstErr
+	}
+
+	if outfmt.IsJSON(ctx) {
+		out := map[string]any{"term": term, "results": grouped}
+		if len(failures) > 0 {
+			out["errors"] = failures
+		}
+
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), out)
+	}
+
+	total := 0
+
+	w, done := tableWriter(ctx)
+
+	_, _ = fmt.Fprintln(w, "RESOURCE\tID\tNAME\tDETAIL")
+
+	for _, r := range c.Resources {
+		for _, h := range grouped[r] {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", h.Resource, h.ID, h.Name, h.Detail)
+			total++
+		}
+	}
+
+	done()
+
+	for _, r := range c.Resources {
+		if msg, ok := failures[r]; ok {
+			u.Err().Printf("%s: %s", r, msg)
+		}
+	}
+
+	if total == 0 {
+		u.Err().Printf("no matches for %q", term)
+	}
+
+	return nil
+}
+
+// searchResource runs the resource's q search and, for numeric terms, an
+// ID lookup, returning the ID match first.
+func searchResource(ctx context.Context, client *api.Client, resource, term string, limit int) ([]searchHit, error) {
+	s := searchResources[resource]
+
+	var items []map[string]any
+
+	if digitsPattern.MatchString(term) {
+		resp, err := client.Get(ctx, s.path+"/"+term, nil) //nolint:bodyclose // DecodeResponse closes body
+		if err != nil && !api.IsNotFoundError(err) {
+			return nil, err
+		}
+
+		if err == nil {
+			item, err := api.DecodeResponse[map[string]any](resp)
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, item)
+		}
+	}
+
+	q := url.Values{"q": {term}, "per_page": {strconv.Itoa(limit)}}
+
+	resp, err := client.Get(ctx, s.path, q) //nolint:bodyclose // decodeList closes body
+	if err != nil && !api.IsNotFoundError(err) {
+		return nil, err
+	}
+
+	if err == nil {
+		found, err := decodeList(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range found {
+			id := jsonStr(item, "id")
+			if !slices.ContainsFunc(items, func(m map[string]any) bool { return jsonStr(m, "id") == id }) {
+				items = append(items, item)
+			}
+		}
+	}
+
+	hits := make([]searchHit, 0, len(items))
+
+	for _, item := range items[:min(len(items), limit)] {
+		hits = append(hits, searchHit{
+			Resource: resource,
+			ID:       jsonStr(item, "id"),
+			Name:     s.name(item),
+			Detail:   s.detail(item),
+			Item:     item,
+		})
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/123/orders/500":
+			_, _ = w.Write([]byte(`{"id":500,"number":77,"total":"10.00","currency":"ARS","customer":{"name":"Ana"}}`))
+		case "/v1/123/orders":
+			// The same order also matches the text search.
+			_, _ = w.Write([]byte(`[{"id":500,"number":77}]`))
+		case "/v1/123/products":
+			if r.URL.Query().Get("q") != "500" || r.URL.Query().Get("per_page") != "5" {
+				t.Errorf("product query = %s", r.URL.RawQuery)
+			}
+
+			_, _ = w.Write([]byte(`[{"id":1,"name":{"es":"Remera 500"},"variants":[{"sku":"R500"}]}]`))
+		case "/v1/123/customers":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	out := captureStdout(t)
+	errOut := captureStderr(t)
+
+	if err := Execute([]string{"--json", "search", "500", "--limit", "5"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	var got struct {
+		Results map[string][]searchHit `json:"results"`
+		Errors  map[string]string      `json:"errors"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if orders := got.Results["orders"]; len(orders) != 1 || orders[0].Name != "#77" || orders[0].Detail != "Ana · 10.00 ARS" {
+		t.Errorf("orders = %+v", orders)
+	}
+
+	if products := got.Results["products"]; len(products) != 1 || products[0].Detail != "R500" {
+		t.Errorf("products = %+v", products)
+	}
+
+	if _, ok := got.Errors["customers"]; !ok || strings.TrimSpace(errOut.String()) != "" {
+		t.Errorf("errors = %v", got.Errors)
+	}
+}
+
+func TestSearch_UnknownResource(t *testing.T) {
+	setupConfigDir(t)
+
+	if err := Execute([]string{"search", "x", "--resources", "coupons"}); ExitCode(err) != ExitUsage {
+		t.Errorf("ExitCode = %d, want %d", ExitCode(err), ExitUsage)
+	}
+}
@@ -0,0 +1,369 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// seedMarker tags everything seed creates (product tag, order owner note),
+// so --wipe only ever touches seeded data.
+const seedMarker = "nube-seed"
+
+// SeedCmd populates a test store with fake products and orders.
+type SeedCmd struct {
+	Products     int    `help:"Number of products to create" name:"products"`
+	Orders       int    `help:"Number of orders to create, buying seeded products" name:"orders"`
+	FakerLocale  string `help:"Locale of the generated names, addresses and prices" name:"faker-locale" enum:"es_AR,es_MX,pt_BR" default:"es_AR"`
+	Seed         uint64 `help:"Random seed, for reproducible data (0 picks one at random)" name:"seed"`
+	Parallel     int    `help:"Number of concurrent writes" name:"parallel" default:"4"`
+	Wipe         bool   `help:"Delete seeded products and customers and cancel seeded orders before seeding" name:"wipe"`
+	ConfirmStore string `help:"Store ID, to confirm --wipe without a prompt" name:"confirm-store"`
+}
+
+// seedReport is the outcome of a seed run.
+type seedReport struct {
+	wipe     seedWipeCounts
+	products int
+	orders   int
+	failed   []error
+}
+
+type seedWipeCounts struct {
+	Products  int
+	Customers int
+	Orders    int
+}
+
+func (c *SeedCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	if !c.Wipe && c.Products <= 0 && c.Orders <= 0 {
+		return usagef("nothing to seed: pass --products, --orders or --wipe")
+	}
+
+	if c.Products < 0 || c.Orders < 0 {
+		return usagef("--products and --orders must not be negative")
+	}
+
+	if c.Parallel < 1 {
+		return usagef("--parallel must be at least 1")
+	}
+
+	seed := c.Seed
+	if seed == 0 {
+		seed = rand.Uint64() //nolint:gosec // fake data, not crypto
+	}
+
+	fake := newFaker(fakeLocales[c.FakerLocale], seed)
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	pool := api.NewPool(api.WithWorkers(c.Parallel))
+
+	var report seedReport
+
+	if c.Wipe {
+		if err := c.wipe(ctx, flags, client, pool, &report); err != nil {
+			return err
+		}
+	}
+
+	if flags.DryRun {
+		return writeSeedReport(ctx, u, c, seed, report, pool.Stats(), true)
+	}
+
+	variants, err := seedProducts(ctx, client, pool, fake, c.Products, &report)
+	if err != nil {
+		return err
+	}
+
+	if c.Orders > 0 {
+		if len(variants) == 0 {
+			variants, err = seededVariants(ctx, client)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(variants) == 0 {
+			return usagef("no seeded products to order; pass --products")
+		}
+
+		seedOrders(ctx, client, pool, fake, variants, c.Orders, &report)
+	}
+
+	if err := writeSeedReport(ctx, u, c, seed, report, pool.Stats(), false); err != nil {
+		return err
+	}
+
+	if len(report.failed) > 0 {
+		for _, e := range report.failed[:min(len(report.failed), 5)] {
+			u.Err().Printf("seed: %v", e)
+		}
+
+		return &ExitErr{Code: stableExitCode(report.failed[0]), Err: fmt.Errorf("%d seed writes failed", len(report.failed))}
+	}
+
+	return nil
+}
+
+// wipe removes previously seeded data. It is guarded harder than other
+// destructive commands: the store ID must be typed or passed with
+// --confirm-store, and --force alone is not enough.
+func (c *SeedCmd) wipe(ctx context.Context, flags *RootFlags, client *api.Client, pool *api.Pool, report *seedReport) error {
+	products, err := api.CollectAllPages(ctx, client, "products", nil, decodeList)
+	if err != nil {
+		return err
+	}
+
+	orders, err := api.CollectAllPages(ctx, client, "orders", nil, decodeList)
+	if err != nil {
+		return err
+	}
+
+	products = slices.DeleteFunc(products, func(p map[string]any) bool { return !isSeeded(p) })
+	orders = slices.DeleteFunc(orders, func(o map[string]any) bool { return !isSeeded(o) })
+	customers := seededCustomerIDs(orders)
+
+	active := slices.DeleteFunc(slices.Clone(orders), func(o map[string]any) bool {
+		return jsonStr(o, "status") == "cancelled"
+	})
+
+	if flags.DryRun {
+		report.wipe = seedWipeCounts{Products: len(products), Customers: len(customers), Orders: len(active)}
+
+		return nil
+	}
+
+	action := fmt.Sprintf("wipe %d seeded products, %d customers and %d orders",
+		len(products), len(customers), len(active))
+
+	if err := confirmTyped(flags, action, client.StoreID(), "--confirm-store", c.ConfirmStore); err != nil {
+		return err
+	}
+
+	// Orders go first: customers with open orders can't be deleted.
+	jobs := make([]api.Job, 0, len(active))
+	for _, o := range active {
+		jobs = append(jobs, seedWrite(client, "cancel order", "orders/"+jsonStr(o, "id")+"/cancel",
+			map[string]any{"email": false}))
+	}
+
+	report.wipe.Orders = report.collect(pool.Run(ctx, jobs))
+
+	jobs = jobs[:0]
+	for _, id := range customers {
+		jobs = append(jobs, seedDelete(client, "customers/"+id))
+	}
+
+	report.wipe.Customers = report.collect(pool.Run(ctx, jobs))
+
+	jobs = jobs[:0]
+	for _, p := range products {
+		jobs = append(jobs, seedDelete(client, "products/"+jsonStr(p, "id")))
+	}
+
+	report.wipe.Products = report.collect(pool.Run(ctx, jobs))
+
+	return nil
+}
+
+// seedProducts creates n products and returns the IDs of their variants.
+// Payloads are generated up front so a given --seed yields the same data
+// regardless of scheduling.
+func seedProducts(ctx context.Context, client *api.Client, pool *api.Pool, fake *faker, n int, report *seedReport) ([]int64, error) {
+	created := make([][]int64, n)
+	jobs := make([]api.Job, n)
+
+	for i := range n {
+		payload := fake.product(seedMarker)
+
+		jobs[i] = func(ctx context.Context) error {
+			body, err := jsonBody(payload)
+			if err != nil {
+				return err
+			}
+
+			resp, err := client.Post(ctx, "products", body) //nolint:bodyclose // DecodeResponse closes body
+			if err != nil {
+				return fmt.Errorf("create product: %w", err)
+			}
+
+			p, err := api.DecodeResponse[map[string]any](resp)
+			if err != nil {
+				return fmt.Errorf("create product: %w", err)
+			}
+
+			created[i] = variantIDs(p)
+
+			return nil
+		}
+	}
+
+	report.products = report.collect(pool.Run(ctx, jobs))
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return slices.Concat(created...), nil
+}
+
+func seedOrders(ctx context.Context, client *api.Client, pool *api.Pool, fake *faker, variants []int64, n int, report *seedReport) {
+	jobs := make([]api.Job, n)
+
+	for i := range n {
+		jobs[i] = seedWrite(client, "create order", "orders", fake.order(variants, seedMarker))
+	}
+
+	report.orders = report.collect(pool.Run(ctx, jobs))
+}
+
+// seededVariants returns the variant IDs of products seeded by earlier runs.
+func seededVariants(ctx context.Context, client *api.Client) ([]int64, error) {
+	products, err := api.CollectAllPages(ctx, client, "products", nil, decodeList)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+
+	for _, p := range products {
+		if isSeeded(p) {
+			ids = append(ids, variantIDs(p)...)
+		}
+	}
+
+	return ids, nil
+}
+
+// seedWrite returns a job that POSTs payload to path.
+func seedWrite(client *api.Client, what, path string, payload any) api.Job {
+	return func(ctx context.Context) error {
+		body, err := jsonBody(payload)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Post(ctx, path, body) //nolint:bodyclose // decodeOptionalJSON closes body
+		if err != nil {
+			return fmt.Errorf("%s: %w", what, err)
+		}
+
+		_, err = decodeOptionalJSON(resp)
+
+		return err
+	}
+}
+
+func seedDelete(client *api.Client, path string) api.Job {
+	return func(ctx context.Context) error {
+		resp, err := client.Delete(ctx, path) //nolint:bodyclose // decodeOptionalJSON closes body
+		if err != nil {
+			return fmt.Errorf("delete %s: %w", path, err)
+		}
+
+		_, err = decodeOptionalJSON(resp)
+
+		return err
+	}
+}
+
+// collect records the failures of a pool run and returns how many jobs
+// succeeded.
+func (r *seedReport) collect(errs []error) int {
+	ok := 0
+
+	for _, err := range errs {
+		if err != nil {
+			r.failed = append(r.failed, err)
+		} else {
+			ok++
+		}
+	}
+
+	return ok
+}
+
+// isSeeded reports whether a product (by tag) or order (by owner note) was
+// created by seed.
+func isSeeded(item map[string]any) bool {
+	if jsonStr(item, "owner_note") == seedMarker {
+		return true
+	}
+
+	for _, tag := range strings.Split(jsonStr(item, "tags"), ",") {
+		if strings.TrimSpace(tag) == seedMarker {
+			return true
+		}
+	}
+
+	return false
+}
+
+// seededCustomerIDs returns the distinct customers of seeded orders, which
+// are the customers seed created.
+func seededCustomerIDs(orders []map[string]any) []string {
+	var ids []string
+
+	for _, o := range orders {
+		cust, _ := o["customer"].(map[string]any)
+		if id := jsonStr(cust, "id"); id != "" && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+func variantIDs(product map[string]any) []int64 {
+	variants, _ := product["variants"].([]any)
+	ids := make([]int64, 0, len(variants))
+
+	for _, v := range variants {
+		m, _ := v.(map[string]any)
+		if id, ok := m["id"].(float64); ok {
+			ids = append(ids, int64(id))
+		}
+	}
+
+	return ids
+}
+
+func writeSeedReport(ctx context.Context, u *ui.UI, c *SeedCmd, seed uint64, r seedReport, stats api.PoolStats, dryRun bool) error {
+	kvs := []resultKV{
+		kv("dry_run", dryRun),
+		kv("locale", c.FakerLocale),
+		kv("seed", seed),
+	}
+
+	if c.Wipe {
+		kvs = append(kvs,
+			kv("products_deleted", r.wipe.Products),
+			kv("customers_deleted", r.wipe.Customers),
+			kv("orders_cancelled", r.wipe.Orders),
+		)
+	}
+
+	if dryRun {
+		kvs = append(kvs, kv("products_planned", c.Products), kv("orders_planned", c.Orders))
+	} else {
+		kvs = append(kvs,
+			kv("products_created", r.products),
+			kv("orders_created", r.orders),
+			kv("failed", len(r.failed)),
+			kv("stats", stats),
+		)
+	}
+
+	return writeResult(ctx, u, kvs...)
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// fakeLocale holds the word lists and price range used to generate
+// plausible demo data for one market.
+type fakeLocale struct {
+	Lang       string
+	Country    string
+	PriceMin   int
+	PriceMax   int
+	Nouns      []string
+	Adjectives []string
+	Colors     []string
+	FirstNames []string
+	LastNames  []string
+	Streets    []string
+	Cities     []string
+	Provinces  []string
+}
+
+var fakeLocales = map[string]fakeLocale{
+	"es_AR": {
+		Lang: "es", Country: "AR", PriceMin: 4500, PriceMax: 95000,
+		Nouns:      []string{"Remera", "Buzo", "Campera", "Jean", "Zapatilla", "Mochila", "Gorra", "Bufanda", "Mate", "Termo", "Taza", "Vela"},
+		Adjectives: []string{"Clásica", "Oversize", "Básica", "Urbana", "Premium", "Vintage", "Deportiva", "Artesanal"},
+		Colors:     []string{"Negra", "Blanca", "Azul", "Verde", "Gris", "Bordó", "Beige"},
+		FirstNames: []string{"Sofía", "Mateo", "Valentina", "Santiago", "Camila", "Benjamín", "Lucía", "Joaquín", "Martina", "Tomás"},
+		LastNames:  []string{"González", "Rodríguez", "Fernández", "López", "Martínez", "Pérez", "Gómez", "Díaz", "Romero", "Sosa"},
+		Streets:    []string{"Av. Corrientes", "Av. Santa Fe", "Florida", "Av. Rivadavia", "San Martín", "Belgrano", "Mitre"},
+		Cities:     []string{"Buenos Aires", "Córdoba", "Rosario", "Mendoza", "La Plata", "Mar del Plata"},
+		Provinces:  []string{"Buenos Aires", "Córdoba", "Santa Fe", "Mendoza"},
+	},
+	"es_MX": {
+		Lang: "es", Country: "MX", PriceMin: 150, PriceMax: 2500,
+		Nouns:      []string{"Playera", "Sudadera", "Chamarra", "Jeans", "Tenis", "Mochila", "Gorra", "Rebozo", "Taza", "Vela"},
+		Adjectives: []string{"Clásica", "Oversize", "Básica", "Urbana", "Premium", "Vintage", "Deportiva", "Artesanal"},
+		Colors:     []string{"Negra", "Blanca", "Azul", "Verde", "Gris", "Rosa", "Café"},
+		FirstNames: []string{"Sofía", "Santiago", "Regina", "Mateo", "Ximena", "Diego", "Valeria", "Emiliano", "Fernanda", "Leonardo"},
+		LastNames:  []string{"Hernández", "García", "Martínez", "López", "González", "Rodríguez", "Pérez", "Sánchez", "Ramírez", "Flores"},
+		Streets:    []string{"Av. Reforma", "Insurgentes Sur", "Av. Juárez", "Madero", "Av. Chapultepec", "Hidalgo"},
+		Cities:     []string{"Ciudad de México", "Guadalajara", "Monterrey", "Puebla", "Querétaro", "Mérida"},
+		Provinces:  []string{"CDMX", "Jalisco", "Nuevo León", "Puebla"},
+	},
+	"pt_BR": {
+		Lang: "pt", Country: "BR", PriceMin: 29, PriceMax: 590,
+		Nouns:      []string{"Camiseta", "Moletom", "Jaqueta", "Calça Jeans", "Tênis", "Mochila", "Boné", "Cachecol", "Caneca", "Vela"},
+		Adjectives: []string{"Clássica", "Oversized", "Básica", "Urbana", "Premium", "Vintage", "Esportiva", "Artesanal"},
+		Colors:     []string{"Preta", "Branca", "Azul", "Verde", "Cinza", "Vinho", "Bege"},
+		FirstNames: []string{"Maria", "Miguel", "Alice", "Arthur", "Helena", "Heitor", "Laura", "Davi", "Valentina", "Gabriel"},
+		LastNames:  []string{"Silva", "Santos", "Oliveira", "Souza", "Rodrigues", "Ferreira", "Alves", "Pereira", "Lima", "Gomes"},
+		Streets:    []string{"Av. Paulista", "Rua Augusta", "Rua Oscar Freire", "Av. Atlântica", "Rua da Consolação", "Av. Brasil"},
+		Cities:     []string{"São Paulo", "Rio de Janeiro", "Belo Horizonte", "Curitiba", "Porto Alegre", "Salvador"},
+		Provinces:  []string{"SP", "RJ", "MG", "PR"},
+	},
+}
+
+// faker generates fake store data from a locale and a seeded source.
+type faker struct {
+	loc fakeLocale
+	rng *rand.Rand
+}
+
+func newFaker(loc fakeLocale, seed uint64) *faker {
+	return &faker{loc: loc, rng: rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))} //nolint:gosec // fake data, not crypto
+}
+
+func (f *faker) pick(list []string) string {
+	return list[f.rng.IntN(len(list))]
+}
+
+// product returns a product payload with one to three color variants.
+func (f *faker) product(tag string) map[string]any {
+	noun := f.pick(f.loc.Nouns)
+	name := noun + " " + f.pick(f.loc.Adjectives)
+	price := f.loc.PriceMin + f.rng.IntN(f.loc.PriceMax-f.loc.PriceMin+1)
+
+	colors := f.rng.Perm(len(f.loc.Colors))[:1+f.rng.IntN(3)]
+	variants := make([]map[string]any, 0, len(colors))
+
+	for _, i := range colors {
+		variants = append(variants, map[string]any{
+			"price":  fmt.Sprintf("%d.00", price),
+			"stock":  f.rng.IntN(101),
+			"sku":    fmt.Sprintf("SEED-%06d", f.rng.IntN(1_000_000)),
+			"values": []map[string]string{{f.loc.Lang: f.loc.Colors[i]}},
+		})
+	}
+
+	return map[string]any{
+		"name":        map[string]string{f.loc.Lang: name},
+		"description": map[string]string{f.loc.Lang: "<p>" + name + "</p>"},
+		"attributes":  []map[string]string{{f.loc.Lang: "Color"}},
+		"published":   true,
+		"tags":        tag,
+		"variants":    variants,
+	}
+}
+
+// customer returns a customer with an address under the reserved
+// example.com domain, so seeded orders never email a real person.
+func (f *faker) customer() map[string]any {
+	first, last := f.pick(f.loc.FirstNames), f.pick(f.loc.LastNames)
+	email := fmt.Sprintf("%s.%s.%d@example.com", asciiFold(first), asciiFold(last), f.rng.IntN(10_000))
+
+	return map[string]any{
+		"name":  first + " " + last,
+		"email": strings.ToLower(email),
+		"phone": fmt.Sprintf("+%s %d", countryCallingCode(f.loc.Country), 1_100_000_000+f.rng.IntN(899_999_999)),
+	}
+}
+
+func (f *faker) address(name string) map[string]any {
+	return map[string]any{
+		"first_name": name,
+		"address":    f.pick(f.loc.Streets),
+		"number":     fmt.Sprintf("%d", 100+f.rng.IntN(4900)),
+		"city":       f.pick(f.loc.Cities),
+		"province":   f.pick(f.loc.Provinces),
+		"zipcode":    fmt.Sprintf("%04d", 1000+f.rng.IntN(8999)),
+		"country":    f.loc.Country,
+	}
+}
+
+// order returns an order payload buying one to three of variants.
+func (f *faker) order(variants []int64, note string) map[string]any {
+	n := min(len(variants), 1+f.rng.IntN(3))
+	items := make([]map[string]any, 0, n)
+
+	for _, i := range f.rng.Perm(len(variants))[:n] {
+		items = append(items, map[string]any{"variant_id": variants[i], "quantity": 1 + f.rng.IntN(3)})
+	}
+
+	cust := f.customer()
+	addr := f.address(cust["name"].(string)) //nolint:forcetypeassert // set just above
+
+	return map[string]any{
+		"products":                items,
+		"customer":                cust,
+		"billing_address":         addr,
+		"shipping_address":        addr,
+		"gateway":                 "offline",
+		"payment_status":          "paid",
+		"inventory_behaviour":     "bypass",
+		"owner_note":              note,
+		"send_confirmation_email": false,
+		"send_fulfillment_email":  false,
+	}
+}
+
+func countryCallingCode(country string) string {
+	switch country {
+	case "BR":
+		return "55"
+	case "MX":
+		return "52"
+	default:
+		return "54"
+	}
+}
+
+// asciiFold strips the accents used in the locale word lists, for emails.
+func asciiFold(s string) string {
+	return strings.NewReplacer(
+		"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ñ", "n", "ã", "a", "ç", "c", "ê", "e", "ô", "o",
+		"Á", "A", "É", "E", "Í", "I", "Ó", "O", "Ú", "U",
+	).Replace(s)
+}
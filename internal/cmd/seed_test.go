@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestFaker_Deterministic(t *testing.T) {
+	t.Parallel()
+
+	a := newFaker(fakeLocales["pt_BR"], 42)
+	b := newFaker(fakeLocales["pt_BR"], 42)
+
+	for range 5 {
+		if pa, pb := mustJSON(t, a.product(seedMarker)), mustJSON(t, b.product(seedMarker)); pa != pb {
+			t.Fatalf("same seed gave different products:\n%s\n%s", pa, pb)
+		}
+	}
+
+	p := a.product(seedMarker)
+	if _, ok := p["name"].(map[string]string)["pt"]; !ok {
+		t.Errorf("pt_BR product name = %v, want a pt translation", p["name"])
+	}
+
+	o := a.order([]int64{1, 2, 3}, seedMarker)
+	if o["owner_note"] != seedMarker || o["send_confirmation_email"] != false {
+		t.Errorf("order = %v", o)
+	}
+}
+
+func TestIsSeeded(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		item map[string]any
+		want bool
+	}{
+		{map[string]any{"tags": "nube-seed"}, true},
+		{map[string]any{"tags": "sale, nube-seed"}, true},
+		{map[string]any{"tags": "nube-seeded"}, false},
+		{map[string]any{"owner_note": "nube-seed"}, true},
+		{map[string]any{"owner_note": "gift wrap"}, false},
+		{map[string]any{}, false},
+	}
+
+	for _, tt := range tests {
+		if got := isSeeded(tt.item); got != tt.want {
+			t.Errorf("isSeeded(%v) = %v, want %v", tt.item, got, tt.want)
+		}
+	}
+}
+
+func TestSeed_CreatesProductsAndOrders(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var (
+		mu       sync.Mutex
+		nextID   = 100
+		variants []float64
+		orders   []map[string]any
+	)
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var body map[string]any
+
+		b, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(b, &body)
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /v1/123/products":
+			if body["tags"] != seedMarker {
+				t.Errorf("product tags = %v", body["tags"])
+			}
+
+			nextID++
+			variants = append(variants, float64(nextID*10))
+			_, _ = fmt.Fprintf(w, `{"id":%d,"variants":[{"id":%d}]}`, nextID, nextID*10)
+		case "POST /v1/123/orders":
+			orders = append(orders, body)
+			_, _ = w.Write([]byte(`{"id":1}`))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"seed", "--products", "3", "--orders", "2", "--seed", "7", "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v (output: %q)", err, buf.String())
+	}
+
+	if got["products_created"] != float64(3) || got["orders_created"] != float64(2) || got["failed"] != float64(0) {
+		t.Errorf("report = %v", got)
+	}
+
+	if len(orders) != 2 {
+		t.Fatalf("orders posted = %d, want 2", len(orders))
+	}
+
+	for _, o := range orders {
+		if o["owner_note"] != seedMarker {
+			t.Errorf("owner_note = %v", o["owner_note"])
+		}
+
+		for _, item := range o["products"].([]any) {
+			if id := item.(map[string]any)["variant_id"].(float64); !slices.Contains(variants, id) {
+				t.Errorf("order uses variant %v, not one just created %v", id, variants)
+			}
+		}
+	}
+}
+
+func TestSeed_NothingToDo(t *testing.T) {
+	err := Execute([]string{"seed"})
+	if ExitCode(err) != ExitUsage {
+		t.Errorf("ExitCode = %d, want %d (err %v)", ExitCode(err), ExitUsage, err)
+	}
+}
+
+// wipeStore serves a store with a seeded and an unseeded product, and seeded
+// orders (one open, one cancelled) next to a real one.
+func wipeStore(t *testing.T, writes *[]string) http.Handler {
+	t.Helper()
+
+	var mu sync.Mutex
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/123/products":
+			_, _ = w.Write([]byte(`[{"id":1,"tags":"nube-seed"},{"id":2,"tags":"real"}]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/123/orders":
+			_, _ = w.Write([]byte(`[
+				{"id":10,"owner_note":"nube-seed","status":"open","customer":{"id":7}},
+				{"id":11,"owner_note":"nube-seed","status":"cancelled","customer":{"id":7}},
+				{"id":12,"owner_note":"","status":"open","customer":{"id":8}}
+			]`))
+		case r.Method == http.MethodGet:
+			// Journaled writes snapshot the resource first.
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			mu.Lock()
+			*writes = append(*writes, r.Method+" "+r.URL.Path)
+			mu.Unlock()
+
+			_, _ = w.Write([]byte(`{}`))
+		}
+	})
+}
+
+func TestSeed_WipeRequiresStoreConfirmation(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var writes []string
+
+	setupMockAPIClient(t, wipeStore(t, &writes))
+
+	for _, args := range [][]string{
+		{"seed", "--wipe", "--force", "--no-input"},
+		{"seed", "--wipe", "--confirm-store", "999"},
+	} {
+		if err := Execute(args); ExitCode(err) != ExitUsage {
+			t.Errorf("%v: ExitCode = %d, want %d (err %v)", args, ExitCode(err), ExitUsage, err)
+		}
+	}
+
+	if len(writes) != 0 {
+		t.Errorf("writes = %v, want none", writes)
+	}
+}
+
+func TestSeed_Wipe(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var writes []string
+
+	setupMockAPIClient(t, wipeStore(t, &writes))
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"seed", "--wipe", "--confirm-store", "123", "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	want := []string{
+		"POST /v1/123/orders/10/cancel",
+		"DELETE /v1/123/customers/7",
+		"DELETE /v1/123/products/1",
+	}
+	if !slices.Equal(writes, want) {
+		t.Errorf("writes = %v, want %v", writes, want)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v (output: %q)", err, buf.String())
+	}
+
+	if got["products_deleted"] != float64(1) || got["customers_deleted"] != float64(1) || got["orders_cancelled"] != float64(1) {
+		t.Errorf("report = %v", got)
+	}
+}
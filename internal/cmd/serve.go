@@ -0,0 +1,431 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// JSON-RPC 2.0 error codes used by the daemon.
+const (
+	rpcParseError     = -32700
+	rpcMethodNotFound = -32601
+)
+
+// ServeCmd runs a local JSON-RPC daemon so repeated invocations skip process
+// startup and reuse warm API clients (credentials + HTTP connections).
+type ServeCmd struct {
+	Socket string `help:"Unix socket path to listen on" name:"socket" default:"${daemon_socket}"`
+}
+
+func (c *ServeCmd) Run(ctx context.Context) error {
+	u := ui.FromContext(ctx)
+
+	if isServing(ctx) {
+		return usagef("cannot start a daemon from within a daemon")
+	}
+
+	if err := refuseNested(ctx, "serve"); err != nil {
+		return err
+	}
+
+	if c.Socket == "" {
+		return usagef("--socket is required: there is no runtime or data dir for the default")
+	}
+
+	ln, err := listenDaemonSocket(ctx, c.Socket)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(c.Socket) }()
+
+	orig := newAPIClient
+	newAPIClient = cachingAPIClient(orig)
+
+	defer func() { newAPIClient = orig }()
+
+	// Runs log to their own callers; the daemon's logger keeps the rest.
+	origLog := slog.Default()
+	slog.SetDefault(slog.New(&ctxLogHandler{next: origLog.Handler()}))
+
+	defer slog.SetDefault(origLog)
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if u != nil {
+		u.Err().Printf("listening on %s", c.Socket)
+	}
+
+	return serveDaemon(ctx, ln)
+}
+
+// defaultDaemonSocket is in the user's runtime dir, which other users can't
+// enter, so nobody else can claim the socket before the daemon does.
+func defaultDaemonSocket() string {
+	dir, err := config.RuntimeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "nube.sock")
+}
+
+// listenDaemonSocket listens on path, replacing a stale socket but refusing
+// to start if another daemon is already answering on it.
+func listenDaemonSocket(ctx context.Context, path string) (net.Listener, error) {
+	if _, err := os.Stat(path); err == nil {
+		if conn, dialErr := (&net.Dialer{}).DialContext(ctx, "unix", path); dialErr == nil {
+			_ = conn.Close()
+
+			return nil, fmt.Errorf("a daemon is already listening on %s", path)
+		}
+
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("remove stale socket: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("create socket dir: %w", err)
+	}
+
+	// Created private, so no caller can connect before the chmod below.
+	restore := restrictUmask()
+	ln, err := (&net.ListenConfig{}).Listen(ctx, "unix", path)
+
+	restore()
+
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", path, err)
+	}
+
+	// The daemon acts with the user's credentials; keep the socket private.
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = ln.Close()
+
+		return nil, fmt.Errorf("restrict socket permissions: %w", err)
+	}
+
+	return ln, nil
+}
+
+func serveDaemon(ctx context.Context, ln net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("accept: %w", err)
+		}
+
+		go handleDaemonConn(ctx, conn)
+	}
+}
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  rpcRunParams    `json:"params"`
+}
+
+type rpcRunParams struct {
+	Args []string `json:"args"`
+	// Env holds the caller's callerEnvVars.
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// callerEnvVars are read from the caller's environment rather than the
+// daemon's in a served run: they pick the store and token, and restrict
+// what may run and be sent. NUBE_SESSION carries the caller's session key,
+// so `nube use` applies even when the session is the caller's shell.
+var callerEnvVars = []string{"NUBE_ACCESS_TOKEN", "NUBE_USER_ID", "NUBE_STORE", credstore.SessionEnv, "NUBE_POLICY", "NUBE_ENABLE_COMMANDS", "NUBE_LANG"}
+
+// callerEnv returns this process's callerEnvVars, to send to a daemon.
+func callerEnv() map[string]string {
+	env := make(map[string]string, len(callerEnvVars))
+	for _, name := range callerEnvVars {
+		env[name] = os.Getenv(name)
+	}
+
+	if key, err := credstore.SessionKey(); err == nil {
+		env[credstore.SessionEnv] = key
+	}
+
+	return env
+}
+
+// getenv reads an environment variable for this run: the caller's value
+// of a callerEnvVars variable in a served run, else the process's.
+func (f *RootFlags) getenv(name string) string {
+	if f == nil {
+		return os.Getenv(name)
+	}
+
+	if v, ok := f.callerEnv[name]; ok {
+		return v
+	}
+
+	return os.Getenv(name)
+}
+
+// servedGetenv is getenv for the run of ctx before its flags are parsed.
+func servedGetenv(ctx context.Context) func(string) string {
+	return (&RootFlags{callerEnv: servedEnv(ctx)}).getenv
+}
+
+// resolveStore is credstore.ResolveStore with the run's environment.
+func (f *RootFlags) resolveStore(store string) (string, credstore.StoreProfile, error) {
+	return credstore.ResolveStoreEnv(store, f.getenv)
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// rpcRunResult is the result of the "run" method: one CLI invocation.
+type rpcRunResult struct {
+	ExitCode int    `json:"exit_code"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+}
+
+// handleDaemonConn serves newline-delimited JSON-RPC requests on one connection.
+func handleDaemonConn(ctx context.Context, conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	dec := json.NewDecoder(conn)
+	enc := json.NewEncoder(conn)
+
+	for {
+		var req rpcRequest
+		if err := dec.Decode(&req); err != nil {
+			if !errors.Is(err, io.EOF) {
+				_ = enc.Encode(rpcResponse{JSONRPC: "2.0", Error: &rpcError{Code: rpcParseError, Message: err.Error()}})
+			}
+
+			return
+		}
+
+		_ = enc.Encode(handleDaemonRequest(ctx, req))
+	}
+}
+
+func handleDaemonRequest(ctx context.Context, req rpcRequest) rpcResponse {
+	resp := rpcResponse{JSONRPC: "2.0", ID: req.ID}
+
+	switch req.Method {
+	case "ping":
+		resp.Result = map[string]any{"version": VersionString()}
+	case "run":
+		var stdout, stderr bytes.Buffer
+
+		// A caller that sent no environment gets none of the daemon's.
+		env := make(map[string]string, len(callerEnvVars))
+		for _, name := range callerEnvVars {
+			env[name] = req.Params.Env[name]
+		}
+
+		// Kong would fill these flags from the daemon's environment; a flag
+		// in the caller's args still wins, being later.
+		args := append([]string{"--store=" + env["NUBE_STORE"], "--enable-commands=" + env["NUBE_ENABLE_COMMANDS"]}, req.Params.Args...)
+
+		err := execute(withServing(ctx, env), args, &stdout, &stderr)
+		resp.Result = rpcRunResult{
+			ExitCode: ExitCode(err),
+			Stdout:   stdout.String(),
+			Stderr:   stderr.String(),
+		}
+	default:
+		resp.Error = &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+
+	return resp
+}
+
+// clientKey identifies the API client a run gets: everything that
+// defaultNewAPIClient builds it from.
+type clientKey struct {
+	store, token, userID        string
+	apiBaseURL, mockDir, record string
+	timeout                     time.Duration
+}
+
+func newClientKey(flags *RootFlags) clientKey {
+	k := clientKey{
+		token:      flags.getenv("NUBE_ACCESS_TOKEN"),
+		userID:     flags.getenv("NUBE_USER_ID"),
+		apiBaseURL: flags.APIBaseURL,
+		mockDir:    flags.MockDir,
+		record:     flags.Record,
+		timeout:    flags.Timeout,
+	}
+
+	// The profile, not --store, picks the client: NUBE_STORE, the session
+	// and the default can name it too, and logging in again changes its token.
+	if k.token == "" {
+		if name, profile, err := flags.resolveStore(flags.Store); err == nil {
+			k.store, k.token = name, profile.AccessToken
+		}
+	}
+
+	return k
+}
+
+// cachingAPIClient memoizes API clients for the daemon's lifetime, one per
+// clientKey.
+func cachingAPIClient(next func(*RootFlags) (*api.Client, error)) func(*RootFlags) (*api.Client, error) {
+	var (
+		mu      sync.Mutex
+		clients = map[clientKey]*api.Client{}
+	)
+
+	return func(flags *RootFlags) (*api.Client, error) {
+		key := newClientKey(flags)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if c, ok := clients[key]; ok {
+			return c, nil
+		}
+
+		c, err := next(flags)
+		if err != nil {
+			return nil, err
+		}
+
+		clients[key] = c
+
+		return c, nil
+	}
+}
+
+type servingCtxKey struct{}
+
+// withServing marks a run the daemon executes for a caller with the
+// environment env (see callerEnvVars).
+func withServing(ctx context.Context, env map[string]string) context.Context {
+	if env == nil {
+		env = map[string]string{}
+	}
+
+	return context.WithValue(ctx, servingCtxKey{}, env)
+}
+
+// isServing reports whether this invocation is being executed by the daemon.
+func isServing(ctx context.Context) bool {
+	return servedEnv(ctx) != nil
+}
+
+// servedEnv returns the caller's environment of a served run, or nil.
+func servedEnv(ctx context.Context) map[string]string {
+	env, _ := ctx.Value(servingCtxKey{}).(map[string]string)
+
+	return env
+}
+
+// forwardToDaemon sends an invocation to a running `nube serve`, with this
+// process's callerEnvVars, and replays its output. The returned error only
+// carries the exit code; the daemon's stderr already contains the message.
+func forwardToDaemon(ctx context.Context, socket string, args []string, stdout, stderr io.Writer) error {
+	// The request carries the caller's token; only hand it to a daemon run
+	// by the same user.
+	if err := checkSocketOwner(socket); err != nil {
+		return &ExitErr{Code: ExitConfig, Err: fmt.Errorf("daemon socket %s: %w", socket, err)}
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "unix", socket)
+	if err != nil {
+		return &ExitErr{Code: ExitConfig, Err: fmt.Errorf("connect to daemon (is `nube serve` running?): %w", err)}
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	req := rpcRequest{JSONRPC: "2.0", ID: json.RawMessage("1"), Method: "run", Params: rpcRunParams{Args: stripDaemonFlag(args), Env: callerEnv()}}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return fmt.Errorf("send to daemon: %w", err)
+	}
+
+	var resp struct {
+		Result *rpcRunResult `json:"result"`
+		Error  *rpcError     `json:"error"`
+	}
+
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return fmt.Errorf("read daemon response: %w", err)
+	}
+
+	if resp.Error != nil {
+		return fmt.Errorf("daemon error %d: %s", resp.Error.Code, resp.Error.Message)
+	}
+
+	if resp.Result == nil {
+		return errors.New("daemon returned no result")
+	}
+
+	_, _ = io.WriteString(stdout, resp.Result.Stdout)
+	_, _ = io.WriteString(stderr, resp.Result.Stderr)
+
+	if resp.Result.ExitCode != 0 {
+		return &ExitErr{Code: resp.Result.ExitCode}
+	}
+
+	return nil
+}
+
+// stripDaemonFlag removes --daemon so the daemon doesn't forward to itself.
+func stripDaemonFlag(args []string) []string {
+	out := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+
+		if a == "--daemon" {
+			i++
+			continue
+		}
+
+		if strings.HasPrefix(a, "--daemon=") {
+			continue
+		}
+
+		out = append(out, a)
+	}
+
+	return out
+}
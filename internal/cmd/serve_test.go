@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/i18n"
+)
+
+func startTestDaemon(t *testing.T) string {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	sock := filepath.Join(t.TempDir(), "nube.sock")
+
+	ln, err := listenDaemonSocket(ctx, sock)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	go func() { _ = serveDaemon(ctx, ln) }()
+
+	return sock
+}
+
+func TestDaemon_ForwardsInvocation(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 123, "name": "Mi Tienda"})
+	}))
+
+	sock := startTestDaemon(t)
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"store", "get", "--json", "--daemon", sock}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v (output: %q)", err, buf.String())
+	}
+
+	if jsonStr(got, "id") != "123" {
+		t.Errorf("got = %v", got)
+	}
+}
+
+func TestDaemon_ForwardsExitCode(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	sock := startTestDaemon(t)
+
+	_ = captureStdout(t)
+	_ = captureStderr(t)
+
+	err := Execute([]string{"product", "get", "1", "--daemon=" + sock})
+	if ExitCode(err) != ExitNotFound {
+		t.Fatalf("exit code = %d, want %d", ExitCode(err), ExitNotFound)
+	}
+}
+
+func TestDaemon_NotRunning(t *testing.T) {
+	setupConfigDir(t)
+	_ = captureStderr(t)
+
+	err := Execute([]string{"version", "--daemon", filepath.Join(t.TempDir(), "missing.sock")})
+	if ExitCode(err) != ExitConfig {
+		t.Fatalf("exit code = %d, want %d", ExitCode(err), ExitConfig)
+	}
+}
+
+func TestHandleDaemonRequest_UnknownMethod(t *testing.T) {
+	t.Parallel()
+
+	resp := handleDaemonRequest(context.Background(), rpcRequest{JSONRPC: "2.0", ID: json.RawMessage("7"), Method: "nope"})
+	if resp.Error == nil || resp.Error.Code != rpcMethodNotFound {
+		t.Fatalf("error = %+v", resp.Error)
+	}
+
+	if string(resp.ID) != "7" {
+		t.Errorf("id = %s", resp.ID)
+	}
+}
+
+func TestStripDaemonFlag(t *testing.T) {
+	t.Parallel()
+
+	got := stripDaemonFlag([]string{"--daemon", "/tmp/s", "product", "list", "--daemon=/tmp/x", "--json"})
+	want := []string{"product", "list", "--json"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("stripDaemonFlag() = %v, want %v", got, want)
+	}
+}
+
+func TestCachingAPIClient(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{
+		"a": {StoreID: "1", AccessToken: "tok-a"},
+		"b": {StoreID: "2", AccessToken: "tok-b"},
+	}, "a")
+
+	calls := 0
+	get := cachingAPIClient(func(_ *RootFlags) (*api.Client, error) {
+		calls++
+		return api.New("1", "tok"), nil
+	})
+
+	a, _ := get(&RootFlags{Store: "a"})
+	a2, _ := get(&RootFlags{})
+	_, _ = get(&RootFlags{Store: "b"})
+	_, _ = get(&RootFlags{Store: "a", APIBaseURL: "http://127.0.0.1:1/v1"})
+	_, _ = get(&RootFlags{Store: "a", MockDir: "fixtures"})
+	_, _ = get(&RootFlags{Store: "a", Timeout: time.Second})
+	_, _ = get(&RootFlags{callerEnv: map[string]string{"NUBE_ACCESS_TOKEN": "env-tok", "NUBE_USER_ID": "3"}})
+
+	if a != a2 {
+		t.Error("expected cached client for same store")
+	}
+
+	if calls != 6 {
+		t.Errorf("calls = %d, want 6", calls)
+	}
+}
+
+func TestDaemon_EnableCommandsCheckedBeforeForwarding(t *testing.T) {
+	setupConfigDir(t)
+	t.Setenv("NUBE_ENABLE_COMMANDS", "version")
+	_ = captureStderr(t)
+
+	// The socket doesn't exist: reaching it would exit with ExitConfig.
+	err := Execute([]string{"store", "get", "--daemon", filepath.Join(t.TempDir(), "missing.sock")})
+	if ExitCode(err) != ExitUsage {
+		t.Fatalf("exit code = %d, want %d", ExitCode(err), ExitUsage)
+	}
+}
+
+func TestHandleDaemonRequest_CallerEnv(t *testing.T) {
+	setupConfigDir(t)
+	t.Setenv("NUBE_ACCESS_TOKEN", "daemon-token")
+	t.Setenv("NUBE_USER_ID", "999")
+
+	var gotPath, gotAuth string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authentication")
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 777})
+	}))
+	t.Cleanup(srv.Close)
+
+	resp := handleDaemonRequest(context.Background(), rpcRequest{Method: "run", Params: rpcRunParams{
+		Args: []string{"store", "get", "--json", "--api-base-url", srv.URL + "/v1"},
+		Env:  map[string]string{"NUBE_ACCESS_TOKEN": "caller-token", "NUBE_USER_ID": "777"},
+	}})
+
+	res, ok := resp.Result.(rpcRunResult)
+	if !ok || res.ExitCode != 0 {
+		t.Fatalf("result = %+v", resp.Result)
+	}
+
+	if gotPath != "/v1/777/store" || gotAuth != "bearer caller-token" {
+		t.Errorf("request = %s with %q, want the caller's store and token", gotPath, gotAuth)
+	}
+}
+
+func TestHandleDaemonRequest_CallerEnableCommands(t *testing.T) {
+	setupConfigDir(t)
+
+	resp := handleDaemonRequest(context.Background(), rpcRequest{Method: "run", Params: rpcRunParams{
+		Args: []string{"store", "get"},
+		Env:  map[string]string{"NUBE_ENABLE_COMMANDS": "version"},
+	}})
+
+	if res, ok := resp.Result.(rpcRunResult); !ok || res.ExitCode != ExitUsage {
+		t.Fatalf("result = %+v, want exit code %d", resp.Result, ExitUsage)
+	}
+}
+
+func TestHandleDaemonRequest_LogsToEachCaller(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 123})
+	}))
+
+	orig := slog.Default()
+	slog.SetDefault(slog.New(&ctxLogHandler{next: slog.NewTextHandler(io.Discard, nil)}))
+	t.Cleanup(func() { slog.SetDefault(orig) })
+
+	const runs = 16
+
+	results := make([]rpcRunResult, runs)
+
+	var wg sync.WaitGroup
+	for i := range runs {
+		wg.Go(func() {
+			resp := handleDaemonRequest(context.Background(), rpcRequest{Method: "run", Params: rpcRunParams{Args: []string{"store", "get", "--json", "--verbose"}}})
+			results[i], _ = resp.Result.(rpcRunResult)
+		})
+	}
+
+	wg.Wait()
+
+	for i, res := range results {
+		if n := strings.Count(res.Stderr, "api response"); n != 1 {
+			t.Errorf("run %d logged %d API responses, want 1:\n%s", i, n, res.Stderr)
+		}
+	}
+}
+
+func TestHandleDaemonRequest_KeepsProcessLanguage(t *testing.T) {
+	setupConfigDir(t)
+
+	orig := i18n.Default()
+	i18n.SetDefault(i18n.New(i18n.English))
+	t.Cleanup(func() { i18n.SetDefault(orig) })
+
+	resp := handleDaemonRequest(context.Background(), rpcRequest{Method: "run", Params: rpcRunParams{Args: []string{"--lang", "es", "version", "--bogus"}}})
+
+	res, _ := resp.Result.(rpcRunResult)
+	if !strings.Contains(res.Stderr, "Ejecutá con --help") {
+		t.Errorf("stderr = %q, want the run's language", res.Stderr)
+	}
+
+	if got := i18n.Default().Lang(); got != i18n.English {
+		t.Errorf("process language = %q, want it unchanged", got)
+	}
+}
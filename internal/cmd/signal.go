@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+)
+
+// errInterrupted is the cause of a context cancelled by SIGINT or SIGTERM.
+var errInterrupted = errors.New("interrupted")
+
+// inputWaits counts reads blocked on the user's input.
+var inputWaits atomic.Int32
+
+// waitingForInput marks a blocking read of stdin until the returned func is
+// called. An interrupt during it exits at once: nothing has been written
+// yet, and the read wouldn't notice a cancelled context.
+func waitingForInput() func() {
+	inputWaits.Add(1)
+
+	return func() { inputWaits.Add(-1) }
+}
+
+// withInterrupt returns a context cancelled by the first SIGINT or SIGTERM,
+// so a running command stops between requests, keeps its checkpoint and
+// reports what it finished. The handler is removed after that signal, so a
+// second one terminates the process the usual way.
+func withInterrupt(ctx context.Context, stderr io.Writer) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case sig := <-sigs:
+			signal.Stop(sigs)
+
+			if inputWaits.Load() > 0 {
+				_, _ = fmt.Fprintln(stderr)
+
+				os.Exit(ExitCancelled)
+			}
+
+			_, _ = fmt.Fprintf(stderr, "\n%s: stopping after requests in flight (repeat to quit now)\n", sig)
+
+			cancel(errInterrupted)
+		case <-done:
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(sigs)
+		close(done)
+		cancel(nil)
+	}
+}
+
+// interruptedErr turns the error of a command stopped by a signal into
+// ExitCancelled, keeping the command's own message when it says more than
+// that it was cancelled.
+func interruptedErr(ctx context.Context, err error) error {
+	if err == nil || !errors.Is(context.Cause(ctx), errInterrupted) {
+		return err
+	}
+
+	if errors.Is(err, context.Canceled) || err.Error() == "" {
+		return &ExitErr{Code: ExitCancelled, Err: errInterrupted}
+	}
+
+	return &ExitErr{Code: ExitCancelled, Err: fmt.Errorf("%w: %w", errInterrupted, err)}
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInterruptedErr(t *testing.T) {
+	t.Parallel()
+
+	interrupted, cancel := context.WithCancelCause(context.Background())
+	cancel(errInterrupted)
+
+	cancelled, cancel2 := context.WithCancel(context.Background())
+	cancel2()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context //nolint:containedctx // table input
+		err      error
+		wantCode int
+		wantMsg  string
+	}{
+		{name: "no error", ctx: interrupted, err: nil, wantCode: ExitOK},
+		{name: "not interrupted", ctx: cancelled, err: context.Canceled, wantCode: ExitError, wantMsg: "context canceled"},
+		{name: "cancelled request", ctx: interrupted, err: fmt.Errorf("get products: %w", context.Canceled), wantCode: ExitCancelled, wantMsg: "interrupted"},
+		{name: "bare exit", ctx: interrupted, err: &ExitErr{Code: ExitError}, wantCode: ExitCancelled, wantMsg: "interrupted"},
+		{name: "summary kept", ctx: interrupted, err: &ExitErr{Code: ExitError, Err: errors.New("3 of 10 price updates failed")}, wantCode: ExitCancelled, wantMsg: "interrupted: 3 of 10 price updates failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := interruptedErr(tt.ctx, tt.err)
+			if got := ExitCode(err); got != tt.wantCode {
+				t.Errorf("exit code = %d, want %d", got, tt.wantCode)
+			}
+
+			if err != nil && err.Error() != tt.wantMsg {
+				t.Errorf("message = %q, want %q", err.Error(), tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestExecute_Interrupted(t *testing.T) {
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}))
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errInterrupted)
+
+	var stdout, stderr bytes.Buffer
+
+	err := execute(ctx, []string{"product", "list"}, &stdout, &stderr)
+	if got := ExitCode(err); got != ExitCancelled {
+		t.Fatalf("exit code = %d (%v), want %d", got, err, ExitCancelled)
+	}
+
+	if !strings.Contains(stderr.String(), "interrupted") {
+		t.Errorf("stderr = %q, want it to say interrupted", stderr.String())
+	}
+}
+
+func TestWithInterrupt_Signal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("can't send os.Interrupt to itself on Windows")
+	}
+
+	ctx, stop := withInterrupt(context.Background(), io.Discard)
+	defer stop()
+
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := self.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context not cancelled by SIGINT")
+	}
+
+	if !errors.Is(context.Cause(ctx), errInterrupted) {
+		t.Errorf("cause = %v, want errInterrupted", context.Cause(ctx))
+	}
+}
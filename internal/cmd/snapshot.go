@@ -0,0 +1,316 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/jsondiff"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// snapshotResources maps snapshot resource names to API collection paths.
+var snapshotResources = map[string]string{
+	"categories": "categories",
+	"coupons":    "coupons",
+	"products":   "products",
+	"scripts":    "scripts",
+	"webhooks":   "webhooks",
+}
+
+// SnapshotCmd groups store state snapshot commands.
+type SnapshotCmd struct {
+	Create SnapshotCreateCmd `cmd:"" help:"Capture a canonical JSON snapshot of store resources"`
+	Diff   SnapshotDiffCmd   `cmd:"" help:"Report drift between the store and a snapshot file"`
+}
+
+// storeSnapshot is the canonical snapshot document. Resource lists are sorted
+// by id so two snapshots of the same state are byte-identical apart from
+// created_at.
+type storeSnapshot struct {
+	Store     string                      `json:"store"`
+	CreatedAt string                      `json:"created_at"`
+	Resources map[string][]map[string]any `json:"resources"`
+}
+
+type SnapshotCreateCmd struct {
+	Resources string `help:"Comma-separated resources to capture (categories,coupons,products,scripts,webhooks)" default:"webhooks,scripts,categories"`
+	Out       string `help:"Write the snapshot to this file instead of stdout" short:"o"`
+}
+
+func (c *SnapshotCreateCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	names, err := parseSnapshotResources(c.Resources)
+	if err != nil {
+		return err
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	snap, err := captureSnapshot(ctx, client, names)
+	if err != nil {
+		return err
+	}
+
+	if c.Out == "" {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), snap)
+	}
+
+	if err := writeJSONFile(c.Out, snap); err != nil {
+		return err
+	}
+
+	counts := make(map[string]int, len(names))
+	for _, n := range names {
+		counts[n] = len(snap.Resources[n])
+	}
+
+	return writeResult(ctx, u, kv("path", c.Out), kv("resources", counts))
+}
+
+func parseSnapshotResources(s string) ([]string, error) {
+	var names []string
+
+	for _, n := range strings.Split(s, ",") {
+		n = strings.TrimSpace(n)
+		if n == "" {
+			continue
+		}
+
+		if _, ok := snapshotResources[n]; !ok {
+			return nil, usagef("unknown resource %q (want categories, coupons, products, scripts, or webhooks)", n)
+		}
+
+		if !slices.Contains(names, n) {
+			names = append(names, n)
+		}
+	}
+
+	if len(names) == 0 {
+		return nil, usagef("--resources is empty")
+	}
+
+	slices.Sort(names)
+
+	return names, nil
+}
+
+func captureSnapshot(ctx context.Context, client *api.Client, names []string) (storeSnapshot, error) {
+	snap := storeSnapshot{
+		Store:     client.StoreID(),
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Resources: make(map[string][]map[string]any, len(names)),
+	}
+
+	for _, n := range names {
+		items, err := api.CollectAllPages(ctx, client, snapshotResources[n], nil, decodeList)
+		if err != nil {
+			return storeSnapshot{}, err
+		}
+
+		if items == nil {
+			items = []map[string]any{}
+		}
+
+		slices.SortFunc(items, func(a, b map[string]any) int {
+			return compareIDs(jsonStr(a, "id"), jsonStr(b, "id"))
+		})
+
+		snap.Resources[n] = items
+	}
+
+	return snap, nil
+}
+
+// compareIDs orders numeric IDs numerically and anything else lexically.
+func compareIDs(a, b string) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+
+	return strings.Compare(a, b)
+}
+
+type SnapshotDiffCmd struct {
+	File     string `arg:"" name:"file" help:"Snapshot file created by 'nube snapshot create' ('-' for stdin)"`
+	ExitCode bool   `help:"Exit with code 1 when drift is found" name:"exit-code"`
+}
+
+// resourceDrift is the drift report for one resource type.
+type resourceDrift struct {
+	Added   []string        `json:"added"`
+	Removed []string        `json:"removed"`
+	Changed []changedObject `json:"changed"`
+}
+
+type changedObject struct {
+	ID      string        `json:"id"`
+	Changes []jsondiff.Op `json:"changes"`
+}
+
+func (d resourceDrift) empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+func (c *SnapshotDiffCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	b, err := readInputFile(c.File)
+	if err != nil {
+		return err
+	}
+
+	var baseline storeSnapshot
+	if err := json.Unmarshal(b, &baseline); err != nil || baseline.Resources == nil {
+		return usagef("%s is not a snapshot file", c.File)
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	if baseline.Store != "" && baseline.Store != client.StoreID() && u != nil {
+		u.Err().Printf("note: snapshot is from store %s, comparing against store %s", baseline.Store, client.StoreID())
+	}
+
+	names := make([]string, 0, len(baseline.Resources))
+	for n := range baseline.Resources {
+		if _, ok := snapshotResources[n]; !ok {
+			return usagef("snapshot contains unknown resource %q", n)
+		}
+
+		names = append(names, n)
+	}
+
+	slices.Sort(names)
+
+	current, err := captureSnapshot(ctx, client, names)
+	if err != nil {
+		return err
+	}
+
+	report := make(map[string]resourceDrift, len(names))
+	drift := false
+
+	for _, n := range names {
+		d := diffResourceSets(baseline.Resources[n], current.Resources[n])
+		report[n] = d
+		drift = drift || !d.empty()
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{
+			"drift":     drift,
+			"resources": report,
+		}); err != nil {
+			return err
+		}
+	} else {
+		printDrift(ctx, u, names, report)
+	}
+
+	if drift && c.ExitCode {
+		return &ExitErr{Code: ExitError}
+	}
+
+	return nil
+}
+
+// diffResourceSets compares two resource lists by id.
+func diffResourceSets(before, after []map[string]any) resourceDrift {
+	d := resourceDrift{Added: []string{}, Removed: []string{}, Changed: []changedObject{}}
+
+	index := make(map[string]map[string]any, len(after))
+	for _, item := range after {
+		index[jsonStr(item, "id")] = item
+	}
+
+	seen := make(map[string]bool, len(before))
+
+	for _, old := range before {
+		id := jsonStr(old, "id")
+		seen[id] = true
+
+		cur, ok := index[id]
+		if !ok {
+			d.Removed = append(d.Removed, id)
+			continue
+		}
+
+		if ops := jsondiff.Diff(old, cur); len(ops) > 0 {
+			d.Changed = append(d.Changed, changedObject{ID: id, Changes: ops})
+		}
+	}
+
+	for _, item := range after {
+		if id := jsonStr(item, "id"); !seen[id] {
+			d.Added = append(d.Added, id)
+		}
+	}
+
+	return d
+}
+
+func printDrift(ctx context.Context, u *ui.UI, names []string, report map[string]resourceDrift) {
+	if u == nil || outfmt.IsPlain(ctx) {
+		w := stdoutFrom(ctx)
+
+		for _, n := range names {
+			d := report[n]
+
+			for _, id := range d.Added {
+				_, _ = fmt.Fprintf(w, "%s\t%s\tadded\n", n, id)
+			}
+
+			for _, id := range d.Removed {
+				_, _ = fmt.Fprintf(w, "%s\t%s\tremoved\n", n, id)
+			}
+
+			for _, ch := range d.Changed {
+				_, _ = fmt.Fprintf(w, "%s\t%s\tchanged\t%d\n", n, ch.ID, len(ch.Changes))
+			}
+		}
+
+		return
+	}
+
+	drift := false
+
+	for _, n := range names {
+		d := report[n]
+		if d.empty() {
+			continue
+		}
+
+		drift = true
+
+		u.Out().Printf("%s:", n)
+
+		for _, id := range d.Added {
+			u.Out().Addedf("  + %s (added)", id)
+		}
+
+		for _, id := range d.Removed {
+			u.Out().Removedf("  - %s (removed)", id)
+		}
+
+		for _, ch := range d.Changed {
+			u.Out().Printf("  ~ %s", ch.ID)
+			printOps(u, "      ", ch.Changes)
+		}
+	}
+
+	if !drift {
+		u.Err().Printf("no drift")
+	}
+}
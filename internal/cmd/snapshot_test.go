@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func setupSnapshotAPI(t *testing.T, webhooks *[]map[string]any) {
+	t.Helper()
+
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch strings.TrimPrefix(r.URL.Path, "/v1/123/") {
+		case "webhooks":
+			_ = json.NewEncoder(w).Encode(*webhooks)
+		case "categories":
+			_ = json.NewEncoder(w).Encode([]map[string]any{{"id": 10, "name": map[string]any{"es": "Ropa"}}})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestSnapshotCreate_Canonical(t *testing.T) {
+	hooks := []map[string]any{
+		{"id": 100, "event": "order/paid", "url": "https://b"},
+		{"id": 20, "event": "order/created", "url": "https://a"},
+	}
+	setupSnapshotAPI(t, &hooks)
+
+	out := filepath.Join(t.TempDir(), "snap.json")
+
+	_ = captureStdout(t)
+	if err := Execute([]string{"snapshot", "create", "--resources", "webhooks,categories", "-o", out}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var snap storeSnapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if snap.Store != "123" || len(snap.Resources) != 2 {
+		t.Fatalf("snapshot = %+v", snap)
+	}
+
+	if got := jsonStr(snap.Resources["webhooks"][0], "id"); got != "20" {
+		t.Errorf("first webhook id = %s, want 20 (sorted numerically)", got)
+	}
+}
+
+func TestSnapshotCreate_StdoutEnvelope(t *testing.T) {
+	hooks := []map[string]any{{"id": 20, "event": "order/created", "url": "https://a"}}
+	setupSnapshotAPI(t, &hooks)
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"snapshot", "create", "--resources", "webhooks", "--envelope", "--select", "store"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	var got envelope
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v (output: %q)", err, buf.String())
+	}
+
+	data, _ := got.Data.(map[string]any)
+	if !got.OK || data["store"] != "123" || data["resources"] != nil {
+		t.Errorf("envelope = %+v, want ok with only the selected store", got)
+	}
+}
+
+func TestSnapshotDiff_ReportsDrift(t *testing.T) {
+	hooks := []map[string]any{
+		{"id": 20, "event": "order/created", "url": "https://a"},
+		{"id": 30, "event": "order/paid", "url": "https://c"},
+	}
+	setupSnapshotAPI(t, &hooks)
+
+	out := filepath.Join(t.TempDir(), "snap.json")
+
+	_ = captureStdout(t)
+	if err := Execute([]string{"snapshot", "create", "--resources", "webhooks", "-o", out}); err != nil {
+		t.Fatalf("create error = %v", err)
+	}
+
+	hooks = []map[string]any{
+		{"id": 20, "event": "order/created", "url": "https://evil"},
+		{"id": 40, "event": "product/updated", "url": "https://d"},
+	}
+
+	buf := captureStdout(t)
+
+	err := Execute([]string{"snapshot", "diff", out, "--json", "--exit-code"})
+	if ExitCode(err) != ExitError {
+		t.Fatalf("exit code = %d, want %d", ExitCode(err), ExitError)
+	}
+
+	var got struct {
+		Drift     bool                     `json:"drift"`
+		Resources map[string]resourceDrift `json:"resources"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v (output: %q)", err, buf.String())
+	}
+
+	d := got.Resources["webhooks"]
+	if !got.Drift || len(d.Added) != 1 || d.Added[0] != "40" || len(d.Removed) != 1 || d.Removed[0] != "30" {
+		t.Errorf("drift = %+v", got)
+	}
+
+	if len(d.Changed) != 1 || d.Changed[0].ID != "20" {
+		t.Errorf("changed = %+v", d.Changed)
+	}
+}
+
+func TestSnapshotDiff_NoDrift(t *testing.T) {
+	hooks := []map[string]any{{"id": 20, "event": "order/created", "url": "https://a"}}
+	setupSnapshotAPI(t, &hooks)
+
+	out := filepath.Join(t.TempDir(), "snap.json")
+
+	_ = captureStdout(t)
+	if err := Execute([]string{"snapshot", "create", "--resources", "webhooks", "-o", out}); err != nil {
+		t.Fatalf("create error = %v", err)
+	}
+
+	_ = captureStdout(t)
+	if err := Execute([]string{"snapshot", "diff", out, "--exit-code"}); err != nil {
+		t.Fatalf("diff error = %v", err)
+	}
+}
+
+func TestParseSnapshotResources(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseSnapshotResources("webhooks, categories,webhooks")
+	if err != nil || strings.Join(got, ",") != "categories,webhooks" {
+		t.Errorf("parseSnapshotResources() = %v, %v", got, err)
+	}
+
+	if _, err := parseSnapshotResources("orders"); ExitCode(err) != ExitUsage {
+		t.Errorf("unknown resource error = %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package cmd
+
+// restrictUmask is a no-op where there is no umask; the socket's ACL comes
+// from its directory.
+func restrictUmask() func() {
+	return func() {}
+}
+
+// checkSocketOwner trusts the socket where ownership can't be read as a
+// uid; the default socket's directory is private to the user.
+func checkSocketOwner(string) error {
+	return nil
+}
@@ -0,0 +1,42 @@
+//go:build unix
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// restrictUmask makes new files private to the user until the returned
+// func restores the previous mask.
+func restrictUmask() func() {
+	old := syscall.Umask(0o177)
+
+	return func() { syscall.Umask(old) }
+}
+
+// checkSocketOwner fails unless path is a socket (not a link to one) owned
+// by the current user.
+func checkSocketOwner(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return fmt.Errorf("stat: %w", err)
+	}
+
+	if info.Mode().Type() != os.ModeSocket {
+		return errors.New("not a socket")
+	}
+
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return errors.New("unknown owner")
+	}
+
+	if int(st.Uid) != os.Getuid() {
+		return fmt.Errorf("owned by uid %d, not the current user", st.Uid)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+//go:build unix
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListenDaemonSocket_Private(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "run", "nube.sock")
+
+	ln, err := listenDaemonSocket(t.Context(), sock)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	t.Cleanup(func() { _ = ln.Close() })
+
+	for path, want := range map[string]os.FileMode{sock: 0o600, filepath.Dir(sock): 0o700} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if perm := info.Mode().Perm(); perm != want {
+			t.Errorf("%s: mode %o, want %o", path, perm, want)
+		}
+	}
+}
+
+func TestForwardToDaemon_ChecksSocket(t *testing.T) {
+	setupConfigDir(t)
+	t.Setenv("NUBE_ACCESS_TOKEN", "secret-token")
+
+	sock := startTestDaemon(t)
+	dir := t.TempDir()
+
+	notSocket := filepath.Join(dir, "file.sock")
+	if err := os.WriteFile(notSocket, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A link could point anywhere; only the socket itself is trusted.
+	link := filepath.Join(dir, "link.sock")
+	if err := os.Symlink(sock, link); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{notSocket, link, filepath.Join(dir, "missing.sock")} {
+		err := forwardToDaemon(t.Context(), path, []string{"version"}, &strings.Builder{}, &strings.Builder{})
+		if ExitCode(err) != ExitConfig {
+			t.Errorf("%s: err = %v, want a config error", filepath.Base(path), err)
+		}
+	}
+
+	if err := forwardToDaemon(t.Context(), sock, []string{"version"}, &strings.Builder{}, &strings.Builder{}); err != nil {
+		t.Errorf("own socket: %v", err)
+	}
+}
+
+func TestDefaultDaemonSocket(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+
+	if got, want := defaultDaemonSocket(), filepath.Join(dir, "nube-cli", "nube.sock"); got != want {
+		t.Errorf("defaultDaemonSocket() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+)
+
+// runStats is the --stats summary of a run's API traffic.
+type runStats struct {
+	Requests      int   `json:"requests"`
+	Retries       int   `json:"retries"`
+	RateLimited   int   `json:"rate_limited"`
+	BytesReceived int64 `json:"bytes_received"`
+	PagesFetched  int   `json:"pages_fetched"`
+	DurationMS    int64 `json:"duration_ms"`
+}
+
+func newRunStats(rec *api.Recorder, start time.Time) *runStats {
+	snap := rec.Snapshot()
+
+	return &runStats{
+		Requests:      snap.Requests,
+		Retries:       snap.Retries,
+		RateLimited:   snap.RateLimited,
+		BytesReceived: snap.BytesReceived,
+		PagesFetched:  snap.Pages,
+		DurationMS:    time.Since(start).Milliseconds(),
+	}
+}
+
+// String renders the stats as a one-line report.
+func (s *runStats) String() string {
+	return fmt.Sprintf("%d requests, %d retries, %d rate-limited (429), %s received, %d pages in %s",
+		s.Requests, s.Retries, s.RateLimited, formatBytes(s.BytesReceived), s.PagesFetched,
+		(time.Duration(s.DurationMS) * time.Millisecond).Round(time.Millisecond))
+}
+
+// writeStats prints the stats to w (stderr): a {"stats":{...}} object with
+// --json, so stderr stays parseable, or a sentence otherwise.
+func writeStats(w io.Writer, asJSON bool, s *runStats) {
+	if asJSON {
+		_ = outfmt.EncodeJSON(w, map[string]any{"stats": s})
+
+		return
+	}
+
+	_, _ = fmt.Fprintln(w, "stats: "+s.String())
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func setupStatsStore(t *testing.T) {
+	t.Helper()
+
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"id":123,"name":{"es":"Tienda"}}`))
+	}))
+}
+
+func TestStats_Text(t *testing.T) {
+	setupStatsStore(t)
+
+	_ = captureStdout(t)
+	stderr := captureStderr(t)
+
+	if err := Execute([]string{"store", "get", "--plain", "--stats"}); err != nil {
+		t.Fatalf("Execute error = %v", err)
+	}
+
+	if got := stderr.String(); !strings.Contains(got, "stats: 1 requests, 0 retries, 0 rate-limited (429), 33 B received, 1 pages in") {
+		t.Errorf("stderr = %q", got)
+	}
+}
+
+func TestStats_JSON(t *testing.T) {
+	setupStatsStore(t)
+
+	stdout := captureStdout(t)
+	stderr := captureStderr(t)
+
+	if err := Execute([]string{"store", "get", "--json", "--stats"}); err != nil {
+		t.Fatalf("Execute error = %v", err)
+	}
+
+	var got struct {
+		Stats runStats `json:"stats"`
+	}
+	if err := json.Unmarshal([]byte(stderr.String()), &got); err != nil {
+		t.Fatalf("stderr is not JSON: %v (%q)", err, stderr.String())
+	}
+
+	if got.Stats.Requests != 1 || got.Stats.PagesFetched != 1 || got.Stats.BytesReceived != 33 {
+		t.Errorf("stats = %+v", got.Stats)
+	}
+
+	if strings.Contains(stdout.String(), "stats") {
+		t.Errorf("stats leaked into stdout: %s", stdout.String())
+	}
+}
+
+func TestStats_Envelope(t *testing.T) {
+	setupStatsStore(t)
+
+	stdout := captureStdout(t)
+	stderr := captureStderr(t)
+
+	if err := Execute([]string{"store", "get", "--envelope", "--stats"}); err != nil {
+		t.Fatalf("Execute error = %v", err)
+	}
+
+	var env struct {
+		Meta struct {
+			Stats *runStats `json:"stats"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &env); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if env.Meta.Stats == nil || env.Meta.Stats.Requests != 1 {
+		t.Errorf("meta.stats = %+v", env.Meta.Stats)
+	}
+
+	if stderr.String() != "" {
+		t.Errorf("stderr = %q, want stats only in the envelope", stderr.String())
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	t.Parallel()
+
+	for n, want := range map[int64]string{0: "0 B", 1023: "1023 B", 1536: "1.5 KiB", 5 << 20: "5.0 MiB"} {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
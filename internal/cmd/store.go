@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"os"
+	"net/url"
 
 	"github.com/gberlati/nube-cli/internal/api"
 	"github.com/gberlati/nube-cli/internal/outfmt"
@@ -13,7 +13,8 @@ import (
 
 // StoreCmd groups store-related commands.
 type StoreCmd struct {
-	Get StoreGetCmd `cmd:"" default:"withargs" help:"Show store information"`
+	Get       StoreGetCmd       `cmd:"" default:"withargs" help:"Show store information"`
+	AppStatus StoreAppStatusCmd `cmd:"" name:"app-status" help:"Check whether the app is still installed (exit 3 when its token was revoked)"`
 }
 
 // StoreGetCmd fetches store info from the API.
@@ -38,7 +39,7 @@ func (c *StoreGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, data)
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), data)
 	}
 
 	return writeResult(ctx, u,
@@ -50,6 +51,67 @@ func (c *StoreGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 	)
 }
 
+// Installation statuses reported by checkInstallation.
+const (
+	appInstalled = "installed"
+	appRevoked   = "revoked"
+	appSuspended = "suspended"
+)
+
+// checkInstallation tells whether client's token still works. A 401 means
+// the app was uninstalled or its token revoked, and a 402 that the store is
+// suspended; any other failure (network, 5xx) is returned, since it says
+// nothing about the installation.
+func checkInstallation(ctx context.Context, client *api.Client) (string, error) {
+	resp, err := client.Get(ctx, "store", url.Values{"fields": {"id"}}) //nolint:bodyclose // DecodeResponse closes body
+	if err == nil {
+		_, err = api.DecodeResponse[map[string]any](resp)
+	}
+
+	switch {
+	case err == nil:
+		return appInstalled, nil
+	case api.IsAuthError(err):
+		return appRevoked, nil
+	case api.IsPaymentRequiredError(err):
+		return appSuspended, nil
+	default:
+		return "", err
+	}
+}
+
+// StoreAppStatusCmd reports whether the active profile's token is still
+// installed on its store.
+type StoreAppStatusCmd struct{}
+
+func (c *StoreAppStatusCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	status, err := checkInstallation(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	if err := writeResult(ctx, u,
+		kv("store", activeStoreName(flags, client)),
+		kv("store_id", client.StoreID()),
+		kv("status", status),
+	); err != nil {
+		return err
+	}
+
+	if status == appRevoked {
+		return &ExitErr{Code: ExitAuthRequired}
+	}
+
+	return nil
+}
+
 // decodeList is a generic response decoder for paginated list endpoints.
 func decodeList(resp *http.Response) ([]map[string]any, error) {
 	return api.DecodeResponse[[]map[string]any](resp)
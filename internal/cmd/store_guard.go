@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"sync"
+
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// storeColors is the banner palette; each store keeps the same color across
+// runs so a glance tells stores apart.
+var storeColors = []string{"#3b82f6", "#a855f7", "#f59e0b", "#14b8a6", "#ec4899", "#84cc16", "#f97316", "#06b6d4"}
+
+// storeGuard protects against writing to the wrong store. With
+// --expect-store no request is sent unless the resolved store matches; with
+// confirm_store_banner the store is announced before the first write.
+type storeGuard struct {
+	flags  *RootFlags
+	banner bool
+	u      *ui.UI
+
+	mu        sync.Mutex
+	checked   bool
+	announced bool
+}
+
+func newStoreGuard(flags *RootFlags, u *ui.UI) *storeGuard {
+	g := &storeGuard{flags: flags, u: u}
+
+	if cfg, err := config.ReadConfig(); err == nil {
+		g.banner = cfg.ConfirmStoreBanner
+	}
+
+	return g
+}
+
+// active reports whether the guard has anything to do.
+func (g *storeGuard) active() bool {
+	return g.banner || g.flags.ExpectStore != ""
+}
+
+// check is an api.RequestGuard.
+func (g *storeGuard) check(method, _ string) error {
+	write := method != http.MethodGet && method != http.MethodHead
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.flags.ExpectStore != "" && !g.checked {
+		name, id := resolvedStore(g.flags)
+		if g.flags.ExpectStore != name && g.flags.ExpectStore != id {
+			return &ExitErr{Code: ExitMismatch, Err: fmt.Errorf("store %s does not match --expect-store %q",
+				describeStore(name, id), g.flags.ExpectStore)}
+		}
+
+		g.checked = true
+	}
+
+	if write && g.banner && !g.announced {
+		g.announced = true
+		name, id := resolvedStore(g.flags)
+		g.u.Err().Colorf(storeColor(id), "▶ Writing to store %s", describeStore(name, id))
+	}
+
+	return nil
+}
+
+// resolvedStore returns the profile name and store ID commands act on. name
+// is empty with NUBE_ACCESS_TOKEN; both are empty when no store resolves.
+func resolvedStore(flags *RootFlags) (name, id string) {
+	if flags.getenv("NUBE_ACCESS_TOKEN") != "" {
+		return "", flags.getenv("NUBE_USER_ID")
+	}
+
+	name, profile, err := flags.resolveStore(flags.Store)
+	if err != nil {
+		if flags.MockDir != "" {
+			return "", mockStoreID
+		}
+
+		return "", ""
+	}
+
+	return name, profile.StoreID
+}
+
+func describeStore(name, id string) string {
+	switch {
+	case name == "" && id == "":
+		return "(unresolved)"
+	case name == "":
+		return id
+	case id == "":
+		return name
+	default:
+		return fmt.Sprintf("%s (%s)", name, id)
+	}
+}
+
+func storeColor(id string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(id))
+
+	return storeColors[h.Sum32()%uint32(len(storeColors))]
+}
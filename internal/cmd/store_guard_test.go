@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestExpectStore(t *testing.T) {
+	tests := []struct {
+		expect   string
+		wantCode int
+	}{
+		{"shop", ExitOK},
+		{"123", ExitOK},
+		{"other", ExitMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expect, func(t *testing.T) {
+			setupCredStore(t, map[string]credstore.StoreProfile{"shop": {StoreID: "123", AccessToken: "tok"}}, "shop")
+
+			var requests atomic.Int32
+
+			setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				requests.Add(1)
+				_, _ = w.Write([]byte(`{"id":123}`))
+			}))
+
+			_ = captureStdout(t)
+
+			err := Execute([]string{"store", "get", "--expect-store", tt.expect})
+			if ExitCode(err) != tt.wantCode {
+				t.Fatalf("ExitCode = %d, want %d (err %v)", ExitCode(err), tt.wantCode, err)
+			}
+
+			if tt.wantCode != ExitOK && requests.Load() != 0 {
+				t.Errorf("requests = %d, want none after a mismatch", requests.Load())
+			}
+		})
+	}
+}
+
+func TestStoreBanner(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"shop": {StoreID: "123", AccessToken: "tok"}}, "shop")
+
+	if err := config.WriteConfig(config.File{ConfirmStoreBanner: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	var put map[string]any
+	setupMockAPIClient(t, customerPrivacyHandler(t, &put))
+
+	_ = captureStdout(t)
+	stderr := captureStderr(t)
+
+	if err := Execute([]string{"customer", "get", "200"}); err != nil {
+		t.Fatalf("customer get: %v", err)
+	}
+
+	if err := Execute([]string{"customer", "anonymize", "200", "--force"}); err != nil {
+		t.Fatalf("customer anonymize: %v", err)
+	}
+
+	if got := strings.Count(stderr.String(), "Writing to store shop (123)"); got != 1 {
+		t.Errorf("banner printed %d times, want once (for the write only); stderr = %q", got, stderr.String())
+	}
+}
+
+func TestDescribeStore(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, id, want string
+	}{
+		{"shop", "123", "shop (123)"},
+		{"", "123", "123"},
+		{"shop", "", "shop"},
+		{"", "", "(unresolved)"},
+	}
+
+	for _, tt := range tests {
+		if got := describeStore(tt.name, tt.id); got != tt.want {
+			t.Errorf("describeStore(%q, %q) = %q, want %q", tt.name, tt.id, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/config"
+)
+
+// storeInfoTTL is how long cached store settings are trusted. Currency,
+// country and domains practically never change, so one fetch a day is
+// plenty.
+const storeInfoTTL = 24 * time.Hour
+
+// storeInfo holds the store settings that shape how output is rendered.
+type storeInfo struct {
+	ID           string `json:"id"`
+	Country      string `json:"country,omitempty"`
+	MainCurrency string `json:"main_currency,omitempty"`
+	MainLanguage string `json:"main_language,omitempty"`
+	// MainDomain is the store's own domain when it has one, and
+	// OriginalDomain the platform subdomain every store has.
+	MainDomain     string    `json:"main_domain,omitempty"`
+	OriginalDomain string    `json:"original_domain,omitempty"`
+	FetchedAt      time.Time `json:"fetched_at"`
+}
+
+// loadStoreInfo returns the store's settings, from the cache in the data
+// directory while it is fresh and from the API otherwise.
+func loadStoreInfo(ctx context.Context, client *api.Client) (storeInfo, error) {
+	path, err := storeInfoPath(client.StoreID())
+	if err != nil {
+		return storeInfo{}, err
+	}
+
+	if info, ok := readStoreInfo(path); ok {
+		return info, nil
+	}
+
+	resp, err := client.Get(ctx, "store", nil) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return storeInfo{}, err
+	}
+
+	data, err := api.DecodeResponse[map[string]any](resp)
+	if err != nil {
+		return storeInfo{}, err
+	}
+
+	info := storeInfo{
+		ID:             client.StoreID(),
+		Country:        jsonStr(data, "country"),
+		MainCurrency:   jsonStr(data, "main_currency"),
+		MainLanguage:   jsonStr(data, "main_language"),
+		OriginalDomain: jsonStr(data, "original_domain"),
+		FetchedAt:      time.Now().UTC(),
+	}
+
+	if domains, _ := data["domains"].([]any); len(domains) > 0 {
+		info.MainDomain, _ = domains[0].(string)
+	}
+
+	// A failed cache write only costs another fetch next time.
+	_ = writeStoreInfo(path, info)
+
+	return info, nil
+}
+
+func storeInfoPath(storeID string) (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "stores", storeID+".json"), nil
+}
+
+func readStoreInfo(path string) (storeInfo, bool) {
+	b, err := os.ReadFile(path) //nolint:gosec // path is built from the data dir
+	if err != nil {
+		return storeInfo{}, false
+	}
+
+	var info storeInfo
+	if err := json.Unmarshal(b, &info); err != nil || time.Since(info.FetchedAt) > storeInfoTTL {
+		return storeInfo{}, false
+	}
+
+	return info, true
+}
+
+func writeStoreInfo(path string, info storeInfo) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create store cache dir: %w", err)
+	}
+
+	b, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("encode store info: %w", err)
+	}
+
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		return fmt.Errorf("write store info: %w", err)
+	}
+
+	return nil
+}
+
+// domain returns the domain the storefront is served on.
+func (s storeInfo) domain() string {
+	if s.MainDomain != "" {
+		return s.MainDomain
+	}
+
+	return s.OriginalDomain
+}
@@ -85,3 +85,38 @@ func TestStoreGet_Human(t *testing.T) {
 		t.Errorf("output = %q, want containing 'Mi Tienda'", output)
 	}
 }
+
+func TestStoreAppStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		want   string
+		code   int
+	}{
+		{"installed", http.StatusOK, "installed", ExitOK},
+		{"revoked", http.StatusUnauthorized, "revoked", ExitAuthRequired},
+		{"suspended", http.StatusPaymentRequired, "suspended", ExitOK},
+		{"server error", http.StatusBadGateway, "", ExitRetryable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"id":123}`))
+			}))
+
+			stdout := captureStdout(t)
+			_ = captureStderr(t)
+
+			err := Execute([]string{"store", "app-status", "--json"})
+			if code := ExitCode(err); code != tt.code {
+				t.Fatalf("exit code = %d, want %d (err %v)", code, tt.code, err)
+			}
+
+			if tt.want != "" && !strings.Contains(stdout.String(), `"status": "`+tt.want+`"`) {
+				t.Errorf("output = %s, want status %s", stdout.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gberlati/nube-cli/internal/api"
+)
+
+// storefrontLinks builds the public URL of a product. The store's domains
+// are only looked up for products that don't carry a canonical_url, and at
+// most once per command.
+type storefrontLinks struct {
+	info func() (storeInfo, error)
+}
+
+func newStorefrontLinks(ctx context.Context, client *api.Client) *storefrontLinks {
+	return &storefrontLinks{info: sync.OnceValues(func() (storeInfo, error) {
+		info, err := loadStoreInfo(ctx, client)
+		if err != nil {
+			slog.DebugContext(ctx, "store domains unavailable; no storefront URLs", "err", err)
+		}
+
+		return info, err
+	})}
+}
+
+// product returns p's storefront URL, or "" when it can't be worked out.
+func (l *storefrontLinks) product(p map[string]any) string {
+	if u := jsonStr(p, "canonical_url"); u != "" {
+		return u
+	}
+
+	if extractI18n(p, "handle") == "" {
+		return ""
+	}
+
+	info, err := l.info()
+	if err != nil {
+		return ""
+	}
+
+	domain := info.domain()
+	if domain == "" {
+		return ""
+	}
+
+	handle := extractI18n(p, "handle")
+	if m, ok := p["handle"].(map[string]any); ok {
+		if h, ok := m[info.MainLanguage].(string); ok && h != "" {
+			handle = h
+		}
+	}
+
+	// Portuguese storefronts serve products under /produtos/.
+	segment := "productos"
+	if strings.HasPrefix(info.MainLanguage, "pt") {
+		segment = "produtos"
+	}
+
+	return "https://" + domain + "/" + segment + "/" + url.PathEscape(handle) + "/"
+}
+
+// withStorefrontURL returns p with a storefront_url field when one is known.
+func (l *storefrontLinks) withStorefrontURL(p map[string]any) map[string]any {
+	if u := l.product(p); u != "" {
+		p["storefront_url"] = u
+	}
+
+	return p
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestStorefrontLinks_Product(t *testing.T) {
+	t.Parallel()
+
+	own := storeInfo{MainLanguage: "pt", MainDomain: "www.loja.com.br", OriginalDomain: "loja.lojavirtualnuvem.com.br"}
+	sub := storeInfo{MainLanguage: "es", OriginalDomain: "tienda.mitiendanube.com"}
+
+	tests := []struct {
+		name string
+		info storeInfo
+		p    map[string]any
+		want string
+	}{
+		{"canonical url wins", own, map[string]any{"canonical_url": "https://x.test/p/", "handle": "a"}, "https://x.test/p/"},
+		{"own domain, main language handle", own, map[string]any{"handle": map[string]any{"es": "remera", "pt": "camiseta"}}, "https://www.loja.com.br/produtos/camiseta/"},
+		{"platform subdomain", sub, map[string]any{"handle": map[string]any{"es": "remera"}}, "https://tienda.mitiendanube.com/productos/remera/"},
+		{"no handle", sub, map[string]any{"id": 1}, ""},
+		{"no domain", storeInfo{MainLanguage: "es"}, map[string]any{"handle": "remera"}, ""},
+	}
+
+	for _, tt := range tests {
+		l := &storefrontLinks{info: func() (storeInfo, error) { return tt.info, nil }}
+		if got := l.product(tt.p); got != tt.want {
+			t.Errorf("%s: product() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestProductList_StorefrontURL(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var storeGets atomic.Int32
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/123/store":
+			storeGets.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id": 123, "main_language": "es",
+				"original_domain": "tienda.mitiendanube.com", "domains": []any{"www.tienda.com.ar"},
+			})
+		case "/v1/123/products":
+			_ = json.NewEncoder(w).Encode([]map[string]any{
+				{"id": 1, "handle": map[string]any{"es": "remera"}},
+				{"id": 2, "handle": map[string]any{"es": "gorra"}},
+			})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	buf := captureStdout(t)
+	if err := Execute([]string{"product", "list", "--page", "1", "--json"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	var got []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if len(got) != 2 || got[1]["storefront_url"] != "https://www.tienda.com.ar/productos/gorra/" {
+		t.Errorf("products = %v", got)
+	}
+
+	buf = captureStdout(t)
+	if err := Execute([]string{"product", "list", "--page", "1", "--columns", "id,url"}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if out := buf.String(); !strings.Contains(out, "https://www.tienda.com.ar/productos/remera/") {
+		t.Errorf("table = %q, want the url column", out)
+	}
+
+	if n := storeGets.Load(); n != 1 {
+		t.Errorf("store fetched %d times, want 1 (cached)", n)
+	}
+}
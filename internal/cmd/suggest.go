@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/errfmt"
+	"github.com/gberlati/nube-cli/internal/i18n"
+)
+
+// withSuggestion names the store profile in the login suggestion of a 401
+// or 403, and for a 403 the scopes the command needs, which errfmt can't
+// know.
+func withSuggestion(err error, tr *i18n.Translator, kctx *kong.Context, flags *RootFlags) error {
+	var (
+		authErr *api.AuthError
+		permErr *api.PermissionDeniedError
+	)
+
+	denied := errors.As(err, &permErr)
+	if !denied && !errors.As(err, &authErr) {
+		return err
+	}
+
+	// A token from the environment isn't renewed by logging in.
+	if flags.getenv("NUBE_ACCESS_TOKEN") != "" {
+		return err
+	}
+
+	name, _, resolveErr := flags.resolveStore(flags.Store)
+	if resolveErr != nil {
+		return err
+	}
+
+	s := errfmt.SuggestionIn(tr, err)
+	s.Command = "nube login " + name
+
+	if denied && kctx.Selected() != nil {
+		if scopes := commandAPI[schemaPath(kctx.Selected())].Scopes; len(scopes) > 0 {
+			s.Reason = tr.Sprintf("authorize the store again and grant the app %s", strings.Join(scopes, ", "))
+		}
+	}
+
+	return errfmt.WithSuggestion(err, *s)
+}
@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/mirror"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// SyncCmd groups commands that keep the store and other systems in step.
+type SyncCmd struct {
+	Mirror SyncMirrorCmd `cmd:"" default:"withargs" help:"Update a local SQLite mirror of products, orders and customers"`
+	Stock  SyncStockCmd  `cmd:"" help:"Push stock levels by SKU from an ERP file or endpoint"`
+}
+
+// SyncMirrorCmd updates the local SQLite mirror that nube sql queries. After
+// the first full pull, only what changed since the previous sync is fetched.
+type SyncMirrorCmd struct {
+	DB        string `help:"Mirror database file (default: one per store in the data directory)" name:"db" type:"path"`
+	Resources string `help:"Comma-separated resources to mirror (products,orders,customers)" default:"products,orders,customers"`
+	Full      bool   `help:"Fetch everything again and drop what was deleted from the store, instead of only changes" name:"full"`
+}
+
+// syncResult is what one resource's sync did.
+type syncResult struct {
+	Fetched int    `json:"fetched"`
+	Pruned  int    `json:"pruned"`
+	Rows    int    `json:"rows"`
+	Mode    string `json:"mode"`
+}
+
+func (c *SyncMirrorCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	names, err := parseMirrorResources(c.Resources)
+	if err != nil {
+		return err
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	path, err := c.path(client.StoreID())
+	if err != nil {
+		return err
+	}
+
+	db, err := mirror.Open(ctx, path, mirror.Options{StoreID: client.StoreID(), Translate: extractI18n})
+	if errors.Is(err, mirror.ErrOtherStore) {
+		return usagef("%s: %v; pass another --db", path, err)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = db.Close() }()
+
+	// Rows a full sync doesn't stamp with this time were deleted upstream.
+	syncedAt := time.Now().UTC().Format(time.RFC3339Nano)
+	results := make(map[string]syncResult, len(names))
+
+	for _, r := range names {
+		res, err := c.syncResource(ctx, client, db, r, syncedAt)
+		if err != nil {
+			return fmt.Errorf("sync %s: %w", r, err)
+		}
+
+		results[r] = res
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{"db": path, "resources": results})
+	}
+
+	for _, r := range names {
+		res := results[r]
+		u.Err().Printf("%s: %d fetched (%s), %d removed, %d rows", r, res.Fetched, res.Mode, res.Pruned, res.Rows)
+	}
+
+	return writeResult(ctx, u, kv("db", path))
+}
+
+// syncResource pulls one resource into db. The cursor only moves once every
+// page is stored, so an interrupted sync is simply repeated.
+func (c *SyncMirrorCmd) syncResource(ctx context.Context, client *api.Client, db *mirror.DB, resource, syncedAt string) (syncResult, error) {
+	cursor, err := db.Cursor(ctx, resource)
+	if err != nil {
+		return syncResult{}, err
+	}
+
+	full := c.Full || cursor == ""
+	res := syncResult{Mode: "changes since " + cursor}
+
+	q := url.Values{"per_page": {"200"}}
+	if full {
+		res.Mode = "full"
+	} else {
+		q.Set("updated_at_min", cursor)
+	}
+
+	latest := cursor
+
+	for page, err := range api.Pages[map[string]any](ctx, client, resource, q) {
+		if err != nil {
+			return syncResult{}, err
+		}
+
+		last, err := db.Upsert(ctx, resource, page.Items, syncedAt)
+		if err != nil {
+			return syncResult{}, err
+		}
+
+		latest = max(latest, last)
+		res.Fetched += len(page.Items)
+	}
+
+	if full {
+		if res.Pruned, err = db.Prune(ctx, resource, syncedAt); err != nil {
+			return syncResult{}, err
+		}
+	}
+
+	if err := db.Finish(ctx, resource, latest, syncedAt); err != nil {
+		return syncResult{}, err
+	}
+
+	if res.Rows, err = db.Count(ctx, resource); err != nil {
+		return syncResult{}, err
+	}
+
+	return res, nil
+}
+
+func (c *SyncMirrorCmd) path(storeID string) (string, error) {
+	if c.DB != "" {
+		return c.DB, nil
+	}
+
+	return mirrorPath(storeID)
+}
+
+// SQLCmd runs a read-only SQL query against the mirror nube sync keeps.
+type SQLCmd struct {
+	Query string `arg:"" name:"query" help:"SQL to run, e.g. \"SELECT status, COUNT(*) FROM orders GROUP BY status\""`
+	DB    string `help:"Mirror database file (default: the active store's mirror from nube sync)" name:"db" type:"path"`
+}
+
+func (c *SQLCmd) Run(ctx context.Context, flags *RootFlags) error {
+	path := c.DB
+	if path == "" {
+		client, err := newAPIClient(flags)
+		if err != nil {
+			return err
+		}
+
+		if path, err = mirrorPath(client.StoreID()); err != nil {
+			return err
+		}
+	}
+
+	db, err := mirror.OpenReadOnly(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return usagef("no mirror at %s; run 'nube sync' first", path)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = db.Close() }()
+
+	cols, rows, err := db.Query(ctx, c.Query)
+	if err != nil {
+		return newUsageError(err)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		items := make([]map[string]any, 0, len(rows))
+
+		for _, row := range rows {
+			item := make(map[string]any, len(cols))
+			for i, col := range cols {
+				item[col] = row[i]
+			}
+
+			items = append(items, item)
+		}
+
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), items)
+	}
+
+	w, done := tableWriter(ctx)
+	defer done()
+
+	_, _ = fmt.Fprintln(w, strings.Join(cols, "\t"))
+
+	cells := make([]string, len(cols))
+
+	for _, row := range rows {
+		for i, v := range row {
+			cells[i] = sqlCell(v)
+		}
+
+		_, _ = fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+
+	return nil
+}
+
+// sqlCell renders a query value for a table; NULL is empty.
+func sqlCell(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case time.Time:
+		return x.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+func parseMirrorResources(s string) ([]string, error) {
+	var names []string
+
+	for _, n := range strings.Split(s, ",") {
+		n = strings.TrimSpace(n)
+		if n == "" {
+			continue
+		}
+
+		if !slices.Contains(mirror.Resources, n) {
+			return nil, usagef("unknown resource %q (want products, orders, or customers)", n)
+		}
+
+		if !slices.Contains(names, n) {
+			names = append(names, n)
+		}
+	}
+
+	if len(names) == 0 {
+		return nil, usagef("--resources is empty")
+	}
+
+	return names, nil
+}
+
+func mirrorPath(storeID string) (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "mirror", storeID+".db"), nil
+}
@@ -0,0 +1,599 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// SyncStockCmd reads SKU stock levels from a CSV file or an HTTP endpoint,
+// typically exported by an ERP, and updates the variants whose stock in the
+// store differs. With --watch it repeats every --interval until stopped.
+// Updates go through the journal like any other write, but are only
+// snapshotted for undo with --snapshot.
+type SyncStockCmd struct {
+	SnapshotFlags `embed:""`
+
+	Source       string        `help:"Where stock levels come from: file or http" name:"source" enum:"file,http" required:""`
+	From         string        `help:"CSV file (--source file) or URL (--source http) with sku,stock rows" name:"from" required:""`
+	Header       []string      `help:"HTTP header for --source http, e.g. 'Authorization: Bearer x' (repeatable)" name:"header" sep:"none"`
+	Watch        bool          `help:"Keep running, syncing every --interval until interrupted" name:"watch"`
+	Interval     time.Duration `help:"Time between syncs with --watch" name:"interval" default:"5m"`
+	HealthListen string        `help:"Serve sync health as JSON on this address with --watch, e.g. 127.0.0.1:8787" name:"health-listen"`
+	Parallel     int           `help:"Variants updated in parallel" name:"parallel" default:"4"`
+}
+
+// stockLevel is a variant's stock; nil is unlimited (stock not tracked).
+type stockLevel = *int64
+
+// stockChange is one variant whose store stock differs from the source.
+type stockChange struct {
+	SKU       string `json:"sku"`
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id"`
+	Old       string `json:"old"`
+	New       string `json:"new"`
+	Error     string `json:"error,omitempty"`
+}
+
+// stockCycle is the outcome of one sync.
+type stockCycle struct {
+	At        time.Time     `json:"at"`
+	Rows      int           `json:"rows"`
+	Changes   []stockChange `json:"changes"`
+	Updated   int           `json:"updated"`
+	Failed    int           `json:"failed"`
+	Unknown   []string      `json:"unknown_skus,omitempty"`
+	Ambiguous []string      `json:"ambiguous_skus,omitempty"`
+	DryRun    bool          `json:"dry_run,omitempty"`
+}
+
+func (c *SyncStockCmd) Run(ctx context.Context, flags *RootFlags) error {
+	ctx = c.historyContext(ctx)
+
+	if c.Interval <= 0 {
+		return usagef("--interval must be positive")
+	}
+
+	if c.Parallel < 1 {
+		return usagef("--parallel must be at least 1")
+	}
+
+	if c.HealthListen != "" && !c.Watch {
+		return usagef("--health-listen requires --watch")
+	}
+
+	if c.Watch {
+		if err := refuseNested(ctx, "sync stock --watch"); err != nil {
+			return err
+		}
+	}
+
+	headers, err := parseStockHeaders(c.Header)
+	if err != nil {
+		return err
+	}
+
+	if c.Source == "http" {
+		if err := validateStockURL(c.From); err != nil {
+			return err
+		}
+	} else if len(headers) > 0 {
+		return usagef("--header only applies to --source http")
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	u := ui.FromContext(ctx)
+
+	if !c.Watch {
+		cycle, err := c.sync(ctx, flags, client, headers)
+		if err != nil {
+			return err
+		}
+
+		return c.report(ctx, u, flags, cycle, true)
+	}
+
+	health := &stockHealth{interval: c.Interval}
+
+	if c.HealthListen != "" {
+		stop, err := health.serve(ctx, c.HealthListen)
+		if err != nil {
+			return err
+		}
+
+		defer stop()
+
+		u.Err().Printf("health on http://%s/healthz", c.HealthListen)
+	}
+
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	// A --watch run outlives bad files and ERP outages: failures are logged,
+	// reported on the health endpoint and retried at the next tick.
+	for {
+		cycle, err := c.sync(ctx, flags, client, headers)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		health.record(cycle, err)
+
+		if err != nil {
+			slog.WarnContext(ctx, "stock sync failed", "err", err)
+		} else if len(cycle.Changes) > 0 || len(cycle.Unknown) > 0 || len(cycle.Ambiguous) > 0 {
+			_ = c.report(ctx, u, flags, cycle, false)
+		} else {
+			slog.DebugContext(ctx, "stock sync: no changes", "rows", cycle.Rows)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// sync runs one cycle: read the source, read the catalog, update the
+// variants that differ.
+func (c *SyncStockCmd) sync(ctx context.Context, flags *RootFlags, client *api.Client, headers http.Header) (stockCycle, error) {
+	cycle := stockCycle{At: time.Now().UTC(), DryRun: flags.DryRun}
+
+	levels, err := c.read(ctx, flags.Timeout, headers)
+	if err != nil {
+		return cycle, err
+	}
+
+	cycle.Rows = len(levels)
+
+	q := url.Values{"fields": {"id,variants"}, "per_page": {"200"}}
+
+	products, err := api.CollectAllPages(ctx, client, "products", q, decodeList)
+	if err != nil {
+		return cycle, err
+	}
+
+	cycle.Changes, cycle.Unknown, cycle.Ambiguous = planStockChanges(products, levels)
+
+	if flags.DryRun || len(cycle.Changes) == 0 {
+		return cycle, nil
+	}
+
+	pool := api.NewPool(api.WithWorkers(c.Parallel))
+	jobs := make([]api.Job, len(cycle.Changes))
+
+	for i, ch := range cycle.Changes {
+		jobs[i] = func(ctx context.Context) error {
+			body, err := jsonBody(map[string]any{"stock": levels[ch.SKU]})
+			if err != nil {
+				return err
+			}
+
+			resp, err := client.Put(ctx, "products/"+ch.ProductID+"/variants/"+ch.VariantID, body) //nolint:bodyclose // decodeOptionalJSON closes body
+			if err != nil {
+				return err
+			}
+
+			_, err = decodeOptionalJSON(resp)
+
+			return err
+		}
+	}
+
+	for i, err := range pool.Run(ctx, jobs) {
+		if err != nil {
+			cycle.Changes[i].Error = err.Error()
+			cycle.Failed++
+		} else {
+			cycle.Updated++
+		}
+	}
+
+	return cycle, nil
+}
+
+// read fetches and parses the source.
+func (c *SyncStockCmd) read(ctx context.Context, timeout time.Duration, headers http.Header) (map[string]stockLevel, error) {
+	if c.Source == "file" {
+		b, err := os.ReadFile(c.From)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", c.From, err)
+		}
+
+		return parseStockCSV(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.From, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+
+	req.Header = headers.Clone()
+
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch stock: %w", unwrapURLError(err))
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return nil, fmt.Errorf("fetch stock: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("fetch stock: HTTP %d", resp.StatusCode)
+	}
+
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt == "application/json" {
+		return parseStockJSON(b)
+	}
+
+	return parseStockCSV(b)
+}
+
+func (c *SyncStockCmd) report(ctx context.Context, u *ui.UI, flags *RootFlags, cycle stockCycle, once bool) error {
+	for _, sku := range cycle.Unknown {
+		u.Err().Printf("SKU %s is not in the store", sku)
+	}
+
+	for _, sku := range cycle.Ambiguous {
+		u.Err().Printf("SKU %s is on several variants; skipped", sku)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, stdoutFrom(ctx), cycle); err != nil {
+			return err
+		}
+	} else {
+		if once || flags.DryRun {
+			writeStockChanges(ctx, cycle.Changes)
+		}
+
+		if !flags.DryRun {
+			if err := writeResult(ctx, u, kv("at", cycle.At.Format(time.RFC3339)), kv("updated", cycle.Updated), kv("failed", cycle.Failed)); err != nil {
+				return err
+			}
+		}
+
+		for _, ch := range cycle.Changes {
+			if ch.Error != "" {
+				u.Err().Printf("SKU %s (variant %s of product %s): %s", ch.SKU, ch.VariantID, ch.ProductID, ch.Error)
+			}
+		}
+	}
+
+	if once && cycle.Failed > 0 {
+		return fmt.Errorf("%d of %d stock updates failed", cycle.Failed, len(cycle.Changes))
+	}
+
+	return nil
+}
+
+// planStockChanges matches source SKUs to variants and returns the variants
+// whose stock differs, in catalog order, plus the source SKUs no variant has
+// and those several variants share (which are left alone).
+func planStockChanges(products []map[string]any, levels map[string]stockLevel) (changes []stockChange, unknown, ambiguous []string) {
+	var candidates []stockChange
+
+	seen := map[string]int{}
+
+	for _, p := range products {
+		variants, _ := p["variants"].([]any)
+
+		for _, v := range variants {
+			m, _ := v.(map[string]any)
+
+			sku := jsonStr(m, "sku")
+
+			want, ok := levels[sku]
+			if sku == "" || !ok {
+				continue
+			}
+
+			seen[sku]++
+
+			old := formatStock(variantStockLevel(m))
+			if old == formatStock(want) {
+				continue
+			}
+
+			candidates = append(candidates, stockChange{
+				SKU:       sku,
+				ProductID: jsonStr(p, "id"),
+				VariantID: jsonStr(m, "id"),
+				Old:       old,
+				New:       formatStock(want),
+			})
+		}
+	}
+
+	for _, ch := range candidates {
+		if seen[ch.SKU] == 1 {
+			changes = append(changes, ch)
+		}
+	}
+
+	for sku := range levels {
+		switch seen[sku] {
+		case 0:
+			unknown = append(unknown, sku)
+		case 1:
+		default:
+			ambiguous = append(ambiguous, sku)
+		}
+	}
+
+	slices.Sort(unknown)
+	slices.Sort(ambiguous)
+
+	return changes, unknown, ambiguous
+}
+
+func variantStockLevel(variant map[string]any) stockLevel {
+	n, ok := variant["stock"].(float64)
+	if !ok {
+		return nil
+	}
+
+	s := int64(n)
+
+	return &s
+}
+
+func formatStock(s stockLevel) string {
+	if s == nil {
+		return "unlimited"
+	}
+
+	return strconv.FormatInt(*s, 10)
+}
+
+// parseStockValue reads a stock cell: a count of zero or more, or
+// "unlimited" to stop tracking stock.
+func parseStockValue(s string) (stockLevel, bool) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "unlimited") {
+		return nil, true
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return nil, false
+	}
+
+	return &n, true
+}
+
+// parseStockCSV reads sku,stock rows, skipping a header row. Any bad row
+// rejects the whole file, since a half-written export would otherwise zero
+// out or skip stock.
+func parseStockCSV(b []byte) (map[string]stockLevel, error) {
+	r := csv.NewReader(bytes.NewReader(b))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	levels := map[string]stockLevel{}
+
+	for line := 1; ; line++ {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, usagef("csv: %v", err)
+		}
+
+		if line == 1 && strings.EqualFold(strings.TrimSpace(rec[0]), "sku") {
+			continue
+		}
+
+		if len(rec) != 2 || strings.TrimSpace(rec[0]) == "" {
+			return nil, usagef("csv line %d: want sku,stock", line)
+		}
+
+		stock, ok := parseStockValue(rec[1])
+		if !ok {
+			return nil, usagef("csv line %d: stock %q, want a count or unlimited", line, rec[1])
+		}
+
+		levels[strings.TrimSpace(rec[0])] = stock
+	}
+
+	if len(levels) == 0 {
+		return nil, usagef("csv has no rows")
+	}
+
+	return levels, nil
+}
+
+// parseStockJSON reads [{"sku": "...", "stock": 5}, ...]; a null stock is
+// unlimited.
+func parseStockJSON(b []byte) (map[string]stockLevel, error) {
+	var rows []struct {
+		SKU   string          `json:"sku"`
+		Stock json.RawMessage `json:"stock"`
+	}
+
+	if err := json.Unmarshal(b, &rows); err != nil {
+		return nil, fmt.Errorf("stock json: %w", err)
+	}
+
+	levels := make(map[string]stockLevel, len(rows))
+
+	for i, row := range rows {
+		raw := strings.Trim(string(row.Stock), `"`)
+		if raw == "null" {
+			raw = "unlimited"
+		}
+
+		stock, ok := parseStockValue(raw)
+		if row.SKU == "" || !ok {
+			return nil, fmt.Errorf("stock json item %d: want a sku and a stock count, unlimited or null", i)
+		}
+
+		levels[row.SKU] = stock
+	}
+
+	if len(levels) == 0 {
+		return nil, errors.New("stock json has no items")
+	}
+
+	return levels, nil
+}
+
+// parseStockHeaders reads --header "Name: value" flags.
+func parseStockHeaders(values []string) (http.Header, error) {
+	h := http.Header{}
+
+	for _, f := range values {
+		name, value, ok := strings.Cut(f, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, usagef("--header %q: want 'Name: value'", f)
+		}
+
+		h.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	return h, nil
+}
+
+func validateStockURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return usagef("--from %q: want an http(s) URL", s)
+	}
+
+	return nil
+}
+
+func writeStockChanges(ctx context.Context, changes []stockChange) {
+	w, done := tableWriter(ctx)
+	defer done()
+
+	_, _ = fmt.Fprintln(w, "SKU\tPRODUCT\tVARIANT\tOLD\tNEW")
+
+	for _, ch := range changes {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", ch.SKU, ch.ProductID, ch.VariantID, ch.Old, ch.New)
+	}
+}
+
+// stockHealth is what the --watch health endpoint reports.
+type stockHealth struct {
+	mu          sync.Mutex
+	interval    time.Duration
+	cycles      int
+	lastRun     time.Time
+	lastSuccess time.Time
+	lastError   string
+	updated     int
+	failed      int
+}
+
+func (h *stockHealth) record(cycle stockCycle, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.cycles++
+	h.lastRun = cycle.At
+	h.lastError = ""
+
+	if err != nil {
+		h.lastError = err.Error()
+
+		return
+	}
+
+	h.updated += cycle.Updated
+	h.failed += cycle.Failed
+
+	if cycle.Failed == 0 {
+		h.lastSuccess = cycle.At
+	} else {
+		h.lastError = fmt.Sprintf("%d stock updates failed", cycle.Failed)
+	}
+}
+
+// ServeHTTP answers 200 while the last sync succeeded and is recent enough,
+// 503 otherwise, so process monitors can restart or alert.
+func (h *stockHealth) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	h.mu.Lock()
+
+	healthy := h.lastError == "" && !h.lastSuccess.IsZero() && time.Since(h.lastSuccess) < 3*h.interval
+	status := map[string]any{
+		"ok":      healthy,
+		"cycles":  h.cycles,
+		"updated": h.updated,
+		"failed":  h.failed,
+	}
+
+	if !h.lastRun.IsZero() {
+		status["last_run"] = h.lastRun
+	}
+
+	if !h.lastSuccess.IsZero() {
+		status["last_success"] = h.lastSuccess
+	}
+
+	if h.lastError != "" {
+		status["last_error"] = h.lastError
+	}
+
+	h.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if !healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+// serve listens on addr and returns a function stopping the server.
+func (h *stockHealth) serve(ctx context.Context, addr string) (func(), error) {
+	ln, err := (&net.ListenConfig{}).Listen(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /healthz", h)
+
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.WarnContext(ctx, "sync stock: health listener stopped", "err", err)
+		}
+	}()
+
+	return func() { _ = srv.Close() }, nil
+}
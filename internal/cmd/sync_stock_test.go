@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const stockCatalog = `[
+	{"id":1,"variants":[{"id":11,"sku":"R-S","stock":5},{"id":12,"sku":"R-M","stock":3}]},
+	{"id":2,"variants":[{"id":21,"sku":"B-1","stock":null},{"id":22,"sku":"DUP","stock":1}]},
+	{"id":3,"variants":[{"id":31,"sku":"DUP","stock":1}]}]`
+
+func TestSyncStock_File(t *testing.T) {
+	setupConfigDir(t)
+
+	var (
+		mu   sync.Mutex
+		puts = map[string]string{}
+	)
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			var body map[string]json.RawMessage
+			_ = json.NewDecoder(r.Body).Decode(&body)
+
+			mu.Lock()
+			puts[strings.TrimPrefix(r.URL.Path, "/v1/123/")] = string(body["stock"])
+			mu.Unlock()
+
+			_, _ = w.Write([]byte(`{}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(stockCatalog))
+	}))
+
+	path := filepath.Join(t.TempDir(), "stock.csv")
+	if err := os.WriteFile(path, []byte("sku,stock\nR-S,5\nR-M,0\nB-1,7\nDUP,4\nGONE,2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = captureStderr(t)
+	out := captureStdout(t)
+
+	if err := Execute([]string{"--json", "sync", "stock", "--source", "file", "--from", path}); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	want := map[string]string{"products/1/variants/12": "0", "products/2/variants/21": "7"}
+	if len(puts) != len(want) {
+		t.Fatalf("puts = %v", puts)
+	}
+
+	for p, stock := range want {
+		if puts[p] != stock {
+			t.Errorf("PUT %s stock = %s, want %s", p, puts[p], stock)
+		}
+	}
+
+	var got stockCycle
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, out.String())
+	}
+
+	if got.Updated != 2 || got.Rows != 5 || strings.Join(got.Unknown, ",") != "GONE" || strings.Join(got.Ambiguous, ",") != "DUP" {
+		t.Errorf("cycle = %+v", got)
+	}
+}
+
+func TestSyncStock_HTTPDryRun(t *testing.T) {
+	setupConfigDir(t)
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("dry run sent %s %s", r.Method, r.URL.Path)
+		}
+
+		_, _ = w.Write([]byte(stockCatalog))
+	}))
+
+	erp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer k" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = w.Write([]byte(`[{"sku":"R-S","stock":9},{"sku":"R-M","stock":null}]`))
+	}))
+	t.Cleanup(erp.Close)
+
+	_ = captureStderr(t)
+	out := captureStdout(t)
+
+	err := Execute([]string{"--json", "--dry-run", "sync", "stock", "--source", "http", "--from", erp.URL, "--header", "Authorization: Bearer k"})
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	var got stockCycle
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, out.String())
+	}
+
+	if !got.DryRun || len(got.Changes) != 2 || got.Changes[0].New != "9" || got.Changes[1].New != "unlimited" {
+		t.Errorf("cycle = %+v", got)
+	}
+}
+
+func TestParseStockCSV_RejectsBadRows(t *testing.T) {
+	t.Parallel()
+
+	for _, in := range []string{"", "sku,stock\n", "A,1\nB,-2\n", "A,1\nB\n", "A,lots\n"} {
+		if _, err := parseStockCSV([]byte(in)); ExitCode(err) != ExitUsage {
+			t.Errorf("parseStockCSV(%q) err = %v, want usage error", in, err)
+		}
+	}
+
+	levels, err := parseStockCSV([]byte("A, 3\nB,unlimited\n"))
+	if err != nil || formatStock(levels["A"]) != "3" || formatStock(levels["B"]) != "unlimited" {
+		t.Errorf("levels = %v, err = %v", levels, err)
+	}
+}
+
+func TestStockHealth(t *testing.T) {
+	t.Parallel()
+
+	h := &stockHealth{interval: time.Minute}
+
+	status := func() int {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		return rec.Code
+	}
+
+	if got := status(); got != http.StatusServiceUnavailable {
+		t.Errorf("before first sync = %d", got)
+	}
+
+	h.record(stockCycle{At: time.Now(), Updated: 2}, nil)
+
+	if got := status(); got != http.StatusOK {
+		t.Errorf("after sync = %d", got)
+	}
+
+	h.record(stockCycle{At: time.Now()}, os.ErrNotExist)
+
+	if got := status(); got != http.StatusServiceUnavailable {
+		t.Errorf("after failure = %d", got)
+	}
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestSync_IncrementalAndSQL(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var (
+		mu         sync.Mutex
+		updatedMin []string
+		products   = `[{"id":1,"name":{"es":"Remera"},"updated_at":"2024-06-01T10:00:00+0000"},{"id":2,"name":{"es":"Zapato"},"updated_at":"2024-06-02T10:00:00+0000"}]`
+	)
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch strings.TrimPrefix(r.URL.Path, "/v1/123/") {
+		case "products":
+			updatedMin = append(updatedMin, r.URL.Query().Get("updated_at_min"))
+			_, _ = w.Write([]byte(products))
+		case "orders":
+			_, _ = w.Write([]byte(`[{"id":7,"number":1001,"status":"open","total":"150.50","customer":{"id":3},"updated_at":"2024-06-03T10:00:00+0000"}]`))
+		case "customers":
+			_, _ = w.Write([]byte(`[]`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+
+	_ = captureStderr(t)
+
+	runSync := func(args ...string) map[string]syncResult {
+		t.Helper()
+
+		out := captureStdout(t)
+		if err := Execute(append([]string{"--json", "sync"}, args...)); err != nil {
+			t.Fatalf("sync %v: %v", args, err)
+		}
+
+		var res struct {
+			Resources map[string]syncResult `json:"resources"`
+		}
+		if err := json.Unmarshal(out.Bytes(), &res); err != nil {
+			t.Fatalf("unmarshal: %v\n%s", err, out.String())
+		}
+
+		return res.Resources
+	}
+
+	if res := runSync(); res["products"].Rows != 2 || res["orders"].Rows != 1 || res["products"].Mode != "full" {
+		t.Fatalf("first sync = %+v", res)
+	}
+
+	// Product 2 is gone: an incremental sync keeps it, a full one drops it.
+	products = `[{"id":1,"name":{"es":"Remera lisa"},"updated_at":"2024-06-05T10:00:00+0000"}]`
+
+	if res := runSync("--resources", "products"); res["products"].Rows != 2 {
+		t.Errorf("incremental sync = %+v", res)
+	}
+
+	if res := runSync("--resources", "products", "--full"); res["products"].Pruned != 1 || res["products"].Rows != 1 {
+		t.Errorf("full sync = %+v", res)
+	}
+
+	if want := []string{"", "2024-06-02T10:00:00+0000", ""}; strings.Join(updatedMin, ",") != strings.Join(want, ",") {
+		t.Errorf("updated_at_min = %q, want %q", updatedMin, want)
+	}
+
+	out := captureStdout(t)
+	if err := Execute([]string{"--json", "sql", "SELECT p.name, o.total FROM products p, orders o"}); err != nil {
+		t.Fatalf("sql: %v", err)
+	}
+
+	var rows []map[string]any
+	if err := json.Unmarshal(out.Bytes(), &rows); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, out.String())
+	}
+
+	if len(rows) != 1 || rows[0]["name"] != "Remera lisa" || rows[0]["total"] != 150.5 {
+		t.Errorf("rows = %v", rows)
+	}
+
+	if err := Execute([]string{"sql", "DELETE FROM orders"}); ExitCode(err) != ExitUsage {
+		t.Errorf("write query: exit code = %d, want %d", ExitCode(err), ExitUsage)
+	}
+}
+
+func TestSQL_NoMirror(t *testing.T) {
+	setupConfigDir(t)
+
+	_ = captureStdout(t)
+	_ = captureStderr(t)
+
+	if err := Execute([]string{"sql", "SELECT 1", "--db", t.TempDir() + "/none.db"}); ExitCode(err) != ExitUsage {
+		t.Errorf("exit code = %d, want %d", ExitCode(err), ExitUsage)
+	}
+}
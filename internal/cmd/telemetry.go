@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/telemetry"
+)
+
+// Counters exported with OTEL_EXPORTER_OTLP_ENDPOINT set.
+var (
+	commandRuns = telemetry.Counter{
+		Name: "nube.command.runs", Unit: "{run}",
+		Description: "Command runs, by command and exit code",
+	}
+	httpRequests = telemetry.Counter{
+		Name: "nube.http.requests", Unit: "{request}",
+		Description: "API requests, by method and final status; retries count once",
+	}
+	httpRetries = telemetry.Counter{
+		Name: "nube.http.retries", Unit: "{retry}",
+		Description: "API attempts repeated after a 429 or 5xx response",
+	}
+	httpRateLimited = telemetry.Counter{
+		Name: "nube.http.rate_limited", Unit: "{response}",
+		Description: "429 responses from the API",
+	}
+)
+
+// startTelemetry traces the command run in a span and, for a top-level run
+// with OTLP configured, sets up export of it and of every API request. The
+// returned func ends the span with the command's outcome and, if this run
+// owns the export, sends everything to the collector.
+func startTelemetry(baseCtx, ctx context.Context, command string) (context.Context, func(error)) {
+	p := telemetry.FromContext(ctx)
+	owner := false
+
+	// Nested runs report into their parent's provider.
+	if p == nil && !isNested(baseCtx) {
+		cfg, ok := telemetry.ConfigFromEnv(os.Getenv, strings.TrimSpace(version))
+		if !ok {
+			return ctx, func(error) {}
+		}
+
+		p, owner = telemetry.New(cfg), true
+		ctx = telemetry.WithProvider(ctx, p)
+		ctx = api.WithHooks(ctx, telemetryHooks(p))
+	}
+
+	if p == nil {
+		return ctx, func(error) {}
+	}
+
+	ctx, span := p.Start(ctx, "nube "+command, telemetry.KindInternal, telemetry.String("nube.command", command))
+
+	return ctx, func(err error) {
+		code := ExitCode(err)
+
+		span.SetAttributes(telemetry.Int("process.exit.code", code))
+		span.SetError(err)
+		span.End()
+		p.Add(commandRuns, 1, telemetry.String("nube.command", command), telemetry.Int("process.exit.code", code))
+
+		if !owner {
+			return
+		}
+
+		// The command's own deadline may be spent; export has its own.
+		if exportErr := p.Shutdown(context.WithoutCancel(ctx)); exportErr != nil {
+			slog.WarnContext(ctx, "telemetry export failed", "error", exportErr)
+		}
+	}
+}
+
+// telemetryHooks trace each API request as a client span, propagated to the
+// API with a traceparent header, and count requests, retries and rate-limit
+// responses.
+func telemetryHooks(p *telemetry.Provider) *api.Hooks {
+	return &api.Hooks{
+		Request: func(req *http.Request) func(int, error) {
+			_, span := p.Start(req.Context(), req.Method, telemetry.KindClient,
+				telemetry.String("http.request.method", req.Method),
+				telemetry.String("server.address", req.URL.Hostname()),
+				telemetry.String("url.path", req.URL.Path))
+			req.Header.Set("Traceparent", span.TraceParent())
+
+			return func(status int, err error) {
+				if status > 0 {
+					span.SetAttributes(telemetry.Int("http.response.status_code", status))
+				}
+
+				span.SetError(err)
+				span.End()
+				p.Add(httpRequests, 1, telemetry.String("http.request.method", req.Method), telemetry.Int("http.response.status_code", status))
+			}
+		},
+		Attempt: func(_ *http.Request, status int, retry bool) {
+			if status == http.StatusTooManyRequests {
+				p.Add(httpRateLimited, 1)
+			}
+
+			if !retry {
+				return
+			}
+
+			reason := "server_error"
+			if status == http.StatusTooManyRequests {
+				reason = "rate_limit"
+			}
+
+			p.Add(httpRetries, 1, telemetry.String("reason", reason))
+		},
+	}
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestTelemetry_ExportsCommandAndRequests(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var (
+		calls       atomic.Int32
+		traceparent atomic.Value
+	)
+
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent.Store(r.Header.Get("Traceparent"))
+
+		if calls.Add(1) == 1 {
+			w.Header().Set("X-Rate-Limit-Reset", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"id":123}`))
+	}))
+	t.Cleanup(apiSrv.Close)
+
+	orig := newAPIClient
+	newAPIClient = func(_ *RootFlags) (*api.Client, error) {
+		hc := &http.Client{Transport: api.NewRetryTransport(apiSrv.Client().Transport)}
+
+		return api.New("123", "test-token", api.WithBaseURL(apiSrv.URL+"/v1"), api.WithHTTPClient(hc)), nil
+	}
+	t.Cleanup(func() { newAPIClient = orig })
+
+	var (
+		mu    sync.Mutex
+		posts = map[string]string{}
+	)
+
+	collector := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		posts[r.URL.Path] = string(b)
+		mu.Unlock()
+	}))
+	t.Cleanup(collector.Close)
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", collector.URL)
+
+	_ = captureStdout(t)
+
+	if err := Execute([]string{"store", "get", "--json"}); err != nil {
+		t.Fatalf("Execute error = %v", err)
+	}
+
+	traces, metrics := posts["/v1/traces"], posts["/v1/metrics"]
+
+	for _, want := range []string{`"name":"nube store get"`, `"name":"GET"`, `"url.path"`} {
+		if !strings.Contains(traces, want) {
+			t.Errorf("traces missing %s:\n%s", want, traces)
+		}
+	}
+
+	tp, _ := traceparent.Load().(string)
+	if tp == "" || !strings.Contains(traces, strings.Split(tp, "-")[1]) {
+		t.Errorf("traceparent %q not from an exported trace", tp)
+	}
+
+	var payload struct {
+		ResourceMetrics []struct {
+			ScopeMetrics []struct {
+				Metrics []struct {
+					Name string `json:"name"`
+					Sum  struct {
+						DataPoints []struct {
+							AsInt string `json:"asInt"`
+						} `json:"dataPoints"`
+					} `json:"sum"`
+				} `json:"metrics"`
+			} `json:"scopeMetrics"`
+		} `json:"resourceMetrics"`
+	}
+
+	if err := json.Unmarshal([]byte(metrics), &payload); err != nil {
+		t.Fatalf("metrics: %v\n%s", err, metrics)
+	}
+
+	got := map[string]string{}
+	for _, m := range payload.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		got[m.Name] = m.Sum.DataPoints[0].AsInt
+	}
+
+	want := map[string]string{"nube.command.runs": "1", "nube.http.requests": "1", "nube.http.retries": "1", "nube.http.rate_limited": "1"}
+	for name, n := range want {
+		if got[name] != n {
+			t.Errorf("%s = %q, want %q (all: %v)", name, got[name], n, got)
+		}
+	}
+}
+
+func TestTelemetry_OffWithoutEndpoint(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Traceparent") != "" {
+			t.Error("traceparent sent without OTLP configured")
+		}
+
+		_, _ = w.Write([]byte(`{"id":123}`))
+	}))
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	_ = captureStdout(t)
+
+	if err := Execute([]string{"store", "get", "--json"}); err != nil {
+		t.Fatalf("Execute error = %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/alecthomas/kong"
@@ -23,12 +24,15 @@ func setupCredStore(t *testing.T, stores map[string]credstore.StoreProfile, defa
 	}
 }
 
-// setupConfigDir sets XDG_CONFIG_HOME to a temp dir.
+// setupConfigDir sets XDG_CONFIG_HOME, XDG_DATA_HOME and XDG_RUNTIME_DIR
+// to temp dirs.
 func setupConfigDir(t *testing.T) {
 	t.Helper()
 
 	tmp := t.TempDir()
 	t.Setenv("XDG_CONFIG_HOME", tmp)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+	t.Setenv("XDG_RUNTIME_DIR", filepath.Join(tmp, "run"))
 }
 
 // stdoutCapture holds the captured stdout buffer and a flush function.
@@ -79,13 +83,13 @@ func captureStdout(t *testing.T) *stdoutCapture {
 }
 
 // stderrCapture holds the captured stderr buffer.
-type stderrCapture struct { //nolint:unused // test infrastructure for future commands
+type stderrCapture struct {
 	buf  bytes.Buffer
 	w    *os.File
 	done chan struct{}
 }
 
-func (c *stderrCapture) String() string { //nolint:unused // test infrastructure for future commands
+func (c *stderrCapture) String() string {
 	_ = c.w.Close()
 	<-c.done
 
@@ -94,7 +98,7 @@ func (c *stderrCapture) String() string { //nolint:unused // test infrastructure
 
 // captureStderr redirects os.Stderr to a buffer.
 // Call .String() on the returned value to flush and get output.
-func captureStderr(t *testing.T) *stderrCapture { //nolint:unused // test infrastructure for future commands
+func captureStderr(t *testing.T) *stderrCapture {
 	t.Helper()
 
 	r, w, err := os.Pipe()
@@ -119,7 +123,7 @@ func captureStderr(t *testing.T) *stderrCapture { //nolint:unused // test infras
 }
 
 // withStdin temporarily replaces os.Stdin with a pipe containing the given input.
-func withStdin(t *testing.T, input string, fn func()) { //nolint:unused // test infrastructure for future commands
+func withStdin(t *testing.T, input string, fn func()) {
 	t.Helper()
 
 	orig := os.Stdin
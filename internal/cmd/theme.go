@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// configTheme returns the theme from config.json. A broken config file is
+// left to the commands that need it; an invalid theme is a config error.
+func configTheme() (ui.Theme, error) {
+	cfg, err := config.ReadConfig()
+	if err != nil || cfg.Theme == nil {
+		return ui.Theme{}, nil //nolint:nilerr // see above
+	}
+
+	t := ui.Theme{
+		Success:    cfg.Theme.Success,
+		Error:      cfg.Theme.Error,
+		Accent:     cfg.Theme.Accent,
+		Muted:      cfg.Theme.Muted,
+		Header:     cfg.Theme.Header,
+		Background: cfg.Theme.Background,
+	}
+
+	if err := t.Validate(); err != nil {
+		return ui.Theme{}, &ExitErr{Code: ExitConfig, Err: fmt.Errorf("config.json: %w", err)}
+	}
+
+	return t, nil
+}
+
+// ConfigThemeCmd groups theme commands.
+type ConfigThemeCmd struct {
+	Preview ConfigThemePreviewCmd `cmd:"" default:"1" help:"Show the color theme in use"`
+}
+
+// ConfigThemePreviewCmd prints a sample of every theme color.
+type ConfigThemePreviewCmd struct{}
+
+func (c *ConfigThemePreviewCmd) Run(ctx context.Context) error {
+	u := ui.FromContext(ctx)
+	t := u.Out().Theme()
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{
+			"success":       t.Success,
+			"error":         t.Error,
+			"accent":        t.Accent,
+			"muted":         t.Muted,
+			"header":        t.Header,
+			"background":    t.Background,
+			"color_enabled": u.Out().ColorEnabled(),
+		})
+	}
+
+	p := u.Out()
+	if !p.ColorEnabled() {
+		u.Err().Printf("colors are off (not a terminal, NO_COLOR or --color never); try --color always")
+	}
+
+	w, done := tableWriter(ctx)
+	defer done()
+
+	_, _ = fmt.Fprintln(w, "ROLE\tVALUE\tSAMPLE")
+	_, _ = fmt.Fprintf(w, "success\t%s\t%s\n", t.Success, p.Color(t.Success, "Product 123 updated"))
+	_, _ = fmt.Fprintf(w, "error\t%s\t%s\n", t.Error, p.Color(t.Error, "Request failed"))
+	_, _ = fmt.Fprintf(w, "accent\t%s\t%s\n", t.Accent, p.Accent("name"))
+	_, _ = fmt.Fprintf(w, "muted\t%s\t%s\n", t.Muted, p.Muted("hint text"))
+	_, _ = fmt.Fprintf(w, "header\t%s\t%s\n", t.Header, p.Header("TABLE HEADER"))
+	_, _ = fmt.Fprintf(w, "background\t%s\t\n", t.Background)
+
+	return nil
+}
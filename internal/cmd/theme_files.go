@@ -0,0 +1,546 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/ftp"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// themeManifestName is the file in the local theme directory recording what
+// each file looked like at the last pull or push.
+const themeManifestName = ".nube-theme.json"
+
+// ThemeCmd groups storefront theme file commands. Theme files aren't part of
+// the REST API; the store's FTP server serves them.
+type ThemeCmd struct {
+	Pull  ThemePullCmd  `cmd:"" help:"Download changed theme files from the store's FTP server"`
+	Push  ThemePushCmd  `cmd:"" help:"Upload locally changed theme files to the store's FTP server"`
+	Watch ThemeWatchCmd `cmd:"" help:"Upload theme files as they change locally"`
+}
+
+// ThemeFTPFlags locate the theme on the FTP server and locally.
+type ThemeFTPFlags struct {
+	Dir       string `help:"Local theme directory" name:"dir" default:"." type:"path"`
+	RemoteDir string `help:"Theme directory on the FTP server" name:"remote-dir" default:"/"`
+	Host      string `help:"FTP server, host or host:port" name:"ftp-host" env:"NUBE_FTP_HOST"`
+	User      string `help:"FTP user" name:"ftp-user" env:"NUBE_FTP_USER"`
+	Password  string `help:"FTP password" name:"ftp-password" env:"NUBE_FTP_PASSWORD"`
+	TLS       bool   `help:"Use explicit FTP over TLS (--no-ftp-tls for plain FTP)" name:"ftp-tls" default:"true" negatable:""`
+}
+
+// themeRemote is the FTP session theme commands use. It is an interface so
+// tests can swap dialThemeFTP for an in-memory server.
+type themeRemote interface {
+	Walk(root string) ([]ftp.Entry, error)
+	Retrieve(p string, w io.Writer) error
+	Store(p string, r io.Reader) error
+	Quit() error
+}
+
+var dialThemeFTP = func(ctx context.Context, f *ThemeFTPFlags, timeout time.Duration) (themeRemote, error) {
+	addr := f.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "21")
+	}
+
+	c, err := ftp.Dial(ctx, addr, ftp.Options{TLS: f.TLS, Timeout: timeout})
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", addr, err)
+	}
+
+	if err := c.Login(f.User, f.Password); err != nil {
+		_ = c.Quit()
+
+		return nil, &ExitErr{Code: ExitAuthRequired, Err: fmt.Errorf("ftp login as %s: %w", f.User, err)}
+	}
+
+	return c, nil
+}
+
+func (f *ThemeFTPFlags) check() error {
+	if f.Host == "" || f.User == "" {
+		return &ExitErr{Code: ExitConfig, Err: errors.New("pass --ftp-host and --ftp-user (or set NUBE_FTP_HOST and NUBE_FTP_USER) with the store's FTP access")}
+	}
+
+	return nil
+}
+
+func (f *ThemeFTPFlags) connect(ctx context.Context, timeout time.Duration) (themeRemote, error) {
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	return dialThemeFTP(ctx, f, timeout)
+}
+
+// themeManifest maps theme paths to their state at the last sync.
+type themeManifest struct {
+	Files map[string]themeFileState `json:"files"`
+}
+
+// themeFileState is a file's remote size and time and its content hash.
+type themeFileState struct {
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	SHA256   string    `json:"sha256"`
+}
+
+// sameRemote reports whether e is still the remote file recorded in s.
+func (s themeFileState) sameRemote(e ftp.Entry) bool {
+	return s.Size == e.Size && s.Modified.Equal(e.ModTime)
+}
+
+// themeFile is one file a pull or push acted on (or would, on --dry-run).
+type themeFile struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func readThemeManifest(dir string) (*themeManifest, error) {
+	m := &themeManifest{Files: map[string]themeFileState{}}
+
+	b, err := os.ReadFile(filepath.Join(dir, themeManifestName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("%s: %w", themeManifestName, err)
+	}
+
+	if m.Files == nil {
+		m.Files = map[string]themeFileState{}
+	}
+
+	return m, nil
+}
+
+func (m *themeManifest) write(dir string) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeThemeFile(filepath.Join(dir, themeManifestName), append(b, '\n'))
+}
+
+// ThemePullCmd downloads the theme files that changed on the server since the
+// last pull. Local edits not yet pushed are kept unless --overwrite.
+type ThemePullCmd struct {
+	ThemeFTPFlags `embed:""`
+
+	Overwrite bool `help:"Replace local files edited since the last sync" name:"overwrite"`
+}
+
+func (c *ThemePullCmd) Run(ctx context.Context, flags *RootFlags) error {
+	man, err := readThemeManifest(c.Dir)
+	if err != nil {
+		return err
+	}
+
+	remote, err := c.connect(ctx, flags.Timeout)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = remote.Quit() }()
+
+	entries, err := remote.Walk(c.RemoteDir)
+	if err != nil {
+		return fmt.Errorf("list %s: %w", c.RemoteDir, err)
+	}
+
+	var done []themeFile
+
+	for _, e := range entries {
+		// Paths come from the server; none may land outside --dir or on
+		// the manifest.
+		if !filepath.IsLocal(filepath.FromSlash(e.Path)) || e.Path == themeManifestName {
+			return fmt.Errorf("server listed %q, which is not a theme file path", e.Path)
+		}
+
+		local := filepath.Join(c.Dir, filepath.FromSlash(e.Path))
+		st, known := man.Files[e.Path]
+
+		localSum, err := fileSHA256(local)
+		exists := err == nil
+
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		if known && exists && st.sameRemote(e) {
+			continue
+		}
+
+		editedLocally := exists && (!known || localSum != st.SHA256)
+
+		if flags.DryRun {
+			done = append(done, pullAction(e.Path, editedLocally && !c.Overwrite))
+
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := remote.Retrieve(path.Join(c.RemoteDir, e.Path), &buf); err != nil {
+			return fmt.Errorf("download %s: %w", e.Path, err)
+		}
+
+		sum := sha256Hex(buf.Bytes())
+
+		switch {
+		case exists && localSum == sum:
+		case editedLocally && !c.Overwrite:
+			done = append(done, pullAction(e.Path, true))
+
+			continue
+		default:
+			if err := os.MkdirAll(filepath.Dir(local), 0o755); err != nil {
+				return err
+			}
+
+			if err := writeThemeFile(local, buf.Bytes()); err != nil {
+				return err
+			}
+
+			done = append(done, pullAction(e.Path, false))
+		}
+
+		man.Files[e.Path] = themeFileState{Size: e.Size, Modified: e.ModTime, SHA256: sum}
+	}
+
+	if !flags.DryRun {
+		if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+			return err
+		}
+
+		if err := man.write(c.Dir); err != nil {
+			return err
+		}
+	}
+
+	return writeThemeFiles(ctx, flags, c.Dir, done)
+}
+
+func pullAction(p string, conflict bool) themeFile {
+	if conflict {
+		return themeFile{Path: p, Action: "conflict", Reason: "edited locally since the last sync"}
+	}
+
+	return themeFile{Path: p, Action: "downloaded"}
+}
+
+// ThemePushCmd uploads the local theme files that changed since the last
+// sync. Files also changed on the server are left alone unless --overwrite.
+type ThemePushCmd struct {
+	ThemeFTPFlags `embed:""`
+
+	Overwrite bool `help:"Replace files changed on the server since the last sync" name:"overwrite"`
+}
+
+func (c *ThemePushCmd) Run(ctx context.Context, flags *RootFlags) error {
+	done, err := pushTheme(ctx, flags, &c.ThemeFTPFlags, c.Overwrite)
+	if err != nil {
+		return err
+	}
+
+	return writeThemeFiles(ctx, flags, c.Dir, done)
+}
+
+// ThemeWatchCmd pushes local changes as they happen, for a save-and-reload
+// loop while editing a theme.
+type ThemeWatchCmd struct {
+	ThemeFTPFlags `embed:""`
+
+	Interval  time.Duration `help:"How often to check local files for changes" name:"interval" default:"1s"`
+	Overwrite bool          `help:"Replace files changed on the server since the last sync" name:"overwrite"`
+}
+
+func (c *ThemeWatchCmd) Run(ctx context.Context, flags *RootFlags) error {
+	if err := refuseNested(ctx, "theme watch"); err != nil {
+		return err
+	}
+
+	if c.Interval <= 0 {
+		return usagef("--interval must be positive")
+	}
+
+	if err := c.check(); err != nil {
+		return err
+	}
+
+	u := ui.FromContext(ctx)
+	u.Err().Printf("watching %s; press Ctrl-C to stop", c.Dir)
+
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	// Pending changes are pushed when they differ from the last tick's, so
+	// a conflict is reported once rather than every interval.
+	var pushed map[string]string
+
+	for {
+		changed, err := localThemeChanges(c.Dir)
+		if err != nil {
+			return err
+		}
+
+		if len(changed) > 0 && !maps.Equal(changed, pushed) {
+			done, err := pushTheme(ctx, flags, &c.ThemeFTPFlags, c.Overwrite)
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			// A failed upload (server restart, dropped connection) is
+			// retried at the next tick; the manifest only records what
+			// made it.
+			if err != nil {
+				slog.WarnContext(ctx, "theme watch: push failed", "err", err)
+			} else {
+				if err := writeThemeFiles(ctx, flags, c.Dir, done); err != nil {
+					u.Err().Println(err.Error())
+				}
+
+				if pushed, err = localThemeChanges(c.Dir); err != nil {
+					return err
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// pushTheme uploads the local files whose content differs from the manifest,
+// then records their new remote state.
+func pushTheme(ctx context.Context, flags *RootFlags, f *ThemeFTPFlags, overwrite bool) ([]themeFile, error) {
+	man, err := readThemeManifest(f.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := localThemeChanges(f.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	remote, err := f.connect(ctx, flags.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = remote.Quit() }()
+
+	entries, err := remote.Walk(f.RemoteDir)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", f.RemoteDir, err)
+	}
+
+	onServer := make(map[string]ftp.Entry, len(entries))
+	for _, e := range entries {
+		onServer[e.Path] = e
+	}
+
+	var (
+		done   []themeFile
+		pushed = map[string]string{}
+	)
+
+	for _, p := range slices.Sorted(maps.Keys(changed)) {
+		st, known := man.Files[p]
+
+		if e, ok := onServer[p]; ok && !overwrite && (!known || !st.sameRemote(e)) {
+			done = append(done, themeFile{Path: p, Action: "conflict", Reason: "changed on the server since the last sync; pull first"})
+
+			continue
+		}
+
+		if flags.DryRun {
+			done = append(done, themeFile{Path: p, Action: "uploaded"})
+
+			continue
+		}
+
+		b, err := os.ReadFile(filepath.Join(f.Dir, filepath.FromSlash(p)))
+		if err != nil {
+			return nil, err
+		}
+
+		if err := remote.Store(path.Join(f.RemoteDir, p), bytes.NewReader(b)); err != nil {
+			return nil, fmt.Errorf("upload %s: %w", p, err)
+		}
+
+		pushed[p] = sha256Hex(b)
+		done = append(done, themeFile{Path: p, Action: "uploaded"})
+	}
+
+	if len(pushed) == 0 {
+		return done, nil
+	}
+
+	// The server sets the time of uploaded files, so it is read back.
+	if entries, err = remote.Walk(f.RemoteDir); err != nil {
+		return nil, fmt.Errorf("list %s: %w", f.RemoteDir, err)
+	}
+
+	for _, e := range entries {
+		if sum, ok := pushed[e.Path]; ok {
+			man.Files[e.Path] = themeFileState{Size: e.Size, Modified: e.ModTime, SHA256: sum}
+		}
+	}
+
+	return done, man.write(f.Dir)
+}
+
+// localThemeChanges returns the files under dir whose content differs from
+// the manifest, with their SHA-256. Hidden files and directories (.git, the
+// manifest) are skipped.
+func localThemeChanges(dir string) (map[string]string, error) {
+	man, err := readThemeManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := map[string]string{}
+
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if p != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+
+		sum, err := fileSHA256(p)
+		if err != nil {
+			return err
+		}
+
+		if rel = filepath.ToSlash(rel); man.Files[rel].SHA256 != sum {
+			changed[rel] = sum
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return changed, nil
+}
+
+func writeThemeFiles(ctx context.Context, flags *RootFlags, dir string, files []themeFile) error {
+	counts := map[string]int{}
+	for _, f := range files {
+		counts[f.Action]++
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if files == nil {
+			files = []themeFile{}
+		}
+
+		out := map[string]any{"dir": dir, "files": files, "counts": counts}
+		if flags.DryRun {
+			out["dry_run"] = true
+		}
+
+		if err := outfmt.WriteJSON(ctx, stdoutFrom(ctx), out); err != nil {
+			return err
+		}
+	} else if len(files) == 0 {
+		ui.FromContext(ctx).Err().Println("theme is up to date")
+	} else {
+		w, done := tableWriter(ctx)
+
+		_, _ = fmt.Fprintln(w, "PATH\tACTION\tREASON")
+
+		for _, f := range files {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", f.Path, f.Action, f.Reason)
+		}
+
+		done()
+	}
+
+	if n := counts["conflict"]; n > 0 {
+		return fmt.Errorf("%d files changed on both sides; resolve them or pass --overwrite", n)
+	}
+
+	return nil
+}
+
+// writeThemeFile replaces p through a hidden temporary file, so a watch or
+// the server never sees half a file.
+func writeThemeFile(p string, b []byte) error {
+	tmp := filepath.Join(filepath.Dir(p), "."+filepath.Base(p)+".tmp")
+	if err := os.WriteFile(tmp, b, 0o644); err != nil { //nolint:gosec // theme files are public assets
+		return fmt.Errorf("write %s: %w", p, err)
+	}
+
+	if err := os.Rename(tmp, p); err != nil {
+		return fmt.Errorf("write %s: %w", p, err)
+	}
+
+	return nil
+}
+
+func fileSHA256(p string) (string, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return "", err
+	}
+
+	return sha256Hex(b), nil
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/ftp"
+)
+
+// fakeThemeFTP is an in-memory theme server. Every upload bumps the clock,
+// as a real server stamps files with the upload time.
+type fakeThemeFTP struct {
+	files map[string]string
+	times map[string]time.Time
+	now   time.Time
+}
+
+func newFakeThemeFTP(t *testing.T, files map[string]string) *fakeThemeFTP {
+	t.Helper()
+
+	f := &fakeThemeFTP{files: map[string]string{}, times: map[string]time.Time{}, now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	for p, body := range files {
+		f.put(p, body)
+	}
+
+	orig := dialThemeFTP
+	dialThemeFTP = func(context.Context, *ThemeFTPFlags, time.Duration) (themeRemote, error) { return f, nil }
+
+	t.Cleanup(func() { dialThemeFTP = orig })
+
+	return f
+}
+
+func (f *fakeThemeFTP) put(p, body string) {
+	f.now = f.now.Add(time.Minute)
+	f.files[p] = body
+	f.times[p] = f.now
+}
+
+func (f *fakeThemeFTP) Walk(root string) ([]ftp.Entry, error) {
+	var entries []ftp.Entry
+
+	for p, body := range f.files {
+		if rel, ok := strings.CutPrefix(p, path.Clean(root)+"/"); ok {
+			entries = append(entries, ftp.Entry{Path: rel, Size: int64(len(body)), ModTime: f.times[p]})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+
+	return entries, nil
+}
+
+func (f *fakeThemeFTP) Retrieve(p string, w io.Writer) error {
+	_, err := io.WriteString(w, f.files[p])
+
+	return err
+}
+
+func (f *fakeThemeFTP) Store(p string, r io.Reader) error {
+	b, err := io.ReadAll(r)
+	f.put(p, string(b))
+
+	return err
+}
+
+func (f *fakeThemeFTP) Quit() error { return nil }
+
+func TestTheme_PullPushConflicts(t *testing.T) {
+	setupConfigDir(t)
+
+	srv := newFakeThemeFTP(t, map[string]string{
+		"/theme/layouts/layout.tpl": "<html>v1</html>",
+		"/theme/static/style.css":   "body{}",
+	})
+
+	dir := filepath.Join(t.TempDir(), "theme")
+	conn := []string{"--dir", dir, "--remote-dir", "/theme", "--ftp-host", "ftp.test", "--ftp-user", "shop"}
+
+	_ = captureStderr(t)
+
+	run := func(args ...string) (map[string]any, error) {
+		t.Helper()
+
+		out := captureStdout(t)
+		err := Execute(append(append([]string{"--json", "theme"}, args...), conn...))
+
+		// A failing run prints its error object after the result.
+		var res map[string]any
+		if jsonErr := json.NewDecoder(bytes.NewReader(out.Bytes())).Decode(&res); jsonErr != nil {
+			t.Fatalf("theme %v: %v (err %v)\n%s", args, jsonErr, err, out.String())
+		}
+
+		return res, err
+	}
+
+	if res, err := run("pull"); err != nil || res["counts"].(map[string]any)["downloaded"] != 2.0 {
+		t.Fatalf("first pull = %v, %v", res, err)
+	}
+
+	if b, _ := os.ReadFile(filepath.Join(dir, "layouts", "layout.tpl")); string(b) != "<html>v1</html>" {
+		t.Errorf("layout.tpl = %q", b)
+	}
+
+	// Nothing changed: nothing to do either way.
+	if res, err := run("pull"); err != nil || len(res["files"].([]any)) != 0 {
+		t.Errorf("second pull = %v, %v", res, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "static", "style.css"), []byte("body{color:red}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = os.MkdirAll(filepath.Join(dir, "snipplets"), 0o755)
+	_ = os.WriteFile(filepath.Join(dir, "snipplets", "new.tpl"), []byte("hola"), 0o600)
+	_ = os.WriteFile(filepath.Join(dir, ".DS_Store"), []byte("x"), 0o600)
+
+	// The layout changes on the server; it wasn't edited locally, so the
+	// push leaves it alone.
+	srv.put("/theme/layouts/layout.tpl", "<html>v2</html>")
+
+	if res, err := run("push"); err != nil || res["counts"].(map[string]any)["uploaded"] != 2.0 {
+		t.Fatalf("push = %v, %v", res, err)
+	}
+
+	if srv.files["/theme/static/style.css"] != "body{color:red}" || srv.files["/theme/snipplets/new.tpl"] != "hola" {
+		t.Errorf("server files = %v", srv.files)
+	}
+
+	if _, ok := srv.files["/theme/.DS_Store"]; ok {
+		t.Error("hidden file uploaded")
+	}
+
+	// Edited on both sides: pull and push both refuse.
+	_ = os.WriteFile(filepath.Join(dir, "layouts", "layout.tpl"), []byte("<html>mine</html>"), 0o600)
+
+	if res, err := run("pull"); err == nil || res["counts"].(map[string]any)["conflict"] != 1.0 {
+		t.Errorf("conflicting pull = %v, %v", res, err)
+	}
+
+	if res, err := run("push"); err == nil || res["counts"].(map[string]any)["conflict"] != 1.0 {
+		t.Errorf("conflicting push = %v, %v", res, err)
+	}
+
+	if _, err := run("push", "--overwrite"); err != nil {
+		t.Fatalf("push --overwrite: %v", err)
+	}
+
+	if got := srv.files["/theme/layouts/layout.tpl"]; got != "<html>mine</html>" {
+		t.Errorf("layout after --overwrite = %q", got)
+	}
+}
+
+func TestTheme_PullRejectsUnsafePaths(t *testing.T) {
+	setupConfigDir(t)
+
+	_ = captureStdout(t)
+	_ = captureStderr(t)
+
+	root := t.TempDir()
+	dir := filepath.Join(root, "theme")
+
+	for _, hostile := range []string{"/theme/../evil.tpl", "/theme/" + themeManifestName} {
+		newFakeThemeFTP(t, map[string]string{"/theme/layouts/layout.tpl": "<html></html>", hostile: "{}"})
+
+		err := Execute([]string{"theme", "pull", "--dir", dir, "--remote-dir", "/theme", "--ftp-host", "ftp.test", "--ftp-user", "shop"})
+		if err == nil {
+			t.Errorf("pull with %s succeeded", hostile)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "evil.tpl")); err == nil {
+		t.Error("pull wrote outside --dir")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, themeManifestName)); err == nil {
+		t.Error("pull replaced the manifest with the server's")
+	}
+}
+
+func TestTheme_MissingFTPAccess(t *testing.T) {
+	setupConfigDir(t)
+	t.Setenv("NUBE_FTP_HOST", "")
+
+	_ = captureStdout(t)
+	_ = captureStderr(t)
+
+	if err := Execute([]string{"theme", "pull", "--dir", t.TempDir()}); ExitCode(err) != ExitConfig {
+		t.Errorf("exit code = %d, want %d (%v)", ExitCode(err), ExitConfig, err)
+	}
+}
+
+func TestLocalThemeChanges(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(dir, ".git"), 0o755)
+	_ = os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref"), 0o600)
+	_ = os.WriteFile(filepath.Join(dir, "a.tpl"), []byte("a"), 0o600)
+	_ = os.WriteFile(filepath.Join(dir, "b.tpl"), []byte("b"), 0o600)
+
+	man := &themeManifest{Files: map[string]themeFileState{"a.tpl": {SHA256: sha256Hex([]byte("a"))}}}
+	if err := man.write(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := localThemeChanges(dir)
+	if err != nil || len(changed) != 1 || changed["b.tpl"] != sha256Hex([]byte("b")) {
+		t.Errorf("changed = %v, %v", changed, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, themeManifestName)); err != nil {
+		t.Errorf("manifest: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// UseCmd selects the store profile for the current shell session, so it
+// doesn't need --store on every command. Sessions are keyed by
+// $NUBE_SESSION or the parent process, so shells don't affect each other.
+type UseCmd struct {
+	Name  string `arg:"" optional:"" name:"name" help:"Store profile to use (omit to show the current one)"`
+	Clear bool   `help:"Forget this session's selection and go back to the default store" name:"clear"`
+	Shell bool   `help:"Print an export line for eval, so scripts and subshells share the selection" name:"shell"`
+}
+
+func (c *UseCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	name := strings.TrimSpace(c.Name)
+
+	key, err := credstore.SessionKeyEnv(flags.getenv)
+	if err != nil {
+		return newUsageError(err)
+	}
+
+	if c.Clear {
+		if name != "" {
+			return usagef("--clear doesn't take a profile name")
+		}
+
+		if err := credstore.ClearSessionStore(key); err != nil {
+			return err
+		}
+
+		return writeResult(ctx, u, kv("store", ""), kv("cleared", true))
+	}
+
+	if name == "" {
+		if c.Shell {
+			return usagef("--shell needs a profile name")
+		}
+
+		current, err := credstore.SessionStoreEnv(flags.getenv)
+		if err != nil {
+			return err
+		}
+
+		return writeResult(ctx, u, kv("store", current))
+	}
+
+	if _, err := credstore.GetStore(name); err != nil {
+		return &ExitErr{Code: ExitConfig, Err: err}
+	}
+
+	if !c.Shell {
+		if err := credstore.SetSessionStore(key, name); err != nil {
+			return err
+		}
+
+		return writeResult(ctx, u, kv("store", name))
+	}
+
+	// The printed variable names the session from now on; reuse it when
+	// the shell already has one.
+	id := os.Getenv(credstore.SessionEnv)
+	if id == "" {
+		id = credstore.NewSessionID()
+	}
+
+	if err := credstore.SetSessionStore(id, name); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(stdoutFrom(ctx), "export %s=%s\n", credstore.SessionEnv, id)
+
+	return err
+}
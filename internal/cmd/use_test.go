@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+func TestUse(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{
+		"a": {StoreID: "1", AccessToken: "ta"},
+		"b": {StoreID: "2", AccessToken: "tb"},
+	}, "a")
+	t.Setenv(credstore.SessionEnv, "")
+
+	_ = captureStdout(t)
+	if err := Execute([]string{"use", "b"}); err != nil {
+		t.Fatalf("use b: %v", err)
+	}
+
+	if name, _, _ := credstore.ResolveStore(""); name != "b" {
+		t.Errorf("resolved %q after use b", name)
+	}
+
+	out := captureStdout(t)
+	if err := Execute([]string{"use", "b", "--shell"}); err != nil {
+		t.Fatalf("use --shell: %v", err)
+	}
+
+	id, ok := strings.CutPrefix(strings.TrimSpace(out.String()), "export NUBE_SESSION=")
+	if !ok || id == "" {
+		t.Fatalf("output = %q", out.String())
+	}
+
+	t.Setenv(credstore.SessionEnv, id)
+
+	if name, _, _ := credstore.ResolveStore(""); name != "b" {
+		t.Errorf("resolved %q in the --shell session", name)
+	}
+
+	_ = captureStdout(t)
+	if err := Execute([]string{"use", "--clear"}); err != nil {
+		t.Fatalf("use --clear: %v", err)
+	}
+
+	if name, _, _ := credstore.ResolveStore(""); name != "a" {
+		t.Errorf("resolved %q after --clear", name)
+	}
+
+	_ = captureStderr(t)
+
+	if err := Execute([]string{"use", "missing"}); ExitCode(err) != ExitConfig {
+		t.Errorf("unknown profile: err = %v", err)
+	}
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/gberlati/nube-cli/internal/outfmt"
@@ -40,14 +39,14 @@ type VersionCmd struct{}
 
 func (c *VersionCmd) Run(ctx context.Context) error {
 	if outfmt.IsJSON(ctx) {
-		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+		return outfmt.WriteJSON(ctx, stdoutFrom(ctx), map[string]any{
 			"version": strings.TrimSpace(version),
 			"commit":  strings.TrimSpace(commit),
 			"date":    strings.TrimSpace(date),
 		})
 	}
 
-	fmt.Fprintln(os.Stdout, VersionString())
+	fmt.Fprintln(stdoutFrom(ctx), VersionString())
 
 	return nil
 }
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/outfmt"
+	"github.com/gberlati/nube-cli/internal/ui"
+)
+
+// waitResources maps the resource names wait takes to their API paths.
+var waitResources = map[string]string{
+	"order":    "orders",
+	"product":  "products",
+	"customer": "customers",
+	"category": "categories",
+}
+
+// WaitCmd polls a resource until conditions on its fields hold, replacing
+// sleep loops in scripts that wait for a payment or a shipment. With --via
+// webhook, deliveries for the resource trigger a check as soon as they
+// arrive, and polling continues as a fallback.
+type WaitCmd struct {
+	Resource    string        `arg:"" enum:"order,product,customer,category" help:"Resource type: order, product, customer or category"`
+	ID          string        `arg:"" name:"id" help:"Resource ID"`
+	Until       []string      `help:"Condition field=value or field!=value on a dotted field path (repeatable; all must hold)" name:"until" required:"" sep:"none"`
+	WaitTimeout time.Duration `help:"Give up after this long (exit 13)" name:"wait-timeout" default:"10m"`
+	Interval    time.Duration `help:"Time between checks" name:"interval" default:"15s"`
+	Via         string        `help:"How to notice changes: poll, or webhook deliveries with polling as a fallback" name:"via" enum:"poll,webhook" default:"poll"`
+	Listen      string        `help:"Address to receive webhook deliveries on (--via webhook)" name:"listen" default:"127.0.0.1:9810"`
+	PublicURL   string        `help:"Public URL that forwards to --listen, e.g. a tunnel, registered as a temporary webhook (--via webhook)" name:"public-url"`
+}
+
+// waitCondition is one --until: field must equal want, or differ from it.
+type waitCondition struct {
+	field  string
+	want   string
+	negate bool
+}
+
+func parseWaitCondition(s string) (waitCondition, error) {
+	if field, want, ok := strings.Cut(s, "!="); ok && field != "" {
+		return waitCondition{field: strings.TrimSpace(field), want: strings.TrimSpace(want), negate: true}, nil
+	}
+
+	if field, want, ok := strings.Cut(s, "="); ok && field != "" {
+		return waitCondition{field: strings.TrimSpace(field), want: strings.TrimSpace(want)}, nil
+	}
+
+	return waitCondition{}, usagef("--until %q: want field=value or field!=value", s)
+}
+
+func (w waitCondition) holds(item map[string]any) bool {
+	return (fieldValue(item, w.field) == w.want) != w.negate
+}
+
+// unmet describes why the condition doesn't hold for item.
+func (w waitCondition) unmet(item map[string]any) string {
+	got := fieldValue(item, w.field)
+	if w.negate {
+		return fmt.Sprintf("%s is still %q", w.field, got)
+	}
+
+	return fmt.Sprintf("%s is %q, want %q", w.field, got, w.want)
+}
+
+func (c *WaitCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	if c.Interval <= 0 || c.WaitTimeout <= 0 {
+		return usagef("--interval and --wait-timeout must be positive")
+	}
+
+	conds := make([]waitCondition, 0, len(c.Until))
+
+	for _, s := range c.Until {
+		cond, err := parseWaitCondition(s)
+		if err != nil {
+			return err
+		}
+
+		conds = append(conds, cond)
+	}
+
+	if c.Via == "webhook" {
+		if target, err := url.Parse(c.PublicURL); err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return usagef("--via webhook needs --public-url, an http(s) URL that reaches --listen")
+		}
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	// A nil channel never fires, leaving only the ticker.
+	var wake <-chan struct{}
+
+	switch {
+	case c.Via != "webhook":
+	case flags.DryRun:
+		u.Err().Printf("--dry-run: not registering webhooks, polling every %s", c.Interval)
+	default:
+		hooks, err := startWaitWebhooks(ctx, client, c.Resource, c.ID, c.Listen, c.PublicURL)
+		if err != nil {
+			u.Err().Printf("webhooks unavailable, polling every %s: %v", c.Interval, err)
+
+			break
+		}
+
+		defer hooks.close(ctx)
+
+		wake = hooks.wake
+	}
+
+	path := waitResources[c.Resource] + "/" + c.ID
+	start := time.Now()
+
+	waitCtx, cancel := context.WithTimeout(ctx, c.WaitTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	// unmet is kept from the last completed check for the timeout message.
+	var unmet []string
+
+	for {
+		item, err := fetchWaitResource(waitCtx, client, path)
+
+		switch {
+		case err != nil && ctx.Err() == nil && errors.Is(waitCtx.Err(), context.DeadlineExceeded):
+			return c.timedOut(unmet)
+		case err != nil:
+			return err
+		}
+
+		unmet = unmet[:0]
+
+		for _, cond := range conds {
+			if !cond.holds(item) {
+				unmet = append(unmet, cond.unmet(item))
+			}
+		}
+
+		if len(unmet) == 0 {
+			if outfmt.IsJSON(ctx) {
+				return outfmt.WriteJSON(ctx, stdoutFrom(ctx), item)
+			}
+
+			u.Err().Printf("%s %s: %s after %s", c.Resource, c.ID, strings.Join(c.Until, ", "), time.Since(start).Round(time.Second))
+
+			return nil
+		}
+
+		slog.DebugContext(ctx, "wait: condition not met", "path", path, "unmet", unmet)
+
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			return c.timedOut(unmet)
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
+
+// timedOut is the ExitTimeout error, naming what was still unmet at the
+// last check.
+func (c *WaitCmd) timedOut(unmet []string) error {
+	msg := fmt.Sprintf("%s %s: timed out after %s", c.Resource, c.ID, c.WaitTimeout)
+	if len(unmet) > 0 {
+		msg += ": " + strings.Join(unmet, "; ")
+	} else {
+		msg += " before the first check finished"
+	}
+
+	return &ExitErr{Code: ExitTimeout, Err: errors.New(msg)}
+}
+
+func fetchWaitResource(ctx context.Context, client *api.Client, path string) (map[string]any, error) {
+	resp, err := client.Get(ctx, path, nil) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return nil, err
+	}
+
+	return api.DecodeResponse[map[string]any](resp)
+}
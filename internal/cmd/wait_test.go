@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+)
+
+// setupWaitOrder serves order 1 as pending until the paidAfter-th request.
+func setupWaitOrder(t *testing.T, paidAfter int32) *atomic.Int32 {
+	t.Helper()
+
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	var calls atomic.Int32
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/123/orders/1" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":404,"message":"Not Found"}`))
+
+			return
+		}
+
+		status := "pending"
+		if calls.Add(1) >= paidAfter {
+			status = "paid"
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id": 1, "payment_status": status, "shipping_status": "unpacked",
+			"customer": map[string]any{"email": "ana@example.com"},
+		})
+	}))
+
+	return &calls
+}
+
+func TestWait_Met(t *testing.T) {
+	calls := setupWaitOrder(t, 3)
+
+	buf := captureStdout(t)
+	_ = captureStderr(t)
+
+	err := Execute([]string{
+		"wait", "order", "1", "--until", "payment_status=paid", "--until", "customer.email=ana@example.com",
+		"--until", "shipping_status!=shipped", "--interval", "5ms", "--json",
+	})
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if calls.Load() != 3 {
+		t.Errorf("requests = %d, want 3", calls.Load())
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if got["payment_status"] != "paid" {
+		t.Errorf("output = %v, want the paid order", got)
+	}
+}
+
+func TestWait_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantErr  string
+	}{
+		{"timeout", []string{"wait", "order", "1", "--until", "payment_status=paid", "--interval", "5ms", "--wait-timeout", "30ms"}, ExitTimeout, `payment_status is "pending", want "paid"`},
+		{"not found", []string{"wait", "order", "2", "--until", "payment_status=paid"}, ExitNotFound, ""},
+		{"bad condition", []string{"wait", "order", "1", "--until", "paid"}, ExitUsage, "field=value"},
+		{"bad interval", []string{"wait", "order", "1", "--until", "payment_status=paid", "--interval", "0s"}, ExitUsage, ""},
+		{"webhook without url", []string{"wait", "order", "1", "--until", "payment_status=paid", "--via", "webhook"}, ExitUsage, "--public-url"},
+		{"unknown resource", []string{"wait", "coupon", "1", "--until", "valid=true"}, ExitUsage, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupWaitOrder(t, 1000)
+
+			_ = captureStderr(t)
+
+			err := Execute(tt.args)
+			if ExitCode(err) != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d (err %v)", ExitCode(err), tt.wantCode, err)
+			}
+
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// freeAddr returns a loopback address nothing is listening on.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	return addr
+}
+
+func TestWait_ViaWebhook(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	listen := freeAddr(t)
+
+	var (
+		paid             atomic.Bool
+		created, deleted atomic.Int32
+		registeredURL    atomic.Value
+		delivered        atomic.Bool
+	)
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/123/webhooks":
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			registeredURL.Store(body["url"])
+
+			n := created.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": 500 + n, "event": body["event"], "url": body["url"]})
+
+			// The order is paid once every webhook is in place; only the
+			// delivery can wake the wait before its hour-long interval.
+			if n == int32(len(waitWebhookEvents["order"])) {
+				go func() {
+					paid.Store(true)
+
+					for !delivered.Load() {
+						resp, err := http.Post("http://"+listen+"/", "application/json", strings.NewReader(`{"store_id":123,"event":"order/paid","id":1}`))
+						if err == nil {
+							_ = resp.Body.Close()
+							delivered.Store(true)
+						}
+
+						time.Sleep(5 * time.Millisecond)
+					}
+				}()
+			}
+		case strings.HasPrefix(r.URL.Path, "/v1/123/webhooks/"):
+			// DELETE, after the undo snapshot's GET.
+			if r.Method == http.MethodDelete {
+				deleted.Add(1)
+			}
+
+			_, _ = w.Write([]byte(`{}`))
+		case r.URL.Path == "/v1/123/orders/1":
+			status := "pending"
+			if paid.Load() {
+				status = "paid"
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": 1, "payment_status": status})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	_ = captureStderr(t)
+
+	err := Execute([]string{
+		"wait", "order", "1", "--until", "payment_status=paid", "--via", "webhook",
+		"--listen", listen, "--public-url", "https://tunnel.example/hook", "--interval", "1h", "--wait-timeout", "10s",
+	})
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if got := registeredURL.Load(); got != "https://tunnel.example/hook" {
+		t.Errorf("registered url = %v", got)
+	}
+
+	if created.Load() != deleted.Load() || created.Load() == 0 {
+		t.Errorf("webhooks created %d, deleted %d", created.Load(), deleted.Load())
+	}
+}
+
+func TestWait_ViaWebhookFallback(t *testing.T) {
+	calls := setupWaitOrder(t, 3)
+
+	errBuf := captureStderr(t)
+
+	err := Execute([]string{
+		"wait", "order", "1", "--until", "payment_status=paid", "--via", "webhook",
+		"--listen", freeAddr(t), "--public-url", "https://tunnel.example/hook", "--interval", "5ms",
+	})
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if calls.Load() != 3 {
+		t.Errorf("order requests = %d, want 3", calls.Load())
+	}
+
+	if !strings.Contains(errBuf.String(), "polling every 5ms") {
+		t.Errorf("stderr = %q, want the fallback notice", errBuf.String())
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+)
+
+// waitWebhookEvents are the events wait --via webhook subscribes to; any of
+// them may be the change a condition is waiting for.
+var waitWebhookEvents = map[string][]string{
+	"order":    {"order/updated", "order/paid", "order/packed", "order/fulfilled", "order/cancelled"},
+	"product":  {"product/updated"},
+	"customer": {"customer/updated"},
+	"category": {"category/updated"},
+}
+
+// waitWebhooks receives deliveries for temporary webhooks registered for a
+// wait. Deliveries only trigger an early check: the conditions are always
+// read from the API, so they need no signature.
+type waitWebhooks struct {
+	client *api.Client
+	srv    *http.Server
+	ids    []string
+	wake   chan struct{}
+}
+
+// startWaitWebhooks listens on listen, then registers publicURL (which must
+// forward there) for the resource's events. Deliveries about id are sent on
+// the wake channel. On error nothing is left registered.
+func startWaitWebhooks(ctx context.Context, client *api.Client, resource, id, listen, publicURL string) (*waitWebhooks, error) {
+	ln, err := (&net.ListenConfig{}).Listen(ctx, "tcp", listen)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", listen, err)
+	}
+
+	w := &waitWebhooks{client: client, wake: make(chan struct{}, 1)}
+	w.srv = &http.Server{
+		Handler:           w.handler(id),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := w.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.WarnContext(ctx, "wait: webhook listener stopped", "err", err)
+		}
+	}()
+
+	for _, event := range waitWebhookEvents[resource] {
+		hookID, err := w.register(ctx, event, publicURL)
+		if err != nil {
+			w.close(ctx)
+
+			return nil, fmt.Errorf("register %s webhook: %w", event, err)
+		}
+
+		w.ids = append(w.ids, hookID)
+	}
+
+	return w, nil
+}
+
+func (w *waitWebhooks) register(ctx context.Context, event, publicURL string) (string, error) {
+	body, err := jsonBody(map[string]any{"event": event, "url": publicURL})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := w.client.Post(ctx, "webhooks", body) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return "", err
+	}
+
+	hook, err := api.DecodeResponse[map[string]any](resp)
+	if err != nil {
+		return "", err
+	}
+
+	return jsonStr(hook, "id"), nil
+}
+
+// handler wakes the wait for deliveries about id and acknowledges all of
+// them, so the store doesn't retry.
+func (w *waitWebhooks) handler(id string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var delivery map[string]any
+		if r.Method == http.MethodPost && json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&delivery) == nil &&
+			jsonStr(delivery, "id") == id {
+			select {
+			case w.wake <- struct{}{}:
+			default:
+			}
+		}
+
+		rw.WriteHeader(http.StatusOK)
+	})
+}
+
+// close deletes the registered webhooks and stops listening. It runs after
+// the wait's deadline too, so it gets its own time budget.
+func (w *waitWebhooks) close(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+
+	for _, hookID := range w.ids {
+		resp, err := w.client.Delete(ctx, "webhooks/"+url.PathEscape(hookID)) //nolint:bodyclose // decodeOptionalJSON closes body
+		if err == nil {
+			_, err = decodeOptionalJSON(resp)
+		}
+
+		if err != nil {
+			slog.WarnContext(ctx, "wait: temporary webhook left registered", "id", hookID, "err", err)
+		}
+	}
+
+	_ = w.srv.Close()
+}
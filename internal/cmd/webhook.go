@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/ui"
+	"github.com/gberlati/nube-cli/internal/webhook"
+)
+
+// WebhookCmd groups webhook development helpers.
+type WebhookCmd struct {
+	Verify WebhookVerifyCmd `cmd:"" help:"Check a webhook payload against its HMAC signature"`
+	Replay WebhookReplayCmd `cmd:"" help:"Send a signed webhook for an existing resource to a local handler"`
+}
+
+// WebhookSecretFlags select the app secret webhooks are signed with.
+type WebhookSecretFlags struct {
+	Secret          string `help:"App client secret" name:"secret" env:"NUBE_WEBHOOK_SECRET"`
+	SecretFromStore bool   `help:"Use the client secret saved with 'nube auth credentials set'" name:"secret-from-store"`
+}
+
+// resolve returns the secret from --secret or the credential store.
+func (f WebhookSecretFlags) resolve() (string, error) {
+	switch {
+	case f.SecretFromStore:
+		client, err := credstore.GetOAuthClient("default")
+		if err != nil {
+			return "", &ExitErr{Code: ExitConfig, Err: err}
+		}
+
+		return client.ClientSecret, nil
+	case f.Secret != "":
+		return f.Secret, nil
+	default:
+		return "", usagef("pass --secret, NUBE_WEBHOOK_SECRET or --secret-from-store")
+	}
+}
+
+type WebhookVerifyCmd struct {
+	WebhookSecretFlags `embed:""`
+
+	Payload   string `help:"File with the raw request body ('-' for stdin)" name:"payload" required:""`
+	Signature string `help:"Hex signature from the X-Linkedstore-Hmac-Sha256 header" name:"signature" required:""`
+}
+
+func (c *WebhookVerifyCmd) Run(ctx context.Context, _ *RootFlags) error {
+	secret, err := c.resolve()
+	if err != nil {
+		return err
+	}
+
+	body, err := readInputFile(c.Payload)
+	if err != nil {
+		return err
+	}
+
+	ok := webhook.Verify(secret, body, c.Signature)
+	u := ui.FromContext(ctx)
+
+	result := "ok"
+	if !ok {
+		result = "mismatch"
+	}
+
+	if err := writeResult(ctx, u,
+		kv("result", result),
+		kv("expected", webhook.Sign(secret, body)),
+	); err != nil {
+		return err
+	}
+
+	if !ok {
+		if u != nil && len(body) > 0 && body[len(body)-1] == '\n' {
+			u.Err().Printf("hint: the payload ends with a newline; signatures cover the exact bytes received")
+		}
+
+		return &ExitErr{Code: ExitMismatch}
+	}
+
+	return nil
+}
+
+// webhookResources maps the resource part of an event name to its API path.
+// App events (app/uninstalled, app/suspended) refer to the store itself.
+var webhookResources = map[string]string{
+	"app":      "",
+	"category": "categories",
+	"customer": "customers",
+	"order":    "orders",
+	"product":  "products",
+}
+
+type WebhookReplayCmd struct {
+	WebhookSecretFlags `embed:""`
+
+	Event string `help:"Event name, e.g. order/created or product/updated" name:"event" required:""`
+	ID    string `help:"ID of the resource the event is about" name:"id" required:""`
+	To    string `help:"Handler URL to POST the webhook to" name:"to" required:""`
+}
+
+// webhookPayload is the body Tienda Nube delivers: identifiers only, which
+// the handler uses to fetch the resource.
+type webhookPayload struct {
+	StoreID any    `json:"store_id"`
+	Event   string `json:"event"`
+	ID      any    `json:"id"`
+}
+
+func (c *WebhookReplayCmd) Run(ctx context.Context, flags *RootFlags) error {
+	resource, _, ok := strings.Cut(c.Event, "/")
+	path, known := webhookResources[resource]
+
+	if !ok || !known {
+		return usagef("unknown event %q (want app/*, category/*, customer/*, order/*, or product/*)", c.Event)
+	}
+
+	target, err := url.Parse(c.To)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return usagef("--to %q: want an http(s) URL", c.To)
+	}
+
+	secret, err := c.resolve()
+	if err != nil {
+		return err
+	}
+
+	client, err := newAPIClient(flags)
+	if err != nil {
+		return err
+	}
+
+	// The handler will fetch the resource, so make sure it exists first.
+	if path != "" {
+		resp, getErr := client.Get(ctx, path+"/"+c.ID, nil) //nolint:bodyclose // DecodeResponse closes body
+		if getErr != nil {
+			return getErr
+		}
+
+		if _, getErr = api.DecodeResponse[map[string]any](resp); getErr != nil {
+			return getErr
+		}
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		StoreID: numericOrString(client.StoreID()),
+		Event:   c.Event,
+		ID:      numericOrString(c.ID),
+	})
+	if err != nil {
+		return fmt.Errorf("encode webhook: %w", err)
+	}
+
+	signature := webhook.Sign(secret, body)
+	u := ui.FromContext(ctx)
+
+	if flags.DryRun {
+		return writeResult(ctx, u,
+			kv("dry_run", true),
+			kv("to", c.To),
+			kv("payload", json.RawMessage(body)),
+			kv("signature", signature),
+		)
+	}
+
+	status, err := postWebhook(ctx, flags.Timeout, c.To, body, signature)
+	if err != nil {
+		return err
+	}
+
+	if err := writeResult(ctx, u,
+		kv("to", c.To),
+		kv("event", c.Event),
+		kv("id", c.ID),
+		kv("status", status),
+		kv("signature", signature),
+	); err != nil {
+		return err
+	}
+
+	if status < 200 || status > 299 {
+		return &ExitErr{Code: ExitError, Err: fmt.Errorf("handler responded with HTTP %d", status)}
+	}
+
+	return nil
+}
+
+// postWebhook delivers body to url the way Tienda Nube does and returns the
+// handler's status code.
+func postWebhook(ctx context.Context, timeout time.Duration, target string, body []byte, signature string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("build request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.SignatureHeader, signature)
+
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("post webhook: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
+
+// numericOrString returns s as a number when it is one, matching the IDs in
+// real deliveries.
+func numericOrString(s string) any {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n
+	}
+
+	return s
+}
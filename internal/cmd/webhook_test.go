@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/webhook"
+)
+
+func writePayload(t *testing.T, body string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "payload.json")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestWebhookVerify(t *testing.T) {
+	setupConfigDir(t)
+
+	body := `{"store_id":123,"event":"order/created","id":1}`
+	path := writePayload(t, body)
+	sig := webhook.Sign("s3cret", []byte(body))
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		want     string
+	}{
+		{"ok", []string{"--secret", "s3cret", "--signature", sig}, ExitOK, "ok"},
+		{"wrong secret", []string{"--secret", "other", "--signature", sig}, ExitMismatch, "mismatch"},
+		{"no secret", []string{"--signature", sig}, ExitUsage, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureStdout(t)
+			args := append([]string{"webhook", "verify", "--json", "--payload", path}, tt.args...)
+
+			err := Execute(args)
+			if got := ExitCode(err); got != tt.wantCode {
+				t.Fatalf("ExitCode = %d, want %d (err %v)", got, tt.wantCode, err)
+			}
+
+			if tt.want == "" {
+				return
+			}
+
+			var got map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("unmarshal: %v (output: %q)", err, buf.String())
+			}
+
+			if got["result"] != tt.want {
+				t.Errorf("result = %v, want %s", got["result"], tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookVerify_SecretFromStore(t *testing.T) {
+	setupConfigDir(t)
+
+	body := `{"id":1}`
+	path := writePayload(t, body)
+	sig := webhook.Sign("app-secret", []byte(body))
+	args := []string{"webhook", "verify", "--secret-from-store", "--payload", path, "--signature", sig}
+
+	if err := Execute(args); ExitCode(err) != ExitConfig {
+		t.Errorf("without saved credentials: ExitCode = %d, want %d (err %v)", ExitCode(err), ExitConfig, err)
+	}
+
+	if err := credstore.SetOAuthClient("default", credstore.OAuthClient{ClientID: "1", ClientSecret: "app-secret"}); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = captureStdout(t)
+
+	if err := Execute(args); err != nil {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestWebhookReplay(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/123/orders/456" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+
+		_, _ = w.Write([]byte(`{"id":456}`))
+	}))
+
+	var (
+		gotBody []byte
+		gotSig  string
+	)
+
+	handler := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotSig = r.Header.Get(webhook.SignatureHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(handler.Close)
+
+	_ = captureStdout(t)
+
+	err := Execute([]string{"webhook", "replay", "--secret", "s3cret", "--event", "order/created", "--id", "456", "--to", handler.URL})
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if want := `{"store_id":123,"event":"order/created","id":456}`; string(gotBody) != want {
+		t.Errorf("body = %s, want %s", gotBody, want)
+	}
+
+	if !webhook.Verify("s3cret", gotBody, gotSig) {
+		t.Errorf("signature %q does not verify", gotSig)
+	}
+}
+
+func TestWebhookReplay_Errors(t *testing.T) {
+	setupCredStore(t, map[string]credstore.StoreProfile{"test": {StoreID: "123", AccessToken: "tok"}}, "test")
+
+	setupMockAPIClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code":404,"message":"Not Found"}`))
+	}))
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(failing.Close)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+	}{
+		{"unknown event", []string{"--event", "coupon/created", "--id", "1", "--to", failing.URL}, ExitUsage},
+		{"bad url", []string{"--event", "order/created", "--id", "1", "--to", "localhost:3000"}, ExitUsage},
+		{"missing resource", []string{"--event", "order/created", "--id", "1", "--to", failing.URL}, ExitNotFound},
+		{"handler error", []string{"--event", "app/uninstalled", "--id", "123", "--to", failing.URL}, ExitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = captureStdout(t)
+
+			err := Execute(append([]string{"webhook", "replay", "--secret", "s"}, tt.args...))
+			if got := ExitCode(err); got != tt.wantCode {
+				t.Errorf("ExitCode = %d, want %d (err %v)", got, tt.wantCode, err)
+			}
+		})
+	}
+}
@@ -4,14 +4,85 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-
-	"github.com/yosuke-furukawa/json5/encoding/json5"
 )
 
 // File holds non-credential configuration.
-// Currently empty after migration to credstore; kept for future non-secret settings.
 type File struct {
+	// Version is the file format; see CurrentVersion and MigrateConfig.
+	Version int `json:"version,omitempty"`
+
 	ClientDomains map[string]string `json:"client_domains,omitempty"`
+
+	// ConfirmThreshold is the number of affected resources above which bulk
+	// writes require typing the store name rather than answering y/N.
+	ConfirmThreshold int `json:"confirm_threshold,omitempty"`
+	// ConfirmPreview is how many IDs bulk confirmations list.
+	ConfirmPreview int `json:"confirm_preview,omitempty"`
+	// ConfirmStoreBanner announces the active store on stderr before a
+	// command's first write.
+	ConfirmStoreBanner bool `json:"confirm_store_banner,omitempty"`
+	// Lang is the language of help and messages (en, es, pt); --lang and
+	// NUBE_LANG override it.
+	Lang string `json:"lang,omitempty"`
+	// LangPriority is the order in which translations of product, category
+	// and store names are picked for tables; --lang-priority overrides it.
+	LangPriority []string `json:"lang_priority,omitempty"`
+	// BrokerAllowlist, when set, lists the OAuth broker hosts (host or
+	// host:port) nube login may send tokens through.
+	BrokerAllowlist []string `json:"broker_allowlist,omitempty"`
+	// Theme overrides the colors used when color output is enabled.
+	Theme *Theme `json:"theme,omitempty"`
+	// HTTP tunes the API client's connections.
+	HTTP *HTTP `json:"http,omitempty"`
+	// AgentMaxItems caps the items of a list returned in an --envelope,
+	// to keep output small for LLM agents; 0 means no cap.
+	AgentMaxItems int `json:"agent_max_items,omitempty"`
+	// AgentDefaultSelect is the --select applied to --envelope output when
+	// the command line gives none.
+	AgentDefaultSelect string `json:"agent_default_select,omitempty"`
+	// DidYouMean makes get commands search for close matches when an ID or
+	// SKU is not found. Unset, it is on for table output only, so scripts
+	// don't pay for the extra request.
+	DidYouMean *bool `json:"did_you_mean,omitempty"`
+	// SMTP is the mail server reports are emailed through; without it,
+	// --email-to falls back on the local sendmail.
+	SMTP *SMTP `json:"smtp,omitempty"`
+}
+
+// SMTP holds mail server settings. The password may instead come from
+// NUBE_SMTP_PASSWORD, so it needn't be kept in the file.
+type SMTP struct {
+	Host string `json:"host"`
+	// Port defaults to 587, or 465 with TLS "tls".
+	Port     int    `json:"port,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// From is the sender address, e.g. "Reports <reports@example.com>".
+	From string `json:"from,omitempty"`
+	// TLS is starttls (default), tls (implicit) or none.
+	TLS string `json:"tls,omitempty"`
+}
+
+// HTTP holds connection pool settings for the API client. Zero values keep
+// the defaults.
+type HTTP struct {
+	MaxIdleConnsPerHost int `json:"max_idle_conns_per_host,omitempty"`
+	// IdleConnTimeout is a Go duration, e.g. "90s".
+	IdleConnTimeout   string `json:"idle_conn_timeout,omitempty"`
+	ForceHTTP2        bool   `json:"force_http2,omitempty"`
+	DisableKeepAlives bool   `json:"disable_keep_alives,omitempty"`
+}
+
+// Theme holds output colors as "#rrggbb", the table header style
+// (bold, underline, accent or none), and the terminal background (dark or
+// light; detected when empty). Empty fields keep the defaults.
+type Theme struct {
+	Success    string `json:"success,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Accent     string `json:"accent,omitempty"`
+	Muted      string `json:"muted,omitempty"`
+	Header     string `json:"header,omitempty"`
+	Background string `json:"background,omitempty"`
 }
 
 func WriteConfig(cfg File) error {
@@ -25,21 +96,27 @@ func WriteConfig(cfg File) error {
 		return err
 	}
 
+	cfg.Version = CurrentVersion
+
 	b, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		return fmt.Errorf("encode config json: %w", err)
 	}
 
-	b = append(b, '\n')
+	return writeAtomic(path, append(b, '\n'))
+}
 
+// writeAtomic replaces path with b through a temporary file, with 0600
+// permissions.
+func writeAtomic(path string, b []byte) error {
 	tmp := path + ".tmp"
 
 	if err := os.WriteFile(tmp, b, 0o600); err != nil {
-		return fmt.Errorf("write config: %w", err)
+		return fmt.Errorf("write %s: %w", path, err)
 	}
 
 	if err := os.Rename(tmp, path); err != nil {
-		return fmt.Errorf("commit config: %w", err)
+		return fmt.Errorf("commit %s: %w", path, err)
 	}
 
 	return nil
@@ -78,7 +155,7 @@ func ReadConfig() (File, error) {
 	}
 
 	var cfg File
-	if err := json5.Unmarshal(b, &cfg); err != nil {
+	if err := DecodeMigrated(b, configSteps, &cfg); err != nil {
 		return File{}, fmt.Errorf("parse config %s: %w", path, err)
 	}
 
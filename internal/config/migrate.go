@@ -0,0 +1,214 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/yosuke-furukawa/json5/encoding/json5"
+)
+
+// Step upgrades a decoded file by one version, in place.
+type Step func(raw map[string]any) error
+
+// CurrentVersion is the config.json format this build reads and writes.
+const CurrentVersion = 1
+
+// configSteps[i] upgrades config.json from version i to i+1.
+var configSteps = []Step{
+	// 0 → 1: files from before versioning only gain the version key.
+	func(map[string]any) error { return nil },
+}
+
+// ErrNewerVersion means a file was written by a newer nube, whose format
+// this build can't know.
+var ErrNewerVersion = errors.New("written by a newer version of nube; upgrade nube to use it")
+
+// Version returns the "version" of a decoded file; files from before
+// versioning have none and are version 0.
+func Version(raw map[string]any) (int, error) {
+	v, ok := raw["version"]
+	if !ok {
+		return 0, nil
+	}
+
+	n, ok := v.(float64)
+	if !ok || n < 0 || n != float64(int(n)) {
+		return 0, fmt.Errorf("version %v: want a whole number", v)
+	}
+
+	return int(n), nil
+}
+
+// Migrate upgrades raw in place with steps, where steps[i] turns version i
+// into i+1, and returns the version it started from.
+func Migrate(raw map[string]any, steps []Step) (int, error) {
+	from, err := Version(raw)
+	if err != nil {
+		return 0, err
+	}
+
+	if from > len(steps) {
+		return from, fmt.Errorf("version %d: %w", from, ErrNewerVersion)
+	}
+
+	for v := from; v < len(steps); v++ {
+		if err := steps[v](raw); err != nil {
+			return from, fmt.Errorf("upgrade from version %d: %w", v, err)
+		}
+
+		raw["version"] = v + 1
+	}
+
+	return from, nil
+}
+
+// DecodeMigrated decodes a versioned JSON5 file into v after upgrading it
+// with steps (see Migrate).
+func DecodeMigrated(b []byte, steps []Step, v any) error {
+	raw := map[string]any{}
+	if err := json5.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	if _, err := Migrate(raw, steps); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// MigrateResult describes the upgrade of one file.
+type MigrateResult struct {
+	Path   string `json:"path"`
+	From   int    `json:"from"`
+	To     int    `json:"to"`
+	Backup string `json:"backup,omitempty"`
+}
+
+// MigrateFile upgrades the file at path with steps and rewrites it, after
+// copying the original to <path>.v<from>.bak. Unknown keys are kept;
+// JSON5 comments are not. A missing or current file is left alone, as is
+// any file when dryRun is set.
+func MigrateFile(path string, steps []Step, dryRun bool) (MigrateResult, error) {
+	res := MigrateResult{Path: path, From: len(steps), To: len(steps)}
+
+	b, err := os.ReadFile(path) //nolint:gosec // config file path
+	if err != nil {
+		if os.IsNotExist(err) {
+			return res, nil
+		}
+
+		return res, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	raw := map[string]any{}
+	if err := json5.Unmarshal(b, &raw); err != nil {
+		return res, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	res.From, err = Migrate(raw, steps)
+	if err != nil {
+		return res, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if res.From == res.To || dryRun {
+		return res, nil
+	}
+
+	res.Backup = fmt.Sprintf("%s.v%d.bak", path, res.From)
+	if err := os.WriteFile(res.Backup, b, 0o600); err != nil {
+		return res, fmt.Errorf("back up %s: %w", path, err)
+	}
+
+	out, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return res, err
+	}
+
+	return res, writeAtomic(path, append(out, '\n'))
+}
+
+// MigrateConfig upgrades config.json to CurrentVersion (see MigrateFile).
+func MigrateConfig(dryRun bool) (MigrateResult, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return MigrateResult{}, err
+	}
+
+	return MigrateFile(path, configSteps, dryRun)
+}
+
+// UnknownKeys returns the dotted paths of keys in raw that v's JSON field
+// tags don't define, descending into nested structs and maps of structs.
+func UnknownKeys(raw map[string]any, v any) []string {
+	return unknownKeys(raw, reflect.TypeOf(v), "")
+}
+
+func unknownKeys(raw map[string]any, t reflect.Type, prefix string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	fields := map[string]reflect.Type{}
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = f.Type
+		}
+	}
+
+	var out []string
+
+	for _, k := range slices.Sorted(maps.Keys(raw)) {
+		ft, ok := fields[k]
+		if !ok {
+			out = append(out, prefix+k)
+
+			continue
+		}
+
+		out = append(out, nestedUnknownKeys(raw[k], ft, prefix+k)...)
+	}
+
+	return out
+}
+
+func nestedUnknownKeys(v any, t reflect.Type, path string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return unknownKeys(m, t, path+".")
+	case reflect.Map:
+		var out []string
+
+		for _, k := range slices.Sorted(maps.Keys(m)) {
+			out = append(out, nestedUnknownKeys(m[k], t.Elem(), path+"."+k)...)
+		}
+
+		return out
+	default:
+		return nil
+	}
+}
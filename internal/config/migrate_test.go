@@ -0,0 +1,120 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigSteps_MatchCurrentVersion(t *testing.T) {
+	t.Parallel()
+
+	if len(configSteps) != CurrentVersion {
+		t.Errorf("%d config steps for version %d", len(configSteps), CurrentVersion)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	var ran []int
+
+	steps := []Step{
+		func(raw map[string]any) error {
+			ran = append(ran, 0)
+			raw["b"] = raw["a"]
+			delete(raw, "a")
+			return nil
+		},
+		func(map[string]any) error { ran = append(ran, 1); return nil },
+	}
+
+	raw := map[string]any{"a": "x"}
+
+	from, err := Migrate(raw, steps)
+	if err != nil || from != 0 || !reflect.DeepEqual(ran, []int{0, 1}) || raw["b"] != "x" || raw["version"] != 2 {
+		t.Errorf("Migrate = %d, %v; ran %v, raw %v", from, err, ran, raw)
+	}
+
+	ran = nil
+
+	if from, err := Migrate(map[string]any{"version": 1.0}, steps); err != nil || from != 1 || !reflect.DeepEqual(ran, []int{1}) {
+		t.Errorf("from version 1: %d, %v; ran %v", from, err, ran)
+	}
+
+	if _, err := Migrate(map[string]any{"version": 3.0}, steps); !errors.Is(err, ErrNewerVersion) {
+		t.Errorf("newer version: err = %v", err)
+	}
+
+	if _, err := Migrate(map[string]any{"version": "2"}, steps); err == nil {
+		t.Error("expected error for a string version")
+	}
+}
+
+func TestMigrateConfig(t *testing.T) {
+	setupConfigDir(t)
+
+	path, _ := ConfigPath()
+	_, _ = EnsureDir()
+
+	old := "// comment\n{lang: \"es\", future_key: 1,}\n"
+	if err := os.WriteFile(path, []byte(old), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if res, err := MigrateConfig(true); err != nil || res.From != 0 || res.Backup != "" {
+		t.Fatalf("dry run = %+v, %v", res, err)
+	}
+
+	res, err := MigrateConfig(false)
+	if err != nil || res.From != 0 || res.To != CurrentVersion || res.Backup != path+".v0.bak" {
+		t.Fatalf("MigrateConfig = %+v, %v", res, err)
+	}
+
+	if b, _ := os.ReadFile(res.Backup); string(b) != old {
+		t.Errorf("backup = %q", b)
+	}
+
+	b, _ := os.ReadFile(path)
+	if !strings.Contains(string(b), `"future_key": 1`) || !strings.Contains(string(b), `"version": 1`) {
+		t.Errorf("migrated = %s", b)
+	}
+
+	if res, err := MigrateConfig(false); err != nil || res.From != res.To || res.Backup != "" {
+		t.Errorf("second run = %+v, %v", res, err)
+	}
+
+	cfg, err := ReadConfig()
+	if err != nil || cfg.Lang != "es" || cfg.Version != CurrentVersion {
+		t.Errorf("ReadConfig = %+v, %v", cfg, err)
+	}
+
+	_ = os.WriteFile(path, []byte(`{"version": 99}`), 0o600)
+
+	if _, err := ReadConfig(); !errors.Is(err, ErrNewerVersion) {
+		t.Errorf("newer file: err = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), "config.json.tmp")); !os.IsNotExist(err) {
+		t.Error("temporary file left behind")
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	t.Parallel()
+
+	raw := map[string]any{
+		"lang":  "es",
+		"typo":  true,
+		"theme": map[string]any{"accent": "#ffffff", "acent": "#000000"},
+		"smtp":  map[string]any{"host": "mail", "user": "x"},
+	}
+
+	got := UnknownKeys(raw, File{})
+	if want := []string{"smtp.user", "theme.acent", "typo"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UnknownKeys = %v, want %v", got, want)
+	}
+}
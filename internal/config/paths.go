@@ -36,6 +36,32 @@ func EnsureDir() (string, error) {
 	return dir, nil
 }
 
+// DataDir returns the directory for local state that isn't configuration
+// (journals, history), following XDG_DATA_HOME.
+func DataDir() (string, error) {
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		return filepath.Join(xdg, AppName), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home dir: %w", err)
+	}
+
+	return filepath.Join(home, ".local", "share", AppName), nil
+}
+
+// RuntimeDir returns the directory for state that shouldn't outlive the
+// login session (shell selections): $XDG_RUNTIME_DIR, which the system
+// clears on logout, or DataDir where there is none.
+func RuntimeDir() (string, error) {
+	if xdg := os.Getenv("XDG_RUNTIME_DIR"); xdg != "" {
+		return filepath.Join(xdg, AppName), nil
+	}
+
+	return DataDir()
+}
+
 func ConfigPath() (string, error) {
 	dir, err := Dir()
 	if err != nil {
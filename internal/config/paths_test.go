@@ -33,6 +33,36 @@ func TestConfigPath(t *testing.T) {
 	}
 }
 
+func TestDataDir(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+
+	dir, err := DataDir()
+	if err != nil {
+		t.Fatalf("DataDir() error = %v", err)
+	}
+
+	if want := filepath.Join(tmp, AppName); dir != want {
+		t.Errorf("DataDir() = %q, want %q", dir, want)
+	}
+}
+
+func TestRuntimeDir(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+	t.Setenv("XDG_RUNTIME_DIR", "")
+
+	if dir, _ := RuntimeDir(); dir != filepath.Join(tmp, "data", AppName) {
+		t.Errorf("without XDG_RUNTIME_DIR: RuntimeDir() = %q, want the data dir", dir)
+	}
+
+	t.Setenv("XDG_RUNTIME_DIR", filepath.Join(tmp, "run"))
+
+	if dir, _ := RuntimeDir(); dir != filepath.Join(tmp, "run", AppName) {
+		t.Errorf("RuntimeDir() = %q, want it under XDG_RUNTIME_DIR", dir)
+	}
+}
+
 func TestEnsureDir(t *testing.T) {
 	setupConfigDir(t)
 
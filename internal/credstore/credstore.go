@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"time"
 
 	"github.com/gberlati/nube-cli/internal/config"
 )
@@ -18,6 +19,15 @@ type StoreProfile struct {
 	Email       string   `json:"email,omitempty"`
 	Scopes      []string `json:"scopes,omitempty"`
 	CreatedAt   string   `json:"created_at,omitempty"`
+	// LastUsedAt is when a command last built a client from the profile,
+	// kept to within TouchInterval (see TouchStore).
+	LastUsedAt string `json:"last_used_at,omitempty"`
+	// APIBaseURL points the profile at another API, e.g. a mock or staging
+	// server; empty means the Tienda Nube API.
+	APIBaseURL string `json:"api_base_url,omitempty"`
+	// Defaults maps flag names (e.g. "per-page") to values used when a
+	// command on this store doesn't set them.
+	Defaults map[string]string `json:"defaults,omitempty"`
 }
 
 // OAuthClient holds the OAuth client ID and secret for a Tienda Nube app.
@@ -26,17 +36,47 @@ type OAuthClient struct {
 	ClientSecret string `json:"client_secret"` //nolint:gosec // field name, not a credential
 }
 
+// PartnerProfile holds a partners API token, which acts on a partner's
+// apps rather than on a store.
+type PartnerProfile struct {
+	PartnerID   string `json:"partner_id"`
+	AccessToken string `json:"access_token"` //nolint:gosec // G101: field name, not a credential
+	CreatedAt   string `json:"created_at,omitempty"`
+	// APIBaseURL points the profile at another partners API; empty means
+	// the Tienda Nube one.
+	APIBaseURL string `json:"api_base_url,omitempty"`
+}
+
+// CurrentVersion is the credentials.json format this build reads and writes.
+const CurrentVersion = 1
+
+// steps[i] upgrades credentials.json from version i to i+1.
+var steps = []config.Step{
+	// 0 → 1: files from before versioning only gain the version key.
+	func(map[string]any) error { return nil },
+}
+
 // File is the top-level credentials.json structure.
 type File struct {
-	DefaultStore string                  `json:"default_store,omitempty"`
-	Stores       map[string]StoreProfile `json:"stores,omitempty"`
-	OAuthClients map[string]OAuthClient  `json:"oauth_clients,omitempty"`
+	// Version is the file format; see CurrentVersion and Migrate.
+	Version      int                       `json:"version,omitempty"`
+	DefaultStore string                    `json:"default_store,omitempty"`
+	Stores       map[string]StoreProfile   `json:"stores,omitempty"`
+	OAuthClients map[string]OAuthClient    `json:"oauth_clients,omitempty"`
+	Partners     map[string]PartnerProfile `json:"partners,omitempty"`
 }
 
+// Errors of store profile lookups, for callers that explain them.
 var (
-	errNoStore        = errors.New("no store profile configured; run `nube login` first")
-	errStoreNotFound  = errors.New("store profile not found")
-	errAmbiguousStore = errors.New("multiple store profiles exist; use --store to select one")
+	ErrNoStore        = errors.New("no store profile configured; run `nube login` first")
+	ErrStoreNotFound  = errors.New("store profile not found")
+	ErrAmbiguousStore = errors.New("multiple store profiles exist; use --store to select one")
+)
+
+var (
+	errNoPartner        = errors.New("no partner profile configured; run `nube partner login` first")
+	errPartnerNotFound  = errors.New("partner profile not found")
+	errAmbiguousPartner = errors.New("multiple partner profiles exist; use --partner to select one")
 )
 
 // Path returns the path to credentials.json.
@@ -66,7 +106,7 @@ func Read() (File, error) {
 	}
 
 	var f File
-	if err := json.Unmarshal(b, &f); err != nil {
+	if err := config.DecodeMigrated(b, steps, &f); err != nil {
 		return File{}, fmt.Errorf("parse credentials %s: %w", path, err)
 	}
 
@@ -81,6 +121,7 @@ func Write(f File) error {
 	}
 
 	path := filepath.Join(dir, "credentials.json")
+	f.Version = CurrentVersion
 
 	b, err := json.MarshalIndent(f, "", "  ")
 	if err != nil {
@@ -101,6 +142,17 @@ func Write(f File) error {
 	return nil
 }
 
+// Migrate upgrades credentials.json to CurrentVersion (see
+// config.MigrateFile).
+func Migrate(dryRun bool) (config.MigrateResult, error) {
+	path, err := Path()
+	if err != nil {
+		return config.MigrateResult{}, err
+	}
+
+	return config.MigrateFile(path, steps, dryRun)
+}
+
 // SetStore adds or updates a named store profile.
 // If it's the only store, it automatically becomes the default.
 func SetStore(name string, profile StoreProfile) error {
@@ -131,7 +183,7 @@ func GetStore(name string) (StoreProfile, error) {
 
 	p, ok := f.Stores[name]
 	if !ok {
-		return StoreProfile{}, fmt.Errorf("%w: %s", errStoreNotFound, name)
+		return StoreProfile{}, fmt.Errorf("%w: %s", ErrStoreNotFound, name)
 	}
 
 	return p, nil
@@ -145,7 +197,7 @@ func RemoveStore(name string) error {
 	}
 
 	if _, ok := f.Stores[name]; !ok {
-		return fmt.Errorf("%w: %s", errStoreNotFound, name)
+		return fmt.Errorf("%w: %s", ErrStoreNotFound, name)
 	}
 
 	delete(f.Stores, name)
@@ -163,13 +215,52 @@ func RemoveStore(name string) error {
 	return Write(f)
 }
 
+// TouchInterval is how stale a profile's LastUsedAt must be before
+// TouchStore rewrites the credential file.
+const TouchInterval = time.Hour
+
+// TouchStore records now as the profile's LastUsedAt. It only writes when
+// the recorded time is older than TouchInterval, so frequent commands don't
+// rewrite the file on every run. It reports whether the file was written.
+func TouchStore(name string, now time.Time) (bool, error) {
+	f, err := Read()
+	if err != nil {
+		return false, err
+	}
+
+	p, ok := f.Stores[name]
+	if !ok {
+		return false, fmt.Errorf("%w: %s", ErrStoreNotFound, name)
+	}
+
+	if last, err := time.Parse(time.RFC3339, p.LastUsedAt); err == nil && now.Sub(last) < TouchInterval {
+		return false, nil
+	}
+
+	p.LastUsedAt = now.UTC().Format(time.RFC3339)
+	f.Stores[name] = p
+
+	return true, Write(f)
+}
+
+// Getenv reads an environment variable: os.Getenv, or the environment of
+// the process a daemon runs a command for.
+type Getenv func(string) string
+
 // ResolveStore resolves the active store profile using the priority chain:
-// --store flag → NUBE_STORE env → default_store → single-store auto-select.
+// --store flag → NUBE_STORE env → session (`nube use`) → default_store →
+// single-store auto-select.
 // Returns (name, profile, error).
 func ResolveStore(flagValue string) (string, StoreProfile, error) {
+	return ResolveStoreEnv(flagValue, os.Getenv)
+}
+
+// ResolveStoreEnv is ResolveStore with NUBE_STORE and the session read
+// through getenv.
+func ResolveStoreEnv(flagValue string, getenv Getenv) (string, StoreProfile, error) {
 	name := flagValue
 	if name == "" {
-		name = os.Getenv("NUBE_STORE")
+		name = getenv("NUBE_STORE")
 	}
 
 	f, err := Read()
@@ -178,13 +269,29 @@ func ResolveStore(flagValue string) (string, StoreProfile, error) {
 	}
 
 	if len(f.Stores) == 0 {
-		return "", StoreProfile{}, errNoStore
+		return "", StoreProfile{}, ErrNoStore
+	}
+
+	if name == "" {
+		session, err := SessionStoreEnv(getenv)
+		if err != nil {
+			return "", StoreProfile{}, err
+		}
+
+		if session != "" {
+			p, ok := f.Stores[session]
+			if !ok {
+				return "", StoreProfile{}, fmt.Errorf("%w: %s (selected with `nube use`; run `nube use --clear`)", ErrStoreNotFound, session)
+			}
+
+			return session, p, nil
+		}
 	}
 
 	if name != "" {
 		p, ok := f.Stores[name]
 		if !ok {
-			return "", StoreProfile{}, fmt.Errorf("%w: %s", errStoreNotFound, name)
+			return "", StoreProfile{}, fmt.Errorf("%w: %s", ErrStoreNotFound, name)
 		}
 
 		return name, p, nil
@@ -202,7 +309,7 @@ func ResolveStore(flagValue string) (string, StoreProfile, error) {
 		}
 	}
 
-	return "", StoreProfile{}, errAmbiguousStore
+	return "", StoreProfile{}, ErrAmbiguousStore
 }
 
 // ListStores returns all store profile names, sorted.
@@ -230,7 +337,7 @@ func SetDefault(name string) error {
 	}
 
 	if _, ok := f.Stores[name]; !ok {
-		return fmt.Errorf("%w: %s", errStoreNotFound, name)
+		return fmt.Errorf("%w: %s", ErrStoreNotFound, name)
 	}
 
 	f.DefaultStore = name
@@ -273,6 +380,74 @@ func SetOAuthClient(name string, client OAuthClient) error {
 	return Write(f)
 }
 
+// SetPartner adds or updates a named partner profile.
+func SetPartner(name string, profile PartnerProfile) error {
+	f, err := Read()
+	if err != nil {
+		return err
+	}
+
+	if f.Partners == nil {
+		f.Partners = make(map[string]PartnerProfile)
+	}
+
+	f.Partners[name] = profile
+
+	return Write(f)
+}
+
+// RemovePartner deletes a named partner profile.
+func RemovePartner(name string) error {
+	f, err := Read()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := f.Partners[name]; !ok {
+		return fmt.Errorf("%w: %s", errPartnerNotFound, name)
+	}
+
+	delete(f.Partners, name)
+
+	return Write(f)
+}
+
+// ResolvePartner resolves the active partner profile: --partner flag →
+// NUBE_PARTNER env → single-partner auto-select.
+// Returns (name, profile, error).
+func ResolvePartner(flagValue string) (string, PartnerProfile, error) {
+	name := flagValue
+	if name == "" {
+		name = os.Getenv("NUBE_PARTNER")
+	}
+
+	f, err := Read()
+	if err != nil {
+		return "", PartnerProfile{}, err
+	}
+
+	if len(f.Partners) == 0 {
+		return "", PartnerProfile{}, errNoPartner
+	}
+
+	if name != "" {
+		p, ok := f.Partners[name]
+		if !ok {
+			return "", PartnerProfile{}, fmt.Errorf("%w: %s", errPartnerNotFound, name)
+		}
+
+		return name, p, nil
+	}
+
+	if len(f.Partners) == 1 {
+		for k, v := range f.Partners {
+			return k, v, nil
+		}
+	}
+
+	return "", PartnerProfile{}, errAmbiguousPartner
+}
+
 // OAuthClientMissingError is returned when no OAuth client credentials are found.
 type OAuthClientMissingError struct {
 	Name string
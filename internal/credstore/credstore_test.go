@@ -3,7 +3,12 @@ package credstore
 import (
 	"errors"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func setupTempDir(t *testing.T) {
@@ -11,6 +16,8 @@ func setupTempDir(t *testing.T) {
 
 	tmp := t.TempDir()
 	t.Setenv("XDG_CONFIG_HOME", tmp)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+	t.Setenv("XDG_RUNTIME_DIR", filepath.Join(tmp, "run"))
 }
 
 func TestWriteRead_Roundtrip(t *testing.T) {
@@ -202,6 +209,119 @@ func TestResolveStore_Default(t *testing.T) {
 	}
 }
 
+func TestResolveStore_Session(t *testing.T) {
+	setupTempDir(t)
+
+	_ = SetStore("a", StoreProfile{StoreID: "1", AccessToken: "ta"})
+	_ = SetStore("b", StoreProfile{StoreID: "2", AccessToken: "tb"})
+	_ = SetDefault("a")
+
+	t.Setenv(SessionEnv, "one")
+
+	if err := SetSessionStore("one", "b"); err != nil {
+		t.Fatalf("SetSessionStore: %v", err)
+	}
+
+	if name, _, _ := ResolveStore(""); name != "b" {
+		t.Errorf("session one: got %q, want b", name)
+	}
+
+	if name, _, _ := ResolveStore("a"); name != "a" {
+		t.Errorf("--store over session: got %q, want a", name)
+	}
+
+	// Another session keeps the default.
+	t.Setenv(SessionEnv, "two")
+
+	if name, _, _ := ResolveStore(""); name != "a" {
+		t.Errorf("session two: got %q, want a", name)
+	}
+
+	t.Setenv(SessionEnv, "one")
+	_ = RemoveStore("b")
+
+	if _, _, err := ResolveStore(""); !errors.Is(err, ErrStoreNotFound) {
+		t.Errorf("removed profile: err = %v", err)
+	}
+
+	if err := ClearSessionStore("one"); err != nil {
+		t.Fatalf("ClearSessionStore: %v", err)
+	}
+
+	if name, _, _ := ResolveStore(""); name != "a" {
+		t.Errorf("cleared: got %q, want a", name)
+	}
+
+	t.Setenv(SessionEnv, "../x")
+
+	if _, err := SessionKey(); err == nil {
+		t.Error("expected error for a bad NUBE_SESSION")
+	}
+}
+
+func TestSessionKey_ParentStartTime(t *testing.T) {
+	t.Setenv(SessionEnv, "")
+
+	if _, err := processStart(os.Getppid()); err != nil {
+		t.Skipf("no process start times here: %v", err)
+	}
+
+	key, err := SessionKey()
+	if err != nil {
+		t.Fatalf("SessionKey: %v", err)
+	}
+
+	// A reused parent ID alone must not match the session.
+	if prefix := "ppid-" + strconv.Itoa(os.Getppid()) + "-"; !strings.HasPrefix(key, prefix) || len(key) == len(prefix) {
+		t.Errorf("SessionKey() = %q, want %s<start>", key, prefix)
+	}
+}
+
+func TestSetSessionStore_PrunesStale(t *testing.T) {
+	setupTempDir(t)
+
+	if _, err := processStart(os.Getpid()); err != nil {
+		t.Skipf("no process start times here: %v", err)
+	}
+
+	dir, err := sessionDir()
+	if err != nil {
+		t.Fatalf("sessionDir: %v", err)
+	}
+
+	live := processSessionKey(os.Getpid())
+	reused := "ppid-" + strconv.Itoa(os.Getpid()) + "-1"
+
+	for _, key := range []string{live, reused, "ppid-" + strconv.Itoa(os.Getpid()), "recent", "old"} {
+		if err := SetSessionStore(key, "a"); err != nil {
+			t.Fatalf("SetSessionStore(%q): %v", key, err)
+		}
+	}
+
+	old := time.Now().Add(-sessionMaxAge - time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "old"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetSessionStore("new", "a"); err != nil {
+		t.Fatalf("SetSessionStore: %v", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+
+	want := []string{live, "new", "recent"}
+	slices.Sort(want)
+
+	if !slices.Equal(got, want) {
+		t.Errorf("sessions = %v, want %v", got, want)
+	}
+}
+
 func TestResolveStore_SingleAutoSelect(t *testing.T) {
 	setupTempDir(t)
 
@@ -258,6 +378,38 @@ func TestListStores(t *testing.T) {
 	}
 }
 
+func TestTouchStore(t *testing.T) {
+	setupTempDir(t)
+
+	_ = SetStore("a", StoreProfile{StoreID: "1", AccessToken: "t"})
+
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		at    time.Time
+		wrote bool
+		want  string
+	}{
+		{now, true, "2026-03-01T10:00:00Z"},
+		{now.Add(59 * time.Minute), false, "2026-03-01T10:00:00Z"},
+		{now.Add(TouchInterval), true, "2026-03-01T11:00:00Z"},
+	} {
+		wrote, err := TouchStore("a", tt.at)
+		if err != nil {
+			t.Fatalf("TouchStore: %v", err)
+		}
+
+		p, _ := GetStore("a")
+		if wrote != tt.wrote || p.LastUsedAt != tt.want {
+			t.Errorf("TouchStore(%s) = %v, last_used_at %q; want %v, %q", tt.at, wrote, p.LastUsedAt, tt.wrote, tt.want)
+		}
+	}
+
+	if _, err := TouchStore("missing", now); err == nil {
+		t.Error("expected error for a missing profile")
+	}
+}
+
 func TestSetDefault(t *testing.T) {
 	setupTempDir(t)
 
@@ -318,3 +470,43 @@ func TestGetOAuthClient_Missing(t *testing.T) {
 func isOAuthClientMissing(err error, target **OAuthClientMissingError) bool {
 	return errors.As(err, target)
 }
+
+func TestResolvePartner(t *testing.T) {
+	setupTempDir(t)
+	t.Setenv("NUBE_PARTNER", "")
+
+	if _, _, err := ResolvePartner(""); !errors.Is(err, errNoPartner) {
+		t.Fatalf("no partners: err = %v", err)
+	}
+
+	_ = SetPartner("a", PartnerProfile{PartnerID: "1", AccessToken: "ta"})
+
+	if name, _, err := ResolvePartner(""); err != nil || name != "a" {
+		t.Fatalf("single partner: name=%q err=%v", name, err)
+	}
+
+	_ = SetPartner("b", PartnerProfile{PartnerID: "2", AccessToken: "tb"})
+
+	if _, _, err := ResolvePartner(""); !errors.Is(err, errAmbiguousPartner) {
+		t.Errorf("two partners: err = %v", err)
+	}
+
+	t.Setenv("NUBE_PARTNER", "b")
+
+	if name, p, err := ResolvePartner(""); err != nil || name != "b" || p.AccessToken != "tb" {
+		t.Errorf("env: name=%q token=%q err=%v", name, p.AccessToken, err)
+	}
+
+	if _, _, err := ResolvePartner("c"); !errors.Is(err, errPartnerNotFound) {
+		t.Errorf("unknown: err = %v", err)
+	}
+
+	if err := RemovePartner("a"); err != nil {
+		t.Fatalf("RemovePartner: %v", err)
+	}
+
+	f, _ := Read()
+	if _, ok := f.Partners["a"]; ok || len(f.Stores) != 0 {
+		t.Errorf("after remove: %+v", f)
+	}
+}
@@ -0,0 +1,26 @@
+package credstore
+
+import (
+	"fmt"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// processStart returns when process pid started, in microseconds since the
+// epoch.
+func processStart(pid int) (string, error) {
+	kp, err := unix.SysctlKinfoProc("kern.proc.pid", pid)
+	if err != nil {
+		return "", fmt.Errorf("read process %d: %w", pid, err)
+	}
+
+	// A pid with no process yields an empty record rather than an error.
+	if kp.Proc.P_pid != int32(pid) { //nolint:gosec // pids fit in int32
+		return "", fmt.Errorf("read process %d: no such process", pid)
+	}
+
+	start := kp.Proc.P_starttime
+
+	return strconv.FormatInt(start.Sec*1_000_000+int64(start.Usec), 10), nil
+}
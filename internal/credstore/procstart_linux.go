@@ -0,0 +1,27 @@
+package credstore
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// processStart returns when process pid started, in clock ticks since boot
+// (field 22 of /proc/<pid>/stat).
+func processStart(pid int) (string, error) {
+	b, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return "", fmt.Errorf("read process %d: %w", pid, err)
+	}
+
+	// The command name in parentheses may hold spaces; count fields after it.
+	s := string(b)
+
+	fields := strings.Fields(s[strings.LastIndexByte(s, ')')+1:])
+	if len(fields) < 20 {
+		return "", fmt.Errorf("read process %d: short stat", pid)
+	}
+
+	return fields[19], nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !windows
+
+package credstore
+
+import "errors"
+
+// processStart can't tell when a process started here, so session keys are
+// the pid alone and stale ones go by age only.
+func processStart(int) (string, error) {
+	return "", errors.New("process start time not supported")
+}
@@ -0,0 +1,25 @@
+package credstore
+
+import (
+	"fmt"
+	"strconv"
+
+	"golang.org/x/sys/windows"
+)
+
+// processStart returns when process pid started, as its creation time in
+// nanoseconds since the epoch.
+func processStart(pid int) (string, error) {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid)) //nolint:gosec // pids fit in uint32
+	if err != nil {
+		return "", fmt.Errorf("read process %d: %w", pid, err)
+	}
+	defer windows.CloseHandle(h) //nolint:errcheck // read-only handle
+
+	var creation, exit, kernel, user windows.Filetime
+	if err := windows.GetProcessTimes(h, &creation, &exit, &kernel, &user); err != nil {
+		return "", fmt.Errorf("read process %d: %w", pid, err)
+	}
+
+	return strconv.FormatInt(creation.Nanoseconds(), 10), nil
+}
@@ -0,0 +1,197 @@
+package credstore
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/config"
+)
+
+// SessionEnv names the variable that identifies a shell session. When it
+// is unset, the session is the parent process (normally the shell).
+const SessionEnv = "NUBE_SESSION"
+
+var sessionIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// sessionMaxAge is how long a selection is kept without being used.
+const sessionMaxAge = 30 * 24 * time.Hour
+
+// SessionKey returns the key of the current shell session: $NUBE_SESSION,
+// or the parent process (see processSessionKey).
+func SessionKey() (string, error) {
+	return SessionKeyEnv(os.Getenv)
+}
+
+// SessionKeyEnv is SessionKey with $NUBE_SESSION read through getenv.
+func SessionKeyEnv(getenv Getenv) (string, error) {
+	if id := getenv(SessionEnv); id != "" {
+		if !sessionIDPattern.MatchString(id) {
+			return "", fmt.Errorf("%s=%q: use letters, digits, - and _ only", SessionEnv, id)
+		}
+
+		return id, nil
+	}
+
+	return processSessionKey(os.Getppid()), nil
+}
+
+// processSessionKey returns the session key of the process pid: its ID and
+// start time, so a later shell that reuses the ID is a new session. Where
+// the start time can't be read the key is the ID alone.
+func processSessionKey(pid int) string {
+	key := "ppid-" + strconv.Itoa(pid)
+	if start, err := processStart(pid); err == nil {
+		key += "-" + start
+	}
+
+	return key
+}
+
+// NewSessionID returns a random value for NUBE_SESSION.
+func NewSessionID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+func sessionPath(key string) (string, error) {
+	if !sessionIDPattern.MatchString(strings.TrimPrefix(key, "ppid-")) {
+		return "", fmt.Errorf("bad session key %q", key)
+	}
+
+	dir, err := sessionDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, key), nil
+}
+
+// sessionDir holds a file per session naming its store profile. It is under
+// the runtime dir, so selections end with the login session where the
+// system supports it.
+func sessionDir() (string, error) {
+	dir, err := config.RuntimeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "sessions"), nil
+}
+
+// SessionStore returns the store profile selected with `nube use` for the
+// current session, or "" when there is none.
+func SessionStore() (string, error) {
+	return SessionStoreEnv(os.Getenv)
+}
+
+// SessionStoreEnv is SessionStore with $NUBE_SESSION read through getenv.
+func SessionStoreEnv(getenv Getenv) (string, error) {
+	key, err := SessionKeyEnv(getenv)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := sessionPath(key)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := os.ReadFile(path) //nolint:gosec // session file path
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("read session: %w", err)
+	}
+
+	// Using a selection keeps it from being pruned.
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return strings.TrimSpace(string(b)), nil
+}
+
+// SetSessionStore selects a store profile for the session with the given
+// key (see SessionKey).
+func SetSessionStore(key, name string) error {
+	path, err := sessionPath(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create session dir: %w", err)
+	}
+
+	if err := os.WriteFile(path, []byte(name+"\n"), 0o600); err != nil {
+		return fmt.Errorf("write session: %w", err)
+	}
+
+	pruneSessions(filepath.Dir(path), time.Now())
+
+	return nil
+}
+
+// pruneSessions removes the selections of shells that have exited, and of
+// any session unused for sessionMaxAge. Failing to prune never fails the
+// command.
+func pruneSessions(dir string, now time.Time) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		if now.Sub(info.ModTime()) > sessionMaxAge || staleProcessSession(e.Name()) {
+			_ = os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
+
+// staleProcessSession reports whether key is the session of a process
+// that is gone: its ID is free or now belongs to another process.
+func staleProcessSession(key string) bool {
+	rest, ok := strings.CutPrefix(key, "ppid-")
+	if !ok {
+		return false
+	}
+
+	id, _, _ := strings.Cut(rest, "-")
+
+	pid, err := strconv.Atoi(id)
+	if err != nil {
+		return true
+	}
+
+	return processSessionKey(pid) != key
+}
+
+// ClearSessionStore drops the session's selection, if any.
+func ClearSessionStore(key string) error {
+	path, err := sessionPath(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove session: %w", err)
+	}
+
+	return nil
+}
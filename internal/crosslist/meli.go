@@ -0,0 +1,313 @@
+// Package crosslist maps store products to other marketplaces' listing
+// schemas, noting what can't be carried over.
+package crosslist
+
+import (
+	"html"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MeLi limits. Titles longer than 60 characters are rejected, and items
+// take at most 10 pictures.
+const (
+	meliTitleMax    = 60
+	meliPicturesMax = 10
+)
+
+// Translator picks the text of a multilingual field such as a product name.
+type Translator func(obj map[string]any, key string) string
+
+// MeLiOptions are the listing settings MercadoLibre needs that products
+// don't have.
+type MeLiOptions struct {
+	// CategoryID is used for products without a category_id custom field.
+	CategoryID  string
+	ListingType string
+	Condition   string
+	Currency    string
+	Translate   Translator
+}
+
+// Listing is one product mapped to a marketplace item. Item is ready to
+// send only when Errors is empty; Warnings note what was changed or
+// dropped on the way.
+type Listing struct {
+	ProductID string         `json:"product_id"`
+	OK        bool           `json:"ok"`
+	Errors    []string       `json:"errors,omitempty"`
+	Warnings  []string       `json:"warnings,omitempty"`
+	Item      map[string]any `json:"item"`
+}
+
+// MeLi maps product to a MercadoLibre item. fields are the product's
+// custom fields for MercadoLibre, by key: category_id overrides the
+// category, and every other key is sent as the attribute of that ID
+// (e.g. MODEL, COLOR).
+func MeLi(product map[string]any, fields map[string]string, opts MeLiOptions) Listing {
+	tr := opts.Translate
+	l := Listing{ProductID: scalar(product["id"])}
+
+	title := strings.TrimSpace(tr(product, "name"))
+	if r := []rune(title); len(r) > meliTitleMax {
+		title = strings.TrimSpace(string(r[:meliTitleMax]))
+		l.warn("title cut to 60 characters: " + strconv.Quote(title))
+	}
+
+	if title == "" {
+		l.fail("no name")
+	}
+
+	category := opts.CategoryID
+	if c := fields["category_id"]; c != "" {
+		category = c
+	}
+
+	if category == "" {
+		l.fail("no MercadoLibre category: pass --category or set a category_id custom field")
+	}
+
+	item := map[string]any{
+		"title":           title,
+		"category_id":     category,
+		"currency_id":     opts.Currency,
+		"buying_mode":     "buy_it_now",
+		"listing_type_id": opts.ListingType,
+		"condition":       opts.Condition,
+	}
+
+	if desc := plainText(tr(product, "description")); desc != "" {
+		item["description"] = map[string]any{"plain_text": desc}
+	}
+
+	item["pictures"] = l.pictures(product)
+	item["attributes"] = attributes(product, fields)
+
+	variants, _ := product["variants"].([]any)
+	if len(variants) == 0 {
+		l.fail("no variants")
+	}
+
+	if len(variants) == 1 {
+		v, _ := variants[0].(map[string]any)
+		l.single(item, v)
+	} else {
+		l.variations(item, product, variants, tr)
+	}
+
+	l.Item = item
+	l.OK = len(l.Errors) == 0
+
+	return l
+}
+
+func (l *Listing) fail(msg string) { l.Errors = append(l.Errors, msg) }
+func (l *Listing) warn(msg string) { l.Warnings = append(l.Warnings, msg) }
+
+func (l *Listing) pictures(product map[string]any) []any {
+	images, _ := product["images"].([]any)
+
+	pics := []any{}
+
+	for _, img := range images {
+		m, _ := img.(map[string]any)
+		if src := scalar(m["src"]); src != "" {
+			pics = append(pics, map[string]any{"source": src})
+		}
+	}
+
+	if len(pics) == 0 {
+		l.fail("no pictures; MercadoLibre requires at least one")
+	}
+
+	if len(pics) > meliPicturesMax {
+		l.warn(strconv.Itoa(len(pics)-meliPicturesMax) + " pictures dropped; 10 at most")
+		pics = pics[:meliPicturesMax]
+	}
+
+	return pics
+}
+
+// attributes are the product's brand plus its custom fields.
+func attributes(product map[string]any, fields map[string]string) []any {
+	attrs := []any{}
+	seen := map[string]bool{}
+
+	add := func(id, value string) {
+		if value == "" || seen[id] {
+			return
+		}
+
+		seen[id] = true
+		attrs = append(attrs, map[string]any{"id": id, "value_name": value})
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		if key != "category_id" {
+			add(strings.ToUpper(key), fields[key])
+		}
+	}
+
+	add("BRAND", scalar(product["brand"]))
+
+	return attrs
+}
+
+// single sets price, stock and identifiers of a product without variations.
+func (l *Listing) single(item, v map[string]any) {
+	price, ok := variantPrice(v)
+	if !ok {
+		l.fail("no price")
+	}
+
+	item["price"] = price
+	item["available_quantity"] = l.quantity(v, "")
+
+	attrs, _ := item["attributes"].([]any)
+	item["attributes"] = append(attrs, variantAttributes(v)...)
+}
+
+func (l *Listing) variations(item, product map[string]any, variants []any, tr Translator) {
+	names := attributeNames(product, tr)
+	vars := make([]any, 0, len(variants))
+
+	var first float64
+
+	mixed := false
+
+	for i, raw := range variants {
+		v, _ := raw.(map[string]any)
+		label := "variant " + scalar(v["id"])
+
+		price, ok := variantPrice(v)
+		if !ok {
+			l.fail(label + ": no price")
+		}
+
+		if i == 0 {
+			first = price
+			item["price"] = price
+		} else if price != first && !mixed {
+			mixed = true
+
+			l.warn("variants have different prices; some MercadoLibre categories need one price per item")
+		}
+
+		combos := []any{}
+		values, _ := v["values"].([]any)
+
+		for j, val := range values {
+			text := tr(map[string]any{"v": val}, "v")
+			if j >= len(names) || text == "" {
+				continue
+			}
+
+			combos = append(combos, map[string]any{"name": names[j], "value_name": text})
+		}
+
+		if len(combos) == 0 {
+			l.fail(label + ": no attribute values to tell it apart")
+		}
+
+		vars = append(vars, map[string]any{
+			"price":                  price,
+			"available_quantity":     l.quantity(v, label+": "),
+			"attribute_combinations": combos,
+			"attributes":             variantAttributes(v),
+		})
+	}
+
+	item["variations"] = vars
+}
+
+// quantity is the variant's stock; unlimited stock, which MercadoLibre
+// can't express, is listed as 1.
+func (l *Listing) quantity(v map[string]any, label string) int {
+	s := scalar(v["stock"])
+	if s == "" {
+		l.warn(label + "unlimited stock listed as 1")
+
+		return 1
+	}
+
+	n, _ := strconv.Atoi(s)
+	if n <= 0 {
+		l.warn(label + "out of stock")
+
+		return 0
+	}
+
+	return n
+}
+
+// variantPrice is the regular price; promotions are left to MercadoLibre's
+// own tools.
+func variantPrice(v map[string]any) (float64, bool) {
+	p, err := strconv.ParseFloat(scalar(v["price"]), 64)
+
+	return p, err == nil && p > 0
+}
+
+func variantAttributes(v map[string]any) []any {
+	attrs := []any{}
+
+	if sku := scalar(v["sku"]); sku != "" {
+		attrs = append(attrs, map[string]any{"id": "SELLER_SKU", "value_name": sku})
+	}
+
+	if gtin := scalar(v["barcode"]); gtin != "" {
+		attrs = append(attrs, map[string]any{"id": "GTIN", "value_name": gtin})
+	}
+
+	return attrs
+}
+
+// attributeNames are the names of the product's variant attributes, such
+// as Color and Talle, in the order of variant values.
+func attributeNames(product map[string]any, tr Translator) []string {
+	raw, _ := product["attributes"].([]any)
+	names := make([]string, len(raw))
+
+	for i, a := range raw {
+		names[i] = tr(map[string]any{"a": a}, "a")
+	}
+
+	return names
+}
+
+var (
+	tagPattern   = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</li>|</h[1-6]>`)
+	anyTag       = regexp.MustCompile(`<[^>]*>`)
+	blankPattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// plainText turns a product's HTML description into the plain text
+// MercadoLibre accepts.
+func plainText(s string) string {
+	s = tagPattern.ReplaceAllString(s, "\n")
+	s = anyTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+
+	return strings.TrimSpace(blankPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+func scalar(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
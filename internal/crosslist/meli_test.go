@@ -0,0 +1,161 @@
+package crosslist
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// esText reads the Spanish text of a multilingual field, or a plain string.
+func esText(obj map[string]any, key string) string {
+	switch v := obj[key].(type) {
+	case string:
+		return v
+	case map[string]any:
+		s, _ := v["es"].(string)
+
+		return s
+	default:
+		return ""
+	}
+}
+
+var meliOpts = MeLiOptions{CategoryID: "MLA1234", ListingType: "gold_special", Condition: "new", Currency: "ARS", Translate: esText}
+
+func decodeProduct(t *testing.T, s string) map[string]any {
+	t.Helper()
+
+	var p map[string]any
+	if err := json.Unmarshal([]byte(s), &p); err != nil {
+		t.Fatal(err)
+	}
+
+	return p
+}
+
+func TestMeLi_Single(t *testing.T) {
+	t.Parallel()
+
+	p := decodeProduct(t, `{
+		"id": 7, "brand": "Acme",
+		"name": {"es": "Remera de algodón peinado con estampa exclusiva de la temporada de verano"},
+		"description": {"es": "<p>Suave &amp; liviana.</p><ul><li>100% algodón</li></ul>"},
+		"images": [{"src": "https://cdn.test/1.jpg"}],
+		"variants": [{"id": 70, "price": "1500.00", "stock": 4, "sku": "REM-1", "barcode": "7790001"}]
+	}`)
+
+	l := MeLi(p, map[string]string{"model": "Verano", "category_id": "MLA999"}, meliOpts)
+
+	if !l.OK || l.ProductID != "7" {
+		t.Fatalf("listing = %+v", l)
+	}
+
+	item := l.Item
+	if title := item["title"].(string); len([]rune(title)) > 60 || !strings.HasPrefix(title, "Remera de algodón") {
+		t.Errorf("title = %q", title)
+	}
+
+	if len(l.Warnings) != 1 || !strings.Contains(l.Warnings[0], "title cut") {
+		t.Errorf("warnings = %q", l.Warnings)
+	}
+
+	if item["category_id"] != "MLA999" || item["price"] != 1500.0 || item["available_quantity"] != 4 {
+		t.Errorf("item = %v", item)
+	}
+
+	if desc := item["description"].(map[string]any)["plain_text"]; desc != "Suave & liviana.\n100% algodón" {
+		t.Errorf("description = %q", desc)
+	}
+
+	var ids []string
+	for _, a := range item["attributes"].([]any) {
+		ids = append(ids, a.(map[string]any)["id"].(string))
+	}
+
+	if !slices.Equal(ids, []string{"MODEL", "BRAND", "SELLER_SKU", "GTIN"}) {
+		t.Errorf("attributes = %v", ids)
+	}
+}
+
+func TestMeLi_Variations(t *testing.T) {
+	t.Parallel()
+
+	p := decodeProduct(t, `{
+		"id": 8, "name": {"es": "Zapatilla"},
+		"attributes": [{"es": "Color"}, {"es": "Talle"}],
+		"images": [{"src": "https://cdn.test/z.jpg"}],
+		"variants": [
+			{"id": 81, "price": "100", "stock": null, "values": [{"es": "Rojo"}, {"es": "40"}]},
+			{"id": 82, "price": "120", "stock": 0, "values": [{"es": "Azul"}, {"es": "41"}]}
+		]
+	}`)
+
+	l := MeLi(p, nil, meliOpts)
+	if !l.OK {
+		t.Fatalf("errors = %q", l.Errors)
+	}
+
+	vars := l.Item["variations"].([]any)
+	if len(vars) != 2 {
+		t.Fatalf("variations = %v", vars)
+	}
+
+	combos := vars[0].(map[string]any)["attribute_combinations"].([]any)
+	if len(combos) != 2 || combos[1].(map[string]any)["name"] != "Talle" || combos[1].(map[string]any)["value_name"] != "40" {
+		t.Errorf("combinations = %v", combos)
+	}
+
+	for _, want := range []string{"different prices", "variant 81: unlimited stock", "variant 82: out of stock"} {
+		if !slices.ContainsFunc(l.Warnings, func(w string) bool { return strings.Contains(w, want) }) {
+			t.Errorf("warnings %q lack %q", l.Warnings, want)
+		}
+	}
+}
+
+func TestMeLi_Unmappable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		product string
+		opts    MeLiOptions
+		want    string
+	}{
+		{
+			name:    "no category",
+			product: `{"id":1,"name":"A","images":[{"src":"x"}],"variants":[{"price":"1","stock":1}]}`,
+			opts:    MeLiOptions{Translate: esText},
+			want:    "no MercadoLibre category",
+		},
+		{
+			name:    "no pictures",
+			product: `{"id":1,"name":"A","variants":[{"price":"1","stock":1}]}`,
+			opts:    meliOpts,
+			want:    "no pictures",
+		},
+		{
+			name:    "no price",
+			product: `{"id":1,"name":"A","images":[{"src":"x"}],"variants":[{"price":null,"stock":1}]}`,
+			opts:    meliOpts,
+			want:    "no price",
+		},
+		{
+			name:    "variations without values",
+			product: `{"id":1,"name":"A","images":[{"src":"x"}],"variants":[{"id":2,"price":"1"},{"id":3,"price":"1"}]}`,
+			opts:    meliOpts,
+			want:    "variant 2: no attribute values",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			l := MeLi(decodeProduct(t, tt.product), nil, tt.opts)
+			if l.OK || len(l.Errors) == 0 || !strings.Contains(strings.Join(l.Errors, "; "), tt.want) {
+				t.Errorf("ok=%v errors=%q, want %q", l.OK, l.Errors, tt.want)
+			}
+		})
+	}
+}
@@ -11,36 +11,48 @@ import (
 
 	"github.com/gberlati/nube-cli/internal/api"
 	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/i18n"
 )
 
+// Format returns the message to show for err, in the default language.
 func Format(err error) string {
+	return FormatIn(i18n.Default(), err)
+}
+
+// FormatIn is Format in the language of tr.
+func FormatIn(tr *i18n.Translator, err error) string {
 	if err == nil {
 		return ""
 	}
 
+	var userErr *UserFacingError
+	if errors.As(err, &userErr) {
+		return userErr.Message
+	}
+
 	var parseErr *kong.ParseError
 	if errors.As(err, &parseErr) {
-		return formatParseError(parseErr)
+		return formatParseError(tr, parseErr)
 	}
 
 	var credErr *credstore.OAuthClientMissingError
 	if errors.As(err, &credErr) {
-		return "OAuth client credentials missing.\nCreate an app at https://partners.tiendanube.com and save credentials.\nThen run: nube auth credentials <credentials.json>"
+		return tr.T("OAuth client credentials missing.\nCreate an app at https://partners.tiendanube.com and save credentials.\nThen run: nube auth credentials <credentials.json>")
 	}
 
 	var apiErr *api.APIError
 	if errors.As(err, &apiErr) {
-		return fmt.Sprintf("API error (HTTP %d): %s", apiErr.StatusCode, apiErr.Message)
+		return tr.Sprintf("API error (HTTP %d): %s", apiErr.StatusCode, apiErr.Message)
 	}
 
 	var authErr *api.AuthError
 	if errors.As(err, &authErr) {
-		return "Authentication failed. Check your access token or run: nube login"
+		return tr.T("Authentication failed. Check your access token or run: nube login")
 	}
 
 	var rateLimitErr *api.RateLimitError
 	if errors.As(err, &rateLimitErr) {
-		return fmt.Sprintf("Rate limit exceeded after %d retries. Try again in a few seconds.", rateLimitErr.Retries)
+		return tr.Sprintf("Rate limit exceeded after %d retries. Try again in a few seconds.", rateLimitErr.Retries)
 	}
 
 	var notFoundErr *api.NotFoundError
@@ -50,37 +62,32 @@ func Format(err error) string {
 
 	var validationErr *api.ValidationError
 	if errors.As(err, &validationErr) {
-		return formatValidationError(validationErr)
+		return formatValidationError(tr, validationErr)
 	}
 
 	var paymentErr *api.PaymentRequiredError
 	if errors.As(err, &paymentErr) {
-		return "Store access suspended (payment required). Check your Tienda Nube subscription."
+		return tr.T("Store access suspended (payment required). Check your Tienda Nube subscription.")
 	}
 
 	var permDeniedErr *api.PermissionDeniedError
 	if errors.As(err, &permDeniedErr) {
 		if permDeniedErr.Message != "" {
-			return fmt.Sprintf("Permission denied: %s", permDeniedErr.Message)
+			return tr.Sprintf("Permission denied: %s", permDeniedErr.Message)
 		}
 
-		return "Permission denied"
+		return tr.T("Permission denied")
 	}
 
 	var cbErr *api.CircuitBreakerError
 	if errors.As(err, &cbErr) {
-		return "API temporarily unavailable (circuit breaker open). Try again shortly."
+		return tr.T("API temporarily unavailable (circuit breaker open). Try again shortly.")
 	}
 
 	if errors.Is(err, os.ErrNotExist) {
 		return err.Error()
 	}
 
-	var userErr *UserFacingError
-	if errors.As(err, &userErr) {
-		return userErr.Message
-	}
-
 	return err.Error()
 }
 
@@ -110,7 +117,7 @@ func NewUserFacingError(message string, cause error) error {
 	return &UserFacingError{Message: message, Cause: cause}
 }
 
-func formatValidationError(err *api.ValidationError) string {
+func formatValidationError(tr *i18n.Translator, err *api.ValidationError) string {
 	// Sort field names for deterministic output.
 	fields := make([]string, 0, len(err.Fields))
 	for f := range err.Fields {
@@ -125,10 +132,10 @@ func formatValidationError(err *api.ValidationError) string {
 		parts = append(parts, fmt.Sprintf("%s: %s", f, strings.Join(err.Fields[f], ", ")))
 	}
 
-	return fmt.Sprintf("Validation error: %s", strings.Join(parts, "; "))
+	return tr.Sprintf("Validation error: %s", strings.Join(parts, "; "))
 }
 
-func formatParseError(err *kong.ParseError) string {
+func formatParseError(tr *i18n.Translator, err *kong.ParseError) string {
 	msg := err.Error()
 
 	if strings.Contains(msg, "did you mean") {
@@ -136,11 +143,11 @@ func formatParseError(err *kong.ParseError) string {
 	}
 
 	if strings.HasPrefix(msg, "unknown flag") {
-		return msg + "\nRun with --help to see available flags"
+		return msg + "\n" + tr.T("Run with --help to see available flags")
 	}
 
 	if strings.Contains(msg, "missing") || strings.Contains(msg, "required") {
-		return msg + "\nRun with --help to see usage"
+		return msg + "\n" + tr.T("Run with --help to see usage")
 	}
 
 	return msg
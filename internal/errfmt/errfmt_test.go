@@ -2,12 +2,14 @@ package errfmt_test
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/gberlati/nube-cli/internal/api"
 	"github.com/gberlati/nube-cli/internal/credstore"
 	"github.com/gberlati/nube-cli/internal/errfmt"
+	"github.com/gberlati/nube-cli/internal/i18n"
 )
 
 var (
@@ -116,6 +118,25 @@ func TestFormat(t *testing.T) {
 	}
 }
 
+func TestFormat_Translated(t *testing.T) {
+	i18n.SetDefault(i18n.New(i18n.Portuguese))
+	t.Cleanup(func() { i18n.SetDefault(nil) })
+
+	got := errfmt.Format(&api.RateLimitError{Retries: 3})
+	if want := "Limite de requisições excedido após 3 tentativas. Tente novamente em alguns segundos."; got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatIn(t *testing.T) {
+	t.Parallel()
+
+	got := errfmt.FormatIn(i18n.New(i18n.Portuguese), &api.RateLimitError{Retries: 3})
+	if want := "Limite de requisições excedido após 3 tentativas. Tente novamente em alguns segundos."; got != want {
+		t.Errorf("FormatIn() = %q, want %q", got, want)
+	}
+}
+
 func TestUserFacingError(t *testing.T) {
 	t.Parallel()
 
@@ -129,3 +150,41 @@ func TestUserFacingError(t *testing.T) {
 		t.Error("Unwrap should expose the cause")
 	}
 }
+
+func TestSuggestionFor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		err     error
+		command string
+	}{
+		{"nil", nil, ""},
+		{"generic", errTestGeneric, ""},
+		{"auth", &api.AuthError{}, "nube login"},
+		{"permission", fmt.Errorf("list: %w", &api.PermissionDeniedError{}), "nube login"},
+		{"payment", &api.PaymentRequiredError{}, "nube store app-status"},
+		{"no store", fmt.Errorf("resolve: %w", credstore.ErrNoStore), "nube login"},
+		{"unknown store", fmt.Errorf("%w: x", credstore.ErrStoreNotFound), "nube auth list"},
+		{"explicit", errfmt.WithSuggestion(&api.AuthError{}, errfmt.Suggestion{Command: "nube login shop"}), "nube login shop"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := errfmt.SuggestionFor(tt.err)
+			if tt.command == "" {
+				if got != nil {
+					t.Errorf("SuggestionFor() = %+v, want none", got)
+				}
+
+				return
+			}
+
+			if got == nil || got.Command != tt.command {
+				t.Errorf("SuggestionFor() = %+v, want command %q", got, tt.command)
+			}
+		})
+	}
+}
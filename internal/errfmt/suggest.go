@@ -0,0 +1,95 @@
+package errfmt
+
+import (
+	"errors"
+
+	"github.com/gberlati/nube-cli/internal/api"
+	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/i18n"
+)
+
+// Suggestion is the command most likely to fix an error, and why.
+type Suggestion struct {
+	Command string `json:"command"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// String is the suggestion as printed under an error message.
+func (s *Suggestion) String() string {
+	return s.StringIn(i18n.Default())
+}
+
+// StringIn is String in the language of tr.
+func (s *Suggestion) StringIn(tr *i18n.Translator) string {
+	if s.Reason == "" {
+		return tr.Sprintf("Next: %s", s.Command)
+	}
+
+	return tr.Sprintf("Next: %s (%s)", s.Command, s.Reason)
+}
+
+// suggestedError attaches a suggestion that knows more than the error
+// class, such as the store profile or the scopes a command needs.
+type suggestedError struct {
+	err        error
+	suggestion Suggestion
+}
+
+func (e *suggestedError) Error() string { return e.err.Error() }
+
+func (e *suggestedError) Unwrap() error { return e.err }
+
+// WithSuggestion returns err carrying s, which SuggestionFor prefers over
+// the one for err's class.
+func WithSuggestion(err error, s Suggestion) error {
+	if err == nil {
+		return nil
+	}
+
+	return &suggestedError{err: err, suggestion: s}
+}
+
+// SuggestionFor returns the next command to run after err, or nil when
+// there is no better advice than the message.
+func SuggestionFor(err error) *Suggestion {
+	return SuggestionIn(i18n.Default(), err)
+}
+
+// SuggestionIn is SuggestionFor with the reason in the language of tr.
+func SuggestionIn(tr *i18n.Translator, err error) *Suggestion {
+	if err == nil {
+		return nil
+	}
+
+	var sugErr *suggestedError
+	if errors.As(err, &sugErr) {
+		s := sugErr.suggestion
+		return &s
+	}
+
+	var (
+		credErr    *credstore.OAuthClientMissingError
+		authErr    *api.AuthError
+		permErr    *api.PermissionDeniedError
+		paymentErr *api.PaymentRequiredError
+	)
+
+	switch {
+	case errors.As(err, &credErr):
+		return &Suggestion{Command: "nube auth credentials <credentials.json>", Reason: tr.T("save your app's client ID and secret")}
+	case errors.As(err, &authErr):
+		return &Suggestion{Command: "nube login", Reason: tr.T("the access token was rejected; authorize the store again")}
+	case errors.As(err, &permErr):
+		return &Suggestion{Command: "nube login", Reason: tr.T("authorize the store again to grant the app the scopes this command needs")}
+	case errors.As(err, &paymentErr):
+		return &Suggestion{Command: "nube store app-status", Reason: tr.T("check whether the store or the app is suspended")}
+	case errors.Is(err, credstore.ErrNoStore):
+		return &Suggestion{Command: "nube login", Reason: tr.T("no store profile is saved yet")}
+	case errors.Is(err, credstore.ErrStoreNotFound):
+		return &Suggestion{Command: "nube auth list", Reason: tr.T("see the saved store profiles")}
+	case errors.Is(err, credstore.ErrAmbiguousStore):
+		return &Suggestion{Command: "nube auth default <name>", Reason: tr.T("pick the store used when --store is omitted")}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,167 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+var testOrder = map[string]any{
+	"id":             float64(7),
+	"number":         float64(1001),
+	"created_at":     "2024-06-01T10:00:00-0300",
+	"status":         "open",
+	"total":          "150.50",
+	"customer":       map[string]any{"id": float64(3)},
+	"payment_status": nil,
+}
+
+func TestRow(t *testing.T) {
+	t.Parallel()
+
+	row := Row(Schema("orders"), testOrder, nil)
+	byName := map[string]any{}
+
+	for i, f := range Schema("orders") {
+		byName[f.Name] = row[i]
+	}
+
+	want := map[string]any{
+		"id":             int64(7),
+		"number":         int64(1001),
+		"created_at":     time.Date(2024, 6, 1, 13, 0, 0, 0, time.UTC),
+		"updated_at":     nil,
+		"status":         "open",
+		"payment_status": nil,
+		"total":          150.5,
+		"customer_id":    int64(3),
+	}
+
+	for name, w := range want {
+		if got := byName[name]; got != w {
+			t.Errorf("%s = %#v, want %#v", name, got, w)
+		}
+	}
+
+	if data, _ := byName[DataField].(string); !strings.Contains(data, `"number":1001`) {
+		t.Errorf("data = %q", data)
+	}
+}
+
+func TestRow_Translation(t *testing.T) {
+	t.Parallel()
+
+	item := map[string]any{"id": "1", "name": map[string]any{"pt": "Camisa", "es": "Remera"}}
+	pick := func(obj map[string]any, key string) string { return obj[key].(map[string]any)["pt"].(string) }
+
+	if got := Row(Schema("products"), item, nil)[3]; got != "Remera" {
+		t.Errorf("default translation = %v, want Remera", got)
+	}
+
+	if got := Row(Schema("products"), item, pick)[3]; got != "Camisa" {
+		t.Errorf("translator = %v, want Camisa", got)
+	}
+}
+
+func TestNewWriter_CSV(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	w, err := NewWriter(&buf, "csv", "orders", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Write(testOrder); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 2 || records[0][0] != "id" || records[1][0] != "7" || records[1][1] != "2024-06-01T13:00:00Z" {
+		t.Errorf("records = %q", records)
+	}
+}
+
+func TestNewWriter_Parquet(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	w, err := NewWriter(&buf, "parquet", "orders", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 3 {
+		if err := w.Write(testOrder); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := buffer.NewBufferFile(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pr, err := reader.NewParquetReader(file, nil, 1)
+	if err != nil {
+		t.Fatalf("read back: %v", err)
+	}
+
+	// Columns keep the schema's names; the reader's structs capitalize them.
+	if name := pr.SchemaHandler.Infos[1].ExName; name != "id" {
+		t.Errorf("first column = %q, want id", name)
+	}
+
+	if pr.GetNumRows() != 3 {
+		t.Errorf("rows = %d, want 3", pr.GetNumRows())
+	}
+
+	rows, err := pr.ReadByNumber(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := json.Marshal(rows[0])
+
+	var got map[string]any
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got["Total"] != 150.5 || got["Status"] != "open" || got["Payment_status"] != nil {
+		t.Errorf("row = %s", b)
+	}
+
+	pr.ReadStop()
+}
+
+func TestNewWriter_Unknown(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct{ format, resource string }{{"xml", "orders"}, {"csv", "coupons"}} {
+		if _, err := NewWriter(&bytes.Buffer{}, tt.format, tt.resource, nil); !errors.Is(err, ErrUnknown) {
+			t.Errorf("%s/%s: error = %v, want ErrUnknown", tt.format, tt.resource, err)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// Package export writes store resources as rows with a stable schema per
+// resource, for loading into databases and data tools without flattening
+// the API's JSON first.
+package export
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the type of a schema field.
+type Kind int
+
+const (
+	String Kind = iota
+	Int
+	Float
+	Bool
+	Time
+)
+
+// Field is one column of a resource's schema, read from the item field at
+// a dotted path.
+type Field struct {
+	Name string
+	Kind Kind
+	Path string
+}
+
+// DataField holds the whole API object as JSON; it is the last field of
+// every schema, so nothing the typed columns leave out is lost.
+const DataField = "data"
+
+// timeLayout is how the API formats timestamps.
+const timeLayout = "2006-01-02T15:04:05-0700"
+
+// schemas are the exported columns per resource. Columns are only ever
+// added at the end, before data, so saved queries and table definitions
+// keep working.
+var schemas = map[string][]Field{
+	"products": {
+		{Name: "id", Kind: Int, Path: "id"},
+		{Name: "created_at", Kind: Time, Path: "created_at"},
+		{Name: "updated_at", Kind: Time, Path: "updated_at"},
+		{Name: "name", Kind: String, Path: "name"},
+		{Name: "handle", Kind: String, Path: "handle"},
+		{Name: "published", Kind: Bool, Path: "published"},
+		{Name: "free_shipping", Kind: Bool, Path: "free_shipping"},
+		{Name: "brand", Kind: String, Path: "brand"},
+	},
+	"orders": {
+		{Name: "id", Kind: Int, Path: "id"},
+		{Name: "created_at", Kind: Time, Path: "created_at"},
+		{Name: "updated_at", Kind: Time, Path: "updated_at"},
+		{Name: "number", Kind: Int, Path: "number"},
+		{Name: "status", Kind: String, Path: "status"},
+		{Name: "payment_status", Kind: String, Path: "payment_status"},
+		{Name: "shipping_status", Kind: String, Path: "shipping_status"},
+		{Name: "subtotal", Kind: Float, Path: "subtotal"},
+		{Name: "total", Kind: Float, Path: "total"},
+		{Name: "currency", Kind: String, Path: "currency"},
+		{Name: "customer_id", Kind: Int, Path: "customer.id"},
+		{Name: "email", Kind: String, Path: "contact_email"},
+	},
+	"customers": {
+		{Name: "id", Kind: Int, Path: "id"},
+		{Name: "created_at", Kind: Time, Path: "created_at"},
+		{Name: "updated_at", Kind: Time, Path: "updated_at"},
+		{Name: "name", Kind: String, Path: "name"},
+		{Name: "email", Kind: String, Path: "email"},
+		{Name: "phone", Kind: String, Path: "phone"},
+		{Name: "total_spent", Kind: Float, Path: "total_spent"},
+	},
+	"categories": {
+		{Name: "id", Kind: Int, Path: "id"},
+		{Name: "created_at", Kind: Time, Path: "created_at"},
+		{Name: "updated_at", Kind: Time, Path: "updated_at"},
+		{Name: "name", Kind: String, Path: "name"},
+		{Name: "handle", Kind: String, Path: "handle"},
+		{Name: "parent", Kind: Int, Path: "parent"},
+	},
+}
+
+// Resources returns the exportable resources, sorted.
+func Resources() []string {
+	return slices.Sorted(maps.Keys(schemas))
+}
+
+// Schema returns the fields of resource, ending with DataField, or nil for
+// an unknown resource.
+func Schema(resource string) []Field {
+	fields, ok := schemas[resource]
+	if !ok {
+		return nil
+	}
+
+	return append(slices.Clip(fields), Field{Name: DataField, Kind: String})
+}
+
+// Translator picks the text of a multilingual field such as a product name.
+type Translator func(obj map[string]any, key string) string
+
+// Row converts item to the values of fields: string, int64, float64, bool
+// or time.Time, and nil where the item has no usable value.
+func Row(fields []Field, item map[string]any, translate Translator) []any {
+	if translate == nil {
+		translate = firstTranslation
+	}
+
+	row := make([]any, len(fields))
+
+	for i, f := range fields {
+		if f.Name == DataField && f.Path == "" {
+			b, err := json.Marshal(item)
+			if err == nil {
+				row[i] = string(b)
+			}
+
+			continue
+		}
+
+		row[i] = convert(f.Kind, item, f.Path, translate)
+	}
+
+	return row
+}
+
+func convert(kind Kind, item map[string]any, path string, translate Translator) any {
+	segs := strings.Split(path, ".")
+
+	cur := item
+	for _, seg := range segs[:len(segs)-1] {
+		next, ok := cur[seg].(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		cur = next
+	}
+
+	key := segs[len(segs)-1]
+	raw := cur[key]
+
+	if m, ok := raw.(map[string]any); ok && kind == String {
+		if s := translate(cur, key); s != "" {
+			return s
+		}
+
+		b, _ := json.Marshal(m)
+
+		return string(b)
+	}
+
+	text := scalarText(raw)
+	if text == "" {
+		return nil
+	}
+
+	switch kind {
+	case Int:
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n
+		}
+	case Float:
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f
+		}
+	case Bool:
+		if b, err := strconv.ParseBool(text); err == nil {
+			return b
+		}
+	case Time:
+		for _, layout := range []string{timeLayout, time.RFC3339} {
+			if t, err := time.Parse(layout, text); err == nil {
+				return t.UTC()
+			}
+		}
+	case String:
+		return text
+	}
+
+	return nil
+}
+
+// scalarText renders a JSON scalar as text; objects and lists become JSON.
+func scalarText(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case json.Number:
+		return x.String()
+	case bool:
+		return strconv.FormatBool(x)
+	default:
+		b, _ := json.Marshal(x)
+
+		return string(b)
+	}
+}
+
+func firstTranslation(obj map[string]any, key string) string {
+	m, _ := obj[key].(map[string]any)
+
+	for _, lang := range slices.Sorted(maps.Keys(m)) {
+		if s, ok := m[lang].(string); ok && s != "" {
+			return s
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,204 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// ErrSink is returned by OpenSink for a URL it can't write to.
+var ErrSink = errors.New("unsupported sink")
+
+// tableName is a table, optionally schema-qualified.
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// postgresTypes are the column types of each kind.
+var postgresTypes = map[Kind]string{
+	String: "text",
+	Int:    "bigint",
+	Float:  "double precision",
+	Bool:   "boolean",
+	Time:   "timestamptz",
+}
+
+// Sink upserts the rows of one resource into a database table keyed by id.
+type Sink struct {
+	db        *sql.DB
+	table     string
+	fields    []Field
+	translate Translator
+}
+
+// OpenSink connects to the database at rawURL and creates table (default
+// nube_<resource>) or adds the columns it is missing. Only PostgreSQL
+// (postgres:// and postgresql://) is supported.
+func OpenSink(ctx context.Context, rawURL, resource, table string, translate Translator) (*Sink, error) {
+	fields := Schema(resource)
+	if fields == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknown, resource)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+		return nil, fmt.Errorf("%w: want a postgres:// URL", ErrSink)
+	}
+
+	if table == "" {
+		table = "nube_" + resource
+	}
+
+	if !tableName.MatchString(table) {
+		return nil, fmt.Errorf("%w: invalid table name %q", ErrSink, table)
+	}
+
+	db, err := sql.Open("postgres", rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", u.Redacted(), err)
+	}
+
+	s := &Sink{db: db, table: table, fields: fields, translate: translate}
+
+	for _, stmt := range createSQL(table, fields) {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			_ = db.Close()
+
+			return nil, fmt.Errorf("create table %s: %w", table, err)
+		}
+	}
+
+	return s, nil
+}
+
+// Table returns the table rows are written to.
+func (s *Sink) Table() string { return s.table }
+
+// Upsert inserts items, replacing the rows with the same id, in one
+// statement, and returns how many rows it wrote. Items without an id are
+// skipped.
+func (s *Sink) Upsert(ctx context.Context, items []map[string]any) (int, error) {
+	rows := make([][]any, 0, len(items))
+	at := make(map[int64]int, len(items))
+
+	for _, item := range items {
+		row := Row(s.fields, item, s.translate)
+
+		id, ok := row[0].(int64)
+		if !ok {
+			continue
+		}
+
+		// One statement can't update a row twice; the last copy wins.
+		if i, dup := at[id]; dup {
+			rows[i] = row
+
+			continue
+		}
+
+		at[id] = len(rows)
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	args := make([]any, 0, len(rows)*len(s.fields))
+	for _, row := range rows {
+		args = append(args, row...)
+	}
+
+	if _, err := s.db.ExecContext(ctx, upsertSQL(s.table, s.fields, len(rows)), args...); err != nil {
+		return 0, fmt.Errorf("upsert into %s: %w", s.table, err)
+	}
+
+	return len(rows), nil
+}
+
+// Close closes the database connection.
+func (s *Sink) Close() error {
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("close sink: %w", err)
+	}
+
+	return nil
+}
+
+func quoteTable(table string) string {
+	parts := strings.Split(table, ".")
+	for i, p := range parts {
+		parts[i] = pq.QuoteIdentifier(p)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// createSQL creates table, then adds each column separately so a table
+// made by an older version gains the columns added since.
+func createSQL(table string, fields []Field) []string {
+	t := quoteTable(table)
+	stmts := []string{fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s bigint PRIMARY KEY)", t, pq.QuoteIdentifier(fields[0].Name))}
+
+	for _, f := range fields[1:] {
+		typ := postgresTypes[f.Kind]
+		if f.Name == DataField {
+			typ = "jsonb"
+		}
+
+		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s", t, pq.QuoteIdentifier(f.Name), typ))
+	}
+
+	return stmts
+}
+
+// upsertSQL is a multi-row INSERT of n rows that updates every column but
+// id on conflict.
+func upsertSQL(table string, fields []Field, n int) string {
+	cols := make([]string, len(fields))
+	for i, f := range fields {
+		cols[i] = pq.QuoteIdentifier(f.Name)
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "INSERT INTO %s (%s) VALUES ", quoteTable(table), strings.Join(cols, ", "))
+
+	p := 1
+
+	for r := range n {
+		if r > 0 {
+			b.WriteString(", ")
+		}
+
+		b.WriteByte('(')
+
+		for i := range fields {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+
+			b.WriteString("$" + strconv.Itoa(p))
+			p++
+		}
+
+		b.WriteByte(')')
+	}
+
+	fmt.Fprintf(&b, " ON CONFLICT (%s) DO UPDATE SET ", cols[0])
+
+	for i, c := range cols[1:] {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		fmt.Fprintf(&b, "%s = EXCLUDED.%s", c, c)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCreateSQL(t *testing.T) {
+	t.Parallel()
+
+	stmts := createSQL("analytics.nube_orders", Schema("orders"))
+
+	if stmts[0] != `CREATE TABLE IF NOT EXISTS "analytics"."nube_orders" ("id" bigint PRIMARY KEY)` {
+		t.Errorf("create = %s", stmts[0])
+	}
+
+	want := map[string]string{
+		"created_at": "timestamptz",
+		"number":     "bigint",
+		"total":      "double precision",
+		"status":     "text",
+		"data":       "jsonb",
+	}
+
+	for _, s := range stmts[1:] {
+		for col, typ := range want {
+			if strings.HasSuffix(s, `"`+col+`" `+typ) {
+				delete(want, col)
+			}
+		}
+	}
+
+	if len(want) != 0 {
+		t.Errorf("columns missing or mistyped: %v\n%s", want, strings.Join(stmts, "\n"))
+	}
+}
+
+func TestUpsertSQL(t *testing.T) {
+	t.Parallel()
+
+	fields := []Field{{Name: "id", Kind: Int}, {Name: "name", Kind: String}, {Name: DataField, Kind: String}}
+
+	got := upsertSQL("nube_products", fields, 2)
+	want := `INSERT INTO "nube_products" ("id", "name", "data") VALUES ($1, $2, $3), ($4, $5, $6)` +
+		` ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name", "data" = EXCLUDED."data"`
+
+	if got != want {
+		t.Errorf("upsertSQL() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestOpenSink_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, url, table string
+	}{
+		{name: "bigquery", url: "bigquery://project/dataset"},
+		{name: "mysql", url: "mysql://localhost/shop"},
+		{name: "not a url", url: "host=localhost dbname=shop"},
+		{name: "table", url: "postgres://localhost/shop", table: "orders; DROP TABLE x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := OpenSink(context.Background(), tt.url, "orders", tt.table, nil); !errors.Is(err, ErrSink) {
+				t.Errorf("OpenSink() error = %v, want ErrSink", err)
+			}
+		})
+	}
+}
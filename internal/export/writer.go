@@ -0,0 +1,176 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// Formats are the output formats NewWriter accepts. jsonl keeps the API
+// objects as they are; csv and parquet write the resource's schema.
+var Formats = []string{"jsonl", "csv", "parquet"}
+
+// ErrUnknown is returned by NewWriter for an unknown resource or format.
+var ErrUnknown = errors.New("unknown export resource or format")
+
+// Writer writes items of one resource. Close flushes what is buffered and
+// must be called for the output to be complete.
+type Writer interface {
+	Write(item map[string]any) error
+	Close() error
+}
+
+// NewWriter returns a Writer of resource items to w in format.
+func NewWriter(w io.Writer, format, resource string, translate Translator) (Writer, error) {
+	fields := Schema(resource)
+	if fields == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknown, resource)
+	}
+
+	switch format {
+	case "jsonl":
+		return &jsonlWriter{enc: json.NewEncoder(w)}, nil
+	case "csv":
+		return newCSVWriter(w, fields, translate)
+	case "parquet":
+		return newParquetWriter(w, fields, translate)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknown, format)
+	}
+}
+
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func (j *jsonlWriter) Write(item map[string]any) error {
+	if err := j.enc.Encode(item); err != nil {
+		return fmt.Errorf("write jsonl: %w", err)
+	}
+
+	return nil
+}
+
+func (j *jsonlWriter) Close() error { return nil }
+
+type csvWriter struct {
+	w         *csv.Writer
+	fields    []Field
+	translate Translator
+	record    []string
+}
+
+func newCSVWriter(w io.Writer, fields []Field, translate Translator) (*csvWriter, error) {
+	c := &csvWriter{w: csv.NewWriter(w), fields: fields, translate: translate, record: make([]string, len(fields))}
+
+	for i, f := range fields {
+		c.record[i] = f.Name
+	}
+
+	if err := c.w.Write(c.record); err != nil {
+		return nil, fmt.Errorf("write csv: %w", err)
+	}
+
+	return c, nil
+}
+
+func (c *csvWriter) Write(item map[string]any) error {
+	for i, v := range Row(c.fields, item, c.translate) {
+		c.record[i] = csvText(v)
+	}
+
+	if err := c.w.Write(c.record); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+
+	if err := c.w.Error(); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+
+	return nil
+}
+
+func csvText(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case time.Time:
+		return x.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// parquetTypes are the physical and logical types of each kind; timestamps
+// are UTC milliseconds.
+var parquetTypes = map[Kind]string{
+	String: "type=BYTE_ARRAY, convertedtype=UTF8",
+	Int:    "type=INT64",
+	Float:  "type=DOUBLE",
+	Bool:   "type=BOOLEAN",
+	Time:   "type=INT64, convertedtype=TIMESTAMP_MILLIS",
+}
+
+type parquetWriter struct {
+	pw        *writer.CSVWriter
+	fields    []Field
+	translate Translator
+}
+
+func newParquetWriter(w io.Writer, fields []Field, translate Translator) (*parquetWriter, error) {
+	md := make([]string, len(fields))
+	for i, f := range fields {
+		md[i] = fmt.Sprintf("name=%s, %s, repetitiontype=OPTIONAL", f.Name, parquetTypes[f.Kind])
+	}
+
+	pw, err := writer.NewCSVWriterFromWriter(md, w, 1)
+	if err != nil {
+		return nil, fmt.Errorf("start parquet: %w", err)
+	}
+
+	return &parquetWriter{pw: pw, fields: fields, translate: translate}, nil
+}
+
+func (p *parquetWriter) Write(item map[string]any) error {
+	row := Row(p.fields, item, p.translate)
+
+	for i, v := range row {
+		if t, ok := v.(time.Time); ok {
+			row[i] = t.UnixMilli()
+		}
+	}
+
+	if err := p.pw.Write(row); err != nil {
+		return fmt.Errorf("write parquet: %w", err)
+	}
+
+	return nil
+}
+
+func (p *parquetWriter) Close() error {
+	if err := p.pw.WriteStop(); err != nil {
+		return fmt.Errorf("finish parquet: %w", err)
+	}
+
+	return nil
+}
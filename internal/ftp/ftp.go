@@ -0,0 +1,500 @@
+// Package ftp is a small FTP client for storefront theme files: login
+// (optionally with explicit TLS), passive transfers, recursive listings,
+// downloads and uploads.
+package ftp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry is a remote file. Path is relative to the listed root, with forward
+// slashes.
+type Entry struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Options configures Dial.
+type Options struct {
+	// TLS upgrades the control and data connections with AUTH TLS.
+	TLS bool
+	// TLSConfig overrides the TLS settings; ServerName defaults to the host.
+	TLSConfig *tls.Config
+	// Timeout bounds dialing and every command's reply.
+	Timeout time.Duration
+}
+
+// Conn is a logged-in FTP session. It is not safe for concurrent use.
+type Conn struct {
+	conn    net.Conn
+	text    *textproto.Conn
+	host    string
+	tls     *tls.Config
+	timeout time.Duration
+	mlsd    bool
+}
+
+// Error is a negative reply from the server.
+type Error struct {
+	Code int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("ftp %d: %s", e.Code, e.Msg)
+}
+
+// Dial connects to addr (host:port) and reads the greeting.
+func Dial(ctx context.Context, addr string, opts Options) (*Conn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("ftp address %q: %w", addr, err)
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+
+	nc, err := (&net.Dialer{Timeout: opts.Timeout}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Conn{conn: nc, text: textproto.NewConn(nc), host: host, timeout: opts.Timeout}
+
+	if _, err := c.reply(220); err != nil {
+		_ = nc.Close()
+
+		return nil, err
+	}
+
+	if opts.TLS {
+		cfg := &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12, ClientSessionCache: tls.NewLRUClientSessionCache(4)}
+		if opts.TLSConfig != nil {
+			cfg = opts.TLSConfig.Clone()
+			if cfg.ServerName == "" {
+				cfg.ServerName = host
+			}
+
+			if cfg.ClientSessionCache == nil {
+				// Servers commonly require data connections to resume
+				// the control connection's TLS session.
+				cfg.ClientSessionCache = tls.NewLRUClientSessionCache(4)
+			}
+		}
+
+		if err := c.startTLS(cfg); err != nil {
+			_ = nc.Close()
+
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (c *Conn) startTLS(cfg *tls.Config) error {
+	if _, err := c.cmd(234, "AUTH TLS"); err != nil {
+		return fmt.Errorf("server doesn't offer TLS: %w", err)
+	}
+
+	tc := tls.Client(c.conn, cfg)
+	if err := tc.Handshake(); err != nil {
+		return fmt.Errorf("tls handshake: %w", err)
+	}
+
+	c.conn = tc
+	c.text = textproto.NewConn(tc)
+	c.tls = cfg
+
+	if _, err := c.cmd(200, "PBSZ 0"); err != nil {
+		return err
+	}
+
+	_, err := c.cmd(200, "PROT P")
+
+	return err
+}
+
+// Login authenticates and switches to binary transfers.
+func (c *Conn) Login(user, password string) error {
+	code, msg, err := c.send("USER " + user)
+	if err != nil {
+		return err
+	}
+
+	if code == 331 {
+		code, msg, err = c.send("PASS " + password)
+		if err != nil {
+			return err
+		}
+	}
+
+	if code != 230 {
+		return &Error{Code: code, Msg: msg}
+	}
+
+	if _, err := c.cmd(200, "TYPE I"); err != nil {
+		return err
+	}
+
+	if code, msg, err := c.send("FEAT"); err == nil && code == 211 {
+		c.mlsd = strings.Contains(strings.ToUpper(msg), "MLSD")
+	}
+
+	return nil
+}
+
+// Walk lists every file under root, recursively, sorted by path.
+func (c *Conn) Walk(root string) ([]Entry, error) {
+	var files []Entry
+
+	dirs := []string{""}
+
+	for len(dirs) > 0 {
+		rel := dirs[0]
+		dirs = dirs[1:]
+
+		entries, subdirs, err := c.list(path.Join(root, rel))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range entries {
+			e.Path = path.Join(rel, e.Path)
+			files = append(files, e)
+		}
+
+		for _, d := range subdirs {
+			dirs = append(dirs, path.Join(rel, d))
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	return files, nil
+}
+
+// list returns the files and subdirectory names in dir.
+func (c *Conn) list(dir string) ([]Entry, []string, error) {
+	if !c.mlsd {
+		return c.listUnix(dir)
+	}
+
+	data, err := c.readData("MLSD " + dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		files []Entry
+		dirs  []string
+	)
+
+	for _, line := range strings.Split(string(data), "\n") {
+		facts, name, ok := strings.Cut(strings.TrimRight(line, "\r"), " ")
+		if !ok || !validName(name) {
+			continue
+		}
+
+		e := Entry{Path: name}
+		kind := ""
+
+		for _, f := range strings.Split(facts, ";") {
+			k, v, _ := strings.Cut(f, "=")
+
+			switch strings.ToLower(k) {
+			case "type":
+				kind = strings.ToLower(v)
+			case "size":
+				e.Size, _ = strconv.ParseInt(v, 10, 64)
+			case "modify":
+				e.ModTime, _ = parseTime(v)
+			}
+		}
+
+		switch kind {
+		case "file":
+			files = append(files, e)
+		case "dir":
+			dirs = append(dirs, name)
+		}
+	}
+
+	return files, dirs, nil
+}
+
+// validName reports whether a listed name is a single path element. Others
+// ("..", "a/b") would let the server point Walk, and the caller's local
+// copies, outside the listed root.
+func validName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, "/\\")
+}
+
+// listUnix is the fallback for servers without MLSD: it parses "ls -l"
+// style LIST output and asks MDTM for each file's time.
+func (c *Conn) listUnix(dir string) ([]Entry, []string, error) {
+	data, err := c.readData("LIST " + dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		files []Entry
+		dirs  []string
+	)
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(strings.TrimRight(line, "\r"))
+		if len(fields) < 9 {
+			continue
+		}
+
+		name := strings.Join(fields[8:], " ")
+		if !validName(name) {
+			continue
+		}
+
+		switch fields[0][0] {
+		case 'd':
+			dirs = append(dirs, name)
+		case '-':
+			size, _ := strconv.ParseInt(fields[4], 10, 64)
+			e := Entry{Path: name, Size: size}
+
+			if _, msg, err := c.send("MDTM " + path.Join(dir, name)); err == nil {
+				e.ModTime, _ = parseTime(msg)
+			}
+
+			files = append(files, e)
+		}
+	}
+
+	return files, dirs, nil
+}
+
+// Retrieve downloads the file at p into w.
+func (c *Conn) Retrieve(p string, w io.Writer) error {
+	return c.transfer("RETR "+p, func(dc net.Conn) error {
+		_, err := io.Copy(w, dc)
+
+		return err
+	})
+}
+
+// Store uploads r to p, creating missing parent directories.
+func (c *Conn) Store(p string, r io.Reader) error {
+	if err := c.mkdirAll(path.Dir(p)); err != nil {
+		return err
+	}
+
+	return c.transfer("STOR "+p, func(dc net.Conn) error {
+		_, err := io.Copy(dc, r)
+
+		return err
+	})
+}
+
+func (c *Conn) mkdirAll(dir string) error {
+	if dir == "." || dir == "/" || dir == "" {
+		return nil
+	}
+
+	if err := c.mkdirAll(path.Dir(dir)); err != nil {
+		return err
+	}
+
+	code, msg, err := c.send("MKD " + dir)
+	if err != nil {
+		return err
+	}
+
+	// 550 is also what servers answer for an existing directory.
+	if code != 257 && code != 550 {
+		return &Error{Code: code, Msg: msg}
+	}
+
+	return nil
+}
+
+// Quit ends the session and closes the connection.
+func (c *Conn) Quit() error {
+	_, _, _ = c.send("QUIT")
+
+	return c.conn.Close()
+}
+
+func (c *Conn) readData(command string) ([]byte, error) {
+	var data []byte
+
+	err := c.transfer(command, func(dc net.Conn) error {
+		var err error
+
+		data, err = io.ReadAll(dc)
+
+		return err
+	})
+
+	return data, err
+}
+
+// transfer opens a passive data connection, sends command and runs fn on
+// the data connection, then waits for the transfer's completion reply.
+func (c *Conn) transfer(command string, fn func(net.Conn) error) error {
+	addr, err := c.passive()
+	if err != nil {
+		return err
+	}
+
+	dc, err := net.DialTimeout("tcp", addr, c.timeout)
+	if err != nil {
+		return fmt.Errorf("ftp data connection: %w", err)
+	}
+
+	defer func() { _ = dc.Close() }()
+
+	code, msg, err := c.send(command)
+	if err != nil {
+		return err
+	}
+
+	if code != 125 && code != 150 {
+		return &Error{Code: code, Msg: msg}
+	}
+
+	if c.tls != nil {
+		tc := tls.Client(dc, c.tls)
+		if err := tc.Handshake(); err != nil {
+			return fmt.Errorf("ftp data connection: %w", err)
+		}
+
+		dc = tc
+	}
+
+	_ = dc.SetDeadline(time.Now().Add(10 * c.timeout))
+
+	fnErr := fn(dc)
+
+	if err := dc.Close(); err != nil && fnErr == nil {
+		fnErr = err
+	}
+
+	if _, err := c.reply(226, 250); err != nil {
+		return err
+	}
+
+	return fnErr
+}
+
+// passive asks for a data port with EPSV, falling back on PASV. Both
+// connect to the control connection's host, since PASV addresses are often
+// private ones behind NAT.
+func (c *Conn) passive() (string, error) {
+	code, msg, err := c.send("EPSV")
+	if err != nil {
+		return "", err
+	}
+
+	if code == 229 {
+		start, end := strings.Index(msg, "(|||"), strings.LastIndex(msg, "|)")
+		if start < 0 || end < start+4 {
+			return "", fmt.Errorf("ftp: bad EPSV reply %q", msg)
+		}
+
+		return net.JoinHostPort(c.host, msg[start+4:end]), nil
+	}
+
+	code, msg, err = c.send("PASV")
+	if err != nil {
+		return "", err
+	}
+
+	if code != 227 {
+		return "", &Error{Code: code, Msg: msg}
+	}
+
+	start, end := strings.Index(msg, "("), strings.Index(msg, ")")
+	if start < 0 || end < start {
+		return "", fmt.Errorf("ftp: bad PASV reply %q", msg)
+	}
+
+	parts := strings.Split(msg[start+1:end], ",")
+	if len(parts) != 6 {
+		return "", fmt.Errorf("ftp: bad PASV reply %q", msg)
+	}
+
+	hi, err1 := strconv.Atoi(parts[4])
+	lo, err2 := strconv.Atoi(parts[5])
+
+	if err := errors.Join(err1, err2); err != nil {
+		return "", fmt.Errorf("ftp: bad PASV reply %q", msg)
+	}
+
+	return net.JoinHostPort(c.host, strconv.Itoa(hi<<8|lo)), nil
+}
+
+// cmd sends a command and expects the reply code want.
+func (c *Conn) cmd(want int, command string) (string, error) {
+	code, msg, err := c.send(command)
+	if err != nil {
+		return "", err
+	}
+
+	if code != want {
+		return "", &Error{Code: code, Msg: msg}
+	}
+
+	return msg, nil
+}
+
+// send writes a command and returns the reply, whatever its code.
+func (c *Conn) send(command string) (int, string, error) {
+	_ = c.conn.SetDeadline(time.Now().Add(c.timeout))
+
+	if err := c.text.PrintfLine("%s", command); err != nil {
+		return 0, "", err
+	}
+
+	return c.text.ReadResponse(0)
+}
+
+// reply reads a reply and checks its code is one of want.
+func (c *Conn) reply(want ...int) (string, error) {
+	_ = c.conn.SetDeadline(time.Now().Add(c.timeout))
+
+	code, msg, err := c.text.ReadResponse(0)
+	if err != nil {
+		return "", err
+	}
+
+	for _, w := range want {
+		if code == w {
+			return msg, nil
+		}
+	}
+
+	return "", &Error{Code: code, Msg: msg}
+}
+
+// parseTime reads an MLSD modify fact or MDTM reply (YYYYMMDDHHMMSS[.sss],
+// UTC).
+func parseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		s = s[:i]
+	}
+
+	return time.ParseInLocation("20060102150405", s, time.UTC)
+}
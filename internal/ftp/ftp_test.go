@@ -0,0 +1,321 @@
+package ftp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer is an in-memory FTP server speaking just enough of the
+// protocol for the client: one session, passive (EPSV) transfers.
+type fakeServer struct {
+	mu    sync.Mutex
+	files map[string][]byte
+	mod   time.Time
+	mlsd  bool
+	ln    net.Listener
+	// extra is appended to every listing, verbatim.
+	extra string
+}
+
+func newFakeServer(t *testing.T, mlsd bool, files map[string]string) *fakeServer {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &fakeServer{files: map[string][]byte{}, mod: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC), mlsd: mlsd, ln: ln}
+	for p, body := range files {
+		s.files[p] = []byte(body)
+	}
+
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go s.session(c)
+		}
+	}()
+
+	return s
+}
+
+func (s *fakeServer) session(c net.Conn) {
+	defer func() { _ = c.Close() }()
+
+	r := bufio.NewReader(c)
+	reply := func(format string, args ...any) { _, _ = fmt.Fprintf(c, format+"\r\n", args...) }
+
+	var data net.Listener
+
+	reply("220 fake")
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+
+		switch strings.ToUpper(verb) {
+		case "USER":
+			reply("331 password please")
+		case "PASS":
+			if arg != "secret" {
+				reply("530 login incorrect")
+				continue
+			}
+
+			reply("230 logged in")
+		case "TYPE":
+			reply("200 ok")
+		case "FEAT":
+			if s.mlsd {
+				reply("211-Features:\r\n MLSD\r\n SIZE\r\n211 End")
+			} else {
+				reply("211-Features:\r\n SIZE\r\n211 End")
+			}
+		case "EPSV":
+			data, _ = net.Listen("tcp", "127.0.0.1:0")
+			reply("229 Entering Extended Passive Mode (|||%d|)", data.Addr().(*net.TCPAddr).Port)
+		case "MLSD", "LIST":
+			s.send(data, reply, s.listing(arg, verb == "MLSD"))
+		case "MDTM":
+			reply("213 %s", s.mod.Format("20060102150405"))
+		case "RETR":
+			s.mu.Lock()
+			body, ok := s.files[arg]
+			s.mu.Unlock()
+
+			if !ok {
+				_ = data.Close()
+
+				reply("550 no such file")
+
+				continue
+			}
+
+			s.send(data, reply, body)
+		case "STOR":
+			reply("150 ok")
+
+			dc, _ := data.Accept()
+			body, _ := io.ReadAll(dc)
+			_ = dc.Close()
+			_ = data.Close()
+
+			s.mu.Lock()
+			s.files[arg] = body
+			s.mu.Unlock()
+
+			reply("226 done")
+		case "MKD":
+			reply("257 created")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func (s *fakeServer) send(data net.Listener, reply func(string, ...any), body []byte) {
+	reply("150 ok")
+
+	dc, err := data.Accept()
+	if err == nil {
+		_, _ = dc.Write(body)
+		_ = dc.Close()
+	}
+
+	_ = data.Close()
+
+	reply("226 done")
+}
+
+// listing renders dir in MLSD or "ls -l" form.
+func (s *fakeServer) listing(dir string, mlsd bool) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir = strings.TrimSuffix(dir, "/")
+	seen := map[string]bool{}
+
+	var names []string
+
+	for p := range s.files {
+		rest, ok := strings.CutPrefix(p, dir+"/")
+		if !ok {
+			continue
+		}
+
+		name, _, isDir := strings.Cut(rest, "/")
+		if !seen[name] {
+			seen[name] = true
+
+			names = append(names, name)
+		}
+
+		if isDir {
+			seen[name+"/"] = true
+		}
+	}
+
+	sort.Strings(names)
+
+	var b bytes.Buffer
+
+	b.WriteString(s.extra)
+
+	for _, name := range names {
+		size := len(s.files[path.Join(dir, name)])
+
+		switch {
+		case mlsd && seen[name+"/"]:
+			fmt.Fprintf(&b, "type=dir;modify=%s; %s\r\n", s.mod.Format("20060102150405"), name)
+		case mlsd:
+			fmt.Fprintf(&b, "type=file;size=%d;modify=%s; %s\r\n", size, s.mod.Format("20060102150405"), name)
+		case seen[name+"/"]:
+			fmt.Fprintf(&b, "drwxr-xr-x 2 u g 4096 Jun 1 12:00 %s\r\n", name)
+		default:
+			fmt.Fprintf(&b, "-rw-r--r-- 1 u g %d Jun 1 12:00 %s\r\n", size, name)
+		}
+	}
+
+	return b.Bytes()
+}
+
+func TestConn_WalkRetrieveStore(t *testing.T) {
+	t.Parallel()
+
+	for _, mlsd := range []bool{true, false} {
+		t.Run(fmt.Sprintf("mlsd=%v", mlsd), func(t *testing.T) {
+			t.Parallel()
+
+			srv := newFakeServer(t, mlsd, map[string]string{
+				"/layouts/layout.tpl":   "<html>{{ content }}</html>",
+				"/static/css/style.css": "body{}",
+			})
+
+			c, err := Dial(context.Background(), srv.ln.Addr().String(), Options{Timeout: 5 * time.Second})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			t.Cleanup(func() { _ = c.Quit() })
+
+			if err := c.Login("shop", "secret"); err != nil {
+				t.Fatalf("login: %v", err)
+			}
+
+			files, err := c.Walk("/")
+			if err != nil {
+				t.Fatalf("walk: %v", err)
+			}
+
+			if len(files) != 2 || files[0].Path != "layouts/layout.tpl" || files[1].Path != "static/css/style.css" {
+				t.Fatalf("files = %+v", files)
+			}
+
+			if files[1].Size != 6 || !files[1].ModTime.Equal(srv.mod) {
+				t.Errorf("style.css = %+v", files[1])
+			}
+
+			var buf bytes.Buffer
+			if err := c.Retrieve("/layouts/layout.tpl", &buf); err != nil || buf.String() != "<html>{{ content }}</html>" {
+				t.Errorf("retrieve = %q, %v", buf.String(), err)
+			}
+
+			if err := c.Store("/snipplets/new.tpl", strings.NewReader("hola")); err != nil {
+				t.Fatalf("store: %v", err)
+			}
+
+			if got := string(srv.files["/snipplets/new.tpl"]); got != "hola" {
+				t.Errorf("stored %q", got)
+			}
+
+			if err := c.Retrieve("/missing", io.Discard); err == nil {
+				t.Error("retrieve of a missing file succeeded")
+			}
+		})
+	}
+}
+
+func TestConn_LoginRejected(t *testing.T) {
+	t.Parallel()
+
+	srv := newFakeServer(t, true, nil)
+
+	c, err := Dial(context.Background(), srv.ln.Addr().String(), Options{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = c.Quit() })
+
+	err = c.Login("shop", "wrong")
+
+	var ftpErr *Error
+	if !errors.As(err, &ftpErr) || ftpErr.Code != 530 {
+		t.Errorf("err = %v, want a 530 reply", err)
+	}
+}
+
+func TestConn_WalkSkipsUnsafeNames(t *testing.T) {
+	t.Parallel()
+
+	hostile := map[bool]string{
+		true: "type=file;size=4; ../../evil\r\ntype=file;size=4; a/b\r\ntype=file;size=4; ..\\evil\r\n" +
+			"type=dir; ..\r\ntype=dir; .\r\ntype=pdir; ..\r\n",
+		false: "-rw-r--r-- 1 u g 4 Jun 1 12:00 ../../evil\r\n-rw-r--r-- 1 u g 4 Jun 1 12:00 a/b\r\n" +
+			"drwxr-xr-x 2 u g 4096 Jun 1 12:00 ..\r\ndrwxr-xr-x 2 u g 4096 Jun 1 12:00 ../up\r\n",
+	}
+
+	for _, mlsd := range []bool{true, false} {
+		t.Run(fmt.Sprintf("mlsd=%v", mlsd), func(t *testing.T) {
+			t.Parallel()
+
+			srv := newFakeServer(t, mlsd, map[string]string{"/layouts/layout.tpl": "<html></html>"})
+			srv.extra = hostile[mlsd]
+
+			c, err := Dial(context.Background(), srv.ln.Addr().String(), Options{Timeout: 5 * time.Second})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			t.Cleanup(func() { _ = c.Quit() })
+
+			if err := c.Login("shop", "secret"); err != nil {
+				t.Fatalf("login: %v", err)
+			}
+
+			files, err := c.Walk("/")
+			if err != nil {
+				t.Fatalf("walk: %v", err)
+			}
+
+			if len(files) != 1 || files[0].Path != "layouts/layout.tpl" {
+				t.Errorf("files = %+v, want only layouts/layout.tpl", files)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+// Package history stores snapshots of resources taken before they are
+// updated or deleted, so a change can be reverted with the inverse API call.
+package history
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/journal"
+)
+
+// ErrNotFound is returned when no matching snapshot exists.
+var ErrNotFound = errors.New("no matching history entry")
+
+// Entry is a resource snapshot taken before a write.
+type Entry struct {
+	ID       string          `json:"id"`
+	Time     time.Time       `json:"time"`
+	Store    string          `json:"store,omitempty"`
+	Method   string          `json:"method"`
+	Path     string          `json:"path"`
+	Snapshot json.RawMessage `json:"snapshot"`
+	Undone   bool            `json:"undone,omitempty"`
+}
+
+// record is one line in the history file.
+type record struct {
+	Event string `json:"event"`
+	Entry
+}
+
+const (
+	eventSnapshot = "snapshot"
+	eventUndone   = "undone"
+)
+
+// History is an append-only JSON-lines file of snapshots.
+type History struct {
+	path string
+	mu   sync.Mutex
+	now  func() time.Time
+}
+
+// New returns a History backed by the file at path.
+func New(path string) *History {
+	return &History{path: path, now: time.Now}
+}
+
+// DefaultPath returns the history location inside the data directory.
+func DefaultPath() (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "history.jsonl"), nil
+}
+
+// Save records the pre-write state of the resource at path and returns the entry.
+func (h *History) Save(store, method, path string, snapshot []byte) (Entry, error) {
+	id, err := journal.NewID()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	e := Entry{ID: id, Time: h.now().UTC(), Store: store, Method: method, Path: path, Snapshot: snapshot}
+
+	return e, h.append(record{Event: eventSnapshot, Entry: e})
+}
+
+// MarkUndone records that the entry has been reverted.
+func (h *History) MarkUndone(id string) error {
+	return h.append(record{Event: eventUndone, Entry: Entry{ID: id, Time: h.now().UTC()}})
+}
+
+func (h *History) append(rec record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return fmt.Errorf("ensure history dir: %w", err)
+	}
+
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open history: %w", err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode history record: %w", err)
+	}
+
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("write history: %w", err)
+	}
+
+	return nil
+}
+
+// List returns all snapshots, oldest first. A missing file is empty.
+func (h *History) List() ([]Entry, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	f, err := os.Open(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("open history: %w", err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	var (
+		entries []Entry
+		index   = map[string]int{}
+	)
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+
+	for sc.Scan() {
+		var rec record
+		// A torn final line (crash mid-write) is skipped rather than fatal.
+		if json.Unmarshal(sc.Bytes(), &rec) != nil || rec.ID == "" {
+			continue
+		}
+
+		switch rec.Event {
+		case eventSnapshot:
+			index[rec.ID] = len(entries)
+			entries = append(entries, rec.Entry)
+		case eventUndone:
+			if i, ok := index[rec.ID]; ok {
+				entries[i].Undone = true
+			}
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Find returns the entry with the given ID, or with id "last" the most recent
+// entry for store that hasn't been undone.
+func (h *History) Find(id, store string) (Entry, error) {
+	entries, err := h.List()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+
+		if id == "last" {
+			if !e.Undone && (store == "" || e.Store == store) {
+				return e, nil
+			}
+
+			continue
+		}
+
+		if e.ID == id {
+			return e, nil
+		}
+	}
+
+	return Entry{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+// ParentPath returns the collection path for a resource path
+// ("products/1/variants/2" -> "products/1/variants").
+func ParentPath(path string) string {
+	path = strings.Trim(path, "/")
+
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[:i]
+	}
+
+	return ""
+}
+
+type historyCtxKey struct{}
+
+// WithHistory attaches a History to the context; PUT and DELETE requests made
+// with it snapshot the target resource first.
+func WithHistory(ctx context.Context, h *History) context.Context {
+	return context.WithValue(ctx, historyCtxKey{}, h)
+}
+
+// FromContext returns the History attached to ctx, or nil.
+func FromContext(ctx context.Context) *History {
+	h, _ := ctx.Value(historyCtxKey{}).(*History)
+
+	return h
+}
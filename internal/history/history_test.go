@@ -0,0 +1,103 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func newTestHistory(t *testing.T) *History {
+	t.Helper()
+
+	return New(filepath.Join(t.TempDir(), "sub", "history.jsonl"))
+}
+
+func TestHistory_SaveFindUndo(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHistory(t)
+
+	first, err := h.Save("123", "PUT", "products/1", []byte(`{"id":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := h.Save("123", "DELETE", "products/2", []byte(`{"id":2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := h.Save("999", "PUT", "products/3", []byte(`{"id":3}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	last, err := h.Find("last", "123")
+	if err != nil || last.ID != second.ID {
+		t.Fatalf("Find(last) = %+v, %v; want %s", last, err, second.ID)
+	}
+
+	if err := h.MarkUndone(second.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	last, err = h.Find("last", "123")
+	if err != nil || last.ID != first.ID {
+		t.Fatalf("Find(last) after undo = %+v, %v; want %s", last, err, first.ID)
+	}
+
+	got, err := h.Find(second.ID, "")
+	if err != nil || !got.Undone || string(got.Snapshot) != `{"id":2}` {
+		t.Errorf("Find(id) = %+v, %v", got, err)
+	}
+}
+
+func TestHistory_Empty(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHistory(t)
+
+	entries, err := h.List()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("List() = %v, %v; want empty", entries, err)
+	}
+
+	if _, err := h.Find("last", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Find() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestParentPath(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"products/1":             "products",
+		"/products/1/variants/2": "products/1/variants",
+		"store":                  "",
+	}
+
+	for in, want := range tests {
+		if got := ParentPath(in); got != want {
+			t.Errorf("ParentPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestContextHelpers(t *testing.T) {
+	t.Parallel()
+
+	if FromContext(context.Background()) != nil {
+		t.Fatal("empty context should carry no history")
+	}
+
+	h := newTestHistory(t)
+	ctx := WithHistory(context.Background(), h)
+
+	if FromContext(ctx) != h {
+		t.Error("FromContext did not return attached history")
+	}
+
+	if FromContext(WithHistory(ctx, nil)) != nil {
+		t.Error("WithHistory(nil) should disable snapshots")
+	}
+}
@@ -0,0 +1,126 @@
+// Package i18n translates user-facing CLI text. Messages are keyed by their
+// English source text, so untranslated strings fall back to English and call
+// sites stay readable.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// Supported languages.
+const (
+	English    = "en"
+	Spanish    = "es"
+	Portuguese = "pt"
+)
+
+// Languages lists the supported languages, English first.
+var Languages = []string{English, Spanish, Portuguese}
+
+var catalogs = map[string]map[string]string{
+	Spanish:    es,
+	Portuguese: pt,
+}
+
+// Normalize maps a language or locale name ("es", "pt-BR", "es_AR.UTF-8") to
+// a supported language. It returns "" for anything else.
+func Normalize(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s, _, _ = strings.Cut(s, ".")
+
+	lang, _, _ := strings.Cut(strings.ReplaceAll(s, "_", "-"), "-")
+
+	for _, l := range Languages {
+		if lang == l {
+			return l
+		}
+	}
+
+	return ""
+}
+
+// Translator translates into one language. A nil Translator is English.
+type Translator struct {
+	lang    string
+	catalog map[string]string
+}
+
+// New returns a translator for lang (see Normalize); unsupported languages
+// get English.
+func New(lang string) *Translator {
+	lang = Normalize(lang)
+	if lang == "" {
+		lang = English
+	}
+
+	return &Translator{lang: lang, catalog: catalogs[lang]}
+}
+
+// Lang returns the translator's language.
+func (t *Translator) Lang() string {
+	if t == nil {
+		return English
+	}
+
+	return t.lang
+}
+
+// T returns the translation of msg, or msg itself when there is none.
+func (t *Translator) T(msg string) string {
+	if t == nil {
+		return msg
+	}
+
+	if tr, ok := t.catalog[msg]; ok {
+		return tr
+	}
+
+	return msg
+}
+
+// Sprintf translates format, then formats it.
+func (t *Translator) Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(t.T(format), args...)
+}
+
+var current atomic.Pointer[Translator]
+
+// SetDefault sets the translator used by the package-level functions.
+func SetDefault(t *Translator) {
+	current.Store(t)
+}
+
+// Default returns the translator set with SetDefault (English if none).
+func Default() *Translator {
+	return current.Load()
+}
+
+// T translates msg with the default translator.
+func T(msg string) string {
+	return Default().T(msg)
+}
+
+// Sprintf translates format with the default translator, then formats it.
+func Sprintf(format string, args ...any) string {
+	return Default().Sprintf(format, args...)
+}
+
+type ctxKey struct{}
+
+// WithTranslator returns ctx carrying t, for runs that can't change the
+// process-wide default, such as those of a daemon serving several callers.
+func WithTranslator(ctx context.Context, t *Translator) context.Context {
+	return context.WithValue(ctx, ctxKey{}, t)
+}
+
+// FromContext returns the translator of ctx, or the default.
+func FromContext(ctx context.Context) *Translator {
+	if t, ok := ctx.Value(ctxKey{}).(*Translator); ok {
+		return t
+	}
+
+	return Default()
+}
@@ -0,0 +1,82 @@
+package i18n
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"es":          Spanish,
+		"ES":          Spanish,
+		"es_AR.UTF-8": Spanish,
+		"pt-BR":       Portuguese,
+		"en_US":       English,
+		"fr":          "",
+		"":            "",
+	}
+
+	for in, want := range tests {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestTranslator(t *testing.T) {
+	t.Parallel()
+
+	es := New("es_AR")
+	if es.Lang() != Spanish || es.T("Usage:") != "Uso:" {
+		t.Errorf("es: lang %q, Usage: %q", es.Lang(), es.T("Usage:"))
+	}
+
+	if got := es.Sprintf("API error (HTTP %d): %s", 500, "boom"); got != "Error de la API (HTTP 500): boom" {
+		t.Errorf("Sprintf = %q", got)
+	}
+
+	if got := es.T("not in any catalog"); got != "not in any catalog" {
+		t.Errorf("fallback = %q", got)
+	}
+
+	if New("fr").Lang() != English {
+		t.Error("unsupported language should fall back to English")
+	}
+
+	var none *Translator
+	if none.T("Usage:") != "Usage:" || none.Lang() != English {
+		t.Error("nil translator should be English")
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	if FromContext(ctx) != Default() {
+		t.Error("a context without a translator should get the default")
+	}
+
+	if got := FromContext(WithTranslator(ctx, New("pt"))).Lang(); got != Portuguese {
+		t.Errorf("lang = %q, want %q", got, Portuguese)
+	}
+}
+
+// verbs matches fmt verbs, so translations keep their arguments.
+var verbs = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func TestCatalogs_KeepFormatVerbs(t *testing.T) {
+	t.Parallel()
+
+	for lang, catalog := range catalogs {
+		for en, tr := range catalog {
+			if want, got := verbs.FindAllString(en, -1), verbs.FindAllString(tr, -1); !slices.Equal(want, got) {
+				t.Errorf("%s: %q has verbs %v, translation %q has %v", lang, en, want, tr, got)
+			}
+		}
+	}
+}
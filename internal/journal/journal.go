@@ -0,0 +1,420 @@
+// Package journal keeps a local write-ahead log of mutating API requests.
+//
+// Every write is recorded before it is sent and again once its outcome is
+// known, so after a crash or network failure the log shows whether a request
+// was acknowledged and holds enough to resend it with the same idempotency key.
+//
+// The log itself holds request bodies with personal data scrubbed (see
+// redact.ScrubPII). The original body is kept in a file of its own, for
+// resending, until the request is acknowledged or BodyMaxAge passes. The log
+// rotates at MaxSize, keeping one previous file.
+package journal
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/config"
+	"github.com/gberlati/nube-cli/internal/redact"
+)
+
+// Status is the last known outcome of a journaled request.
+type Status string
+
+const (
+	// StatusPending means the request was sent (or about to be) and no
+	// outcome was recorded; it may or may not have been applied.
+	StatusPending Status = "pending"
+	// StatusOK means the API acknowledged the request.
+	StatusOK Status = "ok"
+	// StatusFailed means the API rejected the request.
+	StatusFailed Status = "failed"
+	// StatusUnknown means the request failed without a response (network
+	// error, timeout); it may or may not have been applied.
+	StatusUnknown Status = "unknown"
+)
+
+// Retention limits.
+const (
+	// MaxSize is the log size in bytes that starts a new file; the previous
+	// one is kept as path.1 and the one before it dropped.
+	MaxSize = 4 << 20
+	// BodyMaxAge is how long the original body of a request that wasn't
+	// acknowledged is kept for resending.
+	BodyMaxAge = 30 * 24 * time.Hour
+)
+
+var (
+	// ErrNotFound is returned by Get for an unknown entry ID.
+	ErrNotFound = errors.New("journal entry not found")
+	// ErrNoBody is returned by Body when the original body is no longer kept.
+	ErrNoBody = errors.New("the original request body is no longer kept")
+)
+
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Entry is the folded view of one journaled request across all its attempts.
+// The ID doubles as the Idempotency-Key sent with every attempt.
+type Entry struct {
+	ID         string          `json:"id"`
+	Time       time.Time       `json:"time"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	Store      string          `json:"store,omitempty"`
+	Method     string          `json:"method"`
+	Path       string          `json:"path"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	Status     Status          `json:"status"`
+	HTTPStatus int             `json:"http_status,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Attempts   int             `json:"attempts"`
+}
+
+// Retryable reports whether resending the request is meaningful.
+func (e Entry) Retryable() bool {
+	return e.Status != StatusOK
+}
+
+// MaybeApplied reports whether the request may have been applied although
+// no acknowledgement was recorded.
+func (e Entry) MaybeApplied() bool {
+	return e.Status == StatusPending || e.Status == StatusUnknown
+}
+
+// record is one line in the journal file.
+type record struct {
+	Event      string          `json:"event"`
+	ID         string          `json:"id"`
+	Time       time.Time       `json:"time"`
+	Store      string          `json:"store,omitempty"`
+	Method     string          `json:"method,omitempty"`
+	Path       string          `json:"path,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	Status     Status          `json:"status,omitempty"`
+	HTTPStatus int             `json:"http_status,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+const (
+	eventBegin = "begin"
+	eventEnd   = "end"
+)
+
+// Journal is an append-only JSON-lines file of request records.
+type Journal struct {
+	path    string
+	maxSize int64
+	mu      sync.Mutex
+	now     func() time.Time
+}
+
+// New returns a Journal backed by the file at path, keeping original bodies
+// in the directory path.bodies.
+func New(path string) *Journal {
+	return &Journal{path: path, maxSize: MaxSize, now: time.Now}
+}
+
+// DefaultPath returns the journal location inside the data directory.
+func DefaultPath() (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "journal.jsonl"), nil
+}
+
+// Path returns the journal file path.
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// NewID returns a random idempotency key.
+func NewID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate idempotency key: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// Begin records an attempt before it is sent. Begin with an existing ID
+// records a retry of that entry.
+func (j *Journal) Begin(id, store, method, path string, body []byte) error {
+	rec := record{Event: eventBegin, ID: id, Store: store, Method: method, Path: path}
+	if len(body) > 0 && json.Valid(body) {
+		if err := j.keepBody(id, body); err != nil {
+			return err
+		}
+
+		rec.Body = redact.ScrubPII(body)
+	}
+
+	return j.append(rec)
+}
+
+// Finish records the outcome of the latest attempt for id. An acknowledged
+// request no longer needs its original body.
+func (j *Journal) Finish(id string, status Status, httpStatus int, errMsg string) error {
+	if status == StatusOK {
+		if path, err := j.bodyPath(id); err == nil {
+			_ = os.Remove(path)
+		}
+	}
+
+	return j.append(record{Event: eventEnd, ID: id, Status: status, HTTPStatus: httpStatus, Error: errMsg})
+}
+
+// Body returns the original body of entry id, for resending it.
+func (j *Journal) Body(id string) ([]byte, error) {
+	path, err := j.bodyPath(id)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := os.ReadFile(path) //nolint:gosec // path built from a checked ID
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNoBody, id)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read journal body: %w", err)
+	}
+
+	return b, nil
+}
+
+func (j *Journal) bodyPath(id string) (string, error) {
+	if !idPattern.MatchString(id) {
+		return "", fmt.Errorf("bad journal entry ID %q", id)
+	}
+
+	return filepath.Join(j.path+".bodies", id+".json"), nil
+}
+
+// keepBody saves the original body of id, and drops bodies older than
+// BodyMaxAge.
+func (j *Journal) keepBody(id string, body []byte) error {
+	path, err := j.bodyPath(id)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("ensure journal dir: %w", err)
+	}
+
+	if err := os.WriteFile(path, body, 0o600); err != nil {
+		return fmt.Errorf("write journal body: %w", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if e.Name() == filepath.Base(path) {
+			continue
+		}
+
+		if info, err := e.Info(); err == nil && j.now().Sub(info.ModTime()) > BodyMaxAge {
+			_ = os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+
+	return nil
+}
+
+func (j *Journal) append(rec record) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	rec.Time = j.now().UTC()
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o700); err != nil {
+		return fmt.Errorf("ensure journal dir: %w", err)
+	}
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode journal record: %w", err)
+	}
+
+	f, err := j.open(int64(len(b) + 1))
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("write journal: %w", err)
+	}
+
+	return nil
+}
+
+// open opens the log for appending n bytes, first moving it to path.1 when
+// they would take it past maxSize.
+func (j *Journal) open(n int64) (*os.File, error) {
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open journal: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil || j.maxSize <= 0 || info.Size() == 0 || info.Size()+n <= j.maxSize {
+		return f, nil //nolint:nilerr // an unknown size just skips rotation
+	}
+
+	_ = f.Close()
+
+	// Another process may have rotated it since; only move the file we saw.
+	if cur, err := os.Stat(j.path); err == nil && os.SameFile(cur, info) {
+		if err := os.Rename(j.path, j.path+".1"); err != nil {
+			return nil, fmt.Errorf("rotate journal: %w", err)
+		}
+	}
+
+	f, err = os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open journal: %w", err)
+	}
+
+	return f, nil
+}
+
+// List returns all entries, oldest first. A missing journal is empty.
+func (j *Journal) List() ([]Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var (
+		order   []string
+		entries = map[string]*Entry{}
+	)
+
+	// An entry's records may start in the previous file.
+	for _, path := range []string{j.path + ".1", j.path} {
+		if err := readRecords(path, func(rec record) {
+			e, ok := entries[rec.ID]
+			if !ok {
+				e = &Entry{ID: rec.ID, Time: rec.Time}
+				entries[rec.ID] = e
+				order = append(order, rec.ID)
+			}
+
+			e.fold(rec)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]Entry, 0, len(order))
+	for _, id := range order {
+		out = append(out, *entries[id])
+	}
+
+	return out, nil
+}
+
+// fold applies one record to the entry.
+func (e *Entry) fold(rec record) {
+	e.UpdatedAt = rec.Time
+
+	switch rec.Event {
+	case eventBegin:
+		e.Store, e.Method, e.Path, e.Body = rec.Store, rec.Method, rec.Path, rec.Body
+		e.Status, e.HTTPStatus, e.Error = StatusPending, 0, ""
+		e.Attempts++
+	case eventEnd:
+		e.Status, e.HTTPStatus, e.Error = rec.Status, rec.HTTPStatus, rec.Error
+	}
+}
+
+// readRecords calls fn with each record of the file at path. A missing
+// file has none.
+func readRecords(path string, fn func(record)) error {
+	f, err := os.Open(path) //nolint:gosec // journal path
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("open journal: %w", err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+
+	for sc.Scan() {
+		var rec record
+		// A torn final line (crash mid-write) is skipped rather than fatal.
+		if json.Unmarshal(sc.Bytes(), &rec) != nil || rec.ID == "" {
+			continue
+		}
+
+		fn(rec)
+	}
+
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("read journal: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the entry with the given ID.
+func (j *Journal) Get(id string) (Entry, error) {
+	entries, err := j.List()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	for _, e := range entries {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+
+	return Entry{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+type journalCtxKey struct{}
+
+type keyCtxKey struct{}
+
+// WithJournal attaches a Journal to the context; API writes made with it are recorded.
+func WithJournal(ctx context.Context, j *Journal) context.Context {
+	return context.WithValue(ctx, journalCtxKey{}, j)
+}
+
+// FromContext returns the Journal attached to ctx, or nil.
+func FromContext(ctx context.Context) *Journal {
+	j, _ := ctx.Value(journalCtxKey{}).(*Journal)
+
+	return j
+}
+
+// WithKey makes the next journaled write reuse key instead of generating one
+// (used when retrying an entry).
+func WithKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, keyCtxKey{}, key)
+}
+
+// KeyFromContext returns the idempotency key set by WithKey, or "".
+func KeyFromContext(ctx context.Context) string {
+	k, _ := ctx.Value(keyCtxKey{}).(string)
+
+	return k
+}
@@ -0,0 +1,258 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestJournal(t *testing.T) *Journal {
+	t.Helper()
+
+	return New(filepath.Join(t.TempDir(), "sub", "journal.jsonl"))
+}
+
+func TestJournal_FoldsAttempts(t *testing.T) {
+	t.Parallel()
+
+	j := newTestJournal(t)
+
+	steps := []func() error{
+		func() error { return j.Begin("a", "123", "POST", "products", []byte(`{"name":"x"}`)) },
+		func() error { return j.Finish("a", StatusUnknown, 0, "timeout") },
+		func() error { return j.Begin("b", "123", "DELETE", "products/9", nil) },
+		func() error { return j.Finish("b", StatusOK, 200, "") },
+		func() error { return j.Begin("a", "123", "POST", "products", []byte(`{"name":"x"}`)) },
+		func() error { return j.Finish("a", StatusOK, 201, "") },
+	}
+
+	for _, step := range steps {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := j.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("entries = %d, want 2", len(entries))
+	}
+
+	a := entries[0]
+	if a.ID != "a" || a.Attempts != 2 || a.Status != StatusOK || a.HTTPStatus != 201 || a.Error != "" {
+		t.Errorf("entry a = %+v", a)
+	}
+
+	if a.Retryable() {
+		t.Error("ok entry should not be retryable")
+	}
+
+	if string(a.Body) != `{"name":"x"}` {
+		t.Errorf("body = %s", a.Body)
+	}
+}
+
+func TestJournal_KeepsBodyUntilAcknowledged(t *testing.T) {
+	t.Parallel()
+
+	j := newTestJournal(t)
+	body := []byte(`{"name":"Ana","email":"ana@example.org"}`)
+
+	if err := j.Begin("a", "123", "POST", "customers", body); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(j.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(raw), "ana@example.org") || strings.Contains(string(raw), "Ana") {
+		t.Errorf("journal holds personal data: %s", raw)
+	}
+
+	if got, err := j.Body("a"); err != nil || string(got) != string(body) {
+		t.Errorf("Body() = %s, %v; want the original body", got, err)
+	}
+
+	if err := j.Finish("a", StatusUnknown, 0, "timeout"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := j.Body("a"); err != nil {
+		t.Errorf("Body() after unknown outcome: %v", err)
+	}
+
+	if err := j.Finish("a", StatusOK, 201, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := j.Body("a"); !errors.Is(err, ErrNoBody) {
+		t.Errorf("Body() after ok: err = %v, want ErrNoBody", err)
+	}
+
+	if _, err := j.Body("../a"); err == nil {
+		t.Error("expected an error for a bad ID")
+	}
+}
+
+func TestJournal_DropsOldBodies(t *testing.T) {
+	t.Parallel()
+
+	j := newTestJournal(t)
+	if err := j.Begin("old", "123", "PUT", "products/1", []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	j.now = func() time.Time { return time.Now().Add(BodyMaxAge + time.Hour) }
+
+	if err := j.Begin("new", "123", "PUT", "products/2", []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := j.Body("old"); !errors.Is(err, ErrNoBody) {
+		t.Errorf("old body: err = %v, want ErrNoBody", err)
+	}
+
+	if _, err := j.Body("new"); err != nil {
+		t.Errorf("new body: %v", err)
+	}
+}
+
+func TestJournal_Rotates(t *testing.T) {
+	t.Parallel()
+
+	j := newTestJournal(t)
+	j.maxSize = 400
+
+	for i := range 20 {
+		id := fmt.Sprintf("e%02d", i)
+		if err := j.Begin(id, "123", "DELETE", "products/1", nil); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := j.Finish(id, StatusOK, 200, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, path := range []string{j.Path(), j.Path() + ".1"} {
+		if info, err := os.Stat(path); err != nil || info.Size() > j.maxSize {
+			t.Errorf("%s: %v, %v; want at most %d bytes", path, info, err, j.maxSize)
+		}
+	}
+
+	entries, err := j.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Older entries were dropped; the newest are whole, even when they
+	// span both files.
+	if len(entries) == 0 || len(entries) >= 20 || entries[len(entries)-1].ID != "e19" {
+		t.Fatalf("entries = %+v", entries)
+	}
+
+	for _, e := range entries[1:] {
+		if e.Attempts != 1 || e.Status != StatusOK {
+			t.Errorf("entry = %+v", e)
+		}
+	}
+}
+
+func TestJournal_PendingWithoutOutcome(t *testing.T) {
+	t.Parallel()
+
+	j := newTestJournal(t)
+	if err := j.Begin("a", "123", "PUT", "products/1", []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := j.Get("a")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if e.Status != StatusPending || !e.Retryable() {
+		t.Errorf("entry = %+v, want retryable pending", e)
+	}
+}
+
+func TestJournal_SkipsTornLines(t *testing.T) {
+	t.Parallel()
+
+	j := newTestJournal(t)
+	if err := j.Begin("a", "123", "PUT", "products/1", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.OpenFile(j.Path(), os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = f.WriteString(`{"event":"end","id":"a","sta`)
+	_ = f.Close()
+
+	entries, err := j.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(entries) != 1 || entries[0].Status != StatusPending {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestJournal_MissingFileAndUnknownID(t *testing.T) {
+	t.Parallel()
+
+	j := newTestJournal(t)
+
+	entries, err := j.List()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("List() = %v, %v; want empty", entries, err)
+	}
+
+	if _, err := j.Get("nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestContextHelpers(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	if FromContext(ctx) != nil || KeyFromContext(ctx) != "" {
+		t.Fatal("empty context should carry nothing")
+	}
+
+	j := newTestJournal(t)
+	ctx = WithKey(WithJournal(ctx, j), "k")
+
+	if FromContext(ctx) != j || KeyFromContext(ctx) != "k" {
+		t.Error("context helpers did not round-trip")
+	}
+}
+
+func TestNewID(t *testing.T) {
+	t.Parallel()
+
+	a, err := NewID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := NewID()
+	if len(a) != 32 || a == b {
+		t.Errorf("NewID() = %q, %q", a, b)
+	}
+}
@@ -0,0 +1,117 @@
+// Package jsondiff computes field-level differences between decoded JSON
+// documents, expressed as RFC 6902 JSON Patch operations.
+package jsondiff
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Operation kinds.
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+)
+
+// Op is one JSON Patch operation. Old holds the value being replaced or
+// removed, for human-readable output; it is not part of the patch.
+type Op struct {
+	Op    string
+	Path  string
+	Value any
+	Old   any
+}
+
+// MarshalJSON emits the RFC 6902 form ({"op","path","value"}); remove
+// operations carry no value.
+func (o Op) MarshalJSON() ([]byte, error) {
+	if o.Op == OpRemove {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{o.Op, o.Path})
+	}
+
+	return json.Marshal(struct {
+		Op    string `json:"op"`
+		Path  string `json:"path"`
+		Value any    `json:"value"`
+	}{o.Op, o.Path, o.Value})
+}
+
+// Diff returns the operations that turn from into to. Both values must be
+// decoded JSON (map[string]any, []any, string, float64, bool, nil). Objects
+// are compared key by key in sorted order; arrays of different lengths are
+// replaced as a whole.
+func Diff(from, to any) []Op {
+	var ops []Op
+
+	diff("", from, to, &ops)
+
+	return ops
+}
+
+func diff(path string, from, to any, ops *[]Op) {
+	fm, fok := from.(map[string]any)
+	tm, tok := to.(map[string]any)
+
+	if fok && tok {
+		diffObjects(path, fm, tm, ops)
+		return
+	}
+
+	fa, fok := from.([]any)
+	ta, tok := to.([]any)
+
+	if fok && tok && len(fa) == len(ta) {
+		for i := range fa {
+			diff(path+"/"+strconv.Itoa(i), fa[i], ta[i], ops)
+		}
+
+		return
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*ops = append(*ops, Op{Op: OpReplace, Path: path, Value: to, Old: from})
+	}
+}
+
+func diffObjects(path string, from, to map[string]any, ops *[]Op) {
+	keys := make([]string, 0, len(from)+len(to))
+
+	for k := range from {
+		keys = append(keys, k)
+	}
+
+	for k := range to {
+		if _, ok := from[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		p := path + "/" + escape(k)
+		fv, inFrom := from[k]
+		tv, inTo := to[k]
+
+		switch {
+		case !inTo:
+			*ops = append(*ops, Op{Op: OpRemove, Path: p, Old: fv})
+		case !inFrom:
+			*ops = append(*ops, Op{Op: OpAdd, Path: p, Value: tv})
+		default:
+			diff(p, fv, tv, ops)
+		}
+	}
+}
+
+// escape encodes a key as a JSON Pointer reference token (RFC 6901).
+func escape(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
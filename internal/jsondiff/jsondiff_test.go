@@ -0,0 +1,61 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func decode(t *testing.T, s string) any {
+	t.Helper()
+
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("decode %q: %v", s, err)
+	}
+
+	return v
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want string
+	}{
+		{name: "equal", from: `{"a":1,"b":[1,2]}`, to: `{"b":[1,2],"a":1}`, want: `null`},
+		{name: "replace scalar", from: `{"a":1}`, to: `{"a":2}`, want: `[{"op":"replace","path":"/a","value":2}]`},
+		{name: "add and remove", from: `{"a":1}`, to: `{"b":null}`, want: `[{"op":"remove","path":"/a"},{"op":"add","path":"/b","value":null}]`},
+		{name: "nested", from: `{"name":{"es":"Remera","pt":"Camisa"}}`, to: `{"name":{"es":"Remera roja","pt":"Camisa"}}`, want: `[{"op":"replace","path":"/name/es","value":"Remera roja"}]`},
+		{name: "array same length", from: `{"t":["a","b"]}`, to: `{"t":["a","c"]}`, want: `[{"op":"replace","path":"/t/1","value":"c"}]`},
+		{name: "array resized", from: `{"t":["a"]}`, to: `{"t":["a","b"]}`, want: `[{"op":"replace","path":"/t","value":["a","b"]}]`},
+		{name: "type change", from: `{"a":{"x":1}}`, to: `{"a":"x"}`, want: `[{"op":"replace","path":"/a","value":"x"}]`},
+		{name: "escaped key", from: `{"a/b~c":1}`, to: `{"a/b~c":2}`, want: `[{"op":"replace","path":"/a~1b~0c","value":2}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := json.Marshal(Diff(decode(t, tt.from), decode(t, tt.to)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Diff() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff_KeepsOldValue(t *testing.T) {
+	t.Parallel()
+
+	ops := Diff(decode(t, `{"a":"old","b":true}`), decode(t, `{"a":"new"}`))
+	if len(ops) != 2 || ops[0].Old != "old" || ops[1].Old != true {
+		t.Errorf("ops = %+v", ops)
+	}
+}
@@ -0,0 +1,92 @@
+package lockfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAcquire_Exclusive(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "locks", "sync.lock")
+
+	l, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	_, err = Acquire(path)
+
+	var locked *LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("second Acquire error = %v, want *LockedError", err)
+	}
+
+	if locked.Owner.PID != os.Getpid() {
+		t.Errorf("owner pid = %d, want %d", locked.Owner.PID, os.Getpid())
+	}
+
+	if err := l.Release(); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+
+	l, err = Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire after release: %v", err)
+	}
+
+	_ = l.Release()
+}
+
+func TestAcquire_LeftoverFile(t *testing.T) {
+	t.Parallel()
+
+	// A file left by a crashed run, or an older version, holds no lock.
+	path := filepath.Join(t.TempDir(), "sync.lock")
+	if err := os.WriteFile(path, []byte(`{"pid":1,"since":"2020-01-01T00:00:00Z"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire over leftover file: %v", err)
+	}
+
+	if owner := readOwner(path); owner.PID != os.Getpid() {
+		t.Errorf("owner pid = %d, want %d", owner.PID, os.Getpid())
+	}
+
+	_ = l.Release()
+}
+
+func TestRelease_KeepsNewHolder(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "sync.lock")
+
+	first, err := Acquire(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := first.Release(); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := Acquire(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = second.Release() })
+
+	// A second Release of the old lock must not free the new holder's.
+	_ = first.Release()
+
+	var locked *LockedError
+	if _, err := Acquire(path); !errors.As(err, &locked) {
+		t.Errorf("Acquire after stale Release = %v, want *LockedError", err)
+	}
+}
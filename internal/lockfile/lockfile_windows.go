@@ -0,0 +1,29 @@
+package lockfile
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockOffset is where the locked byte sits: Windows locks are mandatory, so
+// the range is past anything written, leaving the owner record readable.
+const lockOffset = 1 << 30
+
+func tryLock(f *os.File) error {
+	ol := windows.Overlapped{OffsetHigh: lockOffset}
+
+	err := windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, &ol)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errLocked
+	}
+
+	return err
+}
+
+func unlock(f *os.File) error {
+	ol := windows.Overlapped{OffsetHigh: lockOffset}
+
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, &ol)
+}
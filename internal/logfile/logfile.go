@@ -0,0 +1,151 @@
+// Package logfile provides an append-only log file that rotates by size, for
+// keeping the logs of scheduled runs without letting them grow unbounded.
+//
+// When a write would take the file past its size limit, the file is renamed
+// to path.1 (shifting older backups to path.2, path.3, ...) and a new file is
+// started. Backups past the limit are removed.
+package logfile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// Defaults used by the CLI's --log-file.
+const (
+	DefaultMaxSize = 10 << 20
+	DefaultBackups = 3
+)
+
+// File is a size-rotated log file. It is safe for concurrent use.
+type File struct {
+	path    string
+	maxSize int64
+	backups int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// Open opens path for appending, creating it and its directory as needed.
+// maxSize is the size in bytes that triggers a rotation (zero never
+// rotates); backups is how many rotated files are kept.
+func Open(path string, maxSize int64, backups int) (*File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("create log dir: %w", err)
+	}
+
+	l := &File{path: path, maxSize: maxSize, backups: backups}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (l *File) open() error {
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+
+		return fmt.Errorf("stat log file: %w", err)
+	}
+
+	l.f, l.size = f, info.Size()
+
+	return nil
+}
+
+// Write appends p, rotating first if p would take the file past its limit.
+// A single write is never split across files.
+func (l *File) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.f == nil {
+		return 0, fs.ErrClosed
+	}
+
+	if l.maxSize > 0 && l.size > 0 && l.size+int64(len(p)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := l.f.Write(p)
+	l.size += int64(n)
+
+	if err != nil {
+		return n, fmt.Errorf("write log file: %w", err)
+	}
+
+	return n, nil
+}
+
+// rotate shifts the backups, moves the current file to path.1 and reopens
+// path empty.
+func (l *File) rotate() error {
+	if err := l.f.Close(); err != nil {
+		return fmt.Errorf("close log file: %w", err)
+	}
+
+	l.f = nil
+
+	if l.backups <= 0 {
+		if err := os.Remove(l.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("rotate log file: %w", err)
+		}
+
+		return l.open()
+	}
+
+	if err := os.Remove(l.backup(l.backups)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("rotate log file: %w", err)
+	}
+
+	for i := l.backups - 1; i >= 1; i-- {
+		if err := os.Rename(l.backup(i), l.backup(i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("rotate log file: %w", err)
+		}
+	}
+
+	if err := os.Rename(l.path, l.backup(1)); err != nil {
+		return fmt.Errorf("rotate log file: %w", err)
+	}
+
+	return l.open()
+}
+
+func (l *File) backup(n int) string {
+	return l.path + "." + strconv.Itoa(n)
+}
+
+// Close closes the file. Later writes fail.
+func (l *File) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.f == nil {
+		return nil
+	}
+
+	err := l.f.Close()
+	l.f = nil
+
+	if err != nil {
+		return fmt.Errorf("close log file: %w", err)
+	}
+
+	return nil
+}
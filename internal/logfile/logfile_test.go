@@ -0,0 +1,107 @@
+package logfile
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+
+	return string(b)
+}
+
+func TestFile_Rotates(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "logs", "nube.log")
+
+	l, err := Open(path, 10, 2)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := l.Write([]byte(line)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	for name, want := range map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	} {
+		if got := readFile(t, name); got != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(name), got, want)
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("path.3 should not exist, stat error = %v", err)
+	}
+}
+
+func TestFile_AppendsAcrossOpens(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "nube.log")
+
+	for _, line := range []string{"a\n", "b\n"} {
+		l, err := Open(path, DefaultMaxSize, DefaultBackups)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+
+		if _, err := l.Write([]byte(line)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+
+		_ = l.Close()
+	}
+
+	if got := readFile(t, path); got != "a\nb\n" {
+		t.Errorf("log = %q, want both lines", got)
+	}
+}
+
+func TestFile_ExistingSizeCounts(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "nube.log")
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", 8)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := Open(path, 10, 0)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	if _, err := l.Write([]byte("new\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	_ = l.Close()
+
+	if got := readFile(t, path); got != "new\n" {
+		t.Errorf("log = %q, want only the new line (no backups kept)", got)
+	}
+
+	if _, err := l.Write([]byte("late\n")); !errors.Is(err, fs.ErrClosed) {
+		t.Errorf("Write after Close error = %v, want fs.ErrClosed", err)
+	}
+}
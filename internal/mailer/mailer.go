@@ -0,0 +1,247 @@
+// Package mailer builds MIME messages with attachments and sends them over
+// SMTP or through the local sendmail.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoTransport is returned by Send when there is no SMTP server and no
+// sendmail to fall back on.
+var ErrNoTransport = errors.New("no SMTP server configured and no sendmail found")
+
+// Attachment is a file attached to a message.
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Message is an HTML email. From may be empty when sendmail sends it.
+type Message struct {
+	From        string
+	To          []string
+	Subject     string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Bytes renders m as a multipart/mixed MIME message with CRLF line ends.
+func (m *Message) Bytes() []byte {
+	var b bytes.Buffer
+
+	boundary := newBoundary()
+
+	header := func(k, v string) { fmt.Fprintf(&b, "%s: %s\r\n", k, v) }
+
+	if m.From != "" {
+		header("From", m.From)
+	}
+
+	header("To", strings.Join(m.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `multipart/mixed; boundary="`+boundary+`"`)
+	b.WriteString("\r\n")
+
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	header("Content-Type", "text/html; charset=utf-8")
+	header("Content-Transfer-Encoding", "base64")
+	b.WriteString("\r\n")
+	writeBase64(&b, []byte(m.HTML))
+
+	for _, a := range m.Attachments {
+		fmt.Fprintf(&b, "--%s\r\n", boundary)
+		header("Content-Type", a.ContentType)
+		header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Name}))
+		header("Content-Transfer-Encoding", "base64")
+		b.WriteString("\r\n")
+		writeBase64(&b, a.Data)
+	}
+
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+
+	return b.Bytes()
+}
+
+// writeBase64 writes data in base64 lines of 76 characters (RFC 2045).
+func writeBase64(b *bytes.Buffer, data []byte) {
+	s := base64.StdEncoding.EncodeToString(data)
+
+	for len(s) > 76 {
+		b.WriteString(s[:76] + "\r\n")
+		s = s[76:]
+	}
+
+	b.WriteString(s + "\r\n")
+}
+
+func newBoundary() string {
+	buf := make([]byte, 12)
+	_, _ = rand.Read(buf)
+
+	return "nube-" + hex.EncodeToString(buf)
+}
+
+// SMTP is an SMTP server to send through.
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// TLS is "starttls" (the default: upgrade when the server offers it),
+	// "tls" for implicit TLS (usually port 465), or "none".
+	TLS string
+}
+
+// Send delivers m through server, or through sendmail when server is nil.
+func Send(ctx context.Context, server *SMTP, m *Message) error {
+	if server != nil {
+		return server.Send(ctx, m)
+	}
+
+	path, err := exec.LookPath(Sendmail)
+	if err != nil {
+		return ErrNoTransport
+	}
+
+	return sendmail(ctx, path, m)
+}
+
+// Sendmail is the sendmail command Send falls back on. It is a variable so
+// tests can swap it.
+var Sendmail = "sendmail"
+
+// sendmail pipes m to "sendmail -t -i", which reads the recipients from
+// the To header.
+func sendmail(ctx context.Context, path string, m *Message) error {
+	cmd := exec.CommandContext(ctx, path, "-t", "-i")
+	cmd.Stdin = bytes.NewReader(m.Bytes())
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("sendmail: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// Send delivers m through the server.
+func (s *SMTP) Send(ctx context.Context, m *Message) error {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return fmt.Errorf("smtp: sender %q: %w", m.From, err)
+	}
+
+	port := s.Port
+	if port == 0 {
+		port = 587
+		if s.TLS == "tls" {
+			port = 465
+		}
+	}
+
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(port))
+
+	conn, err := s.dial(ctx, addr)
+	if err != nil {
+		return fmt.Errorf("smtp: connect %s: %w", addr, err)
+	}
+
+	// net/smtp has no context support; a deadline bounds the whole session.
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		_ = conn.Close()
+
+		return fmt.Errorf("smtp: %w", err)
+	}
+
+	defer func() { _ = c.Close() }()
+
+	if err := s.session(c, from.Address, m); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SMTP) dial(ctx context.Context, addr string) (net.Conn, error) {
+	d := &net.Dialer{Timeout: 30 * time.Second}
+
+	if s.TLS == "tls" {
+		td := &tls.Dialer{NetDialer: d, Config: &tls.Config{ServerName: s.Host, MinVersion: tls.VersionTLS12}}
+
+		return td.DialContext(ctx, "tcp", addr) //nolint:wrapcheck // wrapped by Send
+	}
+
+	return d.DialContext(ctx, "tcp", addr) //nolint:wrapcheck // wrapped by Send
+}
+
+func (s *SMTP) session(c *smtp.Client, from string, m *Message) error {
+	if s.TLS == "" || s.TLS == "starttls" {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(&tls.Config{ServerName: s.Host, MinVersion: tls.VersionTLS12}); err != nil {
+				return fmt.Errorf("starttls: %w", err)
+			}
+		}
+	}
+
+	if s.Username != "" {
+		// PlainAuth refuses to send the password unencrypted, except to
+		// localhost.
+		if err := c.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	if err := c.Mail(from); err != nil {
+		return fmt.Errorf("MAIL FROM: %w", err)
+	}
+
+	for _, to := range m.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("RCPT TO %s: %w", to, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("DATA: %w", err)
+	}
+
+	if _, err := w.Write(m.Bytes()); err != nil {
+		return fmt.Errorf("DATA: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("DATA: %w", err)
+	}
+
+	if err := c.Quit(); err != nil {
+		return fmt.Errorf("QUIT: %w", err)
+	}
+
+	return nil
+}
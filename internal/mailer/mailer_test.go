@@ -0,0 +1,242 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var testMessage = &Message{
+	From:    "Tienda <reports@shop.test>",
+	To:      []string{"owner@shop.test", "ops@shop.test"},
+	Subject: "Ventas por día",
+	HTML:    "<table><tr><td>2024-06-01</td></tr></table>",
+	Attachments: []Attachment{
+		{Name: "sales.csv", ContentType: "text/csv; charset=utf-8", Data: []byte("period,orders\n2024-06-01,3\n")},
+	},
+}
+
+// parseMessage returns the subject and the parts of a rendered message by
+// content type.
+func parseMessage(t *testing.T, raw []byte) (string, map[string]string) {
+	t.Helper()
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := map[string]string{}
+	r := multipart.NewReader(msg.Body, params["boundary"])
+
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// multipart decodes quoted-printable only; base64 is left to us.
+		b, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, p))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		key := p.Header.Get("Content-Type")
+		if name := p.FileName(); name != "" {
+			key = name
+		}
+
+		parts[key] = string(b)
+	}
+
+	return subject, parts
+}
+
+func TestMessageBytes(t *testing.T) {
+	t.Parallel()
+
+	subject, parts := parseMessage(t, testMessage.Bytes())
+
+	if subject != "Ventas por día" {
+		t.Errorf("subject = %q", subject)
+	}
+
+	if parts["text/html; charset=utf-8"] != testMessage.HTML {
+		t.Errorf("html part = %q", parts["text/html; charset=utf-8"])
+	}
+
+	if parts["sales.csv"] != "period,orders\n2024-06-01,3\n" {
+		t.Errorf("attachment = %q", parts["sales.csv"])
+	}
+}
+
+// fakeSMTP accepts one message on a local port and returns the port and a
+// channel receiving the envelope and data.
+func fakeSMTP(t *testing.T) (int, <-chan []string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = ln.Close() })
+
+	got := make(chan []string, 1)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = conn.Close() }()
+
+		var lines []string
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+
+		reply("220 fake")
+
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				got <- lines
+
+				return
+			}
+
+			line = strings.TrimRight(line, "\r\n")
+			cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+			switch cmd {
+			case "EHLO":
+				reply("250-fake\r\n250 AUTH PLAIN")
+			case "AUTH", "MAIL", "RCPT":
+				lines = append(lines, line)
+
+				if cmd == "AUTH" {
+					reply("235 ok")
+				} else {
+					reply("250 ok")
+				}
+			case "DATA":
+				reply("354 go")
+
+				var data strings.Builder
+
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+
+					data.WriteString(l)
+				}
+
+				lines = append(lines, data.String())
+
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+
+				got <- lines
+
+				return
+			default:
+				reply("502 no")
+			}
+		}
+	}()
+
+	return ln.Addr().(*net.TCPAddr).Port, got
+}
+
+func TestSMTPSend(t *testing.T) {
+	t.Parallel()
+
+	port, got := fakeSMTP(t)
+	server := &SMTP{Host: "localhost", Port: port, Username: "reports", Password: "secret", TLS: "none"}
+
+	if err := server.Send(context.Background(), testMessage); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	lines := <-got
+	if len(lines) != 5 {
+		t.Fatalf("session = %q", lines)
+	}
+
+	if !strings.HasPrefix(lines[0], "AUTH PLAIN ") || lines[1] != "MAIL FROM:<reports@shop.test>" ||
+		lines[2] != "RCPT TO:<owner@shop.test>" || lines[3] != "RCPT TO:<ops@shop.test>" {
+		t.Errorf("envelope = %q", lines[:4])
+	}
+
+	if _, parts := parseMessage(t, []byte(lines[4])); parts["sales.csv"] == "" {
+		t.Errorf("data has no attachment: %q", lines[4])
+	}
+}
+
+func TestSend_Sendmail(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell script")
+	}
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "message")
+	script := filepath.Join(dir, "sendmail")
+
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > "+strconv.Quote(out+".args")+"\ncat > "+strconv.Quote(out)+"\n"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	orig := Sendmail
+	Sendmail = script
+
+	t.Cleanup(func() { Sendmail = orig })
+
+	if err := Send(context.Background(), nil, testMessage); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	args, _ := os.ReadFile(out + ".args")
+	if strings.TrimSpace(string(args)) != "-t -i" {
+		t.Errorf("sendmail args = %q", args)
+	}
+
+	raw, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if subject, _ := parseMessage(t, raw); subject != testMessage.Subject {
+		t.Errorf("subject = %q", subject)
+	}
+}
@@ -0,0 +1,10 @@
+package mirror
+
+import (
+	// Registers the "sqlite" database/sql driver. It is pure Go, so every
+	// release build, cgo or not, can sync.
+	_ "modernc.org/sqlite"
+)
+
+// driverName is the database/sql driver the mirror is opened with.
+const driverName = "sqlite"
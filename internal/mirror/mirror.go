@@ -0,0 +1,403 @@
+// Package mirror keeps a local SQLite copy of store resources, updated
+// incrementally by updated_at, so large stores can be analyzed with SQL
+// instead of paging through the API again for every question.
+//
+// Each resource is a table with its id, created_at, updated_at, a few typed
+// columns for common filters and the full API object as JSON in data (query
+// the rest with json_extract and json_each).
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrOtherStore is returned by Open for a database mirroring another store.
+var ErrOtherStore = errors.New("database mirrors another store")
+
+// Resources are the mirrored resources, in sync order.
+var Resources = []string{"products", "orders", "customers"}
+
+// column is a typed table column filled from the item field at path.
+type column struct {
+	name string
+	typ  string
+	path string
+}
+
+// columns are the typed columns of each table besides id, created_at,
+// updated_at, synced_at and data. Adding one needs a migration; the schema
+// is meant to stay stable for saved queries.
+var columns = map[string][]column{
+	"products": {
+		{name: "name", typ: "TEXT", path: "name"},
+		{name: "handle", typ: "TEXT", path: "handle"},
+		{name: "published", typ: "INTEGER", path: "published"},
+	},
+	"orders": {
+		{name: "number", typ: "INTEGER", path: "number"},
+		{name: "status", typ: "TEXT", path: "status"},
+		{name: "payment_status", typ: "TEXT", path: "payment_status"},
+		{name: "shipping_status", typ: "TEXT", path: "shipping_status"},
+		{name: "total", typ: "NUMERIC", path: "total"},
+		{name: "currency", typ: "TEXT", path: "currency"},
+		{name: "customer_id", typ: "INTEGER", path: "customer.id"},
+		{name: "email", typ: "TEXT", path: "contact_email"},
+	},
+	"customers": {
+		{name: "name", typ: "TEXT", path: "name"},
+		{name: "email", typ: "TEXT", path: "email"},
+		{name: "phone", typ: "TEXT", path: "phone"},
+		{name: "total_spent", typ: "NUMERIC", path: "total_spent"},
+	},
+}
+
+// Options configure Open.
+type Options struct {
+	// StoreID is the store being mirrored; a database holds one store.
+	StoreID string
+	// Translate picks the text of a multilingual field such as a product
+	// name. Without it the first non-empty translation is used.
+	Translate func(obj map[string]any, key string) string
+}
+
+// DB is an open mirror database.
+type DB struct {
+	db        *sql.DB
+	translate func(obj map[string]any, key string) string
+}
+
+// Open opens the mirror at path for writing, creating it and its tables if
+// needed.
+func Open(ctx context.Context, path string, opts Options) (*DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("create mirror dir: %w", err)
+	}
+
+	db, err := sql.Open(driverName, "file:"+path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("open mirror: %w", err)
+	}
+
+	m := &DB{db: db, translate: opts.Translate}
+	if m.translate == nil {
+		m.translate = firstTranslation
+	}
+
+	if err := m.migrate(ctx, opts.StoreID); err != nil {
+		_ = db.Close()
+
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// OpenReadOnly opens an existing mirror for queries only.
+func OpenReadOnly(path string) (*DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("open mirror: %w", err)
+	}
+
+	db, err := sql.Open(driverName, "file:"+path+"?mode=ro&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("open mirror: %w", err)
+	}
+
+	return &DB{db: db}, nil
+}
+
+// Close closes the database.
+func (m *DB) Close() error {
+	if err := m.db.Close(); err != nil {
+		return fmt.Errorf("close mirror: %w", err)
+	}
+
+	return nil
+}
+
+func (m *DB) migrate(ctx context.Context, storeID string) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS meta (key TEXT PRIMARY KEY, value TEXT NOT NULL)`,
+		`CREATE TABLE IF NOT EXISTS sync_state (resource TEXT PRIMARY KEY, cursor TEXT NOT NULL, synced_at TEXT NOT NULL)`,
+	}
+
+	for _, r := range Resources {
+		defs := []string{"id INTEGER PRIMARY KEY", "created_at TEXT", "updated_at TEXT"}
+		for _, c := range columns[r] {
+			defs = append(defs, c.name+" "+c.typ)
+		}
+
+		defs = append(defs, "synced_at TEXT NOT NULL", "data TEXT NOT NULL")
+		stmts = append(stmts,
+			fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", r, strings.Join(defs, ", ")),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_updated_at ON %s (updated_at)", r, r))
+	}
+
+	for _, s := range stmts {
+		if _, err := m.db.ExecContext(ctx, s); err != nil {
+			return fmt.Errorf("create mirror schema: %w", err)
+		}
+	}
+
+	var have string
+
+	err := m.db.QueryRowContext(ctx, `SELECT value FROM meta WHERE key = 'store_id'`).Scan(&have)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		if _, err := m.db.ExecContext(ctx, `INSERT INTO meta (key, value) VALUES ('store_id', ?)`, storeID); err != nil {
+			return fmt.Errorf("write mirror store: %w", err)
+		}
+	case err != nil:
+		return fmt.Errorf("read mirror store: %w", err)
+	case have != storeID:
+		return fmt.Errorf("%w: %s, not %s", ErrOtherStore, have, storeID)
+	}
+
+	return nil
+}
+
+// Cursor returns the updated_at the next sync of resource starts from, or ""
+// before its first complete sync.
+func (m *DB) Cursor(ctx context.Context, resource string) (string, error) {
+	var cursor string
+
+	err := m.db.QueryRowContext(ctx, `SELECT cursor FROM sync_state WHERE resource = ?`, resource).Scan(&cursor)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("read sync state: %w", err)
+	}
+
+	return cursor, nil
+}
+
+// Upsert writes items of resource in one transaction, stamping them with
+// syncedAt. It returns the latest updated_at among them.
+func (m *DB) Upsert(ctx context.Context, resource string, items []map[string]any, syncedAt string) (string, error) {
+	cols := columns[resource]
+	if cols == nil {
+		return "", fmt.Errorf("unknown mirror resource %q", resource)
+	}
+
+	names := []string{"id", "created_at", "updated_at"}
+	for _, c := range cols {
+		names = append(names, c.name)
+	}
+
+	names = append(names, "synced_at", "data")
+
+	updates := make([]string, 0, len(names)-1)
+	for _, n := range names[1:] {
+		updates = append(updates, n+" = excluded."+n)
+	}
+
+	stmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (id) DO UPDATE SET %s",
+		resource, strings.Join(names, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", "), strings.Join(updates, ", "))
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("begin mirror write: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	var latest string
+
+	for _, item := range items {
+		id, ok := intValue(item["id"])
+		if !ok {
+			continue
+		}
+
+		data, err := json.Marshal(item)
+		if err != nil {
+			return "", fmt.Errorf("encode %s %d: %w", resource, id, err)
+		}
+
+		updated, _ := item["updated_at"].(string)
+		created, _ := item["created_at"].(string)
+		latest = max(latest, updated)
+
+		args := []any{id, created, updated}
+		for _, c := range cols {
+			args = append(args, m.value(item, c.path))
+		}
+
+		args = append(args, syncedAt, string(data))
+
+		if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+			return "", fmt.Errorf("write %s %d: %w", resource, id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("commit mirror write: %w", err)
+	}
+
+	return latest, nil
+}
+
+// Finish records a complete sync of resource: the next one starts at cursor.
+func (m *DB) Finish(ctx context.Context, resource, cursor, syncedAt string) error {
+	_, err := m.db.ExecContext(ctx,
+		`INSERT INTO sync_state (resource, cursor, synced_at) VALUES (?, ?, ?)
+		 ON CONFLICT (resource) DO UPDATE SET cursor = excluded.cursor, synced_at = excluded.synced_at`,
+		resource, cursor, syncedAt)
+	if err != nil {
+		return fmt.Errorf("write sync state: %w", err)
+	}
+
+	return nil
+}
+
+// Prune deletes the rows of resource a full sync stamped syncedAt didn't
+// see: they were deleted from the store.
+func (m *DB) Prune(ctx context.Context, resource, syncedAt string) (int, error) {
+	if columns[resource] == nil {
+		return 0, fmt.Errorf("unknown mirror resource %q", resource)
+	}
+
+	res, err := m.db.ExecContext(ctx, "DELETE FROM "+resource+" WHERE synced_at <> ?", syncedAt)
+	if err != nil {
+		return 0, fmt.Errorf("prune %s: %w", resource, err)
+	}
+
+	n, _ := res.RowsAffected()
+
+	return int(n), nil
+}
+
+// Count returns the number of rows of resource.
+func (m *DB) Count(ctx context.Context, resource string) (int, error) {
+	if columns[resource] == nil {
+		return 0, fmt.Errorf("unknown mirror resource %q", resource)
+	}
+
+	var n int
+	if err := m.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+resource).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count %s: %w", resource, err)
+	}
+
+	return n, nil
+}
+
+// Query runs a statement and returns its column names and rows. Text and
+// blob values come back as strings.
+func (m *DB) Query(ctx context.Context, query string, args ...any) ([]string, [][]any, error) {
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, nil, fmt.Errorf("query: %w", err)
+	}
+
+	out := [][]any{}
+
+	for rows.Next() {
+		vals := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, nil, fmt.Errorf("query: %w", err)
+		}
+
+		for i, v := range vals {
+			if b, ok := v.([]byte); ok {
+				vals[i] = string(b)
+			}
+		}
+
+		out = append(out, vals)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("query: %w", err)
+	}
+
+	return cols, out, nil
+}
+
+// value converts the field at a dotted path to a column value: translations
+// to text, booleans to 0/1 and other objects and lists to JSON.
+func (m *DB) value(item map[string]any, path string) any {
+	segs := strings.Split(path, ".")
+
+	cur := item
+	for _, seg := range segs[:len(segs)-1] {
+		next, ok := cur[seg].(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		cur = next
+	}
+
+	key := segs[len(segs)-1]
+
+	switch v := cur[key].(type) {
+	case map[string]any:
+		return m.translate(cur, key)
+	case []any:
+		b, _ := json.Marshal(v)
+
+		return string(b)
+	case bool:
+		if v {
+			return 1
+		}
+
+		return 0
+	case json.Number:
+		return v.String()
+	default:
+		return v
+	}
+}
+
+func firstTranslation(obj map[string]any, key string) string {
+	m, _ := obj[key].(map[string]any)
+
+	for _, lang := range slices.Sorted(maps.Keys(m)) {
+		if s, ok := m[lang].(string); ok && s != "" {
+			return s
+		}
+	}
+
+	return ""
+}
+
+// intValue reads a JSON id.
+func intValue(v any) (int64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return int64(x), x == float64(int64(x))
+	case json.Number:
+		n, err := x.Int64()
+
+		return n, err == nil
+	case string:
+		n, err := strconv.ParseInt(x, 10, 64)
+
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
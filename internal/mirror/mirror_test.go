@@ -0,0 +1,118 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpsertPrune(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "mirror", "123.db")
+
+	db, err := Open(ctx, path, Options{StoreID: "123"})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	defer func() { _ = db.Close() }()
+
+	if cursor, err := db.Cursor(ctx, "products"); err != nil || cursor != "" {
+		t.Fatalf("Cursor = %q, %v; want empty", cursor, err)
+	}
+
+	latest, err := db.Upsert(ctx, "products", []map[string]any{
+		{"id": float64(1), "name": map[string]any{"pt": "Camisa", "es": "Remera"}, "published": true, "updated_at": "2024-06-01T10:00:00+0000"},
+		{"id": float64(2), "name": map[string]any{"es": "Zapato"}, "published": false, "updated_at": "2024-06-02T10:00:00+0000"},
+	}, "s1")
+	if err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	if latest != "2024-06-02T10:00:00+0000" {
+		t.Errorf("latest = %q", latest)
+	}
+
+	if err := db.Finish(ctx, "products", latest, "s1"); err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+
+	if cursor, _ := db.Cursor(ctx, "products"); cursor != latest {
+		t.Errorf("Cursor = %q, want %q", cursor, latest)
+	}
+
+	// A later change to product 1, seen by a full sync that no longer
+	// finds product 2.
+	if _, err := db.Upsert(ctx, "products", []map[string]any{
+		{"id": float64(1), "name": map[string]any{"es": "Remera lisa"}, "published": true},
+	}, "s2"); err != nil {
+		t.Fatalf("second Upsert: %v", err)
+	}
+
+	if n, err := db.Prune(ctx, "products", "s2"); err != nil || n != 1 {
+		t.Fatalf("Prune = %d, %v; want 1", n, err)
+	}
+
+	cols, rows, err := db.Query(ctx, "SELECT id, name, published, json_extract(data, '$.name.es') FROM products")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+
+	if len(cols) != 4 || len(rows) != 1 {
+		t.Fatalf("cols = %v, rows = %v", cols, rows)
+	}
+
+	if rows[0][0] != int64(1) || rows[0][1] != "Remera lisa" || rows[0][2] != int64(1) || rows[0][3] != "Remera lisa" {
+		t.Errorf("row = %v", rows[0])
+	}
+}
+
+func TestOpen_OtherStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "m.db")
+
+	db, err := Open(ctx, path, Options{StoreID: "123"})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	_ = db.Close()
+
+	if _, err := Open(ctx, path, Options{StoreID: "456"}); !errors.Is(err, ErrOtherStore) {
+		t.Errorf("error = %v, want ErrOtherStore", err)
+	}
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "m.db")
+
+	if _, err := OpenReadOnly(path); err == nil {
+		t.Fatal("OpenReadOnly of a missing file: want error")
+	}
+
+	db, err := Open(ctx, path, Options{StoreID: "123"})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	_ = db.Close()
+
+	ro, err := OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("OpenReadOnly: %v", err)
+	}
+
+	defer func() { _ = ro.Close() }()
+
+	if _, _, err := ro.Query(ctx, "DELETE FROM products"); err == nil {
+		t.Error("write through a read-only mirror: want error")
+	}
+}
@@ -0,0 +1,155 @@
+package oauth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gberlati/nube-cli/internal/i18n"
+)
+
+var (
+	// brokerPortPattern matches the CLI callback ports a broker redirects to.
+	brokerPortPattern = regexp.MustCompile(`^\d{4,5}$`)
+	// brokerStatePattern matches the CLI's state (see randomState).
+	brokerStatePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{16,128}$`)
+)
+
+// NewBroker returns an OAuth broker for the app with the given client ID
+// and secret. It speaks the protocol of the Cloudflare worker in broker/,
+// so nube login --broker-url can point at either:
+//
+//  1. GET /start?port=<port>&state=<state> redirects to the Tienda Nube
+//     authorize page, passing <port>.<state> as its state.
+//  2. GET /callback?code=<code>&state=<port>.<state>, where Tienda Nube
+//     sends the browser back, exchanges the code and redirects to
+//     http://127.0.0.1:<port>/callback?token=<token>&user_id=<id>&state=<state>.
+//
+// The CLI checks that the state it made up comes back, so the broker keeps
+// nothing between the two requests. Without a state (older CLIs) the port
+// alone is passed through. The app's redirect URL must be the broker's
+// /callback.
+//
+// For logins approved on another device (nube login --qr), /start gets
+// poll=1 instead of a port and the Tienda Nube state is poll.<state>. The
+// callback then keeps the token in memory, and POST /poll with the form
+// value verifier=<verifier> returns it once as JSON (204 until then), if
+// <state> is the unpadded base64url SHA-256 of <verifier>. The state is in
+// the QR code for anyone to see; the verifier never leaves the CLI until it
+// polls. Tokens nobody polls for are dropped after 10 minutes.
+func NewBroker(clientID, clientSecret string) http.Handler {
+	creds := clientCredentials{clientID: clientID, clientSecret: clientSecret}
+	polls := newPollStore()
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /start", func(w http.ResponseWriter, r *http.Request) {
+		port, state := r.URL.Query().Get("port"), r.URL.Query().Get("state")
+		if r.URL.Query().Get("poll") != "" {
+			if !brokerStatePattern.MatchString(state) {
+				http.Error(w, "Bad Request: invalid or missing state parameter", http.StatusBadRequest)
+				return
+			}
+
+			http.Redirect(w, r, authURL(creds.clientID)+"?state=poll."+state, http.StatusFound)
+
+			return
+		}
+
+		if !brokerPortPattern.MatchString(port) {
+			http.Error(w, "Bad Request: invalid or missing port parameter", http.StatusBadRequest)
+			return
+		}
+
+		upstreamState := port
+
+		if state != "" {
+			if !brokerStatePattern.MatchString(state) {
+				http.Error(w, "Bad Request: invalid state parameter", http.StatusBadRequest)
+				return
+			}
+
+			upstreamState += "." + state
+		}
+
+		http.Redirect(w, r, authURL(creds.clientID)+"?state="+upstreamState, http.StatusFound)
+	})
+
+	mux.HandleFunc("GET /callback", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		code := q.Get("code")
+		if code == "" {
+			http.Error(w, "Bad Request: missing code parameter", http.StatusBadRequest)
+			return
+		}
+
+		port, state, withState := strings.Cut(q.Get("state"), ".")
+		poll := port == "poll"
+
+		valid := brokerPortPattern.MatchString(port) && (!withState || brokerStatePattern.MatchString(state))
+		if poll {
+			valid = brokerStatePattern.MatchString(state)
+		}
+
+		if !valid {
+			http.Error(w, "Bad Request: invalid or missing state parameter", http.StatusBadRequest)
+			return
+		}
+
+		tok, err := exchangeCode(r.Context(), creds, code)
+		if err != nil {
+			http.Error(w, "Bad Gateway: "+err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		if poll {
+			polls.put(state, tok)
+			writeCallbackPage(w, i18n.Default(), defaultCallbackTemplate, http.StatusOK, true, i18n.T("Go back to the terminal: nube finishes logging in there. You can close this page."))
+
+			return
+		}
+
+		params := url.Values{"token": {tok.AccessToken}}
+		if tok.UserID != "" {
+			params.Set("user_id", tok.UserID.String())
+		}
+
+		if withState {
+			params.Set("state", state)
+		}
+
+		http.Redirect(w, r, fmt.Sprintf("http://127.0.0.1:%s/callback?%s", port, params.Encode()), http.StatusFound)
+	})
+
+	mux.HandleFunc("POST /poll", func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<10)
+
+		verifier := r.PostFormValue("verifier")
+		if !brokerStatePattern.MatchString(verifier) {
+			http.Error(w, "Bad Request: invalid or missing verifier parameter", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+
+		tok, ok := polls.take(pollChallenge(verifier))
+		if !ok {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(TokenResponse{AccessToken: tok.AccessToken, UserID: tok.UserID})
+	})
+
+	mux.HandleFunc("GET /robots.txt", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = fmt.Fprint(w, "User-agent: *\nDisallow: /\n")
+	})
+
+	return mux
+}
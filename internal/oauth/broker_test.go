@@ -0,0 +1,269 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// tiendaNubeBrowser plays the browser and the Tienda Nube authorize page:
+// it follows the redirects from the authorization URL, and approves at
+// Tienda Nube by going to the app's redirect URL (appRedirect, unless the
+// URL names one) with a code.
+func tiendaNubeBrowser(t *testing.T, appRedirect string) func(string) error {
+	t.Helper()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	return func(u string) error {
+		go func() {
+			for range 5 {
+				parsed, err := url.Parse(u)
+				if err != nil {
+					t.Errorf("browser: %v", err)
+					return
+				}
+
+				if parsed.Host == "www.tiendanube.com" {
+					redirect := parsed.Query().Get("redirect_uri")
+					if redirect == "" {
+						redirect = appRedirect
+					}
+
+					u = redirect + "?" + url.Values{"code": {"the-code"}, "state": {parsed.Query().Get("state")}}.Encode()
+
+					continue
+				}
+
+				req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, u, nil)
+				if err != nil {
+					t.Errorf("browser: %v", err)
+					return
+				}
+
+				resp, err := client.Do(req)
+				if err != nil {
+					t.Errorf("browser: %v", err)
+					return
+				}
+
+				resp.Body.Close()
+
+				if u = resp.Header.Get("Location"); u == "" {
+					return
+				}
+			}
+		}()
+
+		return nil
+	}
+}
+
+// mockQR replaces showing the QR code with fn, and polls every few
+// milliseconds.
+func mockQR(t *testing.T, fn func(string) error) {
+	t.Helper()
+
+	origShow, origInterval := showQRFn, pollInterval
+	showQRFn, pollInterval = fn, 10*time.Millisecond
+
+	t.Cleanup(func() { showQRFn, pollInterval = origShow, origInterval })
+}
+
+func TestAuthorize_NativeAndSelfHostedBroker(t *testing.T) {
+	// Cannot run in parallel — uses fixed port 8910
+	mockTokenServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Form.Get("code") != "the-code" || r.Form.Get("client_secret") != testCreds.clientSecret {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(TokenResponse{AccessToken: "tok", UserID: "42"})
+	})
+
+	broker := httptest.NewServer(NewBroker(testCreds.clientID, testCreds.clientSecret))
+	t.Cleanup(broker.Close)
+
+	for _, tt := range []struct {
+		name      string
+		brokerURL string
+	}{
+		{"native", ""},
+		{"self-hosted broker", broker.URL},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReadOAuthClientOK(t)
+			mockBrowser(t, tiendaNubeBrowser(t, broker.URL+"/callback"))
+
+			tok, err := Authorize(context.Background(), AuthorizeOptions{Timeout: 5 * time.Second, BrokerURL: tt.brokerURL})
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+
+			if tok.AccessToken != "tok" || tok.UserID.String() != "42" {
+				t.Errorf("token = %+v, want tok for user 42", tok)
+			}
+		})
+	}
+}
+
+func TestAuthorize_QRPolling(t *testing.T) {
+	mockTokenServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Form.Get("code") != "the-code" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(TokenResponse{AccessToken: "tok", UserID: "42"})
+	})
+
+	broker := httptest.NewServer(NewBroker(testCreds.clientID, testCreds.clientSecret))
+	t.Cleanup(broker.Close)
+
+	// Saved credentials would pick the native flow; --qr uses the broker.
+	mockReadOAuthClientOK(t)
+	mockBrowser(t, func(string) error {
+		t.Error("the browser should not open")
+		return nil
+	})
+
+	var authURL string
+
+	phone := tiendaNubeBrowser(t, broker.URL+"/callback")
+	mockQR(t, func(u string) error {
+		authURL = u
+		return phone(u)
+	})
+
+	tok, err := Authorize(context.Background(), AuthorizeOptions{Timeout: 5 * time.Second, BrokerURL: broker.URL, QR: true})
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if tok.AccessToken != "tok" || tok.UserID.String() != "42" {
+		t.Errorf("token = %+v, want tok for user 42", tok)
+	}
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The state in the code doesn't get anyone the token.
+	_, ok, err := pollBroker(context.Background(), broker.URL+"/poll", parsed.Query().Get("state"))
+	if err != nil || ok {
+		t.Errorf("poll with the state: ok = %v, err = %v; want pending", ok, err)
+	}
+}
+
+func TestBroker_PollNeedsVerifier(t *testing.T) {
+	mockTokenServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(TokenResponse{AccessToken: "tok", UserID: "42"})
+	})
+
+	broker := httptest.NewServer(NewBroker(testCreds.clientID, testCreds.clientSecret))
+	t.Cleanup(broker.Close)
+
+	ctx := context.Background()
+	verifier := "0123456789abcdef0123456789abcdef"
+	state := pollChallenge(verifier)
+
+	phone := tiendaNubeBrowser(t, broker.URL+"/callback")
+	if err := phone(broker.URL + "/start?poll=1&state=" + state); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		if _, ok, err := pollBroker(ctx, broker.URL+"/poll", state); err != nil || ok {
+			t.Fatalf("poll with the state: ok = %v, err = %v; want pending", ok, err)
+		}
+
+		tok, ok, err := pollBroker(ctx, broker.URL+"/poll", verifier)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ok {
+			if tok.AccessToken != "tok" {
+				t.Errorf("token = %+v", tok)
+			}
+
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("the token never came")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The token is handed out once.
+	if _, ok, err := pollBroker(ctx, broker.URL+"/poll", verifier); err != nil || ok {
+		t.Errorf("second poll: ok = %v, err = %v; want pending", ok, err)
+	}
+
+	if _, _, err := pollBroker(ctx, broker.URL+"/poll", "short"); !errors.Is(err, errAuthorization) {
+		t.Errorf("bad verifier: err = %v, want a 400", err)
+	}
+}
+
+func TestAuthorize_QRPollingUnsupported(t *testing.T) {
+	old := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(old.Close)
+
+	mockQR(t, func(string) error { return nil })
+
+	_, err := Authorize(context.Background(), AuthorizeOptions{Timeout: 5 * time.Second, BrokerURL: old.URL, QR: true})
+	if !errors.Is(err, errPollUnsupported) {
+		t.Errorf("error = %v, want errPollUnsupported", err)
+	}
+}
+
+func TestBroker_BadRequests(t *testing.T) {
+	broker := httptest.NewServer(NewBroker(testCreds.clientID, testCreds.clientSecret))
+	t.Cleanup(broker.Close)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/start", http.StatusBadRequest},
+		{"/start?port=80", http.StatusBadRequest},
+		{"/callback?state=8910", http.StatusBadRequest},
+		{"/callback?code=x&state=evil.example", http.StatusBadRequest},
+		{"/start?port=8910&state=short", http.StatusBadRequest},
+		{"/callback?code=x&state=8910.not+a+state", http.StatusBadRequest},
+		{"/start?poll=1", http.StatusBadRequest},
+		{"/callback?code=x&state=poll", http.StatusBadRequest},
+		{"/callback?code=x&state=poll.short", http.StatusBadRequest},
+		{"/poll?state=0123456789abcdef0123", http.StatusMethodNotAllowed},
+		{"/robots.txt", http.StatusOK},
+		{"/other", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, broker.URL+tt.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.want {
+			t.Errorf("GET %s = %d, want %d", tt.path, resp.StatusCode, tt.want)
+		}
+	}
+}
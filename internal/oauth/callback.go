@@ -0,0 +1,80 @@
+package oauth
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gberlati/nube-cli/internal/i18n"
+)
+
+//go:embed callback.html
+var callbackHTML string
+
+var defaultCallbackTemplate = template.Must(template.New("callback").Parse(callbackHTML))
+
+// callbackPage is what a callback template is executed with: whether the
+// login worked, a localized title and message, and the language they are
+// in (en, es or pt).
+type callbackPage struct {
+	Success bool
+	Title   string
+	Message string
+	Lang    string
+}
+
+// loadCallbackTemplate parses the HTML template at path, or returns the
+// built-in page when path is empty. The template is tried once here, so a
+// mistake shows in the terminal rather than in the browser.
+func loadCallbackTemplate(path string) (*template.Template, error) {
+	if path == "" {
+		return defaultCallbackTemplate, nil
+	}
+
+	b, err := os.ReadFile(path) //nolint:gosec // user-provided path
+	if err != nil {
+		return nil, fmt.Errorf("read callback template: %w", err)
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Parse(string(b))
+	if err != nil {
+		return nil, fmt.Errorf("parse callback template: %w", err)
+	}
+
+	if err := tmpl.Execute(io.Discard, callbackPage{Success: true, Title: "Title", Message: "Message", Lang: "en"}); err != nil {
+		return nil, fmt.Errorf("callback template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// writeCallbackPage answers the browser's callback with tmpl in the language
+// of tr, falling back on plain text if it fails.
+func writeCallbackPage(w http.ResponseWriter, tr *i18n.Translator, tmpl *template.Template, status int, success bool, message string) {
+	page := callbackPage{
+		Success: success,
+		Title:   tr.T("Authorization failed"),
+		Message: message,
+		Lang:    tr.Lang(),
+	}
+	if success {
+		page.Title = tr.T("Authorization successful")
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, page); err != nil {
+		w.WriteHeader(status)
+		_, _ = fmt.Fprintf(w, "%s. %s", page.Title, page.Message)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = buf.WriteTo(w)
+}
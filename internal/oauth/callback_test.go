@@ -0,0 +1,81 @@
+package oauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteCallbackPage_Default(t *testing.T) {
+	tmpl, err := loadCallbackTemplate("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	writeCallbackPage(rec, nil, tmpl, http.StatusOK, true, "You can close <this> window.")
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("Content-Type = %q, want text/html", ct)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{"Authorization successful", "You can close &lt;this&gt; window.", "window.close()"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page missing %q:\n%s", want, body)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	writeCallbackPage(rec, nil, tmpl, http.StatusBadRequest, false, "Try again.")
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+
+	if body := rec.Body.String(); !strings.Contains(body, "Authorization failed") || strings.Contains(body, "window.close()") {
+		t.Errorf("failure page should not close itself:\n%s", body)
+	}
+}
+
+func TestLoadCallbackTemplate_Custom(t *testing.T) {
+	dir := t.TempDir()
+
+	good := filepath.Join(dir, "good.html")
+	if err := os.WriteFile(good, []byte(`<p class="acme">{{.Lang}} {{if .Success}}ok{{end}}: {{.Message}}</p>`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := loadCallbackTemplate(good)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	writeCallbackPage(rec, nil, tmpl, http.StatusOK, true, "done")
+
+	if got, want := rec.Body.String(), `<p class="acme">en ok: done</p>`; got != want {
+		t.Errorf("page = %q, want %q", got, want)
+	}
+
+	for name, content := range map[string]string{
+		"unparsable.html": `{{if .Success}}`,
+		"bad-field.html":  `{{.Store}}`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := loadCallbackTemplate(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if _, err := loadCallbackTemplate(filepath.Join(dir, "missing.html")); err == nil {
+		t.Error("missing file: expected an error")
+	}
+}
@@ -12,10 +12,12 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/gberlati/nube-cli/internal/credstore"
+	"github.com/gberlati/nube-cli/internal/i18n"
 )
 
 const (
@@ -35,6 +37,17 @@ type AuthorizeOptions struct {
 	Timeout   time.Duration
 	OAuthApp  string
 	BrokerURL string
+	// CallbackTemplate is an HTML template file for the page the browser
+	// shows when it comes back to the CLI; see callbackPage for its data.
+	// Empty means the built-in page.
+	CallbackTemplate string
+	// AllowedBrokers, when set, lists the broker hosts (host or host:port)
+	// the broker flow may use; see CheckBrokerURL.
+	AllowedBrokers []string
+	// QR shows the authorization URL as a QR code and polls the broker for
+	// the token instead of waiting for a local callback, so the login can
+	// be approved from a phone. It always uses a broker.
+	QR bool
 }
 
 // TokenResponse holds the response from the Tienda Nube token endpoint.
@@ -67,6 +80,9 @@ var (
 	errTokenExchange = errors.New("token exchange failed")
 	errNoAccessToken = errors.New("no access token in response")
 
+	// ErrBrokerURL marks a broker URL that CheckBrokerURL rejects.
+	ErrBrokerURL = errors.New("invalid broker URL")
+
 	readOAuthClient = defaultReadOAuthClient
 	openBrowserFn   = openBrowser
 )
@@ -89,10 +105,20 @@ func Authorize(ctx context.Context, opts AuthorizeOptions) (TokenResponse, error
 	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
 	defer cancel()
 
-	brokerURL := opts.BrokerURL
-	if brokerURL != "" {
+	if opts.QR {
+		// The phone can't reach a callback on this machine, so the token
+		// comes back through the broker, whatever credentials are saved.
+		brokerURL := opts.BrokerURL
+		if brokerURL == "" {
+			brokerURL = DefaultBrokerURL
+		}
+
+		return authorizePoll(ctx, opts, brokerURL)
+	}
+
+	if opts.BrokerURL != "" {
 		// Broker flow — no local credentials needed.
-		return authorizeServer(ctx, opts, clientCredentials{}, brokerURL)
+		return authorizeBroker(ctx, opts, opts.BrokerURL)
 	}
 
 	// Try native flow — requires local credentials.
@@ -107,7 +133,7 @@ func Authorize(ctx context.Context, opts AuthorizeOptions) (TokenResponse, error
 		if DefaultBrokerURL != "" {
 			var credErr *credstore.OAuthClientMissingError
 			if errors.As(err, &credErr) {
-				return authorizeServer(ctx, opts, clientCredentials{}, DefaultBrokerURL)
+				return authorizeBroker(ctx, opts, DefaultBrokerURL)
 			}
 		}
 
@@ -117,22 +143,74 @@ func Authorize(ctx context.Context, opts AuthorizeOptions) (TokenResponse, error
 	return authorizeServer(ctx, opts, creds, "")
 }
 
+// authorizeBroker runs the broker flow through brokerURL once
+// CheckBrokerURL accepts it.
+func authorizeBroker(ctx context.Context, opts AuthorizeOptions, brokerURL string) (TokenResponse, error) {
+	brokerURL, err := CheckBrokerURL(brokerURL, opts.AllowedBrokers)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	return authorizeServer(ctx, opts, clientCredentials{}, brokerURL)
+}
+
+// CheckBrokerURL returns the broker URL raw names, with https:// added to a
+// bare host. The broker receives the access token, so it must use https,
+// unless it runs on this machine, and when allowed is non-empty its host
+// must be listed there.
+func CheckBrokerURL(raw string, allowed []string) (string, error) {
+	s := strings.TrimRight(strings.TrimSpace(raw), "/")
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" || u.User != nil || u.RawQuery != "" || u.Fragment != "" ||
+		(u.Scheme != "https" && u.Scheme != "http") {
+		return "", fmt.Errorf("%w: %q is not an http(s) URL", ErrBrokerURL, raw)
+	}
+
+	if u.Scheme == "http" && !isLoopback(u.Hostname()) {
+		return "", fmt.Errorf("%w: %s must use https (plain http is only for localhost)", ErrBrokerURL, u.Host)
+	}
+
+	if len(allowed) > 0 && !slices.ContainsFunc(allowed, func(a string) bool {
+		return strings.EqualFold(a, u.Host) || strings.EqualFold(a, u.Hostname())
+	}) {
+		return "", fmt.Errorf("%w: %s is not in the config broker_allowlist", ErrBrokerURL, u.Host)
+	}
+
+	return s, nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
 func authURL(clientID string) string {
 	return fmt.Sprintf("%s/%s/authorize", AuthBaseURL, clientID)
 }
 
-func authorizeServer(ctx context.Context, _ AuthorizeOptions, creds clientCredentials, brokerURL string) (TokenResponse, error) {
+func authorizeServer(ctx context.Context, opts AuthorizeOptions, creds clientCredentials, brokerURL string) (TokenResponse, error) {
 	isBroker := brokerURL != ""
 
-	var state string
-
-	if !isBroker {
-		var err error
+	page, err := loadCallbackTemplate(opts.CallbackTemplate)
+	if err != nil {
+		return TokenResponse{}, err
+	}
 
-		state, err = randomState()
-		if err != nil {
-			return TokenResponse{}, err
-		}
+	// The state goes to Tienda Nube, or through the broker, and must come
+	// back with the code or token, so a callback the CLI didn't start
+	// can't log it into someone else's store.
+	state, err := randomState()
+	if err != nil {
+		return TokenResponse{}, err
 	}
 
 	ln, err := (&net.ListenConfig{}).Listen(ctx, "tcp", fmt.Sprintf("127.0.0.1:%d", CallbackPort))
@@ -145,6 +223,8 @@ func authorizeServer(ctx context.Context, _ AuthorizeOptions, creds clientCreden
 	resultCh := make(chan authResult, 1)
 	errCh := make(chan error, 1)
 
+	tr := i18n.FromContext(ctx)
+
 	srv := &http.Server{
 		ReadHeaderTimeout: 5 * time.Second,
 		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -161,21 +241,30 @@ func authorizeServer(ctx context.Context, _ AuthorizeOptions, creds clientCreden
 				default:
 				}
 
-				w.WriteHeader(http.StatusOK)
-				_, _ = fmt.Fprint(w, "Authorization failed. You can close this window.")
+				writeCallbackPage(w, tr, page, http.StatusOK, false, tr.Sprintf("Tienda Nube says: %s. Go back to the terminal to try again.", q.Get("error")))
 
 				return
 			}
 
 			// Broker flow: token + user_id come directly.
 			if tok := q.Get("token"); tok != "" {
+				if q.Get("state") != state {
+					select {
+					case errCh <- errStateMismatch:
+					default:
+					}
+
+					writeCallbackPage(w, tr, page, http.StatusBadRequest, false, tr.T("This page doesn't belong to the login in progress. Go back to the terminal to try again."))
+
+					return
+				}
+
 				select {
 				case resultCh <- authResult{token: tok, userID: q.Get("user_id")}:
 				default:
 				}
 
-				w.WriteHeader(http.StatusOK)
-				_, _ = fmt.Fprint(w, "Authorization successful! You can close this window.")
+				writeCallbackPage(w, tr, page, http.StatusOK, true, tr.T("nube is logged in to your store. You can close this window."))
 
 				return
 			}
@@ -188,8 +277,7 @@ func authorizeServer(ctx context.Context, _ AuthorizeOptions, creds clientCreden
 					default:
 					}
 
-					w.WriteHeader(http.StatusBadRequest)
-					_, _ = fmt.Fprint(w, "State mismatch. Please try again.")
+					writeCallbackPage(w, tr, page, http.StatusBadRequest, false, tr.T("This page doesn't belong to the login in progress. Go back to the terminal to try again."))
 
 					return
 				}
@@ -201,8 +289,7 @@ func authorizeServer(ctx context.Context, _ AuthorizeOptions, creds clientCreden
 					default:
 					}
 
-					w.WriteHeader(http.StatusBadRequest)
-					_, _ = fmt.Fprint(w, "Missing authorization code.")
+					writeCallbackPage(w, tr, page, http.StatusBadRequest, false, tr.T("Tienda Nube sent no authorization code. Go back to the terminal to try again."))
 
 					return
 				}
@@ -212,8 +299,7 @@ func authorizeServer(ctx context.Context, _ AuthorizeOptions, creds clientCreden
 				default:
 				}
 
-				w.WriteHeader(http.StatusOK)
-				_, _ = fmt.Fprint(w, "Authorization successful! You can close this window.")
+				writeCallbackPage(w, tr, page, http.StatusOK, true, tr.T("nube is logged in to your store. You can close this window."))
 
 				return
 			}
@@ -224,8 +310,7 @@ func authorizeServer(ctx context.Context, _ AuthorizeOptions, creds clientCreden
 			default:
 			}
 
-			w.WriteHeader(http.StatusBadRequest)
-			_, _ = fmt.Fprint(w, "Missing token in callback.")
+			writeCallbackPage(w, tr, page, http.StatusBadRequest, false, tr.T("The broker sent no access token. Go back to the terminal to try again."))
 		}),
 	}
 
@@ -246,7 +331,7 @@ func authorizeServer(ctx context.Context, _ AuthorizeOptions, creds clientCreden
 	// Build the authorization URL.
 	var fullAuthURL string
 	if isBroker {
-		fullAuthURL = fmt.Sprintf("%s/start?port=%d", brokerURL, CallbackPort)
+		fullAuthURL = fmt.Sprintf("%s/start?port=%d&state=%s", brokerURL, CallbackPort, url.QueryEscape(state))
 	} else {
 		redirectURI := fmt.Sprintf("http://127.0.0.1:%d/callback", CallbackPort)
 		fullAuthURL = fmt.Sprintf("%s?redirect_uri=%s&state=%s",
@@ -255,6 +340,13 @@ func authorizeServer(ctx context.Context, _ AuthorizeOptions, creds clientCreden
 			url.QueryEscape(state))
 	}
 
+	if isBroker {
+		// The broker sees the token, so name it before the browser opens.
+		if u, err := url.Parse(brokerURL); err == nil {
+			fmt.Fprintf(os.Stderr, "Logging in through the broker at %s\n", u.Host)
+		}
+	}
+
 	fmt.Fprintln(os.Stderr, "Opening browser for authorization...")
 	fmt.Fprintln(os.Stderr, "If the browser doesn't open, visit this URL:")
 	fmt.Fprintln(os.Stderr, fullAuthURL)
@@ -0,0 +1,188 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/qr"
+)
+
+// pollTTL is how long a broker keeps a token for the CLI to pick up.
+const pollTTL = 10 * time.Minute
+
+var (
+	errPollUnsupported = errors.New("the broker doesn't support QR logins (update it to one with GET /poll)")
+
+	pollInterval = 2 * time.Second
+	showQRFn     = showQR
+)
+
+// authorizePoll runs the broker flow without a local callback server, for
+// logins approved on another device: the authorization URL is shown as a
+// QR code, the broker keeps the token under the URL's state once the user
+// approves, and the CLI polls the broker for it. Anyone who sees the code
+// knows the state, so it is only the hash of a verifier the CLI keeps
+// (pollChallenge), and the broker hands out the token for the verifier.
+func authorizePoll(ctx context.Context, opts AuthorizeOptions, brokerURL string) (TokenResponse, error) {
+	brokerURL, err := CheckBrokerURL(brokerURL, opts.AllowedBrokers)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	verifier, err := randomState()
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	state := pollChallenge(verifier)
+
+	if u, err := url.Parse(brokerURL); err == nil {
+		fmt.Fprintf(os.Stderr, "Logging in through the broker at %s\n", u.Host)
+	}
+
+	if err := showQRFn(fmt.Sprintf("%s/start?poll=1&state=%s", brokerURL, url.QueryEscape(state))); err != nil {
+		return TokenResponse{}, err
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return TokenResponse{}, fmt.Errorf("authorization timed out: %w", ctx.Err())
+		case <-ticker.C:
+		}
+
+		tok, ok, err := pollBroker(ctx, brokerURL+"/poll", verifier)
+		if err != nil {
+			return TokenResponse{}, err
+		}
+
+		if ok {
+			return tok, nil
+		}
+	}
+}
+
+// pollChallenge returns the state of a QR login with verifier: its
+// unpadded base64url SHA-256, as for PKCE (RFC 7636).
+func pollChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// pollBroker asks the broker once for the token of verifier. ok is false
+// while the user hasn't approved yet, or the broker couldn't be reached.
+func pollBroker(ctx context.Context, pollURL, verifier string) (TokenResponse, bool, error) {
+	form := url.Values{"verifier": {verifier}}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pollURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return TokenResponse{}, false, fmt.Errorf("create poll request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req) //nolint:gosec // broker URL checked by CheckBrokerURL
+	if err != nil {
+		// A flaky network shouldn't end the login; the timeout does.
+		return TokenResponse{}, false, nil //nolint:nilerr // retried on the next tick
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var tok TokenResponse
+		if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+			return TokenResponse{}, false, fmt.Errorf("decode poll response: %w", err)
+		}
+
+		if tok.AccessToken == "" {
+			return TokenResponse{}, false, errNoAccessToken
+		}
+
+		return tok, true, nil
+	case http.StatusNoContent:
+		return TokenResponse{}, false, nil
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return TokenResponse{}, false, errPollUnsupported
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		return TokenResponse{}, false, fmt.Errorf("%w: poll returned HTTP %d: %s", errAuthorization, resp.StatusCode, string(body))
+	}
+}
+
+// showQR prints the authorization URL as a QR code for a phone to scan,
+// and as text for any other device. A URL too long for a code is only
+// printed.
+func showQR(u string) error {
+	fmt.Fprintln(os.Stderr, "Scan this code with your phone to authorize, or open the URL below on any device:")
+
+	if code, err := qr.Encode(u); err == nil {
+		fmt.Fprint(os.Stderr, code.ANSI())
+	}
+
+	fmt.Fprintln(os.Stderr, u)
+	fmt.Fprintln(os.Stderr, "Waiting for authorization...")
+
+	return nil
+}
+
+// pollStore holds tokens a broker has exchanged, under the login's state,
+// until the CLI that started it polls for them, each for up to pollTTL and
+// only once.
+type pollStore struct {
+	mu     sync.Mutex
+	tokens map[string]pollEntry
+}
+
+type pollEntry struct {
+	tok     TokenResponse
+	expires time.Time
+}
+
+func newPollStore() *pollStore {
+	return &pollStore{tokens: map[string]pollEntry{}}
+}
+
+func (s *pollStore) put(state string, tok TokenResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, e := range s.tokens {
+		if now.After(e.expires) {
+			delete(s.tokens, k)
+		}
+	}
+
+	s.tokens[state] = pollEntry{tok: tok, expires: now.Add(pollTTL)}
+}
+
+func (s *pollStore) take(state string) (TokenResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.tokens[state]
+	delete(s.tokens, state)
+
+	if !ok || time.Now().After(e.expires) {
+		return TokenResponse{}, false
+	}
+
+	return e.tok, true
+}
@@ -0,0 +1,229 @@
+// Package openapi validates store API requests against the OpenAPI
+// description of the Tienda Nube REST API embedded in the binary, so bad
+// paths, methods, and bodies fail locally with a clear message instead of an
+// opaque 400 from the API.
+//
+// Only the parts of OpenAPI 3 the description uses are supported: paths with
+// {param} segments, operations, JSON request bodies, and schemas built from
+// type, nullable, enum, required, properties, additionalProperties, items,
+// allOf, and local $refs. Unknown body fields are allowed, as the API
+// ignores them.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+//go:embed tiendanube.json
+var embedded []byte
+
+// Spec is a parsed OpenAPI description.
+type Spec struct {
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+}
+
+// Info identifies the description.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Operation is one method on a path.
+type Operation struct {
+	Summary     string       `json:"summary"`
+	RequestBody *RequestBody `json:"requestBody"`
+}
+
+// RequestBody describes an operation's JSON body.
+type RequestBody struct {
+	Required bool `json:"required"`
+	Content  map[string]struct {
+		Schema *Schema `json:"schema"`
+	} `json:"content"`
+}
+
+// Schema is the subset of JSON Schema used by the description.
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Nullable             bool               `json:"nullable"`
+	Enum                 []any              `json:"enum"`
+	Required             []string           `json:"required"`
+	Properties           map[string]*Schema `json:"properties"`
+	AdditionalProperties *Schema            `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	AllOf                []*Schema          `json:"allOf"`
+}
+
+// Parse decodes an OpenAPI description in JSON.
+func Parse(b []byte) (*Spec, error) {
+	var s Spec
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("parse openapi description: %w", err)
+	}
+
+	for path, ops := range s.Paths {
+		upper := make(map[string]Operation, len(ops))
+		for method, op := range ops {
+			upper[strings.ToUpper(method)] = op
+		}
+
+		s.Paths[path] = upper
+	}
+
+	return &s, nil
+}
+
+// Default returns the embedded description of the store API.
+var Default = sync.OnceValues(func() (*Spec, error) {
+	return Parse(embedded)
+})
+
+// Error lists why a request doesn't match the description.
+type Error struct {
+	Method   string
+	Path     string
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Method, e.Path, strings.Join(e.Problems, "; "))
+}
+
+// Validate checks a request before it is sent. path is relative to the store
+// (e.g. "products/123"); a query string is ignored. body may be nil.
+func (s *Spec) Validate(method, path string, body []byte) error {
+	method = strings.ToUpper(method)
+	path, _, _ = strings.Cut(path, "?")
+	path = strings.Trim(path, "/")
+
+	fail := func(problems ...string) error {
+		return &Error{Method: method, Path: path, Problems: problems}
+	}
+
+	tmpl, ok := s.match(path)
+	if !ok {
+		return fail("unknown API path")
+	}
+
+	op, ok := s.Paths[tmpl][method]
+	if !ok {
+		return fail(fmt.Sprintf("method not allowed on %s (allowed: %s)", tmpl, strings.Join(s.methods(tmpl), ", ")))
+	}
+
+	schema := op.bodySchema()
+
+	if len(body) == 0 {
+		if op.RequestBody != nil && op.RequestBody.Required {
+			return fail("a JSON body is required")
+		}
+
+		return nil
+	}
+
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return fail("body is not valid JSON: " + err.Error())
+	}
+
+	if schema == nil {
+		return nil
+	}
+
+	var problems []string
+	s.check(schema, v, "body", &problems)
+
+	if len(problems) > 0 {
+		return fail(problems...)
+	}
+
+	return nil
+}
+
+// Methods returns the methods the description lists for path (relative to
+// the store, as in Validate), sorted, or nil for an unknown path.
+func (s *Spec) Methods(path string) []string {
+	path, _, _ = strings.Cut(path, "?")
+
+	tmpl, ok := s.match(strings.Trim(path, "/"))
+	if !ok {
+		return nil
+	}
+
+	return s.methods(tmpl)
+}
+
+func (s *Spec) methods(tmpl string) []string {
+	out := make([]string, 0, len(s.Paths[tmpl]))
+	for m := range s.Paths[tmpl] {
+		out = append(out, m)
+	}
+
+	slices.Sort(out)
+
+	return out
+}
+
+func (op Operation) bodySchema() *Schema {
+	if op.RequestBody == nil {
+		return nil
+	}
+
+	if c, ok := op.RequestBody.Content["application/json"]; ok {
+		return c.Schema
+	}
+
+	return nil
+}
+
+// match returns the path template matching path. Literal segments win over
+// parameters, so products/sku/{sku} beats products/{id}/{sub}.
+func (s *Spec) match(path string) (string, bool) {
+	segs := strings.Split(path, "/")
+
+	var (
+		best      string
+		bestScore = -1
+	)
+
+	for tmpl := range s.Paths {
+		tsegs := strings.Split(strings.Trim(tmpl, "/"), "/")
+		if len(tsegs) != len(segs) {
+			continue
+		}
+
+		score, ok := 0, true
+
+		for i, t := range tsegs {
+			switch {
+			case strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}"):
+				if segs[i] == "" {
+					ok = false
+				}
+			case t == segs[i]:
+				score++
+			default:
+				ok = false
+			}
+
+			if !ok {
+				break
+			}
+		}
+
+		if ok && (score > bestScore || score == bestScore && tmpl < best) {
+			best, bestScore = tmpl, score
+		}
+	}
+
+	return best, bestScore >= 0
+}
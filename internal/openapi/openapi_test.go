@@ -0,0 +1,140 @@
+package openapi
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDefault_Parses(t *testing.T) {
+	t.Parallel()
+
+	s, err := Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Paths) == 0 || s.Info.Version == "" {
+		t.Fatalf("spec = %+v", s.Info)
+	}
+
+	// Every $ref must resolve.
+	var walk func(*Schema)
+	walk = func(sc *Schema) {
+		if sc == nil {
+			return
+		}
+
+		if sc.Ref != "" && s.resolve(sc) == nil {
+			t.Errorf("unresolved $ref %q", sc.Ref)
+		}
+
+		for _, p := range sc.Properties {
+			walk(p)
+		}
+
+		for _, a := range sc.AllOf {
+			walk(a)
+		}
+
+		walk(sc.Items)
+		walk(sc.AdditionalProperties)
+	}
+
+	for _, ops := range s.Paths {
+		for _, op := range ops {
+			walk(op.bodySchema())
+		}
+	}
+
+	for _, sc := range s.Components.Schemas {
+		walk(sc)
+	}
+}
+
+func TestMethods(t *testing.T) {
+	t.Parallel()
+
+	s, err := Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := s.Methods("customers/1/addresses/2"); strings.Join(got, ",") != "DELETE,PUT" {
+		t.Errorf("addresses: Methods() = %v, want DELETE, PUT", got)
+	}
+
+	if got := s.Methods("/products/1?fields=id"); !slices.Contains(got, "GET") {
+		t.Errorf("products: Methods() = %v, want GET among them", got)
+	}
+
+	if got := s.Methods("prodcts"); got != nil {
+		t.Errorf("unknown path: Methods() = %v, want nil", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	s, err := Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, method, path, body string
+		want                     []string // substrings of the error; nil = valid
+	}{
+		{"list", "GET", "products", "", nil},
+		{"query and slashes", "get", "/products/123/?fields=id", "", nil},
+		{"sku beats param", "GET", "products/sku/ABC-1", "", nil},
+		{"nested", "PUT", "products/1/variants/2", `{"stock":null,"price":"10.00"}`, nil},
+		{"create", "POST", "products", `{"name":{"es":"Remera"},"variants":[{"price":"10.00","stock":5}]}`, nil},
+		{"unknown fields pass", "POST", "categories", `{"name":{"es":"Ofertas"},"color":"red"}`, nil},
+		{"unknown path", "GET", "prodcts", "", []string{"unknown API path"}},
+		{"method", "DELETE", "orders/1", "", []string{"method not allowed", "GET, PUT"}},
+		{"required body", "POST", "products", "", []string{"a JSON body is required"}},
+		{"bad json", "POST", "products", `{"name":`, []string{"not valid JSON"}},
+		{"missing required", "POST", "webhooks", `{"url":"https://x"}`, []string{`missing required field "event"`}},
+		{"enum", "POST", "webhooks", `{"event":"order/create","url":"https://x"}`, []string{"body.event: order/create is not one of"}},
+		{"types", "POST", "products", `{"name":"Remera","variants":[{"stock":"5"}],"published":1}`, []string{
+			"body.name: want object, got string",
+			"body.published: want boolean, got number",
+			"body.variants[0].stock: want integer, got string",
+		}},
+		{"integer", "PUT", "categories/1", `{"parent":1.5}`, []string{"body.parent: want integer, got number"}},
+		{"null", "PUT", "products/1", `{"published":null}`, []string{"body.published: must not be null"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var body []byte
+			if tt.body != "" {
+				body = []byte(tt.body)
+			}
+
+			err := s.Validate(tt.method, tt.path, body)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+
+				return
+			}
+
+			var vErr *Error
+			if !errors.As(err, &vErr) {
+				t.Fatalf("Validate() = %v, want *Error", err)
+			}
+
+			for _, w := range tt.want {
+				if !strings.Contains(err.Error(), w) {
+					t.Errorf("error %q lacks %q", err, w)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// check validates v (decoded JSON) against schema, appending a problem per
+// mismatch, located by a path like body.variants[0].price.
+func (s *Spec) check(schema *Schema, v any, at string, problems *[]string) {
+	schema = s.resolve(schema)
+	if schema == nil {
+		return
+	}
+
+	for _, sub := range schema.AllOf {
+		s.check(sub, v, at, problems)
+	}
+
+	if v == nil {
+		if schema.Type != "" && !schema.Nullable {
+			*problems = append(*problems, fmt.Sprintf("%s: must not be null", at))
+		}
+
+		return
+	}
+
+	if schema.Type != "" && !hasType(v, schema.Type) {
+		*problems = append(*problems, fmt.Sprintf("%s: want %s, got %s", at, schema.Type, typeOf(v)))
+
+		return
+	}
+
+	if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, v) {
+		*problems = append(*problems, fmt.Sprintf("%s: %v is not one of %s", at, v, enumList(schema.Enum)))
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		for _, k := range schema.Required {
+			if _, ok := v[k]; !ok {
+				*problems = append(*problems, fmt.Sprintf("%s: missing required field %q", at, k))
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if prop, ok := schema.Properties[k]; ok {
+				s.check(prop, v[k], at+"."+k, problems)
+			} else if schema.AdditionalProperties != nil {
+				s.check(schema.AdditionalProperties, v[k], at+"."+k, problems)
+			}
+		}
+	case []any:
+		if schema.Items != nil {
+			for i, item := range v {
+				s.check(schema.Items, item, fmt.Sprintf("%s[%d]", at, i), problems)
+			}
+		}
+	}
+}
+
+// resolve follows local $refs ("#/components/schemas/Name").
+func (s *Spec) resolve(schema *Schema) *Schema {
+	for depth := 0; schema != nil && schema.Ref != ""; depth++ {
+		name, ok := strings.CutPrefix(schema.Ref, "#/components/schemas/")
+		if !ok || depth > 32 {
+			return nil
+		}
+
+		schema = s.Components.Schemas[name]
+	}
+
+	return schema
+}
+
+func hasType(v any, want string) bool {
+	switch want {
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	default:
+		return typeOf(v) == want
+	}
+}
+
+func typeOf(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
+
+func enumList(values []any) string {
+	parts := make([]string, len(values))
+	for i, e := range values {
+		parts[i] = fmt.Sprint(e)
+	}
+
+	return strings.Join(parts, ", ")
+}
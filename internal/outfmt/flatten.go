@@ -0,0 +1,168 @@
+package outfmt
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Flatten formats for JSONTransform.Flatten.
+const (
+	FlattenTSV = "tsv"
+	FlattenCSV = "csv"
+)
+
+// Field is one leaf of a flattened JSON value.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// FlattenValue returns the leaves of v keyed by their dotted path, with map
+// keys sorted and array elements numbered ("variants.0.sku"). Empty objects
+// and arrays are kept as "{}" and "[]" so their keys don't disappear.
+func FlattenValue(v any) []Field {
+	var fields []Field
+
+	flattenInto(&fields, "", normalizeForSelect(v))
+
+	return fields
+}
+
+func flattenInto(fields *[]Field, prefix string, v any) {
+	join := func(k string) string {
+		if prefix == "" {
+			return k
+		}
+
+		return prefix + "." + k
+	}
+
+	switch c := v.(type) {
+	case map[string]any:
+		if len(c) == 0 {
+			*fields = append(*fields, Field{prefix, "{}"})
+
+			return
+		}
+
+		keys := make([]string, 0, len(c))
+		for k := range c {
+			keys = append(keys, k)
+		}
+
+		slices.Sort(keys)
+
+		for _, k := range keys {
+			flattenInto(fields, join(k), c[k])
+		}
+	case []any:
+		if len(c) == 0 {
+			*fields = append(*fields, Field{prefix, "[]"})
+
+			return
+		}
+
+		for i, e := range c {
+			flattenInto(fields, join(strconv.Itoa(i)), e)
+		}
+	default:
+		*fields = append(*fields, Field{prefix, scalarString(v)})
+	}
+}
+
+func scalarString(v any) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	default:
+		b, err := json.Marshal(s)
+		if err != nil {
+			return fmt.Sprint(s)
+		}
+
+		return string(b)
+	}
+}
+
+// writeFlattened writes v as TSV rows of key and value, or as CSV with one
+// row per list item and a column per dotted key.
+func writeFlattened(w io.Writer, v any, format string) error {
+	if format == FlattenCSV {
+		return writeFlatCSV(w, normalizeForSelect(v))
+	}
+
+	// Tabs and newlines inside values would break the rows.
+	escape := strings.NewReplacer("\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+	for _, f := range FlattenValue(v) {
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", f.Key, escape.Replace(f.Value)); err != nil {
+			return fmt.Errorf("write tsv: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func writeFlatCSV(w io.Writer, v any) error {
+	items, ok := v.([]any)
+	if !ok {
+		items = []any{v}
+	}
+
+	var (
+		columns []string
+		rows    []map[string]string
+	)
+
+	for _, item := range items {
+		row := map[string]string{}
+
+		for _, f := range FlattenValue(item) {
+			key := f.Key
+			if key == "" {
+				key = "value"
+			}
+
+			if !slices.Contains(columns, key) {
+				columns = append(columns, key)
+			}
+
+			row[key] = f.Value
+		}
+
+		rows = append(rows, row)
+	}
+
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(columns); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+
+	record := make([]string, len(columns))
+
+	for _, row := range rows {
+		for i, c := range columns {
+			record[i] = row[c]
+		}
+
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("write csv: %w", err)
+		}
+	}
+
+	cw.Flush()
+
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package outfmt_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/gberlati/nube-cli/internal/outfmt"
+)
+
+func TestFlattenValue(t *testing.T) {
+	t.Parallel()
+
+	got := outfmt.FlattenValue(map[string]any{
+		"id":       1,
+		"name":     map[string]any{"es": "Remera"},
+		"tags":     []any{},
+		"variants": []any{map[string]any{"sku": "A", "price": nil}},
+		"on_sale":  true,
+	})
+
+	want := []outfmt.Field{
+		{"id", "1"},
+		{"name.es", "Remera"},
+		{"on_sale", "true"},
+		{"tags", "[]"},
+		{"variants.0.price", ""},
+		{"variants.0.sku", "A"},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("field %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWriteJSON_Flatten(t *testing.T) {
+	t.Parallel()
+
+	data := []any{
+		map[string]any{"id": float64(1), "name": map[string]any{"es": "Remera\tazul"}},
+		map[string]any{"id": float64(2), "sku": "B,2"},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{outfmt.FlattenTSV, "0.id\t1\n0.name.es\tRemera\\tazul\n1.id\t2\n1.sku\tB,2\n"},
+		{outfmt.FlattenCSV, "id,name.es,sku\n1,Remera\tazul,\n2,,\"B,2\"\n"},
+	}
+
+	for _, tt := range tests {
+		ctx := outfmt.WithJSONTransform(context.Background(), outfmt.JSONTransform{Flatten: tt.format})
+
+		var buf bytes.Buffer
+		if err := outfmt.WriteJSON(ctx, &buf, data); err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+
+		if buf.String() != tt.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tt.format, buf.String(), tt.want)
+		}
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 type Mode struct {
@@ -56,8 +58,16 @@ func IsPlain(ctx context.Context) bool { return FromContext(ctx).Plain }
 // JSONTransform configures JSON output transformations.
 type JSONTransform struct {
 	// Select projects objects to only the requested fields (comma-separated; supports dot paths).
+	// A trailing ".*" selects every key under a path, e.g. all translations with "name.*";
+	// "*" elsewhere collects every match into one list ("variants.*.sku"), and a leading
+	// "!" drops a path instead ("!images").
 	// When applied to a list, it projects each element.
 	Select []string
+	// Flatten replaces JSON with dotted key/value rows (FlattenTSV) or
+	// columns (FlattenCSV), for tools that can't read nested data.
+	Flatten string
+	// YAML writes the value as YAML instead of JSON.
+	YAML bool
 }
 
 type transformCtxKey struct{}
@@ -78,14 +88,74 @@ func JSONTransformFromContext(ctx context.Context) JSONTransform {
 	return JSONTransform{}
 }
 
+// Capture holds a command's JSON result instead of writing it, so the caller
+// can wrap it (e.g. in an --envelope) once the command has finished.
+type Capture struct {
+	mu    sync.Mutex
+	data  any
+	wrote bool
+}
+
+// Data returns the captured value and whether anything was written.
+func (c *Capture) Data() (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.data, c.wrote
+}
+
+func (c *Capture) set(v any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.data = v
+	c.wrote = true
+}
+
+type captureCtxKey struct{}
+
+// WithCapture makes WriteJSON store its value in c instead of writing it.
+func WithCapture(ctx context.Context, c *Capture) context.Context {
+	return context.WithValue(ctx, captureCtxKey{}, c)
+}
+
+// CaptureFromContext returns the Capture attached to ctx, or nil.
+func CaptureFromContext(ctx context.Context) *Capture {
+	c, _ := ctx.Value(captureCtxKey{}).(*Capture)
+
+	return c
+}
+
 // WriteJSON encodes v as indented JSON. If a JSONTransform is in the context,
-// it applies field selection before encoding.
+// it applies field selection before encoding, and flattening or YAML instead
+// of it.
+// If a Capture is in the context, the (transformed) value is captured instead
+// of written.
 func WriteJSON(ctx context.Context, w io.Writer, v any) error {
 	transform := JSONTransformFromContext(ctx)
 	if len(transform.Select) > 0 {
 		v = ApplyJSONTransform(v, transform)
 	}
 
+	if c := CaptureFromContext(ctx); c != nil {
+		c.set(v)
+
+		return nil
+	}
+
+	if transform.Flatten != "" {
+		return writeFlattened(w, v, transform.Flatten)
+	}
+
+	if transform.YAML {
+		return EncodeYAML(w, v)
+	}
+
+	return EncodeJSON(w, v)
+}
+
+// EncodeJSON writes v as indented JSON without applying any context transforms.
+func EncodeJSON(w io.Writer, v any) error {
 	enc := json.NewEncoder(w)
 	enc.SetEscapeHTML(false)
 	enc.SetIndent("", "  ")
@@ -151,17 +221,162 @@ func selectFieldsFromItem(v any, fields []string) any {
 		return v
 	}
 
-	out := make(map[string]any, len(fields))
+	var include []string
 
 	for _, f := range fields {
-		if val, ok := getAtPath(m, f); ok {
-			out[f] = val
+		f = strings.TrimSpace(f)
+		if path, ok := strings.CutPrefix(f, "!"); ok {
+			m = withoutPath(m, splitPath(path)).(map[string]any) //nolint:forcetypeassert // maps stay maps
+		} else if f != "" {
+			include = append(include, f)
+		}
+	}
+
+	// Only exclusions: everything else stays, in its original shape.
+	if len(include) == 0 {
+		return m
+	}
+
+	out := make(map[string]any, len(include))
+
+	for _, f := range include {
+		prefix, trailing := strings.CutSuffix(f, ".*")
+
+		switch {
+		case trailing && !strings.Contains(prefix, "*"):
+			if val, ok := getAtPath(m, prefix); ok {
+				selectChildren(out, prefix, val)
+			}
+		case strings.Contains(f, "*"):
+			var vals []any
+
+			collectAtPath(m, splitPath(f), &vals)
+
+			if len(vals) > 0 {
+				out[f] = vals
+			}
+		default:
+			if val, ok := getAtPath(m, f); ok {
+				out[f] = val
+			}
 		}
 	}
 
 	return out
 }
 
+// selectChildren adds each key or element of v to out as prefix.key.
+func selectChildren(out map[string]any, prefix string, v any) {
+	switch c := v.(type) {
+	case map[string]any:
+		for k, child := range c {
+			out[prefix+"."+k] = child
+		}
+	case []any:
+		for i, child := range c {
+			out[prefix+"."+strconv.Itoa(i)] = child
+		}
+	}
+}
+
+func splitPath(path string) []string {
+	segs := strings.Split(path, ".")
+	for i := range segs {
+		segs[i] = strings.TrimSpace(segs[i])
+	}
+
+	return segs
+}
+
+// collectAtPath appends every value matching segs to out. A "*" segment
+// matches all keys or elements, so values under several wildcards come out
+// as one flat list ("variants.*.sku" is every variant's SKU).
+func collectAtPath(v any, segs []string, out *[]any) {
+	if len(segs) == 0 {
+		*out = append(*out, v)
+
+		return
+	}
+
+	seg, rest := segs[0], segs[1:]
+
+	switch c := v.(type) {
+	case map[string]any:
+		if seg != "*" {
+			if next, ok := c[seg]; ok {
+				collectAtPath(next, rest, out)
+			}
+
+			return
+		}
+
+		keys := make([]string, 0, len(c))
+		for k := range c {
+			keys = append(keys, k)
+		}
+
+		slices.Sort(keys)
+
+		for _, k := range keys {
+			collectAtPath(c[k], rest, out)
+		}
+	case []any:
+		if seg != "*" {
+			if i, err := strconv.Atoi(seg); err == nil && i >= 0 && i < len(c) {
+				collectAtPath(c[i], rest, out)
+			}
+
+			return
+		}
+
+		for _, e := range c {
+			collectAtPath(e, rest, out)
+		}
+	}
+}
+
+// withoutPath returns v with the values matching segs removed. Containers
+// on the path are copied, so v itself is left untouched.
+func withoutPath(v any, segs []string) any {
+	if len(segs) == 0 {
+		return v
+	}
+
+	seg, rest := segs[0], segs[1:]
+	match := func(key string) bool { return seg == "*" || seg == key }
+
+	switch c := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(c))
+
+		for k, child := range c {
+			switch {
+			case !match(k):
+				out[k] = child
+			case len(rest) > 0:
+				out[k] = withoutPath(child, rest)
+			}
+		}
+
+		return out
+	case []any:
+		out := make([]any, 0, len(c))
+
+		for i, child := range c {
+			switch {
+			case !match(strconv.Itoa(i)):
+				out = append(out, child)
+			case len(rest) > 0:
+				out = append(out, withoutPath(child, rest))
+			}
+		}
+
+		return out
+	default:
+		return v
+	}
+}
+
 func getAtPath(v any, path string) (any, bool) {
 	path = strings.TrimSpace(path)
 	if path == "" {
@@ -288,6 +288,79 @@ func TestApplyJSONTransform_NestedPath(t *testing.T) {
 	}
 }
 
+func TestApplyJSONTransform_Wildcard(t *testing.T) {
+	t.Parallel()
+
+	data := []any{
+		map[string]any{"id": float64(1), "name": map[string]any{"es": "Remera", "pt": "Camiseta"}},
+		map[string]any{"id": float64(2), "name": "Plain"},
+	}
+
+	result := outfmt.ApplyJSONTransform(data, outfmt.JSONTransform{Select: []string{"id", "name.*"}})
+
+	items, ok := result.([]any)
+	if !ok || len(items) != 2 {
+		t.Fatalf("expected 2 items, got %#v", result)
+	}
+
+	first, _ := items[0].(map[string]any)
+	if first["name.es"] != "Remera" || first["name.pt"] != "Camiseta" || len(first) != 3 {
+		t.Errorf("first = %v, want id and both translations", first)
+	}
+
+	second, _ := items[1].(map[string]any)
+	if len(second) != 1 {
+		t.Errorf("second = %v, want only id (name has no keys)", second)
+	}
+}
+
+func TestApplyJSONTransform_WildcardPaths(t *testing.T) {
+	t.Parallel()
+
+	product := map[string]any{
+		"id": float64(1),
+		"variants": []any{
+			map[string]any{"sku": "A", "values": []any{map[string]any{"es": "Rojo"}}},
+			map[string]any{"sku": "B", "values": []any{map[string]any{"es": "Azul"}}},
+			map[string]any{"price": "10"},
+		},
+		"images": []any{map[string]any{"src": "a.jpg"}},
+	}
+
+	tests := []struct {
+		name   string
+		fields []string
+		want   string
+	}{
+		{"all skus", []string{"id", "variants.*.sku"}, `{"id":1,"variants.*.sku":["A","B"]}`},
+		{"flattened", []string{"variants.*.values.*.es"}, `{"variants.*.values.*.es":["Rojo","Azul"]}`},
+		{"no match", []string{"id", "images.*.alt"}, `{"id":1}`},
+		{"exclude", []string{"!images", "!variants"}, `{"id":1}`},
+		{"exclude nested", []string{"!variants.*.values", "!images.0"}, `{"id":1,"images":[],"variants":[{"sku":"A"},{"sku":"B"},{"price":"10"}]}`},
+		{"exclude then select", []string{"!variants.0", "variants.*.sku"}, `{"variants.*.sku":["B"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := json.Marshal(outfmt.ApplyJSONTransform(product, outfmt.JSONTransform{Select: tt.fields}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// Exclusions copy; the input is left alone.
+	if _, ok := product["images"]; !ok || len(product["variants"].([]any)) != 3 {
+		t.Errorf("input was modified: %v", product)
+	}
+}
+
 func TestApplyJSONTransform_MissingFields(t *testing.T) {
 	t.Parallel()
 
@@ -356,3 +429,25 @@ func TestWriteJSON_WithSelect(t *testing.T) {
 		t.Error("name should be filtered out by --select")
 	}
 }
+
+func TestWriteJSON_YAML(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		Name string   `json:"name"`
+		ID   int64    `json:"id"`
+		Tags []string `json:"tags"`
+	}
+
+	ctx := outfmt.WithJSONTransform(context.Background(), outfmt.JSONTransform{YAML: true})
+
+	var buf bytes.Buffer
+	if err := outfmt.WriteJSON(ctx, &buf, []item{{Name: "Remera", ID: 1234567890, Tags: []string{"no", "42"}}}); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	want := "- name: Remera\n  id: 1234567890\n  tags:\n    - \"no\"\n    - \"42\"\n"
+	if buf.String() != want {
+		t.Errorf("yaml = %q, want %q", buf.String(), want)
+	}
+}
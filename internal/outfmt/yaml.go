@@ -0,0 +1,57 @@
+package outfmt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EncodeYAML writes v as YAML. The value goes through JSON first, so field
+// names, order and number formatting match the JSON output.
+func EncodeYAML(w io.Writer, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode yaml: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return fmt.Errorf("encode yaml: %w", err)
+	}
+
+	blockStyle(&doc)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("encode yaml: %w", err)
+	}
+
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("encode yaml: %w", err)
+	}
+
+	return nil
+}
+
+// yaml11Bools are strings that YAML 1.1 readers take for booleans, so they
+// stay quoted.
+var yaml11Bools = []string{"y", "n", "yes", "no", "on", "off"}
+
+// blockStyle drops the flow and quoting styles a node got from parsing
+// JSON, so it prints as plain YAML; strings that would read as another type
+// are still quoted.
+func blockStyle(n *yaml.Node) {
+	if n.Kind != yaml.ScalarNode || n.Tag != "!!str" || !slices.Contains(yaml11Bools, strings.ToLower(n.Value)) {
+		n.Style = 0
+	}
+
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
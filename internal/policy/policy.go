@@ -0,0 +1,211 @@
+// Package policy evaluates guardrail files that restrict what the CLI may do:
+// which commands run, which need --force, and which API resources may be
+// read or written.
+//
+// A policy is YAML (or JSON):
+//
+//	commands:
+//	  allow: [product, order list, order get]
+//	  deny: [customer anonymize]
+//	  require_force: [apply, seed]
+//	resources:
+//	  products: write
+//	  customers: none
+//	  "*": read
+//
+// Command patterns match a command and its subcommands by whole words; "*"
+// matches any one word. Deny wins over allow, and a non-empty allow list
+// denies everything it doesn't match. Resources are the first segment of the
+// store-relative API path; "*" sets the default, which is otherwise write.
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Access is the level of API access to a resource.
+type Access string
+
+// Access levels, each including the ones before it.
+const (
+	AccessNone  Access = "none"
+	AccessRead  Access = "read"
+	AccessWrite Access = "write"
+)
+
+func (a Access) allows(want Access) bool {
+	return a.rank() >= want.rank()
+}
+
+func (a Access) rank() int {
+	switch a {
+	case AccessRead:
+		return 1
+	case AccessWrite:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Policy is a parsed policy file.
+type Policy struct {
+	Commands  CommandRules      `yaml:"commands"`
+	Resources map[string]Access `yaml:"resources"`
+}
+
+// CommandRules restrict which commands may run.
+type CommandRules struct {
+	Allow        []string `yaml:"allow"`
+	Deny         []string `yaml:"deny"`
+	RequireForce []string `yaml:"require_force"`
+}
+
+// DeniedError is returned for anything the policy forbids.
+type DeniedError struct {
+	Reason string
+}
+
+func (e *DeniedError) Error() string {
+	return "denied by policy: " + e.Reason
+}
+
+// ForceRequiredError is returned when the policy requires --force for a
+// command run without it.
+type ForceRequiredError struct {
+	Command string
+}
+
+func (e *ForceRequiredError) Error() string {
+	return fmt.Sprintf("policy requires --force for %q", e.Command)
+}
+
+// Load reads and validates the policy file at path.
+func Load(path string) (*Policy, error) {
+	b, err := os.ReadFile(path) //nolint:gosec // user-provided path
+	if err != nil {
+		return nil, fmt.Errorf("read policy: %w", err)
+	}
+
+	p, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("policy %s: %w", path, err)
+	}
+
+	return p, nil
+}
+
+// Parse decodes and validates a policy document. Unknown keys are errors, so
+// a typo can't silently weaken a policy.
+func Parse(b []byte) (*Policy, error) {
+	var p Policy
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+
+	if err := dec.Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse: %w", err)
+	}
+
+	for name, a := range p.Resources {
+		if a != AccessNone && a != AccessRead && a != AccessWrite {
+			return nil, fmt.Errorf("resource %q: access %q (want none, read, or write)", name, a)
+		}
+	}
+
+	return &p, nil
+}
+
+// CheckCommand reports whether command (e.g. "product list") may run.
+func (p *Policy) CheckCommand(command string, force bool) error {
+	if p == nil {
+		return nil
+	}
+
+	words := strings.Fields(command)
+
+	if matchAny(p.Commands.Deny, words) {
+		return &DeniedError{Reason: fmt.Sprintf("command %q is denied", command)}
+	}
+
+	if len(p.Commands.Allow) > 0 && !matchAny(p.Commands.Allow, words) {
+		return &DeniedError{Reason: fmt.Sprintf("command %q is not allowed", command)}
+	}
+
+	if !force && matchAny(p.Commands.RequireForce, words) {
+		return &ForceRequiredError{Command: command}
+	}
+
+	return nil
+}
+
+// CheckRequest reports whether an API request may be sent. GET and HEAD
+// need read access to the resource; everything else needs write access.
+func (p *Policy) CheckRequest(method, path string) error {
+	if p == nil || len(p.Resources) == 0 {
+		return nil
+	}
+
+	resource, _, _ := strings.Cut(strings.Trim(path, "/"), "/")
+
+	want := AccessWrite
+	if method == http.MethodGet || method == http.MethodHead {
+		want = AccessRead
+	}
+
+	if p.access(resource).allows(want) {
+		return nil
+	}
+
+	verb := "write"
+	if want == AccessRead {
+		verb = "read"
+	}
+
+	return &DeniedError{Reason: fmt.Sprintf("%s access to %q is not allowed", verb, resource)}
+}
+
+func (p *Policy) access(resource string) Access {
+	if a, ok := p.Resources[resource]; ok {
+		return a
+	}
+
+	if a, ok := p.Resources["*"]; ok {
+		return a
+	}
+
+	return AccessWrite
+}
+
+func matchAny(patterns, words []string) bool {
+	for _, pat := range patterns {
+		if match(strings.Fields(strings.ToLower(pat)), words) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// match reports whether pattern is a word-wise prefix of words.
+func match(pattern, words []string) bool {
+	if len(pattern) == 0 || len(pattern) > len(words) {
+		return false
+	}
+
+	for i, p := range pattern {
+		if p != "*" && p != strings.ToLower(words[i]) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,129 @@
+package policy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testPolicy = `
+commands:
+  allow: [product, order list, "order get", "* diff"]
+  deny: [product delete]
+  require_force: [product update]
+resources:
+  products: write
+  customers: none
+  "*": read
+`
+
+func TestCheckCommand(t *testing.T) {
+	t.Parallel()
+
+	p, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		command string
+		force   bool
+		want    string // "", "denied", "force"
+	}{
+		{"product list", false, ""},
+		{"product update", false, "force"},
+		{"product update", true, ""},
+		{"product delete", true, "denied"},
+		{"order list", false, ""},
+		{"order cancel", false, "denied"},
+		{"category diff", false, ""},
+		{"customer list", false, "denied"},
+	}
+
+	for _, tt := range tests {
+		err := p.CheckCommand(tt.command, tt.force)
+
+		var (
+			denied *DeniedError
+			force  *ForceRequiredError
+			got    string
+		)
+
+		switch {
+		case errors.As(err, &denied):
+			got = "denied"
+		case errors.As(err, &force):
+			got = "force"
+		case err != nil:
+			t.Fatalf("%s: unexpected error %v", tt.command, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("CheckCommand(%q, %v) = %q, want %q", tt.command, tt.force, got, tt.want)
+		}
+	}
+}
+
+func TestCheckRequest(t *testing.T) {
+	t.Parallel()
+
+	p, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method, path string
+		allowed      bool
+	}{
+		{"POST", "products", true},
+		{"DELETE", "products/1/images/2", true},
+		{"GET", "customers/5", false},
+		{"GET", "orders", true},
+		{"POST", "orders/1/cancel", false},
+	}
+
+	for _, tt := range tests {
+		if err := p.CheckRequest(tt.method, tt.path); (err == nil) != tt.allowed {
+			t.Errorf("CheckRequest(%s %s) = %v, want allowed=%v", tt.method, tt.path, err, tt.allowed)
+		}
+	}
+
+	var none *Policy
+	if err := none.CheckRequest("DELETE", "products/1"); err != nil {
+		t.Errorf("nil policy: %v", err)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, doc := range []string{
+		"resources: {products: admin}",
+		"comands: {allow: [product]}",
+		"commands: [",
+	} {
+		if _, err := Parse([]byte(doc)); err == nil {
+			t.Errorf("Parse(%q) succeeded", doc)
+		}
+	}
+}
+
+func TestLoad_JSON(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(`{"resources":{"*":"read"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.CheckRequest("PUT", "products/1"); err == nil {
+		t.Error("read-only policy allowed a PUT")
+	}
+}
@@ -0,0 +1,486 @@
+// Package qr encodes short texts, such as storefront links, as QR codes and
+// renders them as PNG images or terminal text. It implements the byte mode
+// of ISO/IEC 18004 at error correction level M, versions 1 to 10, which
+// holds up to 213 bytes.
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// ErrTooLong is returned for texts that don't fit in a version 10 code.
+var ErrTooLong = errors.New("text too long for a QR code")
+
+// quietZone is the light border, in modules, readers need around a code.
+const quietZone = 4
+
+// Code is an encoded QR symbol.
+type Code struct {
+	size     int
+	modules  [][]bool
+	function [][]bool
+}
+
+// version describes the level M error correction blocks of one version.
+type version struct {
+	ecPerBlock int
+	blocks     []int // data codewords in each block
+	alignment  []int
+}
+
+var versions = []version{
+	1:  {10, []int{16}, nil},
+	2:  {16, []int{28}, []int{6, 18}},
+	3:  {26, []int{44}, []int{6, 22}},
+	4:  {18, []int{32, 32}, []int{6, 26}},
+	5:  {24, []int{43, 43}, []int{6, 30}},
+	6:  {16, []int{27, 27, 27, 27}, []int{6, 34}},
+	7:  {18, []int{31, 31, 31, 31}, []int{6, 22, 38}},
+	8:  {22, []int{38, 38, 39, 39}, []int{6, 24, 42}},
+	9:  {22, []int{36, 36, 36, 37, 37}, []int{6, 26, 46}},
+	10: {26, []int{43, 43, 43, 43, 44}, []int{6, 28, 50}},
+}
+
+func (v version) dataCodewords() int {
+	n := 0
+	for _, b := range v.blocks {
+		n += b
+	}
+
+	return n
+}
+
+// Encode returns the smallest code that holds text.
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+
+	for n := 1; n < len(versions); n++ {
+		countBits := 8
+		if n >= 10 {
+			countBits = 16
+		}
+
+		if 4+countBits+8*len(data) > 8*versions[n].dataCodewords() {
+			continue
+		}
+
+		c := newCode(n)
+		c.placeData(interleave(versions[n], encodeData(data, countBits, versions[n].dataCodewords())))
+		c.applyBestMask()
+
+		return c, nil
+	}
+
+	return nil, fmt.Errorf("%w: %d bytes, at most 213", ErrTooLong, len(data))
+}
+
+// Size is the width and height of the code in modules, without the quiet
+// zone.
+func (c *Code) Size() int { return c.size }
+
+// Dark reports whether the module at column x, row y is dark.
+func (c *Code) Dark(x, y int) bool {
+	return x >= 0 && y >= 0 && x < c.size && y < c.size && c.modules[y][x]
+}
+
+// PNG writes the code as a black on white PNG, scale pixels per module.
+func (c *Code) PNG(w io.Writer, scale int) error {
+	if scale < 1 {
+		scale = 1
+	}
+
+	side := (c.size + 2*quietZone) * scale
+	img := image.NewGray(image.Rect(0, 0, side, side))
+
+	for py := range side {
+		for px := range side {
+			shade := color.Gray{Y: 255}
+			if c.Dark(px/scale-quietZone, py/scale-quietZone) {
+				shade = color.Gray{Y: 0}
+			}
+
+			img.SetGray(px, py, shade)
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// ANSI renders the code with half-block characters, two module rows per
+// line, forcing black on white so it scans on dark terminals too.
+func (c *Code) ANSI() string {
+	var b strings.Builder
+
+	for y := -quietZone; y < c.size+quietZone; y += 2 {
+		b.WriteString("\x1b[30;47m")
+
+		for x := -quietZone; x < c.size+quietZone; x++ {
+			top, bottom := c.Dark(x, y), c.Dark(x, y+1)
+
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+
+		b.WriteString("\x1b[0m\n")
+	}
+
+	return b.String()
+}
+
+// encodeData builds the byte mode bit stream, padded to capacity codewords.
+func encodeData(data []byte, countBits, capacity int) []byte {
+	var bits bitBuffer
+
+	bits.append(0b0100, 4)
+	bits.append(len(data), countBits)
+
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	bits.append(0, min(4, 8*capacity-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+
+	out := bits.bytes()
+	for pad := 0xEC; len(out) < capacity; pad ^= 0xEC ^ 0x11 {
+		out = append(out, byte(pad))
+	}
+
+	return out
+}
+
+// interleave splits data into the version's blocks, adds their error
+// correction and interleaves the lot in transmission order.
+func interleave(v version, data []byte) []byte {
+	blocks := make([][]byte, len(v.blocks))
+	ecc := make([][]byte, len(v.blocks))
+	gen := rsGenerator(v.ecPerBlock)
+
+	for i, n := range v.blocks {
+		blocks[i], data = data[:n], data[n:]
+		ecc[i] = rsRemainder(blocks[i], gen)
+	}
+
+	var out []byte
+
+	for i := range v.blocks[len(v.blocks)-1] {
+		for _, b := range blocks {
+			if i < len(b) {
+				out = append(out, b[i])
+			}
+		}
+	}
+
+	for i := range v.ecPerBlock {
+		for _, e := range ecc {
+			out = append(out, e[i])
+		}
+	}
+
+	return out
+}
+
+// newCode lays out the function patterns of a version, leaving the data
+// area empty.
+func newCode(n int) *Code {
+	size := 17 + 4*n
+	c := &Code{size: size, modules: grid(size), function: grid(size)}
+
+	for i := range size {
+		c.set(6, i, i%2 == 0)
+		c.set(i, 6, i%2 == 0)
+	}
+
+	for _, p := range [][2]int{{3, 3}, {size - 4, 3}, {3, size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := p[0]+dx, p[1]+dy
+				if x >= 0 && y >= 0 && x < size && y < size {
+					d := max(abs(dx), abs(dy))
+					c.set(x, y, d != 2 && d != 4)
+				}
+			}
+		}
+	}
+
+	align := versions[n].alignment
+	last := len(align) - 1
+
+	for i, ay := range align {
+		for j, ax := range align {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					c.set(ax+dx, ay+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// Reserve the format areas; the real bits go in with the mask.
+	c.drawFormat(0)
+
+	if n >= 7 {
+		bits := n<<12 | bchRemainder(n, 0x1F25, 12)
+
+		for i := range 18 {
+			a, b := size-11+i%3, i/3
+			c.set(a, b, bits>>i&1 == 1)
+			c.set(b, a, bits>>i&1 == 1)
+		}
+	}
+
+	return c
+}
+
+// drawFormat writes both copies of the format information for level M and
+// mask, plus the dark module.
+func (c *Code) drawFormat(mask int) {
+	bits := (mask<<10 | bchRemainder(mask, 0x537, 10)) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	for i := range 6 {
+		c.set(8, i, bit(i))
+	}
+
+	c.set(8, 7, bit(6))
+	c.set(8, 8, bit(7))
+	c.set(7, 8, bit(8))
+
+	for i := 9; i < 15; i++ {
+		c.set(14-i, 8, bit(i))
+	}
+
+	for i := range 8 {
+		c.set(c.size-1-i, 8, bit(i))
+	}
+
+	for i := 8; i < 15; i++ {
+		c.set(8, c.size-15+i, bit(i))
+	}
+
+	c.set(8, c.size-8, true)
+}
+
+// placeData fills the data area in the standard two-column zigzag, from the
+// bottom right corner.
+func (c *Code) placeData(data []byte) {
+	i := 0
+
+	for right := c.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+
+		for vert := range c.size {
+			for j := range 2 {
+				x := right - j
+
+				y := vert
+				if (right+1)&2 == 0 {
+					y = c.size - 1 - vert
+				}
+
+				if c.function[y][x] || i >= 8*len(data) {
+					continue
+				}
+
+				c.modules[y][x] = data[i>>3]>>(7-i&7)&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+var masks = []func(x, y int) bool{
+	func(x, y int) bool { return (x+y)%2 == 0 },
+	func(_, y int) bool { return y%2 == 0 },
+	func(x, _ int) bool { return x%3 == 0 },
+	func(x, y int) bool { return (x+y)%3 == 0 },
+	func(x, y int) bool { return (x/3+y/2)%2 == 0 },
+	func(x, y int) bool { return x*y%2+x*y%3 == 0 },
+	func(x, y int) bool { return (x*y%2+x*y%3)%2 == 0 },
+	func(x, y int) bool { return ((x+y)%2+x*y%3)%2 == 0 },
+}
+
+// applyBestMask applies the mask with the lowest penalty score.
+func (c *Code) applyBestMask() {
+	best, bestScore := 0, -1
+
+	for m := range masks {
+		c.applyMask(m)
+		c.drawFormat(m)
+
+		if score := c.penalty(); bestScore < 0 || score < bestScore {
+			best, bestScore = m, score
+		}
+
+		c.applyMask(m)
+	}
+
+	c.applyMask(best)
+	c.drawFormat(best)
+}
+
+// applyMask flips the data modules selected by mask m; applying it twice
+// undoes it.
+func (c *Code) applyMask(m int) {
+	for y := range c.size {
+		for x := range c.size {
+			if !c.function[y][x] && masks[m](x, y) {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores the current modules by the four rules of the standard;
+// lower scans more reliably.
+func (c *Code) penalty() int {
+	score, dark := 0, 0
+	finder := []bool{true, false, true, true, true, false, true}
+
+	line := func(get func(i int) bool) {
+		run := 1
+
+		for i := 1; i <= c.size; i++ {
+			if i < c.size && get(i) == get(i-1) {
+				run++
+
+				continue
+			}
+
+			if run >= 5 {
+				score += run - 2
+			}
+
+			run = 1
+		}
+
+		// Finder-like 1:1:3:1:1 runs with four light modules on one side.
+		for i := 0; i+7 <= c.size; i++ {
+			match := true
+			for k, want := range finder {
+				if get(i+k) != want {
+					match = false
+
+					break
+				}
+			}
+
+			if match && (lightRun(get, i-4, i, c.size) || lightRun(get, i+7, i+11, c.size)) {
+				score += 40
+			}
+		}
+	}
+
+	for y := range c.size {
+		line(func(i int) bool { return c.modules[y][i] })
+	}
+
+	for x := range c.size {
+		line(func(i int) bool { return c.modules[i][x] })
+	}
+
+	for y := range c.size {
+		for x := range c.size {
+			if c.modules[y][x] {
+				dark++
+			}
+
+			if x+1 < c.size && y+1 < c.size {
+				v := c.modules[y][x]
+				if c.modules[y][x+1] == v && c.modules[y+1][x] == v && c.modules[y+1][x+1] == v {
+					score += 3
+				}
+			}
+		}
+	}
+
+	total := c.size * c.size
+	score += 10 * (abs(20*dark-10*total) / total)
+
+	return score
+}
+
+// lightRun reports whether modules from to to (exclusive) are all light,
+// counting the quiet zone outside the symbol as light.
+func lightRun(get func(i int) bool, from, to, size int) bool {
+	for i := from; i < to; i++ {
+		if i >= 0 && i < size && get(i) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *Code) set(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.function[y][x] = true
+}
+
+// bchRemainder appends the BCH error correction bits of data: the
+// remainder of data shifted by n bits, divided by poly.
+func bchRemainder(data, poly, n int) int {
+	rem := data
+	for range n {
+		rem = rem<<1 ^ (rem>>(n-1))*poly
+	}
+
+	return rem
+}
+
+func grid(size int) [][]bool {
+	g := make([][]bool, size)
+	for i := range g {
+		g[i] = make([]bool, size)
+	}
+
+	return g
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+// bitBuffer collects bits most significant first.
+type bitBuffer []bool
+
+func (b *bitBuffer) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, v>>i&1 == 1)
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	out := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			out[i/8] |= 1 << (7 - i%8)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,120 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRSRemainder(t *testing.T) {
+	t.Parallel()
+
+	// "HELLO WORLD" as a 1-M alphanumeric code, the usual worked example.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	if got := rsRemainder(data, rsGenerator(10)); !slices.Equal(got, want) {
+		t.Errorf("rsRemainder = %v, want %v", got, want)
+	}
+}
+
+func TestBCHRemainder(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		got  int
+		want int
+	}{
+		{"format M mask 0", (0<<10 | bchRemainder(0, 0x537, 10)) ^ 0x5412, 0b101010000010010},
+		{"format M mask 5", (5<<10 | bchRemainder(5, 0x537, 10)) ^ 0x5412, 0b100000011001110},
+		{"version 7", 7<<12 | bchRemainder(7, 0x1F25, 12), 0b000111110010010100},
+		{"version 10", 10<<12 | bchRemainder(10, 0x1F25, 12), 0b001010010011010011},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %b, want %b", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestEncodeData(t *testing.T) {
+	t.Parallel()
+
+	got := encodeData([]byte("ab"), 8, 6)
+	want := []byte{0x40, 0x26, 0x16, 0x20, 0xEC, 0x11}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("encodeData = %x, want %x", got, want)
+	}
+}
+
+func TestEncode_Versions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		length int
+		size   int
+	}{
+		{1, 21},
+		{14, 21},
+		{15, 25},
+		{100, 41},
+		{154, 53},
+		{213, 57},
+	}
+
+	for _, tt := range tests {
+		c, err := Encode(strings.Repeat("a", tt.length))
+		if err != nil {
+			t.Fatalf("Encode(%d bytes): %v", tt.length, err)
+		}
+
+		if c.Size() != tt.size {
+			t.Errorf("Encode(%d bytes) size = %d, want %d", tt.length, c.Size(), tt.size)
+		}
+
+		// Finder pattern corners and the always-dark module.
+		for _, p := range [][2]int{{0, 0}, {c.Size() - 1, 0}, {0, c.Size() - 1}, {8, c.Size() - 8}} {
+			if !c.Dark(p[0], p[1]) {
+				t.Errorf("Encode(%d bytes): module %v is light", tt.length, p)
+			}
+		}
+	}
+
+	if _, err := Encode(strings.Repeat("a", 214)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Encode(214 bytes) error = %v, want ErrTooLong", err)
+	}
+}
+
+func TestCode_Render(t *testing.T) {
+	t.Parallel()
+
+	c, err := Encode("https://tienda.example/productos/remera/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := c.PNG(&buf, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("decode png: %v", err)
+	}
+
+	if side := (c.Size() + 8) * 3; img.Bounds().Dx() != side || img.Bounds().Dy() != side {
+		t.Errorf("png bounds = %v, want %dx%d", img.Bounds(), side, side)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(c.ANSI(), "\n"), "\n")
+	if want := (c.Size() + 8 + 1) / 2; len(lines) != want {
+		t.Errorf("ANSI lines = %d, want %d", len(lines), want)
+	}
+}
@@ -0,0 +1,74 @@
+package qr
+
+// Reed-Solomon error correction over GF(256) with the QR polynomial
+// x^8 + x^4 + x^3 + x^2 + 1.
+
+var gfExp, gfLog = func() ([512]byte, [256]byte) {
+	var exp [512]byte
+
+	var log [256]byte
+
+	x := 1
+	for i := range 255 {
+		exp[i] = byte(x)
+		log[x] = byte(i)
+
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11D
+		}
+	}
+
+	for i := 255; i < len(exp); i++ {
+		exp[i] = exp[i-255]
+	}
+
+	return exp, log
+}()
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// rsGenerator returns the coefficients, highest degree first and without
+// the leading 1, of the product of (x - α^i) for i below degree.
+func rsGenerator(degree int) []byte {
+	gen := make([]byte, degree)
+	gen[degree-1] = 1
+
+	root := byte(1)
+
+	for range degree {
+		for j := range gen {
+			gen[j] = gfMul(gen[j], root)
+			if j+1 < len(gen) {
+				gen[j] ^= gen[j+1]
+			}
+		}
+
+		root = gfMul(root, 2)
+	}
+
+	return gen
+}
+
+// rsRemainder returns the error correction codewords of data.
+func rsRemainder(data, gen []byte) []byte {
+	rem := make([]byte, len(gen))
+
+	for _, b := range data {
+		factor := b ^ rem[0]
+		copy(rem, rem[1:])
+		rem[len(rem)-1] = 0
+
+		for i, g := range gen {
+			rem[i] ^= gfMul(g, factor)
+		}
+	}
+
+	return rem
+}
@@ -0,0 +1,46 @@
+package redact
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestScrubPII(t *testing.T) {
+	t.Parallel()
+
+	in := `{"id":1001,"number":55,"total":"10.00","contact_email":"ana@mail.com","contact_phone":"+54 11 5555",
+		"customer":{"id":3,"name":"Ana Paz","email":"ana@mail.com"},
+		"shipping_address":{"name":"Ana Paz","address":"Calle 1","number":"123","city":"CABA"},
+		"products":[{"name":"Remera <b>roja</b>","price":"10.00"}],
+		"client_details":{"browser_ip":"1.2.3.4"}}`
+
+	var got map[string]any
+	if err := json.Unmarshal(ScrubPII([]byte(in)), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	customer := got["customer"].(map[string]any)
+	shipping := got["shipping_address"].(map[string]any)
+	product := got["products"].([]any)[0].(map[string]any)
+
+	if got["contact_email"] != customer["email"] || !strings.HasSuffix(customer["email"].(string), "@example.com") {
+		t.Errorf("emails = %v, %v; want the same placeholder", got["contact_email"], customer["email"])
+	}
+
+	if customer["name"] == "Ana Paz" || shipping["name"] == "Ana Paz" || shipping["number"] == "123" || shipping["address"] == "Calle 1" {
+		t.Errorf("personal data left: %v, %v", customer, shipping)
+	}
+
+	if got["number"] != 55.0 || got["total"] != "10.00" || shipping["city"] != "CABA" || product["name"] != "Remera <b>roja</b>" {
+		t.Errorf("order data changed: %v", got)
+	}
+
+	if got["client_details"].(map[string]any)["browser_ip"] == "1.2.3.4" {
+		t.Error("IP left")
+	}
+
+	if out := ScrubPII([]byte("not json")); string(out) != "not json" {
+		t.Errorf("non-JSON = %q", out)
+	}
+}
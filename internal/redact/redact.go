@@ -0,0 +1,157 @@
+// Package redact masks known secrets (access tokens, client secrets) in
+// everything the CLI prints or logs, and scrubs personal data from recorded
+// API responses.
+//
+// Redaction works on each Write call and each log record independently: the
+// CLI writes whole lines or whole JSON documents at once, so a secret is
+// never split across writes in practice.
+package redact
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// Mask replaces each secret in output.
+const Mask = "[REDACTED]"
+
+// minSecretLen skips values too short to be credentials, which would mask
+// ordinary words and numbers.
+const minSecretLen = 8
+
+// Redactor replaces a fixed set of secrets. A nil *Redactor redacts nothing.
+type Redactor struct {
+	replacer *strings.Replacer
+}
+
+// New returns a Redactor for secrets, ignoring empty and short values.
+func New(secrets ...string) *Redactor {
+	var kept []string
+
+	for _, s := range secrets {
+		if len(s) >= minSecretLen && !slices.Contains(kept, s) {
+			kept = append(kept, s)
+		}
+	}
+
+	if len(kept) == 0 {
+		return nil
+	}
+
+	// Longest first, so a secret containing another is masked whole.
+	slices.SortFunc(kept, func(a, b string) int { return len(b) - len(a) })
+
+	pairs := make([]string, 0, 2*len(kept))
+	for _, s := range kept {
+		pairs = append(pairs, s, Mask)
+	}
+
+	return &Redactor{replacer: strings.NewReplacer(pairs...)}
+}
+
+// String returns s with every secret masked.
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+
+	return r.replacer.Replace(s)
+}
+
+// Writer returns a writer that masks secrets before writing to w.
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	if r == nil {
+		return w
+	}
+
+	return &writer{r: r, w: w}
+}
+
+type writer struct {
+	r *Redactor
+	w io.Writer
+}
+
+// Write reports len(p) on success even when masking changed the length, as
+// callers expect from a transparent writer.
+func (w *writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.w, w.r.String(string(p))); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Handler wraps h so log messages and string attributes are masked, wherever
+// h writes to.
+func (r *Redactor) Handler(h slog.Handler) slog.Handler {
+	if r == nil {
+		return h
+	}
+
+	return &handler{r: r, h: h}
+}
+
+type handler struct {
+	r *Redactor
+	h slog.Handler
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.h.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, rec slog.Record) error {
+	out := slog.NewRecord(rec.Time, rec.Level, h.r.String(rec.Message), rec.PC)
+
+	rec.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.attr(a))
+
+		return true
+	})
+
+	return h.h.Handle(ctx, out)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	masked := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		masked[i] = h.attr(a)
+	}
+
+	return &handler{r: h.r, h: h.h.WithAttrs(masked)}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{r: h.r, h: h.h.WithGroup(name)}
+}
+
+// attr masks string-valued attributes, recursing into groups. Other kinds
+// are formatted first, since errors and Stringers can carry secrets too.
+func (h *handler) attr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+
+	switch v.Kind() {
+	case slog.KindGroup:
+		group := v.Group()
+		masked := make([]any, len(group))
+
+		for i, g := range group {
+			masked[i] = h.attr(g)
+		}
+
+		return slog.Group(a.Key, masked...)
+	case slog.KindString, slog.KindAny:
+		s := v.String()
+		if r := h.r.String(s); r != s {
+			return slog.String(a.Key, r)
+		}
+
+		return slog.Attr{Key: a.Key, Value: v}
+	default:
+		return slog.Attr{Key: a.Key, Value: v}
+	}
+}
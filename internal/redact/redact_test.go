@@ -0,0 +1,91 @@
+package redact
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedactor_String(t *testing.T) {
+	t.Parallel()
+
+	r := New("tok-abcdef123456", "short", "", "tok-abcdef123456-long")
+
+	tests := []struct {
+		in, want string
+	}{
+		{"Authorization: bearer tok-abcdef123456", "Authorization: bearer [REDACTED]"},
+		{"x tok-abcdef123456-long y", "x [REDACTED] y"},
+		{"short words stay", "short words stay"},
+		{"nothing here", "nothing here"},
+	}
+
+	for _, tt := range tests {
+		if got := r.String(tt.in); got != tt.want {
+			t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRedactor_Nil(t *testing.T) {
+	t.Parallel()
+
+	r := New("", "abc")
+	if r != nil {
+		t.Fatalf("New with no usable secrets = %v, want nil", r)
+	}
+
+	var buf bytes.Buffer
+	if w := r.Writer(&buf); w != &buf {
+		t.Error("nil Redactor should return w unchanged")
+	}
+
+	if got := r.String("abc"); got != "abc" {
+		t.Errorf("String = %q", got)
+	}
+}
+
+func TestRedactor_Writer(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	w := New("secret-value-1").Writer(&buf)
+
+	n, err := w.Write([]byte(`{"token":"secret-value-1"}`))
+	if err != nil || n != 26 {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+
+	if got := buf.String(); got != `{"token":"[REDACTED]"}` {
+		t.Errorf("output = %s", got)
+	}
+}
+
+func TestRedactor_Handler(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	r := New("secret-value-1")
+	log := slog.New(r.Handler(slog.NewTextHandler(&buf, nil))).With("auth", "bearer secret-value-1")
+
+	log.Info("token secret-value-1 rejected",
+		"err", errors.New("bad token secret-value-1"),
+		"status", 401,
+		slog.Group("req", "header", "secret-value-1"),
+	)
+
+	out := buf.String()
+	if strings.Contains(out, "secret-value-1") {
+		t.Errorf("secret leaked: %s", out)
+	}
+
+	for _, want := range []string{"msg=\"token [REDACTED] rejected\"", "status=401", "req.header=[REDACTED]", "auth=\"bearer [REDACTED]\""} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s: %s", want, out)
+		}
+	}
+}
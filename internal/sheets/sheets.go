@@ -0,0 +1,358 @@
+// Package sheets writes rows to a Google Sheets spreadsheet as a service
+// account, signing the OAuth token request itself so it needs no Google
+// client libraries.
+package sheets
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// BaseURL is the Sheets API root.
+const BaseURL = "https://sheets.googleapis.com"
+
+const (
+	scope           = "https://www.googleapis.com/auth/spreadsheets"
+	defaultTokenURI = "https://oauth2.googleapis.com/token"
+)
+
+// ErrCredentials is returned for a key file that isn't a service-account key.
+var ErrCredentials = errors.New("invalid service-account key")
+
+// Credentials is the part of a service-account JSON key used to sign in.
+type Credentials struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+
+	key *rsa.PrivateKey
+}
+
+// LoadCredentials reads a service-account JSON key downloaded from the
+// Google Cloud console.
+func LoadCredentials(path string) (*Credentials, error) {
+	b, err := os.ReadFile(path) //nolint:gosec // user-provided path
+	if err != nil {
+		return nil, fmt.Errorf("read key: %w", err)
+	}
+
+	var c Credentials
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCredentials, err)
+	}
+
+	if c.ClientEmail == "" || c.PrivateKey == "" {
+		return nil, fmt.Errorf("%w: client_email or private_key missing", ErrCredentials)
+	}
+
+	block, _ := pem.Decode([]byte(c.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("%w: private_key is not PEM", ErrCredentials)
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCredentials, err)
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: private_key is not RSA", ErrCredentials)
+	}
+
+	c.key = key
+
+	if c.TokenURI == "" {
+		c.TokenURI = defaultTokenURI
+	}
+
+	return &c, nil
+}
+
+// Error is an error response from Google.
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("google: HTTP %d", e.Status)
+	}
+
+	return fmt.Sprintf("google: HTTP %d: %s", e.Status, e.Message)
+}
+
+// Client calls the Sheets API as the service account.
+type Client struct {
+	creds   *Credentials
+	baseURL string
+	http    *http.Client
+
+	token  string
+	expiry time.Time
+}
+
+// New returns a client for the API at baseURL (BaseURL outside tests).
+func New(creds *Credentials, baseURL string, httpClient *http.Client) *Client {
+	return &Client{creds: creds, baseURL: strings.TrimRight(baseURL, "/"), http: httpClient}
+}
+
+// Email is the service account's address, which spreadsheets must be
+// shared with.
+func (c *Client) Email() string { return c.creds.ClientEmail }
+
+// Values returns the cells of tab, row by row, as displayed.
+func (c *Client) Values(ctx context.Context, spreadsheetID, tab string) ([][]any, error) {
+	var out struct {
+		Values [][]any `json:"values"`
+	}
+
+	if err := c.do(ctx, http.MethodGet, c.valuesURL(spreadsheetID, tab, ""), nil, &out); err != nil {
+		return nil, err
+	}
+
+	return out.Values, nil
+}
+
+// Update replaces the cells of tab from A1 with rows. Values are stored as
+// sent (RAW): numbers stay numbers and strings such as "2024-06-01" are not
+// reparsed as dates, so Values reads them back unchanged.
+func (c *Client) Update(ctx context.Context, spreadsheetID, tab string, rows [][]any) error {
+	body := map[string]any{"values": rows}
+
+	return c.do(ctx, http.MethodPut, c.valuesURL(spreadsheetID, tab, "A1")+"?valueInputOption=RAW", body, nil)
+}
+
+// EnsureTab adds tab to the spreadsheet unless it has one by that name.
+func (c *Client) EnsureTab(ctx context.Context, spreadsheetID, tab string) error {
+	var meta struct {
+		Sheets []struct {
+			Properties struct {
+				Title string `json:"title"`
+			} `json:"properties"`
+		} `json:"sheets"`
+	}
+
+	u := c.baseURL + "/v4/spreadsheets/" + url.PathEscape(spreadsheetID) + "?fields=sheets.properties.title"
+	if err := c.do(ctx, http.MethodGet, u, nil, &meta); err != nil {
+		return err
+	}
+
+	for _, s := range meta.Sheets {
+		if s.Properties.Title == tab {
+			return nil
+		}
+	}
+
+	body := map[string]any{"requests": []any{
+		map[string]any{"addSheet": map[string]any{"properties": map[string]any{"title": tab}}},
+	}}
+
+	return c.do(ctx, http.MethodPost, c.baseURL+"/v4/spreadsheets/"+url.PathEscape(spreadsheetID)+":batchUpdate", body, nil)
+}
+
+func (c *Client) valuesURL(spreadsheetID, tab, cell string) string {
+	// Quoted so tab names with spaces or digits parse as a sheet name.
+	rng := "'" + strings.ReplaceAll(tab, "'", "''") + "'"
+	if cell != "" {
+		rng += "!" + cell
+	}
+
+	return c.baseURL + "/v4/spreadsheets/" + url.PathEscape(spreadsheetID) + "/values/" + url.PathEscape(rng)
+}
+
+func (c *Client) do(ctx context.Context, method, endpoint string, body, out any) error {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader
+
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+
+		r = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, r)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	return c.send(req, out)
+}
+
+func (c *Client) send(req *http.Request, out any) error {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", req.Method, req.URL.Host, err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return decodeError(resp.StatusCode, b)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return nil
+}
+
+// decodeError reads both API errors ({"error":{"message"}}) and token
+// endpoint errors ({"error","error_description"}).
+func decodeError(status int, b []byte) error {
+	var apiErr struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	if json.Unmarshal(b, &apiErr) == nil && apiErr.Error.Message != "" {
+		return &Error{Status: status, Message: apiErr.Error.Message}
+	}
+
+	var tokenErr struct {
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+
+	if json.Unmarshal(b, &tokenErr) == nil && tokenErr.Error != "" {
+		return &Error{Status: status, Message: strings.TrimSpace(tokenErr.Error + " " + tokenErr.Description)}
+	}
+
+	return &Error{Status: status}
+}
+
+// accessToken exchanges a signed JWT for an access token (RFC 7523), reusing
+// it until shortly before it expires.
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	if c.token != "" && time.Until(c.expiry) > time.Minute {
+		return c.token, nil
+	}
+
+	assertion, err := c.creds.assertion(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.creds.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("build token request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+
+	if err := c.send(req, &tok); err != nil {
+		return "", fmt.Errorf("service-account sign-in: %w", err)
+	}
+
+	c.token = tok.AccessToken
+	c.expiry = time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second)
+
+	return c.token, nil
+}
+
+// assertion is the RS256-signed JWT the token endpoint accepts for an hour.
+func (c *Credentials) assertion(now time.Time) (string, error) {
+	enc := base64.RawURLEncoding
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   c.ClientEmail,
+		"scope": scope,
+		"aud":   c.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+
+	signed := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(signed))
+
+	sig, err := rsa.SignPKCS1v15(rand.Reader, c.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", fmt.Errorf("sign token request: %w", err)
+	}
+
+	return signed + "." + enc.EncodeToString(sig), nil
+}
+
+// Merge updates existing sheet rows with rows, matching on the first keys
+// cells: a row whose key is already there replaces it in place, others are
+// appended. The first row of existing is taken as a header and replaced by
+// header.
+func Merge(existing [][]any, header []any, rows [][]any, keys int) [][]any {
+	out := [][]any{header}
+	at := map[string]int{}
+
+	key := func(r []any) string {
+		return fmt.Sprint(r[:min(keys, len(r))]...)
+	}
+
+	if len(existing) > 0 {
+		existing = existing[1:]
+	}
+
+	for _, r := range existing {
+		at[key(r)] = len(out)
+		out = append(out, r)
+	}
+
+	for _, r := range rows {
+		if i, ok := at[key(r)]; ok {
+			out[i] = r
+
+			continue
+		}
+
+		at[key(r)] = len(out)
+		out = append(out, r)
+	}
+
+	return out
+}
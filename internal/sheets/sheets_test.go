@@ -0,0 +1,260 @@
+package sheets
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeKey writes a service-account key for tokenURI and returns its path
+// and public key.
+func writeKey(t *testing.T, tokenURI string) (string, *rsa.PublicKey) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "reports@shop.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    tokenURI,
+	})
+
+	path := filepath.Join(t.TempDir(), "key.json")
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path, &key.PublicKey
+}
+
+func TestLoadCredentials_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, content string
+	}{
+		{name: "not json", content: "hello"},
+		{name: "missing key", content: `{"client_email":"a@b"}`},
+		{name: "not pem", content: `{"client_email":"a@b","private_key":"abc"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "key.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := LoadCredentials(path); !errors.Is(err, ErrCredentials) {
+				t.Errorf("LoadCredentials() error = %v, want ErrCredentials", err)
+			}
+		})
+	}
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	var (
+		pub       *rsa.PublicKey
+		tokens    int
+		added     string
+		updateURL string
+		updated   [][]any
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		tokens++
+
+		if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+			t.Errorf("grant_type = %q", r.FormValue("grant_type"))
+		}
+
+		parts := strings.Split(r.FormValue("assertion"), ".")
+		if len(parts) != 3 {
+			t.Errorf("assertion = %q", r.FormValue("assertion"))
+
+			return
+		}
+
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], sig); err != nil {
+			t.Errorf("assertion signature: %v", err)
+		}
+
+		claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		if !strings.Contains(string(claims), `"iss":"reports@shop.iam.gserviceaccount.com"`) {
+			t.Errorf("claims = %s", claims)
+		}
+
+		_, _ = io.WriteString(w, `{"access_token":"ya29.test","expires_in":3600}`)
+	})
+	mux.HandleFunc("/v4/spreadsheets/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer ya29.test" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v4/spreadsheets/sheet1":
+			_, _ = io.WriteString(w, `{"sheets":[{"properties":{"title":"Orders"}}]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/v4/spreadsheets/sheet1:batchUpdate":
+			b, _ := io.ReadAll(r.Body)
+			added = string(b)
+			_, _ = io.WriteString(w, `{}`)
+		case r.Method == http.MethodGet:
+			_, _ = io.WriteString(w, `{"values":[["period","total"],["2024-06-01","10"]]}`)
+		case r.Method == http.MethodPut:
+			updateURL = r.URL.Path + "?" + r.URL.RawQuery
+
+			var body struct {
+				Values [][]any `json:"values"`
+			}
+
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			updated = body.Values
+			_, _ = io.WriteString(w, `{}`)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL)
+		}
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	path, key := writeKey(t, srv.URL+"/token")
+	pub = key
+
+	creds, err := LoadCredentials(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := New(creds, srv.URL, srv.Client())
+	ctx := context.Background()
+
+	if err := c.EnsureTab(ctx, "sheet1", "Sales"); err != nil {
+		t.Fatalf("EnsureTab() error = %v", err)
+	}
+
+	if !strings.Contains(added, `"title":"Sales"`) {
+		t.Errorf("addSheet body = %s", added)
+	}
+
+	got, err := c.Values(ctx, "sheet1", "Sales")
+	if err != nil || len(got) != 2 {
+		t.Fatalf("Values() = %v, %v", got, err)
+	}
+
+	if err := c.Update(ctx, "sheet1", "Sales", [][]any{{"period", "total"}}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if !strings.HasSuffix(updateURL, "/values/'Sales'!A1?valueInputOption=RAW") {
+		t.Errorf("update URL = %s", updateURL)
+	}
+
+	if len(updated) != 1 {
+		t.Errorf("updated = %v", updated)
+	}
+
+	if tokens != 1 {
+		t.Errorf("token requests = %d, want 1 (reused)", tokens)
+	}
+}
+
+func TestClient_Error(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			_, _ = io.WriteString(w, `{"access_token":"t","expires_in":3600}`)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, `{"error":{"code":403,"message":"The caller does not have permission","status":"PERMISSION_DENIED"}}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	path, _ := writeKey(t, srv.URL+"/token")
+
+	creds, err := LoadCredentials(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = New(creds, srv.URL, srv.Client()).Values(context.Background(), "sheet1", "Sales")
+
+	var gErr *Error
+	if !errors.As(err, &gErr) || gErr.Status != http.StatusForbidden || !strings.Contains(gErr.Message, "permission") {
+		t.Errorf("error = %v, want a 403 *Error", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	header := []any{"period", "currency", "orders"}
+
+	tests := []struct {
+		name     string
+		existing [][]any
+		rows     [][]any
+		want     [][]any
+	}{
+		{
+			name: "empty sheet",
+			rows: [][]any{{"2024-06-01", "ARS", 2}},
+			want: [][]any{header, {"2024-06-01", "ARS", 2}},
+		},
+		{
+			name:     "update and append",
+			existing: [][]any{{"old header"}, {"2024-06-01", "ARS", "1"}, {"2024-06-01", "USD", "4"}, {"note"}},
+			rows:     [][]any{{"2024-06-01", "ARS", 3}, {"2024-06-02", "ARS", 1}},
+			want:     [][]any{header, {"2024-06-01", "ARS", 3}, {"2024-06-01", "USD", "4"}, {"note"}, {"2024-06-02", "ARS", 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := Merge(tt.existing, header, tt.rows, 2); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Merge() =\n%v\nwant\n%v", fmt.Sprint(got), fmt.Sprint(tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package telemetry
+
+import (
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultServiceName is the service.name resource attribute unless
+// OTEL_SERVICE_NAME says otherwise.
+const DefaultServiceName = "nube"
+
+// defaultTimeout bounds each export, as OTEL_EXPORTER_OTLP_TIMEOUT does.
+const defaultTimeout = 10 * time.Second
+
+// Config says where and how to export.
+type Config struct {
+	TracesURL  string
+	MetricsURL string
+	Headers    map[string]string
+	// Resource describes the process (service.name and friends).
+	Resource []Attr
+	Timeout  time.Duration
+	// Parent is the remote span the command's spans join, if any.
+	Parent RemoteParent
+}
+
+// RemoteParent identifies a span in another process.
+type RemoteParent struct {
+	TraceID string
+	SpanID  string
+}
+
+var traceParentPattern = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}$`)
+
+// ConfigFromEnv reads the standard OTEL_* variables through getenv. It
+// reports false when no OTLP endpoint is set or OTEL_SDK_DISABLED is true.
+//
+// Supported: OTEL_EXPORTER_OTLP_ENDPOINT (and the _TRACES_/_METRICS_
+// variants), OTEL_EXPORTER_OTLP_HEADERS, OTEL_EXPORTER_OTLP_TIMEOUT,
+// OTEL_SERVICE_NAME, OTEL_RESOURCE_ATTRIBUTES and TRACEPARENT. Only the
+// http/json protocol is spoken.
+func ConfigFromEnv(getenv func(string) string, version string) (Config, bool) {
+	if strings.EqualFold(getenv("OTEL_SDK_DISABLED"), "true") {
+		return Config{}, false
+	}
+
+	base := strings.TrimRight(getenv("OTEL_EXPORTER_OTLP_ENDPOINT"), "/")
+	cfg := Config{
+		TracesURL:  signalURL(getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"), base, "traces"),
+		MetricsURL: signalURL(getenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT"), base, "metrics"),
+		Headers:    parseKeyValues(getenv("OTEL_EXPORTER_OTLP_HEADERS")),
+		Timeout:    defaultTimeout,
+	}
+
+	if cfg.TracesURL == "" && cfg.MetricsURL == "" {
+		return Config{}, false
+	}
+
+	if proto := getenv("OTEL_EXPORTER_OTLP_PROTOCOL"); proto != "" && proto != "http/json" {
+		slog.Warn("OTEL_EXPORTER_OTLP_PROTOCOL is not supported; exporting http/json", "protocol", proto)
+	}
+
+	if ms, err := strconv.Atoi(getenv("OTEL_EXPORTER_OTLP_TIMEOUT")); err == nil && ms > 0 {
+		cfg.Timeout = time.Duration(ms) * time.Millisecond
+	}
+
+	service := getenv("OTEL_SERVICE_NAME")
+	if service == "" {
+		service = DefaultServiceName
+	}
+
+	cfg.Resource = []Attr{String("service.name", service), String("service.version", version)}
+
+	for k, v := range parseKeyValues(getenv("OTEL_RESOURCE_ATTRIBUTES")) {
+		if k != "service.name" && k != "service.version" {
+			cfg.Resource = append(cfg.Resource, String(k, v))
+		}
+	}
+
+	if m := traceParentPattern.FindStringSubmatch(getenv("TRACEPARENT")); m != nil {
+		cfg.Parent = RemoteParent{TraceID: m[1], SpanID: m[2]}
+	}
+
+	return cfg, true
+}
+
+// signalURL is the signal's own endpoint, used as is, or base/v1/<signal>.
+func signalURL(specific, base, signal string) string {
+	if specific != "" {
+		return specific
+	}
+
+	if base == "" {
+		return ""
+	}
+
+	return base + "/v1/" + signal
+}
+
+// parseKeyValues parses "k1=v1,k2=v2" with URL-encoded values, as the OTEL
+// header and resource variables are written.
+func parseKeyValues(s string) map[string]string {
+	out := map[string]string{}
+
+	for pair := range strings.SplitSeq(s, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			continue
+		}
+
+		if dec, err := url.QueryUnescape(strings.TrimSpace(v)); err == nil {
+			v = dec
+		}
+
+		out[strings.TrimSpace(k)] = v
+	}
+
+	return out
+}
@@ -0,0 +1,265 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// scopeName is the instrumentation scope of everything exported.
+const scopeName = "github.com/gberlati/nube-cli"
+
+// OTLP status codes.
+const (
+	statusOK    = 1
+	statusError = 2
+)
+
+// aggregationDelta is OTLP's AGGREGATION_TEMPORALITY_DELTA: each run
+// reports what it counted itself.
+const aggregationDelta = 1
+
+// Shutdown exports the ended spans and the counters. Each signal is sent
+// at most once; the provider records nothing afterwards worth exporting.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	spans, counters := p.spans, p.counters
+	p.spans, p.counters = nil, map[string]*counterPoint{}
+	p.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+
+	var errs []error
+
+	if p.cfg.TracesURL != "" && len(spans) > 0 {
+		errs = append(errs, p.post(ctx, p.cfg.TracesURL, p.tracesPayload(spans)))
+	}
+
+	if p.cfg.MetricsURL != "" && len(counters) > 0 {
+		errs = append(errs, p.post(ctx, p.cfg.MetricsURL, p.metricsPayload(counters)))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p *Provider) post(ctx context.Context, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode otlp: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("otlp export: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	for k, v := range p.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req) //nolint:gosec // URL comes from the user's OTEL_* environment
+	if err != nil {
+		return fmt.Errorf("otlp export: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("otlp export to %s: %s", url, resp.Status)
+	}
+
+	return nil
+}
+
+// OTLP/JSON shapes. 64-bit integers are strings, as in protobuf's JSON
+// mapping; trace and span IDs are hex.
+
+type otlpAttr struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttr `json:"attributes"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              SpanKind   `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []otlpAttr `json:"attributes,omitempty"`
+	Status            otlpStatus `json:"status"`
+}
+
+type otlpDataPoint struct {
+	Attributes        []otlpAttr `json:"attributes,omitempty"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	AsInt             string     `json:"asInt"`
+}
+
+type otlpSum struct {
+	AggregationTemporality int             `json:"aggregationTemporality"`
+	IsMonotonic            bool            `json:"isMonotonic"`
+	DataPoints             []otlpDataPoint `json:"dataPoints"`
+}
+
+type otlpMetric struct {
+	Name        string  `json:"name"`
+	Unit        string  `json:"unit,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Sum         otlpSum `json:"sum"`
+}
+
+func (p *Provider) scope() otlpScope {
+	s := otlpScope{Name: scopeName}
+
+	for _, a := range p.cfg.Resource {
+		if a.Key == "service.version" {
+			s.Version = attrString(a.Value)
+		}
+	}
+
+	return s
+}
+
+func (p *Provider) tracesPayload(spans []*Span) map[string]any {
+	out := make([]otlpSpan, 0, len(spans))
+
+	for _, s := range spans {
+		s.mu.Lock()
+
+		span := otlpSpan{
+			TraceID:           s.traceID,
+			SpanID:            s.spanID,
+			ParentSpanID:      s.parentID,
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: unixNano(s.start),
+			EndTimeUnixNano:   unixNano(s.end),
+			Attributes:        encodeAttrs(s.attrs),
+			Status:            otlpStatus{Code: statusOK},
+		}
+
+		if s.failed {
+			span.Status = otlpStatus{Code: statusError, Message: s.errMsg}
+		}
+
+		s.mu.Unlock()
+
+		out = append(out, span)
+	}
+
+	return map[string]any{"resourceSpans": []any{map[string]any{
+		"resource":   otlpResource{Attributes: encodeAttrs(p.cfg.Resource)},
+		"scopeSpans": []any{map[string]any{"scope": p.scope(), "spans": out}},
+	}}}
+}
+
+func (p *Provider) metricsPayload(counters map[string]*counterPoint) map[string]any {
+	now := unixNano(time.Now())
+	byName := map[string]*otlpMetric{}
+
+	var metrics []*otlpMetric
+
+	for _, key := range slices.Sorted(maps.Keys(counters)) {
+		pt := counters[key]
+
+		m, ok := byName[pt.counter.Name]
+		if !ok {
+			m = &otlpMetric{
+				Name:        pt.counter.Name,
+				Unit:        pt.counter.Unit,
+				Description: pt.counter.Description,
+				Sum:         otlpSum{AggregationTemporality: aggregationDelta, IsMonotonic: true},
+			}
+			byName[pt.counter.Name] = m
+			metrics = append(metrics, m)
+		}
+
+		m.Sum.DataPoints = append(m.Sum.DataPoints, otlpDataPoint{
+			Attributes:        encodeAttrs(pt.attrs),
+			StartTimeUnixNano: unixNano(p.start),
+			TimeUnixNano:      now,
+			AsInt:             strconv.FormatInt(pt.value, 10),
+		})
+	}
+
+	return map[string]any{"resourceMetrics": []any{map[string]any{
+		"resource":     otlpResource{Attributes: encodeAttrs(p.cfg.Resource)},
+		"scopeMetrics": []any{map[string]any{"scope": p.scope(), "metrics": metrics}},
+	}}}
+}
+
+func encodeAttrs(attrs []Attr) []otlpAttr {
+	out := make([]otlpAttr, 0, len(attrs))
+
+	for _, a := range attrs {
+		var v map[string]any
+
+		switch x := a.Value.(type) {
+		case bool:
+			v = map[string]any{"boolValue": x}
+		case int:
+			v = map[string]any{"intValue": strconv.Itoa(x)}
+		case int64:
+			v = map[string]any{"intValue": strconv.FormatInt(x, 10)}
+		default:
+			v = map[string]any{"stringValue": attrString(x)}
+		}
+
+		out = append(out, otlpAttr{Key: a.Key, Value: v})
+	}
+
+	return out
+}
+
+func attrString(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case int:
+		return strconv.Itoa(x)
+	case bool:
+		return strconv.FormatBool(x)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,223 @@
+// Package telemetry exports traces and metrics of CLI runs to an
+// OpenTelemetry collector over OTLP/HTTP with JSON encoding.
+//
+// It implements the small part of OpenTelemetry the CLI needs (spans with
+// attributes and a status, monotonic counters) without the SDK. Everything
+// is kept in memory and exported once, by Shutdown, when the command ends.
+// All methods are safe on a nil *Provider, which records nothing.
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpanKind says what a span represents (OTLP's SpanKind values).
+type SpanKind int
+
+// Span kinds.
+const (
+	KindInternal SpanKind = 1
+	KindClient   SpanKind = 3
+)
+
+// Attr is a span or metric attribute. Value is a string, bool, int or int64.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// String returns a string attribute.
+func String(key, value string) Attr { return Attr{key, value} }
+
+// Int returns an integer attribute.
+func Int(key string, value int) Attr { return Attr{key, int64(value)} }
+
+// Bool returns a boolean attribute.
+func Bool(key string, value bool) Attr { return Attr{key, value} }
+
+// Counter describes a monotonic counter.
+type Counter struct {
+	Name        string
+	Unit        string
+	Description string
+}
+
+// Provider collects spans and counters for one CLI run.
+type Provider struct {
+	cfg   Config
+	start time.Time
+
+	mu       sync.Mutex
+	spans    []*Span
+	counters map[string]*counterPoint
+}
+
+type counterPoint struct {
+	counter Counter
+	attrs   []Attr
+	value   int64
+}
+
+// New returns a provider exporting as cfg says.
+func New(cfg Config) *Provider {
+	return &Provider{cfg: cfg, start: time.Now(), counters: map[string]*counterPoint{}}
+}
+
+// Span is one timed operation.
+type Span struct {
+	p        *Provider
+	traceID  string
+	spanID   string
+	parentID string
+	name     string
+	kind     SpanKind
+	start    time.Time
+
+	mu      sync.Mutex
+	end     time.Time
+	attrs   []Attr
+	errMsg  string
+	failed  bool
+	stopped bool
+}
+
+type spanCtxKey struct{}
+
+// Start begins a span, a child of the span in ctx or of the provider's
+// remote parent (TRACEPARENT), and returns a context carrying it.
+func (p *Provider) Start(ctx context.Context, name string, kind SpanKind, attrs ...Attr) (context.Context, *Span) {
+	if p == nil {
+		return ctx, nil
+	}
+
+	s := &Span{p: p, spanID: randomHex(8), name: name, kind: kind, start: time.Now(), attrs: attrs}
+
+	switch parent := SpanFromContext(ctx); {
+	case parent != nil:
+		s.traceID, s.parentID = parent.traceID, parent.spanID
+	case p.cfg.Parent.TraceID != "":
+		s.traceID, s.parentID = p.cfg.Parent.TraceID, p.cfg.Parent.SpanID
+	default:
+		s.traceID = randomHex(16)
+	}
+
+	return context.WithValue(ctx, spanCtxKey{}, s), s
+}
+
+// SpanFromContext returns the span started in ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanCtxKey{}).(*Span)
+
+	return s
+}
+
+// SetAttributes adds attributes to s.
+func (s *Span) SetAttributes(attrs ...Attr) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// SetError marks s as failed with err's message; a nil err is ignored.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failed, s.errMsg = true, err.Error()
+}
+
+// End finishes s and queues it for export. Later calls do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+
+		return
+	}
+
+	s.stopped, s.end = true, time.Now()
+	s.mu.Unlock()
+
+	s.p.mu.Lock()
+	s.p.spans = append(s.p.spans, s)
+	s.p.mu.Unlock()
+}
+
+// TraceParent returns the W3C traceparent header value naming s as the
+// parent, for propagating the trace to the services s calls.
+func (s *Span) TraceParent() string {
+	if s == nil {
+		return ""
+	}
+
+	return "00-" + s.traceID + "-" + s.spanID + "-01"
+}
+
+// Add increments c by n for the given attributes.
+func (p *Provider) Add(c Counter, n int64, attrs ...Attr) {
+	if p == nil {
+		return
+	}
+
+	attrs = slices.Clone(attrs)
+	slices.SortFunc(attrs, func(a, b Attr) int { return strings.Compare(a.Key, b.Key) })
+
+	var key strings.Builder
+
+	key.WriteString(c.Name)
+
+	for _, a := range attrs {
+		key.WriteString("\x00" + a.Key + "=" + attrString(a.Value))
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pt, ok := p.counters[key.String()]
+	if !ok {
+		pt = &counterPoint{counter: c, attrs: attrs}
+		p.counters[key.String()] = pt
+	}
+
+	pt.value += n
+}
+
+type providerCtxKey struct{}
+
+// WithProvider returns a context carrying p.
+func WithProvider(ctx context.Context, p *Provider) context.Context {
+	return context.WithValue(ctx, providerCtxKey{}, p)
+}
+
+// FromContext returns the provider in ctx, or nil.
+func FromContext(ctx context.Context) *Provider {
+	p, _ := ctx.Value(providerCtxKey{}).(*Provider)
+
+	return p
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,253 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func envFrom(m map[string]string) func(string) string {
+	return func(k string) string { return m[k] }
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		env         map[string]string
+		wantOK      bool
+		wantTraces  string
+		wantMetrics string
+	}{
+		{"unset", nil, false, "", ""},
+		{"base endpoint", map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318/"}, true, "http://collector:4318/v1/traces", "http://collector:4318/v1/metrics"},
+		{"traces only", map[string]string{"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "http://c/traces"}, true, "http://c/traces", ""},
+		{"disabled", map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://c", "OTEL_SDK_DISABLED": "TRUE"}, false, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, ok := ConfigFromEnv(envFrom(tt.env), "1.0.0")
+			if ok != tt.wantOK || cfg.TracesURL != tt.wantTraces || cfg.MetricsURL != tt.wantMetrics {
+				t.Errorf("ConfigFromEnv() = %+v, %v", cfg, ok)
+			}
+		})
+	}
+}
+
+func TestConfigFromEnv_Details(t *testing.T) {
+	t.Parallel()
+
+	cfg, ok := ConfigFromEnv(envFrom(map[string]string{
+		"OTEL_EXPORTER_OTLP_ENDPOINT": "http://c",
+		"OTEL_EXPORTER_OTLP_HEADERS":  "x-api-key=a%20b, tenant=shop",
+		"OTEL_EXPORTER_OTLP_TIMEOUT":  "2500",
+		"OTEL_SERVICE_NAME":           "sync-job",
+		"OTEL_RESOURCE_ATTRIBUTES":    "deployment.environment=prod",
+		"TRACEPARENT":                 "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+	}), "1.2.3")
+	if !ok {
+		t.Fatal("ConfigFromEnv() not enabled")
+	}
+
+	if cfg.Headers["x-api-key"] != "a b" || cfg.Headers["tenant"] != "shop" {
+		t.Errorf("headers = %v", cfg.Headers)
+	}
+
+	if cfg.Timeout != 2500*time.Millisecond {
+		t.Errorf("timeout = %v", cfg.Timeout)
+	}
+
+	want := []Attr{String("service.name", "sync-job"), String("service.version", "1.2.3"), String("deployment.environment", "prod")}
+	if len(cfg.Resource) != len(want) {
+		t.Fatalf("resource = %v, want %v", cfg.Resource, want)
+	}
+
+	for i := range want {
+		if cfg.Resource[i] != want[i] {
+			t.Errorf("resource[%d] = %v, want %v", i, cfg.Resource[i], want[i])
+		}
+	}
+
+	if cfg.Parent.TraceID != "0af7651916cd43dd8448eb211c80319c" || cfg.Parent.SpanID != "b7ad6b7169203331" {
+		t.Errorf("parent = %+v", cfg.Parent)
+	}
+}
+
+func TestNilProvider(t *testing.T) {
+	t.Parallel()
+
+	var p *Provider
+
+	ctx, span := p.Start(context.Background(), "run", KindInternal)
+	span.SetAttributes(String("k", "v"))
+	span.SetError(errors.New("boom"))
+	span.End()
+	p.Add(Counter{Name: "c"}, 1)
+
+	if span != nil || SpanFromContext(ctx) != nil || span.TraceParent() != "" {
+		t.Error("nil provider should not start spans")
+	}
+
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() = %v", err)
+	}
+}
+
+// collector records OTLP/JSON posts by path.
+type collector struct {
+	mu    sync.Mutex
+	posts map[string]map[string]any
+	hdr   http.Header
+}
+
+func newCollector(t *testing.T) (*collector, *httptest.Server) {
+	t.Helper()
+
+	c := &collector{posts: map[string]map[string]any{}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+
+		var body map[string]any
+		if err := json.Unmarshal(b, &body); err != nil {
+			t.Errorf("%s: invalid JSON: %v", r.URL.Path, err)
+		}
+
+		c.mu.Lock()
+		c.posts[r.URL.Path], c.hdr = body, r.Header.Clone()
+		c.mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+
+	return c, srv
+}
+
+// dig walks decoded JSON by map keys and list indexes.
+func dig(v any, path ...any) any {
+	for _, p := range path {
+		switch k := p.(type) {
+		case string:
+			m, _ := v.(map[string]any)
+			v = m[k]
+		case int:
+			l, _ := v.([]any)
+			if k >= len(l) {
+				return nil
+			}
+
+			v = l[k]
+		}
+	}
+
+	return v
+}
+
+func TestProvider_Export(t *testing.T) {
+	t.Parallel()
+
+	c, srv := newCollector(t)
+	p := New(Config{
+		TracesURL:  srv.URL + "/v1/traces",
+		MetricsURL: srv.URL + "/v1/metrics",
+		Headers:    map[string]string{"X-Api-Key": "secret"},
+		Resource:   []Attr{String("service.name", "nube")},
+		Timeout:    time.Second,
+	})
+
+	ctx, run := p.Start(context.Background(), "nube product list", KindInternal, String("nube.command", "product list"))
+	_, req := p.Start(ctx, "GET", KindClient, Int("http.response.status_code", 500))
+	req.SetError(errors.New("server error"))
+	req.End()
+	run.End()
+	run.End() // ending twice exports once
+
+	retries := Counter{Name: "nube.http.retries", Unit: "{retry}"}
+	p.Add(retries, 1, String("reason", "rate_limit"))
+	p.Add(retries, 2, String("reason", "rate_limit"))
+	p.Add(retries, 1, String("reason", "server_error"))
+
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() = %v", err)
+	}
+
+	if c.hdr.Get("X-Api-Key") != "secret" {
+		t.Errorf("headers = %v", c.hdr)
+	}
+
+	spans, _ := dig(c.posts["/v1/traces"], "resourceSpans", 0, "scopeSpans", 0, "spans").([]any)
+	if len(spans) != 2 {
+		t.Fatalf("spans = %v", spans)
+	}
+
+	child, parent := spans[0], spans[1]
+	if dig(child, "traceId") != dig(parent, "traceId") || dig(child, "parentSpanId") != dig(parent, "spanId") {
+		t.Errorf("child %v is not under parent %v", child, parent)
+	}
+
+	if dig(child, "status", "code") != float64(statusError) || dig(child, "kind") != float64(KindClient) {
+		t.Errorf("child = %v", child)
+	}
+
+	if dig(child, "attributes", 0, "value", "intValue") != "500" {
+		t.Errorf("child attributes = %v", dig(child, "attributes"))
+	}
+
+	if dig(c.posts["/v1/traces"], "resourceSpans", 0, "resource", "attributes", 0, "value", "stringValue") != "nube" {
+		t.Errorf("resource = %v", dig(c.posts["/v1/traces"], "resourceSpans", 0, "resource"))
+	}
+
+	metric := dig(c.posts["/v1/metrics"], "resourceMetrics", 0, "scopeMetrics", 0, "metrics", 0)
+	if dig(metric, "name") != "nube.http.retries" || dig(metric, "sum", "isMonotonic") != true {
+		t.Fatalf("metric = %v", metric)
+	}
+
+	points := map[any]any{}
+	for _, dp := range dig(metric, "sum", "dataPoints").([]any) {
+		points[dig(dp, "attributes", 0, "value", "stringValue")] = dig(dp, "asInt")
+	}
+
+	if points["rate_limit"] != "3" || points["server_error"] != "1" {
+		t.Errorf("data points = %v", points)
+	}
+}
+
+func TestProvider_RemoteParent(t *testing.T) {
+	t.Parallel()
+
+	p := New(Config{Parent: RemoteParent{TraceID: "0af7651916cd43dd8448eb211c80319c", SpanID: "b7ad6b7169203331"}})
+
+	_, s := p.Start(context.Background(), "run", KindInternal)
+	if s.traceID != p.cfg.Parent.TraceID || s.parentID != p.cfg.Parent.SpanID {
+		t.Errorf("span = %+v, want child of the remote parent", s)
+	}
+
+	if tp := s.TraceParent(); tp != "00-"+s.traceID+"-"+s.spanID+"-01" {
+		t.Errorf("TraceParent() = %q", tp)
+	}
+}
+
+func TestProvider_ExportError(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	t.Cleanup(srv.Close)
+
+	p := New(Config{TracesURL: srv.URL, Timeout: time.Second})
+	_, s := p.Start(context.Background(), "run", KindInternal)
+	s.End()
+
+	if err := p.Shutdown(context.Background()); err == nil {
+		t.Error("Shutdown() = nil, want the collector's 400")
+	}
+}
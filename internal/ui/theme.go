@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/muesli/termenv"
+)
+
+// Header styles for table headers.
+const (
+	HeaderBold      = "bold"
+	HeaderUnderline = "underline"
+	HeaderAccent    = "accent"
+	HeaderNone      = "none"
+)
+
+// Theme holds the colors (hex RGB, e.g. "#22c55e") and table header style
+// used when color is enabled. Empty fields take the default for the
+// terminal's background.
+type Theme struct {
+	Success string
+	Error   string
+	Accent  string
+	Muted   string
+	Header  string
+	// Background is "dark", "light", or empty to detect it.
+	Background string
+}
+
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Validate reports the first invalid color or style in t.
+func (t Theme) Validate() error {
+	for _, c := range []struct{ name, value string }{
+		{"success", t.Success}, {"error", t.Error}, {"accent", t.Accent}, {"muted", t.Muted},
+	} {
+		if c.value != "" && !hexColor.MatchString(c.value) {
+			return &ParseError{msg: "theme " + c.name + ": " + strconv.Quote(c.value) + " is not a #rrggbb color"}
+		}
+	}
+
+	switch t.Header {
+	case "", HeaderBold, HeaderUnderline, HeaderAccent, HeaderNone:
+	default:
+		return &ParseError{msg: "theme header: " + strconv.Quote(t.Header) + " (expected bold|underline|accent|none)"}
+	}
+
+	switch t.Background {
+	case "", "dark", "light":
+	default:
+		return &ParseError{msg: "theme background: " + strconv.Quote(t.Background) + " (expected dark|light)"}
+	}
+
+	return nil
+}
+
+// Default themes, tuned for contrast on each background.
+var (
+	darkTheme  = Theme{Success: "#22c55e", Error: "#ef4444", Accent: "#38bdf8", Muted: "#9ca3af", Header: HeaderBold}
+	lightTheme = Theme{Success: "#15803d", Error: "#b91c1c", Accent: "#0369a1", Muted: "#6b7280", Header: HeaderBold}
+)
+
+// withDefaults fills t's empty fields from the default theme for its
+// background, detecting the background when t doesn't set it and colors
+// are on.
+func (t Theme) withDefaults(colored bool) Theme {
+	dark := t.Background != "light"
+	if t.Background == "" && colored {
+		dark = hasDarkBackground()
+	}
+
+	base, background := darkTheme, "dark"
+	if !dark {
+		base, background = lightTheme, "light"
+	}
+
+	pick := func(v, def string) string {
+		if v != "" {
+			return v
+		}
+
+		return def
+	}
+
+	return Theme{
+		Success:    pick(t.Success, base.Success),
+		Error:      pick(t.Error, base.Error),
+		Accent:     pick(t.Accent, base.Accent),
+		Muted:      pick(t.Muted, base.Muted),
+		Header:     pick(t.Header, base.Header),
+		Background: background,
+	}
+}
+
+// hasDarkBackground trusts COLORFGBG ("15;0": foreground;background) when
+// the terminal sets it, and otherwise asks the terminal on stdout.
+func hasDarkBackground() bool {
+	if v := os.Getenv("COLORFGBG"); v != "" {
+		parts := strings.Split(v, ";")
+		if bg, err := strconv.Atoi(parts[len(parts)-1]); err == nil {
+			// Colors 0-6 and 8 are the dark half of the 16-color palette.
+			return bg < 7 || bg == 8
+		}
+	}
+
+	return termenv.HasDarkBackground()
+}
@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTheme_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		theme   Theme
+		wantErr string
+	}{
+		{"empty", Theme{}, ""},
+		{"full", Theme{Success: "#00ff00", Error: "#FF0000", Accent: "#123abc", Muted: "#999999", Header: HeaderUnderline, Background: "light"}, ""},
+		{"named color", Theme{Success: "green"}, "theme success"},
+		{"short hex", Theme{Muted: "#999"}, "theme muted"},
+		{"header", Theme{Header: "italic"}, "theme header"},
+		{"background", Theme{Background: "grey"}, "theme background"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.theme.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v", err)
+				}
+
+				return
+			}
+
+			var pe *ParseError
+			if !errors.As(err, &pe) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want ParseError containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTheme_WithDefaults(t *testing.T) {
+	t.Parallel()
+
+	got := Theme{Accent: "#ff00ff", Background: "light"}.withDefaults(true)
+	if got.Accent != "#ff00ff" || got.Success != lightTheme.Success || got.Header != HeaderBold || got.Background != "light" {
+		t.Errorf("light = %+v", got)
+	}
+
+	// Without colors the background isn't probed and the dark theme is used.
+	got = Theme{}.withDefaults(false)
+	if got != (Theme{Success: darkTheme.Success, Error: darkTheme.Error, Accent: darkTheme.Accent, Muted: darkTheme.Muted, Header: HeaderBold, Background: "dark"}) {
+		t.Errorf("default = %+v", got)
+	}
+}
+
+func TestHasDarkBackground_COLORFGBG(t *testing.T) {
+	for value, want := range map[string]bool{"15;0": true, "0;15": false, "0;default;8": true, "0;7": false} {
+		t.Setenv("COLORFGBG", value)
+
+		if got := hasDarkBackground(); got != want {
+			t.Errorf("COLORFGBG=%q: hasDarkBackground() = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestNew_InvalidTheme(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(Options{Theme: Theme{Error: "red"}})
+
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("New() = %v, want *ParseError", err)
+	}
+}
+
+func TestPrinter_ThemeColors(t *testing.T) {
+	t.Parallel()
+
+	var stdout bytes.Buffer
+
+	u, err := New(Options{
+		Stdout: &stdout,
+		Stderr: &bytes.Buffer{},
+		Color:  "always",
+		Theme:  Theme{Success: "#010203", Header: HeaderUnderline, Background: "dark"},
+	})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+
+	u.Out().Successf("ok")
+
+	if !strings.Contains(stdout.String(), "38;2;1;2;3") {
+		t.Errorf("Successf output = %q, want the theme's success color", stdout.String())
+	}
+
+	if h := u.Out().Header("ID"); !strings.Contains(h, "4m") || !strings.Contains(h, "ID") {
+		t.Errorf("Header() = %q, want underlined", h)
+	}
+
+	if u.Out().Theme().Accent != darkTheme.Accent {
+		t.Errorf("Theme().Accent = %q, want the dark default", u.Out().Theme().Accent)
+	}
+}
+
+func TestPrinter_NoColorLeavesTextAlone(t *testing.T) {
+	t.Parallel()
+
+	u, err := New(Options{Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}, Color: "never"})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+
+	p := u.Out()
+	for _, s := range []string{p.Accent("a"), p.Muted("a"), p.Header("a"), p.Color("#ffffff", "a")} {
+		if s != "a" {
+			t.Errorf("styled = %q, want %q", s, "a")
+		}
+	}
+}
@@ -14,6 +14,7 @@ type Options struct {
 	Stdout io.Writer
 	Stderr io.Writer
 	Color  string // auto|always|never
+	Theme  Theme
 }
 
 const colorNever = "never"
@@ -45,15 +46,21 @@ func New(opts Options) (*UI, error) {
 		return nil, &ParseError{msg: "invalid --color (expected auto|always|never)"}
 	}
 
+	if err := opts.Theme.Validate(); err != nil {
+		return nil, err
+	}
+
 	out := termenv.NewOutput(opts.Stdout, termenv.WithProfile(termenv.EnvColorProfile()))
 	errOut := termenv.NewOutput(opts.Stderr, termenv.WithProfile(termenv.EnvColorProfile()))
 
 	outProfile := chooseProfile(out.Profile, colorMode)
 	errProfile := chooseProfile(errOut.Profile, colorMode)
 
+	theme := opts.Theme.withDefaults(outProfile != termenv.Ascii || errProfile != termenv.Ascii)
+
 	return &UI{
-		out: newPrinter(out, outProfile),
-		err: newPrinter(errOut, errProfile),
+		out: newPrinter(out, outProfile, theme),
+		err: newPrinter(errOut, errProfile, theme),
 	}, nil
 }
 
@@ -78,14 +85,57 @@ func (u *UI) Err() *Printer { return u.err }
 type Printer struct {
 	o       *termenv.Output
 	profile termenv.Profile
+	theme   Theme
 }
 
-func newPrinter(o *termenv.Output, profile termenv.Profile) *Printer {
-	return &Printer{o: o, profile: profile}
+func newPrinter(o *termenv.Output, profile termenv.Profile, theme Theme) *Printer {
+	return &Printer{o: o, profile: profile, theme: theme}
 }
 
 func (p *Printer) ColorEnabled() bool { return p.profile != termenv.Ascii }
 
+// Theme returns the printer's theme, with defaults filled in.
+func (p *Printer) Theme() Theme { return p.theme }
+
+// Color styles s in color, a hex RGB string like "#3b82f6", when colors are
+// enabled.
+func (p *Printer) Color(color, s string) string {
+	if !p.ColorEnabled() {
+		return s
+	}
+
+	return termenv.String(s).Foreground(p.profile.Color(color)).String()
+}
+
+// Accent styles s in the theme's accent color, when colors are enabled.
+func (p *Printer) Accent(s string) string { return p.Color(p.theme.Accent, s) }
+
+// Muted styles s in the theme's muted color, when colors are enabled.
+func (p *Printer) Muted(s string) string { return p.Color(p.theme.Muted, s) }
+
+// Header styles a table header line as the theme says, when colors are
+// enabled.
+func (p *Printer) Header(s string) string {
+	if !p.ColorEnabled() {
+		return s
+	}
+
+	st := termenv.String(s)
+
+	switch p.theme.Header {
+	case HeaderBold:
+		st = st.Bold()
+	case HeaderUnderline:
+		st = st.Underline()
+	case HeaderAccent:
+		st = st.Foreground(p.profile.Color(p.theme.Accent)).Bold()
+	default:
+		return s
+	}
+
+	return st.String()
+}
+
 func (p *Printer) line(s string) {
 	_, _ = io.WriteString(p.o, s+"\n")
 }
@@ -99,17 +149,31 @@ func (p *Printer) Print(msg string) {
 }
 
 func (p *Printer) Successf(format string, args ...any) {
-	msg := fmt.Sprintf(format, args...)
-	if p.ColorEnabled() {
-		msg = termenv.String(msg).Foreground(p.profile.Color("#22c55e")).String()
-	}
-
-	p.line(msg)
+	p.line(p.Color(p.theme.Success, fmt.Sprintf(format, args...)))
 }
 
 func (p *Printer) Error(msg string) {
+	p.line(p.Color(p.theme.Error, msg))
+}
+
+// Addedf prints a line marking added content (in the success color when
+// colors are enabled).
+func (p *Printer) Addedf(format string, args ...any) {
+	p.line(p.Color(p.theme.Success, fmt.Sprintf(format, args...)))
+}
+
+// Removedf prints a line marking removed content (in the error color when
+// colors are enabled).
+func (p *Printer) Removedf(format string, args ...any) {
+	p.line(p.Color(p.theme.Error, fmt.Sprintf(format, args...)))
+}
+
+// Colorf prints a line in color, a hex RGB string like "#3b82f6", when colors
+// are enabled.
+func (p *Printer) Colorf(color, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
 	if p.ColorEnabled() {
-		msg = termenv.String(msg).Foreground(p.profile.Color("#ef4444")).String()
+		msg = termenv.String(msg).Foreground(p.profile.Color(color)).Bold().String()
 	}
 
 	p.line(msg)
@@ -89,6 +89,21 @@ func TestPrinter_Output(t *testing.T) {
 		t.Errorf("Print: stdout = %q, want %q", stdout.String(), "raw")
 	}
 
+	stdout.Reset()
+	u.Out().Addedf("+ %s", "a")
+	u.Out().Removedf("- %s", "b")
+
+	if stdout.String() != "+ a\n- b\n" {
+		t.Errorf("Addedf/Removedf: stdout = %q", stdout.String())
+	}
+
+	stdout.Reset()
+	u.Out().Colorf("#3b82f6", "store %s", "demo")
+
+	if stdout.String() != "store demo\n" {
+		t.Errorf("Colorf: stdout = %q", stdout.String())
+	}
+
 	u.Err().Error("bad")
 
 	if !strings.Contains(stderr.String(), "bad") {
@@ -0,0 +1,37 @@
+// Package webhook signs and verifies Tienda Nube webhook deliveries.
+//
+// Tienda Nube signs each delivery with an HMAC-SHA256 of the raw request body,
+// keyed by the app's client secret, and sends it hex-encoded in the
+// SignatureHeader header.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// SignatureHeader carries the delivery signature.
+const SignatureHeader = "X-Linkedstore-Hmac-Sha256"
+
+// Sign returns the hex-encoded signature of body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of body. Hex case and
+// surrounding whitespace are ignored; the comparison is constant-time.
+func Verify(secret string, body []byte, signature string) bool {
+	got, err := hex.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return false
+	}
+
+	want, _ := hex.DecodeString(Sign(secret, body))
+
+	return hmac.Equal(got, want)
+}
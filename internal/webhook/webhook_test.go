@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	t.Parallel()
+
+	// echo -n '{"store_id":1,"event":"order/created","id":2}' | openssl dgst -sha256 -hmac secret
+	body := []byte(`{"store_id":1,"event":"order/created","id":2}`)
+
+	const want = "f5b12c8287e87e0e9497ec27a9e7bef3e234fa91ec917205a87ba853dffe5109"
+	if got := Sign("secret", body); got != want {
+		t.Errorf("Sign = %q, want %q", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"id":2}`)
+	sig := Sign("secret", body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		body      string
+		signature string
+		want      bool
+	}{
+		{"valid", "secret", `{"id":2}`, sig, true},
+		{"uppercase hex", "secret", `{"id":2}`, strings.ToUpper(sig), true},
+		{"trailing newline", "secret", `{"id":2}`, sig + "\n", true},
+		{"wrong secret", "nope", `{"id":2}`, sig, false},
+		{"modified body", "secret", `{"id":3}`, sig, false},
+		{"not hex", "secret", `{"id":2}`, "zz", false},
+		{"empty", "secret", `{"id":2}`, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := Verify(tt.secret, []byte(tt.body), tt.signature); got != tt.want {
+				t.Errorf("Verify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package tiendanube
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// LocalizedString is a per-language text field, keyed by language code
+// ("es", "pt", "en").
+type LocalizedString map[string]string
+
+// UnmarshalJSON accepts the usual language map as well as a plain string,
+// which some endpoints return for single-language stores. A plain string is
+// stored under the empty key.
+func (l *LocalizedString) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*l = LocalizedString{"": s}
+
+		return nil
+	}
+
+	var m map[string]string
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+
+	*l = m
+
+	return nil
+}
+
+// Get returns the value for the first of langs that is set, falling back to
+// es, pt, en, and then any non-empty value.
+func (l LocalizedString) Get(langs ...string) string {
+	for _, lang := range slices.Concat(langs, []string{"es", "pt", "en"}) {
+		if v := l[lang]; v != "" {
+			return v
+		}
+	}
+
+	keys := make([]string, 0, len(l))
+	for k := range l {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		if l[k] != "" {
+			return l[k]
+		}
+	}
+
+	return ""
+}
+
+// Product is a catalog product.
+type Product struct {
+	ID           int64           `json:"id"`
+	Name         LocalizedString `json:"name"`
+	Description  LocalizedString `json:"description,omitempty"`
+	Handle       LocalizedString `json:"handle,omitempty"`
+	Published    bool            `json:"published"`
+	FreeShipping bool            `json:"free_shipping"`
+	Brand        string          `json:"brand,omitempty"`
+	Tags         string          `json:"tags,omitempty"`
+	Variants     []Variant       `json:"variants,omitempty"`
+	Images       []Image         `json:"images,omitempty"`
+	Categories   []Category      `json:"categories,omitempty"`
+	CreatedAt    string          `json:"created_at,omitempty"`
+	UpdatedAt    string          `json:"updated_at,omitempty"`
+}
+
+// Variant is a purchasable product variant. Prices are decimal strings as
+// sent by the API; Stock is nil when stock is not tracked.
+type Variant struct {
+	ID               int64             `json:"id"`
+	ProductID        int64             `json:"product_id"`
+	Price            string            `json:"price,omitempty"`
+	PromotionalPrice string            `json:"promotional_price,omitempty"`
+	Stock            *int              `json:"stock"`
+	SKU              string            `json:"sku,omitempty"`
+	Values           []LocalizedString `json:"values,omitempty"`
+	CreatedAt        string            `json:"created_at,omitempty"`
+	UpdatedAt        string            `json:"updated_at,omitempty"`
+}
+
+// Image is a product image.
+type Image struct {
+	ID        int64  `json:"id"`
+	ProductID int64  `json:"product_id"`
+	Src       string `json:"src"`
+	Position  int    `json:"position"`
+}
+
+// Category is a catalog category. Parent is zero for top-level categories.
+type Category struct {
+	ID            int64           `json:"id"`
+	Name          LocalizedString `json:"name"`
+	Description   LocalizedString `json:"description,omitempty"`
+	Handle        LocalizedString `json:"handle,omitempty"`
+	Parent        int64           `json:"parent,omitempty"`
+	Subcategories []int64         `json:"subcategories,omitempty"`
+	CreatedAt     string          `json:"created_at,omitempty"`
+	UpdatedAt     string          `json:"updated_at,omitempty"`
+}
+
+// Customer is a store customer.
+type Customer struct {
+	ID                 int64  `json:"id"`
+	Name               string `json:"name"`
+	Email              string `json:"email"`
+	Phone              string `json:"phone,omitempty"`
+	Identification     string `json:"identification,omitempty"`
+	Note               string `json:"note,omitempty"`
+	TotalSpent         string `json:"total_spent,omitempty"`
+	TotalSpentCurrency string `json:"total_spent_currency,omitempty"`
+	CreatedAt          string `json:"created_at,omitempty"`
+	UpdatedAt          string `json:"updated_at,omitempty"`
+}
+
+// Order is a store order. Amounts are decimal strings as sent by the API.
+type Order struct {
+	ID             int64     `json:"id"`
+	Number         int64     `json:"number"`
+	Token          string    `json:"token,omitempty"`
+	ContactEmail   string    `json:"contact_email,omitempty"`
+	Status         string    `json:"status"`
+	PaymentStatus  string    `json:"payment_status"`
+	ShippingStatus string    `json:"shipping_status"`
+	Currency       string    `json:"currency"`
+	Subtotal       string    `json:"subtotal,omitempty"`
+	Total          string    `json:"total"`
+	Customer       *Customer `json:"customer,omitempty"`
+	CreatedAt      string    `json:"created_at,omitempty"`
+	UpdatedAt      string    `json:"updated_at,omitempty"`
+}
+
+// Store is the store's own settings.
+type Store struct {
+	ID             int64           `json:"id"`
+	Name           LocalizedString `json:"name"`
+	Email          string          `json:"email"`
+	Country        string          `json:"country,omitempty"`
+	MainCurrency   string          `json:"main_currency,omitempty"`
+	MainLanguage   string          `json:"main_language,omitempty"`
+	OriginalDomain string          `json:"original_domain,omitempty"`
+	PlanName       string          `json:"plan_name,omitempty"`
+}
@@ -0,0 +1,223 @@
+package tiendanube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gberlati/nube-cli/internal/api"
+)
+
+// ListOptions selects a page of a list endpoint. Query carries any other
+// filters the endpoint supports (e.g. "q", "created_at_min", "status").
+type ListOptions struct {
+	Page    int
+	PerPage int
+	Query   url.Values
+}
+
+func (o *ListOptions) values() url.Values {
+	q := url.Values{}
+	if o == nil {
+		return q
+	}
+
+	for k, v := range o.Query {
+		q[k] = v
+	}
+
+	if o.Page > 0 {
+		q.Set("page", strconv.Itoa(o.Page))
+	}
+
+	if o.PerPage > 0 {
+		q.Set("per_page", strconv.Itoa(o.PerPage))
+	}
+
+	return q
+}
+
+// ProductsService reaches /products.
+type ProductsService struct{ client *Client }
+
+// List returns one page of products.
+func (s *ProductsService) List(ctx context.Context, opts *ListOptions) ([]Product, error) {
+	return list[Product](ctx, s.client, "products", opts)
+}
+
+// ListAll follows pagination and returns every matching product. Page and
+// PerPage in opts set the starting point and page size.
+func (s *ProductsService) ListAll(ctx context.Context, opts *ListOptions) ([]Product, error) {
+	return listAll[Product](ctx, s.client, "products", opts)
+}
+
+// Get returns a product by ID.
+func (s *ProductsService) Get(ctx context.Context, id int64) (*Product, error) {
+	return get[Product](ctx, s.client, resourcePath("products", id))
+}
+
+// Create creates a product. body is any value that marshals to the API's
+// product payload, such as a Product or a map.
+func (s *ProductsService) Create(ctx context.Context, body any) (*Product, error) {
+	return send[Product](ctx, s.client, http.MethodPost, "products", body)
+}
+
+// Update changes the fields set in body on product id.
+func (s *ProductsService) Update(ctx context.Context, id int64, body any) (*Product, error) {
+	return send[Product](ctx, s.client, http.MethodPut, resourcePath("products", id), body)
+}
+
+// Delete removes a product.
+func (s *ProductsService) Delete(ctx context.Context, id int64) error {
+	return remove(ctx, s.client, resourcePath("products", id))
+}
+
+// CategoriesService reaches /categories.
+type CategoriesService struct{ client *Client }
+
+// List returns one page of categories.
+func (s *CategoriesService) List(ctx context.Context, opts *ListOptions) ([]Category, error) {
+	return list[Category](ctx, s.client, "categories", opts)
+}
+
+// ListAll follows pagination and returns every matching category.
+func (s *CategoriesService) ListAll(ctx context.Context, opts *ListOptions) ([]Category, error) {
+	return listAll[Category](ctx, s.client, "categories", opts)
+}
+
+// Get returns a category by ID.
+func (s *CategoriesService) Get(ctx context.Context, id int64) (*Category, error) {
+	return get[Category](ctx, s.client, resourcePath("categories", id))
+}
+
+// Create creates a category.
+func (s *CategoriesService) Create(ctx context.Context, body any) (*Category, error) {
+	return send[Category](ctx, s.client, http.MethodPost, "categories", body)
+}
+
+// Update changes the fields set in body on category id.
+func (s *CategoriesService) Update(ctx context.Context, id int64, body any) (*Category, error) {
+	return send[Category](ctx, s.client, http.MethodPut, resourcePath("categories", id), body)
+}
+
+// Delete removes a category.
+func (s *CategoriesService) Delete(ctx context.Context, id int64) error {
+	return remove(ctx, s.client, resourcePath("categories", id))
+}
+
+// CustomersService reaches /customers.
+type CustomersService struct{ client *Client }
+
+// List returns one page of customers.
+func (s *CustomersService) List(ctx context.Context, opts *ListOptions) ([]Customer, error) {
+	return list[Customer](ctx, s.client, "customers", opts)
+}
+
+// ListAll follows pagination and returns every matching customer.
+func (s *CustomersService) ListAll(ctx context.Context, opts *ListOptions) ([]Customer, error) {
+	return listAll[Customer](ctx, s.client, "customers", opts)
+}
+
+// Get returns a customer by ID.
+func (s *CustomersService) Get(ctx context.Context, id int64) (*Customer, error) {
+	return get[Customer](ctx, s.client, resourcePath("customers", id))
+}
+
+// OrdersService reaches /orders.
+type OrdersService struct{ client *Client }
+
+// List returns one page of orders.
+func (s *OrdersService) List(ctx context.Context, opts *ListOptions) ([]Order, error) {
+	return list[Order](ctx, s.client, "orders", opts)
+}
+
+// ListAll follows pagination and returns every matching order.
+func (s *OrdersService) ListAll(ctx context.Context, opts *ListOptions) ([]Order, error) {
+	return listAll[Order](ctx, s.client, "orders", opts)
+}
+
+// Get returns an order by ID.
+func (s *OrdersService) Get(ctx context.Context, id int64) (*Order, error) {
+	return get[Order](ctx, s.client, resourcePath("orders", id))
+}
+
+// StoreService reaches /store.
+type StoreService struct{ client *Client }
+
+// Get returns the store's settings.
+func (s *StoreService) Get(ctx context.Context) (*Store, error) {
+	return get[Store](ctx, s.client, "store")
+}
+
+func resourcePath(collection string, id int64) string {
+	return collection + "/" + strconv.FormatInt(id, 10)
+}
+
+func list[T any](ctx context.Context, c *Client, path string, opts *ListOptions) ([]T, error) {
+	resp, err := c.api.Get(ctx, path, opts.values()) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return nil, err
+	}
+
+	return api.DecodeResponse[[]T](resp)
+}
+
+func listAll[T any](ctx context.Context, c *Client, path string, opts *ListOptions) ([]T, error) {
+	return api.CollectAllPages(ctx, c.api, path, opts.values(), api.DecodeResponse[[]T])
+}
+
+func get[T any](ctx context.Context, c *Client, path string) (*T, error) {
+	resp, err := c.api.Get(ctx, path, nil) //nolint:bodyclose // DecodeResponse closes body
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := api.DecodeResponse[T](resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func send[T any](ctx context.Context, c *Client, method, path string, body any) (*T, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("encode request: %w", err)
+	}
+
+	var resp *http.Response
+
+	if method == http.MethodPost {
+		resp, err = c.api.Post(ctx, path, bytes.NewReader(b)) //nolint:bodyclose // DecodeResponse closes body
+	} else {
+		resp, err = c.api.Put(ctx, path, bytes.NewReader(b)) //nolint:bodyclose // DecodeResponse closes body
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := api.DecodeResponse[T](resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func remove(ctx context.Context, c *Client, path string) error {
+	resp, err := c.api.Delete(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return resp.Body.Close()
+}
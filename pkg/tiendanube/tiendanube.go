@@ -0,0 +1,104 @@
+// Package tiendanube is a Go client for the Tienda Nube (Nuvemshop) REST API.
+//
+// It shares its transport with the nube CLI: the required Authentication and
+// User-Agent headers, TLS 1.2+, retries on 429 and 5xx with rate-limit aware
+// backoff, a circuit breaker, and typed errors. Resources are reached through
+// per-resource services:
+//
+//	c := tiendanube.NewClient(storeID, token)
+//	products, err := c.Products.List(ctx, &tiendanube.ListOptions{PerPage: 50})
+//	order, err := c.Orders.Get(ctx, 123)
+package tiendanube
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gberlati/nube-cli/internal/api"
+)
+
+// DefaultBaseURL is the Tienda Nube API base URL.
+const DefaultBaseURL = api.DefaultBaseURL
+
+// Client is a Tienda Nube API client for one store.
+type Client struct {
+	api *api.Client
+
+	Categories *CategoriesService
+	Customers  *CustomersService
+	Orders     *OrdersService
+	Products   *ProductsService
+	Store      *StoreService
+}
+
+// Option configures a Client.
+type Option = api.Option
+
+// WithBaseURL overrides the API base URL (default DefaultBaseURL).
+func WithBaseURL(u string) Option { return api.WithBaseURL(u) }
+
+// WithUserAgent overrides the User-Agent header. Tienda Nube rejects requests
+// without one, so it should identify your app and a contact URL or email.
+func WithUserAgent(ua string) Option { return api.WithUserAgent(ua) }
+
+// WithHTTPClient replaces the underlying http.Client, including its retrying
+// transport.
+func WithHTTPClient(hc *http.Client) Option { return api.WithHTTPClient(hc) }
+
+// WithTimeout sets the per-request timeout (default 30s).
+func WithTimeout(d time.Duration) Option { return api.WithTimeout(d) }
+
+// TransportOptions tune connection reuse: idle connections kept per host,
+// idle timeout, HTTP/2 only, and keep-alives.
+type TransportOptions = api.TransportOptions
+
+// WithTransportOptions tunes the default transport; it is ignored with
+// WithHTTPClient.
+func WithTransportOptions(o TransportOptions) Option { return api.WithTransportOptions(o) }
+
+// NewClient creates a client for storeID (the store's user_id) authenticated
+// with an app access token.
+func NewClient(storeID, accessToken string, opts ...Option) *Client {
+	c := &Client{api: api.New(storeID, accessToken, opts...)}
+
+	c.Categories = &CategoriesService{client: c}
+	c.Customers = &CustomersService{client: c}
+	c.Orders = &OrdersService{client: c}
+	c.Products = &ProductsService{client: c}
+	c.Store = &StoreService{client: c}
+
+	return c
+}
+
+// StoreID returns the store the client talks to.
+func (c *Client) StoreID() string {
+	return c.api.StoreID()
+}
+
+// Error types returned by the client. Use errors.As, or the Is* helpers.
+type (
+	// APIError is any non-2xx response not covered by a more specific type,
+	// including a 429 that outlasted the retries.
+	APIError = api.APIError
+	// AuthError is a 401 response.
+	AuthError = api.AuthError
+	// CircuitBreakerError is returned without a request while the circuit is open.
+	CircuitBreakerError = api.CircuitBreakerError
+	// NotFoundError is a 404 response.
+	NotFoundError = api.NotFoundError
+	// PaymentRequiredError is a 402 response (store subscription lapsed).
+	PaymentRequiredError = api.PaymentRequiredError
+	// PermissionDeniedError is a 403 response (missing app scope).
+	PermissionDeniedError = api.PermissionDeniedError
+	// ValidationError is a 422 response with per-field messages.
+	ValidationError = api.ValidationError
+)
+
+// IsNotFound reports whether err is a NotFoundError.
+func IsNotFound(err error) bool { return api.IsNotFoundError(err) }
+
+// IsAuth reports whether err is an AuthError.
+func IsAuth(err error) bool { return api.IsAuthError(err) }
+
+// IsValidation reports whether err is a ValidationError.
+func IsValidation(err error) bool { return api.IsValidationError(err) }
@@ -0,0 +1,135 @@
+package tiendanube_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gberlati/nube-cli/pkg/tiendanube"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *tiendanube.Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return tiendanube.NewClient("123", "tok",
+		tiendanube.WithBaseURL(srv.URL),
+		tiendanube.WithHTTPClient(srv.Client()),
+	)
+}
+
+func TestProducts_List(t *testing.T) {
+	t.Parallel()
+
+	var gotPath, gotQuery, gotAuth string
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotQuery = r.URL.RawQuery
+		gotAuth = r.Header.Get("Authentication")
+
+		_, _ = w.Write([]byte(`[{"id":1,"name":{"es":"Remera"},"variants":[{"id":10,"price":"19.90","stock":null}]}]`))
+	})
+
+	products, err := c.Products.List(context.Background(), &tiendanube.ListOptions{
+		Page:    2,
+		PerPage: 50,
+		Query:   map[string][]string{"q": {"remera"}},
+	})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if gotPath != "/123/products" || gotQuery != "page=2&per_page=50&q=remera" || gotAuth != "bearer tok" {
+		t.Errorf("request = %s?%s auth %q", gotPath, gotQuery, gotAuth)
+	}
+
+	if len(products) != 1 || products[0].Name.Get() != "Remera" {
+		t.Fatalf("products = %+v", products)
+	}
+
+	if v := products[0].Variants[0]; v.Price != "19.90" || v.Stock != nil {
+		t.Errorf("variant = %+v", v)
+	}
+}
+
+func TestProducts_Update(t *testing.T) {
+	t.Parallel()
+
+	var gotMethod, gotPath string
+
+	var gotBody map[string]any
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotPath = r.URL.Path
+
+		b, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(b, &gotBody)
+
+		_, _ = w.Write([]byte(`{"id":7,"published":true}`))
+	})
+
+	p, err := c.Products.Update(context.Background(), 7, map[string]any{"published": true})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if gotMethod != http.MethodPut || gotPath != "/123/products/7" || gotBody["published"] != true {
+		t.Errorf("request = %s %s %v", gotMethod, gotPath, gotBody)
+	}
+
+	if p.ID != 7 || !p.Published {
+		t.Errorf("product = %+v", p)
+	}
+}
+
+func TestOrders_GetNotFound(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	_, err := c.Orders.Get(context.Background(), 99)
+	if !tiendanube.IsNotFound(err) {
+		t.Errorf("err = %v, want not found", err)
+	}
+}
+
+func TestLocalizedString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		json  string
+		langs []string
+		want  string
+	}{
+		{name: "default order", json: `{"en":"Shirt","pt":"Camisa","es":"Remera"}`, want: "Remera"},
+		{name: "preferred", json: `{"en":"Shirt","es":"Remera"}`, langs: []string{"en"}, want: "Shirt"},
+		{name: "skips empty", json: `{"es":"","pt":"Camisa"}`, want: "Camisa"},
+		{name: "other language", json: `{"fr":"Chemise"}`, want: "Chemise"},
+		{name: "plain string", json: `"Remera"`, want: "Remera"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var l tiendanube.LocalizedString
+			if err := json.Unmarshal([]byte(tt.json), &l); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := l.Get(tt.langs...); got != tt.want {
+				t.Errorf("Get() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}